@@ -0,0 +1,396 @@
+// Package client sends DNS queries to a name server over UDP or TCP and returns the decoded responses.
+package client
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+var (
+	ErrIDMismatch     = fmt.Errorf("response ID does not match query ID")
+	ErrCookieMismatch = fmt.Errorf("response client cookie does not match query client cookie")
+)
+
+const DefaultTimeout = 5 * time.Second
+
+// DefaultUDPSize is the EDNS UDP payload size advertised by default.
+const DefaultUDPSize = dns.DefaultEDNSUDPSize
+
+// Client sends queries to a single DNS server.
+type Client struct {
+	Server  string           // Address of the DNS server, ex. "8.8.8.8:53"
+	Timeout time.Duration    // Maximum time to wait for a response on each attempt
+	UDPSize uint16           // EDNS UDP payload size to advertise, EDNS is not used if 512 or less
+	Options []dns.EDNSOption // EDNS options to attach to every query, ex. a client subnet
+	Cookies bool             // Send DNS cookies [RFC7873] and echo the server cookies received
+	Retries int              // Number of times a UDP query is sent again if its error class allows it
+
+	IgnoreTruncation bool // Return truncated UDP responses as is instead of retrying over TCP
+
+	SIG0Key       *dns.SIG0Key       // Sign queries with this SIG(0) key [RFC2931]
+	SIG0ServerKey *dns.SIG0PublicKey // Require responses to be SIG(0) signed with this key
+
+	cookieMutex sync.Mutex
+	cookies     map[string]*dns.EDNSOptionCookie // Latest cookies, by server address
+}
+
+// Response holds a decoded DNS response along with details about how it was obtained.
+type Response struct {
+	Message  dns.Message   // The decoded response
+	Server   string        // Address of the server which sent the response
+	Size     int           // The size of the response on the wire, in bytes
+	TCP      bool          // Whether the response was received over TCP, after a truncated UDP response
+	Retries  int           // Number of times the UDP query was sent again, after errors or without EDNS
+	Duration time.Duration // Time elapsed between sending the query and decoding the response
+}
+
+// transport returns the transport the response was received over, "UDP" or "TCP", as in QueryError.
+func (response Response) transport() string {
+	if response.TCP {
+		return "TCP"
+	}
+	return "UDP"
+}
+
+// NewClient returns a Client that queries the given server with the default timeout.
+//
+// Parameters:
+//   - server: The address of the DNS server, ex. "8.8.8.8:53".
+func NewClient(server string) *Client {
+	return &Client{
+		Server:  server,
+		Timeout: DefaultTimeout,
+		UDPSize: DefaultUDPSize,
+	}
+}
+
+// Exchange sends a query to the server and waits for the matching response.
+// The query is assigned a fresh random ID, and responses carrying any other
+// ID are discarded.
+//
+// Unless the query already carries EDNS parameters, the client's UDP payload size
+// and EDNS options (including its cookies, if enabled) are sent in an OPT record and the read buffer is sized accordingly. If that query
+// times out (ex. fragments dropped on the path) or the server does not support EDNS,
+// it is retried once as a plain 512 byte query. If the UDP response is truncated
+// or larger than the advertised size, the query is retried over TCP, unless the
+// client ignores truncation and the response has the TC flag.
+//
+// A UDP query that failed in a way worth retrying on the same server (see ErrorClass)
+// is sent again up to the client's number of retries. Errors are returned as *QueryError.
+//
+// With a SIG(0) key, queries are signed before being sent. With a SIG(0) server key,
+// responses must carry a valid signature from that key.
+//
+// With cookies enabled, a response echoing the wrong client cookie is rejected,
+// and a BADCOOKIE response is retried once with the new server cookie.
+//
+// Parameters:
+//   - query: The DNS query to send.
+//
+// Returns:
+//   - Response: The decoded response and information about the exchange.
+//   - error: If the query could not be sent or no matching response was received.
+func (client *Client) Exchange(query dns.Message) (response Response, err error) {
+	if !client.Cookies {
+		return client.exchange(query, client.Options)
+	}
+
+	response, err = client.exchangeWithCookie(query)
+	if err == nil && response.Message.ResponseCode() == dns.BADCOOKIE {
+		// The server sent a fresh server cookie along with the error: retry once with it
+		response, err = client.exchangeWithCookie(query)
+	}
+	return response, err
+}
+
+// exchangeWithCookie sends the query with the cookies known for the server,
+// and stores the server cookie from the response.
+func (client *Client) exchangeWithCookie(query dns.Message) (response Response, err error) {
+	cookie := client.getCookie(client.Server)
+	options := append(append([]dns.EDNSOption{}, client.Options...), cookie)
+
+	response, err = client.exchange(query, options)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if err = client.storeServerCookie(client.Server, cookie, response.Message); err != nil {
+		return Response{}, newQueryError(response.transport(), err)
+	}
+	return response, nil
+}
+
+func (client *Client) exchange(query dns.Message, options []dns.EDNSOption) (response Response, err error) {
+	query.Header.Id = dns.NewID()
+
+	startTime := time.Now()
+
+	udpQuery, bufferSize, ednsAdded := client.prepareUDPQuery(query, options)
+
+	data, err := client.encodeQuery(udpQuery)
+	if err != nil {
+		return Response{}, err
+	}
+
+	raw, message, truncated, retries, err := client.exchangeUDPWithRetries(data, query.Header.Id, bufferSize)
+	response.Retries = retries
+	if ednsAdded && shouldStepDown(message, err) {
+		// Step down to a plain query that fits in a single unfragmented datagram:
+		// the DO bit and options cannot be sent without EDNS
+		udpQuery = query
+		udpQuery.Header.Flags.DnssecOk = false
+
+		data, err = client.encodeQuery(udpQuery)
+		if err != nil {
+			return Response{}, err
+		}
+		raw, message, truncated, retries, err = client.exchangeUDPWithRetries(data, query.Header.Id, dns.MaxDNSMessageSizeOverUDP)
+		response.Retries += 1 + retries
+	}
+	if err != nil {
+		return Response{}, newQueryError("UDP", err)
+	}
+
+	if truncated && client.IgnoreTruncation && message.Header.Flags.Truncated {
+		truncated = false
+	}
+
+	if truncated {
+		// If UDP response is truncated (i.e. larger than the advertised size)
+		// fall back to TCP
+		response.TCP = true
+
+		raw, err = client.exchangeTCP(data, query.Header.Id)
+		if err != nil {
+			return Response{}, newQueryError("TCP", err)
+		}
+
+		message, err = dns.DecodeMessage(raw)
+		if err != nil {
+			return Response{}, newQueryError("TCP", fmt.Errorf("decode DNS response: %w", err))
+		}
+	}
+
+	if client.SIG0ServerKey != nil {
+		if err = client.SIG0ServerKey.Verify(raw, data); err != nil {
+			return Response{}, newQueryError(response.transport(), fmt.Errorf("verify SIG(0) signature: %w", err))
+		}
+	}
+
+	response.Message = message
+	response.Server = client.Server
+	response.Size = len(raw)
+	response.Duration = time.Since(startTime)
+
+	return response, nil
+}
+
+// prepareUDPQuery adds an OPT record advertising the client's UDP payload size
+// and carrying the given EDNS options, and returns the size of the buffer needed to read the response.
+func (client *Client) prepareUDPQuery(query dns.Message, options []dns.EDNSOption) (udpQuery dns.Message, bufferSize int, ednsAdded bool) {
+	if query.EDNS != nil {
+		// The caller chose its own EDNS parameters
+		return query, max(int(query.EDNS.UDPSize), dns.MaxDNSMessageSizeOverUDP), false
+	}
+
+	if client.UDPSize <= dns.MaxDNSMessageSizeOverUDP && !query.Header.Flags.DnssecOk && len(options) == 0 {
+		return query, dns.MaxDNSMessageSizeOverUDP, false
+	}
+
+	// The DO bit and options require EDNS even if the client does not advertise a larger size
+	udpSize := max(client.UDPSize, dns.MaxDNSMessageSizeOverUDP)
+	udpQuery = query
+	udpQuery.EDNS = &dns.EDNS{UDPSize: udpSize, Options: options}
+	udpQuery.UpdateHeaderCounts()
+
+	return udpQuery, int(udpSize), true
+}
+
+// shouldStepDown reports whether an EDNS query should be retried without EDNS:
+// either no response came back in time, or the server rejected the OPT record.
+func shouldStepDown(message dns.Message, err error) bool {
+	if err != nil {
+		return classifyError(err) == ErrorClassTimeout
+	}
+	return message.Header.Flags.ResponseCode == dns.FORMERR
+}
+
+// encodeQuery encodes a query, signing it if the client has a SIG(0) key.
+func (client *Client) encodeQuery(query dns.Message) (data []byte, err error) {
+	data, err = dns.EncodeMessage(query)
+	if err != nil {
+		return nil, fmt.Errorf("encode DNS query: %w", err)
+	}
+
+	if client.SIG0Key != nil {
+		data, err = client.SIG0Key.Sign(data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("sign DNS query: %w", err)
+		}
+	}
+	return data, nil
+}
+
+func (client *Client) exchangeUDPWithRetries(data []byte, id uint16, bufferSize int) (raw []byte, message dns.Message, truncated bool, retries int, err error) {
+	for attempt := 0; ; attempt++ {
+		raw, message, truncated, err = client.exchangeUDP(data, id, bufferSize)
+		if err == nil || attempt >= client.Retries || !classifyError(err).RetrySameServer() {
+			return raw, message, truncated, attempt, err
+		}
+	}
+}
+
+func (client *Client) exchangeUDP(data []byte, id uint16, bufferSize int) (raw []byte, message dns.Message, truncated bool, err error) {
+	conn, err := net.Dial("udp", client.Server)
+	if err != nil {
+		return nil, dns.Message{}, false, fmt.Errorf("failed to connect to DNS server: %w", err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(client.Timeout))
+
+	_, err = conn.Write(data)
+	if err != nil {
+		return nil, dns.Message{}, false, fmt.Errorf("failed to send DNS query: %w", err)
+	}
+
+	// One extra byte to detect responses larger than the buffer
+	receivedResponse := make([]byte, bufferSize+1)
+	mismatch := false
+	for {
+		n, err := conn.Read(receivedResponse)
+		if err != nil {
+			var netErr net.Error
+			if mismatch && errors.As(err, &netErr) && netErr.Timeout() {
+				return nil, dns.Message{}, false, ErrIDMismatch
+			}
+			return nil, dns.Message{}, false, fmt.Errorf("failed to read DNS response: %w", err)
+		}
+
+		// A datagram with the wrong ID is either stale or spoofed:
+		// ignore it and keep waiting for the real response
+		if !hasID(receivedResponse[:n], id) {
+			mismatch = true
+			continue
+		}
+
+		if n > bufferSize {
+			// The response did not fit in the advertised size: it cannot be trusted to be complete
+			return receivedResponse[:n], dns.Message{}, true, nil
+		}
+
+		raw = receivedResponse[:n]
+		message, err = dns.DecodeMessage(raw)
+		if err != nil {
+			return nil, dns.Message{}, false, fmt.Errorf("decode DNS response: %w", err)
+		}
+		return raw, message, message.Header.Flags.Truncated, nil
+	}
+}
+
+func (client *Client) exchangeTCP(data []byte, id uint16) (response []byte, err error) {
+	conn, err := net.Dial("tcp", client.Server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to DNS server: %w", err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(client.Timeout))
+
+	if err = writeTCPMessage(conn, data); err != nil {
+		return nil, err
+	}
+
+	response, err = readTCPMessage(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasID(response, id) {
+		return nil, ErrIDMismatch
+	}
+
+	return response, nil
+}
+
+// writeTCPMessage sends a message over a TCP connection.
+func writeTCPMessage(conn net.Conn, data []byte) error {
+	if err := dns.WriteRawMessage(conn, data); err != nil {
+		return fmt.Errorf("failed to send DNS query: %w", err)
+	}
+	return nil
+}
+
+// readTCPMessage reads the next length-prefixed message from a TCP connection.
+func readTCPMessage(conn net.Conn) (message []byte, err error) {
+	message, err = dns.ReadRawMessage(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DNS response: %w", err)
+	}
+	return message, nil
+}
+
+// getCookie returns the cookies to send to a server, generating
+// a new client cookie the first time the server is queried.
+func (client *Client) getCookie(server string) *dns.EDNSOptionCookie {
+	client.cookieMutex.Lock()
+	defer client.cookieMutex.Unlock()
+
+	if client.cookies == nil {
+		client.cookies = map[string]*dns.EDNSOptionCookie{}
+	}
+
+	cookie, ok := client.cookies[server]
+	if !ok {
+		cookie = &dns.EDNSOptionCookie{}
+		rand.Read(cookie.ClientCookie[:])
+		client.cookies[server] = cookie
+	}
+
+	return &dns.EDNSOptionCookie{
+		ClientCookie: cookie.ClientCookie,
+		ServerCookie: append([]byte{}, cookie.ServerCookie...),
+	}
+}
+
+// storeServerCookie checks the cookie option in a response against the one sent,
+// and remembers the server cookie to echo on the next query.
+// A response without a cookie is accepted: the server may not support cookies.
+func (client *Client) storeServerCookie(server string, sent *dns.EDNSOptionCookie, response dns.Message) error {
+	edns, ok := getEDNS(response)
+	if !ok {
+		return nil
+	}
+
+	for _, option := range edns.Options {
+		received, ok := option.(*dns.EDNSOptionCookie)
+		if !ok {
+			continue
+		}
+		if received.ClientCookie != sent.ClientCookie {
+			return ErrCookieMismatch
+		}
+
+		client.cookieMutex.Lock()
+		client.cookies[server].ServerCookie = append([]byte{}, received.ServerCookie...)
+		client.cookieMutex.Unlock()
+	}
+	return nil
+}
+
+func getEDNS(message dns.Message) (edns dns.EDNS, ok bool) {
+	if message.EDNS == nil {
+		return dns.EDNS{}, false
+	}
+	return *message.EDNS, true
+}
+
+func hasID(message []byte, id uint16) bool {
+	return len(message) >= 2 && uint16(message[0])<<8|uint16(message[1]) == id
+}
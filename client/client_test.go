@@ -0,0 +1,597 @@
+package client
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// testServer answers queries on UDP and TCP on the same local port.
+// The handler returns the raw responses to send back for each query, in order.
+type testServer struct {
+	address    string
+	packetConn net.PacketConn
+	listener   net.Listener
+}
+
+func startTestServer(t *testing.T, udpHandler func(query []byte) [][]byte, tcpHandler func(query []byte) [][]byte) *testServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen TCP: %v", err)
+	}
+	packetConn, err := net.ListenPacket("udp", listener.Addr().String())
+	if err != nil {
+		listener.Close()
+		t.Fatalf("listen UDP: %v", err)
+	}
+
+	server := &testServer{
+		address:    listener.Addr().String(),
+		packetConn: packetConn,
+		listener:   listener,
+	}
+	t.Cleanup(func() {
+		packetConn.Close()
+		listener.Close()
+	})
+
+	go func() {
+		buffer := make([]byte, dns.MaxDNSMessageSize)
+		for {
+			n, addr, err := packetConn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			if udpHandler == nil {
+				continue
+			}
+			for _, response := range udpHandler(append([]byte{}, buffer[:n]...)) {
+				packetConn.WriteTo(response, addr)
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				lengthPrefix := [2]byte{}
+				if _, err := io.ReadFull(conn, lengthPrefix[:]); err != nil {
+					return
+				}
+				query := make([]byte, int(lengthPrefix[0])<<8|int(lengthPrefix[1]))
+				if _, err := io.ReadFull(conn, query); err != nil {
+					return
+				}
+				if tcpHandler == nil {
+					return
+				}
+				for _, response := range tcpHandler(query) {
+					length := len(response)
+					conn.Write(append([]byte{byte(length >> 8), byte(length)}, response...))
+				}
+			}(conn)
+		}
+	}()
+
+	return server
+}
+
+func buildTestResponse(t *testing.T, query []byte, id uint16, truncated bool) []byte {
+	t.Helper()
+
+	message, err := dns.DecodeMessage(query)
+	if err != nil {
+		t.Errorf("test server: decode query: %v", err)
+		return nil
+	}
+
+	message.Header.Id = id
+	message.Header.Flags.Response = true
+	message.Header.Flags.Truncated = truncated
+
+	response, err := dns.EncodeMessage(message)
+	if err != nil {
+		t.Errorf("test server: encode response: %v", err)
+		return nil
+	}
+	return response
+}
+
+func getTestQueryID(query []byte) uint16 {
+	return uint16(query[0])<<8 | uint16(query[1])
+}
+
+// getTestUDPSize returns the UDP payload size advertised in the query's OPT record, or 0 without EDNS.
+func getTestUDPSize(t *testing.T, query []byte) uint16 {
+	message, err := dns.DecodeMessage(query)
+	if err != nil {
+		t.Errorf("test server: decode query: %v", err)
+		return 0
+	}
+	if message.EDNS == nil {
+		return 0
+	}
+	return message.EDNS.UDPSize
+}
+
+func newTestQuery() dns.Message {
+	return dns.Message{
+		Header: dns.Header{
+			Flags:         dns.Flags{RecursionDesired: true},
+			QuestionCount: 1,
+		},
+		Questions: []dns.Question{
+			{Name: "example.com.", QType: dns.A, QClass: dns.IN},
+		},
+	}
+}
+
+func TestExchange(t *testing.T) {
+	tests := []struct {
+		name        string
+		udpHandler  func(t *testing.T) func(query []byte) [][]byte
+		tcpHandler  func(t *testing.T) func(query []byte) [][]byte
+		udpSize     uint16
+		options     []dns.EDNSOption
+		ignoreTC    bool
+		wantTCP     bool
+		wantRetries int
+		wantError   error
+	}{
+		{
+			name: "UDP response",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					return [][]byte{buildTestResponse(t, query, getTestQueryID(query), false)}
+				}
+			},
+			wantTCP:   false,
+			wantError: nil,
+		},
+		{
+			name: "Spoofed UDP response is ignored",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					id := getTestQueryID(query)
+					return [][]byte{
+						buildTestResponse(t, query, id+1, false),
+						buildTestResponse(t, query, id, false),
+					}
+				}
+			},
+			wantTCP:   false,
+			wantError: nil,
+		},
+		{
+			name: "Only mismatched UDP responses",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					return [][]byte{buildTestResponse(t, query, getTestQueryID(query)+1, false)}
+				}
+			},
+			wantError: ErrIDMismatch,
+		},
+		{
+			name: "Truncated UDP response falls back to TCP",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					return [][]byte{buildTestResponse(t, query, getTestQueryID(query), true)}
+				}
+			},
+			tcpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					return [][]byte{buildTestResponse(t, query, getTestQueryID(query), false)}
+				}
+			},
+			wantTCP:   true,
+			wantError: nil,
+		},
+		{
+			name:     "Truncated UDP response is returned when truncation is ignored",
+			ignoreTC: true,
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					return [][]byte{buildTestResponse(t, query, getTestQueryID(query), true)}
+				}
+			},
+			wantTCP:   false,
+			wantError: nil,
+		},
+		{
+			name: "Mismatched TCP response",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					return [][]byte{buildTestResponse(t, query, getTestQueryID(query), true)}
+				}
+			},
+			tcpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					return [][]byte{buildTestResponse(t, query, getTestQueryID(query)+1, false)}
+				}
+			},
+			wantError: ErrIDMismatch,
+		},
+		{
+			name: "EDNS query advertises UDP size",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					if getTestUDPSize(t, query) != DefaultUDPSize {
+						t.Errorf("test server: query does not advertise UDP size %d", DefaultUDPSize)
+					}
+					return [][]byte{buildTestResponse(t, query, getTestQueryID(query), false)}
+				}
+			},
+			wantTCP:   false,
+			wantError: nil,
+		},
+		{
+			name: "EDNS query dropped: step down to plain query",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					if getTestUDPSize(t, query) != 0 {
+						return nil
+					}
+					return [][]byte{buildTestResponse(t, query, getTestQueryID(query), false)}
+				}
+			},
+			wantTCP:     false,
+			wantRetries: 1,
+			wantError:   nil,
+		},
+		{
+			name: "EDNS not supported: step down to plain query",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					response := buildTestResponse(t, query, getTestQueryID(query), false)
+					if getTestUDPSize(t, query) != 0 {
+						response[3] |= byte(dns.FORMERR)
+					}
+					return [][]byte{response}
+				}
+			},
+			wantTCP:     false,
+			wantRetries: 1,
+			wantError:   nil,
+		},
+		{
+			name:    "EDNS options are sent without a larger UDP size",
+			udpSize: dns.MaxDNSMessageSizeOverUDP,
+			options: []dns.EDNSOption{
+				&dns.EDNSOptionClientSubnet{Family: dns.ECSFamilyIPv4, SourcePrefix: 24, Address: netip.MustParseAddr("192.0.2.0")},
+			},
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					message, err := dns.DecodeMessage(query)
+					if err != nil || message.EDNS == nil {
+						t.Errorf("test server: query has no OPT record: %v", err)
+						return nil
+					}
+					edns := message.EDNS
+					if len(edns.Options) != 1 || edns.Options[0].Code() != dns.EDNS0SUBNET {
+						t.Errorf("test server: query does not carry a client subnet: %+v", edns)
+					}
+					return [][]byte{buildTestResponse(t, query, getTestQueryID(query), false)}
+				}
+			},
+			wantTCP:   false,
+			wantError: nil,
+		},
+		{
+			name:    "UDP response larger than advertised size falls back to TCP",
+			udpSize: dns.MaxDNSMessageSizeOverUDP,
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					response := buildTestResponse(t, query, getTestQueryID(query), false)
+					return [][]byte{append(response, make([]byte, dns.MaxDNSMessageSizeOverUDP)...)}
+				}
+			},
+			tcpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					return [][]byte{buildTestResponse(t, query, getTestQueryID(query), false)}
+				}
+			},
+			wantTCP:   true,
+			wantError: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var udpHandler, tcpHandler func(query []byte) [][]byte
+			if tt.udpHandler != nil {
+				udpHandler = tt.udpHandler(t)
+			}
+			if tt.tcpHandler != nil {
+				tcpHandler = tt.tcpHandler(t)
+			}
+			server := startTestServer(t, udpHandler, tcpHandler)
+
+			client := NewClient(server.address)
+			client.Timeout = 200 * time.Millisecond
+			if tt.udpSize != 0 {
+				client.UDPSize = tt.udpSize
+			}
+			client.Options = tt.options
+			client.IgnoreTruncation = tt.ignoreTC
+
+			got, err := client.Exchange(newTestQuery())
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Exchange() error = %v, want error = %v\n", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange() unexpected error = %v\n", err)
+			}
+
+			if !got.Message.Header.Flags.Response {
+				t.Errorf("Exchange() got a message that is not a response\n")
+			}
+			if got.Message.Header.Flags.ResponseCode != dns.NOERROR {
+				t.Errorf("Exchange() response code got = %s\n", dns.DNSRCode(got.Message.Header.Flags.ResponseCode))
+			}
+			if got.TCP != tt.wantTCP {
+				t.Errorf("Exchange() TCP got = %t, want = %t\n", got.TCP, tt.wantTCP)
+			}
+			if got.Retries != tt.wantRetries {
+				t.Errorf("Exchange() Retries got = %d, want = %d\n", got.Retries, tt.wantRetries)
+			}
+			if got.Size == 0 {
+				t.Errorf("Exchange() Size got = 0\n")
+			}
+			if got.Server != server.address {
+				t.Errorf("Exchange() Server got = %s, want = %s\n", got.Server, server.address)
+			}
+		})
+	}
+}
+
+var testServerCookie = []byte{0xa, 0xb, 0xc, 0xd, 0xe, 0xf, 0x10, 0x11}
+
+// buildTestCookieResponse answers a query with its cookie option, the client cookie
+// replaced by clientCookie if not nil, and the given server cookie.
+func buildTestCookieResponse(t *testing.T, query []byte, clientCookie []byte, responseCode uint16) []byte {
+	t.Helper()
+
+	message, err := dns.DecodeMessage(query)
+	if err != nil || message.EDNS == nil {
+		t.Errorf("test server: query has no OPT record: %v", err)
+		return nil
+	}
+	edns := message.EDNS
+	if len(edns.Options) != 1 {
+		t.Errorf("test server: query has no cookie")
+		return nil
+	}
+	cookie := edns.Options[0].(*dns.EDNSOptionCookie)
+	if clientCookie != nil {
+		copy(cookie.ClientCookie[:], clientCookie)
+	}
+	cookie.ServerCookie = testServerCookie
+
+	edns.ExtendedRCode = uint8(responseCode >> 4)
+	message.Header.Flags.ResponseCode = responseCode & 0xF
+	message.Header.Flags.Response = true
+
+	response, err := dns.EncodeMessage(message)
+	if err != nil {
+		t.Errorf("test server: encode response: %v", err)
+		return nil
+	}
+	return response
+}
+
+func getTestServerCookie(t *testing.T, query []byte) []byte {
+	message, err := dns.DecodeMessage(query)
+	if err != nil || message.EDNS == nil {
+		t.Errorf("test server: query has no OPT record: %v", err)
+		return nil
+	}
+	for _, option := range message.EDNS.Options {
+		if cookie, ok := option.(*dns.EDNSOptionCookie); ok {
+			return cookie.ServerCookie
+		}
+	}
+	return nil
+}
+
+func TestExchangeCookies(t *testing.T) {
+	tests := []struct {
+		name       string
+		udpHandler func(t *testing.T) func(query []byte) [][]byte
+		wantError  error
+	}{
+		{
+			name: "Server cookie is echoed on the next query",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				queries := 0
+				return func(query []byte) [][]byte {
+					queries++
+					serverCookie := getTestServerCookie(t, query)
+					if queries == 1 && serverCookie != nil {
+						t.Errorf("test server: first query already has a server cookie: %v", serverCookie)
+					}
+					if queries > 1 && !bytes.Equal(serverCookie, testServerCookie) {
+						t.Errorf("test server: server cookie got = %v, want = %v", serverCookie, testServerCookie)
+					}
+					return [][]byte{buildTestCookieResponse(t, query, nil, dns.NOERROR)}
+				}
+			},
+			wantError: nil,
+		},
+		{
+			name: "BADCOOKIE is retried with the new server cookie",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					if getTestServerCookie(t, query) == nil {
+						return [][]byte{buildTestCookieResponse(t, query, nil, dns.BADCOOKIE)}
+					}
+					return [][]byte{buildTestCookieResponse(t, query, nil, dns.NOERROR)}
+				}
+			},
+			wantError: nil,
+		},
+		{
+			name: "Response with the wrong client cookie",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					return [][]byte{buildTestCookieResponse(t, query, []byte{0, 0, 0, 0, 0, 0, 0, 0}, dns.NOERROR)}
+				}
+			},
+			wantError: ErrCookieMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startTestServer(t, tt.udpHandler(t), nil)
+
+			client := NewClient(server.address)
+			client.Timeout = 200 * time.Millisecond
+			client.Cookies = true
+
+			for i := 0; i < 2; i++ {
+				got, err := client.Exchange(newTestQuery())
+
+				if tt.wantError != nil {
+					if err == nil || !errors.Is(err, tt.wantError) {
+						t.Fatalf("Exchange() error = %v, want error = %v\n", err, tt.wantError)
+					}
+					var queryErr *QueryError
+					if !errors.As(err, &queryErr) || queryErr.Class != ErrorClassMismatch {
+						t.Errorf("Exchange() error = %v, want a *QueryError of class %s\n", err, ErrorClassMismatch)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Exchange() unexpected error = %v\n", err)
+				}
+				if got.Message.Header.Flags.ResponseCode != dns.NOERROR {
+					t.Errorf("Exchange() response code got = %s\n", dns.DNSRCode(got.Message.Header.Flags.ResponseCode))
+				}
+			}
+		})
+	}
+}
+
+func newTestSIG0Key(t *testing.T, name string) *dns.SIG0Key {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate Ed25519 key: %v", err)
+	}
+	return &dns.SIG0Key{
+		SIG0PublicKey: dns.SIG0PublicKey{
+			Name: name,
+			Key: dns.RDataDNSKEY{
+				Flags:     512,
+				Protocol:  dns.DNSKEYProtocol,
+				Algorithm: dns.ED25519,
+				PublicKey: publicKey,
+			},
+		},
+		Signer: privateKey,
+	}
+}
+
+// buildTestSignedResponse checks the query's SIG(0) signature and answers it
+// with a response signed with the server key.
+func buildTestSignedResponse(t *testing.T, query []byte, clientKey *dns.SIG0PublicKey, serverKey *dns.SIG0Key) []byte {
+	t.Helper()
+
+	if err := clientKey.Verify(query, nil); err != nil {
+		t.Errorf("test server: verify query: %v", err)
+		return nil
+	}
+
+	message, err := dns.DecodeMessage(query)
+	if err != nil {
+		t.Errorf("test server: decode query: %v", err)
+		return nil
+	}
+	message.Header.Flags.Response = true
+	message.Additionals = message.Additionals[:len(message.Additionals)-1]
+	message.Header.AdditionalRRCount--
+
+	response, err := dns.EncodeMessage(message)
+	if err != nil {
+		t.Errorf("test server: encode response: %v", err)
+		return nil
+	}
+	response, err = serverKey.Sign(response, query)
+	if err != nil {
+		t.Errorf("test server: sign response: %v", err)
+		return nil
+	}
+	return response
+}
+
+func TestExchangeSIG0(t *testing.T) {
+	clientKey := newTestSIG0Key(t, "client.example.com.")
+	serverKey := newTestSIG0Key(t, "server.example.com.")
+	otherKey := newTestSIG0Key(t, "server.example.com.")
+
+	tests := []struct {
+		name       string
+		signingKey *dns.SIG0Key
+		wantError  error
+	}{
+		{
+			name:       "Response signed with the server key",
+			signingKey: serverKey,
+			wantError:  nil,
+		},
+		{
+			name:       "Response signed with another key",
+			signingKey: otherKey,
+			wantError:  dns.ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startTestServer(t, func(query []byte) [][]byte {
+				return [][]byte{buildTestSignedResponse(t, query, &clientKey.SIG0PublicKey, tt.signingKey)}
+			}, nil)
+
+			client := NewClient(server.address)
+			client.Timeout = 200 * time.Millisecond
+			client.SIG0Key = clientKey
+			client.SIG0ServerKey = &serverKey.SIG0PublicKey
+
+			_, err := client.Exchange(newTestQuery())
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Exchange() error = %v, want error = %v\n", err, tt.wantError)
+				}
+				var queryErr *QueryError
+				if !errors.As(err, &queryErr) || GetErrorClass(err) != ErrorClassMismatch {
+					t.Errorf("Exchange() error = %v, want a *QueryError of class %s\n", err, ErrorClassMismatch)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange() unexpected error = %v\n", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// ErrorClass classifies why a query failed at the transport level,
+// so that callers can decide whether and where to retry it.
+type ErrorClass int
+
+const (
+	ErrorClassUnknown     ErrorClass = iota
+	ErrorClassTimeout                // No response in time: the query or the response may have been lost
+	ErrorClassRefused                // The server's host rejected the connection or datagram: nothing listens on the port
+	ErrorClassUnreachable            // No route to the server's network or host
+	ErrorClassMismatch               // Only responses with the wrong ID, cookie or SIG(0) signature were received: possible spoofing
+	ErrorClassMalformed              // The response could not be decoded
+)
+
+var errorClassNames = map[ErrorClass]string{
+	ErrorClassUnknown:     "unknown",
+	ErrorClassTimeout:     "timeout",
+	ErrorClassRefused:     "refused",
+	ErrorClassUnreachable: "unreachable",
+	ErrorClassMismatch:    "mismatch",
+	ErrorClassMalformed:   "malformed",
+}
+
+func (class ErrorClass) String() string {
+	if n, ok := errorClassNames[class]; ok {
+		return n
+	}
+	return "unknown"
+}
+
+// RetrySameServer reports whether sending the query to the same server again may succeed.
+// Only lost packets are worth retrying: a refused or unreachable server
+// will fail the same way, and a malformed response will likely be malformed again.
+func (class ErrorClass) RetrySameServer() bool {
+	return class == ErrorClassTimeout
+}
+
+// TryNextServer reports whether another server should be tried after this error.
+func (class ErrorClass) TryNextServer() bool {
+	return class != ErrorClassUnknown
+}
+
+// QueryError is returned by the client when a query fails. It records the transport used
+// and the class of the failure, and wraps the underlying error.
+type QueryError struct {
+	Class     ErrorClass
+	Transport string // "UDP" or "TCP"
+	Err       error
+}
+
+func (err *QueryError) Error() string {
+	return fmt.Sprintf("query over %s: %s", err.Transport, err.Err.Error())
+}
+
+func (err *QueryError) Unwrap() error {
+	return err.Err
+}
+
+// GetErrorClass returns the class of an error returned by the client.
+//
+// Parameters:
+//   - err: The error returned by a query.
+//
+// Returns:
+//   - ErrorClass: The class of the error, ErrorClassUnknown if it cannot be classified.
+func GetErrorClass(err error) ErrorClass {
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) {
+		return queryErr.Class
+	}
+	return classifyError(err)
+}
+
+func newQueryError(transport string, err error) *QueryError {
+	return &QueryError{
+		Class:     classifyError(err),
+		Transport: transport,
+		Err:       err,
+	}
+}
+
+func classifyError(err error) ErrorClass {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return ErrorClassUnknown
+	case errors.Is(err, ErrIDMismatch), errors.Is(err, ErrCookieMismatch), errors.Is(err, dns.ErrInvalidSignature):
+		return ErrorClassMismatch
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	case isRefusedError(err):
+		return ErrorClassRefused
+	case isUnreachableError(err):
+		return ErrorClassUnreachable
+	case errors.Is(err, dns.ErrInvalidMessage), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorClassMalformed
+	}
+	return ErrorClassUnknown
+}
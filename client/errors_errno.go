@@ -0,0 +1,18 @@
+//go:build !plan9
+
+package client
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isRefusedError reports whether the server's host rejected the connection or datagram.
+func isRefusedError(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// isUnreachableError reports whether there is no route to the server's network or host.
+func isUnreachableError(err error) bool {
+	return errors.Is(err, syscall.ENETUNREACH) || errors.Is(err, syscall.EHOSTUNREACH)
+}
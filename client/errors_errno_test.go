@@ -0,0 +1,39 @@
+//go:build !plan9
+
+package client
+
+import (
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestGetErrorClassErrno(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{
+			name: "Connection refused",
+			err:  &net.OpError{Op: "read", Net: "udp", Err: os.NewSyscallError("recvfrom", syscall.ECONNREFUSED)},
+			want: ErrorClassRefused,
+		},
+		{
+			name: "Host unreachable",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.EHOSTUNREACH)},
+			want: ErrorClassUnreachable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetErrorClass(tt.err)
+
+			if got != tt.want {
+				t.Errorf("GetErrorClass() got = %s, want = %s\n", got, tt.want)
+			}
+		})
+	}
+}
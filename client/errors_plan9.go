@@ -0,0 +1,14 @@
+//go:build plan9
+
+package client
+
+// Plan 9 reports network errors as strings rather than errno values:
+// refused and unreachable servers are left unclassified.
+
+func isRefusedError(err error) bool {
+	return false
+}
+
+func isUnreachableError(err error) bool {
+	return false
+}
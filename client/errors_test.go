@@ -0,0 +1,143 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestGetErrorClass(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{
+			name: "Timeout",
+			err:  &net.OpError{Op: "read", Net: "udp", Err: os.ErrDeadlineExceeded},
+			want: ErrorClassTimeout,
+		},
+		{
+			name: "ID mismatch",
+			err:  newQueryError("UDP", ErrIDMismatch),
+			want: ErrorClassMismatch,
+		},
+		{
+			name: "Cookie mismatch",
+			err:  ErrCookieMismatch,
+			want: ErrorClassMismatch,
+		},
+		{
+			name: "Invalid SIG(0) signature",
+			err:  newQueryError("UDP", fmt.Errorf("verify SIG(0) signature: %w", dns.ErrInvalidSignature)),
+			want: ErrorClassMismatch,
+		},
+		{
+			name: "Malformed response",
+			err:  fmt.Errorf("decode DNS response: %w", dns.ErrInvalidMessage),
+			want: ErrorClassMalformed,
+		},
+		{
+			name: "Truncated TCP response",
+			err:  fmt.Errorf("failed to read DNS response: %w", io.ErrUnexpectedEOF),
+			want: ErrorClassMalformed,
+		},
+		{
+			name: "Other error",
+			err:  errors.New("something else"),
+			want: ErrorClassUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetErrorClass(tt.err)
+
+			if got != tt.want {
+				t.Errorf("GetErrorClass() got = %s, want = %s\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExchangeRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		retries   int
+		drops     int
+		wantError ErrorClass
+	}{
+		{
+			name:      "Lost query is retried",
+			retries:   1,
+			drops:     1,
+			wantError: ErrorClassUnknown,
+		},
+		{
+			name:      "Lost queries exhaust the retries",
+			retries:   1,
+			drops:     2,
+			wantError: ErrorClassTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries := 0
+			server := startTestServer(t, func(query []byte) [][]byte {
+				queries++
+				if queries <= tt.drops {
+					return nil
+				}
+				return [][]byte{buildTestResponse(t, query, getTestQueryID(query), false)}
+			}, nil)
+
+			client := NewClient(server.address)
+			client.Timeout = 200 * time.Millisecond
+			client.UDPSize = dns.MaxDNSMessageSizeOverUDP
+			client.Retries = tt.retries
+
+			_, err := client.Exchange(newTestQuery())
+
+			if tt.wantError != ErrorClassUnknown {
+				var queryErr *QueryError
+				if !errors.As(err, &queryErr) || queryErr.Class != tt.wantError {
+					t.Fatalf("Exchange() error = %v, want error class = %s\n", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange() unexpected error = %v\n", err)
+			}
+		})
+	}
+}
+
+func TestExchangeRefused(t *testing.T) {
+	// Find a free port, then close it so nothing listens on it
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen UDP: %v", err)
+	}
+	address := packetConn.LocalAddr().String()
+	packetConn.Close()
+
+	client := NewClient(address)
+	client.Timeout = 200 * time.Millisecond
+	client.Retries = 2
+
+	_, err = client.Exchange(newTestQuery())
+
+	if class := GetErrorClass(err); class != ErrorClassRefused {
+		t.Fatalf("Exchange() error = %v, class = %s, want class = %s\n", err, class, ErrorClassRefused)
+	}
+	if GetErrorClass(err).RetrySameServer() {
+		t.Errorf("RetrySameServer() got = true for a refused query\n")
+	}
+}
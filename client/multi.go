@@ -0,0 +1,58 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// ExchangeMulti sends all the questions of a query in a single message, which saves
+// round trips (ex. for PTR sweeps) toward servers known to support it.
+//
+// This is experimental: RFC 1035 allows several questions per message but almost
+// no server implements it. If the query fails, the server rejects it (FORMERR or NOTIMP)
+// or does not echo every question back, each question is sent in its own query instead.
+//
+// Parameters:
+//   - query: The DNS query to send, with one or more questions.
+//
+// Returns:
+//   - []Response: A single response covering every question, or one response per
+//     question, in the order of the questions, after falling back to individual queries.
+//   - error: If one of the individual queries could not be sent or no matching response was received.
+func (client *Client) ExchangeMulti(query dns.Message) (responses []Response, err error) {
+	if len(query.Questions) == 0 {
+		return nil, fmt.Errorf("query has no questions")
+	}
+
+	query.UpdateHeaderCounts()
+	if len(query.Questions) > 1 {
+		// Servers which choke on several questions may drop the query or reset the connection
+		// rather than answer with an error: then each question is sent on its own too
+		response, err := client.Exchange(query)
+		if err == nil && supportsMultipleQuestions(query, response.Message) {
+			return []Response{response}, nil
+		}
+	}
+
+	for _, question := range query.Questions {
+		singleQuery := query
+		singleQuery.Questions = []dns.Question{question}
+		singleQuery.UpdateHeaderCounts()
+
+		response, err := client.Exchange(singleQuery)
+		if err != nil {
+			return nil, fmt.Errorf("query %s: %w", question.Name, err)
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+func supportsMultipleQuestions(query dns.Message, response dns.Message) bool {
+	switch response.Header.Flags.ResponseCode {
+	case dns.FORMERR, dns.NOTIMP:
+		return false
+	}
+	return len(response.Questions) == len(query.Questions)
+}
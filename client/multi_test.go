@@ -0,0 +1,106 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestExchangeMulti(t *testing.T) {
+	tests := []struct {
+		name          string
+		udpHandler    func(t *testing.T) func(query []byte) [][]byte
+		wantResponses int
+	}{
+		{
+			name: "Server answers all questions at once",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					return [][]byte{buildTestResponse(t, query, getTestQueryID(query), false)}
+				}
+			},
+			wantResponses: 1,
+		},
+		{
+			name: "Server rejects multiple questions: fall back to one query per question",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					response := buildTestResponse(t, query, getTestQueryID(query), false)
+					if query[5] > 1 {
+						response[3] |= byte(dns.FORMERR)
+					}
+					return [][]byte{response}
+				}
+			},
+			wantResponses: 3,
+		},
+		{
+			name: "Server drops multiple questions: fall back to one query per question",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					if query[5] > 1 {
+						return nil
+					}
+					return [][]byte{buildTestResponse(t, query, getTestQueryID(query), false)}
+				}
+			},
+			wantResponses: 3,
+		},
+		{
+			name: "Server only answers the first question: fall back to one query per question",
+			udpHandler: func(t *testing.T) func(query []byte) [][]byte {
+				return func(query []byte) [][]byte {
+					message, err := dns.DecodeMessage(query)
+					if err != nil {
+						t.Errorf("test server: decode query: %v", err)
+						return nil
+					}
+					message.Header.Id = getTestQueryID(query)
+					message.Header.Flags.Response = true
+					message.Questions = message.Questions[:1]
+					message.Header.QuestionCount = 1
+					response, err := dns.EncodeMessage(message)
+					if err != nil {
+						t.Errorf("test server: encode response: %v", err)
+						return nil
+					}
+					return [][]byte{response}
+				}
+			},
+			wantResponses: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startTestServer(t, tt.udpHandler(t), nil)
+
+			client := NewClient(server.address)
+			client.Timeout = 200 * time.Millisecond
+
+			query := newTestQuery()
+			query.Questions = []dns.Question{
+				{Name: "1.2.0.192.in-addr.arpa.", QType: dns.PTR, QClass: dns.IN},
+				{Name: "2.2.0.192.in-addr.arpa.", QType: dns.PTR, QClass: dns.IN},
+				{Name: "3.2.0.192.in-addr.arpa.", QType: dns.PTR, QClass: dns.IN},
+			}
+
+			got, err := client.ExchangeMulti(query)
+
+			if err != nil {
+				t.Fatalf("ExchangeMulti() unexpected error = %v\n", err)
+			}
+			if len(got) != tt.wantResponses {
+				t.Fatalf("ExchangeMulti() responses got = %d, want = %d\n", len(got), tt.wantResponses)
+			}
+			if tt.wantResponses > 1 {
+				for i, response := range got {
+					if len(response.Message.Questions) != 1 || response.Message.Questions[0].Name != query.Questions[i].Name {
+						t.Errorf("ExchangeMulti() response %d questions got = %+v\n", i, response.Message.Questions)
+					}
+				}
+			}
+		})
+	}
+}
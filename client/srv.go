@@ -0,0 +1,52 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+var ErrServiceUnavailable = fmt.Errorf("service not available at this domain")
+
+// LookupSRV queries the SRV records of a service [RFC2782] and returns them in the order
+// their targets should be tried: by priority, then in a random order weighted by the records' weights.
+// A new order is drawn on every call, which spreads the load across the targets.
+//
+// Parameters:
+//   - service: The symbolic name of the service, ex. "sip" or "_sip".
+//   - proto: The protocol of the service, ex. "tcp" or "_tcp".
+//   - name: The domain the service is provided for, ex. "example.com.".
+//
+// Returns:
+//   - []dns.RDataSRV: The SRV records of the service, in selection order.
+//   - error: If the query failed, the server did not answer with NOERROR,
+//     or ErrServiceUnavailable if the domain says the service is not available.
+func (client *Client) LookupSRV(service string, proto string, name string) (records []dns.RDataSRV, err error) {
+	srvName := dns.GetSRVName(service, proto, name)
+
+	query, err := dns.CreateQueryMessage(srvName, dns.SRV, false)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.Exchange(query)
+	if err != nil {
+		return nil, err
+	}
+	if responseCode := response.Message.ResponseCode(); responseCode != dns.NOERROR {
+		return nil, fmt.Errorf("lookup %s: %s", srvName, dns.DNSRCode(responseCode))
+	}
+
+	for _, record := range response.Message.Answers {
+		// Answers may include the CNAME records that lead to the SRV RRset
+		if srv, ok := record.RData.(*dns.RDataSRV); ok && record.RType == dns.SRV {
+			records = append(records, *srv)
+		}
+	}
+
+	if dns.IsServiceUnavailable(records) {
+		return nil, fmt.Errorf("%w: %s", ErrServiceUnavailable, strings.TrimSuffix(srvName, "."))
+	}
+	return dns.SortSRV(records), nil
+}
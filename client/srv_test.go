@@ -0,0 +1,108 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func newTestSRVRecord(name string, priority uint16, weight uint16, target string) dns.ResourceRecord {
+	targetLength := len(target) + 1 // Length of the target in wire format
+	if target == "." {
+		targetLength = 1
+	}
+	return dns.ResourceRecord{
+		Name:     name,
+		RType:    dns.SRV,
+		RClass:   dns.IN,
+		TTL:      300,
+		RDLength: uint16(6 + targetLength),
+		RData:    &dns.RDataSRV{Priority: priority, Weight: weight, Port: 5060, Target: target},
+	}
+}
+
+func TestLookupSRV(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseCode uint16
+		answers      []dns.ResourceRecord
+		wantTargets  []string
+		wantError    error
+	}{
+		{
+			name:         "Targets sorted by priority",
+			responseCode: dns.NOERROR,
+			answers: []dns.ResourceRecord{
+				newTestSRVRecord("_sip._tcp.example.com.", 20, 0, "backup.example.com."),
+				newTestSRVRecord("_sip._tcp.example.com.", 10, 0, "sip.example.com."),
+			},
+			wantTargets: []string{"sip.example.com.", "backup.example.com."},
+			wantError:   nil,
+		},
+		{
+			name:         "No SRV records",
+			responseCode: dns.NOERROR,
+			answers:      nil,
+			wantTargets:  nil,
+			wantError:    nil,
+		},
+		{
+			name:         "Service not available",
+			responseCode: dns.NOERROR,
+			answers: []dns.ResourceRecord{
+				newTestSRVRecord("_sip._tcp.example.com.", 0, 0, "."),
+			},
+			wantError: ErrServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startTestServer(t, func(query []byte) [][]byte {
+				message, err := dns.DecodeMessage(query)
+				if err != nil {
+					t.Errorf("test server: decode query: %v", err)
+					return nil
+				}
+				if len(message.Questions) != 1 || message.Questions[0].Name != "_sip._tcp.example.com." || message.Questions[0].QType != dns.SRV {
+					t.Errorf("test server: question got = %+v", message.Questions)
+				}
+
+				message.Header.Flags.Response = true
+				message.Header.Flags.ResponseCode = tt.responseCode
+				message.Answers = tt.answers
+				message.Header.AnswerRRCount = uint16(len(tt.answers))
+
+				response, err := dns.EncodeMessage(message)
+				if err != nil {
+					t.Errorf("test server: encode response: %v", err)
+					return nil
+				}
+				return [][]byte{response}
+			}, nil)
+
+			client := NewClient(server.address)
+			client.Timeout = 200 * time.Millisecond
+
+			records, err := client.LookupSRV("sip", "tcp", "example.com.")
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("LookupSRV() error = %v, want = %v\n", err, tt.wantError)
+			}
+
+			var targets []string
+			for _, record := range records {
+				targets = append(targets, record.Target)
+			}
+			if len(targets) != len(tt.wantTargets) {
+				t.Fatalf("LookupSRV() got = %v, want = %v\n", targets, tt.wantTargets)
+			}
+			for i := range targets {
+				if targets[i] != tt.wantTargets[i] {
+					t.Errorf("LookupSRV() got = %v, want = %v\n", targets, tt.wantTargets)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+var (
+	ErrTransferRefused = fmt.Errorf("zone transfer refused by server")
+	ErrInvalidTransfer = fmt.Errorf("invalid zone transfer")
+)
+
+// TransferHandler receives the records of a zone transfer, one response message at a time.
+// Returning an error stops the transfer.
+type TransferHandler func(records []dns.ResourceRecord) error
+
+// AXFR requests a full transfer of the zone [RFC5936] over TCP and returns all its records,
+// starting and ending with the zone's SOA record.
+//
+// Parameters:
+//   - zone: The name of the zone to transfer, ex. "example.com.".
+//
+// Returns:
+//   - []dns.ResourceRecord: The records of the zone, in the order they were received.
+//   - error: If the transfer failed, was refused or did not end with the zone's SOA record.
+func (client *Client) AXFR(zone string) (records []dns.ResourceRecord, err error) {
+	err = client.AXFRStream(zone, func(messageRecords []dns.ResourceRecord) error {
+		records = append(records, messageRecords...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// AXFRStream requests a full transfer of the zone [RFC5936] over TCP and passes the records
+// of each response message to the handler as they arrive, so that large zones need not be held in memory.
+// The transfer is complete once the zone's SOA record is received a second time.
+//
+// The request is signed with the client's SIG(0) key if it has one, and with a SIG(0) server key
+// every response message must be signed by the server.
+//
+// Parameters:
+//   - zone: The name of the zone to transfer, ex. "example.com.".
+//   - handler: The function called with the records of each response message.
+//
+// Returns:
+//   - error: If the transfer failed, was refused, did not end with the zone's SOA record,
+//     or the handler returned an error.
+func (client *Client) AXFRStream(zone string, handler TransferHandler) error {
+	started := false
+	var serial uint32
+
+	return client.transfer(newTransferQuery(zone, dns.AXFR), func(message dns.Message) (done bool, err error) {
+		for _, record := range message.Answers {
+			switch {
+			case !started:
+				soa, ok := record.RData.(*dns.RDataSOA)
+				if record.RType != dns.SOA || !ok {
+					return false, fmt.Errorf("%w: first record is %s, not SOA", ErrInvalidTransfer, dns.DNSType(record.RType))
+				}
+				started = true
+				serial = soa.Serial
+			case done:
+				return false, fmt.Errorf("%w: records after the final SOA", ErrInvalidTransfer)
+			case record.RType == dns.SOA:
+				if soa, ok := record.RData.(*dns.RDataSOA); !ok || soa.Serial != serial {
+					// The zone changed during the transfer [RFC5936]
+					return false, fmt.Errorf("%w: final SOA serial does not match the first SOA serial", ErrInvalidTransfer)
+				}
+				done = true
+			}
+		}
+
+		if err = handler(message.Answers); err != nil {
+			return false, err
+		}
+		return done, nil
+	})
+}
+
+func newTransferQuery(zone string, qtype uint16) dns.Message {
+	query := dns.Message{
+		Questions: []dns.Question{
+			{Name: zone, QType: qtype, QClass: dns.IN},
+		},
+	}
+	query.UpdateHeaderCounts()
+	return query
+}
+
+// transfer sends a zone transfer request over TCP and passes each response message to
+// the handler until it reports the transfer is done. The timeout applies to each message.
+func (client *Client) transfer(query dns.Message, handle func(message dns.Message) (done bool, err error)) error {
+	query.Header.Id = dns.NewID()
+
+	data, err := client.encodeQuery(query)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.Dial("tcp", client.Server)
+	if err != nil {
+		return newQueryError("TCP", fmt.Errorf("failed to connect to DNS server: %w", err))
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(client.Timeout))
+	if err = writeTCPMessage(conn, data); err != nil {
+		return newQueryError("TCP", err)
+	}
+
+	for {
+		conn.SetDeadline(time.Now().Add(client.Timeout))
+
+		raw, err := readTCPMessage(conn)
+		if err != nil {
+			return newQueryError("TCP", err)
+		}
+		if !hasID(raw, query.Header.Id) {
+			return newQueryError("TCP", ErrIDMismatch)
+		}
+
+		message, err := dns.DecodeMessage(raw)
+		if err != nil {
+			return newQueryError("TCP", fmt.Errorf("decode DNS response: %w", err))
+		}
+
+		if client.SIG0ServerKey != nil {
+			if err = client.SIG0ServerKey.Verify(raw, data); err != nil {
+				return newQueryError("TCP", fmt.Errorf("verify SIG(0) signature: %w", err))
+			}
+		}
+
+		if responseCode := message.ResponseCode(); responseCode != dns.NOERROR {
+			return fmt.Errorf("%w: %s", ErrTransferRefused, dns.DNSRCode(responseCode))
+		}
+
+		done, err := handle(message)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// IXFRDiff is the difference between two versions of a zone [RFC1995].
+type IXFRDiff struct {
+	FromSerial uint32               // Serial of the zone before the change
+	ToSerial   uint32               // Serial of the zone after the change
+	Deleted    []dns.ResourceRecord // Records removed from the zone, excluding the SOA
+	Added      []dns.ResourceRecord // Records added to the zone, excluding the SOA
+}
+
+// IXFRResult holds the changes of a zone received in an incremental transfer.
+type IXFRResult struct {
+	Serial  uint32               // Current serial of the zone on the server
+	Diffs   []IXFRDiff           // Changes since the requested serial, oldest first
+	Full    bool                 // Whether the server sent the whole zone instead of the changes
+	Records []dns.ResourceRecord // The whole zone, starting and ending with its SOA record, if Full
+}
+
+// IXFR requests the changes of a zone since the given serial [RFC1995] over TCP.
+// The server can answer with the changes, with a single SOA record if the zone did not change,
+// or with the whole zone like an AXFR if it does not have the changes: check IXFRResult.Full.
+//
+// Parameters:
+//   - zone: The name of the zone to transfer, ex. "example.com.".
+//   - serial: The serial of the version of the zone the client has.
+//
+// Returns:
+//   - IXFRResult: The current serial of the zone and its changes, or the whole zone.
+//   - error: If the transfer failed, was refused or the sequence of records is invalid, ex. its changes
+//     do not start from the given serial.
+func (client *Client) IXFR(zone string, serial uint32) (result IXFRResult, err error) {
+	query := newTransferQuery(zone, dns.IXFR)
+	query.NameServers = []dns.ResourceRecord{
+		{
+			Name:     zone,
+			RType:    dns.SOA,
+			RClass:   dns.IN,
+			RDLength: 22, // Two root names and five 32 bit fields: only the serial is used
+			RData:    &dns.RDataSOA{MName: ".", RName: ".", Serial: serial},
+		},
+	}
+	query.UpdateHeaderCounts()
+
+	parser := &ixfrParser{requestedSerial: serial}
+	if err = client.transfer(query, parser.parseMessage); err != nil {
+		return IXFRResult{}, err
+	}
+	return parser.result, nil
+}
+
+type ixfrState int
+
+const (
+	ixfrStart     ixfrState = iota // Waiting for the first SOA
+	ixfrFirst                      // Got the first SOA: the next record tells the response format
+	ixfrFull                       // Receiving the whole zone
+	ixfrDeletions                  // Receiving the records deleted by a diff
+	ixfrAdditions                  // Receiving the records added by a diff
+	ixfrDone
+)
+
+// ixfrParser follows the sequence of records of an IXFR response.
+// An incremental response is the current SOA, then for each change the old SOA,
+// the deleted records, the new SOA and the added records, and the current SOA again.
+type ixfrParser struct {
+	requestedSerial uint32
+	state           ixfrState
+	result          IXFRResult
+}
+
+func (parser *ixfrParser) parseMessage(message dns.Message) (done bool, err error) {
+	for _, record := range message.Answers {
+		if parser.state == ixfrDone {
+			return false, fmt.Errorf("%w: records after the final SOA", ErrInvalidTransfer)
+		}
+		if err = parser.parseRecord(record); err != nil {
+			return false, err
+		}
+	}
+
+	if parser.state == ixfrFirst && !isNewerSerial(parser.result.Serial, parser.requestedSerial) {
+		// A single SOA record: the zone did not change
+		parser.result.Records = nil
+		parser.state = ixfrDone
+	}
+	return parser.state == ixfrDone, nil
+}
+
+func (parser *ixfrParser) parseRecord(record dns.ResourceRecord) error {
+	soa, isSOA := record.RData.(*dns.RDataSOA)
+	isSOA = isSOA && record.RType == dns.SOA
+
+	result := &parser.result
+	var diff *IXFRDiff
+	if len(result.Diffs) > 0 {
+		diff = &result.Diffs[len(result.Diffs)-1]
+	}
+
+	switch parser.state {
+	case ixfrStart:
+		if !isSOA {
+			return fmt.Errorf("%w: first record is %s, not SOA", ErrInvalidTransfer, dns.DNSType(record.RType))
+		}
+		result.Serial = soa.Serial
+		result.Records = []dns.ResourceRecord{record}
+		parser.state = ixfrFirst
+
+	case ixfrFirst:
+		switch {
+		case !isSOA:
+			result.Full = true
+			result.Records = append(result.Records, record)
+			parser.state = ixfrFull
+		case soa.Serial == result.Serial:
+			// The whole zone, with only its SOA record
+			result.Full = true
+			result.Records = append(result.Records, record)
+			parser.state = ixfrDone
+		case soa.Serial != parser.requestedSerial:
+			return fmt.Errorf("%w: first change is from serial %d, not the requested serial %d", ErrInvalidTransfer, soa.Serial, parser.requestedSerial)
+		default:
+			result.Records = nil
+			result.Diffs = append(result.Diffs, IXFRDiff{FromSerial: soa.Serial})
+			parser.state = ixfrDeletions
+		}
+
+	case ixfrFull:
+		result.Records = append(result.Records, record)
+		if isSOA {
+			if soa.Serial != result.Serial {
+				return fmt.Errorf("%w: final SOA serial does not match the first SOA serial", ErrInvalidTransfer)
+			}
+			parser.state = ixfrDone
+		}
+
+	case ixfrDeletions:
+		if isSOA {
+			diff.ToSerial = soa.Serial
+			parser.state = ixfrAdditions
+		} else {
+			diff.Deleted = append(diff.Deleted, record)
+		}
+
+	case ixfrAdditions:
+		switch {
+		case !isSOA:
+			diff.Added = append(diff.Added, record)
+		case soa.Serial == result.Serial && diff.ToSerial == result.Serial:
+			parser.state = ixfrDone
+		case soa.Serial != diff.ToSerial:
+			return fmt.Errorf("%w: change from serial %d does not follow the change to serial %d", ErrInvalidTransfer, soa.Serial, diff.ToSerial)
+		default:
+			result.Diffs = append(result.Diffs, IXFRDiff{FromSerial: soa.Serial})
+			parser.state = ixfrDeletions
+		}
+	}
+	return nil
+}
+
+// isNewerSerial reports whether serial a is newer than serial b, in serial number arithmetic [RFC1982].
+func isNewerSerial(a uint32, b uint32) bool {
+	return a != b && a-b < 1<<31
+}
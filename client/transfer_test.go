@@ -0,0 +1,324 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func newTestSOARecord(serial uint32) dns.ResourceRecord {
+	return dns.ResourceRecord{
+		Name:     "example.com.",
+		RType:    dns.SOA,
+		RClass:   dns.IN,
+		TTL:      3600,
+		RDLength: 56,
+		RData: &dns.RDataSOA{
+			MName:   "ns1.example.com.",
+			RName:   "admin.example.com.",
+			Serial:  serial,
+			Refresh: 7200,
+			Retry:   3600,
+			Expire:  1209600,
+			Minimum: 3600,
+		},
+	}
+}
+
+func newTestARecord(name string, ip string) dns.ResourceRecord {
+	return dns.ResourceRecord{
+		Name:     name,
+		RType:    dns.A,
+		RClass:   dns.IN,
+		TTL:      300,
+		RDLength: 4,
+		RData:    &dns.RDataA{IP: netip.MustParseAddr(ip)},
+	}
+}
+
+// buildTestTransferResponses returns one response message per group of records.
+func buildTestTransferResponses(t *testing.T, query []byte, responseCode uint16, groups ...[]dns.ResourceRecord) [][]byte {
+	t.Helper()
+
+	message, err := dns.DecodeMessage(query)
+	if err != nil {
+		t.Errorf("test server: decode query: %v", err)
+		return nil
+	}
+	if len(message.Questions) != 1 || (message.Questions[0].QType != dns.AXFR && message.Questions[0].QType != dns.IXFR) {
+		t.Errorf("test server: question got = %+v, want AXFR or IXFR", message.Questions)
+	}
+
+	responses := [][]byte{}
+	for _, records := range groups {
+		response := dns.Message{
+			Header: dns.Header{
+				Id:            message.Header.Id,
+				Flags:         dns.Flags{Response: true, Authoritative: true, ResponseCode: responseCode},
+				QuestionCount: 1,
+				AnswerRRCount: uint16(len(records)),
+			},
+			Questions: message.Questions,
+			Answers:   records,
+		}
+		data, err := dns.EncodeMessage(response)
+		if err != nil {
+			t.Errorf("test server: encode response: %v", err)
+			return nil
+		}
+		responses = append(responses, data)
+	}
+	return responses
+}
+
+func TestAXFR(t *testing.T) {
+	www := newTestARecord("www.example.com.", "192.0.2.1")
+	mail := newTestARecord("mail.example.com.", "192.0.2.2")
+
+	tests := []struct {
+		name         string
+		responseCode uint16
+		groups       [][]dns.ResourceRecord
+		wantRecords  int
+		wantError    error
+	}{
+		{
+			name: "Zone in a single message",
+			groups: [][]dns.ResourceRecord{
+				{newTestSOARecord(1), www, mail, newTestSOARecord(1)},
+			},
+			wantRecords: 4,
+			wantError:   nil,
+		},
+		{
+			name: "Zone across several messages",
+			groups: [][]dns.ResourceRecord{
+				{newTestSOARecord(1), www},
+				{},
+				{mail, newTestSOARecord(1)},
+			},
+			wantRecords: 4,
+			wantError:   nil,
+		},
+		{
+			name:         "Transfer refused",
+			responseCode: dns.REFUSED,
+			groups:       [][]dns.ResourceRecord{{}},
+			wantError:    ErrTransferRefused,
+		},
+		{
+			name: "First record is not SOA",
+			groups: [][]dns.ResourceRecord{
+				{www, newTestSOARecord(1)},
+			},
+			wantError: ErrInvalidTransfer,
+		},
+		{
+			name: "Zone changed during the transfer",
+			groups: [][]dns.ResourceRecord{
+				{newTestSOARecord(1), www},
+				{mail, newTestSOARecord(2)},
+			},
+			wantError: ErrInvalidTransfer,
+		},
+		{
+			name: "Records after the final SOA",
+			groups: [][]dns.ResourceRecord{
+				{newTestSOARecord(1), www, newTestSOARecord(1), mail},
+			},
+			wantError: ErrInvalidTransfer,
+		},
+		{
+			name: "Connection closed before the final SOA",
+			groups: [][]dns.ResourceRecord{
+				{newTestSOARecord(1), www},
+			},
+			wantError: io.EOF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startTestServer(t, nil, func(query []byte) [][]byte {
+				return buildTestTransferResponses(t, query, tt.responseCode, tt.groups...)
+			})
+
+			client := NewClient(server.address)
+			client.Timeout = 200 * time.Millisecond
+
+			got, err := client.AXFR("example.com.")
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("AXFR() error = %v, want error = %v\n", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AXFR() unexpected error = %v\n", err)
+			}
+			if len(got) != tt.wantRecords {
+				t.Fatalf("AXFR() records got = %d, want = %d\n", len(got), tt.wantRecords)
+			}
+			if got[0].RType != dns.SOA || got[len(got)-1].RType != dns.SOA {
+				t.Errorf("AXFR() records got = %+v, want SOA first and last\n", got)
+			}
+		})
+	}
+}
+
+func TestAXFRStream(t *testing.T) {
+	errStop := errors.New("stop")
+	server := startTestServer(t, nil, func(query []byte) [][]byte {
+		return buildTestTransferResponses(t, query, dns.NOERROR,
+			[]dns.ResourceRecord{newTestSOARecord(1), newTestARecord("www.example.com.", "192.0.2.1")},
+			[]dns.ResourceRecord{newTestARecord("mail.example.com.", "192.0.2.2"), newTestSOARecord(1)},
+		)
+	})
+
+	client := NewClient(server.address)
+	client.Timeout = 200 * time.Millisecond
+
+	messages := 0
+	err := client.AXFRStream("example.com.", func(records []dns.ResourceRecord) error {
+		messages++
+		return errStop
+	})
+
+	if err == nil || !errors.Is(err, errStop) {
+		t.Fatalf("AXFRStream() error = %v, want error = %v\n", err, errStop)
+	}
+	if messages != 1 {
+		t.Errorf("AXFRStream() handler calls got = %d, want = 1\n", messages)
+	}
+}
+
+func TestIXFR(t *testing.T) {
+	www := newTestARecord("www.example.com.", "192.0.2.1")
+	wwwNew := newTestARecord("www.example.com.", "192.0.2.10")
+	mail := newTestARecord("mail.example.com.", "192.0.2.2")
+
+	// The names of the SOA records are compressed against the question
+	compressedSOA := newTestSOARecord(3)
+	compressedSOA.RDLength = 34
+
+	tests := []struct {
+		name      string
+		groups    [][]dns.ResourceRecord
+		want      IXFRResult
+		wantError error
+	}{
+		{
+			name: "Zone did not change",
+			groups: [][]dns.ResourceRecord{
+				{newTestSOARecord(1)},
+			},
+			want: IXFRResult{Serial: 1},
+		},
+		{
+			name: "Incremental changes across several messages",
+			groups: [][]dns.ResourceRecord{
+				{newTestSOARecord(3), newTestSOARecord(1), www, newTestSOARecord(2), wwwNew},
+				{newTestSOARecord(2), newTestSOARecord(3), mail, newTestSOARecord(3)},
+			},
+			want: IXFRResult{
+				Serial: 3,
+				Diffs: []IXFRDiff{
+					{FromSerial: 1, ToSerial: 2, Deleted: []dns.ResourceRecord{www}, Added: []dns.ResourceRecord{wwwNew}},
+					{FromSerial: 2, ToSerial: 3, Added: []dns.ResourceRecord{mail}},
+				},
+			},
+		},
+		{
+			name: "Server sends the whole zone",
+			groups: [][]dns.ResourceRecord{
+				{newTestSOARecord(3), www},
+				{mail, newTestSOARecord(3)},
+			},
+			want: IXFRResult{
+				Serial:  3,
+				Full:    true,
+				Records: []dns.ResourceRecord{compressedSOA, www, mail, compressedSOA},
+			},
+		},
+		{
+			name: "Changes are not contiguous",
+			groups: [][]dns.ResourceRecord{
+				{newTestSOARecord(3), newTestSOARecord(1), newTestSOARecord(2), www, newTestSOARecord(4), newTestSOARecord(3), newTestSOARecord(3)},
+			},
+			wantError: ErrInvalidTransfer,
+		},
+		{
+			name: "First change is not from the requested serial",
+			groups: [][]dns.ResourceRecord{
+				{newTestSOARecord(3), newTestSOARecord(2), www, newTestSOARecord(3), wwwNew, newTestSOARecord(3)},
+			},
+			wantError: ErrInvalidTransfer,
+		},
+		{
+			name: "Whole zone ends with another serial",
+			groups: [][]dns.ResourceRecord{
+				{newTestSOARecord(3), www, newTestSOARecord(4)},
+			},
+			wantError: ErrInvalidTransfer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startTestServer(t, nil, func(query []byte) [][]byte {
+				message, err := dns.DecodeMessage(query)
+				if err != nil {
+					t.Errorf("test server: decode query: %v", err)
+					return nil
+				}
+				if len(message.NameServers) != 1 || message.NameServers[0].RData.(*dns.RDataSOA).Serial != 1 {
+					t.Errorf("test server: authority section got = %+v, want SOA with serial 1", message.NameServers)
+				}
+				return buildTestTransferResponses(t, query, dns.NOERROR, tt.groups...)
+			})
+
+			client := NewClient(server.address)
+			client.Timeout = 200 * time.Millisecond
+
+			got, err := client.IXFR("example.com.", 1)
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("IXFR() error = %v, want error = %v\n", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("IXFR() unexpected error = %v\n", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("IXFR() got = %+v, want = %+v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNewerSerial(t *testing.T) {
+	tests := []struct {
+		a, b uint32
+		want bool
+	}{
+		{a: 2, b: 1, want: true},
+		{a: 1, b: 2, want: false},
+		{a: 1, b: 1, want: false},
+		{a: 0, b: 0xFFFFFFFF, want: true}, // Wrapped around
+		{a: 0xFFFFFFFF, b: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isNewerSerial(tt.a, tt.b); got != tt.want {
+			t.Errorf("isNewerSerial(%d, %d) got = %v, want = %v\n", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+var ErrUpdateRejected = fmt.Errorf("update rejected by server")
+
+// Update sends a dynamic update request [RFC2136] to the server,
+// which should be the primary server of the zone or forward updates to it.
+//
+// The request is sent like any other query (see Exchange): sign it with
+// the client's SIG(0) key if the server requires authenticated updates.
+//
+// Parameters:
+//   - update: The update request to send.
+//
+// Returns:
+//   - Response: The decoded response and information about the exchange.
+//   - error: If the request could not be sent, or ErrUpdateRejected with the
+//     response code if the server did not apply the update (ex. NXRRSET when a prerequisite failed).
+func (client *Client) Update(update *dns.Update) (response Response, err error) {
+	message, err := update.Message()
+	if err != nil {
+		return Response{}, fmt.Errorf("build update message: %w", err)
+	}
+
+	response, err = client.Exchange(message)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if responseCode := response.Message.ResponseCode(); responseCode != dns.NOERROR {
+		return response, fmt.Errorf("%w: %s", ErrUpdateRejected, dns.DNSRCode(responseCode))
+	}
+	return response, nil
+}
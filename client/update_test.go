@@ -0,0 +1,82 @@
+package client
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseCode uint16
+		wantError    error
+	}{
+		{
+			name:         "Update applied",
+			responseCode: dns.NOERROR,
+			wantError:    nil,
+		},
+		{
+			name:         "Prerequisite failed",
+			responseCode: dns.NXRRSET,
+			wantError:    ErrUpdateRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startTestServer(t, func(query []byte) [][]byte {
+				message, err := dns.DecodeMessage(query)
+				if err != nil {
+					t.Errorf("test server: decode query: %v", err)
+					return nil
+				}
+				if message.Header.Flags.Opcode != dns.UPDATE {
+					t.Errorf("test server: opcode got = %s, want = UPDATE", dns.DNSOpCode(message.Header.Flags.Opcode))
+				}
+				if len(message.Questions) != 1 || message.Questions[0].Name != "example.com." || message.Questions[0].QType != dns.SOA {
+					t.Errorf("test server: zone section got = %+v", message.Questions)
+				}
+				if len(message.Answers) != 1 || len(message.NameServers) != 2 {
+					t.Errorf("test server: prerequisites got = %+v, updates got = %+v", message.Answers, message.NameServers)
+				}
+
+				response := buildTestResponse(t, query, getTestQueryID(query), false)
+				response[3] |= byte(tt.responseCode)
+				return [][]byte{response}
+			}, nil)
+
+			client := NewClient(server.address)
+			client.Timeout = 200 * time.Millisecond
+
+			update := dns.NewUpdate("example.com.")
+			update.RRsetNotExists("www.example.com.", dns.AAAA)
+			update.DeleteRRset("www.example.com.", dns.A)
+			update.Add(dns.ResourceRecord{
+				Name:  "www.example.com.",
+				RType: dns.A,
+				TTL:   300,
+				RData: &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")},
+			})
+
+			got, err := client.Update(update)
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Update() error = %v, want error = %v\n", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Update() unexpected error = %v\n", err)
+			}
+			if got.Message.Header.Flags.Opcode != dns.UPDATE {
+				t.Errorf("Update() response opcode got = %s\n", dns.DNSOpCode(got.Message.Header.Flags.Opcode))
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"text/tabwriter"
+
+	"github.com/mcombeau/dns-tools/client"
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// ednsUDPSize is the payload size an attacker would advertise to get the largest responses
+const ednsUDPSize = 4096
+
+type config struct {
+	dnsServer string
+	zone      string
+}
+
+type auditQuery struct {
+	name  string
+	qtype uint16
+	edns  bool
+}
+
+type auditResult struct {
+	query        auditQuery
+	querySize    int
+	responseSize int
+	truncated    bool
+	responseCode uint16
+	err          error
+}
+
+var auditQueries = []auditQuery{
+	{name: "ANY", qtype: dns.ALL, edns: false},
+	{name: "ANY", qtype: dns.ALL, edns: true},
+	{name: "DNSKEY", qtype: dns.DNSKEY, edns: false},
+	{name: "DNSKEY", qtype: dns.DNSKEY, edns: true},
+	{name: "TXT", qtype: dns.TXT, edns: false},
+	{name: "TXT", qtype: dns.TXT, edns: true},
+}
+
+func main() {
+	cfg, err := parseArgs()
+	if err != nil {
+		log.Fatalf("Failed to parse args: %v\n", err)
+	}
+
+	dnsClient := client.NewClient(cfg.dnsServer)
+	dnsClient.IgnoreTruncation = true
+
+	results := make([]auditResult, 0, len(auditQueries))
+	for _, query := range auditQueries {
+		results = append(results, runAuditQuery(dnsClient, cfg.zone, query))
+	}
+
+	printResults(cfg, results)
+}
+
+func parseArgs() (cfg config, err error) {
+	var port string
+	flag.StringVar(&port, "p", "53", "Specify the DNS server port")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: go run ./cmd/amp-audit [-p port] <server> <zone>\n")
+		fmt.Fprintf(os.Stderr, "Measures the response/request size ratio of the server for ANY, DNSKEY and TXT queries, with and without EDNS.\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  -h\tDisplay this help message\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(0)
+	}
+
+	if net.ParseIP(flag.Arg(0)) == nil {
+		return config{}, fmt.Errorf("invalid server IP address: %s", flag.Arg(0))
+	}
+	cfg.dnsServer = net.JoinHostPort(flag.Arg(0), port)
+	cfg.zone = flag.Arg(1)
+
+	return cfg, nil
+}
+
+// runAuditQuery sends a single query over UDP and measures the query and response sizes.
+// Truncated responses are measured as is: they are what an attacker would get over UDP.
+func runAuditQuery(dnsClient *client.Client, zone string, auditQuery auditQuery) (result auditResult) {
+	result.query = auditQuery
+
+	query, err := dns.CreateQueryMessage(zone, auditQuery.qtype, false)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	query.Header.Flags.RecursionDesired = false
+
+	if auditQuery.edns {
+		// Set the EDNS parameters here so the client sends them as is, and the OPT record counts in the query size
+		query.EDNS = &dns.EDNS{UDPSize: ednsUDPSize, DnssecOk: true}
+		query.UpdateHeaderCounts()
+	} else {
+		dnsClient.UDPSize = dns.MaxDNSMessageSizeOverUDP
+	}
+
+	data, err := dns.EncodeMessage(query)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	result.querySize = len(data)
+
+	response, err := dnsClient.Exchange(query)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	result.responseSize = response.Size
+	result.truncated = response.Message.Header.Flags.Truncated
+	result.responseCode = response.Message.ResponseCode()
+
+	return result
+}
+
+func printResults(cfg config, results []auditResult) {
+	fmt.Printf(";; Amplification audit of %s for %s\n\n", cfg.dnsServer, cfg.zone)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(writer, "TYPE\tEDNS\tQUERY\tRESPONSE\tRATIO\tSTATUS\t")
+
+	maxRatio := 0.0
+	for _, result := range results {
+		edns := "no"
+		if result.query.edns {
+			edns = fmt.Sprintf("%d do", ednsUDPSize)
+		}
+
+		if result.err != nil {
+			fmt.Fprintf(writer, "%s\t%s\t%d\t-\t-\terror: %v\t\n", result.query.name, edns, result.querySize, result.err)
+			continue
+		}
+
+		ratio := float64(result.responseSize) / float64(result.querySize)
+		maxRatio = max(maxRatio, ratio)
+
+		status := dns.DNSRCode(result.responseCode).String()
+		if result.truncated {
+			status += ", truncated"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%.1fx\t%s\t\n", result.query.name, edns, result.querySize, result.responseSize, ratio, status)
+	}
+	writer.Flush()
+
+	fmt.Printf("\n;; Maximum amplification factor: %.1fx\n", maxRatio)
+}
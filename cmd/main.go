@@ -1,109 +1,124 @@
 package main
 
 import (
-	"bufio"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"log"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/mcombeau/dns-tools/client"
 	"github.com/mcombeau/dns-tools/dns"
+	"github.com/mcombeau/dns-tools/recursive"
+	"github.com/mcombeau/dns-tools/sysconfig"
 )
 
+type config struct {
+	dnsResolver   string
+	resolvConf    *sysconfig.ResolvConf
+	domainOrIP    string
+	questionType  uint16
+	questionClass uint16
+	reverseQuery  bool
+	homographWarn bool
+	idnOut        bool
+	idnaPolicy    dns.IDNAPolicy
+	udpSize       uint16
+	dnssec        bool
+	cookie        bool
+	nsid          bool
+	clientSubnet  *dns.EDNSOptionClientSubnet
+	danePort      uint16
+	trace         bool
+}
+
 func main() {
-	dnsResolver, domain, questionType, reverseQuery, err := parseArgs()
+	cfg, err := parseArgs()
 	if err != nil {
 		log.Fatalf("Failed to parse args: %v\n", err)
 	}
 
-	query, err := dns.CreateDNSQuery(domain, questionType, reverseQuery)
-	if err != nil {
-		log.Fatalf("Failed to create DNS query: %v\n", err)
+	queryName := cfg.domainOrIP
+	if !cfg.reverseQuery {
+		queryName, err = cfg.idnaPolicy.ToASCII(cfg.domainOrIP)
+		if err != nil {
+			log.Fatalf("Failed to convert domain name: %v\n", err)
+		}
 	}
 
-	startTime := time.Now()
-
-	tcpQuery := false
-	response, err := sendDNSQuery("udp", dnsResolver, query)
-	if err != nil {
-		log.Fatalf("Failed to send DNS query over UDP: %v\n", err)
+	host := queryName
+	if cfg.danePort != 0 {
+		queryName = dns.GetTLSAName(cfg.danePort, "tcp", host)
+		cfg.questionType = dns.TLSA
 	}
 
-	decodedMessage, err := dns.DecodeMessage(response)
+	query, err := dns.CreateQueryMessage(queryName, cfg.questionType, cfg.reverseQuery)
 	if err != nil {
-		log.Fatalf("Failed to decode DNS response: %v\n", err)
+		log.Fatalf("Failed to create DNS query: %v\n", err)
 	}
+	query.Header.Flags.DnssecOk = cfg.dnssec
+	query.Questions[0].QClass = cfg.questionClass
 
-	if decodedMessage.Header.Flags.Truncated {
-		// If UDP response is truncated (i.e. larger than 512 bytes)
-		// fall back to TCP
-
-		tcpQuery = true
-		response, err := sendDNSQuery("tcp", dnsResolver, query)
-		if err != nil {
-			log.Fatalf("Failed to send DNS query over TCP: %v\n", err)
-		}
-
-		decodedMessage, err = dns.DecodeMessage(response)
-		if err != nil {
-			log.Fatalf("Failed to decode DNS response: %v\n", err)
-		}
+	if cfg.trace {
+		printTrace(cfg.domainOrIP, query.Questions[0])
+		return
 	}
 
-	queryTime := time.Since(startTime)
-
-	dns.PrintBasicQueryInfo(domain, questionType)
-	dns.PrintMessage(decodedMessage)
-	dns.PrintQueryInfo(dnsResolver, queryTime, tcpQuery, len(response))
-}
-
-func sendDNSQuery(transmissionProtocol string, server string, data []byte) (response []byte, err error) {
-	conn, err := net.Dial(transmissionProtocol, server)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to DNS server: %v", err)
+	dnsClient := client.NewClient(cfg.dnsResolver)
+	dnsClient.UDPSize = cfg.udpSize
+	if cfg.resolvConf != nil {
+		dnsClient.Timeout = cfg.resolvConf.Timeout
+		dnsClient.Retries = cfg.resolvConf.Attempts - 1
 	}
-	defer conn.Close()
-
-	if transmissionProtocol == "tcp" {
-		// Messages sent over TCP connections use server port 53 (decimal).
-		// The message is prefixed with a two byte length field which gives
-		// the message length, excluding the two byte length field.
-
-		length := uint16(len(data))    // ex. 00000001	00101100
-		highByte := byte(length >> 8)  // ex.			00000001
-		lowByte := byte(length & 0xFF) // ex. 			00101100
-
-		data = append([]byte{highByte, lowByte}, data...)
+	dnsClient.Cookies = cfg.cookie
+	if cfg.nsid {
+		dnsClient.Options = append(dnsClient.Options, &dns.EDNSOptionNSID{})
 	}
-
-	_, err = conn.Write(data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send DNS query: %v", err)
+	if cfg.clientSubnet != nil {
+		dnsClient.Options = append(dnsClient.Options, cfg.clientSubnet)
 	}
 
-	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
-
-	receivedResponse := [dns.MaxDNSMessageSize]byte{}
-	n, err := conn.Read(receivedResponse[:])
+	response, err := dnsClient.Exchange(query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read DNS response: %v", err)
+		log.Fatalf("Failed to send DNS query: %v\n", err)
 	}
 
-	if transmissionProtocol == "tcp" {
-		// Skip the first two length prefix bytes
-		response = receivedResponse[2:n]
+	dns.PrintBasicQueryInfo(cfg.domainOrIP, cfg.questionType)
+	if cfg.idnOut {
+		dns.PrintMessage(cfg.idnaPolicy.ToUnicodeMessage(response.Message))
 	} else {
-		response = receivedResponse[:n]
+		dns.PrintMessage(response.Message)
 	}
-
-	return response, nil
+	if cfg.homographWarn {
+		dns.PrintHomographWarnings(response.Message)
+	}
+	if cfg.dnssec {
+		dns.PrintDenialProof(response.Message)
+	}
+	if cfg.danePort != 0 {
+		checkDANE(host, cfg.danePort, response.Message)
+	}
+	dns.PrintQueryInfo(cfg.dnsResolver, response.Duration, response.TCP, response.Size)
 }
 
-func parseArgs() (dnsResolver string, domainOrIP string, questionType uint16, reverseQuery bool, err error) {
+func parseArgs() (cfg config, err error) {
 	reverseDNSQuery := flag.Bool("x", false, "Perform a reverse DNS query")
+	questionClass := flag.String("c", "IN", "Specify the query class, ex. CH for \"-c CH version.bind TXT\"")
+	homographWarn := flag.Bool("idn-warn", false, "Warn about mixed-script or confusable punycode labels")
+	idnOut := flag.Bool("idn-out", false, "Print the names of the response in their Unicode form")
+	idnaTransitional := flag.Bool("idna-transitional", false, "Use IDNA2003 transitional mapping for Unicode names (ex. \"ß\" to \"ss\")")
+	idnaSTD3 := flag.Bool("idna-std3", false, "Reject Unicode names with characters other than letters, digits and hyphens")
+	dnssec := flag.Bool("dnssec", false, "Request DNSSEC records by setting the DO bit")
+	cookie := flag.Bool("cookie", false, "Send a DNS cookie")
+	nsid := flag.Bool("nsid", false, "Request the name server identifier")
+	subnet := flag.String("subnet", "", "Send an EDNS client subnet, ex. 192.0.2.0/24")
+	udpSize := flag.Uint("bufsize", client.DefaultUDPSize, "Specify the EDNS UDP payload size to advertise (512 or less disables EDNS)")
+	danePort := flag.Uint("dane", 0, "Query the TLSA records of the TLS service on this port and check the server's certificate against them")
+	trace := flag.Bool("trace", false, "Resolve the name iteratively from the root servers and print the response of each server")
 
 	var server string
 	var port string
@@ -111,7 +126,7 @@ func parseArgs() (dnsResolver string, domainOrIP string, questionType uint16, re
 	flag.StringVar(&port, "p", "53", "Specify the DNS resolver server port")
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: go run main.go [-s server] [-p port] [-x] <domain_or_ip> [question_type]\n")
+		fmt.Fprintf(os.Stderr, "Usage: go run main.go [-s server] [-p port] [-x] [-c class] [-trace] [-dnssec] [-cookie] [-nsid] [-idn-warn] [-idn-out] [-idna-transitional] [-idna-std3] [-bufsize size] [-subnet addr/prefix] [-dane port] <domain_or_ip> [question_type]\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		fmt.Fprintf(os.Stderr, "  -h\tDisplay this help message\n")
 		flag.PrintDefaults()
@@ -124,54 +139,113 @@ func parseArgs() (dnsResolver string, domainOrIP string, questionType uint16, re
 		os.Exit(0)
 	}
 
-	domainOrIP = flag.Arg(0)
+	cfg.domainOrIP = flag.Arg(0)
 
-	questionType = dns.A // Default to A
+	cfg.questionType = dns.A // Default to A
 	if flag.NArg() == 2 {
-		questionType = dns.GetRecordTypeFromTypeString(flag.Arg(1))
+		cfg.questionType = dns.GetRecordTypeFromTypeString(flag.Arg(1))
+	}
+
+	cfg.questionClass = dns.GetClassFromClassString(*questionClass)
+	if cfg.questionClass == 0 {
+		return config{}, fmt.Errorf("invalid query class: %s", *questionClass)
 	}
 
-	reverseQuery = *reverseDNSQuery
+	cfg.reverseQuery = *reverseDNSQuery
+	cfg.homographWarn = *homographWarn
+	cfg.idnOut = *idnOut
+	cfg.idnaPolicy = dns.IDNAPolicy{
+		Transitional: *idnaTransitional,
+		UseSTD3Rules: *idnaSTD3,
+	}
+	cfg.dnssec = *dnssec
+	cfg.cookie = *cookie
+	cfg.nsid = *nsid
+	cfg.trace = *trace
+	if *udpSize > 65535 {
+		return config{}, fmt.Errorf("invalid UDP payload size: %d", *udpSize)
+	}
+	cfg.udpSize = uint16(*udpSize)
+
+	if *danePort > 65535 || (*danePort != 0 && cfg.reverseQuery) {
+		return config{}, fmt.Errorf("invalid DANE port: %d", *danePort)
+	}
+	cfg.danePort = uint16(*danePort)
+
+	if *subnet != "" {
+		cfg.clientSubnet, err = dns.ParseClientSubnet(*subnet)
+		if err != nil {
+			return config{}, err
+		}
+	}
 
-	dnsResolver, err = getDNSResolver(server, port)
+	cfg.dnsResolver, cfg.resolvConf, err = getDNSResolver(server, port)
 	if err != nil {
-		return "", "", 0, false, fmt.Errorf("get DNS resolver: %w", err)
+		return config{}, fmt.Errorf("get DNS resolver: %w", err)
 	}
 
-	return dnsResolver, domainOrIP, questionType, reverseQuery, nil
+	return cfg, nil
+}
+
+// printTrace resolves a question iteratively from the root servers, like "dig +trace",
+// and prints the records each server gave.
+func printTrace(domainOrIP string, question dns.Question) {
+	dns.PrintBasicQueryInfo(domainOrIP, question.QType)
+	_, trace, err := recursive.NewResolver().ResolveTrace(question.Name, question.QType)
+	fmt.Print(trace.String())
+	if err != nil {
+		log.Fatalf("Failed to resolve %s: %v\n", question.Name, err)
+	}
 }
 
-func getDNSResolver(server string, port string) (dnsResolver string, err error) {
+// getDNSResolver returns the address of the server to query: the given server, or else the
+// first system resolver, along with the system configuration its timeout and attempts come from.
+func getDNSResolver(server string, port string) (dnsResolver string, resolvConf *sysconfig.ResolvConf, err error) {
 	if server == "" {
-		server, err = getDefaultDNSResolver()
+		conf, err := sysconfig.Load()
 		if err != nil {
-			return "", fmt.Errorf("error getting default DNS resolver: %w", err)
+			return "", nil, fmt.Errorf("error getting default DNS resolver: %w", err)
 		}
+		resolvConf = &conf
+		server = conf.Nameservers[0]
 	}
-	return fmt.Sprintf("%s:%s", server, port), nil
+	return net.JoinHostPort(server, port), resolvConf, nil
 }
 
-func getDefaultDNSResolver() (server string, err error) {
-	file, err := os.Open("/etc/resolv.conf")
-	if err != nil {
-		return "", fmt.Errorf("cannot open /etc/resolv.conf: %w", err)
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if strings.HasPrefix(line, "nameserver") {
-			fields := strings.Fields(line)
-			if len(fields) > 1 {
-				return fields[1], nil
-			}
+// checkDANE connects to the TLS service and checks its certificate chain against the TLSA records of the response.
+func checkDANE(host string, port uint16, message dns.Message) {
+	records := []dns.RDataTLSA{}
+	for _, record := range message.Answers {
+		if tlsa, ok := record.RData.(*dns.RDataTLSA); ok && record.RType == dns.TLSA {
+			records = append(records, *tlsa)
 		}
 	}
+	if len(records) == 0 {
+		fmt.Println(";; DANE: no TLSA records")
+		return
+	}
 
-	if err = scanner.Err(); err != nil {
-		return "", fmt.Errorf("error reading /etc/resolv.conf: %w", err)
+	serverName := strings.TrimSuffix(host, ".")
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(serverName, strconv.Itoa(int(port))), &tls.Config{
+		ServerName: serverName,
+		// The chain is checked against the TLSA records instead
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		fmt.Printf(";; DANE: TLS connection failed: %v\n", err)
+		return
 	}
+	chain := conn.ConnectionState().PeerCertificates
+	conn.Close()
 
-	return "", fmt.Errorf("no nameserver found in /etc/resolv.conf")
+	record, err := dns.VerifyTLSA(records, chain, host, nil)
+	if err != nil {
+		fmt.Printf(";; DANE: FAILED: %v\n", err)
+		return
+	}
+	fmt.Printf(";; DANE: certificate matches TLSA %s\n", record.String())
+	if !message.Header.Flags.AuthenticatedData {
+		fmt.Println(";; WARNING: the TLSA records were not validated with DNSSEC (AD flag not set)")
+	}
 }
@@ -0,0 +1,99 @@
+// Package compat converts messages and resource records between this module's
+// dns package and the github.com/miekg/dns package, so that both libraries can be
+// used side by side (ex. sending queries with this module's client and handing the
+// responses to existing miekg-based code).
+//
+// Conversions go through the DNS wire format, so every record type either
+// library can decode is preserved, including types the other one does not know.
+package compat
+
+import (
+	"fmt"
+
+	miekgdns "github.com/miekg/dns"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// ToMiekg converts a Message into a miekg/dns Msg.
+//
+// Parameters:
+//   - message: The Message to convert.
+//
+// Returns:
+//   - *miekgdns.Msg: The equivalent miekg/dns message.
+//   - error: If the message cannot be encoded or miekg/dns cannot parse it.
+func ToMiekg(message dns.Message) (*miekgdns.Msg, error) {
+	data, err := dns.EncodeMessage(message)
+	if err != nil {
+		return nil, fmt.Errorf("encode message: %w", err)
+	}
+
+	msg := new(miekgdns.Msg)
+	if err = msg.Unpack(data); err != nil {
+		return nil, fmt.Errorf("unpack message with miekg/dns: %w", err)
+	}
+	return msg, nil
+}
+
+// FromMiekg converts a miekg/dns Msg into a Message.
+//
+// Parameters:
+//   - msg: The miekg/dns message to convert.
+//
+// Returns:
+//   - dns.Message: The equivalent Message.
+//   - error: If miekg/dns cannot pack the message or it cannot be decoded.
+func FromMiekg(msg *miekgdns.Msg) (dns.Message, error) {
+	data, err := msg.Pack()
+	if err != nil {
+		return dns.Message{}, fmt.Errorf("pack message with miekg/dns: %w", err)
+	}
+
+	message, err := dns.DecodeMessage(data)
+	if err != nil {
+		return dns.Message{}, fmt.Errorf("decode message: %w", err)
+	}
+	return message, nil
+}
+
+// ToMiekgRR converts a ResourceRecord into a miekg/dns RR.
+//
+// Parameters:
+//   - record: The ResourceRecord to convert.
+//
+// Returns:
+//   - miekgdns.RR: The equivalent miekg/dns resource record.
+//   - error: If the record cannot be encoded or miekg/dns cannot parse it.
+func ToMiekgRR(record dns.ResourceRecord) (miekgdns.RR, error) {
+	msg, err := ToMiekg(dns.Message{Answers: []dns.ResourceRecord{record}})
+	if err != nil {
+		return nil, err
+	}
+	if len(msg.Answer) != 1 {
+		return nil, fmt.Errorf("unexpected record count after conversion: %d", len(msg.Answer))
+	}
+	return msg.Answer[0], nil
+}
+
+// FromMiekgRR converts a miekg/dns RR into a ResourceRecord.
+//
+// Parameters:
+//   - rr: The miekg/dns resource record to convert.
+//
+// Returns:
+//   - dns.ResourceRecord: The equivalent ResourceRecord.
+//   - error: If miekg/dns cannot pack the record or it cannot be decoded.
+func FromMiekgRR(rr miekgdns.RR) (dns.ResourceRecord, error) {
+	msg := new(miekgdns.Msg)
+	msg.Answer = []miekgdns.RR{rr}
+
+	message, err := FromMiekg(msg)
+	if err != nil {
+		return dns.ResourceRecord{}, err
+	}
+	if len(message.Answers) != 1 {
+		return dns.ResourceRecord{}, fmt.Errorf("unexpected record count after conversion: %d", len(message.Answers))
+	}
+	return message.Answers[0], nil
+}
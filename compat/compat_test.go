@@ -0,0 +1,243 @@
+package compat
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+
+	miekgdns "github.com/miekg/dns"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestToMiekg(t *testing.T) {
+	message := dns.Message{
+		Header: dns.Header{
+			Id:            1234,
+			Flags:         dns.Flags{Response: true, RecursionDesired: true},
+			QuestionCount: 1,
+			AnswerRRCount: 1,
+		},
+		Questions: []dns.Question{
+			{Name: "example.com.", QType: dns.A, QClass: dns.IN},
+		},
+		Answers: []dns.ResourceRecord{
+			{
+				Name:     "example.com.",
+				RType:    dns.A,
+				RClass:   dns.IN,
+				TTL:      300,
+				RDLength: 4,
+				RData:    &dns.RDataA{IP: netip.AddrFrom4([4]byte{93, 184, 216, 34})},
+			},
+		},
+	}
+
+	got, err := ToMiekg(message)
+	if err != nil {
+		t.Fatalf("ToMiekg() unexpected error = %v\n", err)
+	}
+
+	if got.Id != 1234 || !got.Response || !got.RecursionDesired {
+		t.Errorf("ToMiekg() header got = %+v\n", got.MsgHdr)
+	}
+	if len(got.Question) != 1 || got.Question[0].Name != "example.com." || got.Question[0].Qtype != miekgdns.TypeA {
+		t.Fatalf("ToMiekg() question got = %v\n", got.Question)
+	}
+	if len(got.Answer) != 1 {
+		t.Fatalf("ToMiekg() answer count got = %d, want = 1\n", len(got.Answer))
+	}
+	a, ok := got.Answer[0].(*miekgdns.A)
+	if !ok {
+		t.Fatalf("ToMiekg() answer is not of type *miekgdns.A, got %T", got.Answer[0])
+	}
+	if !a.A.Equal(net.IPv4(93, 184, 216, 34)) || a.Hdr.Ttl != 300 {
+		t.Errorf("ToMiekg() answer got = %s\n", a.String())
+	}
+}
+
+func TestFromMiekg(t *testing.T) {
+	msg := new(miekgdns.Msg)
+	msg.SetQuestion("example.com.", miekgdns.TypeMX)
+	msg.Id = 4321
+	msg.Response = true
+	msg.Answer = []miekgdns.RR{
+		&miekgdns.MX{
+			Hdr:        miekgdns.RR_Header{Name: "example.com.", Rrtype: miekgdns.TypeMX, Class: miekgdns.ClassINET, Ttl: 3600},
+			Preference: 10,
+			Mx:         "mail.example.com.",
+		},
+	}
+
+	got, err := FromMiekg(msg)
+	if err != nil {
+		t.Fatalf("FromMiekg() unexpected error = %v\n", err)
+	}
+
+	if got.Header.Id != 4321 || !got.Header.Flags.Response {
+		t.Errorf("FromMiekg() header got = %+v\n", got.Header)
+	}
+	if len(got.Questions) != 1 || got.Questions[0].Name != "example.com." || got.Questions[0].QType != dns.MX {
+		t.Fatalf("FromMiekg() questions got = %+v\n", got.Questions)
+	}
+	if len(got.Answers) != 1 {
+		t.Fatalf("FromMiekg() answer count got = %d, want = 1\n", len(got.Answers))
+	}
+	if got.Answers[0].TTL != 3600 || got.Answers[0].RData.String() != "10 mail.example.com." {
+		t.Errorf("FromMiekg() answer got = %+v, RData = %s\n", got.Answers[0], got.Answers[0].RData.String())
+	}
+}
+
+func TestRRRoundTrip(t *testing.T) {
+	rr, err := miekgdns.NewRR("www.example.com. 300 IN CNAME example.com.")
+	if err != nil {
+		t.Fatalf("miekgdns.NewRR() unexpected error = %v\n", err)
+	}
+
+	record, err := FromMiekgRR(rr)
+	if err != nil {
+		t.Fatalf("FromMiekgRR() unexpected error = %v\n", err)
+	}
+	if record.Name != "www.example.com." || record.RType != dns.CNAME || record.RData.String() != "example.com." {
+		t.Errorf("FromMiekgRR() got = %+v, RData = %s\n", record, record.RData.String())
+	}
+
+	got, err := ToMiekgRR(record)
+	if err != nil {
+		t.Fatalf("ToMiekgRR() unexpected error = %v\n", err)
+	}
+	if !miekgdns.IsDuplicate(got, rr) {
+		t.Errorf("ToMiekgRR() got = %s, want = %s\n", got.String(), rr.String())
+	}
+}
+
+// TestSIG0Interop checks that a query signed by the dns package verifies with miekg/dns.
+func TestSIG0Interop(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ECDSA key: %v", err)
+	}
+	key := &dns.SIG0Key{
+		SIG0PublicKey: dns.SIG0PublicKey{
+			Name: "client.example.com.",
+			Key: dns.RDataDNSKEY{
+				Flags:     512,
+				Protocol:  dns.DNSKEYProtocol,
+				Algorithm: dns.ECDSAP256SHA256,
+				PublicKey: append(privateKey.X.FillBytes(make([]byte, 32)), privateKey.Y.FillBytes(make([]byte, 32))...),
+			},
+		},
+		Signer: privateKey,
+	}
+
+	query, err := dns.CreateQueryMessage("example.com.", dns.SOA, false)
+	if err != nil {
+		t.Fatalf("CreateQueryMessage() unexpected error = %v\n", err)
+	}
+	data, err := dns.EncodeMessage(query)
+	if err != nil {
+		t.Fatalf("EncodeMessage() unexpected error = %v\n", err)
+	}
+	signed, err := key.Sign(data, nil)
+	if err != nil {
+		t.Fatalf("Sign() unexpected error = %v\n", err)
+	}
+
+	msg := new(miekgdns.Msg)
+	if err := msg.Unpack(signed); err != nil {
+		t.Fatalf("miekg Unpack() unexpected error = %v\n", err)
+	}
+	if len(msg.Extra) != 1 {
+		t.Fatalf("miekg Unpack() additional section got = %v\n", msg.Extra)
+	}
+	sig, ok := msg.Extra[0].(*miekgdns.SIG)
+	if !ok {
+		t.Fatalf("miekg Unpack() additional record is not of type *miekgdns.SIG, got %T", msg.Extra[0])
+	}
+
+	miekgKey := &miekgdns.KEY{
+		DNSKEY: miekgdns.DNSKEY{
+			Hdr:       miekgdns.RR_Header{Name: key.Name, Rrtype: miekgdns.TypeKEY, Class: miekgdns.ClassINET},
+			Flags:     key.Key.Flags,
+			Protocol:  key.Key.Protocol,
+			Algorithm: key.Key.Algorithm,
+			PublicKey: base64.StdEncoding.EncodeToString(key.Key.PublicKey),
+		},
+	}
+	if sig.KeyTag != miekgKey.KeyTag() {
+		t.Errorf("Sign() key tag got = %d, want = %d\n", sig.KeyTag, miekgKey.KeyTag())
+	}
+	if err := sig.Verify(miekgKey, signed); err != nil {
+		t.Errorf("miekg Verify() unexpected error = %v\n", err)
+	}
+}
+
+func TestRoundTripMiekgRecords(t *testing.T) {
+	records := []string{
+		"example.com. 300 IN A 192.0.2.1",
+		"example.com. 300 IN AAAA 2001:db8::1",
+		"www.example.com. 300 IN CNAME example.com.",
+		"example.com. 300 IN NS ns1.example.com.",
+		"1.2.0.192.in-addr.arpa. 300 IN PTR host.example.com.",
+		"example.com. 300 IN MX 10 mail.example.com.",
+		`example.com. 300 IN TXT "v=spf1 -all" "second string"`,
+		`example.com. 300 IN HINFO "INTEL-386" "Windows"`,
+		"example.com. 300 IN SOA ns1.example.com. hostmaster.example.com. 2024010101 7200 3600 1209600 300",
+		"_sip._udp.example.com. 300 IN SRV 10 60 5060 sip.example.com.",
+		`example.com. 300 IN NAPTR 100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`,
+		`_http._tcp.example.com. 300 IN URI 10 1 "https://www.example.com/"`,
+		"example.com. 300 IN LOC 42 21 54.000 N 71 06 18.000 W -24.00m 30m 10000m 10m",
+		"example.com. 300 IN DS 60485 5 1 2BB183AF5F22588179A53B0A98631FAD1A292118",
+		"example.com. 300 IN DNSKEY 257 3 13 mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ==",
+		"example.com. 300 IN RRSIG A 13 2 300 20240201000000 20240101000000 12345 example.com. AQIDBA==",
+		"example.com. 300 IN NSEC a.example.com. A MX RRSIG NSEC TYPE1234",
+		"2t7b4g4vsa5smi47k61mv5bv1a22bojr.example.com. 300 IN NSEC3 1 1 12 AABBCCDD 2VPTU5TIMAMQTTGL4LUU9KG21E0AOR3S A RRSIG",
+		"example.com. 0 IN NSEC3PARAM 1 0 12 AABBCCDD",
+		"_443._tcp.example.com. 300 IN TLSA 3 1 1 0123456789ABCDEF",
+		"example.com. 300 IN CERT PKIX 12345 8 AQID",
+		"example.com. 300 IN CSYNC 66 3 A NS AAAA",
+		"example.com. 300 IN HTTPS 1 . alpn=h2,h3 ipv4hint=192.0.2.1",
+		"example.com. 300 IN TYPE731 \\# 3 DEADFF",
+	}
+
+	msg := new(miekgdns.Msg)
+	msg.SetQuestion("example.com.", miekgdns.TypeANY)
+	msg.Response = true
+	msg.Compress = true
+	for _, record := range records {
+		rr, err := miekgdns.NewRR(record)
+		if err != nil {
+			t.Fatalf("miekgdns.NewRR(%q) unexpected error = %v\n", record, err)
+		}
+		msg.Answer = append(msg.Answer, rr)
+	}
+	msg.SetEdns0(1232, true)
+
+	data, err := msg.Pack()
+	if err != nil {
+		t.Fatalf("Pack() unexpected error = %v\n", err)
+	}
+	if err = dns.CheckRoundTrip(data); err != nil {
+		t.Errorf("CheckRoundTrip() unexpected error = %v\n", err)
+	}
+
+	// The message encoded back must be readable by miekg/dns, with the same records
+	message, err := FromMiekg(msg)
+	if err != nil {
+		t.Fatalf("FromMiekg() unexpected error = %v\n", err)
+	}
+	got, err := ToMiekg(message)
+	if err != nil {
+		t.Fatalf("ToMiekg() unexpected error = %v\n", err)
+	}
+	for i, rr := range got.Answer {
+		if !strings.EqualFold(rr.String(), msg.Answer[i].String()) {
+			t.Errorf("ToMiekg() record %d got = %s, want = %s\n", i, rr, msg.Answer[i])
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package dns
+
+// Builders for query and response messages. Each method changes the message and returns it,
+// so calls can be chained, ex.:
+//
+//	query := dns.NewQuery("example.com.", dns.A).WithRecursion().WithEDNS(1232, true)
+//	response := dns.NewResponse(query).Answer(record).WithAuthoritative()
+//
+// The header's section counts and the flags carried by the EDNS parameters are kept
+// in sync with the message as it is built.
+
+// NewQuery returns a query message with a random ID for the given name and type in the IN class.
+// Recursion is not desired unless WithRecursion is called. A Unicode name is converted to its
+// ASCII form with DefaultIDNAPolicy, or kept as is if it is not valid: use IDNAPolicy.ToASCII
+// first to get the error.
+//
+// Parameters:
+//   - name: The domain name to query, ex. "example.com.".
+//   - qtype: The DNS record type to query.
+func NewQuery(name string, qtype uint16) *Message {
+	if asciiName, err := getASCIIName(name); err == nil {
+		name = asciiName
+	}
+
+	message := &Message{
+		Header: Header{Id: NewID()},
+		Questions: []Question{
+			{
+				Name:   name,
+				QType:  qtype,
+				QClass: IN,
+			},
+		},
+	}
+	message.UpdateHeaderCounts()
+	return message
+}
+
+// NewResponse returns an empty response to a query: the ID, opcode, question and
+// RD and CD flags of the query are copied [RFC1035] [RFC4035].
+// The query's EDNS parameters are not copied, since a response advertises its own.
+//
+// Parameters:
+//   - query: The query to respond to.
+func NewResponse(query *Message) *Message {
+	message := &Message{
+		Header: Header{
+			Id: query.Header.Id,
+			Flags: Flags{
+				Response:         true,
+				Opcode:           query.Header.Flags.Opcode,
+				RecursionDesired: query.Header.Flags.RecursionDesired,
+				CheckingDisabled: query.Header.Flags.CheckingDisabled,
+			},
+		},
+		Questions: append([]Question{}, query.Questions...),
+	}
+	message.UpdateHeaderCounts()
+	return message
+}
+
+// NewNotify returns a NOTIFY message with a random ID, telling the secondary servers of a zone
+// that it changed [RFC1996]. The zone's new SOA record may be added with Answer.
+//
+// Parameters:
+//   - zone: The name of the zone which changed, ex. "example.com.".
+func NewNotify(zone string) *Message {
+	message := &Message{
+		Header: Header{
+			Id:    NewID(),
+			Flags: Flags{Opcode: NOTIFY, Authoritative: true},
+		},
+		Questions: []Question{
+			{
+				Name:   zone,
+				QType:  SOA,
+				QClass: IN,
+			},
+		},
+	}
+	message.UpdateHeaderCounts()
+	return message
+}
+
+// WithID sets the message ID.
+func (message *Message) WithID(id uint16) *Message {
+	message.Header.Id = id
+	return message
+}
+
+// WithRecursion sets the RD flag, asking the server to answer the query recursively.
+func (message *Message) WithRecursion() *Message {
+	message.Header.Flags.RecursionDesired = true
+	return message
+}
+
+// WithCheckingDisabled sets the CD flag, asking the resolver not to validate the DNSSEC signatures [RFC4035].
+func (message *Message) WithCheckingDisabled() *Message {
+	message.Header.Flags.CheckingDisabled = true
+	return message
+}
+
+// WithEDNS adds EDNS(0) parameters to the message, or updates its existing ones [RFC6891].
+//
+// Parameters:
+//   - udpSize: The UDP payload size the sender can receive, ex. 1232.
+//   - dnssecOk: Whether to set the DO bit, to receive DNSSEC records [RFC3225].
+func (message *Message) WithEDNS(udpSize uint16, dnssecOk bool) *Message {
+	if message.EDNS == nil {
+		message.EDNS = &EDNS{}
+	}
+	message.EDNS.UDPSize = udpSize
+	message.EDNS.DnssecOk = dnssecOk
+	message.Header.Flags.DnssecOk = dnssecOk
+	message.UpdateHeaderCounts()
+	return message
+}
+
+// SetEDNS0 replaces the EDNS(0) parameters of the message [RFC6891], unlike WithEDNS which keeps
+// their options. The parameters are then read and changed through the EDNS field of the message.
+//
+// Parameters:
+//   - udpSize: The UDP payload size the sender can receive, ex. 1232.
+//   - dnssecOk: Whether to set the DO bit, to receive DNSSEC records [RFC3225].
+//   - options: The EDNS options, ex. &EDNSOptionNSID{}.
+func (message *Message) SetEDNS0(udpSize uint16, dnssecOk bool, options ...EDNSOption) *Message {
+	message.EDNS = &EDNS{UDPSize: udpSize, DnssecOk: dnssecOk, Options: options}
+	message.Header.Flags.DnssecOk = dnssecOk
+	message.UpdateHeaderCounts()
+	return message
+}
+
+// WithAuthoritative sets the AA flag of a response.
+func (message *Message) WithAuthoritative() *Message {
+	message.Header.Flags.Authoritative = true
+	return message
+}
+
+// WithRecursionAvailable sets the RA flag of a response.
+func (message *Message) WithRecursionAvailable() *Message {
+	message.Header.Flags.RecursionAvailable = true
+	return message
+}
+
+// WithResponseCode sets the response code, ex. NXDOMAIN. The upper 8 bits of extended
+// response codes, ex. BADCOOKIE, are written to the OPT record when the message is encoded [RFC6891].
+func (message *Message) WithResponseCode(rcode uint16) *Message {
+	message.Header.Flags.ResponseCode = rcode
+	return message
+}
+
+// AddQuestion appends the questions to the question section.
+// Most servers only answer queries with a single question.
+func (message *Message) AddQuestion(questions ...Question) *Message {
+	message.Questions = append(message.Questions, questions...)
+	message.UpdateHeaderCounts()
+	return message
+}
+
+// Answer appends the records to the answer section.
+func (message *Message) Answer(records ...ResourceRecord) *Message {
+	message.Answers = append(message.Answers, records...)
+	message.UpdateHeaderCounts()
+	return message
+}
+
+// Authority appends the records to the authority section.
+func (message *Message) Authority(records ...ResourceRecord) *Message {
+	message.NameServers = append(message.NameServers, records...)
+	message.UpdateHeaderCounts()
+	return message
+}
+
+// Additional appends the records to the additional section.
+// EDNS parameters are set with WithEDNS rather than as an OPT record.
+func (message *Message) Additional(records ...ResourceRecord) *Message {
+	message.Additionals = append(message.Additionals, records...)
+	message.UpdateHeaderCounts()
+	return message
+}
+
+// UpdateHeaderCounts sets the header's section counts from the sections of the message,
+// counting the OPT record of its EDNS parameters in the additional section.
+// The builder methods call it, and encoding sets the counts unless EncodeOptions.KeepHeaderCounts
+// is set, so it is only needed to print a message whose sections were changed directly.
+func (message *Message) UpdateHeaderCounts() {
+	message.Header.QuestionCount = uint16(len(message.Questions))
+	message.Header.AnswerRRCount = uint16(len(message.Answers))
+	message.Header.NameserverRRCount = uint16(len(message.NameServers))
+	message.Header.AdditionalRRCount = uint16(len(message.Additionals))
+	if message.EDNS != nil {
+		message.Header.AdditionalRRCount++
+	}
+}
@@ -0,0 +1,150 @@
+package dns
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestNewQuery(t *testing.T) {
+	query := NewQuery("example.com.", A).WithID(1).WithRecursion().WithEDNS(1232, true)
+
+	wantHeader := Header{
+		Id:                1,
+		Flags:             Flags{RecursionDesired: true, DnssecOk: true},
+		QuestionCount:     1,
+		AdditionalRRCount: 1,
+	}
+	if query.Header != wantHeader {
+		t.Errorf("NewQuery() header got = %+v, want = %+v\n", query.Header, wantHeader)
+	}
+	if !reflect.DeepEqual(query.EDNS, &EDNS{UDPSize: 1232, DnssecOk: true}) {
+		t.Errorf("WithEDNS() got = %+v, want UDP size 1232 and DO bit\n", query.EDNS)
+	}
+
+	// The header of the built query is the one of the query decoded from its encoding
+	data, err := query.Pack()
+	if err != nil {
+		t.Fatalf("Pack() unexpected error = %v\n", err)
+	}
+	decoded, err := DecodeMessage(data)
+	if err != nil {
+		t.Fatalf("DecodeMessage() unexpected error = %v\n", err)
+	}
+	if decoded.Header != query.Header || !reflect.DeepEqual(decoded.Questions, query.Questions) {
+		t.Errorf("DecodeMessage() got = %+v, want = %+v\n", decoded, *query)
+	}
+}
+
+func TestNewResponse(t *testing.T) {
+	query := NewQuery("example.com.", A).WithRecursion().WithCheckingDisabled().WithEDNS(1232, false)
+	record := ResourceRecord{Name: "example.com.", RType: A, RClass: IN, TTL: 300, RData: &RDataA{IP: netip.MustParseAddr("192.0.2.1")}}
+	soa := ResourceRecord{Name: "example.com.", RType: SOA, RClass: IN, TTL: 300, RData: &RDataSOA{MName: "ns.example.com.", RName: "hostmaster.example.com."}}
+
+	response := NewResponse(query).
+		WithAuthoritative().
+		WithRecursionAvailable().
+		Answer(record, record).
+		Authority(soa).
+		Additional(record).
+		WithEDNS(4096, false)
+
+	wantHeader := Header{
+		Id: query.Header.Id,
+		Flags: Flags{
+			Response:           true,
+			Authoritative:      true,
+			RecursionDesired:   true,
+			RecursionAvailable: true,
+			CheckingDisabled:   true,
+		},
+		QuestionCount:     1,
+		AnswerRRCount:     2,
+		NameserverRRCount: 1,
+		AdditionalRRCount: 2,
+	}
+	if response.Header != wantHeader {
+		t.Errorf("NewResponse() header got = %+v, want = %+v\n", response.Header, wantHeader)
+	}
+	if !reflect.DeepEqual(response.Questions, query.Questions) {
+		t.Errorf("NewResponse() questions got = %+v, want = %+v\n", response.Questions, query.Questions)
+	}
+	if response.EDNS == query.EDNS || response.EDNS.UDPSize != 4096 {
+		t.Errorf("WithEDNS() got = %+v, want the response's own EDNS parameters\n", response.EDNS)
+	}
+
+	// Changing the response's question must not change the query's
+	response.Questions[0].Name = "other.example."
+	if query.Questions[0].Name != "example.com." {
+		t.Errorf("NewResponse() shares its questions with the query\n")
+	}
+
+	nxdomain := NewResponse(query).WithResponseCode(NXDOMAIN)
+	if nxdomain.Header.Flags.ResponseCode != NXDOMAIN || nxdomain.Header.AdditionalRRCount != 0 {
+		t.Errorf("WithResponseCode() header got = %+v, want NXDOMAIN without EDNS\n", nxdomain.Header)
+	}
+}
+
+func TestNewNotify(t *testing.T) {
+	notify := NewNotify("example.com.").WithID(1)
+
+	wantHeader := Header{Id: 1, Flags: Flags{Opcode: NOTIFY, Authoritative: true}, QuestionCount: 1}
+	if notify.Header != wantHeader {
+		t.Errorf("NewNotify() header got = %+v, want = %+v\n", notify.Header, wantHeader)
+	}
+	wantQuestions := []Question{{Name: "example.com.", QType: SOA, QClass: IN}}
+	if !reflect.DeepEqual(notify.Questions, wantQuestions) {
+		t.Errorf("NewNotify() questions got = %+v, want = %+v\n", notify.Questions, wantQuestions)
+	}
+}
+
+func TestNewQueryUnicodeName(t *testing.T) {
+	if got := NewQuery("bücher.example.", A).Questions[0].Name; got != "xn--bcher-kva.example." {
+		t.Errorf("NewQuery() name got = %s, want = xn--bcher-kva.example.\n", got)
+	}
+	if got := NewQuery("WWW.Example.com.", A).Questions[0].Name; got != "WWW.Example.com." {
+		t.Errorf("NewQuery() name got = %s, want = WWW.Example.com.\n", got)
+	}
+}
+
+func TestUpdateHeaderCounts(t *testing.T) {
+	record := ResourceRecord{Name: "example.com.", RType: A, RClass: IN, TTL: 300, RData: &RDataA{IP: netip.MustParseAddr("192.0.2.1")}}
+	message := NewQuery("example.com.", A).AddQuestion(Question{Name: "example.com.", QType: AAAA, QClass: IN})
+	if message.Header.QuestionCount != 2 {
+		t.Errorf("AddQuestion() question count got = %d, want = 2\n", message.Header.QuestionCount)
+	}
+
+	// Sections changed directly
+	message.Answers = []ResourceRecord{record, record}
+	message.EDNS = &EDNS{UDPSize: 1232}
+	message.UpdateHeaderCounts()
+
+	want := Header{Id: message.Header.Id, QuestionCount: 2, AnswerRRCount: 2, AdditionalRRCount: 1}
+	if message.Header != want {
+		t.Errorf("UpdateHeaderCounts() header got = %+v, want = %+v\n", message.Header, want)
+	}
+}
+
+func TestSetEDNS0(t *testing.T) {
+	query := NewQuery("example.com.", A).WithEDNS(4096, true).SetEDNS0(1232, false, &EDNSOptionNSID{})
+
+	want := &EDNS{UDPSize: 1232, Options: []EDNSOption{&EDNSOptionNSID{}}}
+	if !reflect.DeepEqual(query.EDNS, want) {
+		t.Errorf("SetEDNS0() got = %+v, want = %+v\n", query.EDNS, want)
+	}
+	if query.Header.Flags.DnssecOk || query.Header.AdditionalRRCount != 1 {
+		t.Errorf("SetEDNS0() header got = %+v\n", query.Header)
+	}
+
+	data, err := query.Pack()
+	if err != nil {
+		t.Fatalf("Pack() unexpected error = %v\n", err)
+	}
+	decoded, err := DecodeMessage(data)
+	if err != nil {
+		t.Fatalf("DecodeMessage() unexpected error = %v\n", err)
+	}
+	if !isEqualEDNS(decoded.EDNS, want) {
+		t.Errorf("DecodeMessage() EDNS got = %+v, want = %+v\n", decoded.EDNS, want)
+	}
+}
@@ -0,0 +1,175 @@
+package dns
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+)
+
+// Canonical form and order of records [RFC4034], as signed by RRSIG records:
+//
+//   - Domain names are fully expanded: not compressed.
+//   - Owner names are in lowercase, and so are the domain names in the RDATA of the types
+//     NS, MD, MF, CNAME, SOA, MB, MG, MR, PTR, MINFO, MX, RP, AFSDB, RT, SIG, PX, NXT,
+//     NAPTR, KX, SRV, DNAME, A6 and RRSIG [RFC4034] [RFC6840]. Of these, this package
+//     decodes the RDATA of NS, CNAME, SOA, PTR, MX, SIG, NAPTR, SRV, DNAME and RRSIG records.
+//   - Names are ordered label by label from the rightmost, and the records of an RRset
+//     by their canonical RDATA compared as unsigned octet sequences. Duplicate records are removed.
+
+// CanonicalName returns a domain name in canonical form [RFC4034]: fully qualified and in lowercase.
+func CanonicalName(name string) string {
+	return strings.ToLower(Fqdn(name))
+}
+
+// CompareCanonicalNames compares two domain names in canonical order [RFC4034]:
+// label by label from the rightmost, case insensitively, a name sorting before its subdomains.
+//
+// Returns:
+//   - int: -1 if a sorts before b, 0 if they are equal, and +1 if a sorts after b.
+func CompareCanonicalNames(a string, b string) int {
+	switch c := compareCanonical(a, b); {
+	case c < 0:
+		return -1
+	case c > 0:
+		return 1
+	}
+	return 0
+}
+
+// CanonicalRecord returns a copy of a record in canonical form [RFC4034]: its owner name and the
+// domain names in its RDATA are in lowercase, and its RDLength is the length of its uncompressed RDATA.
+// The TTL is left as is: a record checked against an RRSIG record must be given its original TTL.
+//
+// Parameters:
+//   - record: The record to convert. It is not modified.
+//
+// Returns:
+//   - ResourceRecord: The record in canonical form.
+//   - error: If the RData of the record cannot be encoded.
+func CanonicalRecord(record ResourceRecord) (ResourceRecord, error) {
+	rdata := record.RData
+	if rdata == nil {
+		rdata = &RDataUnknown{}
+	}
+
+	// Decode the encoded RData to get a copy which can be modified
+	data, err := EncodeRData(rdata)
+	if err != nil {
+		return ResourceRecord{}, err
+	}
+	rdata, err = decodeRData(record.RType, data)
+	if err != nil {
+		return ResourceRecord{}, err
+	}
+
+	switch rdata := rdata.(type) {
+	case *RDataNS:
+		rdata.DomainName = strings.ToLower(rdata.DomainName)
+	case *RDataCNAME:
+		rdata.DomainName = strings.ToLower(rdata.DomainName)
+	case *RDataPTR:
+		rdata.DomainName = strings.ToLower(rdata.DomainName)
+	case *RDataDNAME:
+		rdata.Target = strings.ToLower(rdata.Target)
+	case *RDataSOA:
+		rdata.MName = strings.ToLower(rdata.MName)
+		rdata.RName = strings.ToLower(rdata.RName)
+	case *RDataMX:
+		rdata.Exchange = strings.ToLower(rdata.Exchange)
+	case *RDataNAPTR:
+		rdata.Replacement = strings.ToLower(rdata.Replacement)
+	case *RDataSRV:
+		rdata.Target = strings.ToLower(rdata.Target)
+	case *RDataRRSIG:
+		rdata.SignerName = strings.ToLower(rdata.SignerName)
+	}
+
+	record.Name = CanonicalName(record.Name)
+	record.RData = rdata
+	record.RDLength = uint16(len(data))
+	return record, nil
+}
+
+// EncodeCanonicalRecord returns the wire format of a record in canonical form [RFC4034]:
+// its owner name, type, class, TTL, RDLENGTH and RDATA, without name compression.
+//
+// Parameters:
+//   - record: The record to encode.
+//
+// Returns:
+//   - []byte: The encoded record in canonical form.
+//   - error: If the RData of the record cannot be encoded.
+func EncodeCanonicalRecord(record ResourceRecord) ([]byte, error) {
+	record, err := CanonicalRecord(record)
+	if err != nil {
+		return nil, err
+	}
+	rdata, err := EncodeRData(record.RData)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &dnsWriter{}
+	if err := writer.writeDomainName(record.Name); err != nil {
+		return nil, err
+	}
+	writer.writeUint16(record.RType)
+	writer.writeUint16(record.RClass)
+	writer.writeUint32(record.TTL)
+	writer.writeUint16(record.RDLength)
+	writer.writeData(rdata)
+	return writer.data, nil
+}
+
+// SortCanonical returns the records in canonical form, sorted in canonical order [RFC4034]:
+// by owner name, then class and type, so that the records of an RRset are together,
+// then by RDATA within an RRset. Duplicate records of an RRset are removed [RFC2181].
+//
+// Parameters:
+//   - records: The records to sort. They are not modified.
+//
+// Returns:
+//   - []ResourceRecord: The records in canonical form and order.
+//   - error: If the RData of a record cannot be encoded.
+func SortCanonical(records []ResourceRecord) ([]ResourceRecord, error) {
+	type canonicalRecord struct {
+		record ResourceRecord
+		rdata  []byte
+	}
+
+	canonicalRecords := make([]canonicalRecord, 0, len(records))
+	for _, record := range records {
+		canonical, err := CanonicalRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		rdata, err := EncodeRData(canonical.RData)
+		if err != nil {
+			return nil, err
+		}
+		canonicalRecords = append(canonicalRecords, canonicalRecord{canonical, rdata})
+	}
+
+	compare := func(a canonicalRecord, b canonicalRecord) int {
+		if c := CompareCanonicalNames(a.record.Name, b.record.Name); c != 0 {
+			return c
+		}
+		if a.record.RClass != b.record.RClass {
+			return int(a.record.RClass) - int(b.record.RClass)
+		}
+		if a.record.RType != b.record.RType {
+			return int(a.record.RType) - int(b.record.RType)
+		}
+		return bytes.Compare(a.rdata, b.rdata)
+	}
+	slices.SortStableFunc(canonicalRecords, compare)
+
+	sorted := make([]ResourceRecord, 0, len(canonicalRecords))
+	for i, canonical := range canonicalRecords {
+		if i > 0 && compare(canonicalRecords[i-1], canonical) == 0 {
+			continue
+		}
+		sorted = append(sorted, canonical.record)
+	}
+	return sorted, nil
+}
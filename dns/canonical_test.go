@@ -0,0 +1,124 @@
+package dns
+
+import (
+	"bytes"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalRecord(t *testing.T) {
+	tests := []struct {
+		name string
+		data ResourceRecord
+		want ResourceRecord
+	}{
+		{
+			name: "MX record",
+			data: ResourceRecord{Name: "Example.COM", RType: MX, RClass: IN, TTL: 300, RData: &RDataMX{Preference: 10, Exchange: "Mail.Example.COM."}},
+			want: ResourceRecord{Name: "example.com.", RType: MX, RClass: IN, TTL: 300, RDLength: 20, RData: &RDataMX{Preference: 10, Exchange: "mail.example.com."}},
+		},
+		{
+			name: "NSEC next domain name is left as is",
+			data: ResourceRecord{Name: "A.example.com.", RType: NSEC, RClass: IN, TTL: 300, RData: &RDataNSEC{NextDomainName: "B.example.com.", Types: []uint16{A}}},
+			want: ResourceRecord{Name: "a.example.com.", RType: NSEC, RClass: IN, TTL: 300, RDLength: 18, RData: &RDataNSEC{NextDomainName: "B.example.com.", Types: []uint16{A}}},
+		},
+		{
+			name: "TXT record is left as is",
+			data: ResourceRecord{Name: "example.com.", RType: TXT, RClass: IN, TTL: 300, RData: &RDataTXT{Text: []string{"Hello"}}},
+			want: ResourceRecord{Name: "example.com.", RType: TXT, RClass: IN, TTL: 300, RDLength: 6, RData: &RDataTXT{Text: []string{"Hello"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.data.RData.String()
+			got, err := CanonicalRecord(tt.data)
+			if err != nil {
+				t.Fatalf("CanonicalRecord() unexpected error = %v\n", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CanonicalRecord() got = %+v, want = %+v\n", got, tt.want)
+			}
+			if tt.data.RData.String() != original {
+				t.Errorf("CanonicalRecord() modified the RData of the record: %s\n", tt.data.RData.String())
+			}
+		})
+	}
+}
+
+func TestEncodeCanonicalRecord(t *testing.T) {
+	record := ResourceRecord{Name: "WWW.Example.", RType: CNAME, RClass: IN, TTL: 300, RData: &RDataCNAME{DomainName: "Example."}}
+	want := []byte{
+		3, 'w', 'w', 'w', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 0,
+		0, 5, 0, 1, 0, 0, 1, 0x2c, 0, 9,
+		7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 0,
+	}
+
+	got, err := EncodeCanonicalRecord(record)
+	if err != nil {
+		t.Fatalf("EncodeCanonicalRecord() unexpected error = %v\n", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("EncodeCanonicalRecord() got = %v, want = %v\n", got, want)
+	}
+}
+
+func TestSortCanonical(t *testing.T) {
+	newA := func(name string, ip string) ResourceRecord {
+		return ResourceRecord{Name: name, RType: A, RClass: IN, TTL: 300, RData: &RDataA{IP: netip.MustParseAddr(ip)}}
+	}
+	ns := ResourceRecord{Name: "example.", RType: NS, RClass: IN, TTL: 300, RData: &RDataNS{DomainName: "ns.example."}}
+
+	records := []ResourceRecord{
+		newA("z.example.", "192.0.2.1"),
+		newA("*.z.example.", "192.0.2.1"),
+		newA("a.example.", "192.0.2.10"),
+		ns,
+		newA("A.example.", "192.0.2.9"),
+		newA("a.example.", "192.0.2.10"),
+		newA("example.", "192.0.2.1"),
+	}
+	want := []ResourceRecord{
+		newA("example.", "192.0.2.1"),
+		ns,
+		newA("a.example.", "192.0.2.9"),
+		newA("a.example.", "192.0.2.10"),
+		newA("z.example.", "192.0.2.1"),
+		newA("*.z.example.", "192.0.2.1"),
+	}
+	for i := range want {
+		want[i].RDLength = uint16(len(mustEncodeRData(t, want[i].RData)))
+	}
+
+	got, err := SortCanonical(records)
+	if err != nil {
+		t.Fatalf("SortCanonical() unexpected error = %v\n", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SortCanonical() got = %v, want = %v\n", got, want)
+	}
+}
+
+func mustEncodeRData(t *testing.T, rdata RData) []byte {
+	t.Helper()
+	data, err := EncodeRData(rdata)
+	if err != nil {
+		t.Fatalf("EncodeRData() unexpected error = %v\n", err)
+	}
+	return data
+}
+
+func TestCompareCanonicalNames(t *testing.T) {
+	// Canonical order example of RFC 4034 section 6.1, without the names with escaped octets
+	names := []string{"example.", "a.example.", "yljkjljk.a.example.", "Z.a.example.", "zABC.a.EXAMPLE.", "z.example.", "*.z.example."}
+	for i := 0; i < len(names)-1; i++ {
+		if got := CompareCanonicalNames(names[i], names[i+1]); got != -1 {
+			t.Errorf("CompareCanonicalNames(%s, %s) got = %d, want = -1\n", names[i], names[i+1], got)
+		}
+	}
+	if got := CompareCanonicalNames("Example.", "example"); got != 0 {
+		t.Errorf("CompareCanonicalNames() got = %d, want = 0\n", got)
+	}
+}
@@ -0,0 +1,92 @@
+package dns
+
+import (
+	"bytes"
+	"slices"
+)
+
+// Child DNSSEC records [RFC7344][RFC8078]:
+// A child zone publishes the DS records it wants at its parent as CDS records, or the keys
+// they should refer to as CDNSKEY records. The parent, or its operator, polls them and updates
+// its DS RRset to match. A single CDS "0 0 0 00" or CDNSKEY "0 3 0 AA==" record asks the parent
+// to remove all DS records, turning DNSSEC off for the child.
+
+// IsDeleteCDS reports whether a CDS record asks the parent to delete the DS RRset [RFC8078].
+func IsDeleteCDS(rdata *RDataDS) bool {
+	return rdata.KeyTag == 0 && rdata.Algorithm == 0 && rdata.DigestType == 0 && bytes.Equal(rdata.Digest, []byte{0})
+}
+
+// IsDeleteCDNSKEY reports whether a CDNSKEY record asks the parent to delete the DS RRset [RFC8078].
+func IsDeleteCDNSKEY(rdata *RDataDNSKEY) bool {
+	return rdata.Flags == 0 && rdata.Protocol == DNSKEYProtocol && rdata.Algorithm == 0 && bytes.Equal(rdata.PublicKey, []byte{0})
+}
+
+// DiffCDS compares the DS RRset of the parent with the CDS RRset of the child.
+//
+// Parameters:
+//   - parent: The DS records at the parent.
+//   - cds: The CDS records at the child.
+//
+// Returns:
+//   - added: The CDS records with no identical DS record at the parent.
+//   - removed: The DS records with no identical CDS record at the child. All of them for a delete request.
+func DiffCDS(parent []RDataDS, cds []RDataDS) (added []RDataDS, removed []RDataDS) {
+	if len(cds) == 1 && IsDeleteCDS(&cds[0]) {
+		return nil, slices.Clone(parent)
+	}
+
+	for _, record := range cds {
+		if !slices.ContainsFunc(parent, func(ds RDataDS) bool { return isSameDS(ds, record) }) {
+			added = append(added, record)
+		}
+	}
+	for _, ds := range parent {
+		if !slices.ContainsFunc(cds, func(record RDataDS) bool { return isSameDS(ds, record) }) {
+			removed = append(removed, ds)
+		}
+	}
+	return added, removed
+}
+
+// DiffCDNSKEY compares the DS RRset of the parent with the CDNSKEY RRset of the child.
+// A key is at the parent if a DS record refers to it, whatever its digest type.
+//
+// Parameters:
+//   - owner: The name of the child zone, ex. "example.com.".
+//   - parent: The DS records at the parent.
+//   - cdnskeys: The CDNSKEY records at the child.
+//
+// Returns:
+//   - added: The CDNSKEY records no DS record at the parent refers to.
+//   - removed: The DS records which refer to none of the CDNSKEY records, including those with
+//     an unsupported digest type. All of them for a delete request.
+func DiffCDNSKEY(owner string, parent []RDataDS, cdnskeys []RDataDNSKEY) (added []RDataDNSKEY, removed []RDataDS) {
+	if len(cdnskeys) == 1 && IsDeleteCDNSKEY(&cdnskeys[0]) {
+		return nil, slices.Clone(parent)
+	}
+
+	for _, key := range cdnskeys {
+		if !slices.ContainsFunc(parent, func(ds RDataDS) bool { return isDSOfKey(owner, ds, &key) }) {
+			added = append(added, key)
+		}
+	}
+	for _, ds := range parent {
+		if !slices.ContainsFunc(cdnskeys, func(key RDataDNSKEY) bool { return isDSOfKey(owner, ds, &key) }) {
+			removed = append(removed, ds)
+		}
+	}
+	return added, removed
+}
+
+func isSameDS(a RDataDS, b RDataDS) bool {
+	return a.KeyTag == b.KeyTag && a.Algorithm == b.Algorithm && a.DigestType == b.DigestType && bytes.Equal(a.Digest, b.Digest)
+}
+
+// isDSOfKey reports whether the DS record refers to the key, computing the key's DS with the same digest type.
+func isDSOfKey(owner string, ds RDataDS, key *RDataDNSKEY) bool {
+	if ds.KeyTag != key.KeyTag() || ds.Algorithm != key.Algorithm {
+		return false
+	}
+	keyDS, err := key.DS(owner, ds.DigestType)
+	return err == nil && isSameDS(ds, *keyDS)
+}
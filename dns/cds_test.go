@@ -0,0 +1,191 @@
+package dns
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+func newTestCDSKeys(t *testing.T) (key RDataDNSKEY, otherKey RDataDNSKEY) {
+	t.Helper()
+
+	// DNSKEY record from RFC 4034 section 5.4
+	publicKey, _ := base64.StdEncoding.DecodeString("AQOeiiR0GOMYkDshWoSKz9XzfwJr1AYtsmx3TGkJaNXVbfi/2pHm822aJ5iI9BMzNXxeYCmZDRD99WYwYqUSdjMmmAphXdvxegXd/M5+X7OrzKBaMbCVdFLUUh6DhweJBjEVv5f2wwjM9XzcnOf+EPbtG9DMBmADjFDc2w/rljwvFw==")
+	key = RDataDNSKEY{Flags: DNSKEYFlagZone | DNSKEYFlagSecureEntryPoint, Protocol: DNSKEYProtocol, Algorithm: RSASHA1, PublicKey: publicKey}
+	otherKey = RDataDNSKEY{Flags: DNSKEYFlagZone | DNSKEYFlagSecureEntryPoint, Protocol: DNSKEYProtocol, Algorithm: ED25519, PublicKey: make([]byte, 32)}
+	return key, otherKey
+}
+
+func getTestDS(t *testing.T, key RDataDNSKEY, digestType uint8) RDataDS {
+	t.Helper()
+
+	ds, err := key.DS("example.com.", digestType)
+	if err != nil {
+		t.Fatalf("DS() unexpected error = %v\n", err)
+	}
+	return *ds
+}
+
+func TestDecodeDeleteRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		isDelete func(rdata RData) bool
+	}{
+		{
+			name: "CDS delete record",
+			data: []byte{
+				7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // Name: example.com.
+				0, 59, // RType: 59 (CDS)
+				0, 1, // RClass: 1
+				0, 0, 1, 44, // TTL: 300
+				0, 5, // RDLength: 5
+				0, 0, // Key tag: 0
+				0, // Algorithm: 0
+				0, // Digest type: 0
+				0, // Digest: 00
+			},
+			isDelete: func(rdata RData) bool {
+				cds, ok := rdata.(*RDataDS)
+				return ok && IsDeleteCDS(cds) && cds.String() == "0 0 0 00"
+			},
+		},
+		{
+			name: "CDNSKEY delete record",
+			data: []byte{
+				7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // Name: example.com.
+				0, 60, // RType: 60 (CDNSKEY)
+				0, 1, // RClass: 1
+				0, 0, 1, 44, // TTL: 300
+				0, 5, // RDLength: 5
+				0, 0, // Flags: 0
+				3, // Protocol: 3
+				0, // Algorithm: 0
+				0, // Public key: AA==
+			},
+			isDelete: func(rdata RData) bool {
+				cdnskey, ok := rdata.(*RDataDNSKEY)
+				return ok && IsDeleteCDNSKEY(cdnskey) && cdnskey.String() == "0 3 0 AA=="
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &dnsReader{data: tt.data}
+			record, err := reader.readResourceRecord()
+			if err != nil {
+				t.Fatalf("readResourceRecord() unexpected error = %v\n", err)
+			}
+			if !tt.isDelete(record.RData) {
+				t.Errorf("readResourceRecord() got = %T %s, want a delete record\n", record.RData, record.RData.String())
+			}
+		})
+	}
+}
+
+func TestDiffCDS(t *testing.T) {
+	key, otherKey := newTestCDSKeys(t)
+	keySHA1 := getTestDS(t, key, DSDigestSHA1)
+	keySHA256 := getTestDS(t, key, DSDigestSHA256)
+	otherKeySHA256 := getTestDS(t, otherKey, DSDigestSHA256)
+	deleteCDS := RDataDS{Digest: []byte{0}}
+
+	tests := []struct {
+		name        string
+		parent      []RDataDS
+		cds         []RDataDS
+		wantAdded   []RDataDS
+		wantRemoved []RDataDS
+	}{
+		{
+			name:   "Parent in sync",
+			parent: []RDataDS{keySHA256, keySHA1},
+			cds:    []RDataDS{keySHA1, keySHA256},
+		},
+		{
+			name:        "Key rollover",
+			parent:      []RDataDS{keySHA256},
+			cds:         []RDataDS{otherKeySHA256},
+			wantAdded:   []RDataDS{otherKeySHA256},
+			wantRemoved: []RDataDS{keySHA256},
+		},
+		{
+			name:      "Digest type added",
+			parent:    []RDataDS{keySHA1},
+			cds:       []RDataDS{keySHA1, keySHA256},
+			wantAdded: []RDataDS{keySHA256},
+		},
+		{
+			name:        "Delete request",
+			parent:      []RDataDS{keySHA1, keySHA256},
+			cds:         []RDataDS{deleteCDS},
+			wantRemoved: []RDataDS{keySHA1, keySHA256},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := DiffCDS(tt.parent, tt.cds)
+			if !reflect.DeepEqual(added, tt.wantAdded) {
+				t.Errorf("DiffCDS() added got = %v, want = %v\n", added, tt.wantAdded)
+			}
+			if !reflect.DeepEqual(removed, tt.wantRemoved) {
+				t.Errorf("DiffCDS() removed got = %v, want = %v\n", removed, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestDiffCDNSKEY(t *testing.T) {
+	key, otherKey := newTestCDSKeys(t)
+	keySHA1 := getTestDS(t, key, DSDigestSHA1)
+	keySHA256 := getTestDS(t, key, DSDigestSHA256)
+	unsupportedDigest := RDataDS{KeyTag: key.KeyTag(), Algorithm: key.Algorithm, DigestType: 3, Digest: []byte{1, 2, 3}}
+	deleteCDNSKEY := RDataDNSKEY{Protocol: DNSKEYProtocol, PublicKey: []byte{0}}
+
+	tests := []struct {
+		name        string
+		parent      []RDataDS
+		cdnskeys    []RDataDNSKEY
+		wantAdded   []RDataDNSKEY
+		wantRemoved []RDataDS
+	}{
+		{
+			name:     "Parent in sync with any digest type",
+			parent:   []RDataDS{keySHA1, keySHA256},
+			cdnskeys: []RDataDNSKEY{key},
+		},
+		{
+			name:        "Key rollover",
+			parent:      []RDataDS{keySHA256},
+			cdnskeys:    []RDataDNSKEY{otherKey},
+			wantAdded:   []RDataDNSKEY{otherKey},
+			wantRemoved: []RDataDS{keySHA256},
+		},
+		{
+			name:        "Unsupported digest type",
+			parent:      []RDataDS{keySHA256, unsupportedDigest},
+			cdnskeys:    []RDataDNSKEY{key},
+			wantRemoved: []RDataDS{unsupportedDigest},
+		},
+		{
+			name:        "Delete request",
+			parent:      []RDataDS{keySHA256},
+			cdnskeys:    []RDataDNSKEY{deleteCDNSKEY},
+			wantRemoved: []RDataDS{keySHA256},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := DiffCDNSKEY("example.com.", tt.parent, tt.cdnskeys)
+			if !reflect.DeepEqual(added, tt.wantAdded) {
+				t.Errorf("DiffCDNSKEY() added got = %v, want = %v\n", added, tt.wantAdded)
+			}
+			if !reflect.DeepEqual(removed, tt.wantRemoved) {
+				t.Errorf("DiffCDNSKEY() removed got = %v, want = %v\n", removed, tt.wantRemoved)
+			}
+		})
+	}
+}
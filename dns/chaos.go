@@ -0,0 +1,35 @@
+package dns
+
+// CHAOS class queries: servers answer TXT queries in the CH class for these names
+// with their software version or identity, ex. "dig CH TXT version.bind".
+const (
+	VersionBind   = "version.bind."   // Server software and version, for BIND and most others
+	HostnameBind  = "hostname.bind."  // Host name of the server
+	IDServer      = "id.server."      // Identity of the server, ex. of an anycast instance [RFC4892]
+	VersionServer = "version.server." // Server software and version [RFC4892]
+)
+
+// NewChaosQuery returns a TXT query in the CH class with a random ID, ex. for VersionBind.
+// Recursion is not desired: the name is answered by the server itself.
+//
+// Parameters:
+//   - name: The name to query, ex. VersionBind, HostnameBind or IDServer.
+func NewChaosQuery(name string) *Message {
+	message := NewQuery(name, TXT)
+	message.Questions[0].QClass = CH
+	return message
+}
+
+// GetChaosStrings returns the strings of the TXT records answering a CH class query, ex. the version of the server.
+//
+// Parameters:
+//   - message: The response to the query.
+func GetChaosStrings(message Message) []string {
+	texts := []string{}
+	for _, record := range message.Answers {
+		if txt, ok := record.RData.(*RDataTXT); ok && record.RType == TXT && record.RClass == CH {
+			texts = append(texts, txt.Text...)
+		}
+	}
+	return texts
+}
@@ -0,0 +1,64 @@
+package dns
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewChaosQuery(t *testing.T) {
+	query := NewChaosQuery(VersionBind)
+
+	wantQuestions := []Question{{Name: "version.bind.", QType: TXT, QClass: CH}}
+	if !reflect.DeepEqual(query.Questions, wantQuestions) {
+		t.Errorf("NewChaosQuery() questions got = %+v, want = %+v\n", query.Questions, wantQuestions)
+	}
+	if query.Header.QuestionCount != 1 || query.Header.Flags.RecursionDesired {
+		t.Errorf("NewChaosQuery() header got = %+v\n", query.Header)
+	}
+}
+
+func TestGetChaosStrings(t *testing.T) {
+	response := NewResponse(NewChaosQuery(VersionBind)).Answer(
+		ResourceRecord{Name: "version.bind.", RType: TXT, RClass: CH, RData: &RDataTXT{Text: []string{"9.18.24"}}},
+		ResourceRecord{Name: "version.bind.", RType: TXT, RClass: IN, RData: &RDataTXT{Text: []string{"IN class"}}},
+	)
+
+	// The record class is kept through the encoding
+	data, err := response.Pack()
+	if err != nil {
+		t.Fatalf("Pack() unexpected error = %v\n", err)
+	}
+	decoded, err := DecodeMessage(data)
+	if err != nil {
+		t.Fatalf("DecodeMessage() unexpected error = %v\n", err)
+	}
+
+	want := []string{"9.18.24"}
+	if got := GetChaosStrings(decoded); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetChaosStrings() got = %v, want = %v\n", got, want)
+	}
+	if got, want := decoded.Answers[0].String(), "version.bind.\t0\tCH\tTXT\t\"9.18.24\""; got != want {
+		t.Errorf("ResourceRecord.String() got = %q, want = %q\n", got, want)
+	}
+}
+
+func TestGetClassFromClassString(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want uint16
+	}{
+		{name: "IN", data: "IN", want: IN},
+		{name: "CH lowercase", data: "ch", want: CH},
+		{name: "CLASSn", data: "CLASS3", want: CH},
+		{name: "Unknown", data: "CHAOS", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetClassFromClassString(tt.data); got != tt.want {
+				t.Errorf("GetClassFromClassString() got = %d, want = %d\n", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package dns
 
+import "fmt"
+
 type DNSClass uint16
 
 const (
@@ -20,9 +22,19 @@ var dnsClassNames = map[uint16]string{
 	ANY:  "*",
 }
 
+// String returns the mnemonic of the class, or CLASSn for unknown classes [RFC3597].
 func (c DNSClass) String() string {
 	if n, ok := dnsClassNames[uint16(c)]; ok {
 		return n
 	}
-	return "UNKNOWN"
+	return fmt.Sprintf("CLASS%d", c)
+}
+
+// GetClassFromClassString returns the class of a mnemonic, ex. "CH", or of a CLASSn name [RFC3597].
+// Returns 0 if the class is unknown.
+func GetClassFromClassString(class string) uint16 {
+	if rclass, ok := parseClass(class); ok {
+		return rclass
+	}
+	return 0
 }
@@ -1,5 +1,7 @@
 package dns
 
+import "fmt"
+
 // ------------------- RCODES
 type DNSRCode uint16
 
@@ -20,8 +22,8 @@ const (
 	//	UNASSIGNED2 uint16 = 13 // Unassigned
 	//	UNASSIGNED3 uint16 = 14 // Unassigned
 	//	UNASSIGNED4 uint16 = 15 // Unassigned
-	BADVERS uint16 = 16 // Bad OPT Version [RFC6891]
-	//	BADSIG	  uint16 = 16 // TSIG Signature Failure [RFC8945] // BADVERS more common, so ignoring this for now
+	BADVERS   uint16 = 16 // Bad OPT Version [RFC6891]
+	BADSIG    uint16 = 16 // TSIG Signature Failure [RFC8945], same code as BADVERS
 	BADKEY    uint16 = 17 // Key not recognized [RFC8945]
 	BADTIME   uint16 = 18 // Signature out of time window [RFC8945]
 	BADMODE   uint16 = 19 // Bad TKEY Mode [RFC2930]
@@ -37,7 +39,7 @@ var dnsResponseCodeNames = map[uint16]string{
 	SERVFAIL:  "SERVFAIL",
 	NXDOMAIN:  "NXDOMAIN",
 	NOTIMP:    "NOTIMP",
-	REFUSED:   "REFUSEd",
+	REFUSED:   "REFUSED",
 	YXDOMAIN:  "YXDOMAIN",
 	YXRRSET:   "YXRRSET",
 	NXRRSET:   "NXRRSET",
@@ -48,8 +50,7 @@ var dnsResponseCodeNames = map[uint16]string{
 	// UNASSIGNED2: "Unassigned",
 	// UNASSIGNED3: "Unassigned",
 	// UNASSIGNED4: "Unassigned",
-	BADVERS: "BADVERS",
-	// BADSIG:      "BADSIG",
+	BADVERS:   "BADVERS", // Also BADSIG, in TSIG records [RFC8945]
 	BADKEY:    "BADKEY",
 	BADTIME:   "BADTIME",
 	BADMODE:   "BADMODE",
@@ -59,11 +60,13 @@ var dnsResponseCodeNames = map[uint16]string{
 	BADCOOKIE: "BADCOOKIE",
 }
 
+// String returns the mnemonic of the response code, or RCODEn for unassigned codes.
+// Codes above 15 are extended response codes, whose upper 8 bits are in the OPT record [RFC6891].
 func (responseCode DNSRCode) String() string {
 	if n, ok := dnsResponseCodeNames[uint16(responseCode)]; ok {
 		return n
 	}
-	return "UNKNOWN"
+	return fmt.Sprintf("RCODE%d", responseCode)
 }
 
 // ------------------- OPCODES
@@ -81,18 +84,18 @@ const (
 )
 
 var dnsOperationCodeNames = map[uint16]string{
-	QUERY:      "QUERY",
-	IQUERY:     "IQUERY",
-	STATUS:     "STATUS",
-	UNASSIGNED: "UNASSIGNED",
-	NOTIFY:     "NOTIFY",
-	UPDATE:     "UPDATE",
-	DSO:        "DSO",
+	QUERY:  "QUERY",
+	IQUERY: "IQUERY",
+	STATUS: "STATUS",
+	NOTIFY: "NOTIFY",
+	UPDATE: "UPDATE",
+	DSO:    "DSO",
 }
 
+// String returns the mnemonic of the opcode, or RESERVEDn for unassigned opcodes, as dig prints them.
 func (opCode DNSOpCode) String() string {
 	if n, ok := dnsOperationCodeNames[uint16(opCode)]; ok {
 		return n
 	}
-	return "UNKNOWN"
+	return fmt.Sprintf("RESERVED%d", opCode)
 }
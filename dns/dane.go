@@ -0,0 +1,209 @@
+package dns
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DNS-Based Authentication of Named Entities [RFC6698][RFC7671]:
+// The TLSA records at "_<port>._<protocol>.<host>" tell a TLS client which certificates
+// the server may present. Each record either pins the server's certificate (end entity, "EE" usages)
+// or a certificate of its chain (trust anchor, "TA" usages). The PKIX usages also require
+// the chain to pass the usual validation against the client's root CAs, while the DANE usages
+// replace that validation: the records must then be validated with DNSSEC to be trusted.
+
+// GetTLSAName returns the owner name of the TLSA records of a TLS service [RFC6698],
+// ex. "_443._tcp.www.example.com." for HTTPS at "www.example.com.".
+//
+// Parameters:
+//   - port: The port of the service.
+//   - proto: The transport protocol of the service, usually "tcp", with or without its leading underscore.
+//   - host: The name of the server.
+func GetTLSAName(port uint16, proto string, host string) string {
+	if !strings.HasSuffix(host, ".") {
+		host += "."
+	}
+	return "_" + strconv.Itoa(int(port)) + "._" + strings.TrimPrefix(proto, "_") + "." + host
+}
+
+// GetOPENPGPKEYName returns the owner name of the OPENPGPKEY records of an email address [RFC7929],
+// ex. "c93f1e400f26708f98cb19d936620da35eec8f72e57f9eec01c1afd6._openpgpkey.example.com." for "hugh@example.com".
+//
+// Parameters:
+//   - email: The email address. Its local part is hashed as is, without case folding.
+//
+// Returns:
+//   - string: The owner name of the records.
+//   - error: If the address has no local part or domain.
+func GetOPENPGPKEYName(email string) (string, error) {
+	return getEmailOwnerName(email, "_openpgpkey")
+}
+
+// GetSMIMEAName returns the owner name of the SMIMEA records of an email address [RFC8162],
+// ex. "c93f1e400f26708f98cb19d936620da35eec8f72e57f9eec01c1afd6._smimecert.example.com." for "hugh@example.com".
+//
+// Parameters:
+//   - email: The email address. Its local part is hashed as is, without case folding.
+//
+// Returns:
+//   - string: The owner name of the records.
+//   - error: If the address has no local part or domain.
+func GetSMIMEAName(email string) (string, error) {
+	return getEmailOwnerName(email, "_smimecert")
+}
+
+// getEmailOwnerName returns the SHA-256 hash of the local part truncated to 28 bytes in hexadecimal,
+// the label of the record type and the domain of the address.
+func getEmailOwnerName(email string, label string) (string, error) {
+	at := strings.LastIndex(email, "@")
+	if at < 1 || at == len(email)-1 {
+		return "", invalidDomainNameError(fmt.Sprintf("invalid email address: %s", email))
+	}
+
+	hash := sha256.Sum256([]byte(email[:at]))
+	domain := email[at+1:]
+	if !strings.HasSuffix(domain, ".") {
+		domain += "."
+	}
+	return hex.EncodeToString(hash[:28]) + "." + label + "." + domain, nil
+}
+
+// VerifyTLSA checks that a server's certificate chain matches one of the records of a TLSA RRset.
+// Records with unknown usages, selectors or matching types are unusable and ignored [RFC6698].
+//
+// The TLSA records' signatures must be validated separately: without DNSSEC, a match does not
+// authenticate the server.
+//
+// Parameters:
+//   - records: The records of the TLSA RRset of the service.
+//   - chain: The certificates presented by the server, starting with its own.
+//   - serverName: The name of the server, checked against its certificate for all usages except DANE-EE [RFC7671].
+//   - roots: The root CAs for PKIX validation, or nil for the system's roots.
+//
+// Returns:
+//   - *RDataTLSA: The first record that matches the chain.
+//   - error: ErrTLSAMismatch if no record matches the chain, or the matching records' chain validation failed.
+func VerifyTLSA(records []RDataTLSA, chain []*x509.Certificate, serverName string, roots *x509.CertPool) (*RDataTLSA, error) {
+	if len(chain) == 0 {
+		return nil, tlsaMismatchError("empty certificate chain")
+	}
+
+	var lastErr error
+	for i, record := range records {
+		if !isUsableTLSA(record) {
+			continue
+		}
+
+		var err error
+		switch record.Usage {
+		case TLSAUsageDANEEE:
+			// The certificate is the trust anchor itself: names and expiration are not checked [RFC7671]
+			if !matchesTLSA(record, chain[0]) {
+				continue
+			}
+		case TLSAUsageDANETA:
+			err = verifyDANETA(record, chain, serverName)
+		case TLSAUsagePKIXEE:
+			if !matchesTLSA(record, chain[0]) {
+				continue
+			}
+			_, err = verifyChain(chain, serverName, roots)
+		case TLSAUsagePKIXTA:
+			err = verifyPKIXTA(record, chain, serverName, roots)
+		}
+
+		if err == nil {
+			return &records[i], nil
+		}
+		lastErr = err
+	}
+
+	if lastErr != nil {
+		return nil, tlsaMismatchError(lastErr.Error())
+	}
+	return nil, tlsaMismatchError("no usable record matches")
+}
+
+func isUsableTLSA(record RDataTLSA) bool {
+	return record.Usage <= TLSAUsageDANEEE && record.Selector <= TLSASelectorSPKI && record.MatchingType <= TLSAMatchingSHA512
+}
+
+// matchesTLSA reports whether the selected content of the certificate matches the record.
+func matchesTLSA(record RDataTLSA, certificate *x509.Certificate) bool {
+	content := certificate.Raw
+	if record.Selector == TLSASelectorSPKI {
+		content = certificate.RawSubjectPublicKeyInfo
+	}
+
+	switch record.MatchingType {
+	case TLSAMatchingSHA256:
+		hash := sha256.Sum256(content)
+		content = hash[:]
+	case TLSAMatchingSHA512:
+		hash := sha512.Sum512(content)
+		content = hash[:]
+	}
+	return bytes.Equal(content, record.Data)
+}
+
+// verifyDANETA validates the chain up to a trust anchor matching the record, either a certificate
+// of the chain or, for a full certificate record, the certificate published in the record itself.
+func verifyDANETA(record RDataTLSA, chain []*x509.Certificate, serverName string) error {
+	anchors := []*x509.Certificate{}
+	for _, certificate := range chain[1:] {
+		if matchesTLSA(record, certificate) {
+			anchors = append(anchors, certificate)
+		}
+	}
+	if record.Selector == TLSASelectorCert && record.MatchingType == TLSAMatchingFull {
+		if certificate, err := x509.ParseCertificate(record.Data); err == nil {
+			anchors = append(anchors, certificate)
+		}
+	}
+	if len(anchors) == 0 {
+		return fmt.Errorf("no trust anchor matches %s", record.String())
+	}
+
+	roots := x509.NewCertPool()
+	for _, anchor := range anchors {
+		roots.AddCert(anchor)
+	}
+	_, err := verifyChain(chain, serverName, roots)
+	return err
+}
+
+// verifyPKIXTA validates the chain, and checks that one of the CAs of a validated chain matches the record.
+func verifyPKIXTA(record RDataTLSA, chain []*x509.Certificate, serverName string, roots *x509.CertPool) error {
+	verifiedChains, err := verifyChain(chain, serverName, roots)
+	if err != nil {
+		return err
+	}
+
+	for _, verifiedChain := range verifiedChains {
+		for _, certificate := range verifiedChain[1:] {
+			if matchesTLSA(record, certificate) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no CA matches %s", record.String())
+}
+
+func verifyChain(chain []*x509.Certificate, serverName string, roots *x509.CertPool) ([][]*x509.Certificate, error) {
+	intermediates := x509.NewCertPool()
+	for _, certificate := range chain[1:] {
+		intermediates.AddCert(certificate)
+	}
+
+	return chain[0].Verify(x509.VerifyOptions{
+		DNSName:       strings.TrimSuffix(serverName, "."),
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+}
@@ -0,0 +1,256 @@
+package dns
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// newTestCertificate creates a certificate signed by the parent, or a self-signed CA without parent.
+func newTestCertificate(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	} else {
+		template.DNSNames = []string{name}
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return certificate, key
+}
+
+func TestGetTLSAName(t *testing.T) {
+	if got := GetTLSAName(443, "tcp", "www.example.com"); got != "_443._tcp.www.example.com." {
+		t.Errorf("GetTLSAName() got = %v, want = %v\n", got, "_443._tcp.www.example.com.")
+	}
+	if got := GetTLSAName(25, "_tcp", "mail.example.com."); got != "_25._tcp.mail.example.com." {
+		t.Errorf("GetTLSAName() got = %v, want = %v\n", got, "_25._tcp.mail.example.com.")
+	}
+}
+
+func TestGetEmailOwnerNames(t *testing.T) {
+	tests := []struct {
+		name      string
+		getName   func(email string) (string, error)
+		email     string
+		want      string
+		wantError error
+	}{
+		{
+			name:    "OPENPGPKEY",
+			getName: GetOPENPGPKEYName,
+			email:   "hugh@example.com",
+			want:    "c93f1e400f26708f98cb19d936620da35eec8f72e57f9eec01c1afd6._openpgpkey.example.com.",
+		},
+		{
+			name:    "SMIMEA",
+			getName: GetSMIMEAName,
+			email:   "hugh@example.com.",
+			want:    "c93f1e400f26708f98cb19d936620da35eec8f72e57f9eec01c1afd6._smimecert.example.com.",
+		},
+		{
+			name:    "Local part is case sensitive",
+			getName: GetOPENPGPKEYName,
+			email:   "Hugh@example.com",
+			want:    "7063a398942ba5c6125429518d0608563f3974bb48013ddf58fb01d4._openpgpkey.example.com.",
+		},
+		{
+			name:      "No local part",
+			getName:   GetOPENPGPKEYName,
+			email:     "@example.com",
+			wantError: ErrInvalidDomainName,
+		},
+		{
+			name:      "No domain",
+			getName:   GetSMIMEAName,
+			email:     "hugh",
+			wantError: ErrInvalidDomainName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.getName(tt.email)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("getName() error = %v, want = %v\n", err, tt.wantError)
+			}
+			if got != tt.want {
+				t.Errorf("getName() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyTLSA(t *testing.T) {
+	ca, caKey := newTestCertificate(t, "Test CA", nil, nil)
+	leaf, _ := newTestCertificate(t, "www.example.com", ca, caKey)
+	otherCA, otherCAKey := newTestCertificate(t, "Other CA", nil, nil)
+	otherLeaf, _ := newTestCertificate(t, "www.example.com", otherCA, otherCAKey)
+
+	chain := []*x509.Certificate{leaf, ca}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	leafSPKISHA256 := sha256.Sum256(leaf.RawSubjectPublicKeyInfo)
+	leafSHA512 := sha512.Sum512(leaf.Raw)
+	caSHA256 := sha256.Sum256(ca.Raw)
+	otherLeafSHA256 := sha256.Sum256(otherLeaf.RawSubjectPublicKeyInfo)
+
+	daneEE := RDataTLSA{Usage: TLSAUsageDANEEE, Selector: TLSASelectorSPKI, MatchingType: TLSAMatchingSHA256, Data: leafSPKISHA256[:]}
+	daneTA := RDataTLSA{Usage: TLSAUsageDANETA, Selector: TLSASelectorCert, MatchingType: TLSAMatchingSHA256, Data: caSHA256[:]}
+	pkixEE := RDataTLSA{Usage: TLSAUsagePKIXEE, Selector: TLSASelectorCert, MatchingType: TLSAMatchingSHA512, Data: leafSHA512[:]}
+	pkixTA := RDataTLSA{Usage: TLSAUsagePKIXTA, Selector: TLSASelectorCert, MatchingType: TLSAMatchingSHA256, Data: caSHA256[:]}
+	otherDANEEE := RDataTLSA{Usage: TLSAUsageDANEEE, Selector: TLSASelectorSPKI, MatchingType: TLSAMatchingSHA256, Data: otherLeafSHA256[:]}
+	unusable := RDataTLSA{Usage: 4, Selector: TLSASelectorSPKI, MatchingType: TLSAMatchingSHA256, Data: leafSPKISHA256[:]}
+
+	tests := []struct {
+		name       string
+		records    []RDataTLSA
+		chain      []*x509.Certificate
+		serverName string
+		roots      *x509.CertPool
+		want       *RDataTLSA
+		wantError  error
+	}{
+		{
+			name:       "DANE-EE",
+			records:    []RDataTLSA{daneEE},
+			chain:      []*x509.Certificate{leaf},
+			serverName: "www.example.com.",
+			want:       &daneEE,
+		},
+		{
+			name:       "DANE-EE ignores the server name",
+			records:    []RDataTLSA{daneEE},
+			chain:      []*x509.Certificate{leaf},
+			serverName: "mail.example.com.",
+			want:       &daneEE,
+		},
+		{
+			name:       "DANE-TA in chain",
+			records:    []RDataTLSA{daneTA},
+			chain:      chain,
+			serverName: "www.example.com.",
+			want:       &daneTA,
+		},
+		{
+			name:       "DANE-TA published in record",
+			records:    []RDataTLSA{{Usage: TLSAUsageDANETA, Selector: TLSASelectorCert, MatchingType: TLSAMatchingFull, Data: ca.Raw}},
+			chain:      []*x509.Certificate{leaf},
+			serverName: "www.example.com.",
+			want:       &RDataTLSA{Usage: TLSAUsageDANETA, Selector: TLSASelectorCert, MatchingType: TLSAMatchingFull, Data: ca.Raw},
+		},
+		{
+			name:       "DANE-TA with wrong server name",
+			records:    []RDataTLSA{daneTA},
+			chain:      chain,
+			serverName: "mail.example.com.",
+			wantError:  ErrTLSAMismatch,
+		},
+		{
+			name:       "PKIX-EE",
+			records:    []RDataTLSA{pkixEE},
+			chain:      chain,
+			serverName: "www.example.com.",
+			roots:      roots,
+			want:       &pkixEE,
+		},
+		{
+			name:       "PKIX-EE without trusted root",
+			records:    []RDataTLSA{pkixEE},
+			chain:      chain,
+			serverName: "www.example.com.",
+			roots:      x509.NewCertPool(),
+			wantError:  ErrTLSAMismatch,
+		},
+		{
+			name:       "PKIX-TA",
+			records:    []RDataTLSA{pkixTA},
+			chain:      chain,
+			serverName: "www.example.com.",
+			roots:      roots,
+			want:       &pkixTA,
+		},
+		{
+			name:       "PKIX-TA with other CA",
+			records:    []RDataTLSA{pkixTA},
+			chain:      []*x509.Certificate{otherLeaf, otherCA},
+			serverName: "www.example.com.",
+			roots:      roots,
+			wantError:  ErrTLSAMismatch,
+		},
+		{
+			name:       "first matching record of RRset",
+			records:    []RDataTLSA{unusable, otherDANEEE, daneEE},
+			chain:      chain,
+			serverName: "www.example.com.",
+			want:       &daneEE,
+		},
+		{
+			name:       "no matching record",
+			records:    []RDataTLSA{otherDANEEE},
+			chain:      chain,
+			serverName: "www.example.com.",
+			wantError:  ErrTLSAMismatch,
+		},
+		{
+			name:       "only unusable records",
+			records:    []RDataTLSA{unusable},
+			chain:      chain,
+			serverName: "www.example.com.",
+			wantError:  ErrTLSAMismatch,
+		},
+		{
+			name:       "empty chain",
+			records:    []RDataTLSA{daneEE},
+			chain:      nil,
+			serverName: "www.example.com.",
+			wantError:  ErrTLSAMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyTLSA(tt.records, tt.chain, tt.serverName, tt.roots)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("VerifyTLSA() error = %v, want = %v\n", err, tt.wantError)
+			}
+			if tt.want == nil {
+				return
+			}
+			if got == nil || got.String() != tt.want.String() {
+				t.Errorf("VerifyTLSA() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
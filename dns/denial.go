@@ -0,0 +1,414 @@
+package dns
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Authenticated denial of existence [RFC4035][RFC5155]:
+// A signed zone proves that a name or type does not exist with NSEC or NSEC3 records
+// in the authority section of the negative response.
+// NSEC records chain the zone's names in canonical order, and NSEC3 records chain the hashes of the names:
+// a record "covers" a name that sorts between its owner and its next name, so that name does not exist.
+//
+// An NXDOMAIN proof shows that the queried name and the wildcard at its closest encloser
+// (its longest existing ancestor) do not exist. A NODATA proof shows that the queried name,
+// or the wildcard that matches it, exists without the queried type.
+
+// maxNSEC3Iterations is the number of additional NSEC3 hash iterations above which
+// a proof is not checked, as the hashing gets expensive for the validator [RFC9276].
+const maxNSEC3Iterations = 150
+
+// ------------------- DENIAL TYPES
+type DenialType int
+
+const (
+	DenialNXDOMAIN       DenialType = iota + 1 // The name does not exist
+	DenialNODATA                               // The name exists, but not with the queried type
+	DenialWildcardNODATA                       // The name matches a wildcard that does not have the queried type
+	DenialOptOut                               // The name may be an unsigned delegation in an NSEC3 opt-out span
+)
+
+var denialTypeNames = map[DenialType]string{
+	DenialNXDOMAIN:       "NXDOMAIN",
+	DenialNODATA:         "NODATA",
+	DenialWildcardNODATA: "wildcard NODATA",
+	DenialOptOut:         "opt-out",
+}
+
+func (denialType DenialType) String() string {
+	if n, ok := denialTypeNames[denialType]; ok {
+		return n
+	}
+	return "UNKNOWN"
+}
+
+// DenialProof describes how a negative response proves that the queried name or type does not exist.
+type DenialProof struct {
+	Type            DenialType
+	Name            string // The name proven not to exist or not to have the type, after following CNAME records
+	NSEC3           bool   // Whether the proof uses NSEC3 rather than NSEC records
+	ClosestEncloser string // The longest existing ancestor of the name, except for NODATA proofs
+}
+
+func (proof DenialProof) String() string {
+	method := "NSEC"
+	if proof.NSEC3 {
+		method = "NSEC3"
+	}
+	if proof.ClosestEncloser == "" {
+		return fmt.Sprintf("%s for %s proven by %s", proof.Type, proof.Name, method)
+	}
+	return fmt.Sprintf("%s for %s proven by %s, closest encloser %s", proof.Type, proof.Name, method, proof.ClosestEncloser)
+}
+
+// ProveDenial checks that an NXDOMAIN or NODATA response carries the NSEC or NSEC3 records
+// that prove the queried name or type does not exist. If the answer section holds a CNAME chain,
+// the proof is checked for the last name of the chain.
+//
+// Only the proof's structure is checked: the signatures of the NSEC or NSEC3 records
+// must be validated separately for the proof to be trusted.
+//
+// Parameters:
+//   - message: A negative response to a single question.
+//
+// Returns:
+//   - DenialProof: What the response proves.
+//   - error: ErrDenialNotProven if the response is not negative or its records do not prove the denial.
+func ProveDenial(message Message) (DenialProof, error) {
+	if len(message.Questions) != 1 {
+		return DenialProof{}, denialNotProvenError(fmt.Sprintf("%d questions", len(message.Questions)))
+	}
+	question := message.Questions[0]
+	name := getCNAMETarget(question.Name, message.Answers)
+
+	nxdomain := message.Header.Flags.ResponseCode == NXDOMAIN
+	if !nxdomain && (message.Header.Flags.ResponseCode != NOERROR || hasRecord(message.Answers, name, question.QType)) {
+		return DenialProof{}, denialNotProvenError("not a negative response")
+	}
+
+	nsecs := []ResourceRecord{}
+	nsec3s := []ResourceRecord{}
+	for _, record := range message.NameServers {
+		switch record.RData.(type) {
+		case *RDataNSEC:
+			nsecs = append(nsecs, record)
+		case *RDataNSEC3:
+			nsec3s = append(nsec3s, record)
+		}
+	}
+
+	var proof DenialProof
+	var err error
+	switch {
+	case len(nsec3s) > 0:
+		proof, err = proveNSEC3Denial(name, question.QType, nsec3s, nxdomain)
+	case len(nsecs) > 0:
+		proof, err = proveNSECDenial(name, question.QType, nsecs, nxdomain)
+	default:
+		return DenialProof{}, denialNotProvenError("no NSEC or NSEC3 records")
+	}
+	if err != nil {
+		return DenialProof{}, err
+	}
+
+	proof.Name = name
+	return proof, nil
+}
+
+// getCNAMETarget follows the CNAME records of the answer section from the name.
+func getCNAMETarget(name string, answers []ResourceRecord) string {
+	for range answers {
+		followed := false
+		for _, record := range answers {
+			if cname, ok := record.RData.(*RDataCNAME); ok && record.RType == CNAME && strings.EqualFold(record.Name, name) {
+				name = cname.DomainName
+				followed = true
+				break
+			}
+		}
+		if !followed {
+			break
+		}
+	}
+	return name
+}
+
+func hasRecord(records []ResourceRecord, name string, rtype uint16) bool {
+	for _, record := range records {
+		if record.RType == rtype && strings.EqualFold(record.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNODATATypes checks that a record matching the name proves it does not have the type.
+func checkNODATATypes(name string, qtype uint16, types []uint16) error {
+	for _, rtype := range types {
+		if rtype == qtype || rtype == CNAME {
+			return denialNotProvenError(fmt.Sprintf("%s has type %s", name, DNSType(rtype)))
+		}
+	}
+	if qtype != DS && hasType(types, NS) && !hasType(types, SOA) {
+		// A record from the parent side of a delegation only proves the absence of the DS type
+		return denialNotProvenError(fmt.Sprintf("%s is a delegation", name))
+	}
+	return nil
+}
+
+func hasType(types []uint16, rtype uint16) bool {
+	for _, t := range types {
+		if t == rtype {
+			return true
+		}
+	}
+	return false
+}
+
+// ------------------- NSEC
+
+func proveNSECDenial(name string, qtype uint16, records []ResourceRecord, nxdomain bool) (DenialProof, error) {
+	if !nxdomain {
+		for _, record := range records {
+			if strings.EqualFold(record.Name, name) {
+				if err := checkNODATATypes(name, qtype, record.RData.(*RDataNSEC).Types); err != nil {
+					return DenialProof{}, err
+				}
+				return DenialProof{Type: DenialNODATA}, nil
+			}
+		}
+	}
+
+	covering := findCoveringNSEC(name, records)
+	if covering == nil {
+		return DenialProof{}, denialNotProvenError(fmt.Sprintf("no NSEC covers %s", name))
+	}
+
+	if next := covering.RData.(*RDataNSEC).NextDomainName; !nxdomain && IsSubdomain(next, name) && !EqualNames(next, name) {
+		// The name is an empty non-terminal: it exists, as the ancestor of the next name, without records [RFC4035]
+		return DenialProof{Type: DenialNODATA}, nil
+	}
+
+	// The closest encloser is the longest ancestor the name shares with the covering record's names
+	closestEncloser := getCommonAncestor(name, covering.Name)
+	if next := getCommonAncestor(name, covering.RData.(*RDataNSEC).NextDomainName); CountLabels(next) > CountLabels(closestEncloser) {
+		closestEncloser = next
+	}
+	wildcard := getWildcardName(closestEncloser)
+
+	if nxdomain {
+		if findCoveringNSEC(wildcard, records) == nil {
+			return DenialProof{}, denialNotProvenError(fmt.Sprintf("no NSEC covers %s", wildcard))
+		}
+		return DenialProof{Type: DenialNXDOMAIN, ClosestEncloser: closestEncloser}, nil
+	}
+
+	for _, record := range records {
+		if strings.EqualFold(record.Name, wildcard) {
+			if err := checkNODATATypes(wildcard, qtype, record.RData.(*RDataNSEC).Types); err != nil {
+				return DenialProof{}, err
+			}
+			return DenialProof{Type: DenialWildcardNODATA, ClosestEncloser: closestEncloser}, nil
+		}
+	}
+	return DenialProof{}, denialNotProvenError(fmt.Sprintf("no NSEC matches %s or %s", name, wildcard))
+}
+
+func findCoveringNSEC(name string, records []ResourceRecord) *ResourceRecord {
+	for i, record := range records {
+		next := record.RData.(*RDataNSEC).NextDomainName
+		if isCovered(compareCanonical(record.Name, name), compareCanonical(name, next), compareCanonical(record.Name, next)) {
+			return &records[i]
+		}
+	}
+	return nil
+}
+
+// ------------------- NSEC3
+
+type nsec3Record struct {
+	hash  []byte
+	zone  string
+	rdata *RDataNSEC3
+}
+
+type nsec3Prover struct {
+	records       []nsec3Record
+	zone          string
+	hashAlgorithm uint8
+	iterations    uint16
+	salt          []byte
+}
+
+func proveNSEC3Denial(name string, qtype uint16, records []ResourceRecord, nxdomain bool) (DenialProof, error) {
+	prover, err := newNSEC3Prover(records)
+	if err != nil {
+		return DenialProof{}, err
+	}
+	if !IsSubdomain(name, prover.zone) {
+		return DenialProof{}, denialNotProvenError(fmt.Sprintf("%s is not in the NSEC3 zone %s", name, prover.zone))
+	}
+
+	if !nxdomain {
+		if record := prover.findMatching(name); record != nil {
+			if err := checkNODATATypes(name, qtype, record.rdata.Types); err != nil {
+				return DenialProof{}, err
+			}
+			return DenialProof{Type: DenialNODATA, NSEC3: true}, nil
+		}
+	}
+
+	closestEncloser, nextCloserCover, err := prover.proveClosestEncloser(name)
+	if err != nil {
+		return DenialProof{}, err
+	}
+	proof := DenialProof{NSEC3: true, ClosestEncloser: closestEncloser}
+	wildcard := getWildcardName(closestEncloser)
+
+	switch {
+	case nxdomain:
+		if prover.findCovering(wildcard) == nil {
+			return DenialProof{}, denialNotProvenError(fmt.Sprintf("no NSEC3 covers %s", wildcard))
+		}
+		proof.Type = DenialNXDOMAIN
+	case qtype == DS && nextCloserCover.rdata.Flags&NSEC3FlagOptOut != 0:
+		proof.Type = DenialOptOut
+	default:
+		record := prover.findMatching(wildcard)
+		if record == nil {
+			return DenialProof{}, denialNotProvenError(fmt.Sprintf("no NSEC3 matches %s or %s", name, wildcard))
+		}
+		if err := checkNODATATypes(wildcard, qtype, record.rdata.Types); err != nil {
+			return DenialProof{}, err
+		}
+		proof.Type = DenialWildcardNODATA
+	}
+	return proof, nil
+}
+
+// newNSEC3Prover decodes the hashed owner names of the records, which must all
+// belong to the same zone and use the same hash parameters.
+func newNSEC3Prover(records []ResourceRecord) (*nsec3Prover, error) {
+	prover := &nsec3Prover{}
+	for i, record := range records {
+		rdata := record.RData.(*RDataNSEC3)
+
+		hash, zone, err := ParseNSEC3HashedOwnerName(record.Name)
+		if err != nil {
+			return nil, denialNotProvenError(fmt.Sprintf("invalid NSEC3 owner name %s", record.Name))
+		}
+
+		if i == 0 {
+			prover.zone = zone
+			prover.hashAlgorithm = rdata.HashAlgorithm
+			prover.iterations = rdata.Iterations
+			prover.salt = rdata.Salt
+		} else if !strings.EqualFold(zone, prover.zone) || rdata.HashAlgorithm != prover.hashAlgorithm ||
+			rdata.Iterations != prover.iterations || !bytes.Equal(rdata.Salt, prover.salt) {
+			return nil, denialNotProvenError("NSEC3 records with different zones or parameters")
+		}
+
+		prover.records = append(prover.records, nsec3Record{hash: hash, zone: zone, rdata: rdata})
+	}
+
+	if prover.hashAlgorithm != NSEC3HashSHA1 {
+		return nil, fmt.Errorf("%w: NSEC3 hash algorithm %d", ErrUnsupportedAlgorithm, prover.hashAlgorithm)
+	}
+	if prover.iterations > maxNSEC3Iterations {
+		return nil, denialNotProvenError(fmt.Sprintf("too many NSEC3 iterations: %d", prover.iterations))
+	}
+	return prover, nil
+}
+
+// proveClosestEncloser finds the longest ancestor of the name that has a matching record,
+// and checks that the next closer name (one label longer, toward the name) is covered [RFC5155].
+func (prover *nsec3Prover) proveClosestEncloser(name string) (closestEncloser string, nextCloserCover *nsec3Record, err error) {
+	nextCloser := name
+	for candidate := getParentName(name); ; candidate = getParentName(candidate) {
+		if prover.findMatching(candidate) != nil {
+			nextCloserCover = prover.findCovering(nextCloser)
+			if nextCloserCover == nil {
+				return "", nil, denialNotProvenError(fmt.Sprintf("no NSEC3 covers the next closer name %s", nextCloser))
+			}
+			return candidate, nextCloserCover, nil
+		}
+		if strings.EqualFold(candidate, prover.zone) || candidate == "." {
+			return "", nil, denialNotProvenError(fmt.Sprintf("no NSEC3 matches an ancestor of %s", name))
+		}
+		nextCloser = candidate
+	}
+}
+
+func (prover *nsec3Prover) findMatching(name string) *nsec3Record {
+	hash := prover.hash(name)
+	for i, record := range prover.records {
+		if bytes.Equal(record.hash, hash) {
+			return &prover.records[i]
+		}
+	}
+	return nil
+}
+
+func (prover *nsec3Prover) findCovering(name string) *nsec3Record {
+	hash := prover.hash(name)
+	for i, record := range prover.records {
+		next := record.rdata.NextHashedOwnerName
+		if isCovered(bytes.Compare(record.hash, hash), bytes.Compare(hash, next), bytes.Compare(record.hash, next)) {
+			return &prover.records[i]
+		}
+	}
+	return nil
+}
+
+// hash computes the NSEC3 hash of a name with the parameters of the records.
+// The hash algorithm is checked when creating the prover, so hashing cannot fail.
+func (prover *nsec3Prover) hash(name string) []byte {
+	hash, _ := HashNSEC3Name(name, prover.hashAlgorithm, prover.iterations, prover.salt)
+	return hash
+}
+
+// ------------------- NAMES
+
+// getLabels returns the lowercase labels of a name, from the leftmost to the rightmost.
+func getLabels(name string) []string {
+	return SplitLabels(strings.ToLower(name))
+}
+
+// compareCanonical compares two names in canonical DNS order [RFC4034]:
+// label by label from the rightmost, case insensitively.
+func compareCanonical(a string, b string) int {
+	labelsA, labelsB := getLabels(a), getLabels(b)
+	for i := 1; i <= len(labelsA) && i <= len(labelsB); i++ {
+		if c := strings.Compare(labelsA[len(labelsA)-i], labelsB[len(labelsB)-i]); c != 0 {
+			return c
+		}
+	}
+	return len(labelsA) - len(labelsB)
+}
+
+// getCommonAncestor returns the longest name that both names are equal to or under.
+func getCommonAncestor(a string, b string) string {
+	labelsA, labelsB := getLabels(a), getLabels(b)
+	common := []string{}
+	for i := 1; i <= len(labelsA) && i <= len(labelsB) && labelsA[len(labelsA)-i] == labelsB[len(labelsB)-i]; i++ {
+		common = append([]string{labelsA[len(labelsA)-i]}, common...)
+	}
+	return strings.Join(common, ".") + "."
+}
+
+func getParentName(name string) string {
+	labels := getLabels(name)
+	if len(labels) <= 1 {
+		return "."
+	}
+	return strings.Join(labels[1:], ".") + "."
+}
+
+func getWildcardName(name string) string {
+	if name == "." {
+		return "*."
+	}
+	return "*." + name
+}
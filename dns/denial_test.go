@@ -0,0 +1,338 @@
+package dns
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"testing"
+)
+
+// nsec3TestZone lists the names of the test zone and their types.
+// w.example. is an empty non-terminal, which has an NSEC3 record but no NSEC record.
+var nsec3TestZone = map[string][]uint16{
+	"example.":     {NS, SOA, RRSIG, DNSKEY, NSEC3PARAM},
+	"a.example.":   {A, RRSIG},
+	"w.example.":   {},
+	"*.w.example.": {MX, RRSIG},
+	"x.w.example.": {A, RRSIG},
+}
+
+var nsecTestChain = []ResourceRecord{
+	newTestNSECRecord("example.", "a.example.", NS, SOA, RRSIG, NSEC, DNSKEY),
+	newTestNSECRecord("a.example.", "*.w.example.", A, RRSIG, NSEC),
+	newTestNSECRecord("*.w.example.", "x.w.example.", MX, RRSIG, NSEC),
+	newTestNSECRecord("x.w.example.", "example.", A, RRSIG, NSEC),
+}
+
+func newTestNSECRecord(name string, next string, types ...uint16) ResourceRecord {
+	return ResourceRecord{
+		Name:   name,
+		RType:  NSEC,
+		RClass: IN,
+		TTL:    3600,
+		RData:  &RDataNSEC{NextDomainName: next, Types: types},
+	}
+}
+
+func newTestNegativeResponse(name string, qtype uint16, rcode uint16, answers []ResourceRecord, authorities []ResourceRecord) Message {
+	return Message{
+		Header: Header{
+			Id:                1234,
+			Flags:             Flags{Response: true, Authoritative: true, ResponseCode: rcode},
+			QuestionCount:     1,
+			AnswerRRCount:     uint16(len(answers)),
+			NameserverRRCount: uint16(len(authorities)),
+		},
+		Questions:   []Question{{Name: name, QType: qtype, QClass: IN}},
+		Answers:     answers,
+		NameServers: authorities,
+	}
+}
+
+func hashTestNSEC3Name(name string, iterations uint16) []byte {
+	hash, _ := HashNSEC3Name(name, NSEC3HashSHA1, iterations, []byte{0xaa, 0xbb, 0xcc, 0xdd})
+	return hash
+}
+
+// newTestNSEC3Chain hashes the names of the test zone and chains them in hash order.
+func newTestNSEC3Chain(flags uint8, iterations uint16) []ResourceRecord {
+	salt := []byte{0xaa, 0xbb, 0xcc, 0xdd}
+
+	type hashedName struct {
+		hash  []byte
+		types []uint16
+	}
+	hashedNames := []hashedName{}
+	for name, types := range nsec3TestZone {
+		hashedNames = append(hashedNames, hashedName{hash: hashTestNSEC3Name(name, iterations), types: types})
+	}
+	sort.Slice(hashedNames, func(i, j int) bool {
+		return bytes.Compare(hashedNames[i].hash, hashedNames[j].hash) < 0
+	})
+
+	chain := []ResourceRecord{}
+	for i, hashed := range hashedNames {
+		next := hashedNames[(i+1)%len(hashedNames)].hash
+		chain = append(chain, ResourceRecord{
+			Name:   GetNSEC3HashedOwnerName(hashed.hash, "example."),
+			RType:  NSEC3,
+			RClass: IN,
+			TTL:    3600,
+			RData: &RDataNSEC3{
+				HashAlgorithm:       NSEC3HashSHA1,
+				Flags:               flags,
+				Iterations:          iterations,
+				Salt:                salt,
+				NextHashedOwnerName: next,
+				Types:               hashed.types,
+			},
+		})
+	}
+	return chain
+}
+
+// pickNSEC3Records returns the records of the chain that match or cover each of the names,
+// as an authoritative server would include them in a negative response.
+func pickNSEC3Records(chain []ResourceRecord, names ...string) []ResourceRecord {
+	iterations := chain[0].RData.(*RDataNSEC3).Iterations
+
+	records := []ResourceRecord{}
+	for _, name := range names {
+		hash := GetNSEC3HashedOwnerName(hashTestNSEC3Name(name, iterations), "example.")
+
+		// The chain is sorted: the record covering a hash is the last one before it, or the last one of the chain
+		picked := chain[len(chain)-1]
+		for _, record := range chain {
+			if record.Name > hash {
+				break
+			}
+			picked = record
+		}
+		records = append(records, picked)
+	}
+	return records
+}
+
+func TestProveNSECDenial(t *testing.T) {
+	alias := ResourceRecord{
+		Name:     "alias.example.",
+		RType:    CNAME,
+		RClass:   IN,
+		TTL:      3600,
+		RDLength: 11,
+		RData:    &RDataCNAME{DomainName: "a.example."},
+	}
+	aaaa := ResourceRecord{
+		Name:     "a.example.",
+		RType:    AAAA,
+		RClass:   IN,
+		TTL:      3600,
+		RDLength: 16,
+		RData:    &RDataAAAA{},
+	}
+
+	tests := []struct {
+		name      string
+		message   Message
+		want      DenialProof
+		wantError error
+	}{
+		{
+			name:    "NXDOMAIN",
+			message: newTestNegativeResponse("b.example.", A, NXDOMAIN, nil, []ResourceRecord{nsecTestChain[1], nsecTestChain[0]}),
+			want:    DenialProof{Type: DenialNXDOMAIN, Name: "b.example.", ClosestEncloser: "example."},
+		},
+		{
+			name:    "NXDOMAIN under existing name",
+			message: newTestNegativeResponse("q.a.example.", A, NXDOMAIN, nil, []ResourceRecord{nsecTestChain[1]}),
+			want:    DenialProof{Type: DenialNXDOMAIN, Name: "q.a.example.", ClosestEncloser: "a.example."},
+		},
+		{
+			name:      "NXDOMAIN without wildcard proof",
+			message:   newTestNegativeResponse("b.example.", A, NXDOMAIN, nil, []ResourceRecord{nsecTestChain[1]}),
+			wantError: ErrDenialNotProven,
+		},
+		{
+			name:      "NXDOMAIN with matching NSEC",
+			message:   newTestNegativeResponse("a.example.", A, NXDOMAIN, nil, []ResourceRecord{nsecTestChain[1], nsecTestChain[0]}),
+			wantError: ErrDenialNotProven,
+		},
+		{
+			name:    "NODATA",
+			message: newTestNegativeResponse("a.example.", AAAA, NOERROR, nil, []ResourceRecord{nsecTestChain[1]}),
+			want:    DenialProof{Type: DenialNODATA, Name: "a.example."},
+		},
+		{
+			name:    "NODATA case insensitive",
+			message: newTestNegativeResponse("A.Example.", AAAA, NOERROR, nil, []ResourceRecord{nsecTestChain[1]}),
+			want:    DenialProof{Type: DenialNODATA, Name: "A.Example."},
+		},
+		{
+			name:    "NODATA after CNAME",
+			message: newTestNegativeResponse("alias.example.", AAAA, NOERROR, []ResourceRecord{alias}, []ResourceRecord{nsecTestChain[1]}),
+			want:    DenialProof{Type: DenialNODATA, Name: "a.example."},
+		},
+		{
+			name:      "NODATA with existing type",
+			message:   newTestNegativeResponse("a.example.", A, NOERROR, nil, []ResourceRecord{nsecTestChain[1]}),
+			wantError: ErrDenialNotProven,
+		},
+		{
+			name: "NODATA from delegation",
+			message: newTestNegativeResponse("sub.example.", A, NOERROR, nil, []ResourceRecord{
+				newTestNSECRecord("sub.example.", "*.w.example.", NS, RRSIG, NSEC),
+			}),
+			wantError: ErrDenialNotProven,
+		},
+		{
+			name: "NODATA for DS at delegation",
+			message: newTestNegativeResponse("sub.example.", DS, NOERROR, nil, []ResourceRecord{
+				newTestNSECRecord("sub.example.", "*.w.example.", NS, RRSIG, NSEC),
+			}),
+			want: DenialProof{Type: DenialNODATA, Name: "sub.example."},
+		},
+		{
+			name:    "NODATA at empty non-terminal",
+			message: newTestNegativeResponse("w.example.", A, NOERROR, nil, []ResourceRecord{nsecTestChain[1]}),
+			want:    DenialProof{Type: DenialNODATA, Name: "w.example."},
+		},
+		{
+			name:      "NXDOMAIN at empty non-terminal",
+			message:   newTestNegativeResponse("w.example.", A, NXDOMAIN, nil, []ResourceRecord{nsecTestChain[1], nsecTestChain[0]}),
+			wantError: ErrDenialNotProven,
+		},
+		{
+			name:    "wildcard NODATA",
+			message: newTestNegativeResponse("z.w.example.", AAAA, NOERROR, nil, []ResourceRecord{nsecTestChain[3], nsecTestChain[2]}),
+			want:    DenialProof{Type: DenialWildcardNODATA, Name: "z.w.example.", ClosestEncloser: "w.example."},
+		},
+		{
+			name:      "wildcard NODATA without wildcard NSEC",
+			message:   newTestNegativeResponse("z.w.example.", AAAA, NOERROR, nil, []ResourceRecord{nsecTestChain[3]}),
+			wantError: ErrDenialNotProven,
+		},
+		{
+			name:      "positive answer",
+			message:   newTestNegativeResponse("a.example.", AAAA, NOERROR, []ResourceRecord{aaaa}, []ResourceRecord{nsecTestChain[1]}),
+			wantError: ErrDenialNotProven,
+		},
+		{
+			name:      "no NSEC records",
+			message:   newTestNegativeResponse("b.example.", A, NXDOMAIN, nil, nil),
+			wantError: ErrDenialNotProven,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProveDenial(tt.message)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("ProveDenial() error = %v, want = %v\n", err, tt.wantError)
+			}
+			if got != tt.want {
+				t.Errorf("ProveDenial() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProveNSEC3Denial(t *testing.T) {
+	chain := newTestNSEC3Chain(0, 12)
+	optOutChain := newTestNSEC3Chain(NSEC3FlagOptOut, 12)
+
+	unsupported := pickNSEC3Records(chain, "a.example.")
+	unsupportedRData := *unsupported[0].RData.(*RDataNSEC3)
+	unsupportedRData.HashAlgorithm = 2
+	unsupported[0].RData = &unsupportedRData
+
+	tests := []struct {
+		name      string
+		message   Message
+		want      DenialProof
+		wantError error
+	}{
+		{
+			name:    "NXDOMAIN",
+			message: newTestNegativeResponse("c.example.", A, NXDOMAIN, nil, pickNSEC3Records(chain, "example.", "c.example.", "*.example.")),
+			want:    DenialProof{Type: DenialNXDOMAIN, Name: "c.example.", NSEC3: true, ClosestEncloser: "example."},
+		},
+		{
+			name:    "NXDOMAIN deep under closest encloser",
+			message: newTestNegativeResponse("a.b.x.w.example.", A, NXDOMAIN, nil, pickNSEC3Records(chain, "x.w.example.", "b.x.w.example.", "*.x.w.example.")),
+			want:    DenialProof{Type: DenialNXDOMAIN, Name: "a.b.x.w.example.", NSEC3: true, ClosestEncloser: "x.w.example."},
+		},
+		{
+			name:      "NXDOMAIN without wildcard proof",
+			message:   newTestNegativeResponse("c.example.", A, NXDOMAIN, nil, pickNSEC3Records(chain, "example.", "c.example.")),
+			wantError: ErrDenialNotProven,
+		},
+		{
+			name:      "NXDOMAIN without closest encloser",
+			message:   newTestNegativeResponse("c.example.", A, NXDOMAIN, nil, pickNSEC3Records(chain, "c.example.", "*.example.")),
+			wantError: ErrDenialNotProven,
+		},
+		{
+			name:    "NODATA",
+			message: newTestNegativeResponse("a.example.", AAAA, NOERROR, nil, pickNSEC3Records(chain, "a.example.")),
+			want:    DenialProof{Type: DenialNODATA, Name: "a.example.", NSEC3: true},
+		},
+		{
+			name:    "NODATA at empty non-terminal",
+			message: newTestNegativeResponse("w.example.", A, NOERROR, nil, pickNSEC3Records(chain, "w.example.")),
+			want:    DenialProof{Type: DenialNODATA, Name: "w.example.", NSEC3: true},
+		},
+		{
+			name:      "NODATA with existing type",
+			message:   newTestNegativeResponse("a.example.", A, NOERROR, nil, pickNSEC3Records(chain, "a.example.")),
+			wantError: ErrDenialNotProven,
+		},
+		{
+			name:    "wildcard NODATA",
+			message: newTestNegativeResponse("z.w.example.", AAAA, NOERROR, nil, pickNSEC3Records(chain, "w.example.", "z.w.example.", "*.w.example.")),
+			want:    DenialProof{Type: DenialWildcardNODATA, Name: "z.w.example.", NSEC3: true, ClosestEncloser: "w.example."},
+		},
+		{
+			name:    "DS in opt-out span",
+			message: newTestNegativeResponse("sub.example.", DS, NOERROR, nil, pickNSEC3Records(optOutChain, "example.", "sub.example.")),
+			want:    DenialProof{Type: DenialOptOut, Name: "sub.example.", NSEC3: true, ClosestEncloser: "example."},
+		},
+		{
+			name:      "DS without opt-out",
+			message:   newTestNegativeResponse("sub.example.", DS, NOERROR, nil, pickNSEC3Records(chain, "example.", "sub.example.")),
+			wantError: ErrDenialNotProven,
+		},
+		{
+			name:      "name outside of zone",
+			message:   newTestNegativeResponse("example.com.", A, NXDOMAIN, nil, pickNSEC3Records(chain, "example.")),
+			wantError: ErrDenialNotProven,
+		},
+		{
+			name: "mixed parameters",
+			message: newTestNegativeResponse("c.example.", A, NXDOMAIN, nil, append(
+				pickNSEC3Records(chain, "example.", "c.example."),
+				pickNSEC3Records(newTestNSEC3Chain(0, 1), "*.example.")...,
+			)),
+			wantError: ErrDenialNotProven,
+		},
+		{
+			name:      "too many iterations",
+			message:   newTestNegativeResponse("a.example.", AAAA, NOERROR, nil, pickNSEC3Records(newTestNSEC3Chain(0, 151), "a.example.")),
+			wantError: ErrDenialNotProven,
+		},
+		{
+			name:      "unsupported hash algorithm",
+			message:   newTestNegativeResponse("a.example.", AAAA, NOERROR, nil, unsupported),
+			wantError: ErrUnsupportedAlgorithm,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProveDenial(tt.message)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("ProveDenial() error = %v, want = %v\n", err, tt.wantError)
+			}
+			if got != tt.want {
+				t.Errorf("ProveDenial() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
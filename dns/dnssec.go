@@ -0,0 +1,261 @@
+package dns
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"strings"
+
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// ------------------- DNSSEC ALGORITHMS
+type DNSSECAlgorithm uint8
+
+const (
+	RSAMD5           uint8 = 1  // RSA/MD5, deprecated [RFC3110][RFC4034]
+	DH               uint8 = 2  // Diffie-Hellman [RFC2539]
+	DSA              uint8 = 3  // DSA/SHA1 [RFC3755]
+	RSASHA1          uint8 = 5  // RSA/SHA-1 [RFC3110][RFC4034]
+	DSANSEC3SHA1     uint8 = 6  // DSA-NSEC3-SHA1 [RFC5155]
+	RSASHA1NSEC3SHA1 uint8 = 7  // RSASHA1-NSEC3-SHA1 [RFC5155]
+	RSASHA256        uint8 = 8  // RSA/SHA-256 [RFC5702]
+	RSASHA512        uint8 = 10 // RSA/SHA-512 [RFC5702]
+	ECCGOST          uint8 = 12 // GOST R 34.10-2001 [RFC5933]
+	ECDSAP256SHA256  uint8 = 13 // ECDSA Curve P-256 with SHA-256 [RFC6605]
+	ECDSAP384SHA384  uint8 = 14 // ECDSA Curve P-384 with SHA-384 [RFC6605]
+	ED25519          uint8 = 15 // Ed25519 [RFC8080]
+	ED448            uint8 = 16 // Ed448 [RFC8080]
+)
+
+var dnssecAlgorithmNames = map[uint8]string{
+	RSAMD5:           "RSAMD5",
+	DH:               "DH",
+	DSA:              "DSA",
+	RSASHA1:          "RSASHA1",
+	DSANSEC3SHA1:     "DSA-NSEC3-SHA1",
+	RSASHA1NSEC3SHA1: "RSASHA1-NSEC3-SHA1",
+	RSASHA256:        "RSASHA256",
+	RSASHA512:        "RSASHA512",
+	ECCGOST:          "ECC-GOST",
+	ECDSAP256SHA256:  "ECDSAP256SHA256",
+	ECDSAP384SHA384:  "ECDSAP384SHA384",
+	ED25519:          "ED25519",
+	ED448:            "ED448",
+}
+
+func (algorithm DNSSECAlgorithm) String() string {
+	if n, ok := dnssecAlgorithmNames[uint8(algorithm)]; ok {
+		return n
+	}
+	return fmt.Sprintf("ALG%d", uint8(algorithm))
+}
+
+// getAlgorithmHash returns the hash function used by a signature algorithm.
+// Ed25519 signs the data itself, so its hash is 0.
+func getAlgorithmHash(algorithm uint8) (crypto.Hash, error) {
+	switch algorithm {
+	case RSASHA1, RSASHA1NSEC3SHA1:
+		return crypto.SHA1, nil
+	case RSASHA256, ECDSAP256SHA256:
+		return crypto.SHA256, nil
+	case ECDSAP384SHA384:
+		return crypto.SHA384, nil
+	case RSASHA512:
+		return crypto.SHA512, nil
+	case ED25519:
+		return 0, nil
+	}
+	return 0, unsupportedAlgorithmError(algorithm)
+}
+
+// DS computes the DS record data referring to this key [RFC4034],
+// ex. to check a DNSKEY against a trust anchor or a parent zone's DS records.
+//
+// Parameters:
+//   - owner: The owner name of the DNSKEY record, ex. "example.com.".
+//   - digestType: The digest algorithm, ex. DSDigestSHA256.
+//
+// Returns:
+//   - *RDataDS: The DS record data.
+//   - error: If the digest type is not supported.
+func (rdata *RDataDNSKEY) DS(owner string, digestType uint8) (*RDataDS, error) {
+	var hash crypto.Hash
+	switch digestType {
+	case DSDigestSHA1:
+		hash = crypto.SHA1
+	case DSDigestSHA256:
+		hash = crypto.SHA256
+	case DSDigestSHA384:
+		hash = crypto.SHA384
+	default:
+		return nil, fmt.Errorf("%w: digest type %d", ErrUnsupportedAlgorithm, digestType)
+	}
+
+	// digest = hash(canonical owner name | DNSKEY RDATA)
+	writer := &dnsWriter{}
+	if err := writer.writeDomainName(strings.ToLower(owner)); err != nil {
+		return nil, err
+	}
+	rdata.WriteRecordData(writer)
+
+	h := hash.New()
+	h.Write(writer.data)
+
+	return &RDataDS{
+		KeyTag:     rdata.KeyTag(),
+		Algorithm:  rdata.Algorithm,
+		DigestType: digestType,
+		Digest:     h.Sum(nil),
+	}, nil
+}
+
+// getPublicKey decodes the public key material of a DNSKEY or KEY record.
+func getPublicKey(key *RDataDNSKEY) (crypto.PublicKey, error) {
+	switch key.Algorithm {
+	case RSASHA1, RSASHA1NSEC3SHA1, RSASHA256, RSASHA512:
+		return getRSAPublicKey(key.PublicKey)
+	case ECDSAP256SHA256:
+		return getECDSAPublicKey(elliptic.P256(), key.PublicKey)
+	case ECDSAP384SHA384:
+		return getECDSAPublicKey(elliptic.P384(), key.PublicKey)
+	case ED25519:
+		if len(key.PublicKey) != ed25519.PublicKeySize {
+			return nil, invalidSignatureError(fmt.Sprintf("invalid Ed25519 public key length: %d", len(key.PublicKey)))
+		}
+		return ed25519.PublicKey(key.PublicKey), nil
+	}
+	return nil, unsupportedAlgorithmError(key.Algorithm)
+}
+
+// RSA public key format [RFC3110]: exponent length (1 byte, or 0 followed by 2 bytes), exponent, modulus.
+func getRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	if len(data) < 3 {
+		return nil, invalidSignatureError("RSA public key too short")
+	}
+
+	exponentLength := int(data[0])
+	data = data[1:]
+	if exponentLength == 0 {
+		exponentLength = int(data[0])<<8 | int(data[1])
+		data = data[2:]
+	}
+	if exponentLength == 0 || exponentLength > 4 || len(data) <= exponentLength {
+		return nil, invalidSignatureError(fmt.Sprintf("invalid RSA public key exponent length: %d", exponentLength))
+	}
+
+	exponent := 0
+	for _, b := range data[:exponentLength] {
+		exponent = exponent<<8 | int(b)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(data[exponentLength:]),
+		E: exponent,
+	}, nil
+}
+
+// ECDSA public key format [RFC6605]: the X and Y coordinates, each padded to the curve size.
+func getECDSAPublicKey(curve elliptic.Curve, data []byte) (*ecdsa.PublicKey, error) {
+	size := (curve.Params().BitSize + 7) / 8
+	if len(data) != 2*size {
+		return nil, invalidSignatureError(fmt.Sprintf("invalid ECDSA public key length: %d", len(data)))
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(data[:size]),
+		Y:     new(big.Int).SetBytes(data[size:]),
+	}, nil
+}
+
+// signData signs data with the algorithm's hash and signature format.
+func signData(algorithm uint8, signer crypto.Signer, data []byte) ([]byte, error) {
+	hash, err := getAlgorithmHash(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := data
+	if hash != 0 {
+		h := hash.New()
+		h.Write(data)
+		digest = h.Sum(nil)
+	}
+
+	signature, err := signer.Sign(rand.Reader, digest, hash)
+	if err != nil {
+		return nil, invalidSignatureError(err.Error())
+	}
+
+	if publicKey, ok := signer.Public().(*ecdsa.PublicKey); ok {
+		// ECDSA signatures are the fixed size r and s integers [RFC6605], not ASN.1
+		return encodeECDSASignature(publicKey.Curve, signature)
+	}
+	return signature, nil
+}
+
+func encodeECDSASignature(curve elliptic.Curve, asn1Signature []byte) ([]byte, error) {
+	var integers struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(asn1Signature, &integers); err != nil {
+		return nil, invalidSignatureError(fmt.Sprintf("invalid ECDSA signature: %s", err.Error()))
+	}
+
+	size := (curve.Params().BitSize + 7) / 8
+	signature := make([]byte, 2*size)
+	integers.R.FillBytes(signature[:size])
+	integers.S.FillBytes(signature[size:])
+	return signature, nil
+}
+
+// verifyData checks a signature over data with the given public key.
+func verifyData(key *RDataDNSKEY, data []byte, signature []byte) error {
+	hash, err := getAlgorithmHash(key.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	publicKey, err := getPublicKey(key)
+	if err != nil {
+		return err
+	}
+
+	digest := data
+	if hash != 0 {
+		h := hash.New()
+		h.Write(data)
+		digest = h.Sum(nil)
+	}
+
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(publicKey, hash, digest, signature); err != nil {
+			return invalidSignatureError(err.Error())
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		size := len(signature) / 2
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(publicKey, digest, r, s) {
+			return invalidSignatureError("ECDSA verification failed")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(publicKey, digest, signature) {
+			return invalidSignatureError("Ed25519 verification failed")
+		}
+		return nil
+	}
+	return unsupportedAlgorithmError(key.Algorithm)
+}
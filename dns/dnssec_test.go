@@ -0,0 +1,66 @@
+package dns
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestDNSKEYToDS(t *testing.T) {
+	// DNSKEY and DS records from RFC 4034 section 5.4
+	publicKey, _ := base64.StdEncoding.DecodeString("AQOeiiR0GOMYkDshWoSKz9XzfwJr1AYtsmx3TGkJaNXVbfi/2pHm822aJ5iI9BMzNXxeYCmZDRD99WYwYqUSdjMmmAphXdvxegXd/M5+X7OrzKBaMbCVdFLUUh6DhweJBjEVv5f2wwjM9XzcnOf+EPbtG9DMBmADjFDc2w/rljwvFw==")
+	key := &RDataDNSKEY{
+		Flags:     DNSKEYFlagZone,
+		Protocol:  DNSKEYProtocol,
+		Algorithm: RSASHA1,
+		PublicKey: publicKey,
+	}
+
+	tests := []struct {
+		name       string
+		owner      string
+		digestType uint8
+		wantString string
+		wantError  error
+	}{
+		{
+			name:       "SHA-1 digest",
+			owner:      "dskey.example.com.",
+			digestType: DSDigestSHA1,
+			wantString: "60485 5 1 2BB183AF5F22588179A53B0A98631FAD1A292118",
+			wantError:  nil,
+		},
+		{
+			name:       "Owner name is case insensitive",
+			owner:      "DSKEY.Example.COM.",
+			digestType: DSDigestSHA1,
+			wantString: "60485 5 1 2BB183AF5F22588179A53B0A98631FAD1A292118",
+			wantError:  nil,
+		},
+		{
+			name:       "Unsupported digest type",
+			owner:      "dskey.example.com.",
+			digestType: 3,
+			wantError:  ErrUnsupportedAlgorithm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := key.DS(tt.owner, tt.digestType)
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("DS() error = %v, want error = %v\n", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DS() unexpected error = %v\n", err)
+			}
+			if got.String() != tt.wantString {
+				t.Errorf("DS() got = \"%s\", want = \"%s\"\n", got.String(), tt.wantString)
+			}
+		})
+	}
+}
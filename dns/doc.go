@@ -3,9 +3,22 @@
 // Key Features:
 //   - EncodeMessage: Converts a Message structure into DNS message bytes.
 //   - DecodeMessage: Parses DNS message bytes into a Message structure.
+//   - CreateQueryMessage: Builds a query Message with a cryptographically random ID (see NewID).
+//   - NewQuery, NewResponse, NewNotify: Build messages with chained methods, ex. NewQuery(name, A).WithRecursion().
+//   - NewChaosQuery: Asks a server for its version or identity in the CH class, ex. NewChaosQuery(VersionBind).
 //   - PrintQueryInfo: Displays DNS query details including server and query time.
 //   - PrintBasicQueryInfo: Shows basic query details.
-//   - PrintMessage: Prints comprehensive DNS message information.
+//   - PrintMessage: Prints comprehensive DNS message information, as returned by Message.String.
+//   - CheckHomographs: Flags punycode labels that mix scripts or imitate Latin labels.
+//   - DumpMessage: Annotates the fields of a message in wire format, ex. to debug a malformed packet.
+//
+// Record data is decoded into a struct per record type implementing RData, ex. *RDataMX,
+// so that fields like the MX preference or the SOA serial can be read without parsing strings.
+// It can also be parsed from its presentation format with ParseRData, and encoded with EncodeRData.
+// NewRR parses a whole record, ex. NewRR("example.com. 300 IN MX 10 mail.example.com."), and WriteZone
+// writes records as a zone file.
+// Messages convert to and from their JSON representation [RFC8427] with encoding/json, see MarshalMessageJSON,
+// and to YAML with MarshalMessageYAML.
 //
 // The package also includes constants for DNS record types and a function to map DNS type strings to their codes.
 package dns
@@ -3,6 +3,7 @@ package dns
 import (
 	"fmt"
 	"net/netip"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -29,13 +30,28 @@ labels are restricted to 63 octets or less.  (The 10 and 01 combinations
 are reserved for future use.)
 */
 
+// Limits of domain names in wire format [RFC1035]
+const (
+	maxLabelLength      = 63
+	maxDomainNameLength = 255 // Including the length bytes and the root label
+	// A name of 255 bytes has at most 127 labels, the root label included:
+	// a pointer before each of the others is the most a valid name can need.
+	maxCompressionPointers = 126
+)
+
+// readDomainName reads a domain name, following compression pointers.
+// Pointers must point to a prior occurrence of a name [RFC1035]: forward pointers
+// are rejected, which rules out loops, and the number of pointers is capped.
+// Errors are *DomainNameError, with the offset where decoding failed.
 func (reader *dnsReader) readDomainName() (domainName string, err error) {
 	jumped := false
 	pointerOffset := 0
+	pointers := 0
+	length := 1 // For the root label
 
 	for {
 		if reader.offset >= len(reader.data) {
-			return "", invalidDomainNameError("offset out of bounds")
+			return "", domainNameError(reader.offset, "offset out of bounds")
 		}
 
 		labelIndicator := int(reader.data[reader.offset]) // Read the label length or pointer indicator
@@ -48,6 +64,14 @@ func (reader *dnsReader) readDomainName() (domainName string, err error) {
 		}
 
 		if isPointerIndicator(labelIndicator) {
+			if reader.offset+1 >= len(reader.data) {
+				return "", domainNameError(reader.offset, "pointer out of bounds")
+			}
+
+			pointers++
+			if pointers > maxCompressionPointers {
+				return "", domainNameError(reader.offset, "too many compression pointers")
+			}
 
 			if !jumped {
 				// Save the current offset if we haven't jumped yet
@@ -56,26 +80,35 @@ func (reader *dnsReader) readDomainName() (domainName string, err error) {
 
 			newOffset := getJumpOffset(labelIndicator, reader)
 
-			if newOffset >= len(reader.data) {
-				return "", invalidDomainNameError("pointer offset out of bounds")
+			if newOffset >= reader.offset {
+				return "", domainNameError(reader.offset, fmt.Sprintf("forward pointer to offset %d", newOffset))
 			}
 
 			reader.offset = newOffset // Perform actual jump
 			jumped = true
 
+		} else if labelIndicator > maxLabelLength {
+			// The 01 and 10 prefixes are reserved [RFC1035]
+			return "", domainNameError(reader.offset, fmt.Sprintf("invalid label length: %d", labelIndicator))
+
 		} else {
 			// Normal label, not a pointer:
 			// labelIndicator indicates the length of the label
+			length += 1 + labelIndicator
+			if length > maxDomainNameLength {
+				return "", domainNameError(reader.offset, fmt.Sprintf("name longer than %d bytes", maxDomainNameLength))
+			}
+
+			if reader.offset+1+labelIndicator > len(reader.data) {
+				return "", domainNameError(reader.offset, "label offset out of bounds")
+			}
+
 			reader.offset++
 
 			if len(domainName) > 0 {
 				domainName += "."
 			}
 
-			if reader.offset+labelIndicator > len(reader.data) {
-				return "", invalidDomainNameError("label offset out of bounds")
-			}
-
 			// Add label to domain name
 			domainName += string(reader.data[reader.offset : reader.offset+labelIndicator])
 			reader.offset += labelIndicator // Move to the next label
@@ -117,36 +150,36 @@ func getJumpOffset(pointerIndicator int, reader *dnsReader) int {
 	return int(pointerIndicator&^0b11000000)<<8 | int(reader.data[reader.offset+1])
 }
 
-func (writer *dnsWriter) writeDomainName(name string) {
-	labels := strings.Split(name, ".")
-	requiredBytes := 0
-
-	// Calculate how many bytes are required to encode the domain name
+// writeDomainName writes a domain name in full.
+// Returns an error if a label is longer than 63 bytes or the name longer than 255 bytes [RFC1035].
+func (writer *dnsWriter) writeDomainName(name string) error {
+	labels, err := getWireLabels(name)
+	if err != nil {
+		return err
+	}
 	for _, label := range labels {
-		if len(label) > 0 {
-			// 1 for the label indicator + length of label
-			requiredBytes += 1 + len(label)
-		}
+		writer.writeData(append([]byte{byte(len(label))}, label...))
 	}
-	requiredBytes += 1 // For the terminating 0
+	writer.writeData([]byte{0})
+	return nil
+}
 
-	// Ensure there is enough space to write the domain name
-	if writer.offset+requiredBytes > len(writer.data) {
-		writer.data = append(writer.data, make([]byte, writer.offset+requiredBytes-len(writer.data))...)
-	}
+// getWireLabels returns the labels of a domain name, without the root label, checking that
+// they fit in wire format: labels of at most 63 bytes, in a name of at most 255 bytes [RFC1035].
+func getWireLabels(name string) ([]string, error) {
+	labels := slices.DeleteFunc(strings.Split(name, "."), func(label string) bool { return label == "" })
 
-	// Write domain name
+	length := 1 // For the root label
 	for _, label := range labels {
-		if len(label) == 0 {
-			continue
+		if len(label) > maxLabelLength {
+			return nil, invalidDomainNameError(fmt.Sprintf("%s: label longer than %d bytes", name, maxLabelLength))
 		}
-		writer.data[writer.offset] = byte(len(label))
-		writer.offset++
-		copy(writer.data[writer.offset:], label)
-		writer.offset += len(label)
+		length += 1 + len(label)
+	}
+	if length > maxDomainNameLength {
+		return nil, invalidDomainNameError(fmt.Sprintf("%s: name longer than %d bytes", name, maxDomainNameLength))
 	}
-	writer.data[writer.offset] = 0
-	writer.offset++
+	return labels, nil
 }
 
 // GetReverseDNSDomain returns the reverse DNS domain for the given IP address.
@@ -167,15 +200,31 @@ func GetReverseDNSDomain(ip string) (reversedDomain string, err error) {
 		return "", invalidIPError(ip)
 	}
 
-	if parsedIP.Is4() {
-		reversedDomain = reverseIPv4(parsedIP)
-	} else if parsedIP.Is6() {
-		reversedDomain = reverseIPv6(parsedIP)
-	} else {
-		return "", invalidIPError(ip)
-	}
+	return ReverseAddr(parsedIP)
+}
 
-	return reversedDomain, nil
+// ReverseAddr returns the owner name of the PTR record of an IP address [RFC1035] [RFC3596],
+// in in-addr.arpa. for IPv4 or with the 32 nibbles of the address in ip6.arpa. for IPv6, ex.:
+//
+//	name, err := dns.ReverseAddr(netip.MustParseAddr("192.0.2.1")) // "1.2.0.192.in-addr.arpa."
+//	query := dns.NewQuery(name, dns.PTR)
+//
+// The zone of an IPv6 address is ignored, and IPv4-mapped IPv6 addresses are reversed
+// in ip6.arpa.: call Unmap first to reverse them in in-addr.arpa.
+//
+// Parameters:
+//   - ip: The IP address to convert.
+//
+// Returns:
+//   - string: The reverse DNS domain.
+//   - error: If the IP address is the zero value.
+func ReverseAddr(ip netip.Addr) (string, error) {
+	if ip.Is4() {
+		return reverseIPv4(ip), nil
+	} else if ip.Is6() {
+		return reverseIPv6(ip), nil
+	}
+	return "", invalidIPError(ip.String())
 }
 
 func reverseIPv4(parsedIP netip.Addr) string {
@@ -211,3 +260,39 @@ func reverseIPv6(parsedIP netip.Addr) string {
 
 	return strings.Join(nibbles[:], ".") + ".ip6.arpa."
 }
+
+// maxCompressionOffset is the largest offset a compression pointer can hold: 14 bits.
+const maxCompressionOffset = 0x3FFF
+
+// writeCompressedDomainName writes a domain name, replacing its longest suffix already
+// written in the message by a pointer to it [RFC1035]. Names are written in full if the
+// writer does not compress.
+//
+// Compression is only allowed in the RDATA of the types defined in RFC 1035: other types
+// must call writeDomainName so that servers and resolvers not knowing them can copy them as is [RFC3597].
+// Returns an error if the name does not fit in wire format, see writeDomainName.
+func (writer *dnsWriter) writeCompressedDomainName(name string) error {
+	if writer.compression == nil {
+		return writer.writeDomainName(name)
+	}
+
+	labels, err := getWireLabels(name)
+	if err != nil {
+		return err
+	}
+
+	for i, label := range labels {
+		suffix := strings.Join(labels[i:], ".")
+		if pointer, ok := writer.compression[suffix]; ok {
+			writer.writeUint16(0xC000 | uint16(pointer))
+			return nil
+		}
+		if writer.offset <= maxCompressionOffset {
+			writer.compression[suffix] = writer.offset
+		}
+
+		writer.writeData(append([]byte{byte(len(label))}, label...))
+	}
+	writer.writeData([]byte{0})
+	return nil
+}
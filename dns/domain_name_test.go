@@ -1,8 +1,12 @@
 package dns
 
 import (
+	"bytes"
 	"errors"
+	"net/netip"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -101,6 +105,116 @@ func TestReadDomainName(t *testing.T) {
 	}
 }
 
+func TestReadDomainNameErrors(t *testing.T) {
+	longLabel := append([]byte{63}, bytes.Repeat([]byte{'a'}, 63)...)
+
+	// A chain of pointers, each pointing to the previous one, ending with the root label
+	pointerChain := func(pointers int) []byte {
+		data := []byte{0}
+		for i := 0; i < pointers; i++ {
+			data = append(data, 0xc0, byte(max(0, 2*i-1)))
+		}
+		return data
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		offset     int
+		wantOffset int
+	}{
+		{
+			name:       "Empty data",
+			data:       []byte{},
+			wantOffset: 0,
+		},
+		{
+			name:       "Pointer to itself",
+			data:       []byte{0xc0, 0},
+			wantOffset: 0,
+		},
+		{
+			name:       "Forward pointer",
+			data:       []byte{0xc0, 2, 0},
+			wantOffset: 0,
+		},
+		{
+			name: "Pointer loop",
+			data: []byte{
+				3, 'f', 'o', 'o', 0xc0, 6, // 0: "foo" and pointer to offset 6
+				0xc0, 4, // 6: pointer back to offset 4
+			},
+			offset:     6,
+			wantOffset: 4,
+		},
+		{
+			name:       "Truncated pointer",
+			data:       []byte{3, 'w', 'w', 'w', 0xc0},
+			wantOffset: 4,
+		},
+		{
+			name:       "Reserved label type",
+			data:       []byte{0x40, 'a', 0},
+			wantOffset: 0,
+		},
+		{
+			name:       "Label out of bounds",
+			data:       []byte{3, 'w', 'w', 'w', 5, 'a', 'b'},
+			wantOffset: 4,
+		},
+		{
+			name:       "Name longer than 255 bytes",
+			data:       slices.Concat(longLabel, longLabel, longLabel, longLabel, []byte{0}),
+			wantOffset: 192,
+		},
+		{
+			name:       "Too many pointers",
+			data:       pointerChain(127),
+			offset:     253,
+			wantOffset: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := &dnsReader{data: test.data, offset: test.offset}
+			_, err := reader.readDomainName()
+
+			var nameErr *DomainNameError
+			if !errors.As(err, &nameErr) || !errors.Is(err, ErrInvalidDomainName) {
+				t.Fatalf("readDomainName() error got = %v, want *DomainNameError\n", err)
+			}
+			if nameErr.Offset != test.wantOffset {
+				t.Errorf("readDomainName() error offset got = %d, want = %d, error = %v\n", nameErr.Offset, test.wantOffset, err)
+			}
+		})
+	}
+
+	// The longest allowed chain of pointers can be read
+	reader := &dnsReader{data: pointerChain(126), offset: 251}
+	if got, err := reader.readDomainName(); err != nil || got != "." {
+		t.Errorf("readDomainName() with %d pointers got = %s, error = %v, want = .\n", maxCompressionPointers, got, err)
+	}
+}
+
+func TestDecodeMessageDomainNameErrorOffset(t *testing.T) {
+	data := []byte{
+		0, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, // Header with one question
+		0xc0, 12, // Question name pointing to itself
+		0, 1, 0, 1,
+	}
+
+	_, err := DecodeMessage(data)
+
+	var nameErr *DomainNameError
+	if !errors.As(err, &nameErr) || !errors.Is(err, ErrInvalidMessage) {
+		t.Fatalf("DecodeMessage() error got = %v, want *DomainNameError\n", err)
+	}
+	if nameErr.Offset != 12 {
+		t.Errorf("DecodeMessage() error offset got = %d, want = 12\n", nameErr.Offset)
+	}
+}
+
 func TestEncodeName(t *testing.T) {
 
 	tests := []struct {
@@ -149,6 +263,80 @@ func TestEncodeName(t *testing.T) {
 	}
 }
 
+func TestWriteDomainNameLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantError error
+	}{
+		{name: "Label of 63 bytes", data: strings.Repeat("a", 63) + ".example."},
+		{name: "Label of 64 bytes", data: strings.Repeat("a", 64) + ".example.", wantError: ErrInvalidDomainName},
+		{name: "Name of 255 bytes", data: strings.Repeat(strings.Repeat("a", 63)+".", 3) + strings.Repeat("a", 61) + "."},
+		{name: "Name of 256 bytes", data: strings.Repeat(strings.Repeat("a", 63)+".", 3) + strings.Repeat("a", 62) + ".", wantError: ErrInvalidDomainName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &dnsWriter{}
+			if err := writer.writeDomainName(tt.data); !errors.Is(err, tt.wantError) {
+				t.Errorf("writeDomainName() error = %v, want error = %v\n", err, tt.wantError)
+			}
+			writer = &dnsWriter{compression: map[string]int{}}
+			if err := writer.writeCompressedDomainName(tt.data); !errors.Is(err, tt.wantError) {
+				t.Errorf("writeCompressedDomainName() error = %v, want error = %v\n", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestWriteCompressedDomainName(t *testing.T) {
+	writer := &dnsWriter{compression: map[string]int{}}
+
+	writer.writeCompressedDomainName("www.example.com.")
+	writer.writeCompressedDomainName("mail.example.com.")
+	writer.writeCompressedDomainName("www.example.com")
+	writer.writeCompressedDomainName(".com")
+	writer.writeCompressedDomainName(".")
+	writer.writeCompressedDomainName("WWW.example.com.")
+
+	want := []byte{
+		3, 'w', 'w', 'w', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // 0: www.example.com.
+		4, 'm', 'a', 'i', 'l', 0xC0, 4, // 17: mail + pointer to example.com.
+		0xC0, 0, // 25: pointer to www.example.com.
+		0xC0, 12, // 27: pointer to com.
+		0,                         // 29: root
+		3, 'W', 'W', 'W', 0xC0, 4, // 30: names are compressed case sensitively
+	}
+	if !reflect.DeepEqual(writer.data, want) {
+		t.Errorf("writeCompressedDomainName() bytes got = %v, want = %v\n", writer.data, want)
+	}
+
+	reader := &dnsReader{data: writer.data, offset: 17}
+	got, err := reader.readDomainName()
+	if err != nil || got != "mail.example.com." {
+		t.Errorf("readDomainName() got = %s, error = %v, want = mail.example.com.\n", got, err)
+	}
+
+	// Without compression, names are written in full
+	writer = &dnsWriter{}
+	writer.writeCompressedDomainName("example.com.")
+	writer.writeCompressedDomainName("example.com.")
+	if len(writer.data) != 26 {
+		t.Errorf("writeCompressedDomainName() without compression length got = %d, want = 26\n", len(writer.data))
+	}
+}
+
+func TestWriteCompressedDomainNameOffsetLimit(t *testing.T) {
+	// Names written past the 14-bit pointer range cannot be pointed to
+	writer := &dnsWriter{data: make([]byte, maxCompressionOffset+1), offset: maxCompressionOffset + 1, compression: map[string]int{}}
+	writer.writeCompressedDomainName("example.com.")
+	writer.writeCompressedDomainName("example.com.")
+
+	if got := len(writer.data) - (maxCompressionOffset + 1); got != 26 {
+		t.Errorf("writeCompressedDomainName() length got = %d, want = 26\n", got)
+	}
+}
+
 func TestGetReverseDNSDomain(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -224,3 +412,55 @@ func TestGetReverseDNSDomain(t *testing.T) {
 	}
 
 }
+
+func TestReverseAddr(t *testing.T) {
+	tests := []struct {
+		name      string
+		ip        netip.Addr
+		want      string
+		wantError error
+	}{
+		{
+			name: "IPv4",
+			ip:   netip.MustParseAddr("192.0.2.1"),
+			want: "1.2.0.192.in-addr.arpa.",
+		},
+		{
+			name: "IPv6",
+			ip:   netip.MustParseAddr("2001:db8::567:89ab"),
+			want: "b.a.9.8.7.6.5.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.",
+		},
+		{
+			name: "IPv6 with zone",
+			ip:   netip.MustParseAddr("fe80::1%eth0"),
+			want: "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.e.f.ip6.arpa.",
+		},
+		{
+			name: "IPv4-mapped IPv6",
+			ip:   netip.MustParseAddr("::ffff:192.0.2.1"),
+			want: "1.0.2.0.0.0.0.c.f.f.f.f.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.ip6.arpa.",
+		},
+		{
+			name: "IPv4-mapped IPv6 unmapped",
+			ip:   netip.MustParseAddr("::ffff:192.0.2.1").Unmap(),
+			want: "1.2.0.192.in-addr.arpa.",
+		},
+		{
+			name:      "Zero address",
+			ip:        netip.Addr{},
+			wantError: ErrInvalidIP,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReverseAddr(tt.ip)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("ReverseAddr() error = %v, want error = %v\n", err, tt.wantError)
+			}
+			if got != tt.want {
+				t.Errorf("ReverseAddr() got = %s, want = %s\n", got, tt.want)
+			}
+		})
+	}
+}
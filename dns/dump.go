@@ -0,0 +1,203 @@
+package dns
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Annotated dump of a message in wire format: one line per field, with its offset,
+// its bytes in hexadecimal and what they mean, ex.:
+//
+//	0000  04 d2                    ID: 1234
+//	0002  01 00                    Flags: rd, opcode: QUERY, rcode: NOERROR
+//	...
+//	000c  07 65 78 61 6d 70 6c 65  Label: "example"
+//	0014  03 63 6f 6d              Label: "com"
+//	0018  00                       Root
+//
+// Fields longer than dumpBytesPerLine bytes continue on the following lines.
+
+// dumpBytesPerLine is the number of bytes per line of a dump.
+const dumpBytesPerLine = 8
+
+type dumper struct {
+	data    []byte
+	offset  int
+	builder strings.Builder
+}
+
+// DumpMessage returns a message in wire format as a hex dump annotated with its fields:
+// the header fields, each label and compression pointer of the names, and the type, class,
+// TTL, RDLENGTH and RDATA of each record. A malformed message is dumped up to the first
+// field which cannot be read, ex. to find where a packet is broken.
+//
+// Parameters:
+//   - data: The DNS message in a byte slice.
+//
+// Returns:
+//   - string: The annotated dump, one line per field.
+//   - error: If the message is malformed. The dump is returned up to the malformed field.
+func DumpMessage(data []byte) (string, error) {
+	dumper := &dumper{data: data}
+	err := dumper.dumpMessage()
+	if err != nil {
+		dumper.writeLine(dumper.offset, nil, "Error: "+err.Error())
+		err = invalidMessageError(err.Error())
+	}
+	return dumper.builder.String(), err
+}
+
+func (dumper *dumper) dumpMessage() error {
+	if len(dumper.data) < DNSHeaderLength {
+		return fmt.Errorf("header too short: %d bytes", len(dumper.data))
+	}
+
+	reader := &dnsReader{data: dumper.data}
+	header, _ := reader.readHeader()
+	flags := header.Flags
+	dumper.field(2, fmt.Sprintf("ID: %d", header.Id))
+	dumper.field(2, fmt.Sprintf("Flags: %s, opcode: %s, rcode: %s", getFlagString(flags), DNSOpCode(flags.Opcode), DNSRCode(flags.ResponseCode)))
+
+	titles := getSectionTitles(flags.Opcode)
+	counts := []uint16{header.QuestionCount, header.AnswerRRCount, header.NameserverRRCount, header.AdditionalRRCount}
+	for i, count := range counts {
+		dumper.field(2, fmt.Sprintf("%s count: %d", titles[i], count))
+	}
+
+	for i, count := range counts {
+		if count > 0 {
+			dumper.builder.WriteString(fmt.Sprintf(";; %s SECTION:\n", strings.ToUpper(titles[i])))
+		}
+		for j := 0; j < int(count); j++ {
+			var err error
+			if i == 0 {
+				err = dumper.dumpQuestion()
+			} else {
+				err = dumper.dumpResourceRecord()
+			}
+			if err != nil {
+				return fmt.Errorf("%s section: %w", strings.ToLower(titles[i]), err)
+			}
+		}
+	}
+
+	if trailing := len(dumper.data) - dumper.offset; trailing > 0 {
+		dumper.field(trailing, fmt.Sprintf("Trailing bytes: %d", trailing))
+	}
+	return nil
+}
+
+func (dumper *dumper) dumpQuestion() error {
+	if err := dumper.dumpDomainName(); err != nil {
+		return err
+	}
+	if len(dumper.data) < dumper.offset+4 {
+		return fmt.Errorf("question too short")
+	}
+	dumper.field(2, "Type: "+DNSType(dumper.uint16At(dumper.offset)).String())
+	dumper.field(2, "Class: "+DNSClass(dumper.uint16At(dumper.offset)).String())
+	return nil
+}
+
+func (dumper *dumper) dumpResourceRecord() error {
+	start := dumper.offset
+	if err := dumper.dumpDomainName(); err != nil {
+		return err
+	}
+	if len(dumper.data) < dumper.offset+10 {
+		return fmt.Errorf("resource record too short")
+	}
+
+	rtype := dumper.uint16At(dumper.offset)
+	dumper.field(2, "Type: "+DNSType(rtype).String())
+	if rtype == OPT {
+		dumper.field(2, fmt.Sprintf("UDP payload size: %d", dumper.uint16At(dumper.offset)))
+		ttl := uint32(dumper.uint16At(dumper.offset))<<16 | uint32(dumper.uint16At(dumper.offset+2))
+		dumper.field(4, fmt.Sprintf("Extended RCODE: %d, version: %d, DO: %t", (ttl&ednsExtendedRCodeMask)>>24, (ttl&ednsVersionMask)>>16, ttl&ednsDOMask != 0))
+	} else {
+		dumper.field(2, "Class: "+DNSClass(dumper.uint16At(dumper.offset)).String())
+		ttl := uint32(dumper.uint16At(dumper.offset))<<16 | uint32(dumper.uint16At(dumper.offset+2))
+		dumper.field(4, fmt.Sprintf("TTL: %d", ttl))
+	}
+
+	rdlength := int(dumper.uint16At(dumper.offset))
+	dumper.field(2, fmt.Sprintf("RDLENGTH: %d", rdlength))
+	if len(dumper.data) < dumper.offset+rdlength {
+		return fmt.Errorf("RDATA of %d bytes past the end of the message", rdlength)
+	}
+
+	// Decode the whole record again to show its RDATA in presentation format
+	reader := &dnsReader{data: dumper.data, offset: start}
+	annotation := "RDATA"
+	if record, err := reader.readResourceRecord(); err != nil {
+		annotation += " (malformed): " + err.Error()
+	} else if rdlength > 0 {
+		annotation += ": " + record.RData.String()
+	}
+	dumper.field(rdlength, annotation)
+	return nil
+}
+
+// dumpDomainName dumps the labels of a name at the offset, up to the root label or a compression pointer.
+func (dumper *dumper) dumpDomainName() error {
+	for {
+		if dumper.offset >= len(dumper.data) {
+			return fmt.Errorf("name past the end of the message")
+		}
+
+		labelIndicator := int(dumper.data[dumper.offset])
+		switch {
+		case labelIndicator == 0:
+			dumper.field(1, "Root")
+			return nil
+
+		case isPointerIndicator(labelIndicator):
+			if dumper.offset+1 >= len(dumper.data) {
+				return fmt.Errorf("pointer past the end of the message")
+			}
+			reader := &dnsReader{data: dumper.data, offset: dumper.offset}
+			name, err := reader.readDomainName()
+			if err != nil {
+				return err
+			}
+			target := int(dumper.uint16At(dumper.offset) & 0x3FFF)
+			dumper.field(2, fmt.Sprintf("Pointer to %04x: %s", target, name))
+			return nil
+
+		case labelIndicator > maxLabelLength:
+			return fmt.Errorf("invalid label length: %d", labelIndicator)
+
+		default:
+			if dumper.offset+1+labelIndicator > len(dumper.data) {
+				return fmt.Errorf("label past the end of the message")
+			}
+			label := dumper.data[dumper.offset+1 : dumper.offset+1+labelIndicator]
+			dumper.field(1+labelIndicator, fmt.Sprintf("Label: %q", label))
+		}
+	}
+}
+
+// uint16At returns the 16-bit value at the offset, which must be in bounds.
+func (dumper *dumper) uint16At(offset int) uint16 {
+	reader := &dnsReader{data: dumper.data, offset: offset}
+	return reader.readUint16()
+}
+
+// field writes a field of the given length at the offset, which must be in bounds, and advances past it.
+func (dumper *dumper) field(length int, annotation string) {
+	start := dumper.offset
+	dumper.writeLine(start, dumper.data[start:start+min(length, dumpBytesPerLine)], annotation)
+	for offset := start + dumpBytesPerLine; offset < start+length; offset += dumpBytesPerLine {
+		dumper.writeLine(offset, dumper.data[offset:min(offset+dumpBytesPerLine, start+length)], "")
+	}
+	dumper.offset = start + length
+}
+
+func (dumper *dumper) writeLine(offset int, data []byte, annotation string) {
+	hex := make([]string, 0, len(data))
+	for _, b := range data {
+		hex = append(hex, fmt.Sprintf("%02x", b))
+	}
+	line := fmt.Sprintf("%04x  %-*s  %s", offset, dumpBytesPerLine*3-1, strings.Join(hex, " "), annotation)
+	dumper.builder.WriteString(strings.TrimRight(line, " ") + "\n")
+}
@@ -0,0 +1,89 @@
+package dns
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestDumpMessage(t *testing.T) {
+	query := NewQuery("example.com.", A).WithID(1234).WithRecursion()
+	response := NewResponse(query).Answer(ResourceRecord{Name: "example.com.", RType: A, RClass: IN, TTL: 300, RData: &RDataA{IP: netip.MustParseAddr("192.0.2.1")}})
+	data, err := response.Pack()
+	if err != nil {
+		t.Fatalf("Pack() unexpected error = %v\n", err)
+	}
+
+	header := "0000  04 d2                    ID: 1234\n" +
+		"0002  81 00                    Flags: qr rd, opcode: QUERY, rcode: NOERROR\n" +
+		"0004  00 01                    Question count: 1\n" +
+		"0006  00 01                    Answer count: 1\n" +
+		"0008  00 00                    Authority count: 0\n" +
+		"000a  00 00                    Additional count: 0\n" +
+		";; QUESTION SECTION:\n" +
+		"000c  07 65 78 61 6d 70 6c 65  Label: \"example\"\n" +
+		"0014  03 63 6f 6d              Label: \"com\"\n" +
+		"0018  00                       Root\n" +
+		"0019  00 01                    Type: A\n" +
+		"001b  00 01                    Class: IN\n" +
+		";; ANSWER SECTION:\n" +
+		"001d  c0 0c                    Pointer to 000c: example.com.\n"
+
+	tests := []struct {
+		name      string
+		data      []byte
+		want      string
+		wantError error
+	}{
+		{
+			name: "Response",
+			data: data,
+			want: header +
+				"001f  00 01                    Type: A\n" +
+				"0021  00 01                    Class: IN\n" +
+				"0023  00 00 01 2c              TTL: 300\n" +
+				"0027  00 04                    RDLENGTH: 4\n" +
+				"0029  c0 00 02 01              RDATA: 192.0.2.1\n",
+		},
+		{
+			name: "Trailing bytes",
+			data: append(append([]byte{}, data...), 0xff),
+			want: header +
+				"001f  00 01                    Type: A\n" +
+				"0021  00 01                    Class: IN\n" +
+				"0023  00 00 01 2c              TTL: 300\n" +
+				"0027  00 04                    RDLENGTH: 4\n" +
+				"0029  c0 00 02 01              RDATA: 192.0.2.1\n" +
+				"002d  ff                       Trailing bytes: 1\n",
+		},
+		{
+			name: "Truncated record",
+			data: data[:len(data)-2],
+			want: header +
+				"001f  00 01                    Type: A\n" +
+				"0021  00 01                    Class: IN\n" +
+				"0023  00 00 01 2c              TTL: 300\n" +
+				"0027  00 04                    RDLENGTH: 4\n" +
+				"0029                           Error: answer section: RDATA of 4 bytes past the end of the message\n",
+			wantError: ErrInvalidMessage,
+		},
+		{
+			name:      "Header too short",
+			data:      data[:5],
+			want:      "0000                           Error: header too short: 5 bytes\n",
+			wantError: ErrInvalidMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DumpMessage(tt.data)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("DumpMessage() error = %v, want error = %v\n", err, tt.wantError)
+			}
+			if got != tt.want {
+				t.Errorf("DumpMessage()\n\tgot = %s\n\twant = %s\n", got, tt.want)
+			}
+		})
+	}
+}
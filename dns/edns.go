@@ -0,0 +1,735 @@
+package dns
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// EDNS(0) OPT pseudo-record format [RFC6891]
+// The OPT record is placed in the additional section and reuses the
+// fixed resource record fields with a different meaning:
+
+//     +------------+--------------+------------------------------+
+//     | Field Name | Field Type   | Description                  |
+//     +------------+--------------+------------------------------+
+//     | NAME       | domain name  | MUST be 0 (root domain)      |
+//     | TYPE       | u_int16_t    | OPT (41)                     |
+//     | CLASS      | u_int16_t    | requestor's UDP payload size |
+//     | TTL        | u_int32_t    | extended RCODE and flags     |
+//     | RDLEN      | u_int16_t    | length of all RDATA          |
+//     | RDATA      | octet stream | {attribute,value} pairs      |
+//     +------------+--------------+------------------------------+
+
+// The TTL field is split as follows:
+
+//                 +0 (MSB)                            +1 (LSB)
+//      +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//   0: |         EXTENDED-RCODE        |            VERSION            |
+//      +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//   2: | DO|                           Z                               |
+//      +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+
+// EDNS holds the EDNS(0) parameters carried by an OPT pseudo-record.
+type EDNS struct {
+	UDPSize       uint16 // Requestor's UDP payload size
+	ExtendedRCode uint8  // Upper 8 bits of the extended 12-bit RCODE
+	Version       uint8  // EDNS version, 0 for EDNS(0)
+	DnssecOk      bool   // DO bit: DNSSEC records are accepted [RFC3225]
+	Z             uint16 // Remaining 15 reserved flag bits
+	Options       []EDNSOption
+}
+
+const (
+	ednsExtendedRCodeMask = 0xFF000000
+	ednsVersionMask       = 0x00FF0000
+	ednsDOMask            = 0x00008000
+	ednsZMask             = 0x00007FFF
+)
+
+// ParseEDNS extracts the EDNS parameters from an OPT pseudo-record.
+//
+// Parameters:
+//   - record: The OPT resource record, usually found in the additional section.
+//
+// Returns:
+//   - EDNS: The EDNS parameters and options.
+//   - error: If the record is not an OPT record.
+func ParseEDNS(record ResourceRecord) (EDNS, error) {
+	if record.RType != OPT {
+		return EDNS{}, invalidResourceRecordError(fmt.Sprintf("not an OPT record: %s", DNSType(record.RType)))
+	}
+
+	edns := EDNS{
+		UDPSize:       record.RClass,
+		ExtendedRCode: uint8((record.TTL & ednsExtendedRCodeMask) >> 24),
+		Version:       uint8((record.TTL & ednsVersionMask) >> 16),
+		DnssecOk:      record.TTL&ednsDOMask != 0,
+		Z:             uint16(record.TTL & ednsZMask),
+	}
+
+	if rdata, ok := record.RData.(*RDataOPT); ok {
+		edns.Options = rdata.Options
+	}
+
+	return edns, nil
+}
+
+// ResourceRecord builds the OPT pseudo-record carrying these EDNS parameters.
+//
+// Returns:
+//   - ResourceRecord: The OPT record, ready to be added to the additional section.
+func (edns EDNS) ResourceRecord() ResourceRecord {
+	ttl := uint32(edns.ExtendedRCode)<<24 | uint32(edns.Version)<<16 | uint32(edns.Z)&ednsZMask
+	if edns.DnssecOk {
+		ttl |= ednsDOMask
+	}
+
+	rdata := &RDataOPT{Options: edns.Options}
+
+	writer := &dnsWriter{}
+	rdata.WriteRecordData(writer)
+
+	return ResourceRecord{
+		Name:     ".",
+		RType:    OPT,
+		RClass:   edns.UDPSize,
+		TTL:      ttl,
+		RDLength: uint16(len(writer.data)),
+		RData:    rdata,
+	}
+}
+
+func (edns EDNS) String() string {
+	flags := ""
+	if edns.DnssecOk {
+		flags = "do"
+	}
+	return fmt.Sprintf("EDNS: version: %d, flags: %s; udp: %d", edns.Version, flags, edns.UDPSize)
+}
+
+// -------------- OPT
+// OPT RDATA format
+// Zero or more options, each of the following format:
+
+//                +0 (MSB)                            +1 (LSB)
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//  0: |                          OPTION-CODE                          |
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//  2: |                         OPTION-LENGTH                         |
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//  4: |                                                               |
+//     /                          OPTION-DATA                          /
+//     /                                                               /
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+
+type RDataOPT struct {
+	Options []EDNSOption
+}
+
+func (rdata *RDataOPT) String() string {
+	options := make([]string, 0, len(rdata.Options))
+	for _, option := range rdata.Options {
+		options = append(options, option.String())
+	}
+	return strings.Join(options, "; ")
+}
+
+func (rdata *RDataOPT) WriteRecordData(writer *dnsWriter) error {
+	for _, option := range rdata.Options {
+		writer.writeUint16(option.Code())
+
+		// Write a placeholder for the option length, and fill it in once the data is written
+		lengthOffset := writer.offset
+		writer.writeUint16(0)
+		dataOffset := writer.offset
+
+		if err := option.WriteOptionData(writer); err != nil {
+			return invalidRecordDataError(fmt.Sprintf("OPT RData: %s", err.Error()))
+		}
+
+		length := uint16(writer.offset - dataOffset)
+		writer.data[lengthOffset] = byte(length >> 8)
+		writer.data[lengthOffset+1] = byte(length & 0xFF)
+	}
+	return nil
+}
+
+func (rdata *RDataOPT) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	end := reader.offset + int(length)
+	if end > len(reader.data) {
+		return invalidRecordDataError("OPT RData: too short")
+	}
+
+	rdata.Options = nil
+	for reader.offset < end {
+		if reader.offset+4 > end {
+			return invalidRecordDataError("OPT RData: option header too short")
+		}
+
+		code := reader.readUint16()
+		optionLength := reader.readUint16()
+
+		if reader.offset+int(optionLength) > end {
+			return invalidRecordDataError(fmt.Sprintf("OPT RData: option %s too long: %d", EDNSOptionCode(code), optionLength))
+		}
+
+		option := getEDNSOptionStruct(code)
+		optionEnd := reader.offset + int(optionLength)
+
+		if err = option.ReadOptionData(reader, optionLength); err != nil {
+			return invalidRecordDataError(fmt.Sprintf("OPT RData: %s", err.Error()))
+		}
+		reader.offset = optionEnd
+
+		rdata.Options = append(rdata.Options, option)
+	}
+	return nil
+}
+
+// ------------------- EDNS OPTIONS
+
+// EDNSOption is a single {attribute, value} pair in the OPT record's RDATA.
+type EDNSOption interface {
+	Code() uint16
+	String() string
+	WriteOptionData(writer *dnsWriter) error
+	ReadOptionData(reader *dnsReader, length uint16) error
+}
+
+type EDNSOptionCode uint16
+
+const (
+	EDNS0LLQ           uint16 = 1  // Long-Lived Queries [RFC8764]
+	EDNS0UL            uint16 = 2  // Update Lease [RFC9664]
+	EDNS0NSID          uint16 = 3  // Name Server Identifier [RFC5001]
+	EDNS0DAU           uint16 = 5  // DNSSEC Algorithm Understood [RFC6975]
+	EDNS0DHU           uint16 = 6  // DS Hash Understood [RFC6975]
+	EDNS0N3U           uint16 = 7  // NSEC3 Hash Understood [RFC6975]
+	EDNS0SUBNET        uint16 = 8  // EDNS Client Subnet [RFC7871]
+	EDNS0EXPIRE        uint16 = 9  // EDNS EXPIRE [RFC7314]
+	EDNS0COOKIE        uint16 = 10 // DNS Cookie [RFC7873]
+	EDNS0TCPKEEPALIVE  uint16 = 11 // edns-tcp-keepalive [RFC7828]
+	EDNS0PADDING       uint16 = 12 // Padding [RFC7830]
+	EDNS0CHAIN         uint16 = 13 // CHAIN [RFC7901]
+	EDNS0KEYTAG        uint16 = 14 // edns-key-tag [RFC8145]
+	EDNS0EDE           uint16 = 15 // Extended DNS Error [RFC8914]
+	EDNS0CLIENTTAG     uint16 = 16 // EDNS-Client-Tag [draft-bellis-dnsop-edns-tags]
+	EDNS0SERVERTAG     uint16 = 17 // EDNS-Server-Tag [draft-bellis-dnsop-edns-tags]
+	EDNS0REPORTCHANNEL uint16 = 18 // Report-Channel [RFC9567]
+	EDNS0ZONEVERSION   uint16 = 19 // ZONEVERSION [RFC9660]
+)
+
+var ednsOptionCodeNames = map[uint16]string{
+	EDNS0LLQ:           "LLQ",
+	EDNS0UL:            "UL",
+	EDNS0NSID:          "NSID",
+	EDNS0DAU:           "DAU",
+	EDNS0DHU:           "DHU",
+	EDNS0N3U:           "N3U",
+	EDNS0SUBNET:        "CLIENT-SUBNET",
+	EDNS0EXPIRE:        "EXPIRE",
+	EDNS0COOKIE:        "COOKIE",
+	EDNS0TCPKEEPALIVE:  "TCP-KEEPALIVE",
+	EDNS0PADDING:       "PADDING",
+	EDNS0CHAIN:         "CHAIN",
+	EDNS0KEYTAG:        "KEY-TAG",
+	EDNS0EDE:           "EDE",
+	EDNS0CLIENTTAG:     "CLIENT-TAG",
+	EDNS0SERVERTAG:     "SERVER-TAG",
+	EDNS0REPORTCHANNEL: "REPORT-CHANNEL",
+	EDNS0ZONEVERSION:   "ZONEVERSION",
+}
+
+func (code EDNSOptionCode) String() string {
+	if n, ok := ednsOptionCodeNames[uint16(code)]; ok {
+		return n
+	}
+	return fmt.Sprintf("OPT=%d", uint16(code))
+}
+
+func getEDNSOptionStruct(code uint16) EDNSOption {
+	var option EDNSOption
+	switch code {
+	case EDNS0NSID:
+		option = &EDNSOptionNSID{}
+	case EDNS0SUBNET:
+		option = &EDNSOptionClientSubnet{}
+	case EDNS0COOKIE:
+		option = &EDNSOptionCookie{}
+	case EDNS0PADDING:
+		option = &EDNSOptionPadding{}
+	case EDNS0EDE:
+		option = &EDNSOptionEDE{}
+	default:
+		option = &EDNSOptionUnknown{OptionCode: code}
+	}
+	return option
+}
+
+// -------------- NSID
+// Name Server Identifier option format [RFC5001]
+// Queries carry an empty NSID option to request the server's identifier.
+// Responses carry the identifier, an opaque octet string, often printable.
+
+type EDNSOptionNSID struct {
+	NSID []byte // Empty in queries
+}
+
+func (option *EDNSOptionNSID) Code() uint16 {
+	return EDNS0NSID
+}
+
+func (option *EDNSOptionNSID) String() string {
+	if len(option.NSID) == 0 {
+		return EDNSOptionCode(EDNS0NSID).String()
+	}
+
+	nsid := fmt.Sprintf("%s: %s", EDNSOptionCode(EDNS0NSID), hex.EncodeToString(option.NSID))
+	if isPrintable(option.NSID) {
+		nsid += fmt.Sprintf(" (\"%s\")", option.NSID)
+	}
+	return nsid
+}
+
+func (option *EDNSOptionNSID) WriteOptionData(writer *dnsWriter) error {
+	writer.writeData(option.NSID)
+	return nil
+}
+
+func (option *EDNSOptionNSID) ReadOptionData(reader *dnsReader, length uint16) (err error) {
+	data, err := reader.readUntil(int(length))
+	if err != nil {
+		return fmt.Errorf("NSID: %w", err)
+	}
+	option.NSID = append([]byte{}, data...)
+	return nil
+}
+
+func isPrintable(data []byte) bool {
+	for _, b := range data {
+		if b < 0x20 || b > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// -------------- CLIENT-SUBNET
+// EDNS Client Subnet option format [RFC7871]
+
+//                +0 (MSB)                            +1 (LSB)
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//  0: |                            FAMILY                             |
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//  2: |     SOURCE PREFIX-LENGTH      |     SCOPE PREFIX-LENGTH       |
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//  4: |                           ADDRESS...                          /
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+
+// The address is truncated to the number of bytes needed by the source prefix length, and its bits
+// past the source prefix length must be zero [RFC7871 6]: they are cleared when the option is written,
+// and an option with some set is rejected when decoding in strict mode.
+
+const (
+	ECSFamilyIPv4 uint16 = 1
+	ECSFamilyIPv6 uint16 = 2
+)
+
+type EDNSOptionClientSubnet struct {
+	Family       uint16     // Address family: 1 for IPv4, 2 for IPv6
+	SourcePrefix uint8      // Number of significant bits of the address sent by the client
+	ScopePrefix  uint8      // Number of bits the server's answer covers, 0 in queries
+	Address      netip.Addr // Client subnet address, bits past the source prefix are zero
+}
+
+// ParseClientSubnet creates a client subnet option from an address or CIDR prefix,
+// ex. "192.0.2.0/24" or "2001:db8::/56". A bare address uses its full length as source prefix.
+//
+// Parameters:
+//   - subnet: The client subnet, in CIDR or address notation.
+//
+// Returns:
+//   - *EDNSOptionClientSubnet: The option, ready to be added to an OPT record.
+//   - error: If the subnet cannot be parsed.
+func ParseClientSubnet(subnet string) (*EDNSOptionClientSubnet, error) {
+	prefix, err := netip.ParsePrefix(subnet)
+	if err != nil {
+		addr, addrErr := netip.ParseAddr(subnet)
+		if addrErr != nil {
+			return nil, fmt.Errorf("invalid client subnet: %s", subnet)
+		}
+		prefix = netip.PrefixFrom(addr, addr.BitLen())
+	}
+
+	prefix = prefix.Masked()
+	option := &EDNSOptionClientSubnet{
+		Family:       ECSFamilyIPv6,
+		SourcePrefix: uint8(prefix.Bits()),
+		Address:      prefix.Addr(),
+	}
+	if prefix.Addr().Is4() {
+		option.Family = ECSFamilyIPv4
+	}
+	return option, nil
+}
+
+func (option *EDNSOptionClientSubnet) Code() uint16 {
+	return EDNS0SUBNET
+}
+
+func (option *EDNSOptionClientSubnet) String() string {
+	return fmt.Sprintf("%s: %s/%d/%d", EDNSOptionCode(EDNS0SUBNET), option.Address, option.SourcePrefix, option.ScopePrefix)
+}
+
+func (option *EDNSOptionClientSubnet) WriteOptionData(writer *dnsWriter) error {
+	// The address of the prefix has its bits past the source prefix length cleared
+	prefix, err := option.Address.Prefix(int(option.SourcePrefix))
+	if err != nil {
+		return fmt.Errorf("CLIENT-SUBNET: invalid source prefix length: %d", option.SourcePrefix)
+	}
+
+	writer.writeUint16(option.Family)
+	writer.writeData([]byte{option.SourcePrefix, option.ScopePrefix})
+	writer.writeData(prefix.Addr().AsSlice()[:getPrefixByteLength(option.SourcePrefix)])
+	return nil
+}
+
+func (option *EDNSOptionClientSubnet) ReadOptionData(reader *dnsReader, length uint16) (err error) {
+	if length < 4 {
+		return fmt.Errorf("CLIENT-SUBNET: too short")
+	}
+
+	option.Family = reader.readUint16()
+	prefixes, err := reader.readUntil(2)
+	if err != nil {
+		return fmt.Errorf("CLIENT-SUBNET: %w", err)
+	}
+	option.SourcePrefix, option.ScopePrefix = prefixes[0], prefixes[1]
+
+	var address []byte
+	switch option.Family {
+	case ECSFamilyIPv4:
+		address = make([]byte, 4)
+	case ECSFamilyIPv6:
+		address = make([]byte, 16)
+	default:
+		return fmt.Errorf("CLIENT-SUBNET: unknown address family: %d", option.Family)
+	}
+
+	addressLength := int(length) - 4
+	if int(option.SourcePrefix) > len(address)*8 || addressLength != getPrefixByteLength(option.SourcePrefix) {
+		return fmt.Errorf("CLIENT-SUBNET: address length %d does not match source prefix length %d", addressLength, option.SourcePrefix)
+	}
+
+	data, err := reader.readUntil(addressLength)
+	if err != nil {
+		return fmt.Errorf("CLIENT-SUBNET: %w", err)
+	}
+	copy(address, data)
+
+	option.Address, _ = netip.AddrFromSlice(address)
+	if reader.mode == DecodeStrict {
+		if prefix := netip.PrefixFrom(option.Address, int(option.SourcePrefix)); prefix.Masked().Addr() != option.Address {
+			return fmt.Errorf("CLIENT-SUBNET: address %s has bits set past source prefix length %d", option.Address, option.SourcePrefix)
+		}
+	}
+	return nil
+}
+
+func getPrefixByteLength(prefixLength uint8) int {
+	return (int(prefixLength) + 7) / 8
+}
+
+// -------------- COOKIE
+// DNS Cookie option format [RFC7873]
+
+//                +0 (MSB)                            +1 (LSB)
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//  0: |                                                               |
+//     /                   Client Cookie (8 bytes)                     /
+//     |                                                               |
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//  8: |                                                               |
+//     /            Server Cookie (absent, or 8 to 32 bytes)           /
+//     /                                                               /
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+
+const (
+	ClientCookieLength    = 8
+	MinServerCookieLength = 8
+	MaxServerCookieLength = 32
+)
+
+type EDNSOptionCookie struct {
+	ClientCookie [ClientCookieLength]byte
+	ServerCookie []byte // Empty until the server has sent one
+}
+
+func (option *EDNSOptionCookie) Code() uint16 {
+	return EDNS0COOKIE
+}
+
+func (option *EDNSOptionCookie) String() string {
+	return fmt.Sprintf("%s: %s%s", EDNSOptionCode(EDNS0COOKIE), hex.EncodeToString(option.ClientCookie[:]), hex.EncodeToString(option.ServerCookie))
+}
+
+func (option *EDNSOptionCookie) WriteOptionData(writer *dnsWriter) error {
+	if len(option.ServerCookie) != 0 && (len(option.ServerCookie) < MinServerCookieLength || len(option.ServerCookie) > MaxServerCookieLength) {
+		return fmt.Errorf("COOKIE: invalid server cookie length: %d", len(option.ServerCookie))
+	}
+
+	writer.writeData(option.ClientCookie[:])
+	writer.writeData(option.ServerCookie)
+	return nil
+}
+
+func (option *EDNSOptionCookie) ReadOptionData(reader *dnsReader, length uint16) (err error) {
+	serverCookieLength := int(length) - ClientCookieLength
+	if serverCookieLength < 0 || (serverCookieLength != 0 && (serverCookieLength < MinServerCookieLength || serverCookieLength > MaxServerCookieLength)) {
+		return fmt.Errorf("COOKIE: invalid length: %d", length)
+	}
+
+	data, err := reader.readUntil(int(length))
+	if err != nil {
+		return fmt.Errorf("COOKIE: %w", err)
+	}
+
+	copy(option.ClientCookie[:], data[:ClientCookieLength])
+	option.ServerCookie = nil
+	if serverCookieLength > 0 {
+		option.ServerCookie = append([]byte{}, data[ClientCookieLength:]...)
+	}
+	return nil
+}
+
+// -------------- PADDING
+// Padding option format [RFC7830]
+// The option data is Length zero bytes, added to hide the size of encrypted messages.
+
+// Block sizes recommended by the Block-Length Padding policy [RFC8467]
+const (
+	QueryPaddingBlockSize    = 128
+	ResponsePaddingBlockSize = 468
+)
+
+type EDNSOptionPadding struct {
+	Length uint16 // Number of padding bytes
+}
+
+// PadMessage adds a padding option to the message's EDNS options so that the encoded
+// message length is a multiple of blockSize. EDNS parameters are added if there are none.
+// This is meant for encrypted transports: padding is of no use over plain UDP or TCP.
+//
+// Parameters:
+//   - message: The message to pad.
+//   - blockSize: The block size, ex. QueryPaddingBlockSize for queries.
+//
+// Returns:
+//   - Message: A copy of the message with padded EDNS options.
+//   - error: If the message cannot be encoded.
+func PadMessage(message Message, blockSize int) (Message, error) {
+	message = applyDnssecOk(message)
+
+	// Copy the EDNS parameters so the caller's message is left untouched
+	edns := EDNS{UDPSize: DefaultEDNSUDPSize}
+	if message.EDNS != nil {
+		edns = *message.EDNS
+	}
+	edns.Options = append([]EDNSOption{}, edns.Options...)
+	message.EDNS = &edns
+	message.UpdateHeaderCounts()
+
+	data, err := EncodeMessage(message)
+	if err != nil {
+		return Message{}, err
+	}
+
+	// The padding option header counts towards the padded length
+	paddedLength := len(data) + 4
+	padding := &EDNSOptionPadding{}
+	if blockSize > 0 && paddedLength%blockSize != 0 {
+		padding.Length = uint16(blockSize - paddedLength%blockSize)
+	}
+
+	edns.Options = append(edns.Options, padding)
+
+	return message, nil
+}
+
+func (option *EDNSOptionPadding) Code() uint16 {
+	return EDNS0PADDING
+}
+
+func (option *EDNSOptionPadding) String() string {
+	return fmt.Sprintf("%s: %d bytes", EDNSOptionCode(EDNS0PADDING), option.Length)
+}
+
+func (option *EDNSOptionPadding) WriteOptionData(writer *dnsWriter) error {
+	writer.writeData(make([]byte, option.Length))
+	return nil
+}
+
+func (option *EDNSOptionPadding) ReadOptionData(reader *dnsReader, length uint16) (err error) {
+	// The padding content should be zeros but must be ignored
+	if _, err = reader.readUntil(int(length)); err != nil {
+		return fmt.Errorf("PADDING: %w", err)
+	}
+	option.Length = length
+	return nil
+}
+
+// -------------- EDE
+// Extended DNS Error option format [RFC8914]
+
+//                +0 (MSB)                            +1 (LSB)
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//  0: |                            INFO-CODE                          |
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//  2: / EXTRA-TEXT ...                                                /
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+
+type EDNSOptionEDE struct {
+	InfoCode  uint16
+	ExtraText string // UTF-8 text for humans, may be empty
+}
+
+type EDEInfoCode uint16
+
+const (
+	EDEOther                       uint16 = 0  // Other Error [RFC8914]
+	EDEUnsupportedDNSKEYAlgorithm  uint16 = 1  // Unsupported DNSKEY Algorithm [RFC8914]
+	EDEUnsupportedDSDigestType     uint16 = 2  // Unsupported DS Digest Type [RFC8914]
+	EDEStaleAnswer                 uint16 = 3  // Stale Answer [RFC8914][RFC8767]
+	EDEForgedAnswer                uint16 = 4  // Forged Answer [RFC8914]
+	EDEDNSSECIndeterminate         uint16 = 5  // DNSSEC Indeterminate [RFC8914]
+	EDEDNSSECBogus                 uint16 = 6  // DNSSEC Bogus [RFC8914]
+	EDESignatureExpired            uint16 = 7  // Signature Expired [RFC8914]
+	EDESignatureNotYetValid        uint16 = 8  // Signature Not Yet Valid [RFC8914]
+	EDEDNSKEYMissing               uint16 = 9  // DNSKEY Missing [RFC8914]
+	EDERRSIGsMissing               uint16 = 10 // RRSIGs Missing [RFC8914]
+	EDENoZoneKeyBitSet             uint16 = 11 // No Zone Key Bit Set [RFC8914]
+	EDENSECMissing                 uint16 = 12 // NSEC Missing [RFC8914]
+	EDECachedError                 uint16 = 13 // Cached Error [RFC8914]
+	EDENotReady                    uint16 = 14 // Not Ready [RFC8914]
+	EDEBlocked                     uint16 = 15 // Blocked [RFC8914]
+	EDECensored                    uint16 = 16 // Censored [RFC8914]
+	EDEFiltered                    uint16 = 17 // Filtered [RFC8914]
+	EDEProhibited                  uint16 = 18 // Prohibited [RFC8914]
+	EDEStaleNXDOMAINAnswer         uint16 = 19 // Stale NXDOMAIN Answer [RFC8914]
+	EDENotAuthoritative            uint16 = 20 // Not Authoritative [RFC8914]
+	EDENotSupported                uint16 = 21 // Not Supported [RFC8914]
+	EDENoReachableAuthority        uint16 = 22 // No Reachable Authority [RFC8914]
+	EDENetworkError                uint16 = 23 // Network Error [RFC8914]
+	EDEInvalidData                 uint16 = 24 // Invalid Data [RFC8914]
+	EDESignatureExpiredBeforeValid uint16 = 25 // Signature Expired before Valid [RFC9077]
+	EDETooEarly                    uint16 = 26 // Too Early [RFC9250]
+	EDEUnsupportedNSEC3Iterations  uint16 = 27 // Unsupported NSEC3 Iterations Value [RFC9276]
+	EDEUnableToConformToPolicy     uint16 = 28 // Unable to conform to policy [draft-homburg-dnsop-codcp]
+	EDESynthesized                 uint16 = 29 // Synthesized [RFC9567]
+	EDEInvalidQueryType            uint16 = 30 // Invalid Query Type [RFC9824]
+)
+
+var edeInfoCodeNames = map[uint16]string{
+	EDEOther:                       "Other Error",
+	EDEUnsupportedDNSKEYAlgorithm:  "Unsupported DNSKEY Algorithm",
+	EDEUnsupportedDSDigestType:     "Unsupported DS Digest Type",
+	EDEStaleAnswer:                 "Stale Answer",
+	EDEForgedAnswer:                "Forged Answer",
+	EDEDNSSECIndeterminate:         "DNSSEC Indeterminate",
+	EDEDNSSECBogus:                 "DNSSEC Bogus",
+	EDESignatureExpired:            "Signature Expired",
+	EDESignatureNotYetValid:        "Signature Not Yet Valid",
+	EDEDNSKEYMissing:               "DNSKEY Missing",
+	EDERRSIGsMissing:               "RRSIGs Missing",
+	EDENoZoneKeyBitSet:             "No Zone Key Bit Set",
+	EDENSECMissing:                 "NSEC Missing",
+	EDECachedError:                 "Cached Error",
+	EDENotReady:                    "Not Ready",
+	EDEBlocked:                     "Blocked",
+	EDECensored:                    "Censored",
+	EDEFiltered:                    "Filtered",
+	EDEProhibited:                  "Prohibited",
+	EDEStaleNXDOMAINAnswer:         "Stale NXDOMAIN Answer",
+	EDENotAuthoritative:            "Not Authoritative",
+	EDENotSupported:                "Not Supported",
+	EDENoReachableAuthority:        "No Reachable Authority",
+	EDENetworkError:                "Network Error",
+	EDEInvalidData:                 "Invalid Data",
+	EDESignatureExpiredBeforeValid: "Signature Expired before Valid",
+	EDETooEarly:                    "Too Early",
+	EDEUnsupportedNSEC3Iterations:  "Unsupported NSEC3 Iterations Value",
+	EDEUnableToConformToPolicy:     "Unable to conform to policy",
+	EDESynthesized:                 "Synthesized",
+	EDEInvalidQueryType:            "Invalid Query Type",
+}
+
+func (code EDEInfoCode) String() string {
+	if n, ok := edeInfoCodeNames[uint16(code)]; ok {
+		return n
+	}
+	return "Unknown"
+}
+
+func (option *EDNSOptionEDE) Code() uint16 {
+	return EDNS0EDE
+}
+
+func (option *EDNSOptionEDE) String() string {
+	ede := fmt.Sprintf("%s: %d (%s)", EDNSOptionCode(EDNS0EDE), option.InfoCode, EDEInfoCode(option.InfoCode))
+	if option.ExtraText != "" {
+		ede += fmt.Sprintf(": (%s)", option.ExtraText)
+	}
+	return ede
+}
+
+func (option *EDNSOptionEDE) WriteOptionData(writer *dnsWriter) error {
+	writer.writeUint16(option.InfoCode)
+	writer.writeData([]byte(option.ExtraText))
+	return nil
+}
+
+func (option *EDNSOptionEDE) ReadOptionData(reader *dnsReader, length uint16) (err error) {
+	if length < 2 {
+		return fmt.Errorf("EDE: too short")
+	}
+
+	option.InfoCode = reader.readUint16()
+	extraText, err := reader.readUntil(int(length) - 2)
+	if err != nil {
+		return fmt.Errorf("EDE: %w", err)
+	}
+	// Some implementations wrongly terminate the text with a NUL byte
+	option.ExtraText = strings.TrimRight(string(extraText), "\x00")
+	return nil
+}
+
+// -------------- UNKNOWN
+
+type EDNSOptionUnknown struct {
+	OptionCode uint16
+	Data       []byte
+}
+
+func (option *EDNSOptionUnknown) Code() uint16 {
+	return option.OptionCode
+}
+
+func (option *EDNSOptionUnknown) String() string {
+	return fmt.Sprintf("%s: %s", EDNSOptionCode(option.OptionCode), hex.EncodeToString(option.Data))
+}
+
+func (option *EDNSOptionUnknown) WriteOptionData(writer *dnsWriter) error {
+	writer.writeData(option.Data)
+	return nil
+}
+
+func (option *EDNSOptionUnknown) ReadOptionData(reader *dnsReader, length uint16) (err error) {
+	data, err := reader.readUntil(int(length))
+	if err != nil {
+		return err
+	}
+	option.Data = append([]byte{}, data...)
+	return nil
+}
@@ -0,0 +1,663 @@
+package dns
+
+import (
+	"bytes"
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeOPTRecord(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		want      EDNS
+		wantError error
+	}{
+		{
+			name: "OPT record without options",
+			data: []byte{
+				0,     // Name: root
+				0, 41, // RType: 41 (OPT)
+				0x04, 0xd0, // RClass: UDP payload size 1232
+				0, 0, 0x80, 0, // TTL: extended RCODE 0, version 0, DO bit set
+				0, 0, // RDLength: 0
+			},
+			want: EDNS{
+				UDPSize:  1232,
+				DnssecOk: true,
+			},
+			wantError: nil,
+		},
+		{
+			name: "OPT record with options",
+			data: []byte{
+				0,     // Name: root
+				0, 41, // RType: 41 (OPT)
+				0x10, 0x00, // RClass: UDP payload size 4096
+				1, 0, 0, 0, // TTL: extended RCODE 1, version 0, no flags
+				0, 12, // RDLength: 12
+				0, 3, 0, 0, // Option code: 3 (NSID), length: 0
+				0xfd, 0xe9, 0, 4, 'a', 'b', 'c', 'd', // Option code: 65001 (unknown), length: 4
+			},
+			want: EDNS{
+				UDPSize:       4096,
+				ExtendedRCode: 1,
+				Options: []EDNSOption{
+					&EDNSOptionNSID{NSID: []byte{}},
+					&EDNSOptionUnknown{OptionCode: 65001, Data: []byte{'a', 'b', 'c', 'd'}},
+				},
+			},
+			wantError: nil,
+		},
+		{
+			name: "Invalid OPT record: option longer than RDATA",
+			data: []byte{
+				0,     // Name: root
+				0, 41, // RType: 41 (OPT)
+				0x04, 0xd0, // RClass: UDP payload size 1232
+				0, 0, 0, 0, // TTL
+				0, 6, // RDLength: 6
+				0xfd, 0xe9, 0, 4, 'a', 'b', // Option code: 65001, length: 4 but only 2 bytes
+			},
+			want:      EDNS{},
+			wantError: ErrInvalidResourceRecord,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &dnsReader{data: tt.data}
+
+			record, err := reader.readResourceRecord()
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("readResourceRecord() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readResourceRecord() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test ParseEDNS
+			got, err := ParseEDNS(record)
+			if err != nil {
+				t.Fatalf("ParseEDNS() unexpected error = %v\n", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseEDNS() got = %+v, want = %+v\n", got, tt.want)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{}
+			writer.writeResourceRecord(got.ResourceRecord())
+
+			if !bytes.Equal(writer.data, tt.data) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, tt.data)
+			}
+		})
+	}
+}
+
+func TestParseEDNSInvalidRecord(t *testing.T) {
+	record := ResourceRecord{Name: "example.com.", RType: A, RClass: IN}
+
+	_, err := ParseEDNS(record)
+
+	if err == nil || !errors.Is(err, ErrInvalidResourceRecord) {
+		t.Errorf("ParseEDNS() error = %v, want error = %v\n", err, ErrInvalidResourceRecord)
+	}
+}
+
+func TestEDNSString(t *testing.T) {
+	edns := EDNS{UDPSize: 1232, DnssecOk: true}
+	want := "EDNS: version: 0, flags: do; udp: 1232"
+
+	got := edns.String()
+
+	if got != want {
+		t.Errorf("String() got = %s, want = %s\n", got, want)
+	}
+}
+
+func TestEDNSOptionClientSubnet(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		want       EDNSOption
+		wantString string
+		wantError  error
+	}{
+		{
+			name: "IPv4 client subnet",
+			data: []byte{
+				0, 1, // Family: 1 (IPv4)
+				24,        // Source prefix length: 24
+				0,         // Scope prefix length: 0
+				192, 0, 2, // Address: 192.0.2.0/24, truncated to 3 bytes
+			},
+			want: &EDNSOptionClientSubnet{
+				Family:       ECSFamilyIPv4,
+				SourcePrefix: 24,
+				ScopePrefix:  0,
+				Address:      netip.MustParseAddr("192.0.2.0"),
+			},
+			wantString: "CLIENT-SUBNET: 192.0.2.0/24/0",
+			wantError:  nil,
+		},
+		{
+			name: "IPv6 client subnet with scope",
+			data: []byte{
+				0, 2, // Family: 2 (IPv6)
+				56,                                       // Source prefix length: 56
+				48,                                       // Scope prefix length: 48
+				0x20, 0x01, 0x0d, 0xb8, 0x12, 0x34, 0x56, // Address: 2001:db8:1234:5600::/56, truncated to 7 bytes
+			},
+			want: &EDNSOptionClientSubnet{
+				Family:       ECSFamilyIPv6,
+				SourcePrefix: 56,
+				ScopePrefix:  48,
+				Address:      netip.MustParseAddr("2001:db8:1234:5600::"),
+			},
+			wantString: "CLIENT-SUBNET: 2001:db8:1234:5600::/56/48",
+			wantError:  nil,
+		},
+		{
+			name: "Invalid client subnet: address longer than source prefix",
+			data: []byte{
+				0, 1, // Family: 1 (IPv4)
+				16, 0, // Source prefix length: 16, scope: 0
+				192, 0, 2, // 3 address bytes instead of 2
+			},
+			want:      &EDNSOptionClientSubnet{},
+			wantError: ErrInvalidResourceRecord,
+		},
+		{
+			name: "Invalid client subnet: unknown family",
+			data: []byte{
+				0, 3, // Family: 3
+				8, 0, // Source prefix length: 8, scope: 0
+				10,
+			},
+			want:      &EDNSOptionClientSubnet{},
+			wantError: ErrInvalidResourceRecord,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := []byte{
+				0,     // Name: root
+				0, 41, // RType: 41 (OPT)
+				0x04, 0xd0, // RClass: UDP payload size 1232
+				0, 0, 0, 0, // TTL
+				0, byte(len(tt.data) + 4), // RDLength
+				0, 8, 0, byte(len(tt.data)), // Option code: 8 (CLIENT-SUBNET), length
+			}
+			record = append(record, tt.data...)
+			reader := &dnsReader{data: record}
+
+			got, err := reader.readResourceRecord()
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("readResourceRecord() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readResourceRecord() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test Decode
+			options := got.RData.(*RDataOPT).Options
+			if len(options) != 1 || !reflect.DeepEqual(options[0], tt.want) {
+				t.Fatalf("Decode() got = %+v, want = %+v, data = %v\n", options, tt.want, tt.data)
+			}
+
+			// Test String
+			gotString := options[0].String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{}
+			writer.writeResourceRecord(got)
+
+			if !bytes.Equal(writer.data, record) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, record)
+			}
+		})
+	}
+}
+
+func TestEDNSOptionClientSubnetPrefixBits(t *testing.T) {
+	record := []byte{
+		0,     // Name: root
+		0, 41, // RType: 41 (OPT)
+		0x04, 0xd0, // RClass: UDP payload size 1232
+		0, 0, 0, 0, // TTL
+		0, 11, // RDLength
+		0, 8, 0, 7, // Option code: 8 (CLIENT-SUBNET), length
+		0, 1, // Family: 1 (IPv4)
+		23, 0, // Source prefix length: 23, scope: 0
+		192, 0, 3, // Address: 192.0.3.0, whose 24th bit is past the source prefix
+	}
+
+	reader := &dnsReader{data: record, mode: DecodeStrict}
+	if _, err := reader.readResourceRecord(); !errors.Is(err, ErrInvalidResourceRecord) {
+		t.Errorf("readResourceRecord() strict error = %v, want error = %v\n", err, ErrInvalidResourceRecord)
+	}
+
+	reader = &dnsReader{data: record}
+	got, err := reader.readResourceRecord()
+	if err != nil {
+		t.Fatalf("readResourceRecord() unexpected error = %v\n", err)
+	}
+
+	// The bits past the source prefix are cleared when the option is written
+	writer := &dnsWriter{}
+	writer.writeResourceRecord(got)
+	want := append(append([]byte{}, record[:len(record)-1]...), 2)
+	if !bytes.Equal(writer.data, want) {
+		t.Errorf("Encode() got = %v, want = %v\n", writer.data, want)
+	}
+}
+
+func TestParseClientSubnet(t *testing.T) {
+	tests := []struct {
+		name      string
+		subnet    string
+		want      *EDNSOptionClientSubnet
+		wantError bool
+	}{
+		{
+			name:   "IPv4 prefix with host bits",
+			subnet: "192.0.2.55/24",
+			want: &EDNSOptionClientSubnet{
+				Family:       ECSFamilyIPv4,
+				SourcePrefix: 24,
+				Address:      netip.MustParseAddr("192.0.2.0"),
+			},
+		},
+		{
+			name:   "IPv6 address",
+			subnet: "2001:db8::1",
+			want: &EDNSOptionClientSubnet{
+				Family:       ECSFamilyIPv6,
+				SourcePrefix: 128,
+				Address:      netip.MustParseAddr("2001:db8::1"),
+			},
+		},
+		{
+			name:      "Invalid subnet",
+			subnet:    "example.com",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseClientSubnet(tt.subnet)
+
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("ParseClientSubnet() expected error, got = %+v\n", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseClientSubnet() unexpected error = %v\n", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseClientSubnet() got = %+v, want = %+v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEDNSOptionCookie(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		want       EDNSOption
+		wantString string
+		wantError  error
+	}{
+		{
+			name: "Client cookie only",
+			data: []byte{1, 2, 3, 4, 5, 6, 7, 8},
+			want: &EDNSOptionCookie{
+				ClientCookie: [8]byte{1, 2, 3, 4, 5, 6, 7, 8},
+			},
+			wantString: "COOKIE: 0102030405060708",
+			wantError:  nil,
+		},
+		{
+			name: "Client and server cookies",
+			data: []byte{
+				1, 2, 3, 4, 5, 6, 7, 8, // Client cookie
+				0xa, 0xb, 0xc, 0xd, 0xe, 0xf, 0x10, 0x11, // Server cookie
+			},
+			want: &EDNSOptionCookie{
+				ClientCookie: [8]byte{1, 2, 3, 4, 5, 6, 7, 8},
+				ServerCookie: []byte{0xa, 0xb, 0xc, 0xd, 0xe, 0xf, 0x10, 0x11},
+			},
+			wantString: "COOKIE: 01020304050607080a0b0c0d0e0f1011",
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid cookie: client cookie too short",
+			data:      []byte{1, 2, 3, 4},
+			want:      &EDNSOptionCookie{},
+			wantError: ErrInvalidResourceRecord,
+		},
+		{
+			name:      "Invalid cookie: server cookie too short",
+			data:      []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			want:      &EDNSOptionCookie{},
+			wantError: ErrInvalidResourceRecord,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := []byte{
+				0,     // Name: root
+				0, 41, // RType: 41 (OPT)
+				0x04, 0xd0, // RClass: UDP payload size 1232
+				0, 0, 0, 0, // TTL
+				0, byte(len(tt.data) + 4), // RDLength
+				0, 10, 0, byte(len(tt.data)), // Option code: 10 (COOKIE), length
+			}
+			record = append(record, tt.data...)
+			reader := &dnsReader{data: record}
+
+			got, err := reader.readResourceRecord()
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("readResourceRecord() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readResourceRecord() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test Decode
+			options := got.RData.(*RDataOPT).Options
+			if len(options) != 1 || !reflect.DeepEqual(options[0], tt.want) {
+				t.Fatalf("Decode() got = %+v, want = %+v, data = %v\n", options, tt.want, tt.data)
+			}
+
+			// Test String
+			gotString := options[0].String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{}
+			writer.writeResourceRecord(got)
+
+			if !bytes.Equal(writer.data, record) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, record)
+			}
+		})
+	}
+}
+
+func TestEDNSOptionNSID(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		want       EDNSOption
+		wantString string
+	}{
+		{
+			name:       "NSID request",
+			data:       []byte{},
+			want:       &EDNSOptionNSID{NSID: []byte{}},
+			wantString: "NSID",
+		},
+		{
+			name:       "Printable NSID",
+			data:       []byte{'g', 'p', 'd', 'n', 's', '-', 'a', 'm', 's'},
+			want:       &EDNSOptionNSID{NSID: []byte("gpdns-ams")},
+			wantString: "NSID: 6770646e732d616d73 (\"gpdns-ams\")",
+		},
+		{
+			name:       "Binary NSID",
+			data:       []byte{0xde, 0xad, 0xbe, 0xef},
+			want:       &EDNSOptionNSID{NSID: []byte{0xde, 0xad, 0xbe, 0xef}},
+			wantString: "NSID: deadbeef",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := []byte{
+				0,     // Name: root
+				0, 41, // RType: 41 (OPT)
+				0x04, 0xd0, // RClass: UDP payload size 1232
+				0, 0, 0, 0, // TTL
+				0, byte(len(tt.data) + 4), // RDLength
+				0, 3, 0, byte(len(tt.data)), // Option code: 3 (NSID), length
+			}
+			record = append(record, tt.data...)
+			reader := &dnsReader{data: record}
+
+			got, err := reader.readResourceRecord()
+			if err != nil {
+				t.Fatalf("readResourceRecord() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test Decode
+			options := got.RData.(*RDataOPT).Options
+			if len(options) != 1 || !reflect.DeepEqual(options[0], tt.want) {
+				t.Fatalf("Decode() got = %+v, want = %+v, data = %v\n", options, tt.want, tt.data)
+			}
+
+			// Test String
+			gotString := options[0].String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{}
+			writer.writeResourceRecord(got)
+
+			if !bytes.Equal(writer.data, record) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, record)
+			}
+		})
+	}
+}
+
+func TestEDNSOptionPadding(t *testing.T) {
+	data := []byte{
+		0,     // Name: root
+		0, 41, // RType: 41 (OPT)
+		0x04, 0xd0, // RClass: UDP payload size 1232
+		0, 0, 0, 0, // TTL
+		0, 8, // RDLength: 8
+		0, 12, 0, 4, // Option code: 12 (PADDING), length: 4
+		0, 0, 0, 0, // Padding
+	}
+	reader := &dnsReader{data: data}
+
+	record, err := reader.readResourceRecord()
+	if err != nil {
+		t.Fatalf("readResourceRecord() unexpected error = %v\n", err)
+	}
+
+	options := record.RData.(*RDataOPT).Options
+	if len(options) != 1 || !reflect.DeepEqual(options[0], &EDNSOptionPadding{Length: 4}) {
+		t.Fatalf("Decode() got = %+v\n", options)
+	}
+	if got := options[0].String(); got != "PADDING: 4 bytes" {
+		t.Errorf("String() got = %s, want = PADDING: 4 bytes\n", got)
+	}
+
+	writer := &dnsWriter{}
+	writer.writeResourceRecord(record)
+	if !bytes.Equal(writer.data, data) {
+		t.Errorf("Encode() got = %v, want = %v\n", writer.data, data)
+	}
+}
+
+func TestPadMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   Message
+		blockSize int
+	}{
+		{
+			name: "Query without OPT record",
+			message: Message{
+				Header:    Header{Id: 1, Flags: Flags{RecursionDesired: true}, QuestionCount: 1},
+				Questions: []Question{{Name: "example.com.", QType: A, QClass: IN}},
+			},
+			blockSize: QueryPaddingBlockSize,
+		},
+		{
+			name: "Query with DO bit and cookie",
+			message: Message{
+				Header:    Header{Id: 1, Flags: Flags{RecursionDesired: true, DnssecOk: true}, QuestionCount: 1, AdditionalRRCount: 1},
+				Questions: []Question{{Name: "www.example.org.", QType: AAAA, QClass: IN}},
+				EDNS:      &EDNS{UDPSize: 1232, Options: []EDNSOption{&EDNSOptionCookie{}}},
+			},
+			blockSize: QueryPaddingBlockSize,
+		},
+		{
+			name: "Response block size",
+			message: Message{
+				Header:    Header{Id: 1, Flags: Flags{Response: true}, QuestionCount: 1},
+				Questions: []Question{{Name: "example.com.", QType: A, QClass: IN}},
+			},
+			blockSize: ResponsePaddingBlockSize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PadMessage(tt.message, tt.blockSize)
+			if err != nil {
+				t.Fatalf("PadMessage() unexpected error = %v\n", err)
+			}
+
+			data, err := EncodeMessage(got)
+			if err != nil {
+				t.Fatalf("EncodeMessage() unexpected error = %v\n", err)
+			}
+			if len(data)%tt.blockSize != 0 {
+				t.Errorf("PadMessage() encoded length got = %d, want a multiple of %d\n", len(data), tt.blockSize)
+			}
+
+			decoded, err := DecodeMessage(data)
+			if err != nil {
+				t.Fatalf("DecodeMessage() unexpected error = %v\n", err)
+			}
+			if decoded.Header.Flags.DnssecOk != tt.message.Header.Flags.DnssecOk {
+				t.Errorf("PadMessage() DnssecOk got = %t, want = %t\n", decoded.Header.Flags.DnssecOk, tt.message.Header.Flags.DnssecOk)
+			}
+			if tt.message.EDNS != nil && (len(tt.message.EDNS.Options) != 1 || tt.message.EDNS.DnssecOk) {
+				t.Errorf("PadMessage() modified the original message\n")
+			}
+		})
+	}
+}
+
+func TestEDNSOptionEDE(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		want       EDNSOption
+		wantString string
+		wantError  error
+	}{
+		{
+			name:       "EDE without extra text",
+			data:       []byte{0, 6}, // Info code: 6 (DNSSEC Bogus)
+			want:       &EDNSOptionEDE{InfoCode: EDEDNSSECBogus},
+			wantString: "EDE: 6 (DNSSEC Bogus)",
+			wantError:  nil,
+		},
+		{
+			name: "EDE with extra text",
+			data: []byte{
+				0, 18, // Info code: 18 (Prohibited)
+				'n', 'o', 't', ' ', 'a', 'l', 'l', 'o', 'w', 'e', 'd', // Extra text
+			},
+			want:       &EDNSOptionEDE{InfoCode: EDEProhibited, ExtraText: "not allowed"},
+			wantString: "EDE: 18 (Prohibited): (not allowed)",
+			wantError:  nil,
+		},
+		{
+			name:       "EDE with unknown info code",
+			data:       []byte{0xff, 0x00},
+			want:       &EDNSOptionEDE{InfoCode: 65280},
+			wantString: "EDE: 65280 (Unknown)",
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid EDE: too short",
+			data:      []byte{0},
+			want:      &EDNSOptionEDE{},
+			wantError: ErrInvalidResourceRecord,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := []byte{
+				0,     // Name: root
+				0, 41, // RType: 41 (OPT)
+				0x04, 0xd0, // RClass: UDP payload size 1232
+				0, 0, 0, 0, // TTL
+				0, byte(len(tt.data) + 4), // RDLength
+				0, 15, 0, byte(len(tt.data)), // Option code: 15 (EDE), length
+			}
+			record = append(record, tt.data...)
+			reader := &dnsReader{data: record}
+
+			got, err := reader.readResourceRecord()
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("readResourceRecord() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readResourceRecord() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test Decode
+			options := got.RData.(*RDataOPT).Options
+			if len(options) != 1 || !reflect.DeepEqual(options[0], tt.want) {
+				t.Fatalf("Decode() got = %+v, want = %+v, data = %v\n", options, tt.want, tt.data)
+			}
+
+			// Test String
+			gotString := options[0].String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{}
+			writer.writeResourceRecord(got)
+
+			if !bytes.Equal(writer.data, record) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, record)
+			}
+		})
+	}
+}
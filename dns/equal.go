@@ -0,0 +1,160 @@
+package dns
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+)
+
+// Equal reports whether two questions ask for the same name, type and class.
+// Names are compared case insensitively [RFC4343], with or without their final dot.
+func (question Question) Equal(other Question) bool {
+	return EqualNames(question.Name, other.Name) &&
+		question.QType == other.QType &&
+		question.QClass == other.QClass
+}
+
+// Copy returns a copy of the question.
+func (question Question) Copy() Question {
+	return question
+}
+
+// Equal reports whether two records have the same name, type, class, TTL and RData.
+// Their RData are compared in canonical form [RFC4034]: the RDLength, which depends on
+// the compression of the message, is ignored, and so is the case of the owner name and
+// of the domain names in the RData of the types which have them in lowercase in canonical form.
+func (record ResourceRecord) Equal(other ResourceRecord) bool {
+	if !EqualNames(record.Name, other.Name) ||
+		record.RType != other.RType ||
+		record.RClass != other.RClass ||
+		record.TTL != other.TTL {
+		return false
+	}
+
+	a, errA := getCanonicalRData(record)
+	b, errB := getCanonicalRData(other)
+	if errA != nil || errB != nil {
+		// RData which cannot be encoded can only be compared field by field
+		return reflect.DeepEqual(record.RData, other.RData)
+	}
+	return bytes.Equal(a, b)
+}
+
+// Copy returns a copy of the record which shares no memory with it, its RData decoded from its encoding.
+// The RData is shared if it cannot be encoded and decoded back, ex. RData of the wrong type for the record.
+func (record ResourceRecord) Copy() ResourceRecord {
+	if record.RData == nil {
+		return record
+	}
+	data, err := EncodeRData(record.RData)
+	if err != nil {
+		return record
+	}
+	if rdata, err := decodeRData(record.RType, data); err == nil {
+		record.RData = rdata
+	}
+	return record
+}
+
+// Equal reports whether two messages have the same ID, flags, questions, records and EDNS parameters,
+// comparing their questions and records with their Equal methods. The header's section counts,
+// which depend on the encoding, and the decoding warnings are ignored.
+func (message Message) Equal(other Message) bool {
+	if message.Header.Id != other.Header.Id || message.Header.Flags != other.Header.Flags {
+		return false
+	}
+	if !isEqualEDNS(message.EDNS, other.EDNS) {
+		return false
+	}
+
+	if len(message.Questions) != len(other.Questions) {
+		return false
+	}
+	for i, question := range message.Questions {
+		if !question.Equal(other.Questions[i]) {
+			return false
+		}
+	}
+
+	for _, section := range []struct {
+		a []ResourceRecord
+		b []ResourceRecord
+	}{
+		{message.Answers, other.Answers},
+		{message.NameServers, other.NameServers},
+		{message.Additionals, other.Additionals},
+	} {
+		if len(section.a) != len(section.b) {
+			return false
+		}
+		for i, record := range section.a {
+			if !record.Equal(section.b[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Copy returns a deep copy of the message, with copies of its questions, records and EDNS parameters.
+func (message Message) Copy() Message {
+	copied := Message{
+		Header:   message.Header,
+		Warnings: append([]error(nil), message.Warnings...),
+	}
+	if message.Questions != nil {
+		copied.Questions = append([]Question{}, message.Questions...)
+	}
+
+	for _, section := range []struct {
+		records []ResourceRecord
+		copied  *[]ResourceRecord
+	}{
+		{message.Answers, &copied.Answers},
+		{message.NameServers, &copied.NameServers},
+		{message.Additionals, &copied.Additionals},
+	} {
+		if section.records == nil {
+			continue
+		}
+		*section.copied = make([]ResourceRecord, 0, len(section.records))
+		for _, record := range section.records {
+			*section.copied = append(*section.copied, record.Copy())
+		}
+	}
+
+	if message.EDNS != nil {
+		// Copy the options through the OPT record, whose RData holds them
+		edns, err := ParseEDNS(message.EDNS.ResourceRecord().Copy())
+		if err != nil {
+			edns = *message.EDNS
+		}
+		copied.EDNS = &edns
+	}
+	return copied
+}
+
+// getCanonicalRData returns the RData of a record encoded in canonical form, see CanonicalRecord,
+// with the next domain name of NSEC records in lowercase too.
+func getCanonicalRData(record ResourceRecord) ([]byte, error) {
+	canonical, err := CanonicalRecord(record)
+	if err != nil {
+		return nil, err
+	}
+	if rdata, ok := canonical.RData.(*RDataNSEC); ok {
+		// Kept as is in canonical form [RFC6840], but compared case insensitively like all names
+		rdata.NextDomainName = strings.ToLower(rdata.NextDomainName)
+	}
+	return EncodeRData(canonical.RData)
+}
+
+// isEqualEDNS reports whether two messages have the same EDNS parameters, comparing their
+// OPT records so that options are compared by value, whether there are none or an empty list.
+func isEqualEDNS(a *EDNS, b *EDNS) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	recordA, errA := EncodeCanonicalRecord(a.ResourceRecord())
+	recordB, errB := EncodeCanonicalRecord(b.ResourceRecord())
+	return errA == nil && errB == nil && bytes.Equal(recordA, recordB)
+}
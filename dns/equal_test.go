@@ -0,0 +1,110 @@
+package dns
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestResourceRecordEqual(t *testing.T) {
+	mx := ResourceRecord{Name: "example.com.", RType: MX, RClass: IN, TTL: 300, RDLength: 20, RData: &RDataMX{Preference: 10, Exchange: "mail.example.com."}}
+
+	tests := []struct {
+		name  string
+		other ResourceRecord
+		want  bool
+	}{
+		{
+			name:  "Same record",
+			other: mx,
+			want:  true,
+		},
+		{
+			name:  "Names in another case, compressed RDLength",
+			other: ResourceRecord{Name: "EXAMPLE.com", RType: MX, RClass: IN, TTL: 300, RDLength: 9, RData: &RDataMX{Preference: 10, Exchange: "Mail.Example.com."}},
+			want:  true,
+		},
+		{
+			name:  "Other TTL",
+			other: ResourceRecord{Name: "example.com.", RType: MX, RClass: IN, TTL: 60, RData: &RDataMX{Preference: 10, Exchange: "mail.example.com."}},
+			want:  false,
+		},
+		{
+			name:  "Other RData",
+			other: ResourceRecord{Name: "example.com.", RType: MX, RClass: IN, TTL: 300, RData: &RDataMX{Preference: 20, Exchange: "mail.example.com."}},
+			want:  false,
+		},
+		{
+			name:  "Other type",
+			other: ResourceRecord{Name: "example.com.", RType: 731, RClass: IN, TTL: 300, RData: &RDataMX{Preference: 10, Exchange: "mail.example.com."}},
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mx.Equal(tt.other); got != tt.want {
+				t.Errorf("Equal() got = %v, want = %v\n", got, tt.want)
+			}
+			if got := tt.other.Equal(mx); got != tt.want {
+				t.Errorf("Equal() is not symmetric: got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+
+	nsec := ResourceRecord{Name: "a.example.", RType: NSEC, RClass: IN, TTL: 300, RData: &RDataNSEC{NextDomainName: "b.example.", Types: []uint16{A}}}
+	other := ResourceRecord{Name: "a.example.", RType: NSEC, RClass: IN, TTL: 300, RData: &RDataNSEC{NextDomainName: "B.example.", Types: []uint16{A}}}
+	if !nsec.Equal(other) {
+		t.Errorf("Equal() got = false for NSEC next domain names in another case, want = true\n")
+	}
+}
+
+func TestMessageEqualAndCopy(t *testing.T) {
+	message := Message{
+		Header:    Header{Id: 1, Flags: Flags{Response: true}, QuestionCount: 1, AnswerRRCount: 1, AdditionalRRCount: 1},
+		Questions: []Question{{Name: "example.com.", QType: A, QClass: IN}},
+		Answers: []ResourceRecord{
+			{Name: "example.com.", RType: A, RClass: IN, TTL: 300, RData: &RDataA{IP: netip.MustParseAddr("192.0.2.1")}},
+		},
+		EDNS: &EDNS{UDPSize: 1232, Options: []EDNSOption{&EDNSOptionNSID{}}},
+	}
+
+	copied := message.Copy()
+	if !message.Equal(copied) {
+		t.Fatalf("Equal() got = false for a copy\n")
+	}
+
+	// The copy shares no memory with the message
+	copied.Questions[0].Name = "other.example."
+	copied.Answers[0].RData.(*RDataA).IP = netip.MustParseAddr("192.0.2.2")
+	copied.EDNS.UDPSize = 4096
+	copied.EDNS.Options[0].(*EDNSOptionNSID).NSID = []byte{1}
+	if message.Questions[0].Name != "example.com." ||
+		message.Answers[0].RData.String() != "192.0.2.1" ||
+		message.EDNS.UDPSize != 1232 ||
+		len(message.EDNS.Options[0].(*EDNSOptionNSID).NSID) != 0 {
+		t.Errorf("Copy() shares memory with the message: %+v\n", message)
+	}
+	if message.Equal(copied) {
+		t.Errorf("Equal() got = true for a modified copy\n")
+	}
+
+	// The encoded and decoded message is equal, whatever its header counts
+	data, err := EncodeMessage(message)
+	if err != nil {
+		t.Fatalf("EncodeMessage() unexpected error = %v\n", err)
+	}
+	decoded, err := DecodeMessage(data)
+	if err != nil {
+		t.Fatalf("DecodeMessage() unexpected error = %v\n", err)
+	}
+	decoded.Header.AdditionalRRCount = 0
+	if !message.Equal(decoded) {
+		t.Errorf("Equal() got = false for the decoded message: %+v\n", decoded)
+	}
+
+	withoutEDNS := message.Copy()
+	withoutEDNS.EDNS = nil
+	if message.Equal(withoutEDNS) {
+		t.Errorf("Equal() got = true for a message without EDNS\n")
+	}
+}
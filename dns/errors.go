@@ -12,6 +12,11 @@ var (
 	ErrInvalidRecordData     = fmt.Errorf("invalid record data")
 	ErrInvalidResourceRecord = fmt.Errorf("invalid resource record")
 	ErrInvalidMessage        = fmt.Errorf("invalid DNS message")
+	ErrInvalidSignature      = fmt.Errorf("invalid signature")
+	ErrUnsupportedAlgorithm  = fmt.Errorf("unsupported algorithm")
+	ErrInvalidKey            = fmt.Errorf("invalid key")
+	ErrDenialNotProven       = fmt.Errorf("denial of existence not proven")
+	ErrTLSAMismatch          = fmt.Errorf("no TLSA record matches the certificate chain")
 )
 
 func invalidMessageError(detail string) error {
@@ -22,6 +27,25 @@ func invalidDomainNameError(detail string) error {
 	return fmt.Errorf("%w: %s", ErrInvalidDomainName, detail)
 }
 
+// DomainNameError is the error returned when a domain name of a message cannot be decoded.
+// It wraps ErrInvalidDomainName.
+type DomainNameError struct {
+	Offset int    // Offset in the message where decoding failed
+	Detail string // What is wrong with the name
+}
+
+func (e *DomainNameError) Error() string {
+	return fmt.Sprintf("%s at offset %d: %s", ErrInvalidDomainName, e.Offset, e.Detail)
+}
+
+func (e *DomainNameError) Unwrap() error {
+	return ErrInvalidDomainName
+}
+
+func domainNameError(offset int, detail string) error {
+	return &DomainNameError{Offset: offset, Detail: detail}
+}
+
 func invalidIPError(detail string) error {
 	return fmt.Errorf("%w: %s", ErrInvalidIP, detail)
 }
@@ -41,3 +65,23 @@ func invalidRecordDataError(detail string) error {
 func invalidResourceRecordError(detail string) error {
 	return fmt.Errorf("%w: %s", ErrInvalidResourceRecord, detail)
 }
+
+func invalidSignatureError(detail string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidSignature, detail)
+}
+
+func unsupportedAlgorithmError(algorithm uint8) error {
+	return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, DNSSECAlgorithm(algorithm))
+}
+
+func invalidKeyError(detail string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidKey, detail)
+}
+
+func denialNotProvenError(detail string) error {
+	return fmt.Errorf("%w: %s", ErrDenialNotProven, detail)
+}
+
+func tlsaMismatchError(detail string) error {
+	return fmt.Errorf("%w: %s", ErrTLSAMismatch, detail)
+}
@@ -0,0 +1,97 @@
+package dns
+
+import (
+	"net/netip"
+	"testing"
+)
+
+// getFuzzSeeds returns encoded messages covering the sections, name compression, EDNS and RData types.
+func getFuzzSeeds(t testing.TB) [][]byte {
+	messages := []Message{
+		{
+			Header:    Header{Id: 1, Flags: Flags{RecursionDesired: true}},
+			Questions: []Question{{Name: "example.com.", QType: A, QClass: IN}},
+			EDNS:      &EDNS{UDPSize: DefaultEDNSUDPSize, DnssecOk: true, Options: []EDNSOption{&EDNSOptionCookie{ClientCookie: [ClientCookieLength]byte{1, 2, 3, 4, 5, 6, 7, 8}}}},
+		},
+		{
+			Header:    Header{Id: 2, Flags: Flags{Response: true, Authoritative: true}},
+			Questions: []Question{{Name: "example.com.", QType: ANY, QClass: IN}},
+			Answers: []ResourceRecord{
+				{Name: "example.com.", RType: A, RClass: IN, TTL: 300, RData: &RDataA{IP: netip.MustParseAddr("192.0.2.1")}},
+				{Name: "example.com.", RType: AAAA, RClass: IN, TTL: 300, RData: &RDataAAAA{IP: netip.MustParseAddr("2001:db8::1")}},
+				{Name: "example.com.", RType: MX, RClass: IN, TTL: 300, RData: &RDataMX{Preference: 10, Exchange: "mail.example.com."}},
+				{Name: "example.com.", RType: TXT, RClass: IN, TTL: 300, RData: &RDataTXT{Text: []string{"v=spf1 -all"}}},
+				{Name: "www.example.com.", RType: CNAME, RClass: IN, TTL: 300, RData: &RDataCNAME{DomainName: "example.com."}},
+				{Name: "_sip._udp.example.com.", RType: SRV, RClass: IN, TTL: 300, RData: &RDataSRV{Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com."}},
+				{Name: "example.com.", RType: DS, RClass: IN, TTL: 300, RData: &RDataDS{KeyTag: 1, Algorithm: 13, DigestType: 2, Digest: []byte{1, 2, 3, 4}}},
+				{Name: "example.com.", RType: NSEC, RClass: IN, TTL: 300, RData: &RDataNSEC{NextDomainName: "a.example.com.", Types: []uint16{A, MX, RRSIG, NSEC}}},
+				{Name: "example.com.", RType: HTTPS, RClass: IN, TTL: 300, RData: &RDataSVCB{Priority: 1, Target: ".", Params: []SVCBParam{&SVCBParamALPN{Protocols: []string{"h2"}}}}},
+				{Name: "example.com.", RType: 731, RClass: IN, TTL: 300, RData: &RDataUnknown{Data: []byte{0xde, 0xad}}},
+			},
+			NameServers: []ResourceRecord{
+				{Name: "example.com.", RType: SOA, RClass: IN, TTL: 300, RData: &RDataSOA{MName: "ns.example.com.", RName: "hostmaster.example.com.", Serial: 1, Refresh: 2, Retry: 3, Expire: 4, Minimum: 5}},
+			},
+		},
+	}
+
+	seeds := make([][]byte, 0, len(messages))
+	for _, message := range messages {
+		data, err := EncodeMessage(message)
+		if err != nil {
+			t.Fatalf("EncodeMessage() unexpected error = %v\n", err)
+		}
+		seeds = append(seeds, data)
+	}
+	return seeds
+}
+
+func FuzzDecodeMessage(f *testing.F) {
+	for _, seed := range getFuzzSeeds(f) {
+		f.Add(seed)
+	}
+	// MX and SOA RData too short for their fixed fields
+	f.Add([]byte{0, 1, 0x81, 0x80, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 15, 0, 1, 0, 0, 0, 0, 0, 1, 0})
+	f.Add([]byte{0, 1, 0x81, 0x80, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 6, 0, 1, 0, 0, 0, 0, 0, 2, 0, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, mode := range []DecodeMode{DecodeDefault, DecodeStrict, DecodeLenient} {
+			message, err := DecodeMessageWithOptions(data, DecodeOptions{Mode: mode})
+			if err != nil {
+				continue
+			}
+
+			// Decoded messages must be printable and encodable without panicking
+			for _, section := range [][]ResourceRecord{message.Answers, message.NameServers, message.Additionals} {
+				for _, record := range section {
+					_ = record.RData.String()
+				}
+			}
+			_, _ = EncodeMessage(message)
+		}
+	})
+}
+
+func FuzzDecodeDomainName(f *testing.F) {
+	f.Add([]byte{7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0}, 0)
+	f.Add([]byte{3, 'w', 'w', 'w', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, 0xc0, 4}, 17)
+	f.Add([]byte{0xc0, 0}, 0)
+
+	f.Fuzz(func(t *testing.T, data []byte, offset int) {
+		if offset < 0 || offset > len(data) {
+			return
+		}
+
+		reader := &dnsReader{data: data, offset: offset}
+		name, err := reader.readDomainName()
+		if err != nil {
+			return
+		}
+
+		if len(name) > maxDomainNameLength {
+			t.Errorf("readDomainName() got a name of %d bytes, want at most %d\n", len(name), maxDomainNameLength)
+		}
+		if reader.offset <= offset || reader.offset > len(data) {
+			t.Errorf("readDomainName() offset got = %d, started at %d, data length %d\n", reader.offset, offset, len(data))
+		}
+	})
+}
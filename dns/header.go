@@ -37,9 +37,10 @@ type Flags struct {
 	Truncated          bool
 	RecursionDesired   bool
 	RecursionAvailable bool
-	DnssecOk           bool // RFC 3225
+	DnssecOk           bool // RFC 3225: carried by the OPT record's DO bit, not by the header
 	AuthenticatedData  bool // RFC 4035
 	CheckingDisabled   bool // RFC 4035
+	Zero               bool // Z: reserved bit which must be zero, kept so that messages round-trip unchanged
 	ResponseCode       uint16
 }
 
@@ -67,15 +68,25 @@ const (
 	TCMask     = 0b00000010_00000000 // TC: Bit 9
 	RDMask     = 0b00000001_00000000 // RD: Bit 8
 	RAMask     = 0b00000000_10000000 // RA: Bit 7
-	DOMask     = 0b00000000_01000000 // DO: Bit 6
+	ZMask      = 0b00000000_01000000 // Z: Bit 6, reserved
 	ADMask     = 0b00000000_00100000 // AD: Bit 5
 	CDMask     = 0b00000000_00010000 // CD: Bit 4
 	RCodeMask  = 0b00000000_00001111 // Rcode: Bits 0-3
 )
 
 func (reader *dnsReader) readFlags() Flags {
-	flags := reader.readUint16()
+	return UnpackFlags(reader.readUint16())
+}
 
+// UnpackFlags decodes the flags field of a message header, bytes 2 and 3.
+// The DnssecOk flag is not set: it is carried by the OPT record, not by the header.
+//
+// Parameters:
+//   - flags: The flags field, ex. 0x8180 for a recursive response without error.
+//
+// Returns:
+//   - Flags: The decoded flags, with the reserved Z bit in Zero.
+func UnpackFlags(flags uint16) Flags {
 	return Flags{
 		Response:           flags&QRMask != 0,
 		Opcode:             (flags & OpcodeMask) >> 11,
@@ -83,7 +94,7 @@ func (reader *dnsReader) readFlags() Flags {
 		Truncated:          flags&TCMask != 0,
 		RecursionDesired:   flags&RDMask != 0,
 		RecursionAvailable: flags&RAMask != 0,
-		DnssecOk:           flags&DOMask != 0,
+		Zero:               flags&ZMask != 0,
 		AuthenticatedData:  flags&ADMask != 0,
 		CheckingDisabled:   flags&CDMask != 0,
 		ResponseCode:       flags & RCodeMask,
@@ -100,6 +111,17 @@ func (writer *dnsWriter) writeHeader(message Message) {
 }
 
 func (writer *dnsWriter) writeFlags(flags Flags) {
+	writer.writeUint16(flags.Pack())
+}
+
+// Pack encodes the flags into the flags field of a message header, bytes 2 and 3.
+// The Opcode and ResponseCode are truncated to their 4 bits, and the DnssecOk flag
+// is ignored: it is carried by the OPT record, not by the header.
+// Setting Zero sets the reserved Z bit, ex. to test how servers handle it.
+//
+// Returns:
+//   - uint16: The flags field.
+func (flags Flags) Pack() uint16 {
 	var result uint16
 	if flags.Response {
 		result |= QRMask
@@ -117,8 +139,8 @@ func (writer *dnsWriter) writeFlags(flags Flags) {
 	if flags.RecursionAvailable {
 		result |= RAMask
 	}
-	if flags.DnssecOk {
-		result |= DOMask
+	if flags.Zero {
+		result |= ZMask
 	}
 	if flags.AuthenticatedData {
 		result |= ADMask
@@ -128,5 +150,5 @@ func (writer *dnsWriter) writeFlags(flags Flags) {
 	}
 	result |= flags.ResponseCode & RCodeMask
 
-	writer.writeUint16(result)
+	return result
 }
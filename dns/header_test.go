@@ -86,9 +86,10 @@ func TestDecodeFlags(t *testing.T) {
 				Truncated:          true,
 				RecursionDesired:   true,
 				RecursionAvailable: true,
-				DnssecOk:           true,
+				DnssecOk:           false, // Bit 6 is the reserved Z bit: DO is carried by the OPT record
 				AuthenticatedData:  true,
 				CheckingDisabled:   true,
+				Zero:               true,
 				ResponseCode:       3,
 			},
 		},
@@ -264,6 +265,9 @@ func assertFlags(t *testing.T, got Flags, want Flags, data []byte) {
 	if got.CheckingDisabled != want.CheckingDisabled {
 		t.Errorf("decodeDNSFlags() CD got = %t, want = %t, data = %v\n", got.CheckingDisabled, want.CheckingDisabled, data)
 	}
+	if got.Zero != want.Zero {
+		t.Errorf("decodeDNSFlags() Z got = %t, want = %t, data = %v\n", got.Zero, want.Zero, data)
+	}
 	if got.ResponseCode != want.ResponseCode {
 		t.Errorf("decodeDNSFlags() RCode got = %d, want = %d, data = %v\n", got.ResponseCode, want.ResponseCode, data)
 	}
@@ -348,12 +352,12 @@ func TestEncodeFlags(t *testing.T) {
 				Truncated:          true,
 				RecursionDesired:   true,
 				RecursionAvailable: true,
-				DnssecOk:           true,
+				DnssecOk:           true, // Not encoded in the header: DO is carried by the OPT record
 				AuthenticatedData:  true,
 				CheckingDisabled:   true,
 				ResponseCode:       3,
 			},
-			want: []byte{0b10010111, 0b11110011},
+			want: []byte{0b10010111, 0b10110011},
 		},
 	}
 
@@ -474,3 +478,17 @@ func TestEncodeDNSHeader(t *testing.T) {
 		})
 	}
 }
+
+func TestFlagsPackUnpack(t *testing.T) {
+	// Every flags field round-trips, including the reserved Z bit
+	for flags := 0; flags <= 0xFFFF; flags++ {
+		if got := UnpackFlags(uint16(flags)).Pack(); got != uint16(flags) {
+			t.Fatalf("Pack() got = %016b, want = %016b\n", got, flags)
+		}
+	}
+
+	flags := Flags{Opcode: 0x1F, ResponseCode: 0x1F, DnssecOk: true}
+	if got := flags.Pack(); got != 0b01111000_00001111 {
+		t.Errorf("Pack() got = %016b, want the opcode and response code truncated to 4 bits\n", got)
+	}
+}
@@ -0,0 +1,303 @@
+package dns
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+/*
+Internationalized domain names are carried on the wire as punycode A-labels
+prefixed with "xn--" (RFC 5890, RFC 3492):
+
+	"аpple" (with a Cyrillic 'а') -> "xn--pple-43d"
+
+Once decoded, such labels can be visually indistinguishable from an ASCII
+label, which makes them popular for phishing infrastructure. The checks below
+flag the two classic homograph patterns:
+  - mixed-script labels, ex. a Cyrillic 'а' in an otherwise Latin label
+  - whole-script confusables, ex. "аррӏе" written entirely in Cyrillic
+*/
+
+const punycodePrefix = "xn--"
+
+// HomographWarning describes a punycode label that may be impersonating another label.
+type HomographWarning struct {
+	Label       string   // The A-label as found in the domain name, ex. "xn--pple-43d"
+	Unicode     string   // The decoded U-label, ex. "аpple"
+	Scripts     []string // The scripts used in the label, ex. ["Cyrillic", "Latin"]
+	LooksLike   string   // The Latin label it can be confused with, if any, ex. "apple"
+	MixedScript bool     // Whether the label mixes scripts that are not normally used together
+}
+
+func (warning HomographWarning) String() string {
+	reasons := []string{}
+	if warning.MixedScript {
+		reasons = append(reasons, fmt.Sprintf("mixes scripts %s", strings.Join(warning.Scripts, ", ")))
+	}
+	if warning.LooksLike != "" {
+		reasons = append(reasons, fmt.Sprintf("looks like \"%s\"", warning.LooksLike))
+	}
+	return fmt.Sprintf("label %s (\"%s\") %s", warning.Label, warning.Unicode, strings.Join(reasons, " and "))
+}
+
+// CheckHomographs inspects the punycode labels of a domain name and reports
+// the ones that are mixed-script or confusable with a Latin label.
+//
+// Parameters:
+//   - domainName: The domain name to check, ex. "xn--pple-43d.com.".
+//
+// Returns:
+//   - []HomographWarning: One warning per suspicious label, nil if there are none.
+func CheckHomographs(domainName string) (warnings []HomographWarning) {
+	for _, label := range strings.Split(domainName, ".") {
+		if !strings.HasPrefix(strings.ToLower(label), punycodePrefix) {
+			continue
+		}
+
+		unicodeLabel, err := idna.Punycode.ToUnicode(label)
+		if err != nil || unicodeLabel == label {
+			continue
+		}
+
+		warning, suspicious := checkLabel(label, unicodeLabel)
+		if suspicious {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+func checkLabel(label string, unicodeLabel string) (warning HomographWarning, suspicious bool) {
+	scripts := getLabelScripts(unicodeLabel)
+
+	warning = HomographWarning{
+		Label:       label,
+		Unicode:     unicodeLabel,
+		Scripts:     scripts,
+		MixedScript: isMixedScript(scripts),
+	}
+
+	if looksLike, ok := getLatinLookalike(unicodeLabel); ok && looksLike != unicodeLabel {
+		warning.LooksLike = looksLike
+	}
+
+	return warning, warning.MixedScript || warning.LooksLike != ""
+}
+
+// Scripts that are checked when looking for mixed-script labels.
+// Characters from the Common and Inherited scripts (digits, hyphens, combining marks)
+// are ignored since they are shared between scripts.
+var homographScripts = []string{
+	"Latin",
+	"Cyrillic",
+	"Greek",
+	"Armenian",
+	"Georgian",
+	"Hebrew",
+	"Arabic",
+	"Thai",
+	"Han",
+	"Hiragana",
+	"Katakana",
+	"Hangul",
+	"Bopomofo",
+}
+
+func getLabelScripts(label string) []string {
+	found := map[string]bool{}
+	for _, r := range label {
+		for _, script := range homographScripts {
+			if unicode.Is(unicode.Scripts[script], r) {
+				found[script] = true
+				break
+			}
+		}
+	}
+
+	scripts := make([]string, 0, len(found))
+	for script := range found {
+		scripts = append(scripts, script)
+	}
+	sort.Strings(scripts)
+	return scripts
+}
+
+// Script combinations that are legitimately written together (CJK).
+var allowedScriptMixes = [][]string{
+	{"Han", "Hiragana", "Katakana", "Latin"},
+	{"Han", "Hangul", "Latin"},
+	{"Han", "Bopomofo", "Latin"},
+}
+
+func isMixedScript(scripts []string) bool {
+	if len(scripts) < 2 {
+		return false
+	}
+
+	for _, allowed := range allowedScriptMixes {
+		if isSubset(scripts, allowed) {
+			return false
+		}
+	}
+	return true
+}
+
+func isSubset(scripts []string, allowed []string) bool {
+	for _, script := range scripts {
+		found := false
+		for _, a := range allowed {
+			if script == a {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Non-Latin characters that are commonly rendered identically to a Latin letter.
+var latinConfusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j', 'к': 'k',
+	'ӏ': 'l', 'о': 'o', 'р': 'p', 'ԛ': 'q', 'ѕ': 's', 'ѵ': 'v', 'ԝ': 'w', 'х': 'x',
+	'у': 'y',
+	// Armenian
+	'օ': 'o', 'ս': 'u', 'ց': 'g',
+	// Greek
+	'α': 'a', 'ε': 'e', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p', 'τ': 't',
+	'υ': 'u', 'χ': 'x', 'γ': 'y',
+}
+
+// getLatinLookalike returns the ASCII label the given label can be mistaken for,
+// if every letter in it is either ASCII or a known Latin confusable.
+func getLatinLookalike(label string) (looksLike string, ok bool) {
+	var builder strings.Builder
+	for _, r := range label {
+		if r <= unicode.MaxASCII {
+			builder.WriteRune(r)
+			continue
+		}
+		latin, found := latinConfusables[r]
+		if !found {
+			return "", false
+		}
+		builder.WriteRune(latin)
+	}
+	return builder.String(), true
+}
+
+// IDNAPolicy selects the rules used to convert internationalized domain names
+// between their Unicode and ASCII (punycode) forms [UTS46].
+// The zero value is the lenient policy used by default: non-transitional
+// processing, and underscores allowed for service labels like "_dmarc".
+type IDNAPolicy struct {
+	Transitional    bool // Map deviation characters as IDNA2003 did, ex. "ß" to "ss", instead of encoding them
+	UseSTD3Rules    bool // Only allow letters, digits and hyphens in labels, rejecting ex. underscores
+	VerifyDNSLength bool // Reject empty labels, labels over 63 bytes and names over 253 bytes
+}
+
+// DefaultIDNAPolicy is the policy used when converting names without an explicit policy.
+var DefaultIDNAPolicy = IDNAPolicy{}
+
+func (policy IDNAPolicy) profile() *idna.Profile {
+	return idna.New(
+		idna.MapForLookup(),
+		idna.BidiRule(),
+		idna.Transitional(policy.Transitional),
+		idna.StrictDomainName(policy.UseSTD3Rules),
+		idna.VerifyDNSLength(policy.VerifyDNSLength),
+	)
+}
+
+// ToASCII converts a domain name to its ASCII form, encoding Unicode labels in punycode.
+// ASCII names are only checked and lowercased.
+//
+// Parameters:
+//   - domainName: The domain name to convert, ex. "bücher.example.".
+//
+// Returns:
+//   - string: The ASCII domain name, ex. "xn--bcher-kva.example.".
+//   - error: If the domain name is not valid under the policy.
+func (policy IDNAPolicy) ToASCII(domainName string) (string, error) {
+	return policy.convert(domainName, policy.profile().ToASCII)
+}
+
+// ToUnicode converts a domain name to its Unicode form, decoding punycode labels.
+//
+// Parameters:
+//   - domainName: The domain name to convert, ex. "xn--bcher-kva.example.".
+//
+// Returns:
+//   - string: The Unicode domain name, ex. "bücher.example.".
+//   - error: If the domain name is not valid under the policy.
+func (policy IDNAPolicy) ToUnicode(domainName string) (string, error) {
+	return policy.convert(domainName, policy.profile().ToUnicode)
+}
+
+func (policy IDNAPolicy) convert(domainName string, conversion func(string) (string, error)) (string, error) {
+	if domainName == "." {
+		return domainName, nil
+	}
+
+	// The root label is handled here so that VerifyDNSLength does not reject fully qualified names
+	name, fullyQualified := strings.CutSuffix(domainName, ".")
+
+	converted, err := conversion(name)
+	if err != nil {
+		return "", invalidDomainNameError(fmt.Sprintf("%s: %s", domainName, err.Error()))
+	}
+
+	if fullyQualified {
+		converted += "."
+	}
+	return converted, nil
+}
+
+// ToUnicodeMessage returns a copy of a message for display, with the names of its questions
+// and the owner names of its records converted to their Unicode form.
+// Names which cannot be converted under the policy are kept as is.
+//
+// Parameters:
+//   - message: The message to convert. It is not modified.
+//
+// Returns:
+//   - Message: The message with Unicode names.
+func (policy IDNAPolicy) ToUnicodeMessage(message Message) Message {
+	toUnicode := func(domainName string) string {
+		if converted, err := policy.ToUnicode(domainName); err == nil {
+			return converted
+		}
+		return domainName
+	}
+
+	message.Questions = slices.Clone(message.Questions)
+	for i := range message.Questions {
+		message.Questions[i].Name = toUnicode(message.Questions[i].Name)
+	}
+	for _, records := range []*[]ResourceRecord{&message.Answers, &message.NameServers, &message.Additionals} {
+		*records = slices.Clone(*records)
+		for i := range *records {
+			(*records)[i].Name = toUnicode((*records)[i].Name)
+		}
+	}
+	return message
+}
+
+// getASCIIName returns a domain name in ASCII form: Unicode names are converted with
+// DefaultIDNAPolicy, and ASCII names are returned as is, keeping their case.
+func getASCIIName(domainName string) (string, error) {
+	for _, c := range []byte(domainName) {
+		if c > unicode.MaxASCII {
+			return DefaultIDNAPolicy.ToASCII(domainName)
+		}
+	}
+	return domainName, nil
+}
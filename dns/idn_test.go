@@ -0,0 +1,256 @@
+package dns
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheckHomographs(t *testing.T) {
+	tests := []struct {
+		name       string
+		domainName string
+		want       []HomographWarning
+	}{
+		{
+			name:       "ASCII domain",
+			domainName: "apple.com.",
+			want:       nil,
+		},
+		{
+			name:       "Legitimate single-script IDN",
+			domainName: "xn--mnchen-3ya.de.", // münchen.de
+			want:       nil,
+		},
+		{
+			name:       "Legitimate Japanese IDN",
+			domainName: "xn--r8jz45g.jp.", // 例え.jp
+			want:       nil,
+		},
+		{
+			name:       "Mixed-script label",
+			domainName: "xn--pple-43d.com.", // аpple.com with a Cyrillic 'а'
+			want: []HomographWarning{
+				{
+					Label:       "xn--pple-43d",
+					Unicode:     "аpple",
+					Scripts:     []string{"Cyrillic", "Latin"},
+					LooksLike:   "apple",
+					MixedScript: true,
+				},
+			},
+		},
+		{
+			name:       "Whole-script confusable label",
+			domainName: "www.xn--80ak6aa92e.com.", // аррӏе.com entirely in Cyrillic
+			want: []HomographWarning{
+				{
+					Label:       "xn--80ak6aa92e",
+					Unicode:     "аррӏе",
+					Scripts:     []string{"Cyrillic"},
+					LooksLike:   "apple",
+					MixedScript: false,
+				},
+			},
+		},
+		{
+			name:       "Invalid punycode",
+			domainName: "xn--.com.",
+			want:       nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckHomographs(tt.domainName)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckHomographs() got = %+v, want = %+v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHomographWarningString(t *testing.T) {
+	warning := HomographWarning{
+		Label:       "xn--pple-43d",
+		Unicode:     "аpple",
+		Scripts:     []string{"Cyrillic", "Latin"},
+		LooksLike:   "apple",
+		MixedScript: true,
+	}
+	want := "label xn--pple-43d (\"аpple\") mixes scripts Cyrillic, Latin and looks like \"apple\""
+
+	got := warning.String()
+
+	if got != want {
+		t.Errorf("String() got = %s, want = %s\n", got, want)
+	}
+}
+
+func TestIDNAPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      IDNAPolicy
+		domainName  string
+		wantASCII   string
+		wantUnicode string
+		wantError   error
+	}{
+		{
+			name:        "Unicode name",
+			policy:      DefaultIDNAPolicy,
+			domainName:  "bücher.example.",
+			wantASCII:   "xn--bcher-kva.example.",
+			wantUnicode: "bücher.example.",
+			wantError:   nil,
+		},
+		{
+			name:        "Uppercase Unicode name is mapped to lowercase",
+			policy:      DefaultIDNAPolicy,
+			domainName:  "BÜCHER.example",
+			wantASCII:   "xn--bcher-kva.example",
+			wantUnicode: "bücher.example",
+			wantError:   nil,
+		},
+		{
+			name:        "Non-transitional deviation character",
+			policy:      DefaultIDNAPolicy,
+			domainName:  "faß.de.",
+			wantASCII:   "xn--fa-hia.de.",
+			wantUnicode: "faß.de.",
+			wantError:   nil,
+		},
+		{
+			name:        "Transitional deviation character",
+			policy:      IDNAPolicy{Transitional: true},
+			domainName:  "faß.de.",
+			wantASCII:   "fass.de.",
+			wantUnicode: "fass.de.",
+			wantError:   nil,
+		},
+		{
+			name:        "Underscore allowed by default",
+			policy:      DefaultIDNAPolicy,
+			domainName:  "_dmarc.example.com.",
+			wantASCII:   "_dmarc.example.com.",
+			wantUnicode: "_dmarc.example.com.",
+			wantError:   nil,
+		},
+		{
+			name:       "Underscore rejected by STD3 rules",
+			policy:     IDNAPolicy{UseSTD3Rules: true},
+			domainName: "_dmarc.example.com.",
+			wantError:  ErrInvalidDomainName,
+		},
+		{
+			name:       "Empty label rejected when verifying DNS length",
+			policy:     IDNAPolicy{VerifyDNSLength: true},
+			domainName: "www..example.com.",
+			wantError:  ErrInvalidDomainName,
+		},
+		{
+			name:        "Root",
+			policy:      IDNAPolicy{VerifyDNSLength: true},
+			domainName:  ".",
+			wantASCII:   ".",
+			wantUnicode: ".",
+			wantError:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotASCII, err := tt.policy.ToASCII(tt.domainName)
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("ToASCII() error = %v, want error = %v\n", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToASCII() unexpected error = %v\n", err)
+			}
+			if gotASCII != tt.wantASCII {
+				t.Errorf("ToASCII() got = %s, want = %s\n", gotASCII, tt.wantASCII)
+			}
+
+			gotUnicode, err := tt.policy.ToUnicode(gotASCII)
+			if err != nil {
+				t.Fatalf("ToUnicode() unexpected error = %v\n", err)
+			}
+			if gotUnicode != tt.wantUnicode {
+				t.Errorf("ToUnicode() got = %s, want = %s\n", gotUnicode, tt.wantUnicode)
+			}
+		})
+	}
+}
+
+func TestToUnicodeMessage(t *testing.T) {
+	message := Message{
+		Questions: []Question{{Name: "xn--bcher-kva.example.", QType: A, QClass: IN}},
+		Answers: []ResourceRecord{
+			{Name: "xn--bcher-kva.example.", RType: CNAME, RClass: IN, RData: &RDataCNAME{DomainName: "www.example."}},
+			{Name: "xn--zz.example.", RType: A, RClass: IN},
+		},
+	}
+
+	got := DefaultIDNAPolicy.ToUnicodeMessage(message)
+	if got.Questions[0].Name != "bücher.example." || got.Answers[0].Name != "bücher.example." {
+		t.Errorf("ToUnicodeMessage() names got = %s, %s, want = bücher.example.\n", got.Questions[0].Name, got.Answers[0].Name)
+	}
+	if got.Answers[1].Name != "xn--zz.example." {
+		t.Errorf("ToUnicodeMessage() invalid name got = %s, want it kept as is\n", got.Answers[1].Name)
+	}
+	if message.Questions[0].Name != "xn--bcher-kva.example." || message.Answers[0].Name != "xn--bcher-kva.example." {
+		t.Errorf("ToUnicodeMessage() modified message = %+v\n", message)
+	}
+}
+
+func TestGetASCIIName(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		want      string
+		wantError error
+	}{
+		{name: "ASCII name keeps its case", data: "WWW.Example.com.", want: "WWW.Example.com."},
+		{name: "Unicode name", data: "Bücher.example.", want: "xn--bcher-kva.example."},
+		{name: "Invalid Unicode name", data: "aא.example.", wantError: ErrInvalidDomainName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getASCIIName(tt.data)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("getASCIIName() error = %v, want error = %v\n", err, tt.wantError)
+			}
+			if got != tt.want {
+				t.Errorf("getASCIIName() got = %s, want = %s\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetLatinLookalike(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		want   string
+		wantOk bool
+	}{
+		{name: "Cyrillic confusable", data: "аpple", want: "apple", wantOk: true},
+		{name: "ASCII DEL character", data: "a\x7f", want: "a\x7f", wantOk: true},
+		{name: "Not a Latin confusable", data: "aא", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := getLatinLookalike(tt.data)
+			if ok != tt.wantOk || (ok && got != tt.want) {
+				t.Errorf("getLatinLookalike() got = %q, %v, want = %q, %v\n", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package dns
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSON representation of DNS messages [RFC8427]:
+// a message is an object with a member per header field, ex. "ID", "QR", "RCODE" and "ANCOUNT",
+// the question as "QNAME", "QTYPE" and "QCLASS", and the records as arrays of objects in
+// "answerRRs", "authorityRRs" and "additionalRRs":
+//
+//	{"ID": 1234, "QR": true, ..., "QNAME": "example.com.", "QTYPE": 1, "QTYPEname": "A", ...,
+//	 "answerRRs": [{"NAME": "example.com.", "TYPE": 1, "TYPEname": "A", "CLASS": 1, "CLASSname": "IN",
+//	                "TTL": 300, "RDLENGTH": 4, "rdataA": "192.0.2.1"}]}
+//
+// The RData of a record is written in its presentation format, in a member named after its type,
+// ex. "rdataMX": "10 mail.example.com.". The RData of unknown types, and of types without
+// a presentation format parser (see ParseRData), is written in hexadecimal as "rdataHEX".
+// The EDNS parameters of a message are written as its OPT record, in the additional section.
+// A message with several questions lists them in "questionRRs" instead of "QNAME".
+
+// JSONOptions control how MarshalMessageJSON writes a message.
+// The zero value writes the raw RData in hexadecimal, as MarshalJSON does.
+type JSONOptions struct {
+	// Write the raw RData in base64 as "rdataBASE64", a member not defined by RFC 8427, instead of "rdataHEX"
+	Base64RData bool
+}
+
+type jsonMessage struct {
+	ID            uint16            `json:"ID"`
+	QR            bool              `json:"QR"`
+	Opcode        uint16            `json:"Opcode"`
+	AA            bool              `json:"AA"`
+	TC            bool              `json:"TC"`
+	RD            bool              `json:"RD"`
+	RA            bool              `json:"RA"`
+	AD            bool              `json:"AD"`
+	CD            bool              `json:"CD"`
+	RCODE         uint16            `json:"RCODE"`
+	QDCOUNT       uint16            `json:"QDCOUNT"`
+	ANCOUNT       uint16            `json:"ANCOUNT"`
+	NSCOUNT       uint16            `json:"NSCOUNT"`
+	ARCOUNT       uint16            `json:"ARCOUNT"`
+	QNAME         string            `json:"QNAME,omitempty"`
+	QTYPE         uint16            `json:"QTYPE,omitempty"`
+	QTYPEname     string            `json:"QTYPEname,omitempty"`
+	QCLASS        uint16            `json:"QCLASS,omitempty"`
+	QCLASSname    string            `json:"QCLASSname,omitempty"`
+	QuestionRRs   []Question        `json:"questionRRs,omitempty"`
+	AnswerRRs     []json.RawMessage `json:"answerRRs,omitempty"`
+	AuthorityRRs  []json.RawMessage `json:"authorityRRs,omitempty"`
+	AdditionalRRs []json.RawMessage `json:"additionalRRs,omitempty"`
+}
+
+type jsonQuestion struct {
+	NAME      string `json:"NAME"`
+	TYPE      uint16 `json:"TYPE"`
+	TYPEname  string `json:"TYPEname,omitempty"`
+	CLASS     uint16 `json:"CLASS"`
+	CLASSname string `json:"CLASSname,omitempty"`
+}
+
+type jsonRecord struct {
+	NAME        string  `json:"NAME"`
+	TYPE        uint16  `json:"TYPE"`
+	TYPEname    string  `json:"TYPEname,omitempty"`
+	CLASS       uint16  `json:"CLASS"`
+	CLASSname   string  `json:"CLASSname,omitempty"`
+	TTL         uint32  `json:"TTL"`
+	RDLENGTH    uint16  `json:"RDLENGTH"`
+	RDataHEX    *string `json:"rdataHEX,omitempty"`
+	RDataBASE64 *string `json:"rdataBASE64,omitempty"`
+}
+
+// MarshalMessageJSON converts a message into its JSON representation [RFC8427].
+//
+// The section counts of the header are set from the number of questions and records in each section,
+// and the RDLENGTH of each record from the length of its uncompressed RData, as EncodeMessage does.
+//
+// Parameters:
+//   - message: The message to convert.
+//   - options: The JSON options.
+//
+// Returns:
+//   - []byte: The JSON object of the message.
+//   - error: If the RData of a record cannot be encoded.
+func MarshalMessageJSON(message Message, options JSONOptions) ([]byte, error) {
+	message = applyDnssecOk(message)
+	flags := message.Header.Flags
+	additionals := getAdditionalsWithEDNS(message)
+
+	object := jsonMessage{
+		ID:      message.Header.Id,
+		QR:      flags.Response,
+		Opcode:  flags.Opcode,
+		AA:      flags.Authoritative,
+		TC:      flags.Truncated,
+		RD:      flags.RecursionDesired,
+		RA:      flags.RecursionAvailable,
+		AD:      flags.AuthenticatedData,
+		CD:      flags.CheckingDisabled,
+		RCODE:   flags.ResponseCode,
+		QDCOUNT: uint16(len(message.Questions)),
+		ANCOUNT: uint16(len(message.Answers)),
+		NSCOUNT: uint16(len(message.NameServers)),
+		ARCOUNT: uint16(len(additionals)),
+	}
+
+	if len(message.Questions) == 1 {
+		question := getJSONQuestion(message.Questions[0])
+		object.QNAME, object.QTYPE, object.QTYPEname = question.NAME, question.TYPE, question.TYPEname
+		object.QCLASS, object.QCLASSname = question.CLASS, question.CLASSname
+	} else {
+		object.QuestionRRs = message.Questions
+	}
+
+	for _, section := range []struct {
+		name    string
+		records []ResourceRecord
+		objects *[]json.RawMessage
+	}{
+		{"answer", message.Answers, &object.AnswerRRs},
+		{"authority", message.NameServers, &object.AuthorityRRs},
+		{"additional", additionals, &object.AdditionalRRs},
+	} {
+		for _, record := range section.records {
+			data, err := marshalRecordJSON(record, options)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s section: %w", ErrInvalidMessage, section.name, err)
+			}
+			*section.objects = append(*section.objects, data)
+		}
+	}
+
+	return json.Marshal(object)
+}
+
+// MarshalJSON converts the message into its JSON representation [RFC8427], like MarshalMessageJSON.
+func (message Message) MarshalJSON() ([]byte, error) {
+	return MarshalMessageJSON(message, JSONOptions{})
+}
+
+// UnmarshalJSON reads a message from its JSON representation [RFC8427].
+// The header's section counts are set from the sections read, and the OPT record
+// of the additional section is taken out as the message's EDNS parameters.
+// The message is left unchanged on error.
+func (message *Message) UnmarshalJSON(data []byte) error {
+	var object jsonMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+	}
+
+	decoded := Message{
+		Header: Header{
+			Id: object.ID,
+			Flags: Flags{
+				Response:           object.QR,
+				Opcode:             object.Opcode,
+				Authoritative:      object.AA,
+				Truncated:          object.TC,
+				RecursionDesired:   object.RD,
+				RecursionAvailable: object.RA,
+				AuthenticatedData:  object.AD,
+				CheckingDisabled:   object.CD,
+				ResponseCode:       object.RCODE,
+			},
+		},
+		Questions: object.QuestionRRs,
+	}
+	if len(decoded.Questions) == 0 && object.QNAME != "" {
+		decoded.Questions = []Question{{Name: object.QNAME, QType: object.QTYPE, QClass: object.QCLASS}}
+	}
+
+	for _, section := range []struct {
+		name    string
+		objects []json.RawMessage
+		records *[]ResourceRecord
+	}{
+		{"answer", object.AnswerRRs, &decoded.Answers},
+		{"authority", object.AuthorityRRs, &decoded.NameServers},
+		{"additional", object.AdditionalRRs, &decoded.Additionals},
+	} {
+		for _, recordData := range section.objects {
+			var record ResourceRecord
+			if err := record.UnmarshalJSON(recordData); err != nil {
+				return fmt.Errorf("%w: %s section: %w", ErrInvalidMessage, section.name, err)
+			}
+			*section.records = append(*section.records, record)
+		}
+	}
+
+	additionals, edns, err := getEDNSFromAdditionals(decoded.Additionals)
+	if err != nil {
+		return fmt.Errorf("%w: additional section: %w", ErrInvalidMessage, err)
+	}
+	decoded.Additionals = additionals
+	decoded.EDNS = edns
+	if edns != nil {
+		decoded.Header.Flags.DnssecOk = edns.DnssecOk
+	}
+	decoded.UpdateHeaderCounts()
+
+	*message = decoded
+	return nil
+}
+
+func getJSONQuestion(question Question) jsonQuestion {
+	return jsonQuestion{
+		NAME:      question.Name,
+		TYPE:      question.QType,
+		TYPEname:  dnsTypeNames[question.QType],
+		CLASS:     question.QClass,
+		CLASSname: dnsClassNames[question.QClass],
+	}
+}
+
+// MarshalJSON converts the question into a JSON object with its "NAME", "TYPE" and "CLASS" [RFC8427].
+func (question Question) MarshalJSON() ([]byte, error) {
+	return json.Marshal(getJSONQuestion(question))
+}
+
+// UnmarshalJSON reads a question from a JSON object with its "NAME", "TYPE" and "CLASS" [RFC8427].
+func (question *Question) UnmarshalJSON(data []byte) error {
+	var object jsonQuestion
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidQuestion, err)
+	}
+	*question = Question{Name: object.NAME, QType: object.TYPE, QClass: object.CLASS}
+	return nil
+}
+
+// MarshalJSON converts the record into its JSON representation [RFC8427], with its RData
+// in presentation format or in hexadecimal.
+func (record ResourceRecord) MarshalJSON() ([]byte, error) {
+	return marshalRecordJSON(record, JSONOptions{})
+}
+
+func marshalRecordJSON(record ResourceRecord, options JSONOptions) ([]byte, error) {
+	rdata := record.RData
+	if rdata == nil {
+		rdata = &RDataUnknown{}
+	}
+	raw, err := EncodeRData(rdata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %s: %w", ErrInvalidResourceRecord, record.Name, DNSType(record.RType), err)
+	}
+
+	object := jsonRecord{
+		NAME:     record.Name,
+		TYPE:     record.RType,
+		TYPEname: dnsTypeNames[record.RType],
+		CLASS:    record.RClass,
+		TTL:      record.TTL,
+		RDLENGTH: uint16(len(raw)),
+	}
+	if record.RType != OPT { // The class of an OPT record is the UDP payload size
+		object.CLASSname = dnsClassNames[record.RClass]
+	}
+
+	presentationMember := getPresentationMember(record.RType)
+	if _, unknown := rdata.(*RDataUnknown); unknown || presentationMember == "" {
+		var encoded string
+		if options.Base64RData {
+			encoded = base64.StdEncoding.EncodeToString(raw)
+			object.RDataBASE64 = &encoded
+		} else {
+			encoded = strings.ToUpper(hex.EncodeToString(raw))
+			object.RDataHEX = &encoded
+		}
+		return json.Marshal(object)
+	}
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	presentation, err := json.Marshal(rdata.String())
+	if err != nil {
+		return nil, err
+	}
+	// Add the member named after the type before the closing brace of the object
+	data = append(data[:len(data)-1], fmt.Sprintf(",%q:%s}", presentationMember, presentation)...)
+	return data, nil
+}
+
+// UnmarshalJSON reads a record from its JSON representation [RFC8427]. Its RData is read
+// from the member named after its type in presentation format, ex. "rdataMX", or else
+// from "rdataHEX" or "rdataBASE64". The RDLength is read from "RDLENGTH".
+func (record *ResourceRecord) UnmarshalJSON(data []byte) error {
+	var object jsonRecord
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidResourceRecord, err)
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidResourceRecord, err)
+	}
+
+	rdata, err := getJSONRData(object, members)
+	if err != nil {
+		return fmt.Errorf("%w: %s %s: %w", ErrInvalidResourceRecord, object.NAME, DNSType(object.TYPE), err)
+	}
+
+	*record = ResourceRecord{
+		Name:     object.NAME,
+		RType:    object.TYPE,
+		RClass:   object.CLASS,
+		TTL:      object.TTL,
+		RDLength: object.RDLENGTH,
+		RData:    rdata,
+	}
+	return nil
+}
+
+func getJSONRData(object jsonRecord, members map[string]json.RawMessage) (RData, error) {
+	if member := getPresentationMember(object.TYPE); member != "" && members[member] != nil {
+		var presentation string
+		if err := json.Unmarshal(members[member], &presentation); err != nil {
+			return nil, invalidRecordDataError(fmt.Sprintf("%s: %s", member, err.Error()))
+		}
+		return ParseRData(object.TYPE, presentation)
+	}
+
+	var raw []byte
+	var err error
+	switch {
+	case object.RDataHEX != nil:
+		raw, err = hex.DecodeString(*object.RDataHEX)
+	case object.RDataBASE64 != nil:
+		raw, err = base64.StdEncoding.DecodeString(*object.RDataBASE64)
+	}
+	if err != nil {
+		return nil, invalidRecordDataError(err.Error())
+	}
+	return decodeRData(object.TYPE, raw)
+}
+
+// getPresentationMember returns the name of the member holding RData in presentation format
+// for the type, ex. "rdataMX", or "" if the type has no presentation format parser.
+func getPresentationMember(rtype uint16) string {
+	name, ok := dnsTypeNames[rtype]
+	if !ok || !hasPresentationParser(rtype) {
+		return ""
+	}
+	return "rdata" + name
+}
@@ -0,0 +1,163 @@
+package dns
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestMarshalMessageJSON(t *testing.T) {
+	message := Message{
+		Header:    Header{Id: 1234, Flags: Flags{Response: true, RecursionDesired: true, RecursionAvailable: true}},
+		Questions: []Question{{Name: "example.com.", QType: MX, QClass: IN}},
+		Answers: []ResourceRecord{
+			{Name: "example.com.", RType: MX, RClass: IN, TTL: 300, RData: &RDataMX{Preference: 10, Exchange: "mail.example.com."}},
+			{Name: "example.com.", RType: 731, RClass: IN, TTL: 300, RData: &RDataUnknown{Data: []byte{0xde, 0xad}}},
+		},
+		EDNS: &EDNS{UDPSize: 1232, DnssecOk: true},
+	}
+
+	tests := []struct {
+		name    string
+		options JSONOptions
+		want    string
+	}{
+		{
+			name: "Default options",
+			want: `{"ID":1234,"QR":true,"Opcode":0,"AA":false,"TC":false,"RD":true,"RA":true,"AD":false,"CD":false,"RCODE":0,` +
+				`"QDCOUNT":1,"ANCOUNT":2,"NSCOUNT":0,"ARCOUNT":1,"QNAME":"example.com.","QTYPE":15,"QTYPEname":"MX","QCLASS":1,"QCLASSname":"IN",` +
+				`"answerRRs":[` +
+				`{"NAME":"example.com.","TYPE":15,"TYPEname":"MX","CLASS":1,"CLASSname":"IN","TTL":300,"RDLENGTH":20,"rdataMX":"10 mail.example.com."},` +
+				`{"NAME":"example.com.","TYPE":731,"CLASS":1,"CLASSname":"IN","TTL":300,"RDLENGTH":2,"rdataHEX":"DEAD"}],` +
+				`"additionalRRs":[{"NAME":".","TYPE":41,"TYPEname":"OPT","CLASS":1232,"TTL":32768,"RDLENGTH":0,"rdataHEX":""}]}`,
+		},
+		{
+			name:    "Base64 RData",
+			options: JSONOptions{Base64RData: true},
+			want: `{"ID":1234,"QR":true,"Opcode":0,"AA":false,"TC":false,"RD":true,"RA":true,"AD":false,"CD":false,"RCODE":0,` +
+				`"QDCOUNT":1,"ANCOUNT":2,"NSCOUNT":0,"ARCOUNT":1,"QNAME":"example.com.","QTYPE":15,"QTYPEname":"MX","QCLASS":1,"QCLASSname":"IN",` +
+				`"answerRRs":[` +
+				`{"NAME":"example.com.","TYPE":15,"TYPEname":"MX","CLASS":1,"CLASSname":"IN","TTL":300,"RDLENGTH":20,"rdataMX":"10 mail.example.com."},` +
+				`{"NAME":"example.com.","TYPE":731,"CLASS":1,"CLASSname":"IN","TTL":300,"RDLENGTH":2,"rdataBASE64":"3q0="}],` +
+				`"additionalRRs":[{"NAME":".","TYPE":41,"TYPEname":"OPT","CLASS":1232,"TTL":32768,"RDLENGTH":0,"rdataBASE64":""}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarshalMessageJSON(message, tt.options)
+			if err != nil {
+				t.Fatalf("MarshalMessageJSON() unexpected error = %v\n", err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("MarshalMessageJSON() got = %s, want = %s\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageJSONRoundTrip(t *testing.T) {
+	multipleQuestions := Message{
+		Header: Header{Id: 1},
+		Questions: []Question{
+			{Name: "example.com.", QType: A, QClass: IN},
+			{Name: "example.com.", QType: AAAA, QClass: IN},
+		},
+		Answers: []ResourceRecord{
+			{Name: "example.com.", RType: A, RClass: IN, TTL: 300, RData: &RDataA{IP: netip.MustParseAddr("192.0.2.1")}},
+		},
+	}
+	seed, err := EncodeMessage(multipleQuestions)
+	if err != nil {
+		t.Fatalf("EncodeMessage() unexpected error = %v\n", err)
+	}
+
+	for _, data := range append(getFuzzSeeds(t), seed) {
+		message, err := DecodeMessage(data)
+		if err != nil {
+			t.Fatalf("DecodeMessage() unexpected error = %v\n", err)
+		}
+
+		for _, options := range []JSONOptions{{}, {Base64RData: true}} {
+			encoded, err := MarshalMessageJSON(message, options)
+			if err != nil {
+				t.Fatalf("MarshalMessageJSON() unexpected error = %v\n", err)
+			}
+
+			var got Message
+			if err = json.Unmarshal(encoded, &got); err != nil {
+				t.Fatalf("UnmarshalJSON() unexpected error = %v\n", err)
+			}
+			if differences := getMessageDifferences(message, got); len(differences) > 0 {
+				t.Errorf("UnmarshalJSON() got differences: %s\n", strings.Join(differences, "; "))
+			}
+		}
+	}
+}
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantError error
+	}{
+		{
+			name:      "Not an object",
+			data:      `[]`,
+			wantError: ErrInvalidMessage,
+		},
+		{
+			name:      "Invalid presentation format",
+			data:      `{"answerRRs":[{"NAME":"example.com.","TYPE":1,"CLASS":1,"TTL":300,"rdataA":"2001:db8::1"}]}`,
+			wantError: ErrInvalidResourceRecord,
+		},
+		{
+			name:      "Invalid hexadecimal",
+			data:      `{"answerRRs":[{"NAME":"example.com.","TYPE":731,"CLASS":1,"TTL":300,"rdataHEX":"XY"}]}`,
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Raw RData invalid for the type",
+			data:      `{"answerRRs":[{"NAME":"example.com.","TYPE":1,"CLASS":1,"TTL":300,"rdataHEX":"C00002"}]}`,
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Two OPT records",
+			data:      `{"additionalRRs":[{"NAME":".","TYPE":41,"CLASS":1232,"TTL":0},{"NAME":".","TYPE":41,"CLASS":1232,"TTL":0}]}`,
+			wantError: ErrInvalidResourceRecord,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := Message{Header: Header{Id: 1}}
+			err := json.Unmarshal([]byte(tt.data), &message)
+
+			if err == nil || !errors.Is(err, tt.wantError) {
+				t.Fatalf("UnmarshalJSON() error = %v, want error = %v\n", err, tt.wantError)
+			}
+			if message.Header.Id != 1 {
+				t.Errorf("UnmarshalJSON() changed the message on error: %+v\n", message)
+			}
+		})
+	}
+}
+
+func TestGetPresentationMember(t *testing.T) {
+	// Checking for a parser must not panic for any type, since the parsers are called without fields
+	for rtype := range dnsTypeNames {
+		if member := getPresentationMember(rtype); member != "" && member != "rdata"+DNSType(rtype).String() {
+			t.Errorf("getPresentationMember(%s) got = %q\n", DNSType(rtype), member)
+		}
+	}
+
+	if got := getPresentationMember(MX); got != "rdataMX" {
+		t.Errorf("getPresentationMember(MX) got = %q, want = %q\n", got, "rdataMX")
+	}
+	if got := getPresentationMember(OPT); got != "" {
+		t.Errorf("getPresentationMember(OPT) got = %q, want = %q\n", got, "")
+	}
+}
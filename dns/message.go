@@ -21,17 +21,51 @@ import (
 //     |      Additional     | RRs holding additional information
 //     +---------------------+
 
+// The OPT pseudo-record is not kept in the additional section: its EDNS parameters are
+// in the EDNS field instead [RFC6891]. The header's additional count still includes it.
+
 type Message struct {
 	Header      Header
 	Questions   []Question
 	Answers     []ResourceRecord
 	NameServers []ResourceRecord
 	Additionals []ResourceRecord
+	EDNS        *EDNS   // EDNS parameters of the OPT pseudo-record, nil without one
+	Warnings    []error // Problems found when decoding the message in lenient mode
 }
 
 const MaxDNSMessageSizeOverUDP = 512
 const MaxDNSMessageSize = 4096
 
+// DefaultEDNSUDPSize is the UDP payload size advertised in OPT records added by the encoder.
+// 1232 bytes avoids IP fragmentation on virtually all paths (DNS Flag Day 2020).
+const DefaultEDNSUDPSize = 1232
+
+// DecodeMode is how strictly DecodeMessageWithOptions checks a message.
+type DecodeMode int
+
+const (
+	// DecodeDefault rejects messages that cannot be decoded, as DecodeMessage does.
+	// Trailing bytes after the last record are ignored.
+	DecodeDefault DecodeMode = iota
+	// DecodeStrict also rejects trailing bytes after the last record, RData shorter
+	// than its RDLength, QUERY messages with more than one question [RFC9619], and
+	// client subnet options with address bits set past their source prefix length [RFC7871],
+	// ex. to validate the messages of a server.
+	DecodeStrict
+	// DecodeLenient decodes as much of a message as possible, ex. to analyze captured traffic.
+	// The problems found are in the Warnings of the message instead: records with malformed RData
+	// are kept with their RData as RDataUnknown, and the sections are cut short at the first record
+	// that cannot be read.
+	DecodeLenient
+)
+
+// DecodeOptions control how DecodeMessageWithOptions reads a message.
+// The zero value decodes as DecodeMessage does.
+type DecodeOptions struct {
+	Mode DecodeMode
+}
+
 // DecodeMessage parses DNS message data and returns a Message structure.
 //
 // Parameters:
@@ -41,7 +75,21 @@ const MaxDNSMessageSize = 4096
 //   - *Message: The decoded DNS message in a structure.
 //   - error: If the message is invalid or decoding fails.
 func DecodeMessage(data []byte) (Message, error) {
-	reader := &dnsReader{data: data}
+	return DecodeMessageWithOptions(data, DecodeOptions{})
+}
+
+// DecodeMessageWithOptions parses DNS message data like DecodeMessage, as strictly as the options' mode requires.
+// A message with an invalid header is always rejected.
+//
+// Parameters:
+//   - data: The DNS message in a byte slice.
+//   - options: The decoding options.
+//
+// Returns:
+//   - Message: The decoded DNS message, with the problems found in its Warnings in lenient mode.
+//   - error: If the message is invalid for the mode.
+func DecodeMessageWithOptions(data []byte, options DecodeOptions) (Message, error) {
+	reader := &dnsReader{data: data, mode: options.Mode}
 
 	header, err := reader.readHeader()
 	if err != nil {
@@ -52,55 +100,285 @@ func DecodeMessage(data []byte) (Message, error) {
 
 	}
 
-	questions, err := reader.readQuestions(header.QuestionCount)
-	if err != nil {
-		return Message{}, invalidMessageError(fmt.Sprintf("question section: %s", err.Error()))
+	message := Message{Header: header}
+
+	if header.Flags.Opcode == QUERY && header.QuestionCount > 1 {
+		// Decoded as is by default: the questions are all read, but servers hardly ever answer them
+		questionErr := invalidMessageError(fmt.Sprintf("%d questions in a QUERY message", header.QuestionCount))
+		switch reader.mode {
+		case DecodeStrict:
+			return Message{}, questionErr
+		case DecodeLenient:
+			message.Warnings = append(message.Warnings, questionErr)
+		}
 	}
 
-	answers, err := reader.readResourceRecords(header.AnswerRRCount)
+	message.Questions, err = reader.readQuestions(header.QuestionCount)
 	if err != nil {
-		return Message{}, invalidMessageError(fmt.Sprintf("answer section: %s", err.Error()))
+		err = fmt.Errorf("%w: question section: %w", ErrInvalidMessage, err)
+		if reader.mode != DecodeLenient {
+			return Message{}, err
+		}
+		message.Warnings = append(message.Warnings, err)
+	}
+
+	for _, section := range []struct {
+		name    string
+		count   uint16
+		records *[]ResourceRecord
+	}{
+		{"answer", header.AnswerRRCount, &message.Answers},
+		{"authority", header.NameserverRRCount, &message.NameServers},
+		{"additional", header.AdditionalRRCount, &message.Additionals},
+	} {
+		if err != nil {
+			// The offset of the next section is unknown after a section could not be read
+			*section.records = []ResourceRecord{}
+			continue
+		}
+
+		*section.records, err = reader.readResourceRecords(section.count)
+		if err != nil {
+			err = fmt.Errorf("%w: %s section: %w", ErrInvalidMessage, section.name, err)
+			if reader.mode != DecodeLenient {
+				return Message{}, err
+			}
+			message.Warnings = append(message.Warnings, err)
+		}
 	}
+	message.Warnings = append(message.Warnings, reader.warnings...)
 
-	nameServers, err := reader.readResourceRecords(header.NameserverRRCount)
+	if err == nil && reader.offset != len(data) {
+		err = invalidMessageError(fmt.Sprintf("%d trailing bytes after the last record", len(data)-reader.offset))
+		switch reader.mode {
+		case DecodeStrict:
+			return Message{}, err
+		case DecodeLenient:
+			message.Warnings = append(message.Warnings, err)
+		}
+	}
+
+	additionals, edns, err := getEDNSFromAdditionals(message.Additionals)
 	if err != nil {
-		return Message{}, invalidMessageError(fmt.Sprintf("authority section: %s", err.Error()))
+		err = fmt.Errorf("%w: additional section: %w", ErrInvalidMessage, err)
+		if reader.mode != DecodeLenient {
+			return Message{}, err
+		}
+		// Leave the OPT records in the additional section
+		message.Warnings = append(message.Warnings, err)
+	} else {
+		message.Additionals = additionals
+		message.EDNS = edns
+	}
+	if message.EDNS != nil {
+		message.Header.Flags.DnssecOk = message.EDNS.DnssecOk
 	}
 
-	additionals, err := reader.readResourceRecords(header.AdditionalRRCount)
+	return message, nil
+}
+
+// Unpack decodes DNS message data into the message, like DecodeMessage.
+//
+// Parameters:
+//   - data: The DNS message in a byte slice.
+//
+// Returns:
+//   - error: If the message is invalid or decoding fails. The message is left unchanged.
+func (message *Message) Unpack(data []byte) error {
+	decoded, err := DecodeMessage(data)
 	if err != nil {
-		return Message{}, invalidMessageError(fmt.Sprintf("additional section: %s", err.Error()))
+		return err
 	}
+	*message = decoded
+	return nil
+}
+
+// getEDNSFromAdditionals separates the OPT pseudo-record from the other records of the additional section.
+// A message may carry at most one OPT record [RFC6891].
+func getEDNSFromAdditionals(records []ResourceRecord) (additionals []ResourceRecord, edns *EDNS, err error) {
+	additionals = make([]ResourceRecord, 0, len(records))
+	for _, record := range records {
+		if record.RType != OPT {
+			additionals = append(additionals, record)
+			continue
+		}
+		if edns != nil {
+			return nil, nil, invalidResourceRecordError("more than one OPT record")
+		}
+		if record.Name != "." {
+			return nil, nil, invalidResourceRecordError(fmt.Sprintf("OPT record owner name is not the root: %s", record.Name))
+		}
+
+		parsed, err := ParseEDNS(record)
+		if err != nil {
+			return nil, nil, err
+		}
+		edns = &parsed
+	}
+	return additionals, edns, nil
+}
 
-	return Message{
-		Header:      header,
-		Questions:   questions,
-		Answers:     answers,
-		NameServers: nameServers,
-		Additionals: additionals,
-	}, nil
+// EncodeOptions control how EncodeMessageWithOptions writes a message.
+// The zero value computes the header counts and the RDLengths, as EncodeMessage does.
+type EncodeOptions struct {
+	KeepHeaderCounts bool // Write the header's section counts as is, instead of the lengths of the sections
+	KeepRDLength     bool // Write the records' RDLength as is, instead of the length of their encoded RData
 }
 
 // EncodeMessage converts a Message structure into DNS message bytes.
 //
+// The section counts of the header are set from the number of questions and records in each section,
+// and the RDLength of each record from the length of its encoded RData: the values of the message are ignored.
+//
+// Domain names are compressed [RFC1035]: the owner names, the question names and the names
+// in the RDATA of the RFC 1035 types (CNAME, MX, NS, PTR and SOA) are replaced by a pointer to
+// their longest suffix already written in the message.
+//
+// The EDNS parameters are written as an OPT record at the end of the additional section,
+// before a final SIG(0) or TSIG record which must stay last. Since the DO flag is carried
+// by the OPT record rather than the header, setting Flags.DnssecOk sets the DO bit of
+// the message's EDNS parameters, adding them with the default UDP payload size if there are none.
+//
 // Parameters:
 //   - msg: A pointer to a Message structure to encode.
 //
 // Returns:
 //   - []byte: The encoded DNS message bytes.
-//   - error: If encoding fails.
+//   - error: If a domain name or the RData of a record cannot be encoded, or a section has too many records.
 func EncodeMessage(message Message) ([]byte, error) {
+	return EncodeMessageWithOptions(message, EncodeOptions{})
+}
+
+// Pack encodes the message into DNS message bytes, like EncodeMessage.
+//
+// Returns:
+//   - []byte: The encoded DNS message bytes.
+//   - error: If a domain name or the RData of a record cannot be encoded, or a section has too many records.
+func (message *Message) Pack() ([]byte, error) {
+	return EncodeMessage(*message)
+}
+
+// EncodeMessageWithOptions converts a Message structure into DNS message bytes, like EncodeMessage.
+// The options can keep the header counts and RDLengths of the message, ex. to build deliberately
+// malformed messages for testing.
+//
+// Parameters:
+//   - message: The message to encode.
+//   - options: The encoding options.
+//
+// Returns:
+//   - []byte: The encoded DNS message bytes.
+//   - error: If a domain name or the RData of a record cannot be encoded, or a section has too many records.
+func EncodeMessageWithOptions(message Message, options EncodeOptions) ([]byte, error) {
 	writer := &dnsWriter{
-		data:   make([]byte, DNSHeaderLength),
-		offset: 0,
+		data:         make([]byte, DNSHeaderLength),
+		offset:       0,
+		compression:  map[string]int{},
+		keepRDLength: options.KeepRDLength,
+	}
+
+	message = applyDnssecOk(message)
+	message = applyExtendedResponseCode(message)
+	additionals := getAdditionalsWithEDNS(message)
+
+	if !options.KeepHeaderCounts {
+		for _, count := range []struct {
+			count *uint16
+			n     int
+		}{
+			{&message.Header.QuestionCount, len(message.Questions)},
+			{&message.Header.AnswerRRCount, len(message.Answers)},
+			{&message.Header.NameserverRRCount, len(message.NameServers)},
+			{&message.Header.AdditionalRRCount, len(additionals)},
+		} {
+			if count.n > 0xFFFF {
+				return nil, invalidMessageError(fmt.Sprintf("too many records in a section: %d", count.n))
+			}
+			*count.count = uint16(count.n)
+		}
 	}
 
 	writer.writeHeader(message)
 
-	writer.writeQuestions(message.Questions)
-	writer.writeResourceRecords(message.Answers)
-	writer.writeResourceRecords(message.NameServers)
-	writer.writeResourceRecords(message.Additionals)
+	if err := writer.writeQuestions(message.Questions); err != nil {
+		return nil, fmt.Errorf("%w: question section: %w", ErrInvalidMessage, err)
+	}
+	for _, section := range []struct {
+		name    string
+		records []ResourceRecord
+	}{
+		{"answer", message.Answers},
+		{"authority", message.NameServers},
+		{"additional", additionals},
+	} {
+		if err := writer.writeResourceRecords(section.records); err != nil {
+			return nil, fmt.Errorf("%w: %s section: %w", ErrInvalidMessage, section.name, err)
+		}
+	}
 
 	return writer.data, nil
 }
+
+// getAdditionalsWithEDNS returns the additional section with the message's OPT record, if any.
+func getAdditionalsWithEDNS(message Message) []ResourceRecord {
+	if message.EDNS == nil {
+		return message.Additionals
+	}
+
+	additionals := append([]ResourceRecord{}, message.Additionals...)
+	end := len(additionals)
+	if end > 0 && (additionals[end-1].RType == SIG || additionals[end-1].RType == TSIG) {
+		end--
+	}
+	return append(additionals[:end], append([]ResourceRecord{message.EDNS.ResourceRecord()}, additionals[end:]...)...)
+}
+
+func applyDnssecOk(message Message) Message {
+	if !message.Header.Flags.DnssecOk {
+		return message
+	}
+
+	// Copy the EDNS parameters so the caller's message is left untouched
+	if message.EDNS != nil {
+		edns := *message.EDNS
+		edns.DnssecOk = true
+		message.EDNS = &edns
+		return message
+	}
+
+	message.EDNS = &EDNS{UDPSize: DefaultEDNSUDPSize, DnssecOk: true}
+	message.Header.AdditionalRRCount++
+
+	return message
+}
+
+// applyExtendedResponseCode moves the upper 8 bits of a header response code above 15
+// to the extended RCODE of the OPT record [RFC6891], adding one if the message has none.
+func applyExtendedResponseCode(message Message) Message {
+	responseCode := message.Header.Flags.ResponseCode
+	if responseCode <= RCodeMask {
+		return message
+	}
+
+	edns := EDNS{UDPSize: DefaultEDNSUDPSize}
+	if message.EDNS != nil {
+		edns = *message.EDNS
+	} else {
+		message.Header.AdditionalRRCount++
+	}
+	edns.ExtendedRCode = uint8(responseCode >> 4)
+	message.EDNS = &edns
+	message.Header.Flags.ResponseCode = responseCode & RCodeMask
+
+	return message
+}
+
+// ResponseCode returns the full 12-bit response code of the message [RFC6891]: the RCODE of
+// the header, with the extended RCODE of the OPT record as its upper 8 bits, ex. BADCOOKIE.
+func (message Message) ResponseCode() uint16 {
+	responseCode := message.Header.Flags.ResponseCode
+	if message.EDNS != nil && responseCode <= RCodeMask {
+		responseCode |= uint16(message.EDNS.ExtendedRCode) << 4
+	}
+	return responseCode
+}
@@ -1,8 +1,11 @@
 package dns
 
 import (
+	"errors"
 	"net/netip"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -210,3 +213,579 @@ func TestEncodeDNSMessage(t *testing.T) {
 		t.Errorf("encodeDNSMessage() bytes\n\tgot = %v,\n\twant = %v\n", got, want)
 	}
 }
+
+func TestEncodeDNSMessageDnssecOk(t *testing.T) {
+	message := Message{
+		Header: Header{
+			Id:            1234,
+			Flags:         Flags{RecursionDesired: true, DnssecOk: true},
+			QuestionCount: 1,
+		},
+		Questions: []Question{
+			{
+				Name:   "example.com.",
+				QType:  A,
+				QClass: IN,
+			},
+		},
+	}
+	want := []byte{
+		0x04, 0xd2, // ID bytes
+		0x01, 0x00, // Flags: recursion desired
+		0x00, 0x01, // Question count: 1
+		0x00, 0x00, // Answer count: 0
+		0x00, 0x00, // Authority count: 0
+		0x00, 0x01, // Additional count: 1
+		0x07, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x03, 0x63, 0x6f, 0x6d, 0x00, // example.com.
+		0x00, 0x01, // QTYPE: 1 (A)
+		0x00, 0x01, // QCLASS: 1 (IN)
+		0x00,       // OPT Name: root
+		0x00, 0x29, // OPT Type: 41
+		0x04, 0xd0, // UDP payload size: 1232
+		0x00, 0x00, 0x80, 0x00, // Extended RCODE 0, version 0, DO bit set
+		0x00, 0x00, // RDLength: 0
+	}
+
+	got, err := EncodeMessage(message)
+
+	if err != nil {
+		t.Fatalf("encodeDNSMessage() unexpected error = %v\n", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("encodeDNSMessage() bytes\n\tgot = %v,\n\twant = %v\n", got, want)
+	}
+	if len(message.Additionals) != 0 || message.EDNS != nil || message.Header.AdditionalRRCount != 0 {
+		t.Errorf("encodeDNSMessage() modified the original message: %+v\n", message)
+	}
+
+	decoded, err := DecodeMessage(got)
+	if err != nil {
+		t.Fatalf("decodeDNSMessage() unexpected error = %v\n", err)
+	}
+	if !decoded.Header.Flags.DnssecOk {
+		t.Errorf("decodeDNSMessage() DnssecOk got = false, want = true\n")
+	}
+}
+
+func TestDecodeDNSMessageEDNS(t *testing.T) {
+	header := []byte{
+		0x04, 0xd2, // ID bytes
+		0x81, 0x80, // Flags: response, recursion desired, recursion available
+		0x00, 0x00, // Question count: 0
+		0x00, 0x00, // Answer count: 0
+		0x00, 0x00, // Authority count: 0
+	}
+	opt := []byte{
+		0x00,       // OPT Name: root
+		0x00, 0x29, // OPT Type: 41
+		0x10, 0x00, // UDP payload size: 4096
+		0x01, 0x00, 0x80, 0x00, // Extended RCODE 1, version 0, DO bit set
+		0x00, 0x06, // RDLength: 6
+		0x00, 0x0f, 0x00, 0x02, 0x00, 0x12, // EDE option: 18 (Prohibited)
+	}
+	glue := []byte{
+		0x02, 'n', 's', 0x00, // Name: ns.
+		0x00, 0x01, // Type: 1 (A)
+		0x00, 0x01, // Class: 1 (IN)
+		0x00, 0x00, 0x01, 0x2c, // TTL: 300
+		0x00, 0x04, // RDLength: 4
+		0xc0, 0x00, 0x02, 0x01, // 192.0.2.1
+	}
+
+	data := append(append(append(append([]byte{}, header...), 0x00, 0x02), opt...), glue...)
+
+	message, err := DecodeMessage(data)
+	if err != nil {
+		t.Fatalf("DecodeMessage() unexpected error = %v\n", err)
+	}
+
+	want := &EDNS{UDPSize: 4096, ExtendedRCode: 1, DnssecOk: true, Options: []EDNSOption{&EDNSOptionEDE{InfoCode: 18}}}
+	if !reflect.DeepEqual(message.EDNS, want) {
+		t.Errorf("DecodeMessage() EDNS got = %+v, want = %+v\n", message.EDNS, want)
+	}
+	if !message.Header.Flags.DnssecOk {
+		t.Errorf("DecodeMessage() DnssecOk got = false, want = true\n")
+	}
+	if len(message.Additionals) != 1 || message.Additionals[0].RType != A || message.Header.AdditionalRRCount != 2 {
+		t.Errorf("DecodeMessage() additional section got = %+v, count = %d\n", message.Additionals, message.Header.AdditionalRRCount)
+	}
+
+	// The OPT record is written back after the other additional records
+	encoded, err := EncodeMessage(message)
+	if err != nil {
+		t.Fatalf("EncodeMessage() unexpected error = %v\n", err)
+	}
+	wantEncoded := append(append(append(append([]byte{}, header...), 0x00, 0x02), glue...), opt...)
+	if !reflect.DeepEqual(encoded, wantEncoded) {
+		t.Errorf("EncodeMessage() bytes\n\tgot = %v,\n\twant = %v\n", encoded, wantEncoded)
+	}
+
+	// A message may carry only one OPT record
+	data = append(append(append(append([]byte{}, header...), 0x00, 0x02), opt...), opt...)
+	if _, err := DecodeMessage(data); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("DecodeMessage() error = %v, want error = %v\n", err, ErrInvalidMessage)
+	}
+}
+
+func TestEncodeDNSMessageEDNSBeforeSignature(t *testing.T) {
+	message := Message{
+		Header:      Header{Id: 1, AdditionalRRCount: 2},
+		Additionals: []ResourceRecord{{Name: ".", RType: SIG, RClass: ANY, RData: &RDataUnknown{}}},
+		EDNS:        &EDNS{UDPSize: 1232},
+	}
+
+	data, err := EncodeMessage(message)
+	if err != nil {
+		t.Fatalf("EncodeMessage() unexpected error = %v\n", err)
+	}
+
+	reader := &dnsReader{data: data, offset: DNSHeaderLength}
+	records, err := reader.readResourceRecords(2)
+	if err != nil {
+		t.Fatalf("readResourceRecords() unexpected error = %v\n", err)
+	}
+	if records[0].RType != OPT || records[1].RType != SIG {
+		t.Errorf("EncodeMessage() additional types got = %s %s, want = OPT SIG\n", DNSType(records[0].RType), DNSType(records[1].RType))
+	}
+}
+
+func TestEncodeDNSMessageCompression(t *testing.T) {
+	message := Message{
+		Header:    Header{Id: 1, Flags: Flags{Response: true}, QuestionCount: 1, AnswerRRCount: 3},
+		Questions: []Question{{Name: "example.com.", QType: ANY, QClass: IN}},
+		Answers: []ResourceRecord{
+			{Name: "example.com.", RType: MX, RClass: IN, TTL: 300, RData: &RDataMX{Preference: 10, Exchange: "mail.example.com."}},
+			{Name: "www.example.com.", RType: CNAME, RClass: IN, TTL: 300, RData: &RDataCNAME{DomainName: "example.com."}},
+			{Name: "_sip._tcp.example.com.", RType: SRV, RClass: IN, TTL: 300, RData: &RDataSRV{Priority: 1, Weight: 1, Port: 5060, Target: "sip.example.com."}},
+		},
+	}
+
+	data, err := EncodeMessage(message)
+	if err != nil {
+		t.Fatalf("EncodeMessage() unexpected error = %v\n", err)
+	}
+
+	want := []byte{
+		0x00, 0x01, 0x80, 0x00, 0x00, 0x01, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, // Header
+		7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, 0x00, 0xff, 0x00, 0x01, // 12: Question example.com. ANY IN
+		0xC0, 12, 0x00, 0x0f, 0x00, 0x01, 0x00, 0x00, 0x01, 0x2c, // 29: example.com. MX IN 300
+		0x00, 0x09, 0x00, 0x0a, 4, 'm', 'a', 'i', 'l', 0xC0, 12, // RDLength 9: 10 mail.example.com.
+		3, 'w', 'w', 'w', 0xC0, 12, 0x00, 0x05, 0x00, 0x01, 0x00, 0x00, 0x01, 0x2c, // 50: www.example.com. CNAME IN 300
+		0x00, 0x02, 0xC0, 12, // RDLength 2: example.com.
+		4, '_', 's', 'i', 'p', 4, '_', 't', 'c', 'p', 0xC0, 12, 0x00, 0x21, 0x00, 0x01, 0x00, 0x00, 0x01, 0x2c, // 68: _sip._tcp.example.com. SRV IN 300
+		0x00, 0x17, 0x00, 0x01, 0x00, 0x01, 0x13, 0xc4, // RDLength 23: 1 1 5060
+		3, 's', 'i', 'p', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // SRV targets are not compressed [RFC2782]
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("EncodeMessage() bytes\n\tgot = %v,\n\twant = %v\n", data, want)
+	}
+
+	decoded, err := DecodeMessage(data)
+	if err != nil {
+		t.Fatalf("DecodeMessage() unexpected error = %v\n", err)
+	}
+	for i, record := range decoded.Answers {
+		if record.Name != message.Answers[i].Name || record.RData.String() != message.Answers[i].RData.String() {
+			t.Errorf("DecodeMessage() answer got = %s %s, want = %s %s\n", record.Name, record.RData, message.Answers[i].Name, message.Answers[i].RData)
+		}
+	}
+}
+
+func TestEncodeDNSMessageWithOptions(t *testing.T) {
+	message := Message{
+		Header:    Header{Id: 1, Flags: Flags{Response: true}, QuestionCount: 5, AnswerRRCount: 0, AdditionalRRCount: 2},
+		Questions: []Question{{Name: ".", QType: A, QClass: IN}},
+		Answers: []ResourceRecord{
+			{Name: ".", RType: A, RClass: IN, TTL: 300, RDLength: 100, RData: &RDataA{IP: netip.MustParseAddr("192.0.2.1")}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		options EncodeOptions
+		want    []byte
+	}{
+		{
+			name:    "Counts and RDLength computed",
+			options: EncodeOptions{},
+			want: []byte{
+				0x00, 0x01, 0x80, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, // Header: 1 question, 1 answer
+				0, 0x00, 0x01, 0x00, 0x01, // Question: . A IN
+				0, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, 0x01, 0x2c, 0x00, 0x04, 192, 0, 2, 1, // Answer: RDLength 4
+			},
+		},
+		{
+			name:    "Counts and RDLength kept",
+			options: EncodeOptions{KeepHeaderCounts: true, KeepRDLength: true},
+			want: []byte{
+				0x00, 0x01, 0x80, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, // Header: counts as given
+				0, 0x00, 0x01, 0x00, 0x01, // Question: . A IN
+				0, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, 0x01, 0x2c, 0x00, 0x64, 192, 0, 2, 1, // Answer: RDLength 100
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeMessageWithOptions(message, tt.options)
+			if err != nil {
+				t.Fatalf("EncodeMessageWithOptions() unexpected error = %v\n", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EncodeMessageWithOptions() bytes\n\tgot = %v,\n\twant = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeDNSMessageInvalidRData(t *testing.T) {
+	message := Message{
+		Answers: []ResourceRecord{
+			{Name: "example.com.", RType: TXT, RClass: IN, TTL: 300, RData: &RDataTXT{Text: []string{string(make([]byte, 256))}}},
+		},
+	}
+
+	if _, err := EncodeMessage(message); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("EncodeMessage() error = %v, want error = %v\n", err, ErrInvalidMessage)
+	}
+}
+
+func TestEncodeDNSMessageInvalidName(t *testing.T) {
+	longLabel := strings.Repeat("a", 64)
+	longName := strings.Repeat("abcdefg.", 32) + "example."
+	tests := []struct {
+		name    string
+		message Message
+	}{
+		{name: "Label of 192 bytes in the question", message: *NewQuery(strings.Repeat("a", 192)+".example.com.", A)},
+		{name: "Label longer than 63 bytes in the question", message: *NewQuery(longLabel+".example.com.", A)},
+		{name: "Name longer than 255 bytes in the question", message: *NewQuery(longName, A)},
+		{
+			name:    "Label longer than 63 bytes in a record name",
+			message: Message{Answers: []ResourceRecord{{Name: longLabel + ".example.com.", RType: A, RClass: IN, TTL: 300, RData: &RDataA{IP: netip.MustParseAddr("192.0.2.1")}}}},
+		},
+		{
+			name:    "Name longer than 255 bytes in RData",
+			message: Message{Answers: []ResourceRecord{{Name: "example.com.", RType: CNAME, RClass: IN, TTL: 300, RData: &RDataCNAME{DomainName: longName}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := EncodeMessage(tt.message)
+			if !errors.Is(err, ErrInvalidMessage) || !errors.Is(err, ErrInvalidDomainName) {
+				t.Errorf("EncodeMessage() got = %v, error = %v, want error = %v\n", data, err, ErrInvalidDomainName)
+			}
+		})
+	}
+}
+
+func TestDecodeDNSMessageWithOptions(t *testing.T) {
+	header := func(answerCount byte) []byte {
+		return []byte{
+			0x04, 0xd2, // ID bytes
+			0x81, 0x80, // Flags: response, recursion desired, recursion available
+			0x00, 0x00, // Question count: 0
+			0x00, answerCount, // Answer count
+			0x00, 0x00, // Authority count: 0
+			0x00, 0x00, // Additional count: 0
+		}
+	}
+	a := []byte{
+		0x00,       // Name: root
+		0x00, 0x01, // Type: 1 (A)
+		0x00, 0x01, // Class: 1 (IN)
+		0x00, 0x00, 0x01, 0x2c, // TTL: 300
+		0x00, 0x04, // RDLength: 4
+		0xc0, 0x00, 0x02, 0x01, // 192.0.2.1
+	}
+	shortA := []byte{
+		0x00,       // Name: root
+		0x00, 0x01, // Type: 1 (A)
+		0x00, 0x01, // Class: 1 (IN)
+		0x00, 0x00, 0x01, 0x2c, // TTL: 300
+		0x00, 0x03, // RDLength: 3
+		0xc0, 0x00, 0x02, // Truncated address
+	}
+	longMX := []byte{
+		0x00,       // Name: root
+		0x00, 0x0f, // Type: 15 (MX)
+		0x00, 0x01, // Class: 1 (IN)
+		0x00, 0x00, 0x01, 0x2c, // TTL: 300
+		0x00, 0x05, // RDLength: 5
+		0x00, 0x0a, 0x00, // Preference 10, exchange root
+		0xff, 0xff, // Bytes after the exchange
+	}
+
+	tests := []struct {
+		name         string
+		data         []byte
+		mode         DecodeMode
+		wantError    bool
+		wantAnswers  int
+		wantWarnings int
+	}{
+		{
+			name:        "Trailing bytes in default mode",
+			data:        slices.Concat(header(1), a, []byte{0xff}),
+			mode:        DecodeDefault,
+			wantAnswers: 1,
+		},
+		{
+			name:      "Trailing bytes in strict mode",
+			data:      slices.Concat(header(1), a, []byte{0xff}),
+			mode:      DecodeStrict,
+			wantError: true,
+		},
+		{
+			name:         "Trailing bytes in lenient mode",
+			data:         slices.Concat(header(1), a, []byte{0xff}),
+			mode:         DecodeLenient,
+			wantAnswers:  1,
+			wantWarnings: 1,
+		},
+		{
+			name:        "RData shorter than RDLength in default mode",
+			data:        slices.Concat(header(1), longMX),
+			mode:        DecodeDefault,
+			wantAnswers: 1,
+		},
+		{
+			name:      "RData shorter than RDLength in strict mode",
+			data:      slices.Concat(header(1), longMX),
+			mode:      DecodeStrict,
+			wantError: true,
+		},
+		{
+			name:         "RData shorter than RDLength in lenient mode",
+			data:         slices.Concat(header(2), longMX, a),
+			mode:         DecodeLenient,
+			wantAnswers:  2,
+			wantWarnings: 1,
+		},
+		{
+			name:      "Malformed RData in default mode",
+			data:      slices.Concat(header(2), shortA, a),
+			mode:      DecodeDefault,
+			wantError: true,
+		},
+		{
+			name:         "Malformed RData in lenient mode",
+			data:         slices.Concat(header(2), shortA, a),
+			mode:         DecodeLenient,
+			wantAnswers:  2,
+			wantWarnings: 1,
+		},
+		{
+			name:      "Answer count too high in strict mode",
+			data:      slices.Concat(header(3), a, a),
+			mode:      DecodeStrict,
+			wantError: true,
+		},
+		{
+			name:         "Answer count too high in lenient mode",
+			data:         slices.Concat(header(3), a, a),
+			mode:         DecodeLenient,
+			wantAnswers:  2,
+			wantWarnings: 1,
+		},
+		{
+			name:      "Invalid header in lenient mode",
+			data:      []byte{0x04, 0xd2, 0x81},
+			mode:      DecodeLenient,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := DecodeMessageWithOptions(tt.data, DecodeOptions{Mode: tt.mode})
+
+			if tt.wantError {
+				if !errors.Is(err, ErrInvalidMessage) {
+					t.Fatalf("DecodeMessageWithOptions() error = %v, want error = %v\n", err, ErrInvalidMessage)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeMessageWithOptions() unexpected error = %v\n", err)
+			}
+
+			if len(message.Answers) != tt.wantAnswers {
+				t.Errorf("DecodeMessageWithOptions() answers got = %d, want = %d\n", len(message.Answers), tt.wantAnswers)
+			}
+			if len(message.Warnings) != tt.wantWarnings {
+				t.Errorf("DecodeMessageWithOptions() warnings got = %v, want %d warnings\n", message.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+
+	// Records with malformed RData are kept with their RData as is
+	message, _ := DecodeMessageWithOptions(slices.Concat(header(2), shortA, a), DecodeOptions{Mode: DecodeLenient})
+	want := &RDataUnknown{Data: []byte{0xc0, 0x00, 0x02}}
+	if !reflect.DeepEqual(message.Answers[0].RData, want) {
+		t.Errorf("DecodeMessageWithOptions() RData got = %+v, want = %+v\n", message.Answers[0].RData, want)
+	}
+	if !errors.Is(message.Warnings[0], ErrInvalidRecordData) {
+		t.Errorf("DecodeMessageWithOptions() warning got = %v, want error = %v\n", message.Warnings[0], ErrInvalidRecordData)
+	}
+}
+
+func TestMessagePackUnpack(t *testing.T) {
+	message := Message{
+		Header:    Header{Id: 1234, Flags: Flags{RecursionDesired: true}},
+		Questions: []Question{{Name: "example.com.", QType: A, QClass: IN}},
+	}
+
+	data, err := message.Pack()
+	if err != nil {
+		t.Fatalf("Pack() unexpected error = %v\n", err)
+	}
+
+	var got Message
+	if err = got.Unpack(data); err != nil {
+		t.Fatalf("Unpack() unexpected error = %v\n", err)
+	}
+	message.Header.QuestionCount = 1
+	message.Answers, message.NameServers, message.Additionals = []ResourceRecord{}, []ResourceRecord{}, []ResourceRecord{}
+	if !reflect.DeepEqual(got, message) {
+		t.Errorf("Unpack() got = %+v, want = %+v\n", got, message)
+	}
+
+	// The message is left unchanged when the data is invalid
+	if err = got.Unpack(data[:5]); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("Unpack() error = %v, want error = %v\n", err, ErrInvalidMessage)
+	}
+	if !reflect.DeepEqual(got, message) {
+		t.Errorf("Unpack() after error got = %+v, want = %+v\n", got, message)
+	}
+}
+
+func TestEncodeDecodeDNSMessageOpcodes(t *testing.T) {
+	for _, opcode := range []uint16{QUERY, IQUERY, STATUS, UNASSIGNED, NOTIFY, UPDATE, DSO, 15} {
+		t.Run(DNSOpCode(opcode).String(), func(t *testing.T) {
+			message := Message{
+				Header:      Header{Id: 1234, Flags: Flags{Opcode: opcode}, QuestionCount: 1, NameserverRRCount: 1},
+				Questions:   []Question{{Name: "example.com.", QType: SOA, QClass: IN}},
+				Answers:     []ResourceRecord{},
+				NameServers: []ResourceRecord{{Name: "www.example.com.", RType: A, RClass: ANY, RData: &RDataUnknown{}}},
+				Additionals: []ResourceRecord{},
+			}
+
+			data, err := EncodeMessage(message)
+			if err != nil {
+				t.Fatalf("EncodeMessage() unexpected error = %v\n", err)
+			}
+			got, err := DecodeMessage(data)
+			if err != nil {
+				t.Fatalf("DecodeMessage() unexpected error = %v\n", err)
+			}
+			if !reflect.DeepEqual(got, message) {
+				t.Errorf("DecodeMessage() got = %+v, want = %+v\n", got, message)
+			}
+		})
+	}
+}
+
+func TestEncodeDecodeDNSMessageExtendedResponseCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     Message
+		wantEDNS *EDNS
+	}{
+		{
+			name:     "OPT record added",
+			data:     Message{Header: Header{Id: 1, Flags: Flags{Response: true, ResponseCode: BADCOOKIE}}},
+			wantEDNS: &EDNS{UDPSize: DefaultEDNSUDPSize, ExtendedRCode: 1},
+		},
+		{
+			name:     "OPT record updated",
+			data:     Message{Header: Header{Id: 1, Flags: Flags{Response: true, ResponseCode: BADVERS}}, EDNS: &EDNS{UDPSize: 4096}},
+			wantEDNS: &EDNS{UDPSize: 4096, ExtendedRCode: 1},
+		},
+		{
+			name:     "Extended RCODE in OPT record",
+			data:     Message{Header: Header{Id: 1, Flags: Flags{Response: true, ResponseCode: 7}}, EDNS: &EDNS{UDPSize: 4096, ExtendedRCode: 1}},
+			wantEDNS: &EDNS{UDPSize: 4096, ExtendedRCode: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := EncodeMessage(tt.data)
+			if err != nil {
+				t.Fatalf("EncodeMessage() unexpected error = %v\n", err)
+			}
+			got, err := DecodeMessage(data)
+			if err != nil {
+				t.Fatalf("DecodeMessage() unexpected error = %v\n", err)
+			}
+			if got.Header.Flags.ResponseCode != tt.data.ResponseCode()&RCodeMask || got.Header.AdditionalRRCount != 1 {
+				t.Errorf("DecodeMessage() header got = %+v\n", got.Header)
+			}
+			if !reflect.DeepEqual(got.EDNS, tt.wantEDNS) {
+				t.Errorf("DecodeMessage() EDNS got = %+v, want = %+v\n", got.EDNS, tt.wantEDNS)
+			}
+			if got.ResponseCode() != tt.data.ResponseCode() {
+				t.Errorf("ResponseCode() got = %s, want = %s\n", DNSRCode(got.ResponseCode()), DNSRCode(tt.data.ResponseCode()))
+			}
+		})
+	}
+
+	// The caller's message is left untouched
+	message := Message{Header: Header{Flags: Flags{ResponseCode: BADCOOKIE}}}
+	if _, err := EncodeMessage(message); err != nil {
+		t.Fatalf("EncodeMessage() unexpected error = %v\n", err)
+	}
+	if message.EDNS != nil || message.Header.Flags.ResponseCode != BADCOOKIE {
+		t.Errorf("EncodeMessage() modified message = %+v\n", message)
+	}
+}
+
+func TestDecodeDNSMessageMultipleQuestions(t *testing.T) {
+	questions := []Question{
+		{Name: "example.com.", QType: A, QClass: IN},
+		{Name: "example.com.", QType: AAAA, QClass: IN},
+	}
+
+	tests := []struct {
+		name         string
+		opcode       uint16
+		mode         DecodeMode
+		wantError    bool
+		wantWarnings int
+	}{
+		{name: "Query in default mode", opcode: QUERY, mode: DecodeDefault},
+		{name: "Query in strict mode", opcode: QUERY, mode: DecodeStrict, wantError: true},
+		{name: "Query in lenient mode", opcode: QUERY, mode: DecodeLenient, wantWarnings: 1},
+		{name: "Other opcode in strict mode", opcode: STATUS, mode: DecodeStrict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := Message{Header: Header{Id: 1234, Flags: Flags{Opcode: tt.opcode}}}
+			data, err := query.AddQuestion(questions...).Pack()
+			if err != nil {
+				t.Fatalf("Pack() unexpected error = %v\n", err)
+			}
+
+			message, err := DecodeMessageWithOptions(data, DecodeOptions{Mode: tt.mode})
+			if tt.wantError {
+				if !errors.Is(err, ErrInvalidMessage) {
+					t.Fatalf("DecodeMessageWithOptions() error = %v, want error = %v\n", err, ErrInvalidMessage)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeMessageWithOptions() unexpected error = %v\n", err)
+			}
+
+			if !reflect.DeepEqual(message.Questions, questions) {
+				t.Errorf("DecodeMessageWithOptions() questions got = %+v, want = %+v\n", message.Questions, questions)
+			}
+			if len(message.Warnings) != tt.wantWarnings {
+				t.Errorf("DecodeMessageWithOptions() warnings got = %v, want %d warnings\n", message.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package dns
+
+import "strings"
+
+// Domain names are handled in their presentation form, ex. "www.example.com.": labels separated
+// by dots, with a final dot for the root when the name is fully qualified. Names are compared
+// case insensitively [RFC4343], with or without their final dot.
+
+// SplitLabels returns the labels of a name from the leftmost to the rightmost, keeping their case,
+// ex. ["www", "example", "com"] for "www.example.com.". The root has no labels.
+func SplitLabels(name string) []string {
+	labels := []string{}
+	for _, label := range strings.Split(name, ".") {
+		if label != "" {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// CountLabels returns the number of labels of a name, not counting the root, ex. 3 for "www.example.com.".
+func CountLabels(name string) int {
+	return len(SplitLabels(name))
+}
+
+// IsSubdomain reports whether a name is equal to or under a zone, ex. "www.example.com." under "example.com.".
+// Every name is under the root zone ".".
+//
+// Parameters:
+//   - name: The name to check.
+//   - zone: The name of the zone.
+func IsSubdomain(name string, zone string) bool {
+	labels, zoneLabels := getLabels(name), getLabels(zone)
+	if len(labels) < len(zoneLabels) {
+		return false
+	}
+	for i := 1; i <= len(zoneLabels); i++ {
+		if labels[len(labels)-i] != zoneLabels[len(zoneLabels)-i] {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualNames reports whether two names are the same name: case insensitively [RFC4343],
+// with or without their final dot.
+func EqualNames(a string, b string) bool {
+	return CanonicalName(a) == CanonicalName(b)
+}
+
+// Fqdn returns a name fully qualified, with its final dot, ex. "example.com." for "example.com".
+func Fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// TrimZone returns a name relative to a zone, as written in the zone file of the zone,
+// ex. "www" for "www.example.com." in "example.com.". The zone itself is "@", and a name
+// not under the zone, or any name for the root zone, is returned fully qualified.
+//
+// Parameters:
+//   - name: The name to trim.
+//   - zone: The name of the zone.
+func TrimZone(name string, zone string) string {
+	return getRelativeName(Fqdn(name), Fqdn(zone))
+}
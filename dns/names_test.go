@@ -0,0 +1,91 @@
+package dns
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitLabels(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{name: "Fully qualified name", data: "www.Example.com.", want: []string{"www", "Example", "com"}},
+		{name: "Relative name", data: "www.example", want: []string{"www", "example"}},
+		{name: "Root", data: ".", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitLabels(tt.data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitLabels() got = %v, want = %v\n", got, tt.want)
+			}
+			if got := CountLabels(tt.data); got != len(tt.want) {
+				t.Errorf("CountLabels() got = %d, want = %d\n", got, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestIsSubdomain(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		zone string
+		want bool
+	}{
+		{name: "Subdomain", data: "www.example.com.", zone: "example.com.", want: true},
+		{name: "Same name in another case", data: "Example.COM", zone: "example.com.", want: true},
+		{name: "Root zone", data: "example.com.", zone: ".", want: true},
+		{name: "Parent", data: "com.", zone: "example.com.", want: false},
+		{name: "Label suffix", data: "myexample.com.", zone: "example.com.", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSubdomain(tt.data, tt.zone); got != tt.want {
+				t.Errorf("IsSubdomain() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqualNames(t *testing.T) {
+	if !EqualNames("WWW.example.com", "www.Example.com.") {
+		t.Errorf("EqualNames() got = false, want = true\n")
+	}
+	if EqualNames("www.example.com.", "www.example.org.") {
+		t.Errorf("EqualNames() got = true, want = false\n")
+	}
+}
+
+func TestFqdn(t *testing.T) {
+	for data, want := range map[string]string{"example.com": "example.com.", "example.com.": "example.com.", "": "."} {
+		if got := Fqdn(data); got != want {
+			t.Errorf("Fqdn(%q) got = %s, want = %s\n", data, got, want)
+		}
+	}
+}
+
+func TestTrimZone(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		zone string
+		want string
+	}{
+		{name: "Subdomain", data: "www.example.com.", zone: "example.com", want: "www"},
+		{name: "Zone", data: "example.com.", zone: "example.com.", want: "@"},
+		{name: "Not under the zone", data: "www.example.org", zone: "example.com.", want: "www.example.org."},
+		{name: "Root zone", data: "www.example.com.", zone: ".", want: "www.example.com."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TrimZone(tt.data, tt.zone); got != tt.want {
+				t.Errorf("TrimZone() got = %s, want = %s\n", got, tt.want)
+			}
+		})
+	}
+}
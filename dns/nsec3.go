@@ -0,0 +1,181 @@
+package dns
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"strings"
+)
+
+// NSEC3 hashed owner names [RFC5155]:
+// The owner name of an NSEC3 record is the hash of an original owner name, in base32hex,
+// prepended as a single label to the name of the zone:
+//
+//	IH(salt, x, 0) = H(x || salt)
+//	IH(salt, x, k) = H(IH(salt, x, k-1) || salt), if k > 0
+//
+//	hashed owner name = base32hex(IH(salt, owner name, iterations)) || "." || zone
+//
+// where x is the owner name in canonical (lowercase) wire format.
+
+// nsec3HashEncoding is the presentation format of hashed owner names: base32 with the extended hex alphabet [RFC4648].
+var nsec3HashEncoding = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+// HashNSEC3Name computes the NSEC3 hash of a domain name.
+//
+// Parameters:
+//   - name: The domain name to hash, ex. "www.example.com.".
+//   - hashAlgorithm: The hash algorithm of the NSEC3 chain, ex. NSEC3HashSHA1.
+//   - iterations: The number of additional times the hash is applied.
+//   - salt: The salt appended to the name before each hashing.
+//
+// Returns:
+//   - []byte: The hash of the name.
+//   - error: If the hash algorithm is not supported.
+func HashNSEC3Name(name string, hashAlgorithm uint8, iterations uint16, salt []byte) ([]byte, error) {
+	if hashAlgorithm != NSEC3HashSHA1 {
+		return nil, fmt.Errorf("%w: NSEC3 hash algorithm %d", ErrUnsupportedAlgorithm, hashAlgorithm)
+	}
+
+	writer := &dnsWriter{}
+	if err := writer.writeDomainName(strings.ToLower(name)); err != nil {
+		return nil, err
+	}
+
+	hash := writer.data
+	for i := 0; i <= int(iterations); i++ {
+		h := sha1.New()
+		h.Write(hash)
+		h.Write(salt)
+		hash = h.Sum(nil)
+	}
+	return hash, nil
+}
+
+// EncodeNSEC3Hash returns the base32hex presentation of an NSEC3 hash, without padding.
+func EncodeNSEC3Hash(hash []byte) string {
+	return nsec3HashEncoding.EncodeToString(hash)
+}
+
+// DecodeNSEC3Hash parses the base32hex presentation of an NSEC3 hash, in either case.
+func DecodeNSEC3Hash(label string) ([]byte, error) {
+	hash, err := nsec3HashEncoding.DecodeString(strings.ToUpper(label))
+	if err != nil || len(hash) == 0 || nsec3HashEncoding.EncodedLen(len(hash)) != len(label) {
+		return nil, invalidDomainNameError(fmt.Sprintf("invalid NSEC3 hash %s", label))
+	}
+	return hash, nil
+}
+
+// GetNSEC3HashedOwnerName returns the owner name of the NSEC3 record of a hashed name.
+//
+// Parameters:
+//   - hash: The NSEC3 hash of the original owner name.
+//   - zone: The name of the zone, ex. "example.com.".
+//
+// Returns:
+//   - string: The hashed owner name, in lowercase, ex. "0p9mhaveqvm6t7vbl5lop2u3t2rp3tom.example.com.".
+func GetNSEC3HashedOwnerName(hash []byte, zone string) string {
+	if zone == "." {
+		zone = ""
+	}
+	return strings.ToLower(EncodeNSEC3Hash(hash)) + "." + zone
+}
+
+// ParseNSEC3HashedOwnerName splits the owner name of an NSEC3 record into its hash and its zone.
+//
+// Parameters:
+//   - owner: The owner name of an NSEC3 record, ex. "0p9mhaveqvm6t7vbl5lop2u3t2rp3tom.example.com.".
+//
+// Returns:
+//   - []byte: The hash of the original owner name.
+//   - string: The name of the zone, ex. "example.com.".
+//   - error: If the first label of the owner name is not a base32hex hash.
+func ParseNSEC3HashedOwnerName(owner string) (hash []byte, zone string, err error) {
+	label, zone, _ := strings.Cut(owner, ".")
+	if zone == "" {
+		zone = "."
+	}
+
+	hash, err = DecodeNSEC3Hash(label)
+	if err != nil {
+		return nil, "", err
+	}
+	return hash, zone, nil
+}
+
+// Hash computes the NSEC3 hash of a name with the record's parameters.
+func (rdata *RDataNSEC3) Hash(name string) ([]byte, error) {
+	return HashNSEC3Name(name, rdata.HashAlgorithm, rdata.Iterations, rdata.Salt)
+}
+
+// Hash computes the NSEC3 hash of a name with the parameters of the zone's NSEC3 chain,
+// ex. to build the hashed owner names when signing the zone.
+func (rdata *RDataNSEC3PARAM) Hash(name string) ([]byte, error) {
+	return HashNSEC3Name(name, rdata.HashAlgorithm, rdata.Iterations, rdata.Salt)
+}
+
+// NSEC3Matches reports whether an NSEC3 record's owner name is the hash of a name.
+//
+// Parameters:
+//   - record: An NSEC3 resource record.
+//   - name: The domain name to match, ex. "www.example.com.".
+//
+// Returns:
+//   - bool: Whether the record matches the name.
+//   - error: If the record is not an NSEC3 record, its owner name is not hashed or its hash algorithm is not supported.
+func NSEC3Matches(record ResourceRecord, name string) (bool, error) {
+	ownerHash, hash, _, err := getNSEC3Hashes(record, name)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(ownerHash, hash), nil
+}
+
+// NSEC3Covers reports whether the hash of a name falls strictly between an NSEC3 record's owner name
+// and its next hashed owner name, which proves the name does not exist. The last record of the chain
+// covers the hashes after its owner and before the first one.
+//
+// Parameters:
+//   - record: An NSEC3 resource record.
+//   - name: The domain name to check, ex. "www.example.com.".
+//
+// Returns:
+//   - bool: Whether the record covers the name.
+//   - error: If the record is not an NSEC3 record, its owner name is not hashed or its hash algorithm is not supported.
+func NSEC3Covers(record ResourceRecord, name string) (bool, error) {
+	ownerHash, hash, rdata, err := getNSEC3Hashes(record, name)
+	if err != nil {
+		return false, err
+	}
+	next := rdata.NextHashedOwnerName
+	return isCovered(bytes.Compare(ownerHash, hash), bytes.Compare(hash, next), bytes.Compare(ownerHash, next)), nil
+}
+
+func getNSEC3Hashes(record ResourceRecord, name string) (ownerHash []byte, hash []byte, rdata *RDataNSEC3, err error) {
+	rdata, ok := record.RData.(*RDataNSEC3)
+	if !ok {
+		return nil, nil, nil, invalidRecordDataError(fmt.Sprintf("%s is not an NSEC3 record", record.Name))
+	}
+
+	ownerHash, _, err = ParseNSEC3HashedOwnerName(record.Name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	hash, err = rdata.Hash(name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return ownerHash, hash, rdata, nil
+}
+
+// isCovered reports whether a value sorts strictly between the owner and the next value of a chain record,
+// given the comparisons of owner and value, value and next, and owner and next.
+// The last record of the chain wraps around: its next value is the first one.
+func isCovered(ownerToValue int, valueToNext int, ownerToNext int) bool {
+	if ownerToNext < 0 {
+		return ownerToValue < 0 && valueToNext < 0
+	}
+	return ownerToValue < 0 || valueToNext < 0
+}
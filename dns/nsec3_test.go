@@ -0,0 +1,137 @@
+package dns
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// Parameters and hashes of the example zone of RFC5155 Appendix A
+var nsec3TestSalt = []byte{0xaa, 0xbb, 0xcc, 0xdd}
+
+func newTestNSEC3Record(owner string, next string) ResourceRecord {
+	nextHash, _ := DecodeNSEC3Hash(next)
+	return ResourceRecord{
+		Name:   owner,
+		RType:  NSEC3,
+		RClass: IN,
+		TTL:    3600,
+		RData: &RDataNSEC3{
+			HashAlgorithm:       NSEC3HashSHA1,
+			Flags:               NSEC3FlagOptOut,
+			Iterations:          12,
+			Salt:                nsec3TestSalt,
+			NextHashedOwnerName: nextHash,
+			Types:               []uint16{A, RRSIG},
+		},
+	}
+}
+
+func TestHashNSEC3Name(t *testing.T) {
+	tests := []struct {
+		name          string
+		hashAlgorithm uint8
+		iterations    uint16
+		salt          []byte
+		want          string
+		wantError     error
+	}{
+		{name: "example.", hashAlgorithm: NSEC3HashSHA1, iterations: 12, salt: nsec3TestSalt, want: "0P9MHAVEQVM6T7VBL5LOP2U3T2RP3TOM"},
+		{name: "a.example.", hashAlgorithm: NSEC3HashSHA1, iterations: 12, salt: nsec3TestSalt, want: "35MTHGPGCU1QG68FAB165KLNSNK3DPVL"},
+		{name: "A.EXAMPLE.", hashAlgorithm: NSEC3HashSHA1, iterations: 12, salt: nsec3TestSalt, want: "35MTHGPGCU1QG68FAB165KLNSNK3DPVL"},
+		{name: "*.w.example.", hashAlgorithm: NSEC3HashSHA1, iterations: 12, salt: nsec3TestSalt, want: "R53BQ7CC2UVMUBFU5OCMM6PERS9TK9EN"},
+		{name: "x.w.example.", hashAlgorithm: NSEC3HashSHA1, iterations: 12, salt: nsec3TestSalt, want: "B4UM86EGHHDS6NEA196SMVMLO4ORS995"},
+		{name: "example.", hashAlgorithm: 2, iterations: 12, salt: nsec3TestSalt, wantError: ErrUnsupportedAlgorithm},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := HashNSEC3Name(tt.name, tt.hashAlgorithm, tt.iterations, tt.salt)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("HashNSEC3Name() error = %v, want = %v\n", err, tt.wantError)
+			}
+			if got := EncodeNSEC3Hash(hash); err == nil && got != tt.want {
+				t.Errorf("HashNSEC3Name() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNSEC3HashedOwnerName(t *testing.T) {
+	tests := []struct {
+		name      string
+		owner     string
+		wantHash  string
+		wantZone  string
+		wantError error
+	}{
+		{name: "lowercase", owner: "0p9mhaveqvm6t7vbl5lop2u3t2rp3tom.example.", wantHash: "0P9MHAVEQVM6T7VBL5LOP2U3T2RP3TOM", wantZone: "example."},
+		{name: "uppercase", owner: "0P9MHAVEQVM6T7VBL5LOP2U3T2RP3TOM.example.", wantHash: "0P9MHAVEQVM6T7VBL5LOP2U3T2RP3TOM", wantZone: "example."},
+		{name: "root zone", owner: "0p9mhaveqvm6t7vbl5lop2u3t2rp3tom.", wantHash: "0P9MHAVEQVM6T7VBL5LOP2U3T2RP3TOM", wantZone: "."},
+		{name: "not base32hex", owner: "www.example.", wantError: ErrInvalidDomainName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, zone, err := ParseNSEC3HashedOwnerName(tt.owner)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("ParseNSEC3HashedOwnerName() error = %v, want = %v\n", err, tt.wantError)
+			}
+			if err != nil {
+				return
+			}
+			if got := EncodeNSEC3Hash(hash); got != tt.wantHash || zone != tt.wantZone {
+				t.Errorf("ParseNSEC3HashedOwnerName() got = %v %v, want = %v %v\n", got, zone, tt.wantHash, tt.wantZone)
+			}
+
+			owner := GetNSEC3HashedOwnerName(hash, zone)
+			decoded, _, err := ParseNSEC3HashedOwnerName(owner)
+			if err != nil || !bytes.Equal(decoded, hash) {
+				t.Errorf("GetNSEC3HashedOwnerName() got = %v, does not round trip\n", owner)
+			}
+		})
+	}
+}
+
+func TestNSEC3MatchesCovers(t *testing.T) {
+	// a.example. (35mthgpg...) is followed by x.w.example. (b4um86eg...) in the chain of the example zone,
+	// and xx.example. (t644ebqk...) is the last record, followed by example. (0p9mhave...)
+	record := newTestNSEC3Record("35mthgpgcu1qg68fab165klnsnk3dpvl.example.", "b4um86eghhds6nea196smvmlo4ors995")
+	last := newTestNSEC3Record("t644ebqk9bibcna874givr6joj62mlhv.example.", "0p9mhaveqvm6t7vbl5lop2u3t2rp3tom")
+
+	tests := []struct {
+		name        string
+		record      ResourceRecord
+		domainName  string
+		wantMatches bool
+		wantCovers  bool
+		wantError   error
+	}{
+		{name: "matching name", record: record, domainName: "a.example.", wantMatches: true},
+		{name: "matching name case insensitive", record: record, domainName: "A.Example.", wantMatches: true},
+		{name: "next name", record: record, domainName: "x.w.example.", wantCovers: false},
+		{name: "covered name", record: record, domainName: "c.example.", wantCovers: true},
+		{name: "name after next name", record: record, domainName: "b.example.", wantCovers: false},
+		{name: "covered after last hash", record: last, domainName: "f.example.", wantCovers: true},
+		{name: "not covered by last hash", record: last, domainName: "b.example.", wantCovers: false},
+		{name: "not NSEC3", record: newTestNSECRecord("a.example.", "b.example.", A), domainName: "a.example.", wantError: ErrInvalidRecordData},
+		{name: "owner not hashed", record: newTestNSEC3Record("a.example.", "b4um86eghhds6nea196smvmlo4ors995"), domainName: "a.example.", wantError: ErrInvalidDomainName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := NSEC3Matches(tt.record, tt.domainName)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("NSEC3Matches() error = %v, want = %v\n", err, tt.wantError)
+			}
+			if matches != tt.wantMatches {
+				t.Errorf("NSEC3Matches() got = %v, want = %v\n", matches, tt.wantMatches)
+			}
+
+			covers, err := NSEC3Covers(tt.record, tt.domainName)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("NSEC3Covers() error = %v, want = %v\n", err, tt.wantError)
+			}
+			if covers != tt.wantCovers {
+				t.Errorf("NSEC3Covers() got = %v, want = %v\n", covers, tt.wantCovers)
+			}
+		})
+	}
+}
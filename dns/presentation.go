@@ -0,0 +1,725 @@
+package dns
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record data can be built from its fields, with the struct of its type, ex. &RDataMX{Preference: 10, Exchange: "mail.example.com."},
+// or parsed from its presentation format, as found in zone files, with ParseRData: ParseRData(MX, "10 mail.example.com.").
+// EncodeRData returns its wire format.
+
+// EncodeRData returns the wire format of record data. Domain names are not compressed.
+//
+// Parameters:
+//   - rdata: The record data to encode.
+//
+// Returns:
+//   - []byte: The encoded record data.
+//   - error: If the fields of the record data cannot be encoded, ex. too long a character-string.
+func EncodeRData(rdata RData) ([]byte, error) {
+	writer := &dnsWriter{}
+	if err := rdata.WriteRecordData(writer); err != nil {
+		return nil, err
+	}
+	if len(writer.data) > 0xFFFF {
+		return nil, invalidRecordDataError(fmt.Sprintf("too long: %d bytes", len(writer.data)))
+	}
+	return writer.data, nil
+}
+
+// ParseRData reads record data in its presentation format [RFC1035], as printed by its String method.
+// Fields are separated by spaces, and may span several lines between parentheses. Comments start with ";".
+// Character-strings may be quoted and use the \X and \DDD escapes. Domain names must be fully qualified:
+// a final dot is added if they have none.
+//
+// The generic format of unknown types [RFC3597], ex. "\# 4 0A000001", is accepted for all types, and is
+// the only one accepted for the types without a presentation format parser (OPT and unknown types).
+//
+// Parameters:
+//   - rtype: The type of the record.
+//   - presentation: The record data in presentation format, ex. "10 mail.example.com." for an MX record.
+//
+// Returns:
+//   - RData: The record data in the struct of its type.
+//   - error: If the presentation format is invalid for the type, ErrInvalidDomainName if a domain name
+//     has a label longer than 63 bytes or is longer than 255 bytes.
+func ParseRData(rtype uint16, presentation string) (RData, error) {
+	if fields := strings.Fields(presentation); len(fields) > 0 && fields[0] == `\#` {
+		return ParseGenericRData(rtype, presentation)
+	}
+
+	fields, err := getPresentationFields(presentation)
+	if err != nil {
+		return nil, invalidRecordDataError(fmt.Sprintf("%s RData: %s", DNSType(rtype), err.Error()))
+	}
+
+	rdata, err := parseRDataFields(rtype, fields)
+	if err != nil {
+		return nil, invalidRecordDataError(fmt.Sprintf("%s RData: %s", DNSType(rtype), err.Error()))
+	}
+
+	if _, err = EncodeRData(rdata); err != nil {
+		return nil, err
+	}
+	return rdata, nil
+}
+
+// defaultTTL is the TTL of records parsed by NewRR without a TTL.
+const defaultTTL = 3600
+
+// NewRR reads a record from its presentation format, as found in zone files [RFC1035]:
+// its owner name, optional TTL and class in either order, type and RData, as read by ParseRData,
+// ex. "example.com. 300 IN MX 10 mail.example.com.". The TTL defaults to 3600 and the class to IN.
+// Unknown types and classes are written TYPEn and CLASSn [RFC3597].
+//
+// Parameters:
+//   - presentation: The record in presentation format. A final dot is added to the owner name if it has none.
+//
+// Returns:
+//   - ResourceRecord: The record, with its RDLength set from its RData.
+//   - error: If the presentation format is invalid, ErrInvalidDomainName if a domain name
+//     has a label longer than 63 bytes or is longer than 255 bytes.
+func NewRR(presentation string) (ResourceRecord, error) {
+	if presentation == "" || strings.ContainsRune(" \t", rune(presentation[0])) {
+		return ResourceRecord{}, invalidResourceRecordError(fmt.Sprintf("no owner name: %q", presentation))
+	}
+
+	owner, rest := cutField(presentation)
+	record := ResourceRecord{
+		Name:   Fqdn(owner),
+		RClass: IN,
+		TTL:    defaultTTL,
+	}
+	if _, err := getWireLabels(record.Name); err != nil {
+		return ResourceRecord{}, fmt.Errorf("%w: %w", ErrInvalidResourceRecord, err)
+	}
+
+	var field string
+	hasTTL, hasClass := false, false
+	for {
+		field, rest = cutField(rest)
+		if field == "" {
+			return ResourceRecord{}, invalidResourceRecordError(fmt.Sprintf("no type: %q", presentation))
+		}
+
+		if ttl, err := strconv.ParseUint(field, 10, 32); err == nil && !hasTTL {
+			record.TTL, hasTTL = uint32(ttl), true
+			continue
+		}
+		if rclass, ok := parseClass(field); ok && !hasClass {
+			record.RClass, hasClass = rclass, true
+			continue
+		}
+
+		rtype, err := parseType(field)
+		if err != nil {
+			return ResourceRecord{}, invalidResourceRecordError(err.Error())
+		}
+		record.RType = rtype
+		break
+	}
+
+	rdata, err := ParseRData(record.RType, rest)
+	if err != nil {
+		return ResourceRecord{}, fmt.Errorf("%w: %s: %w", ErrInvalidResourceRecord, record.Name, err)
+	}
+	record.RData = rdata
+	if record.RDLength, err = getRDLength(rdata); err != nil {
+		return ResourceRecord{}, err
+	}
+	return record, nil
+}
+
+// cutField returns the first field of a line separated by spaces or tabs, and the rest of the line.
+func cutField(line string) (field string, rest string) {
+	line = strings.TrimLeft(line, " \t")
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		return line[:i], line[i:]
+	}
+	return line, ""
+}
+
+// parseClass reads a class mnemonic, or CLASSn for unknown classes [RFC3597].
+func parseClass(field string) (uint16, bool) {
+	field = strings.ToUpper(field)
+	if field == "ANY" {
+		return ANY, true
+	}
+	for rclass, name := range dnsClassNames {
+		if field == name {
+			return rclass, true
+		}
+	}
+	if number, ok := strings.CutPrefix(field, "CLASS"); ok {
+		if rclass, err := strconv.ParseUint(number, 10, 16); err == nil {
+			return uint16(rclass), true
+		}
+	}
+	return 0, false
+}
+
+func parseRDataFields(rtype uint16, fields []string) (RData, error) {
+	switch rtype {
+	case A, AAAA:
+		if err := checkFieldCount(fields, 1, 1); err != nil {
+			return nil, err
+		}
+		ip, err := netip.ParseAddr(fields[0])
+		if err != nil || (rtype == A && !ip.Is4()) || (rtype == AAAA && !ip.Is6()) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidIP, fields[0])
+		}
+		if rtype == A {
+			return &RDataA{IP: ip}, nil
+		}
+		return &RDataAAAA{IP: ip}, nil
+
+	case CNAME, PTR, NS, DNAME:
+		if err := checkFieldCount(fields, 1, 1); err != nil {
+			return nil, err
+		}
+		name := Fqdn(fields[0])
+		switch rtype {
+		case CNAME:
+			return &RDataCNAME{DomainName: name}, nil
+		case PTR:
+			return &RDataPTR{DomainName: name}, nil
+		case DNAME:
+			return &RDataDNAME{Target: name}, nil
+		}
+		return &RDataNS{DomainName: name}, nil
+
+	case TXT:
+		if err := checkFieldCount(fields, 1, -1); err != nil {
+			return nil, err
+		}
+		text := make([]string, 0, len(fields))
+		for _, field := range fields {
+			characterString, err := parseCharacterString(field)
+			if err != nil {
+				return nil, err
+			}
+			text = append(text, characterString)
+		}
+		return &RDataTXT{Text: text}, nil
+
+	case HINFO:
+		if err := checkFieldCount(fields, 2, 2); err != nil {
+			return nil, err
+		}
+		cpu, cpuErr := parseCharacterString(fields[0])
+		operatingSystem, osErr := parseCharacterString(fields[1])
+		if err := errors.Join(cpuErr, osErr); err != nil {
+			return nil, err
+		}
+		return &RDataHINFO{CPU: cpu, OS: operatingSystem}, nil
+
+	case MX:
+		if err := checkFieldCount(fields, 2, 2); err != nil {
+			return nil, err
+		}
+		preference, err := parseUint(fields[0], 16)
+		if err != nil {
+			return nil, err
+		}
+		return &RDataMX{Preference: uint16(preference), Exchange: Fqdn(fields[1])}, nil
+
+	case NAPTR:
+		if err := checkFieldCount(fields, 6, 6); err != nil {
+			return nil, err
+		}
+		order, orderErr := parseUint(fields[0], 16)
+		preference, preferenceErr := parseUint(fields[1], 16)
+		flags, flagsErr := parseCharacterString(fields[2])
+		services, servicesErr := parseCharacterString(fields[3])
+		regexp, regexpErr := parseCharacterString(fields[4])
+		if err := errors.Join(orderErr, preferenceErr, flagsErr, servicesErr, regexpErr); err != nil {
+			return nil, err
+		}
+		return &RDataNAPTR{
+			Order:       uint16(order),
+			Preference:  uint16(preference),
+			Flags:       flags,
+			Services:    services,
+			Regexp:      regexp,
+			Replacement: Fqdn(fields[5]),
+		}, nil
+
+	case SRV:
+		if err := checkFieldCount(fields, 4, 4); err != nil {
+			return nil, err
+		}
+		priority, priorityErr := parseUint(fields[0], 16)
+		weight, weightErr := parseUint(fields[1], 16)
+		port, portErr := parseUint(fields[2], 16)
+		if err := errors.Join(priorityErr, weightErr, portErr); err != nil {
+			return nil, err
+		}
+		return &RDataSRV{
+			Priority: uint16(priority),
+			Weight:   uint16(weight),
+			Port:     uint16(port),
+			Target:   Fqdn(fields[3]),
+		}, nil
+
+	case URI:
+		if err := checkFieldCount(fields, 3, 3); err != nil {
+			return nil, err
+		}
+		priority, priorityErr := parseUint(fields[0], 16)
+		weight, weightErr := parseUint(fields[1], 16)
+		target, targetErr := parseCharacterString(fields[2])
+		if err := errors.Join(priorityErr, weightErr, targetErr); err != nil {
+			return nil, err
+		}
+		return &RDataURI{Priority: uint16(priority), Weight: uint16(weight), Target: target}, nil
+
+	case SOA:
+		if err := checkFieldCount(fields, 7, 7); err != nil {
+			return nil, err
+		}
+		var values [5]uint64
+		var errs [5]error
+		for i := range values {
+			values[i], errs[i] = parseUint(fields[2+i], 32)
+		}
+		if err := errors.Join(errs[:]...); err != nil {
+			return nil, err
+		}
+		return &RDataSOA{
+			MName:   Fqdn(fields[0]),
+			RName:   Fqdn(fields[1]),
+			Serial:  uint32(values[0]),
+			Refresh: uint32(values[1]),
+			Retry:   uint32(values[2]),
+			Expire:  uint32(values[3]),
+			Minimum: uint32(values[4]),
+		}, nil
+
+	case RRSIG, SIG:
+		if err := checkFieldCount(fields, 9, -1); err != nil {
+			return nil, err
+		}
+		typeCovered, typeErr := parseType(fields[0])
+		algorithm, algorithmErr := parseUint(fields[1], 8)
+		labels, labelsErr := parseUint(fields[2], 8)
+		originalTTL, ttlErr := parseUint(fields[3], 32)
+		expiration, expirationErr := parseRRSIGTime(fields[4])
+		inception, inceptionErr := parseRRSIGTime(fields[5])
+		keyTag, keyTagErr := parseUint(fields[6], 16)
+		signature, signatureErr := base64.StdEncoding.DecodeString(strings.Join(fields[8:], ""))
+		if err := errors.Join(typeErr, algorithmErr, labelsErr, ttlErr, expirationErr, inceptionErr, keyTagErr, signatureErr); err != nil {
+			return nil, err
+		}
+		return &RDataRRSIG{
+			TypeCovered: typeCovered,
+			Algorithm:   uint8(algorithm),
+			Labels:      uint8(labels),
+			OriginalTTL: uint32(originalTTL),
+			Expiration:  expiration,
+			Inception:   inception,
+			KeyTag:      uint16(keyTag),
+			SignerName:  Fqdn(fields[7]),
+			Signature:   signature,
+		}, nil
+
+	case DNSKEY, KEY, CDNSKEY:
+		if err := checkFieldCount(fields, 4, -1); err != nil {
+			return nil, err
+		}
+		flags, flagsErr := parseUint(fields[0], 16)
+		protocol, protocolErr := parseUint(fields[1], 8)
+		algorithm, algorithmErr := parseUint(fields[2], 8)
+		publicKey, publicKeyErr := base64.StdEncoding.DecodeString(strings.Join(fields[3:], ""))
+		if err := errors.Join(flagsErr, protocolErr, algorithmErr, publicKeyErr); err != nil {
+			return nil, err
+		}
+		return &RDataDNSKEY{
+			Flags:     uint16(flags),
+			Protocol:  uint8(protocol),
+			Algorithm: uint8(algorithm),
+			PublicKey: publicKey,
+		}, nil
+
+	case DS, CDS:
+		if err := checkFieldCount(fields, 4, -1); err != nil {
+			return nil, err
+		}
+		keyTag, keyTagErr := parseUint(fields[0], 16)
+		algorithm, algorithmErr := parseUint(fields[1], 8)
+		digestType, digestTypeErr := parseUint(fields[2], 8)
+		digest, digestErr := hex.DecodeString(strings.Join(fields[3:], ""))
+		if err := errors.Join(keyTagErr, algorithmErr, digestTypeErr, digestErr); err != nil {
+			return nil, err
+		}
+		return &RDataDS{
+			KeyTag:     uint16(keyTag),
+			Algorithm:  uint8(algorithm),
+			DigestType: uint8(digestType),
+			Digest:     digest,
+		}, nil
+
+	case TLSA, SMIMEA:
+		if err := checkFieldCount(fields, 4, -1); err != nil {
+			return nil, err
+		}
+		usage, usageErr := parseUint(fields[0], 8)
+		selector, selectorErr := parseUint(fields[1], 8)
+		matchingType, matchingTypeErr := parseUint(fields[2], 8)
+		data, dataErr := hex.DecodeString(strings.Join(fields[3:], ""))
+		if err := errors.Join(usageErr, selectorErr, matchingTypeErr, dataErr); err != nil {
+			return nil, err
+		}
+		return &RDataTLSA{
+			Usage:        uint8(usage),
+			Selector:     uint8(selector),
+			MatchingType: uint8(matchingType),
+			Data:         data,
+		}, nil
+
+	case NSEC:
+		if err := checkFieldCount(fields, 1, -1); err != nil {
+			return nil, err
+		}
+		types, err := parseTypes(fields[1:])
+		if err != nil {
+			return nil, err
+		}
+		return &RDataNSEC{NextDomainName: Fqdn(fields[0]), Types: types}, nil
+
+	case NSEC3:
+		if err := checkFieldCount(fields, 5, -1); err != nil {
+			return nil, err
+		}
+		hashAlgorithm, flags, iterations, salt, parametersErr := parseNSEC3Parameters(fields[:4])
+		nextHashedOwnerName, hashErr := DecodeNSEC3Hash(fields[4])
+		types, typesErr := parseTypes(fields[5:])
+		if err := errors.Join(parametersErr, hashErr, typesErr); err != nil {
+			return nil, err
+		}
+		return &RDataNSEC3{
+			HashAlgorithm:       hashAlgorithm,
+			Flags:               flags,
+			Iterations:          iterations,
+			Salt:                salt,
+			NextHashedOwnerName: nextHashedOwnerName,
+			Types:               types,
+		}, nil
+
+	case NSEC3PARAM:
+		if err := checkFieldCount(fields, 4, 4); err != nil {
+			return nil, err
+		}
+		hashAlgorithm, flags, iterations, salt, err := parseNSEC3Parameters(fields)
+		if err != nil {
+			return nil, err
+		}
+		return &RDataNSEC3PARAM{HashAlgorithm: hashAlgorithm, Flags: flags, Iterations: iterations, Salt: salt}, nil
+
+	case CSYNC:
+		if err := checkFieldCount(fields, 2, -1); err != nil {
+			return nil, err
+		}
+		serial, serialErr := parseUint(fields[0], 32)
+		flags, flagsErr := parseUint(fields[1], 16)
+		types, typesErr := parseTypes(fields[2:])
+		if err := errors.Join(serialErr, flagsErr, typesErr); err != nil {
+			return nil, err
+		}
+		return &RDataCSYNC{Serial: uint32(serial), Flags: uint16(flags), Types: types}, nil
+
+	case CERT:
+		if err := checkFieldCount(fields, 4, -1); err != nil {
+			return nil, err
+		}
+		certType, certTypeErr := parseCERTType(fields[0])
+		keyTag, keyTagErr := parseUint(fields[1], 16)
+		algorithm, algorithmErr := parseUint(fields[2], 8)
+		certificate, certificateErr := base64.StdEncoding.DecodeString(strings.Join(fields[3:], ""))
+		if err := errors.Join(certTypeErr, keyTagErr, algorithmErr, certificateErr); err != nil {
+			return nil, err
+		}
+		return &RDataCERT{
+			Type:        certType,
+			KeyTag:      uint16(keyTag),
+			Algorithm:   uint8(algorithm),
+			Certificate: certificate,
+		}, nil
+
+	case OPENPGPKEY:
+		if err := checkFieldCount(fields, 1, -1); err != nil {
+			return nil, err
+		}
+		publicKey, err := base64.StdEncoding.DecodeString(strings.Join(fields, ""))
+		if err != nil {
+			return nil, err
+		}
+		return &RDataOPENPGPKEY{PublicKey: publicKey}, nil
+
+	case LOC:
+		return parseLOCFields(fields)
+
+	case SVCB, HTTPS:
+		return parseSVCBFields(fields)
+	}
+	return nil, errNoPresentationParser
+}
+
+var errNoPresentationParser = fmt.Errorf("no presentation format parser for the type, use the generic format")
+
+// hasPresentationParser reports whether ParseRData can parse the presentation format of the type,
+// other than the generic format.
+func hasPresentationParser(rtype uint16) bool {
+	_, err := parseRDataFields(rtype, nil)
+	return !errors.Is(err, errNoPresentationParser)
+}
+
+// getPresentationFields splits record data in presentation format into its fields.
+// Quotes are removed from quoted fields, but escapes are kept so that parseCharacterString can read them.
+func getPresentationFields(presentation string) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	inField, quoted, parentheses := false, false, 0
+
+	for i := 0; i < len(presentation); i++ {
+		c := presentation[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(presentation) {
+				return nil, fmt.Errorf("unterminated escape")
+			}
+			field.WriteByte(c)
+			field.WriteByte(presentation[i+1])
+			inField = true
+			i++
+		case quoted:
+			if c == '"' {
+				quoted = false
+			} else {
+				field.WriteByte(c)
+			}
+		case c == '"':
+			quoted, inField = true, true
+		case c == ';':
+			for i < len(presentation) && presentation[i] != '\n' {
+				i++
+			}
+			i-- // Let the newline end the field
+		case c == '(' || c == ')' || c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if c == '(' {
+				parentheses++
+			} else if c == ')' {
+				parentheses--
+			}
+			if parentheses < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses")
+			}
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteByte(c)
+			inField = true
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated quoted string")
+	}
+	if parentheses != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses")
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields, nil
+}
+
+// checkFieldCount checks that there are between min and max fields, and at least min if max is -1.
+func checkFieldCount(fields []string, min int, max int) error {
+	if len(fields) < min || (max >= 0 && len(fields) > max) {
+		return fmt.Errorf("invalid number of fields: %d", len(fields))
+	}
+	return nil
+}
+
+// parseCharacterString reads a <character-string> field, replacing its \X and \DDD escapes [RFC1035].
+func parseCharacterString(field string) (string, error) {
+	characterString, err := parseEscapedValue(field)
+	if err != nil {
+		return "", err
+	}
+	if len(characterString) > 255 {
+		return "", fmt.Errorf("character-string too long: %d bytes", len(characterString))
+	}
+	return characterString, nil
+}
+
+// parseEscapedValue replaces the \X and \DDD escapes of a field [RFC1035].
+func parseEscapedValue(field string) (string, error) {
+	var characterString strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] != '\\' {
+			characterString.WriteByte(field[i])
+			continue
+		}
+
+		if i+3 < len(field) && isDigit(field[i+1]) && isDigit(field[i+2]) && isDigit(field[i+3]) {
+			value, _ := strconv.Atoi(field[i+1 : i+4])
+			if value > 255 {
+				return "", fmt.Errorf("invalid escape in character-string: \\%s", field[i+1:i+4])
+			}
+			characterString.WriteByte(byte(value))
+			i += 3
+		} else if i+1 < len(field) && !isDigit(field[i+1]) {
+			characterString.WriteByte(field[i+1])
+			i++
+		} else {
+			return "", fmt.Errorf("invalid escape in character-string: %s", field[i:])
+		}
+	}
+	return characterString.String(), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func parseUint(field string, bitSize int) (uint64, error) {
+	value, err := strconv.ParseUint(field, 10, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %d bit number: %s", bitSize, field)
+	}
+	return value, nil
+}
+
+// parseType reads a type mnemonic, ex. "MX", or the generic TYPEn format of unknown types [RFC3597].
+func parseType(field string) (uint16, error) {
+	if rtype, ok := DNSTypeNames[strings.ToUpper(field)]; ok {
+		return rtype, nil
+	}
+	if number, ok := strings.CutPrefix(strings.ToUpper(field), "TYPE"); ok {
+		if rtype, err := strconv.ParseUint(number, 10, 16); err == nil {
+			return uint16(rtype), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid type: %s", field)
+}
+
+func parseTypes(fields []string) ([]uint16, error) {
+	types := make([]uint16, 0, len(fields))
+	for _, field := range fields {
+		rtype, err := parseType(field)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, rtype)
+	}
+	return types, nil
+}
+
+// parseRRSIGTime reads a signature expiration or inception, as YYYYMMDDHHmmSS in UTC or as seconds since the epoch [RFC4034].
+func parseRRSIGTime(field string) (uint32, error) {
+	if len(field) == len(rrsigTimeFormat) {
+		signatureTime, err := time.Parse(rrsigTimeFormat, field)
+		if err != nil {
+			return 0, fmt.Errorf("invalid signature time: %s", field)
+		}
+		// Signature times are serial numbers, which wrap around after 2106 [RFC4034]
+		return uint32(signatureTime.Unix()), nil
+	}
+
+	seconds, err := parseUint(field, 32)
+	return uint32(seconds), err
+}
+
+// parseNSEC3Parameters reads the hash algorithm, flags, iterations and salt ("-" if empty) of NSEC3 and NSEC3PARAM records.
+func parseNSEC3Parameters(fields []string) (hashAlgorithm uint8, flags uint8, iterations uint16, salt []byte, err error) {
+	algorithmValue, algorithmErr := parseUint(fields[0], 8)
+	flagsValue, flagsErr := parseUint(fields[1], 8)
+	iterationsValue, iterationsErr := parseUint(fields[2], 16)
+	var saltErr error
+	if fields[3] != "-" {
+		salt, saltErr = hex.DecodeString(fields[3])
+	}
+	if err = errors.Join(algorithmErr, flagsErr, iterationsErr, saltErr); err != nil {
+		return 0, 0, 0, nil, err
+	}
+	return uint8(algorithmValue), uint8(flagsValue), uint16(iterationsValue), salt, nil
+}
+
+// parseCERTType reads a certificate type mnemonic, ex. "PKIX", or number [RFC4398].
+func parseCERTType(field string) (uint16, error) {
+	for certType, name := range certTypeNames {
+		if strings.EqualFold(field, name) {
+			return certType, nil
+		}
+	}
+	certType, err := parseUint(field, 16)
+	return uint16(certType), err
+}
+
+// parseLOCFields reads the coordinates, altitude, size and precisions of a LOC record [RFC1876]:
+// "d1 [m1 [s1]] {N|S} d2 [m2 [s2]] {E|W} alt[m] [siz[m] [hp[m] [vp[m]]]]".
+func parseLOCFields(fields []string) (RData, error) {
+	latitude, fields, latitudeErr := parseLOCCoordinate(fields, "N", "S")
+	if latitudeErr != nil {
+		return nil, latitudeErr
+	}
+	longitude, fields, longitudeErr := parseLOCCoordinate(fields, "E", "W")
+	if longitudeErr != nil {
+		return nil, longitudeErr
+	}
+	if err := checkFieldCount(fields, 1, 4); err != nil {
+		return nil, err
+	}
+
+	// Size and precisions default to 1m, 10,000m and 10m
+	meters := []float64{0, 1, 10000, 10}
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(strings.TrimSuffix(field, "m"), 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, fmt.Errorf("invalid distance: %s", field)
+		}
+		meters[i] = value
+	}
+
+	rdata, err := NewRDataLOC(latitude, longitude, meters[0], meters[1], meters[2], meters[3])
+	if err != nil {
+		return nil, err
+	}
+	return rdata, nil
+}
+
+// parseLOCCoordinate reads degrees, optional minutes and seconds, and a hemisphere,
+// and returns the coordinate in degrees with the remaining fields.
+func parseLOCCoordinate(fields []string, positive string, negative string) (float64, []string, error) {
+	degrees := 0.0
+	for i, field := range fields {
+		if i > 3 {
+			break
+		}
+		if i > 0 && (strings.EqualFold(field, positive) || strings.EqualFold(field, negative)) {
+			if strings.EqualFold(field, negative) {
+				degrees = -degrees
+			}
+			return degrees, fields[i+1:], nil
+		}
+
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil || value < 0 || (i > 0 && value >= 60) || (i < 2 && value != math.Trunc(value)) {
+			return 0, nil, fmt.Errorf("invalid coordinate: %s", field)
+		}
+		degrees += value / math.Pow(60, float64(i))
+	}
+	return 0, nil, fmt.Errorf("invalid coordinate: no %s or %s hemisphere", positive, negative)
+}
@@ -0,0 +1,448 @@
+package dns
+
+import (
+	"bytes"
+	"errors"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRData(t *testing.T) {
+	tests := []struct {
+		name         string
+		rtype        uint16
+		presentation string
+		want         RData
+		wantError    error
+	}{
+		{
+			name:         "A record",
+			rtype:        A,
+			presentation: "192.0.2.1",
+			want:         &RDataA{IP: netip.MustParseAddr("192.0.2.1")},
+		},
+		{
+			name:         "AAAA record",
+			rtype:        AAAA,
+			presentation: "2001:db8::1",
+			want:         &RDataAAAA{IP: netip.MustParseAddr("2001:db8::1")},
+		},
+		{
+			name:         "IPv6 address in A record",
+			rtype:        A,
+			presentation: "2001:db8::1",
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "CNAME record without final dot",
+			rtype:        CNAME,
+			presentation: "www.example.com",
+			want:         &RDataCNAME{DomainName: "www.example.com."},
+		},
+		{
+			name:         "MX record",
+			rtype:        MX,
+			presentation: "10 mail.example.com.",
+			want:         &RDataMX{Preference: 10, Exchange: "mail.example.com."},
+		},
+		{
+			name:         "MX record with invalid preference",
+			rtype:        MX,
+			presentation: "65536 mail.example.com.",
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "TXT record with quoted and unquoted character-strings",
+			rtype:        TXT,
+			presentation: `"v=spf1 -all" unquoted "say \"hi\"\010" ""`,
+			want:         &RDataTXT{Text: []string{"v=spf1 -all", "unquoted", "say \"hi\"\n", ""}},
+		},
+		{
+			name:         "TXT record with unterminated quoted string",
+			rtype:        TXT,
+			presentation: `"v=spf1 -all`,
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "TXT record with invalid escape",
+			rtype:        TXT,
+			presentation: `"\256"`,
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "SOA record on several lines with comments",
+			rtype:        SOA,
+			presentation: "ns1.example.com. hostmaster.example.com. (\n\t2024010101 ; serial\n\t7200 ; refresh\n\t3600 1209600 300 )",
+			want: &RDataSOA{
+				MName:   "ns1.example.com.",
+				RName:   "hostmaster.example.com.",
+				Serial:  2024010101,
+				Refresh: 7200,
+				Retry:   3600,
+				Expire:  1209600,
+				Minimum: 300,
+			},
+		},
+		{
+			name:         "SOA record with unbalanced parentheses",
+			rtype:        SOA,
+			presentation: "ns1.example.com. hostmaster.example.com. ( 1 2 3 4 5",
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "SOA record with missing field",
+			rtype:        SOA,
+			presentation: "ns1.example.com. hostmaster.example.com. 1 2 3 4",
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "DS record with digest split by spaces",
+			rtype:        DS,
+			presentation: "60485 5 1 2BB183AF5F22588179A53B0A 98631FAD1A292118",
+			want: &RDataDS{
+				KeyTag:     60485,
+				Algorithm:  5,
+				DigestType: 1,
+				Digest:     []byte{0x2b, 0xb1, 0x83, 0xaf, 0x5f, 0x22, 0x58, 0x81, 0x79, 0xa5, 0x3b, 0x0a, 0x98, 0x63, 0x1f, 0xad, 0x1a, 0x29, 0x21, 0x18},
+			},
+		},
+		{
+			name:         "RRSIG record with times in seconds",
+			rtype:        RRSIG,
+			presentation: "A 13 2 300 1700000000 1690000000 12345 example.com. AQID",
+			want: &RDataRRSIG{
+				TypeCovered: A,
+				Algorithm:   13,
+				Labels:      2,
+				OriginalTTL: 300,
+				Expiration:  1700000000,
+				Inception:   1690000000,
+				KeyTag:      12345,
+				SignerName:  "example.com.",
+				Signature:   []byte{1, 2, 3},
+			},
+		},
+		{
+			name:         "NSEC record with unknown type",
+			rtype:        NSEC,
+			presentation: "host.example.com. A mx RRSIG TYPE1234",
+			want:         &RDataNSEC{NextDomainName: "host.example.com.", Types: []uint16{A, MX, RRSIG, 1234}},
+		},
+		{
+			name:         "NSEC record with invalid type",
+			rtype:        NSEC,
+			presentation: "host.example.com. A NOTATYPE",
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "NSEC3PARAM record without salt",
+			rtype:        NSEC3PARAM,
+			presentation: "1 0 0 -",
+			want:         &RDataNSEC3PARAM{HashAlgorithm: 1, Flags: 0, Iterations: 0},
+		},
+		{
+			name:         "CERT record with type mnemonic",
+			rtype:        CERT,
+			presentation: "pgp 0 0 AQID",
+			want:         &RDataCERT{Type: CERTTypePGP, Certificate: []byte{1, 2, 3}},
+		},
+		{
+			name:         "LOC record with defaults",
+			rtype:        LOC,
+			presentation: "52 22 N 4 53 E -2m",
+			want:         mustNewRDataLOC(t, 52+22.0/60, 4+53.0/60, -2, 1, 10000, 10),
+		},
+		{
+			name:         "LOC record without hemisphere",
+			rtype:        LOC,
+			presentation: "52 22 23 4 53 32 -2m",
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "Generic format",
+			rtype:        A,
+			presentation: `\# 4 C0000201`,
+			want:         &RDataA{IP: netip.MustParseAddr("192.0.2.1")},
+		},
+		{
+			name:         "Type without presentation format parser",
+			rtype:        731,
+			presentation: "0A000001",
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "SVCB record: unknown key name",
+			rtype:        SVCB,
+			presentation: "1 . foo=bar",
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "SVCB record: port without value",
+			rtype:        SVCB,
+			presentation: "1 . port",
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "SVCB record: IPv6 address in ipv4hint",
+			rtype:        SVCB,
+			presentation: "1 . ipv4hint=192.0.2.1,2001:db8::1",
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "SVCB record: duplicate key",
+			rtype:        HTTPS,
+			presentation: "1 . port=443 port=8443",
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "Label longer than 63 bytes",
+			rtype:        CNAME,
+			presentation: strings.Repeat("a", 64) + ".example.com.",
+			wantError:    ErrInvalidDomainName,
+		},
+		{
+			name:         "Name longer than 255 bytes",
+			rtype:        NS,
+			presentation: strings.Repeat("abcdefg.", 32) + "example.",
+			wantError:    ErrInvalidDomainName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRData(tt.rtype, tt.presentation)
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("ParseRData() error = %v, want error = %v\n", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRData() unexpected error = %v\n", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRData() got = %+v, want = %+v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func mustNewRDataLOC(t *testing.T, latitude float64, longitude float64, altitude float64, size float64, horizontalPrecision float64, verticalPrecision float64) *RDataLOC {
+	t.Helper()
+	rdata, err := NewRDataLOC(latitude, longitude, altitude, size, horizontalPrecision, verticalPrecision)
+	if err != nil {
+		t.Fatalf("NewRDataLOC() unexpected error = %v\n", err)
+	}
+	return rdata
+}
+
+func TestParseRDataRoundTrip(t *testing.T) {
+	tests := []struct {
+		rtype        uint16
+		presentation string
+	}{
+		{NS, "ns1.example.com."},
+		{PTR, "host.example.com."},
+		{DNAME, "example.net."},
+		{TXT, `"v=spf1 include:_spf.example.com" "-all"`},
+		{HINFO, `"INTEL-386" "Windows"`},
+		{NAPTR, `100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`},
+		{SRV, "10 60 5060 sip.example.com."},
+		{URI, `10 1 "ftp://ftp1.example.com/public"`},
+		{RRSIG, "SOA 8 2 3600 20240201000000 20240101000000 12345 example.com. AQIDBA=="},
+		{DNSKEY, "257 3 13 AQIDBA=="},
+		{CDS, "0 0 0 00"},
+		{TLSA, "3 1 1 0123456789ABCDEF"},
+		{SMIMEA, "3 0 0 DEADBEEF"},
+		{NSEC3, "1 1 12 AABBCCDD 2T7B4G4VSA5SMI47K61MV5BV1A22BOJR NS SOA RRSIG DNSKEY NSEC3PARAM"},
+		{NSEC3PARAM, "1 0 10 AABBCCDD"},
+		{CSYNC, "66 3 A NS AAAA"},
+		{CERT, "PKIX 12345 8 AQID"},
+		{OPENPGPKEY, "AQIDBA=="},
+		{LOC, "42 21 54.000 N 71 06 18.000 W -24.00m 30m 10000m 10m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(DNSType(tt.rtype).String(), func(t *testing.T) {
+			rdata, err := ParseRData(tt.rtype, tt.presentation)
+			if err != nil {
+				t.Fatalf("ParseRData() unexpected error = %v\n", err)
+			}
+			if got := rdata.String(); got != tt.presentation {
+				t.Errorf("String() got = %s, want = %s\n", got, tt.presentation)
+			}
+		})
+	}
+}
+
+func TestEncodeRData(t *testing.T) {
+	tests := []struct {
+		name      string
+		rdata     RData
+		want      []byte
+		wantError error
+	}{
+		{
+			name:  "A record",
+			rdata: &RDataA{IP: netip.MustParseAddr("192.0.2.1")},
+			want:  []byte{192, 0, 2, 1},
+		},
+		{
+			name:  "MX record",
+			rdata: &RDataMX{Preference: 10, Exchange: "mail.example."},
+			want:  []byte{0, 10, 4, 'm', 'a', 'i', 'l', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 0},
+		},
+		{
+			name:  "TXT record",
+			rdata: &RDataTXT{Text: []string{"ab", ""}},
+			want:  []byte{2, 'a', 'b', 0},
+		},
+		{
+			name:      "IPv6 address in A record",
+			rdata:     &RDataA{IP: netip.MustParseAddr("2001:db8::1")},
+			wantError: ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeRData(tt.rdata)
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("EncodeRData() error = %v, want error = %v\n", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EncodeRData() unexpected error = %v\n", err)
+			}
+
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("EncodeRData() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRR(t *testing.T) {
+	tests := []struct {
+		name         string
+		presentation string
+		want         ResourceRecord
+		wantError    error
+	}{
+		{
+			name:         "MX record",
+			presentation: "example.com. 300 IN MX 10 mail.example.com.",
+			want:         ResourceRecord{Name: "example.com.", RType: MX, RClass: IN, TTL: 300, RDLength: 20, RData: &RDataMX{Preference: 10, Exchange: "mail.example.com."}},
+		},
+		{
+			name:         "Class before TTL, tabs and lowercase mnemonics",
+			presentation: "www.example.com\tin\t60\ta\t192.0.2.1",
+			want:         ResourceRecord{Name: "www.example.com.", RType: A, RClass: IN, TTL: 60, RDLength: 4, RData: &RDataA{IP: netip.MustParseAddr("192.0.2.1")}},
+		},
+		{
+			name:         "Default TTL and class",
+			presentation: `example.com. TXT "hello world" ; comment`,
+			want:         ResourceRecord{Name: "example.com.", RType: TXT, RClass: IN, TTL: 3600, RDLength: 12, RData: &RDataTXT{Text: []string{"hello world"}}},
+		},
+		{
+			name:         "Multi-line SOA record",
+			presentation: "example.com. 3600 IN SOA ns1.example.com. hostmaster.example.com. (\n\t1 ; serial\n\t2 3 4 5 )",
+			want: ResourceRecord{Name: "example.com.", RType: SOA, RClass: IN, TTL: 3600, RDLength: 61, RData: &RDataSOA{
+				MName: "ns1.example.com.", RName: "hostmaster.example.com.", Serial: 1, Refresh: 2, Retry: 3, Expire: 4, Minimum: 5,
+			}},
+		},
+		{
+			name:         "Unknown type and class",
+			presentation: `example.com. CLASS32 TYPE731 \# 2 DEAD`,
+			want:         ResourceRecord{Name: "example.com.", RType: 731, RClass: 32, TTL: 3600, RDLength: 2, RData: &RDataUnknown{Data: []byte{0xde, 0xad}}},
+		},
+		{
+			name:         "HTTPS record",
+			presentation: `example.com. 300 IN HTTPS 1 . alpn=h2,h3 port=8443 dohpath="/dns-query{?dns}"`,
+			want: ResourceRecord{Name: "example.com.", RType: HTTPS, RClass: IN, TTL: 300, RDLength: 39, RData: &RDataSVCB{Priority: 1, Target: ".", Params: []SVCBParam{
+				&SVCBParamALPN{Protocols: []string{"h2", "h3"}}, &SVCBParamPort{Port: 8443}, &SVCBParamDoHPath{Template: "/dns-query{?dns}"},
+			}}},
+		},
+		{
+			name:         "No owner name",
+			presentation: " 300 IN A 192.0.2.1",
+			wantError:    ErrInvalidResourceRecord,
+		},
+		{
+			name:         "No type",
+			presentation: "example.com. 300 IN",
+			wantError:    ErrInvalidResourceRecord,
+		},
+		{
+			name:         "Two TTLs",
+			presentation: "example.com. 300 300 A 192.0.2.1",
+			wantError:    ErrInvalidResourceRecord,
+		},
+		{
+			name:         "Invalid RData",
+			presentation: "example.com. 300 IN A 2001:db8::1",
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "Owner name label longer than 63 bytes",
+			presentation: strings.Repeat("b", 70) + ".example. 300 IN CNAME x.",
+			wantError:    ErrInvalidDomainName,
+		},
+		{
+			name:         "Owner name longer than 255 bytes",
+			presentation: strings.Repeat("abcdefg.", 32) + "example. 300 IN CNAME x.",
+			wantError:    ErrInvalidDomainName,
+		},
+		{
+			name:         "RData name label longer than 63 bytes",
+			presentation: "example.com. 300 IN MX 10 " + strings.Repeat("m", 64) + ".example.com.",
+			wantError:    ErrInvalidDomainName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRR(tt.presentation)
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("NewRR() error = %v, want error = %v\n", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewRR() unexpected error = %v\n", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewRR() got = %+v, want = %+v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRRUnknownRoundTrip(t *testing.T) {
+	presentation := "example.com.\t300\tCLASS42\tTYPE731\t\\# 3 DEADFF"
+	record, err := NewRR(presentation)
+	if err != nil {
+		t.Fatalf("NewRR() unexpected error = %v\n", err)
+	}
+	if got := record.String(); got != presentation {
+		t.Fatalf("String() got = %q, want = %q\n", got, presentation)
+	}
+
+	got, err := NewRR(record.String())
+	if err != nil {
+		t.Fatalf("NewRR() unexpected error = %v\n", err)
+	}
+	if !reflect.DeepEqual(got, record) {
+		t.Errorf("NewRR() got = %+v, want = %+v\n", got, record)
+	}
+}
@@ -40,38 +40,71 @@ func PrintBasicQueryInfo(domainName string, questionType uint16) {
 //   - message: A pointer to the Message structure to print.
 func PrintMessage(message Message) {
 	fmt.Println(";; Got answer:")
+	fmt.Println(message.String())
+}
 
-	printHeader(message.Header)
-
-	if message.Header.QuestionCount > 0 {
-		printQuestions(message.Questions)
+// String returns the details of the message in the format of dig: the header,
+// the EDNS parameters as an OPT pseudosection, then each section that is not empty,
+// with its questions and records prefixed with ";".
+func (message Message) String() string {
+	var builder strings.Builder
+	header := message.Header
+	header.Flags.ResponseCode = message.ResponseCode()
+	builder.WriteString(header.String())
+
+	if message.EDNS != nil {
+		builder.WriteString("\n\n;; OPT PSEUDOSECTION:")
+		fmt.Fprintf(&builder, "\n; %s", message.EDNS.String())
+		for _, option := range message.EDNS.Options {
+			fmt.Fprintf(&builder, "\n; %s", option.String())
+		}
 	}
 
-	if message.Header.AnswerRRCount > 0 {
-		printResourceRecord(message.Answers, "Answer")
-	}
+	titles := getSectionTitles(message.Header.Flags.Opcode)
 
-	if message.Header.NameserverRRCount > 0 {
-		printResourceRecord(message.NameServers, "Authority")
+	if len(message.Questions) > 0 {
+		fmt.Fprintf(&builder, "\n\n;; %s SECTION:", strings.ToUpper(titles[0]))
+		for _, question := range message.Questions {
+			fmt.Fprintf(&builder, "\n;%s", question.String())
+		}
 	}
 
-	if message.Header.AdditionalRRCount > 0 {
-		printResourceRecord(message.Additionals, "Additional")
+	for i, records := range [][]ResourceRecord{message.Answers, message.NameServers, message.Additionals} {
+		if len(records) == 0 {
+			continue
+		}
+		fmt.Fprintf(&builder, "\n\n;; %s SECTION:", strings.ToUpper(titles[i+1]))
+		for _, record := range records {
+			fmt.Fprintf(&builder, "\n;%s", record.String())
+		}
 	}
+
+	return builder.String()
 }
 
-func printHeader(header Header) {
+// getSectionTitles returns the names of the four message sections,
+// which are renamed in dynamic update messages [RFC2136].
+func getSectionTitles(opcode uint16) [4]string {
+	if opcode == UPDATE {
+		return [4]string{"Zone", "Prerequisite", "Update", "Additional"}
+	}
+	return [4]string{"Question", "Answer", "Authority", "Additional"}
+}
 
-	fmt.Printf(";; ->>HEADER<<- ")
-	fmt.Printf("opcode: %s, ", DNSOpCode(header.Flags.Opcode))
-	fmt.Printf("status: %s, ", DNSRCode(header.Flags.ResponseCode))
-	fmt.Printf("id: %d\n", header.Id)
+// String returns the opcode, status, ID, flags and section counts of the header on two lines, as dig prints them.
+func (header Header) String() string {
+	counts := [4]string{"QUERY", "ANSWER", "AUTHORITY", "ADDITIONAL"}
+	if header.Flags.Opcode == UPDATE {
+		counts = [4]string{"ZONE", "PREREQ", "UPDATE", "ADDITIONAL"}
+	}
 
-	fmt.Printf(";; flags: %s; ", getFlagString(header.Flags))
-	fmt.Printf("QUERY: %d; ", header.QuestionCount)
-	fmt.Printf("ANSWER: %d; ", header.AnswerRRCount)
-	fmt.Printf("AUTHORITY: %d; ", header.NameserverRRCount)
-	fmt.Printf("ADDITIONAL: %d\n", header.AdditionalRRCount)
+	return fmt.Sprintf(";; ->>HEADER<<- opcode: %s, status: %s, id: %d\n;; flags: %s; %s: %d; %s: %d; %s: %d; %s: %d",
+		DNSOpCode(header.Flags.Opcode), DNSRCode(header.Flags.ResponseCode), header.Id,
+		getFlagString(header.Flags),
+		counts[0], header.QuestionCount,
+		counts[1], header.AnswerRRCount,
+		counts[2], header.NameserverRRCount,
+		counts[3], header.AdditionalRRCount)
 }
 
 func getFlagString(flags Flags) string {
@@ -105,22 +138,89 @@ func getFlagString(flags Flags) string {
 	return strings.Join(flagStrings, " ")
 }
 
-func printQuestions(questions []Question) {
-	fmt.Printf("\n;; QUESTION SECTION:\n")
-	for _, question := range questions {
-		fmt.Printf(";%s\t\t", question.Name)
-		fmt.Printf("%s\t", DNSClass(question.QClass).String())
-		fmt.Printf("%s\n", DNSType(question.QType).String())
+// String returns the question's name, class and type separated by tabs, ex. "example.com.\t\tIN\tA".
+func (question Question) String() string {
+	return fmt.Sprintf("%s\t\t%s\t%s", question.Name, DNSClass(question.QClass), DNSType(question.QType))
+}
+
+// String returns the record in its presentation format, with its fields separated by tabs,
+// ex. "example.com.\t300\tIN\tA\t192.0.2.1".
+func (record ResourceRecord) String() string {
+	rdata := ""
+	if record.RData != nil {
+		rdata = record.RData.String()
+	}
+	return fmt.Sprintf("%s\t%d\t%s\t%s\t%s", record.Name, record.TTL, DNSClass(record.RClass), DNSType(record.RType), rdata)
+}
+
+// PrintHomographWarnings prints a warning for every punycode label in the message
+// that mixes scripts or is confusable with a Latin label.
+//
+// Parameters:
+//   - message: The Message structure whose domain names should be checked.
+func PrintHomographWarnings(message Message) {
+	for _, domainName := range getMessageDomainNames(message) {
+		for _, warning := range CheckHomographs(domainName) {
+			fmt.Printf(";; WARNING: possible IDN homograph in %s: %s\n", domainName, warning.String())
+		}
+	}
+}
+
+// PrintDenialProof prints whether a negative response proves that the queried name or type
+// does not exist with NSEC or NSEC3 records. Nothing is printed for other responses.
+//
+// Parameters:
+//   - message: The Message structure of the response.
+func PrintDenialProof(message Message) {
+	responseCode := message.Header.Flags.ResponseCode
+	if len(message.Questions) != 1 || (responseCode != NXDOMAIN && responseCode != NOERROR) {
+		return
+	}
+	name := getCNAMETarget(message.Questions[0].Name, message.Answers)
+	if responseCode == NOERROR && hasRecord(message.Answers, name, message.Questions[0].QType) {
+		return
 	}
+
+	proof, err := ProveDenial(message)
+	if err != nil {
+		fmt.Printf(";; WARNING: %s\n", err.Error())
+		return
+	}
+	fmt.Printf(";; Denial of existence: %s\n", proof.String())
 }
 
-func printResourceRecord(records []ResourceRecord, title string) {
-	fmt.Printf("\n;; %s SECTION:\n", strings.ToUpper(title))
-	for _, record := range records {
-		fmt.Printf(";%s\t", record.Name)
-		fmt.Printf("%d\t", record.TTL)
-		fmt.Printf("%s\t", DNSClass(record.RClass).String())
-		fmt.Printf("%s\t", DNSType(record.RType).String())
-		fmt.Printf("%s\n", record.RData.String())
+func getMessageDomainNames(message Message) []string {
+	seen := map[string]bool{}
+	domainNames := []string{}
+
+	add := func(domainName string) {
+		if !seen[domainName] {
+			seen[domainName] = true
+			domainNames = append(domainNames, domainName)
+		}
+	}
+
+	for _, question := range message.Questions {
+		add(question.Name)
+	}
+	for _, section := range [][]ResourceRecord{message.Answers, message.NameServers, message.Additionals} {
+		for _, record := range section {
+			add(record.Name)
+			switch rdata := record.RData.(type) {
+			case *RDataCNAME:
+				add(rdata.DomainName)
+			case *RDataNS:
+				add(rdata.DomainName)
+			case *RDataPTR:
+				add(rdata.DomainName)
+			case *RDataDNAME:
+				add(rdata.Target)
+			case *RDataMX:
+				add(rdata.Exchange)
+			case *RDataSRV:
+				add(rdata.Target)
+			}
+		}
 	}
+	return domainNames
 }
@@ -1,6 +1,7 @@
 package dns
 
 import (
+	"net/netip"
 	"testing"
 )
 
@@ -92,3 +93,95 @@ func TestGetFlagString(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageString(t *testing.T) {
+	tests := []struct {
+		name string
+		data Message
+		want string
+	}{
+		{
+			name: "Response with EDNS",
+			data: Message{
+				Header: Header{
+					Id:                1234,
+					Flags:             Flags{Response: true, RecursionDesired: true, RecursionAvailable: true},
+					QuestionCount:     1,
+					AnswerRRCount:     1,
+					AdditionalRRCount: 1,
+				},
+				Questions: []Question{{Name: "example.com.", QType: A, QClass: IN}},
+				Answers: []ResourceRecord{
+					{Name: "example.com.", RType: A, RClass: IN, TTL: 300, RData: &RDataA{IP: netip.MustParseAddr("192.0.2.1")}},
+				},
+				EDNS: &EDNS{UDPSize: 1232},
+			},
+			want: ";; ->>HEADER<<- opcode: QUERY, status: NOERROR, id: 1234\n" +
+				";; flags: qr rd ra; QUERY: 1; ANSWER: 1; AUTHORITY: 0; ADDITIONAL: 1\n" +
+				"\n;; OPT PSEUDOSECTION:\n" +
+				"; " + (&EDNS{UDPSize: 1232}).String() + "\n" +
+				"\n;; QUESTION SECTION:\n" +
+				";example.com.\t\tIN\tA\n" +
+				"\n;; ANSWER SECTION:\n" +
+				";example.com.\t300\tIN\tA\t192.0.2.1",
+		},
+		{
+			name: "Notify",
+			data: *NewNotify("example.com.").WithID(2),
+			want: ";; ->>HEADER<<- opcode: NOTIFY, status: NOERROR, id: 2\n" +
+				";; flags: aa; QUERY: 1; ANSWER: 0; AUTHORITY: 0; ADDITIONAL: 0\n" +
+				"\n;; QUESTION SECTION:\n" +
+				";example.com.\t\tIN\tSOA",
+		},
+		{
+			name: "Reserved opcode",
+			data: Message{Header: Header{Id: 3, Flags: Flags{Opcode: 7, ResponseCode: NOTIMP}}},
+			want: ";; ->>HEADER<<- opcode: RESERVED7, status: NOTIMP, id: 3\n" +
+				";; flags: ; QUERY: 0; ANSWER: 0; AUTHORITY: 0; ADDITIONAL: 0",
+		},
+		{
+			name: "Extended response code",
+			data: Message{Header: Header{Id: 4, Flags: Flags{Response: true, ResponseCode: 7}, AdditionalRRCount: 1}, EDNS: &EDNS{UDPSize: 1232, ExtendedRCode: 1}},
+			want: ";; ->>HEADER<<- opcode: QUERY, status: BADCOOKIE, id: 4\n" +
+				";; flags: qr; QUERY: 0; ANSWER: 0; AUTHORITY: 0; ADDITIONAL: 1\n" +
+				"\n;; OPT PSEUDOSECTION:\n" +
+				"; " + (&EDNS{UDPSize: 1232, ExtendedRCode: 1}).String(),
+		},
+		{
+			name: "Multiple questions",
+			data: *(&Message{Header: Header{Id: 5}}).AddQuestion(
+				Question{Name: "example.com.", QType: A, QClass: IN},
+				Question{Name: "example.com.", QType: AAAA, QClass: IN},
+			),
+			want: ";; ->>HEADER<<- opcode: QUERY, status: NOERROR, id: 5\n" +
+				";; flags: ; QUERY: 2; ANSWER: 0; AUTHORITY: 0; ADDITIONAL: 0\n" +
+				"\n;; QUESTION SECTION:\n" +
+				";example.com.\t\tIN\tA\n" +
+				";example.com.\t\tIN\tAAAA",
+		},
+		{
+			name: "Update",
+			data: Message{
+				Header:      Header{Id: 1, Flags: Flags{Opcode: UPDATE}, QuestionCount: 1, NameserverRRCount: 1},
+				Questions:   []Question{{Name: "example.com.", QType: SOA, QClass: IN}},
+				NameServers: []ResourceRecord{{Name: "www.example.com.", RType: A, RClass: ANY}},
+			},
+			want: ";; ->>HEADER<<- opcode: UPDATE, status: NOERROR, id: 1\n" +
+				";; flags: ; ZONE: 1; PREREQ: 0; UPDATE: 1; ADDITIONAL: 0\n" +
+				"\n;; ZONE SECTION:\n" +
+				";example.com.\t\tIN\tSOA\n" +
+				"\n;; UPDATE SECTION:\n" +
+				";www.example.com.\t0\t*\tA\t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.data.String()
+
+			if got != tt.want {
+				t.Errorf("String() got = %q, want = %q\n", got, tt.want)
+			}
+		})
+	}
+}
@@ -7,21 +7,61 @@ import (
 	"log"
 )
 
+// CreateDNSQuery builds and encodes a recursive query for the given domain name or IP address.
+//
+// Parameters:
+//   - domainOrIP: The domain name to query, or the IP address for a reverse query.
+//   - questionType: The DNS record type to query.
+//   - reverseQuery: Whether to query the PTR record of the IP address in domainOrIP.
+//
+// Returns:
+//   - []byte: The encoded DNS query.
+//   - error: If the IP address for a reverse query is invalid.
 func CreateDNSQuery(domainOrIP string, questionType uint16, reverseQuery bool) (query []byte, err error) {
+	message, err := CreateQueryMessage(domainOrIP, questionType, reverseQuery)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	query, err = EncodeMessage(message)
+	if err != nil {
+		log.Fatalf("Failed to encode DNS message: %v\n", err)
+	}
+
+	return query, nil
+}
+
+// CreateQueryMessage builds a recursive query Message with a random ID
+// for the given domain name or IP address. Unicode domain names are converted
+// to their ASCII form with DefaultIDNAPolicy.
+//
+// Parameters:
+//   - domainOrIP: The domain name to query, or the IP address for a reverse query.
+//   - questionType: The DNS record type to query.
+//   - reverseQuery: Whether to query the PTR record of the IP address in domainOrIP.
+//
+// Returns:
+//   - Message: The query message, ready to be encoded.
+//   - error: If the IP address for a reverse query or the Unicode domain name is invalid.
+func CreateQueryMessage(domainOrIP string, questionType uint16, reverseQuery bool) (message Message, err error) {
 	if reverseQuery {
 		ip := domainOrIP
 		questionType = PTR // Question type must be PTR for reverse query
 		domainOrIP, err = GetReverseDNSDomain(ip)
 		if err != nil {
-			return []byte{}, fmt.Errorf("get Reverse DNS Domain from IP address: %w", err)
+			return Message{}, fmt.Errorf("get Reverse DNS Domain from IP address: %w", err)
+		}
+	} else {
+		domainOrIP, err = getASCIIName(domainOrIP)
+		if err != nil {
+			return Message{}, err
 		}
 	}
 
-	message := Message{
+	message = Message{
 		Header: Header{
-			Id:            generateRandomID(),
-			Flags:         Flags{RecursionDesired: true},
-			QuestionCount: 1,
+			Id:    NewID(),
+			Flags: Flags{RecursionDesired: true},
 		},
 		Questions: []Question{
 			{
@@ -31,16 +71,14 @@ func CreateDNSQuery(domainOrIP string, questionType uint16, reverseQuery bool) (
 			},
 		},
 	}
+	message.UpdateHeaderCounts()
 
-	query, err = EncodeMessage(message)
-	if err != nil {
-		log.Fatalf("Failed to encode DNS message: %v\n", err)
-	}
-
-	return query, nil
+	return message, nil
 }
 
-func generateRandomID() uint16 {
+// NewID returns a cryptographically random message ID.
+// Unpredictable IDs make it harder for an off-path attacker to spoof responses.
+func NewID() uint16 {
 	bytes := [2]byte{}
 
 	_, err := io.ReadFull(rand.Reader, bytes[:])
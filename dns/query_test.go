@@ -88,3 +88,40 @@ func TestCreateDNSQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateQueryMessage(t *testing.T) {
+	got, err := CreateQueryMessage("1.1.1.1", A, true)
+	if err != nil {
+		t.Fatalf("CreateQueryMessage() unexpected error = %v\n", err)
+	}
+
+	want := Question{
+		Name:   "1.1.1.1.in-addr.arpa.",
+		QType:  PTR,
+		QClass: IN,
+	}
+
+	if got.Header.QuestionCount != 1 || len(got.Questions) != 1 {
+		t.Fatalf("CreateQueryMessage() question count got = %d, questions = %d, want = 1\n", got.Header.QuestionCount, len(got.Questions))
+	}
+	if got.Questions[0] != want {
+		t.Errorf("CreateQueryMessage() question got = %+v, want = %+v\n", got.Questions[0], want)
+	}
+	if !got.Header.Flags.RecursionDesired {
+		t.Errorf("CreateQueryMessage() recursion desired flag not set\n")
+	}
+}
+
+func TestCreateQueryMessageUnicodeName(t *testing.T) {
+	got, err := CreateQueryMessage("bücher.example.", A, false)
+	if err != nil {
+		t.Fatalf("CreateQueryMessage() unexpected error = %v\n", err)
+	}
+	if got.Questions[0].Name != "xn--bcher-kva.example." {
+		t.Errorf("CreateQueryMessage() name got = %s, want = xn--bcher-kva.example.\n", got.Questions[0].Name)
+	}
+
+	if _, err = CreateQueryMessage("aא.example.", A, false); !errors.Is(err, ErrInvalidDomainName) {
+		t.Errorf("CreateQueryMessage() error = %v, want error = %v\n", err, ErrInvalidDomainName)
+	}
+}
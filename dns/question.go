@@ -1,5 +1,7 @@
 package dns
 
+import "fmt"
+
 // Question section format
 // The question section is used to carry the "question" in most queries,
 // i.e., the parameters that define what is being asked.  The section
@@ -28,7 +30,7 @@ func (reader *dnsReader) readQuestions(count uint16) (questions []Question, err
 	for i := 0; i < int(count); i++ {
 		question, err := reader.readQuestion()
 		if err != nil {
-			return nil, err
+			return questions, fmt.Errorf("question %d of %d: %w", i+1, count, err)
 		}
 		questions = append(questions, question)
 	}
@@ -38,7 +40,7 @@ func (reader *dnsReader) readQuestions(count uint16) (questions []Question, err
 func (reader *dnsReader) readQuestion() (question Question, err error) {
 	name, err := reader.readDomainName()
 	if err != nil {
-		return Question{}, invalidQuestionError(err.Error())
+		return Question{}, fmt.Errorf("%w: %w", ErrInvalidQuestion, err)
 	}
 
 	if len(reader.data) < reader.offset+4 {
@@ -54,14 +56,20 @@ func (reader *dnsReader) readQuestion() (question Question, err error) {
 	return question, nil
 }
 
-func (writer *dnsWriter) writeQuestions(questions []Question) {
+func (writer *dnsWriter) writeQuestions(questions []Question) error {
 	for _, question := range questions {
-		writer.writeQuestion(question)
+		if err := writer.writeQuestion(question); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (writer *dnsWriter) writeQuestion(question Question) {
-	writer.writeDomainName(question.Name)
+func (writer *dnsWriter) writeQuestion(question Question) error {
+	if err := writer.writeCompressedDomainName(question.Name); err != nil {
+		return err
+	}
 	writer.writeUint16(question.QType)
 	writer.writeUint16(question.QClass)
+	return nil
 }
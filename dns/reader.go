@@ -3,8 +3,10 @@ package dns
 import "fmt"
 
 type dnsReader struct {
-	data   []byte
-	offset int
+	data     []byte
+	offset   int
+	mode     DecodeMode
+	warnings []error // Problems found in lenient mode
 }
 
 // readuint16:
@@ -45,8 +47,8 @@ func (reader *dnsReader) readUint32() (value uint32) {
 }
 
 func (reader *dnsReader) readUntil(length int) (readBytes []byte, err error) {
-	if reader.offset+length > len(reader.data) {
-		return nil, fmt.Errorf("invalid read length: %w", err)
+	if length < 0 || reader.offset+length > len(reader.data) {
+		return nil, fmt.Errorf("invalid read length: %d bytes at offset %d", length, reader.offset)
 	}
 
 	readBytes = reader.data[reader.offset : reader.offset+length]
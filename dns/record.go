@@ -1,5 +1,12 @@
 package dns
 
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // Resource record format
 
 // The answer, authority, and additional sections all share the same
@@ -41,7 +48,7 @@ func (reader *dnsReader) readResourceRecords(count uint16) (records []ResourceRe
 	for i := 0; i < int(count); i++ {
 		record, err := reader.readResourceRecord()
 		if err != nil {
-			return nil, err
+			return records, fmt.Errorf("record %d of %d: %w", i+1, count, err)
 		}
 		records = append(records, record)
 	}
@@ -51,7 +58,7 @@ func (reader *dnsReader) readResourceRecords(count uint16) (records []ResourceRe
 func (reader *dnsReader) readResourceRecord() (record ResourceRecord, err error) {
 	name, err := reader.readDomainName()
 	if err != nil {
-		return ResourceRecord{}, invalidResourceRecordError(err.Error())
+		return ResourceRecord{}, fmt.Errorf("%w: %w", ErrInvalidResourceRecord, err)
 	}
 
 	if len(reader.data) < reader.offset+10 {
@@ -71,9 +78,27 @@ func (reader *dnsReader) readResourceRecord() (record ResourceRecord, err error)
 		return ResourceRecord{}, invalidResourceRecordError(err.Error())
 	}
 
-	err = rdata.ReadRecordData(reader, rdlength)
-	if err != nil {
-		return ResourceRecord{}, invalidResourceRecordError(err.Error())
+	start := reader.offset
+	end := start + int(rdlength)
+	if rdlength == 0 && rtype != OPT && (rclass == ANY || rclass == NONE) {
+		// Dynamic update records matching whole names or RRsets have no RData [RFC2136]
+		rdata = &RDataUnknown{}
+	} else if err = rdata.ReadRecordData(reader, rdlength); err != nil {
+		err = fmt.Errorf("%w: %s %s: %w", ErrInvalidResourceRecord, name, DNSType(rtype), err)
+		if reader.mode != DecodeLenient {
+			return ResourceRecord{}, err
+		}
+		// Keep the record with its RData as is
+		reader.warnings = append(reader.warnings, err)
+		rdata = &RDataUnknown{Data: append([]byte{}, reader.data[start:end]...)}
+		reader.offset = end
+	} else if reader.offset != end && reader.mode != DecodeDefault {
+		err = invalidResourceRecordError(fmt.Sprintf("%s %s: RData of %d bytes, RDLength %d", name, DNSType(rtype), reader.offset-start, rdlength))
+		if reader.mode == DecodeStrict {
+			return ResourceRecord{}, err
+		}
+		reader.warnings = append(reader.warnings, err)
+		reader.offset = end
 	}
 
 	record = ResourceRecord{
@@ -100,6 +125,8 @@ func getRDataStruct(rtype uint16) (RData, error) {
 		rdata = &RDataCNAME{}
 	case PTR:
 		rdata = &RDataPTR{}
+	case DNAME:
+		rdata = &RDataDNAME{}
 	case NS:
 		rdata = &RDataNS{}
 	case TXT:
@@ -108,23 +135,141 @@ func getRDataStruct(rtype uint16) (RData, error) {
 		rdata = &RDataMX{}
 	case SOA:
 		rdata = &RDataSOA{}
+	case SRV:
+		rdata = &RDataSRV{}
+	case NAPTR:
+		rdata = &RDataNAPTR{}
+	case TLSA, SMIMEA:
+		// SMIMEA has the same RDATA format as TLSA [RFC8162]
+		rdata = &RDataTLSA{}
+	case LOC:
+		rdata = &RDataLOC{}
+	case HINFO:
+		rdata = &RDataHINFO{}
+	case URI:
+		rdata = &RDataURI{}
+	case CERT:
+		rdata = &RDataCERT{}
+	case OPENPGPKEY:
+		rdata = &RDataOPENPGPKEY{}
+	case OPT:
+		rdata = &RDataOPT{}
+	case RRSIG, SIG:
+		// SIG has the same RDATA format as RRSIG [RFC4034]
+		rdata = &RDataRRSIG{}
+	case DNSKEY, KEY, CDNSKEY:
+		rdata = &RDataDNSKEY{}
+	case DS, CDS:
+		rdata = &RDataDS{}
+	case NSEC:
+		rdata = &RDataNSEC{}
+	case NSEC3:
+		rdata = &RDataNSEC3{}
+	case NSEC3PARAM:
+		rdata = &RDataNSEC3PARAM{}
+	case CSYNC:
+		rdata = &RDataCSYNC{}
+	case SVCB, HTTPS:
+		// HTTPS has the same RDATA format as SVCB [RFC9460]
+		rdata = &RDataSVCB{}
 	default:
 		rdata = &RDataUnknown{}
 	}
 	return rdata, nil
 }
 
-func (writer *dnsWriter) writeResourceRecords(resourceRecords []ResourceRecord) {
+// ParseGenericRData reads RData in the generic presentation format of unknown types [RFC3597]:
+// "\#", the length of the RData in bytes, and the RData in hexadecimal, ex. "\# 4 0A000001".
+// The hexadecimal may be split by spaces, and is omitted when the length is 0.
+//
+// Parameters:
+//   - rtype: The type of the record. The RData of known types is decoded into its typed struct.
+//   - presentation: The RData in generic presentation format.
+//
+// Returns:
+//   - RData: The decoded RData, RDataUnknown if the type is not known.
+//   - error: If the presentation format is invalid or the RData is not valid for the type.
+func ParseGenericRData(rtype uint16, presentation string) (RData, error) {
+	fields := strings.Fields(presentation)
+	if len(fields) < 2 || fields[0] != `\#` {
+		return nil, invalidRecordDataError(fmt.Sprintf("not in generic format: %q", presentation))
+	}
+
+	length, err := strconv.ParseUint(fields[1], 10, 16)
+	if err != nil {
+		return nil, invalidRecordDataError(fmt.Sprintf("invalid generic RData length: %s", fields[1]))
+	}
+	data, err := hex.DecodeString(strings.Join(fields[2:], ""))
+	if err != nil {
+		return nil, invalidRecordDataError(fmt.Sprintf("invalid generic RData: %s", err.Error()))
+	}
+	if len(data) != int(length) {
+		return nil, invalidRecordDataError(fmt.Sprintf("generic RData length %d does not match data length %d", length, len(data)))
+	}
+	return decodeRData(rtype, data)
+}
+
+// decodeRData decodes uncompressed RData into the typed struct of its type, RDataUnknown if the type is not known.
+func decodeRData(rtype uint16, data []byte) (RData, error) {
+	if len(data) > 0xFFFF {
+		return nil, invalidRecordDataError(fmt.Sprintf("%s RData: too long: %d bytes", DNSType(rtype), len(data)))
+	}
+
+	rdata, err := getRDataStruct(rtype)
+	if err != nil {
+		return nil, err
+	}
+	reader := &dnsReader{data: data}
+	if err = rdata.ReadRecordData(reader, uint16(len(data))); err != nil {
+		return nil, err
+	}
+	if reader.offset != len(data) {
+		return nil, invalidRecordDataError(fmt.Sprintf("%s RData: trailing data", DNSType(rtype)))
+	}
+	return rdata, nil
+}
+
+// getRDLength returns the length of the encoded RData.
+func getRDLength(rdata RData) (uint16, error) {
+	data, err := EncodeRData(rdata)
+	if err != nil {
+		return 0, err
+	}
+	return uint16(len(data)), nil
+}
+
+func (writer *dnsWriter) writeResourceRecords(resourceRecords []ResourceRecord) error {
 	for _, record := range resourceRecords {
-		writer.writeResourceRecord(record)
+		if err := writer.writeResourceRecord(record); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (writer *dnsWriter) writeResourceRecord(record ResourceRecord) {
-	writer.writeDomainName(record.Name)
+func (writer *dnsWriter) writeResourceRecord(record ResourceRecord) error {
+	if err := writer.writeCompressedDomainName(record.Name); err != nil {
+		return err
+	}
 	writer.writeUint16(record.RType)
 	writer.writeUint16(record.RClass)
 	writer.writeUint32(record.TTL)
+
+	rdlengthOffset := writer.offset
 	writer.writeUint16(record.RDLength)
-	record.RData.WriteRecordData(writer)
+	if record.RData != nil {
+		if err := record.RData.WriteRecordData(writer); err != nil {
+			return fmt.Errorf("%s %s: %w", record.Name, DNSType(record.RType), err)
+		}
+	}
+
+	if !writer.keepRDLength {
+		rdlength := writer.offset - rdlengthOffset - 2
+		if rdlength > 0xFFFF {
+			return invalidRecordDataError(fmt.Sprintf("%s %s: too long: %d bytes", record.Name, DNSType(record.RType), rdlength))
+		}
+		writer.data[rdlengthOffset] = byte(rdlength >> 8)
+		writer.data[rdlengthOffset+1] = byte(rdlength & 0xFF)
+	}
+	return nil
 }
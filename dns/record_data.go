@@ -1,12 +1,21 @@
 package dns
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"math"
 	"net/netip"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// RData is the data of a resource record. Each record type is decoded into its own struct,
+// ex. *RDataMX for MX records or *RDataSOA for SOA records, whose exported fields hold the
+// decoded values: use a type switch or assertion on a record's RData to read them.
+// String returns the data in presentation format. Records of unknown types are decoded into *RDataUnknown.
 type RData interface {
 	String() string
 	WriteRecordData(writer *dnsWriter) error
@@ -100,22 +109,46 @@ func (rdata *RDataAAAA) ReadRecordData(reader *dnsReader, length uint16) (err er
 // CNAME:	A <domain-name> which specifies the canonical or primary name for the owner.  The owner name is an alias.
 
 type RDataCNAME struct {
-	domainName string
+	DomainName string
 }
 
 func (rdata *RDataCNAME) String() string {
-	return rdata.domainName
+	return rdata.DomainName
 }
 
 func (rdata *RDataCNAME) WriteRecordData(writer *dnsWriter) error {
-	writer.writeDomainName(rdata.domainName)
-	return nil
+	return writer.writeCompressedDomainName(rdata.DomainName)
 }
 
 func (rdata *RDataCNAME) ReadRecordData(reader *dnsReader, length uint16) (err error) {
-	rdata.domainName, err = reader.readDomainName()
+	rdata.DomainName, err = reader.readDomainName()
+	if err != nil {
+		return fmt.Errorf("%w: CNAME RData: %w", ErrInvalidRecordData, err)
+	}
+	return nil
+}
+
+// -------------- DNAME
+// DNAME RDATA format [RFC6672]
+// Target:	A <domain-name> which the names under the owner are mapped to, by replacing the owner with it.
+// The target is not compressed.
+
+type RDataDNAME struct {
+	Target string
+}
+
+func (rdata *RDataDNAME) String() string {
+	return rdata.Target
+}
+
+func (rdata *RDataDNAME) WriteRecordData(writer *dnsWriter) error {
+	return writer.writeDomainName(rdata.Target)
+}
+
+func (rdata *RDataDNAME) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	rdata.Target, err = reader.readDomainName()
 	if err != nil {
-		return invalidRecordDataError(fmt.Sprintf("CNAME RData: %s", err.Error()))
+		return fmt.Errorf("%w: DNAME RData: %w", ErrInvalidRecordData, err)
 	}
 	return nil
 }
@@ -125,22 +158,21 @@ func (rdata *RDataCNAME) ReadRecordData(reader *dnsReader, length uint16) (err e
 // PTRDNAME:	A <domain-name> which points to some location in the domain name space.
 
 type RDataPTR struct {
-	domainName string
+	DomainName string
 }
 
 func (rdata *RDataPTR) String() string {
-	return rdata.domainName
+	return rdata.DomainName
 }
 
 func (rdata *RDataPTR) WriteRecordData(writer *dnsWriter) error {
-	writer.writeDomainName(rdata.domainName)
-	return nil
+	return writer.writeCompressedDomainName(rdata.DomainName)
 }
 
 func (rdata *RDataPTR) ReadRecordData(reader *dnsReader, length uint16) (err error) {
-	rdata.domainName, err = reader.readDomainName()
+	rdata.DomainName, err = reader.readDomainName()
 	if err != nil {
-		return invalidRecordDataError(fmt.Sprintf("PTR RData: %s", err.Error()))
+		return fmt.Errorf("%w: PTR RData: %w", ErrInvalidRecordData, err)
 	}
 	return nil
 }
@@ -150,22 +182,21 @@ func (rdata *RDataPTR) ReadRecordData(reader *dnsReader, length uint16) (err err
 // NSDNAME:	A <domain-name> which specifies a host which should be authoritative for the specified class and domain.
 
 type RDataNS struct {
-	domainName string
+	DomainName string
 }
 
 func (rdata *RDataNS) String() string {
-	return rdata.domainName
+	return rdata.DomainName
 }
 
 func (rdata *RDataNS) WriteRecordData(writer *dnsWriter) error {
-	writer.writeDomainName(rdata.domainName)
-	return nil
+	return writer.writeCompressedDomainName(rdata.DomainName)
 }
 
 func (rdata *RDataNS) ReadRecordData(reader *dnsReader, length uint16) (err error) {
-	rdata.domainName, err = reader.readDomainName()
+	rdata.DomainName, err = reader.readDomainName()
 	if err != nil {
-		return invalidRecordDataError(fmt.Sprintf("NS RData: %s", err.Error()))
+		return fmt.Errorf("%w: NS RData: %w", ErrInvalidRecordData, err)
 	}
 	return nil
 }
@@ -175,20 +206,126 @@ func (rdata *RDataNS) ReadRecordData(reader *dnsReader, length uint16) (err erro
 // TXT-DATA:	One or more <character-string>s.
 
 type RDataTXT struct {
-	text string
+	Text []string
 }
 
+// String returns the character-strings of the record, quoted and separated by spaces,
+// ex. "v=spf1 include:_spf.example.com" "-all".
 func (rdata *RDataTXT) String() string {
-	return rdata.text
+	txt := make([]string, 0, len(rdata.Text))
+	for _, characterString := range rdata.Text {
+		txt = append(txt, getQuotedValue([]byte(characterString)))
+	}
+
+	return strings.Join(txt, " ")
 }
 
 func (rdata *RDataTXT) WriteRecordData(writer *dnsWriter) error {
-	writer.writeData([]byte(rdata.text))
+	if len(rdata.Text) == 0 {
+		return invalidRecordDataError("TXT RData: no character-string")
+	}
+	for _, characterString := range rdata.Text {
+		if err := writer.writeCharacterString(characterString); err != nil {
+			return invalidRecordDataError(fmt.Sprintf("TXT RData: %s", err.Error()))
+		}
+	}
 	return nil
 }
 
 func (rdata *RDataTXT) ReadRecordData(reader *dnsReader, length uint16) (err error) {
-	rdata.text = string(reader.data[reader.offset : reader.offset+int(length)])
+	end := reader.offset + int(length)
+	if length < 1 || end > len(reader.data) {
+		return invalidRecordDataError(fmt.Sprintf("TXT RData: invalid length: %d", length))
+	}
+
+	rdata.Text = []string{}
+	for reader.offset < end {
+		characterString, err := reader.readCharacterString(end)
+		if err != nil {
+			return invalidRecordDataError(fmt.Sprintf("TXT RData: %s", err.Error()))
+		}
+		rdata.Text = append(rdata.Text, characterString)
+	}
+	return nil
+}
+
+// readCharacterString reads a <character-string>: a one byte length followed by that many bytes, up to the end offset.
+func (reader *dnsReader) readCharacterString(end int) (string, error) {
+	data, err := reader.readLengthPrefixedData(end)
+	if err != nil {
+		return "", fmt.Errorf("character-string: %w", err)
+	}
+	return string(data), nil
+}
+
+func (writer *dnsWriter) writeCharacterString(characterString string) error {
+	if len(characterString) > 255 {
+		return fmt.Errorf("character-string too long: %d bytes", len(characterString))
+	}
+	writer.writeData(append([]byte{byte(len(characterString))}, characterString...))
+	return nil
+}
+
+// getQuotedValue returns a value in the presentation format of a quoted <character-string> [RFC1035]:
+// quotes and backslashes are escaped, and non-printable bytes are written as \DDD in decimal.
+func getQuotedValue(value []byte) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for _, b := range value {
+		switch {
+		case b == '"' || b == '\\':
+			quoted.WriteByte('\\')
+			quoted.WriteByte(b)
+		case b < 0x20 || b > 0x7E:
+			fmt.Fprintf(&quoted, "\\%03d", b)
+		default:
+			quoted.WriteByte(b)
+		}
+	}
+	quoted.WriteByte('"')
+	return quoted.String()
+}
+
+// -------------- HINFO
+// HINFO RDATA format [RFC1035]
+// CPU:	A <character-string> which specifies the CPU type.
+// OS:	A <character-string> which specifies the operating system type.
+// Resolvers may answer ANY queries with a synthesized HINFO record, ex. CPU "RFC8482" and an empty OS [RFC8482].
+
+type RDataHINFO struct {
+	CPU string
+	OS  string
+}
+
+func (rdata *RDataHINFO) String() string {
+	return getQuotedValue([]byte(rdata.CPU)) + " " + getQuotedValue([]byte(rdata.OS))
+}
+
+func (rdata *RDataHINFO) WriteRecordData(writer *dnsWriter) error {
+	for _, characterString := range []string{rdata.CPU, rdata.OS} {
+		if err := writer.writeCharacterString(characterString); err != nil {
+			return invalidRecordDataError(fmt.Sprintf("HINFO RData: %s", err.Error()))
+		}
+	}
+	return nil
+}
+
+func (rdata *RDataHINFO) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	end := reader.offset + int(length)
+	if length < 2 || end > len(reader.data) {
+		return invalidRecordDataError(fmt.Sprintf("HINFO RData: invalid length: %d", length))
+	}
+
+	for _, field := range []*string{&rdata.CPU, &rdata.OS} {
+		*field, err = reader.readCharacterString(end)
+		if err != nil {
+			return invalidRecordDataError(fmt.Sprintf("HINFO RData: %s", err.Error()))
+		}
+	}
+
+	if reader.offset != end {
+		return invalidRecordDataError("HINFO RData: OS does not match record length")
+	}
 	return nil
 }
 
@@ -198,29 +335,193 @@ func (rdata *RDataTXT) ReadRecordData(reader *dnsReader, length uint16) (err err
 // EXCHANGE:	A <domain-name> which specifies a host willing to act as a mail exchange for the owner name.
 
 type RDataMX struct {
-	preference uint16
-	domainName string
+	Preference uint16
+	Exchange   string
 }
 
 func (rdata *RDataMX) String() string {
-	return strconv.Itoa(int(rdata.preference)) + " " + rdata.domainName
+	return strconv.Itoa(int(rdata.Preference)) + " " + rdata.Exchange
 }
 
 func (rdata *RDataMX) WriteRecordData(writer *dnsWriter) error {
-	writer.writeUint16(rdata.preference)
-	writer.writeDomainName(rdata.domainName)
-	return nil
+	writer.writeUint16(rdata.Preference)
+	return writer.writeCompressedDomainName(rdata.Exchange)
 }
 
 func (rdata *RDataMX) ReadRecordData(reader *dnsReader, length uint16) (err error) {
-	rdata.preference = reader.readUint16()
-	rdata.domainName, err = reader.readDomainName()
+	if length < 3 || reader.offset+int(length) > len(reader.data) {
+		return invalidRecordDataError(fmt.Sprintf("MX RData: invalid length: %d", length))
+	}
+
+	rdata.Preference = reader.readUint16()
+	rdata.Exchange, err = reader.readDomainName()
 	if err != nil {
-		return invalidRecordDataError(fmt.Sprintf("MX RData: %s", err.Error()))
+		return fmt.Errorf("%w: MX RData: %w", ErrInvalidRecordData, err)
 	}
 	return nil
 }
 
+// -------------- NAPTR
+// NAPTR RDATA format [RFC3403]
+// ORDER:		A 16 bit integer: records with lower orders must be processed first.
+// PREFERENCE:	A 16 bit integer: the order in which to process records with the same order. Lower values are preferred.
+// FLAGS:		A <character-string> with the flags controlling how the fields are interpreted, ex. "S", "A", "U" or "P".
+// SERVICES:	A <character-string> with the services available down this path, ex. "SIP+D2U" or "E2U+sip".
+// REGEXP:		A <character-string> with a substitution expression applied to the original string.
+// REPLACEMENT:	The next <domain-name> to query, uncompressed. "." if the regular expression is used instead.
+
+type RDataNAPTR struct {
+	Order       uint16
+	Preference  uint16
+	Flags       string
+	Services    string
+	Regexp      string
+	Replacement string
+}
+
+func (rdata *RDataNAPTR) String() string {
+	naptr := []string{
+		strconv.Itoa(int(rdata.Order)),
+		strconv.Itoa(int(rdata.Preference)),
+		getQuotedValue([]byte(rdata.Flags)),
+		getQuotedValue([]byte(rdata.Services)),
+		getQuotedValue([]byte(rdata.Regexp)),
+		rdata.Replacement,
+	}
+
+	return strings.Join(naptr, " ")
+}
+
+func (rdata *RDataNAPTR) WriteRecordData(writer *dnsWriter) error {
+	writer.writeUint16(rdata.Order)
+	writer.writeUint16(rdata.Preference)
+	for _, characterString := range []string{rdata.Flags, rdata.Services, rdata.Regexp} {
+		if err := writer.writeCharacterString(characterString); err != nil {
+			return invalidRecordDataError(fmt.Sprintf("NAPTR RData: %s", err.Error()))
+		}
+	}
+	return writer.writeDomainName(rdata.Replacement)
+}
+
+func (rdata *RDataNAPTR) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	end := reader.offset + int(length)
+	if length < 8 || end > len(reader.data) {
+		return invalidRecordDataError(fmt.Sprintf("NAPTR RData: invalid length: %d", length))
+	}
+
+	rdata.Order = reader.readUint16()
+	rdata.Preference = reader.readUint16()
+	for _, field := range []*string{&rdata.Flags, &rdata.Services, &rdata.Regexp} {
+		*field, err = reader.readCharacterString(end)
+		if err != nil {
+			return invalidRecordDataError(fmt.Sprintf("NAPTR RData: %s", err.Error()))
+		}
+	}
+
+	rdata.Replacement, err = reader.readDomainName()
+	if err != nil {
+		return fmt.Errorf("%w: NAPTR RData: %w", ErrInvalidRecordData, err)
+	}
+	if reader.offset != end {
+		return invalidRecordDataError("NAPTR RData: replacement does not match record length")
+	}
+	return nil
+}
+
+// -------------- SRV
+// SRV RDATA format [RFC2782]
+// PRIORITY:	A 16 bit integer: clients must try the targets with the lowest priority first.
+// WEIGHT:		A 16 bit relative weight for selecting among targets of the same priority. Larger weights are chosen more often.
+// PORT:		The 16 bit TCP or UDP port of the service on the target host.
+// TARGET:		The <domain-name> of the target host, uncompressed. "." means the service is not available at this domain.
+
+type RDataSRV struct {
+	Priority uint16
+	Weight   uint16
+	Port     uint16
+	Target   string
+}
+
+func (rdata *RDataSRV) String() string {
+	srv := []string{
+		strconv.Itoa(int(rdata.Priority)),
+		strconv.Itoa(int(rdata.Weight)),
+		strconv.Itoa(int(rdata.Port)),
+		rdata.Target,
+	}
+
+	return strings.Join(srv, " ")
+}
+
+func (rdata *RDataSRV) WriteRecordData(writer *dnsWriter) error {
+	writer.writeUint16(rdata.Priority)
+	writer.writeUint16(rdata.Weight)
+	writer.writeUint16(rdata.Port)
+	return writer.writeDomainName(rdata.Target)
+}
+
+func (rdata *RDataSRV) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	if length < 7 || reader.offset+int(length) > len(reader.data) {
+		return invalidRecordDataError(fmt.Sprintf("SRV RData: invalid length: %d", length))
+	}
+
+	rdata.Priority = reader.readUint16()
+	rdata.Weight = reader.readUint16()
+	rdata.Port = reader.readUint16()
+	rdata.Target, err = reader.readDomainName()
+	if err != nil {
+		return fmt.Errorf("%w: SRV RData: %w", ErrInvalidRecordData, err)
+	}
+	return nil
+}
+
+// -------------- URI
+// URI RDATA format [RFC7553]
+// PRIORITY:	A 16 bit integer: clients must try the targets with the lowest priority first.
+// WEIGHT:		A 16 bit relative weight for selecting among targets of the same priority. Larger weights are chosen more often.
+// TARGET:		The URI, as a sequence of one or more octets until the end of the record. Unlike a <character-string>, it has no length prefix.
+
+type RDataURI struct {
+	Priority uint16
+	Weight   uint16
+	Target   string
+}
+
+func (rdata *RDataURI) String() string {
+	uri := []string{
+		strconv.Itoa(int(rdata.Priority)),
+		strconv.Itoa(int(rdata.Weight)),
+		getQuotedValue([]byte(rdata.Target)),
+	}
+
+	return strings.Join(uri, " ")
+}
+
+func (rdata *RDataURI) WriteRecordData(writer *dnsWriter) error {
+	if rdata.Target == "" {
+		return invalidRecordDataError("URI RData: empty target")
+	}
+	writer.writeUint16(rdata.Priority)
+	writer.writeUint16(rdata.Weight)
+	writer.writeData([]byte(rdata.Target))
+	return nil
+}
+
+func (rdata *RDataURI) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	if length < 5 || reader.offset+int(length) > len(reader.data) {
+		return invalidRecordDataError(fmt.Sprintf("URI RData: invalid length: %d", length))
+	}
+
+	rdata.Priority = reader.readUint16()
+	rdata.Weight = reader.readUint16()
+	target, err := reader.readUntil(int(length) - 4)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("URI RData: %s", err.Error()))
+	}
+	rdata.Target = string(target)
+	return nil
+}
+
 // -------------- SOA
 // SOA RDATA format
 // MNAME:	The <domain-name> of the name server that was the original or primary source of data for this zone.
@@ -232,80 +533,1032 @@ func (rdata *RDataMX) ReadRecordData(reader *dnsReader, length uint16) (err erro
 // MINIMUM:	The unsigned 32 bit minimum TTL field that should be exported with any RR from this zone.
 
 type RDataSOA struct {
-	mName   string
-	rName   string
-	serial  uint32
-	refresh uint32
-	retry   uint32
-	expire  uint32
-	minimum uint32
+	MName   string
+	RName   string
+	Serial  uint32
+	Refresh uint32
+	Retry   uint32
+	Expire  uint32
+	Minimum uint32
 }
 
 func (rdata *RDataSOA) String() string {
 	soa := []string{
-		rdata.mName,
-		rdata.rName,
-		strconv.Itoa(int(rdata.serial)),
-		strconv.Itoa(int(rdata.refresh)),
-		strconv.Itoa(int(rdata.retry)),
-		strconv.Itoa(int(rdata.expire)),
-		strconv.Itoa(int(rdata.minimum)),
+		rdata.MName,
+		rdata.RName,
+		strconv.Itoa(int(rdata.Serial)),
+		strconv.Itoa(int(rdata.Refresh)),
+		strconv.Itoa(int(rdata.Retry)),
+		strconv.Itoa(int(rdata.Expire)),
+		strconv.Itoa(int(rdata.Minimum)),
 	}
 
 	return strings.Join(soa, " ")
 }
 
 func (rdata *RDataSOA) WriteRecordData(writer *dnsWriter) error {
-	writer.writeDomainName(rdata.mName)
-	writer.writeDomainName(rdata.rName)
+	if err := writer.writeCompressedDomainName(rdata.MName); err != nil {
+		return err
+	}
+	if err := writer.writeCompressedDomainName(rdata.RName); err != nil {
+		return err
+	}
 
-	writer.writeUint32(rdata.serial)
-	writer.writeUint32(rdata.refresh)
-	writer.writeUint32(rdata.retry)
-	writer.writeUint32(rdata.expire)
-	writer.writeUint32(rdata.minimum)
+	writer.writeUint32(rdata.Serial)
+	writer.writeUint32(rdata.Refresh)
+	writer.writeUint32(rdata.Retry)
+	writer.writeUint32(rdata.Expire)
+	writer.writeUint32(rdata.Minimum)
 	return nil
 }
 
 func (rdata *RDataSOA) ReadRecordData(reader *dnsReader, length uint16) (err error) {
-	rdata.mName, err = reader.readDomainName()
+	end := reader.offset + int(length)
+	if length < 22 || end > len(reader.data) {
+		return invalidRecordDataError(fmt.Sprintf("SOA RData: invalid length: %d", length))
+	}
+
+	rdata.MName, err = reader.readDomainName()
+	if err != nil {
+		return fmt.Errorf("%w: SOA RData: %w", ErrInvalidRecordData, err)
+	}
+
+	rdata.RName, err = reader.readDomainName()
+	if err != nil {
+		return fmt.Errorf("%w: SOA RData: %w", ErrInvalidRecordData, err)
+	}
+	if reader.offset+20 > end {
+		return invalidRecordDataError("SOA RData: names exceed record length")
+	}
+
+	rdata.Serial = reader.readUint32()
+	rdata.Refresh = reader.readUint32()
+	rdata.Retry = reader.readUint32()
+	rdata.Expire = reader.readUint32()
+	rdata.Minimum = reader.readUint32()
+
+	return nil
+}
+
+// -------------- RRSIG
+// RRSIG RDATA format [RFC4034]
+// TYPE COVERED:	The type of the RRset covered by this signature.
+// ALGORITHM:		The cryptographic algorithm used to create the signature.
+// LABELS:			The number of labels in the original RRSIG RR owner name, excluding the root and a leading wildcard.
+// ORIGINAL TTL:	The TTL of the covered RRset as it appears in the authoritative zone.
+// EXPIRATION:		The end of the validity period, in seconds since 1 January 1970 UTC (serial number arithmetic).
+// INCEPTION:		The start of the validity period, in seconds since 1 January 1970 UTC (serial number arithmetic).
+// KEY TAG:			The key tag value of the DNSKEY RR that validates this signature.
+// SIGNER'S NAME:	The owner name of the DNSKEY RR that validates this signature, uncompressed.
+// SIGNATURE:		The cryptographic signature that covers the RRSIG RDATA (excluding the signature) and the RRset.
+
+type RDataRRSIG struct {
+	TypeCovered uint16
+	Algorithm   uint8
+	Labels      uint8
+	OriginalTTL uint32
+	Expiration  uint32
+	Inception   uint32
+	KeyTag      uint16
+	SignerName  string
+	Signature   []byte
+}
+
+// rrsigTimeFormat is the presentation format of the signature expiration and inception: YYYYMMDDHHmmSS in UTC.
+const rrsigTimeFormat = "20060102150405"
+
+func (rdata *RDataRRSIG) String() string {
+	rrsig := []string{
+		DNSType(rdata.TypeCovered).String(),
+		strconv.Itoa(int(rdata.Algorithm)),
+		strconv.Itoa(int(rdata.Labels)),
+		strconv.FormatUint(uint64(rdata.OriginalTTL), 10),
+		time.Unix(int64(rdata.Expiration), 0).UTC().Format(rrsigTimeFormat),
+		time.Unix(int64(rdata.Inception), 0).UTC().Format(rrsigTimeFormat),
+		strconv.Itoa(int(rdata.KeyTag)),
+		rdata.SignerName,
+		base64.StdEncoding.EncodeToString(rdata.Signature),
+	}
+
+	return strings.Join(rrsig, " ")
+}
+
+func (rdata *RDataRRSIG) WriteRecordData(writer *dnsWriter) error {
+	writer.writeUint16(rdata.TypeCovered)
+	writer.writeData([]byte{rdata.Algorithm, rdata.Labels})
+	writer.writeUint32(rdata.OriginalTTL)
+	writer.writeUint32(rdata.Expiration)
+	writer.writeUint32(rdata.Inception)
+	writer.writeUint16(rdata.KeyTag)
+	if err := writer.writeDomainName(rdata.SignerName); err != nil {
+		return err
+	}
+	writer.writeData(rdata.Signature)
+	return nil
+}
+
+func (rdata *RDataRRSIG) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	end := reader.offset + int(length)
+	if length < 19 || end > len(reader.data) {
+		return invalidRecordDataError(fmt.Sprintf("RRSIG RData: invalid length: %d", length))
+	}
+
+	rdata.TypeCovered = reader.readUint16()
+	rdata.Algorithm = reader.data[reader.offset]
+	rdata.Labels = reader.data[reader.offset+1]
+	reader.offset += 2
+	rdata.OriginalTTL = reader.readUint32()
+	rdata.Expiration = reader.readUint32()
+	rdata.Inception = reader.readUint32()
+	rdata.KeyTag = reader.readUint16()
+
+	rdata.SignerName, err = reader.readDomainName()
+	if err != nil {
+		return fmt.Errorf("%w: RRSIG RData: %w", ErrInvalidRecordData, err)
+	}
+	if reader.offset > end {
+		return invalidRecordDataError("RRSIG RData: signer's name exceeds record length")
+	}
+
+	signature, err := reader.readUntil(end - reader.offset)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("RRSIG RData: %s", err.Error()))
+	}
+	rdata.Signature = append([]byte{}, signature...)
+
+	return nil
+}
+
+// -------------- DNSKEY
+// DNSKEY RDATA format [RFC4034], also used by KEY [RFC2535][RFC3445]
+// FLAGS:		Bit 7 is the Zone Key flag, bit 15 is the Secure Entry Point flag.
+// PROTOCOL:	Must be 3.
+// ALGORITHM:	The public key's cryptographic algorithm.
+// PUBLIC KEY:	The public key material, in a format that depends on the algorithm.
+
+type RDataDNSKEY struct {
+	Flags     uint16
+	Protocol  uint8
+	Algorithm uint8
+	PublicKey []byte
+}
+
+const (
+	DNSKEYFlagZone             uint16 = 0x0100 // Zone Key flag
+	DNSKEYFlagSecureEntryPoint uint16 = 0x0001 // Secure Entry Point flag, set on key signing keys
+	DNSKEYFlagRevoke           uint16 = 0x0080 // Revoked flag [RFC5011]
+	DNSKEYProtocol             uint8  = 3
+)
+
+func (rdata *RDataDNSKEY) String() string {
+	dnskey := []string{
+		strconv.Itoa(int(rdata.Flags)),
+		strconv.Itoa(int(rdata.Protocol)),
+		strconv.Itoa(int(rdata.Algorithm)),
+		base64.StdEncoding.EncodeToString(rdata.PublicKey),
+	}
+
+	return strings.Join(dnskey, " ")
+}
+
+// KeyTag computes the key tag identifying this key in RRSIG and DS records [RFC4034 Appendix B].
+func (rdata *RDataDNSKEY) KeyTag() uint16 {
+	writer := &dnsWriter{}
+	rdata.WriteRecordData(writer)
+
+	var accumulator uint32
+	for i, b := range writer.data {
+		if i&1 == 0 {
+			accumulator += uint32(b) << 8
+		} else {
+			accumulator += uint32(b)
+		}
+	}
+	accumulator += accumulator >> 16 & 0xFFFF
+	return uint16(accumulator & 0xFFFF)
+}
+
+func (rdata *RDataDNSKEY) WriteRecordData(writer *dnsWriter) error {
+	writer.writeUint16(rdata.Flags)
+	writer.writeData([]byte{rdata.Protocol, rdata.Algorithm})
+	writer.writeData(rdata.PublicKey)
+	return nil
+}
+
+func (rdata *RDataDNSKEY) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	if length < 4 {
+		return invalidRecordDataError(fmt.Sprintf("DNSKEY RData: invalid length: %d", length))
+	}
+
+	rdata.Flags = reader.readUint16()
+	header, err := reader.readUntil(2)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("DNSKEY RData: %s", err.Error()))
+	}
+	rdata.Protocol, rdata.Algorithm = header[0], header[1]
+
+	publicKey, err := reader.readUntil(int(length) - 4)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("DNSKEY RData: %s", err.Error()))
+	}
+	rdata.PublicKey = append([]byte{}, publicKey...)
+
+	return nil
+}
+
+// -------------- DS
+// DS RDATA format [RFC4034], also used by CDS [RFC7344]
+// KEY TAG:		The key tag of the DNSKEY RR referred to by the DS record.
+// ALGORITHM:	The algorithm number of the DNSKEY RR referred to by the DS record.
+// DIGEST TYPE:	The algorithm used to construct the digest.
+// DIGEST:		The digest of the DNSKEY RR's owner name and RDATA.
+
+type RDataDS struct {
+	KeyTag     uint16
+	Algorithm  uint8
+	DigestType uint8
+	Digest     []byte
+}
+
+const (
+	DSDigestSHA1   uint8 = 1 // SHA-1 [RFC3658]
+	DSDigestSHA256 uint8 = 2 // SHA-256 [RFC4509]
+	DSDigestSHA384 uint8 = 4 // SHA-384 [RFC6605]
+)
+
+func (rdata *RDataDS) String() string {
+	ds := []string{
+		strconv.Itoa(int(rdata.KeyTag)),
+		strconv.Itoa(int(rdata.Algorithm)),
+		strconv.Itoa(int(rdata.DigestType)),
+		strings.ToUpper(hex.EncodeToString(rdata.Digest)),
+	}
+
+	return strings.Join(ds, " ")
+}
+
+func (rdata *RDataDS) WriteRecordData(writer *dnsWriter) error {
+	writer.writeUint16(rdata.KeyTag)
+	writer.writeData([]byte{rdata.Algorithm, rdata.DigestType})
+	writer.writeData(rdata.Digest)
+	return nil
+}
+
+func (rdata *RDataDS) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	if length < 4 {
+		return invalidRecordDataError(fmt.Sprintf("DS RData: invalid length: %d", length))
+	}
+
+	rdata.KeyTag = reader.readUint16()
+	header, err := reader.readUntil(2)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("DS RData: %s", err.Error()))
+	}
+	rdata.Algorithm, rdata.DigestType = header[0], header[1]
+
+	digest, err := reader.readUntil(int(length) - 4)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("DS RData: %s", err.Error()))
+	}
+	rdata.Digest = append([]byte{}, digest...)
+
+	return nil
+}
+
+// -------------- NSEC
+// NSEC RDATA format [RFC4034]
+// NEXT DOMAIN NAME:	The next owner name in the canonical ordering of the zone, uncompressed.
+// TYPE BIT MAPS:		The RR types that exist at the NSEC RR's owner name.
+
+type RDataNSEC struct {
+	NextDomainName string
+	Types          []uint16
+}
+
+func (rdata *RDataNSEC) String() string {
+	return strings.Join(append([]string{rdata.NextDomainName}, getTypeBitMapStrings(rdata.Types)...), " ")
+}
+
+func (rdata *RDataNSEC) WriteRecordData(writer *dnsWriter) error {
+	if err := writer.writeDomainName(rdata.NextDomainName); err != nil {
+		return err
+	}
+	writer.writeTypeBitMap(rdata.Types)
+	return nil
+}
+
+func (rdata *RDataNSEC) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	end := reader.offset + int(length)
+	if end > len(reader.data) {
+		return invalidRecordDataError(fmt.Sprintf("NSEC RData: invalid length: %d", length))
+	}
+
+	rdata.NextDomainName, err = reader.readDomainName()
+	if err != nil {
+		return fmt.Errorf("%w: NSEC RData: %w", ErrInvalidRecordData, err)
+	}
+	if reader.offset > end {
+		return invalidRecordDataError("NSEC RData: next domain name exceeds record length")
+	}
+
+	rdata.Types, err = reader.readTypeBitMap(end - reader.offset)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("NSEC RData: %s", err.Error()))
+	}
+
+	return nil
+}
+
+// -------------- NSEC3
+// NSEC3 RDATA format [RFC5155]
+// HASH ALGORITHM:			The cryptographic hash algorithm used to construct the hash-value.
+// FLAGS:					Bit 7 is the Opt-Out flag.
+// ITERATIONS:				The number of additional times the hash function has been performed.
+// SALT LENGTH, SALT:		The salt value appended to the original owner name before hashing.
+// HASH LENGTH, NEXT HASHED OWNER NAME:	The next hashed owner name in hash order, in binary format.
+// TYPE BIT MAPS:			The RR types that exist at the original owner name of the NSEC3 RR.
+
+type RDataNSEC3 struct {
+	HashAlgorithm       uint8
+	Flags               uint8
+	Iterations          uint16
+	Salt                []byte
+	NextHashedOwnerName []byte
+	Types               []uint16
+}
+
+const (
+	NSEC3HashSHA1   uint8 = 1    // SHA-1 [RFC5155]
+	NSEC3FlagOptOut uint8 = 0x01 // Opt-Out flag [RFC5155]
+)
+
+func (rdata *RDataNSEC3) String() string {
+	nsec3 := []string{
+		strconv.Itoa(int(rdata.HashAlgorithm)),
+		strconv.Itoa(int(rdata.Flags)),
+		strconv.Itoa(int(rdata.Iterations)),
+		getSaltString(rdata.Salt),
+		EncodeNSEC3Hash(rdata.NextHashedOwnerName),
+	}
+
+	return strings.Join(append(nsec3, getTypeBitMapStrings(rdata.Types)...), " ")
+}
+
+func (rdata *RDataNSEC3) WriteRecordData(writer *dnsWriter) error {
+	if len(rdata.Salt) > 255 || len(rdata.NextHashedOwnerName) > 255 {
+		return invalidRecordDataError("NSEC3 RData: salt or hash too long")
+	}
+
+	writer.writeData([]byte{rdata.HashAlgorithm, rdata.Flags})
+	writer.writeUint16(rdata.Iterations)
+	writer.writeData(append([]byte{byte(len(rdata.Salt))}, rdata.Salt...))
+	writer.writeData(append([]byte{byte(len(rdata.NextHashedOwnerName))}, rdata.NextHashedOwnerName...))
+	writer.writeTypeBitMap(rdata.Types)
+	return nil
+}
+
+func (rdata *RDataNSEC3) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	end := reader.offset + int(length)
+	if length < 5 || end > len(reader.data) {
+		return invalidRecordDataError(fmt.Sprintf("NSEC3 RData: invalid length: %d", length))
+	}
+
+	rdata.HashAlgorithm, rdata.Flags, rdata.Iterations, rdata.Salt, err = reader.readNSEC3Parameters(end)
 	if err != nil {
-		return invalidRecordDataError(fmt.Sprintf("SOA RData: %s", err.Error()))
+		return invalidRecordDataError(fmt.Sprintf("NSEC3 RData: %s", err.Error()))
 	}
 
-	rdata.rName, err = reader.readDomainName()
+	rdata.NextHashedOwnerName, err = reader.readLengthPrefixedData(end)
 	if err != nil {
-		return invalidRecordDataError(fmt.Sprintf("SOA RData: %s", err.Error()))
+		return invalidRecordDataError(fmt.Sprintf("NSEC3 RData: next hashed owner name: %s", err.Error()))
+	}
+
+	rdata.Types, err = reader.readTypeBitMap(end - reader.offset)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("NSEC3 RData: %s", err.Error()))
+	}
+
+	return nil
+}
+
+// -------------- NSEC3PARAM
+// NSEC3PARAM RDATA format [RFC5155]
+// The hash algorithm, flags, iterations and salt used to build the zone's NSEC3 records.
+
+type RDataNSEC3PARAM struct {
+	HashAlgorithm uint8
+	Flags         uint8
+	Iterations    uint16
+	Salt          []byte
+}
+
+func (rdata *RDataNSEC3PARAM) String() string {
+	nsec3param := []string{
+		strconv.Itoa(int(rdata.HashAlgorithm)),
+		strconv.Itoa(int(rdata.Flags)),
+		strconv.Itoa(int(rdata.Iterations)),
+		getSaltString(rdata.Salt),
 	}
 
-	rdata.serial = reader.readUint32()
-	rdata.refresh = reader.readUint32()
-	rdata.retry = reader.readUint32()
-	rdata.expire = reader.readUint32()
-	rdata.minimum = reader.readUint32()
+	return strings.Join(nsec3param, " ")
+}
+
+func (rdata *RDataNSEC3PARAM) WriteRecordData(writer *dnsWriter) error {
+	if len(rdata.Salt) > 255 {
+		return invalidRecordDataError("NSEC3PARAM RData: salt too long")
+	}
+
+	writer.writeData([]byte{rdata.HashAlgorithm, rdata.Flags})
+	writer.writeUint16(rdata.Iterations)
+	writer.writeData(append([]byte{byte(len(rdata.Salt))}, rdata.Salt...))
+	return nil
+}
+
+func (rdata *RDataNSEC3PARAM) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	end := reader.offset + int(length)
+	if length < 5 || end > len(reader.data) {
+		return invalidRecordDataError(fmt.Sprintf("NSEC3PARAM RData: invalid length: %d", length))
+	}
+
+	rdata.HashAlgorithm, rdata.Flags, rdata.Iterations, rdata.Salt, err = reader.readNSEC3Parameters(end)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("NSEC3PARAM RData: %s", err.Error()))
+	}
+	if reader.offset != end {
+		return invalidRecordDataError("NSEC3PARAM RData: trailing data")
+	}
 
 	return nil
 }
 
+// readNSEC3Parameters reads the fields shared by NSEC3 and NSEC3PARAM, up to the end offset.
+func (reader *dnsReader) readNSEC3Parameters(end int) (hashAlgorithm uint8, flags uint8, iterations uint16, salt []byte, err error) {
+	hashAlgorithm = reader.data[reader.offset]
+	flags = reader.data[reader.offset+1]
+	reader.offset += 2
+	iterations = reader.readUint16()
+
+	salt, err = reader.readLengthPrefixedData(end)
+	if err != nil {
+		return 0, 0, 0, nil, fmt.Errorf("salt: %w", err)
+	}
+	return hashAlgorithm, flags, iterations, salt, nil
+}
+
+// readLengthPrefixedData reads a one byte length followed by that many bytes, up to the end offset.
+func (reader *dnsReader) readLengthPrefixedData(end int) ([]byte, error) {
+	if reader.offset >= end {
+		return nil, fmt.Errorf("missing length")
+	}
+	length := int(reader.data[reader.offset])
+	if reader.offset+1+length > end {
+		return nil, fmt.Errorf("invalid length: %d", length)
+	}
+	reader.offset++
+
+	data, err := reader.readUntil(length)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{}, data...), nil
+}
+
+func getSaltString(salt []byte) string {
+	if len(salt) == 0 {
+		return "-"
+	}
+	return strings.ToUpper(hex.EncodeToString(salt))
+}
+
+// -------------- CSYNC
+// CSYNC RDATA format [RFC7477]
+// SOA SERIAL:		The SOA serial of the child zone the parent should synchronize from, if the soaminimum flag is set.
+// FLAGS:			Bit 0 is the immediate flag, bit 1 the soaminimum flag.
+// TYPE BIT MAP:	The RR types at the child's apex the parent should copy, in the same format as NSEC.
+
+type RDataCSYNC struct {
+	Serial uint32
+	Flags  uint16
+	Types  []uint16
+}
+
+const (
+	CSYNCFlagImmediate  uint16 = 0x0001 // The parent may synchronize immediately, without waiting for its own checks [RFC7477]
+	CSYNCFlagSOAMinimum uint16 = 0x0002 // The parent must only synchronize from a zone with at least this SOA serial [RFC7477]
+)
+
+func (rdata *RDataCSYNC) String() string {
+	csync := []string{
+		strconv.FormatUint(uint64(rdata.Serial), 10),
+		strconv.Itoa(int(rdata.Flags)),
+	}
+
+	return strings.Join(append(csync, getTypeBitMapStrings(rdata.Types)...), " ")
+}
+
+func (rdata *RDataCSYNC) WriteRecordData(writer *dnsWriter) error {
+	writer.writeUint32(rdata.Serial)
+	writer.writeUint16(rdata.Flags)
+	writer.writeTypeBitMap(rdata.Types)
+	return nil
+}
+
+func (rdata *RDataCSYNC) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	if length < 6 || reader.offset+int(length) > len(reader.data) {
+		return invalidRecordDataError(fmt.Sprintf("CSYNC RData: invalid length: %d", length))
+	}
+
+	rdata.Serial = reader.readUint32()
+	rdata.Flags = reader.readUint16()
+	rdata.Types, err = reader.readTypeBitMap(int(length) - 6)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("CSYNC RData: %s", err.Error()))
+	}
+	return nil
+}
+
+// Type bit maps format [RFC4034]: the types are split in windows of 256 types.
+// Each window present is encoded as its number, the length of its bitmap (1 to 32 bytes),
+// and the bitmap, where the most significant bit of the first byte is type 0 of the window.
+
+func (reader *dnsReader) readTypeBitMap(length int) (types []uint16, err error) {
+	data, err := reader.readUntil(length)
+	if err != nil {
+		return nil, err
+	}
+
+	lastWindow := -1
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, fmt.Errorf("type bit map: truncated window")
+		}
+
+		window, bitmapLength := int(data[0]), int(data[1])
+		if window <= lastWindow || bitmapLength == 0 || bitmapLength > 32 || len(data) < 2+bitmapLength {
+			return nil, fmt.Errorf("type bit map: invalid window %d with length %d", window, bitmapLength)
+		}
+		lastWindow = window
+
+		for i, b := range data[2 : 2+bitmapLength] {
+			for bit := 0; bit < 8; bit++ {
+				if b&(0x80>>bit) != 0 {
+					types = append(types, uint16(window<<8|i<<3|bit))
+				}
+			}
+		}
+		data = data[2+bitmapLength:]
+	}
+	return types, nil
+}
+
+func (writer *dnsWriter) writeTypeBitMap(types []uint16) {
+	sorted := append([]uint16{}, types...)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	for len(sorted) > 0 {
+		window := sorted[0] >> 8
+		bitmap := [32]byte{}
+		bitmapLength := 0
+
+		for len(sorted) > 0 && sorted[0]>>8 == window {
+			low := sorted[0] & 0xFF
+			bitmap[low>>3] |= 0x80 >> (low & 7)
+			bitmapLength = int(low>>3) + 1
+			sorted = sorted[1:]
+		}
+
+		writer.writeData([]byte{byte(window), byte(bitmapLength)})
+		writer.writeData(bitmap[:bitmapLength])
+	}
+}
+
+// getTypeBitMapStrings returns the presentation format of the types, unknown types as TYPEn [RFC3597].
+func getTypeBitMapStrings(types []uint16) []string {
+	typeStrings := make([]string, 0, len(types))
+	for _, rtype := range types {
+		typeStrings = append(typeStrings, DNSType(rtype).String())
+	}
+	return typeStrings
+}
+
 // -------------- UNKNOWN
+// RDATA of unknown types is kept as is, and presented in the generic format [RFC3597]:
+// "\#", the length of the RDATA in bytes, and the RDATA in hexadecimal, ex. "\# 4 0A000001".
 
 type RDataUnknown struct {
-	raw []byte
+	Data []byte
 }
 
 func (rdata *RDataUnknown) String() string {
-	return string(rdata.raw)
+	if len(rdata.Data) == 0 {
+		return `\# 0`
+	}
+	return `\# ` + strconv.Itoa(len(rdata.Data)) + " " + strings.ToUpper(hex.EncodeToString(rdata.Data))
 }
 
 func (rdata *RDataUnknown) WriteRecordData(writer *dnsWriter) error {
-	writer.writeData(rdata.raw)
+	writer.writeData(rdata.Data)
 	return nil
 }
 
 func (rdata *RDataUnknown) ReadRecordData(reader *dnsReader, length uint16) (err error) {
-	rdata.raw, err = reader.readUntil(int(length))
+	raw, err := reader.readUntil(int(length))
 	if err != nil {
 		return err
 	}
+	rdata.Data = append([]byte{}, raw...)
+	return nil
+}
+
+// -------------- TLSA
+// TLSA RDATA format [RFC6698], also used by SMIMEA [RFC8162]
+// CERTIFICATE USAGE:			How the certificate association is used to verify the server's certificate.
+// SELECTOR:					Which part of the certificate is matched: the full certificate or its SubjectPublicKeyInfo.
+// MATCHING TYPE:				How the certificate association data is presented: the selected content itself, or its hash.
+// CERTIFICATE ASSOCIATION DATA:	The data to match.
+
+type RDataTLSA struct {
+	Usage        uint8
+	Selector     uint8
+	MatchingType uint8
+	Data         []byte
+}
+
+const (
+	TLSAUsagePKIXTA uint8 = 0 // CA constraint: a CA of the chain, which must also pass PKIX validation [RFC6698]
+	TLSAUsagePKIXEE uint8 = 1 // Service certificate constraint: the server's certificate, which must also pass PKIX validation [RFC6698]
+	TLSAUsageDANETA uint8 = 2 // Trust anchor assertion: a trust anchor for the chain, without PKIX validation [RFC6698]
+	TLSAUsageDANEEE uint8 = 3 // Domain-issued certificate: the server's certificate, without PKIX validation [RFC6698]
+
+	TLSASelectorCert uint8 = 0 // Full certificate [RFC6698]
+	TLSASelectorSPKI uint8 = 1 // SubjectPublicKeyInfo [RFC6698]
+
+	TLSAMatchingFull   uint8 = 0 // Exact match on the selected content [RFC6698]
+	TLSAMatchingSHA256 uint8 = 1 // SHA-256 hash of the selected content [RFC6698]
+	TLSAMatchingSHA512 uint8 = 2 // SHA-512 hash of the selected content [RFC6698]
+)
+
+func (rdata *RDataTLSA) String() string {
+	tlsa := []string{
+		strconv.Itoa(int(rdata.Usage)),
+		strconv.Itoa(int(rdata.Selector)),
+		strconv.Itoa(int(rdata.MatchingType)),
+		strings.ToUpper(hex.EncodeToString(rdata.Data)),
+	}
+
+	return strings.Join(tlsa, " ")
+}
+
+func (rdata *RDataTLSA) WriteRecordData(writer *dnsWriter) error {
+	writer.writeData([]byte{rdata.Usage, rdata.Selector, rdata.MatchingType})
+	writer.writeData(rdata.Data)
+	return nil
+}
+
+func (rdata *RDataTLSA) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	if length < 3 {
+		return invalidRecordDataError(fmt.Sprintf("TLSA RData: invalid length: %d", length))
+	}
+
+	header, err := reader.readUntil(3)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("TLSA RData: %s", err.Error()))
+	}
+	rdata.Usage, rdata.Selector, rdata.MatchingType = header[0], header[1], header[2]
+
+	data, err := reader.readUntil(int(length) - 3)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("TLSA RData: %s", err.Error()))
+	}
+	rdata.Data = append([]byte{}, data...)
+
+	return nil
+}
+
+// -------------- LOC
+// LOC RDATA format [RFC1876]
+// VERSION:		Version number of the representation, must be 0.
+// SIZE:		The diameter of a sphere enclosing the described entity, in centimeters.
+// HORIZ PRE:	The horizontal precision of the data, in centimeters.
+// VERT PRE:	The vertical precision of the data, in centimeters.
+// LATITUDE:	The latitude of the center of the sphere, in thousandths of a second of arc. 2^31 is the equator.
+// LONGITUDE:	The longitude of the center of the sphere, in thousandths of a second of arc. 2^31 is the prime meridian.
+// ALTITUDE:	The altitude of the center of the sphere, in centimeters, from a base of 100,000m below the WGS 84 reference spheroid.
+
+// Sizes and precisions are encoded on one byte as a mantissa (high nibble) and a power of ten (low nibble):
+// 0x13 is 1 * 10^3 centimeters, or 10 meters.
+
+type RDataLOC struct {
+	Version             uint8
+	Size                uint8 // Encoded size
+	HorizontalPrecision uint8 // Encoded horizontal precision
+	VerticalPrecision   uint8 // Encoded vertical precision
+	Latitude            uint32
+	Longitude           uint32
+	Altitude            uint32
+}
+
+const (
+	locEquator       uint32 = 1 << 31  // Latitude of the equator and longitude of the prime meridian
+	locAltitudeBase  int64  = 10000000 // Altitude of the reference spheroid, in centimeters
+	locMilliArcSec          = 1000 * 60 * 60
+	locDegreesMaxLat        = 90
+	locDegreesMaxLon        = 180
+
+	// Default values of the presentation format [RFC1876]
+	LOCDefaultSize                = 0x12 // 1m
+	LOCDefaultHorizontalPrecision = 0x16 // 10,000m
+	LOCDefaultVerticalPrecision   = 0x13 // 10m
+)
+
+// NewRDataLOC creates a LOC record from coordinates in degrees and distances in meters.
+//
+// Parameters:
+//   - latitude: The latitude in degrees, positive north of the equator.
+//   - longitude: The longitude in degrees, positive east of the prime meridian.
+//   - altitude: The altitude in meters above the WGS 84 reference spheroid.
+//   - size, horizontalPrecision, verticalPrecision: In meters. They are rounded down to one significant digit.
+//
+// Returns:
+//   - *RDataLOC: The LOC record data.
+//   - error: If a value is out of range.
+func NewRDataLOC(latitude float64, longitude float64, altitude float64, size float64, horizontalPrecision float64, verticalPrecision float64) (*RDataLOC, error) {
+	if math.Abs(latitude) > locDegreesMaxLat || math.Abs(longitude) > locDegreesMaxLon {
+		return nil, invalidRecordDataError(fmt.Sprintf("LOC RData: invalid coordinates: %f %f", latitude, longitude))
+	}
+
+	altitudeCentimeters := int64(math.Round(altitude*100)) + locAltitudeBase
+	if altitudeCentimeters < 0 || altitudeCentimeters > math.MaxUint32 {
+		return nil, invalidRecordDataError(fmt.Sprintf("LOC RData: invalid altitude: %fm", altitude))
+	}
+
+	rdata := &RDataLOC{
+		Latitude:  uint32(int64(locEquator) + int64(math.Round(latitude*locMilliArcSec))),
+		Longitude: uint32(int64(locEquator) + int64(math.Round(longitude*locMilliArcSec))),
+		Altitude:  uint32(altitudeCentimeters),
+	}
+
+	var err error
+	for _, precision := range []struct {
+		meters  float64
+		encoded *uint8
+	}{
+		{size, &rdata.Size},
+		{horizontalPrecision, &rdata.HorizontalPrecision},
+		{verticalPrecision, &rdata.VerticalPrecision},
+	} {
+		*precision.encoded, err = encodeLOCPrecision(precision.meters)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rdata, nil
+}
+
+// LatitudeDegrees returns the latitude in degrees, positive north of the equator.
+func (rdata *RDataLOC) LatitudeDegrees() float64 {
+	return float64(int64(rdata.Latitude)-int64(locEquator)) / locMilliArcSec
+}
+
+// LongitudeDegrees returns the longitude in degrees, positive east of the prime meridian.
+func (rdata *RDataLOC) LongitudeDegrees() float64 {
+	return float64(int64(rdata.Longitude)-int64(locEquator)) / locMilliArcSec
+}
+
+// AltitudeMeters returns the altitude in meters above the WGS 84 reference spheroid.
+func (rdata *RDataLOC) AltitudeMeters() float64 {
+	return float64(int64(rdata.Altitude)-locAltitudeBase) / 100
+}
+
+// SizeMeters returns the size, horizontal precision and vertical precision in meters.
+func (rdata *RDataLOC) SizeMeters() (size float64, horizontalPrecision float64, verticalPrecision float64) {
+	return float64(decodeLOCPrecision(rdata.Size)) / 100,
+		float64(decodeLOCPrecision(rdata.HorizontalPrecision)) / 100,
+		float64(decodeLOCPrecision(rdata.VerticalPrecision)) / 100
+}
+
+// String returns the presentation format of the record, ex. "42 21 54.000 N 71 06 18.000 W -24.00m 30m 10000m 10m".
+func (rdata *RDataLOC) String() string {
+	loc := []string{
+		getLOCCoordinateString(rdata.Latitude, "N", "S"),
+		getLOCCoordinateString(rdata.Longitude, "E", "W"),
+		getLOCAltitudeString(int64(rdata.Altitude) - locAltitudeBase),
+		getLOCPrecisionString(rdata.Size),
+		getLOCPrecisionString(rdata.HorizontalPrecision),
+		getLOCPrecisionString(rdata.VerticalPrecision),
+	}
+
+	return strings.Join(loc, " ")
+}
+
+func (rdata *RDataLOC) WriteRecordData(writer *dnsWriter) error {
+	writer.writeData([]byte{rdata.Version, rdata.Size, rdata.HorizontalPrecision, rdata.VerticalPrecision})
+	writer.writeUint32(rdata.Latitude)
+	writer.writeUint32(rdata.Longitude)
+	writer.writeUint32(rdata.Altitude)
+	return nil
+}
+
+func (rdata *RDataLOC) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	if length != 16 {
+		return invalidRecordDataError(fmt.Sprintf("LOC RData: invalid length: %d", length))
+	}
+
+	header, err := reader.readUntil(4)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("LOC RData: %s", err.Error()))
+	}
+	rdata.Version = header[0]
+	if rdata.Version != 0 {
+		return invalidRecordDataError(fmt.Sprintf("LOC RData: unsupported version: %d", rdata.Version))
+	}
+	rdata.Size, rdata.HorizontalPrecision, rdata.VerticalPrecision = header[1], header[2], header[3]
+
+	for _, precision := range header[1:] {
+		if precision>>4 > 9 || precision&0x0F > 9 {
+			return invalidRecordDataError(fmt.Sprintf("LOC RData: invalid precision: 0x%02x", precision))
+		}
+	}
+
+	rdata.Latitude = reader.readUint32()
+	rdata.Longitude = reader.readUint32()
+	rdata.Altitude = reader.readUint32()
+	return nil
+}
+
+// decodeLOCPrecision returns an encoded size or precision in centimeters.
+func decodeLOCPrecision(precision uint8) uint64 {
+	centimeters := uint64(precision >> 4)
+	for i := uint8(0); i < precision&0x0F; i++ {
+		centimeters *= 10
+	}
+	return centimeters
+}
+
+// encodeLOCPrecision encodes a size or precision in meters, rounded down to one significant digit.
+func encodeLOCPrecision(meters float64) (uint8, error) {
+	centimeters := uint64(math.Round(meters * 100))
+	if meters < 0 || centimeters > 9e9 {
+		return 0, invalidRecordDataError(fmt.Sprintf("LOC RData: invalid size or precision: %fm", meters))
+	}
+
+	exponent := uint8(0)
+	for centimeters >= 10 {
+		centimeters /= 10
+		exponent++
+	}
+	return uint8(centimeters)<<4 | exponent, nil
+}
+
+func getLOCCoordinateString(coordinate uint32, positive string, negative string) string {
+	hemisphere := positive
+	value := int64(coordinate) - int64(locEquator)
+	if value < 0 {
+		hemisphere = negative
+		value = -value
+	}
+
+	milliseconds := value % 1000
+	value /= 1000
+	seconds := value % 60
+	value /= 60
+	minutes := value % 60
+	degrees := value / 60
+
+	return fmt.Sprintf("%d %02d %02d.%03d %s", degrees, minutes, seconds, milliseconds, hemisphere)
+}
+
+func getLOCAltitudeString(centimeters int64) string {
+	sign := ""
+	if centimeters < 0 {
+		sign = "-"
+		centimeters = -centimeters
+	}
+	return fmt.Sprintf("%s%d.%02dm", sign, centimeters/100, centimeters%100)
+}
+
+func getLOCPrecisionString(precision uint8) string {
+	centimeters := decodeLOCPrecision(precision)
+	if centimeters%100 == 0 {
+		return fmt.Sprintf("%dm", centimeters/100)
+	}
+	return fmt.Sprintf("%d.%02dm", centimeters/100, centimeters%100)
+}
+
+// -------------- CERT
+// CERT RDATA format [RFC4398]
+// TYPE:		The type of the certificate, ex. 1 for an X.509 certificate or 3 for an OpenPGP packet.
+// KEY TAG:		The key tag of the public key of the certificate, as computed for DNSKEY records, or 0.
+// ALGORITHM:	The DNSSEC algorithm of the public key of the certificate, or 0 if unknown.
+// CERTIFICATE:	The certificate or CRL, in a format that depends on the type.
+
+type RDataCERT struct {
+	Type        uint16
+	KeyTag      uint16
+	Algorithm   uint8
+	Certificate []byte
+}
+
+const (
+	CERTTypePKIX    uint16 = 1   // X.509 as per PKIX
+	CERTTypeSPKI    uint16 = 2   // SPKI certificate
+	CERTTypePGP     uint16 = 3   // OpenPGP packet
+	CERTTypeIPKIX   uint16 = 4   // The URL of an X.509 data object
+	CERTTypeISPKI   uint16 = 5   // The URL of an SPKI certificate
+	CERTTypeIPGP    uint16 = 6   // The fingerprint and URL of an OpenPGP packet
+	CERTTypeACPKIX  uint16 = 7   // Attribute Certificate
+	CERTTypeIACPKIX uint16 = 8   // The URL of an Attribute Certificate
+	CERTTypeURI     uint16 = 253 // URI private
+	CERTTypeOID     uint16 = 254 // OID private
+)
+
+var certTypeNames = map[uint16]string{
+	CERTTypePKIX:    "PKIX",
+	CERTTypeSPKI:    "SPKI",
+	CERTTypePGP:     "PGP",
+	CERTTypeIPKIX:   "IPKIX",
+	CERTTypeISPKI:   "ISPKI",
+	CERTTypeIPGP:    "IPGP",
+	CERTTypeACPKIX:  "ACPKIX",
+	CERTTypeIACPKIX: "IACPKIX",
+	CERTTypeURI:     "URI",
+	CERTTypeOID:     "OID",
+}
+
+// String returns the presentation format of the record: the certificate type's mnemonic
+// or number, the key tag, the algorithm number and the certificate in base64.
+func (rdata *RDataCERT) String() string {
+	certType, ok := certTypeNames[rdata.Type]
+	if !ok {
+		certType = strconv.Itoa(int(rdata.Type))
+	}
+
+	cert := []string{
+		certType,
+		strconv.Itoa(int(rdata.KeyTag)),
+		strconv.Itoa(int(rdata.Algorithm)),
+		base64.StdEncoding.EncodeToString(rdata.Certificate),
+	}
+
+	return strings.Join(cert, " ")
+}
+
+func (rdata *RDataCERT) WriteRecordData(writer *dnsWriter) error {
+	writer.writeUint16(rdata.Type)
+	writer.writeUint16(rdata.KeyTag)
+	writer.writeData([]byte{rdata.Algorithm})
+	writer.writeData(rdata.Certificate)
+	return nil
+}
+
+func (rdata *RDataCERT) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	if length < 5 || reader.offset+int(length) > len(reader.data) {
+		return invalidRecordDataError(fmt.Sprintf("CERT RData: invalid length: %d", length))
+	}
+
+	rdata.Type = reader.readUint16()
+	rdata.KeyTag = reader.readUint16()
+	algorithm, err := reader.readUntil(1)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("CERT RData: %s", err.Error()))
+	}
+	rdata.Algorithm = algorithm[0]
+
+	certificate, err := reader.readUntil(int(length) - 5)
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("CERT RData: %s", err.Error()))
+	}
+	rdata.Certificate = append([]byte{}, certificate...)
+	return nil
+}
+
+// -------------- OPENPGPKEY
+// OPENPGPKEY RDATA format [RFC7929]
+// PUBLIC KEY:	An OpenPGP Transferable Public Key, without ASCII armor or base64 encoding.
+
+type RDataOPENPGPKEY struct {
+	PublicKey []byte
+}
+
+func (rdata *RDataOPENPGPKEY) String() string {
+	return base64.StdEncoding.EncodeToString(rdata.PublicKey)
+}
+
+func (rdata *RDataOPENPGPKEY) WriteRecordData(writer *dnsWriter) error {
+	writer.writeData(rdata.PublicKey)
+	return nil
+}
+
+func (rdata *RDataOPENPGPKEY) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	if length < 1 {
+		return invalidRecordDataError(fmt.Sprintf("OPENPGPKEY RData: invalid length: %d", length))
+	}
+
+	publicKey, err := reader.readUntil(int(length))
+	if err != nil {
+		return invalidRecordDataError(fmt.Sprintf("OPENPGPKEY RData: %s", err.Error()))
+	}
+	rdata.PublicKey = append([]byte{}, publicKey...)
 	return nil
 }
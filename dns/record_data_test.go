@@ -3,8 +3,11 @@ package dns
 import (
 	"bytes"
 	"errors"
+	"math"
 	"net/netip"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -157,7 +160,7 @@ func TestRDataCNAME(t *testing.T) {
 			name: "CNAME record",
 			data: []byte{7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0},
 			want: &RDataCNAME{
-				domainName: "example.com.",
+				DomainName: "example.com.",
 			},
 			wantError: nil,
 		},
@@ -165,7 +168,7 @@ func TestRDataCNAME(t *testing.T) {
 			name: "Invalid CNAME record",
 			data: []byte{7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm'},
 			want: &RDataCNAME{
-				domainName: "example.com.",
+				DomainName: "example.com.",
 			},
 			wantError: ErrInvalidRecordData,
 		},
@@ -191,14 +194,14 @@ func TestRDataCNAME(t *testing.T) {
 			}
 
 			// Test Decode
-			if got.domainName != want.domainName {
-				t.Errorf("Decode() domain name got = %s, want = %s, data = %v\n", got.domainName, want.domainName, tt.data)
+			if got.DomainName != want.DomainName {
+				t.Errorf("Decode() domain name got = %s, want = %s, data = %v\n", got.DomainName, want.DomainName, tt.data)
 			}
 
 			// Test String
 			gotString := got.String()
-			if gotString != want.domainName {
-				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, want.domainName, tt.data)
+			if gotString != want.DomainName {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, want.DomainName, tt.data)
 			}
 
 			// Test Encode
@@ -217,60 +220,103 @@ func TestRDataCNAME(t *testing.T) {
 	}
 }
 
+func TestRDataDNAME(t *testing.T) {
+	data := []byte{7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'n', 'e', 't', 0}
+
+	var got RDataDNAME
+	if err := got.ReadRecordData(&dnsReader{data: data}, uint16(len(data))); err != nil || got.Target != "example.net." {
+		t.Fatalf("Decode() got = %s, error = %v, want = example.net.\n", got.Target, err)
+	}
+
+	// The target is not compressed, even when its name was written before
+	writer := &dnsWriter{compression: map[string]int{}}
+	writer.writeCompressedDomainName("example.net.")
+	if err := got.WriteRecordData(writer); err != nil {
+		t.Fatalf("Encode() error = %v\n", err)
+	}
+	if !bytes.Equal(writer.data[len(data):], data) {
+		t.Errorf("Encode() got = %v, want = %v\n", writer.data[len(data):], data)
+	}
+
+	if err := got.ReadRecordData(&dnsReader{data: data[:5]}, 5); !errors.Is(err, ErrInvalidRecordData) {
+		t.Errorf("Decode() error = %v, want error = %v\n", err, ErrInvalidRecordData)
+	}
+}
+
 func TestRDataTXT(t *testing.T) {
 	tests := []struct {
-		name      string
-		data      []byte
-		want      RData
-		wantError error
+		name       string
+		data       []byte
+		want       RData
+		wantString string
+		wantError  error
 	}{
 		{
 			name: "TXT record",
 			data: []byte{
-				't', 'e', 's', 't', // TXT data: "test"
+				4, 't', 'e', 's', 't', // TXT data: "test"
 			},
 			want: &RDataTXT{
-				text: "test",
+				Text: []string{"test"},
 			},
-			wantError: nil,
+			wantString: `"test"`,
+			wantError:  nil,
+		},
+		{
+			name: "TXT record with multiple character-strings",
+			data: []byte{
+				5, 'h', 'e', 'l', 'l', 'o', // "hello"
+				0,                                    // ""
+				7, 's', 'a', 'y', ' ', '"', 'h', '"', // "say \"h\""
+			},
+			want: &RDataTXT{
+				Text: []string{"hello", "", `say "h"`},
+			},
+			wantString: `"hello" "" "say \"h\""`,
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid TXT record: empty",
+			data:      []byte{},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Invalid TXT record: character-string past record length",
+			data:      []byte{5, 'h', 'e', 'l'},
+			wantError: ErrInvalidRecordData,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var got RDataTXT
+			got := &RDataTXT{}
 			reader := &dnsReader{data: tt.data}
 
 			err := got.ReadRecordData(reader, uint16(len(tt.data)))
 
 			if tt.wantError != nil {
 				if err == nil || !errors.Is(err, tt.wantError) {
-					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err.Error(), tt.wantError.Error(), tt.data)
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
 				}
 				return
 			}
-
-			want, ok := tt.want.(*RDataTXT)
-			if !ok {
-				t.Fatalf("want is not of type *RDataTXT, got %T", tt.want)
+			if err != nil {
+				t.Fatalf("Decode() unexpected error = %v, data = %v\n", err, tt.data)
 			}
 
 			// Test Decode
-			if got.text != want.text {
-				t.Errorf("Decode() text got = %s, want = %s, data = %v\n", got.text, want.text, tt.data)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %+v, want = %+v, data = %v\n", got, tt.want, tt.data)
 			}
 
 			// Test String
 			gotString := got.String()
-			if gotString != want.text {
-				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, want.text, tt.data)
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
 			}
 
 			// Test Encode
-			writer := &dnsWriter{
-				data:   make([]byte, 1),
-				offset: 0,
-			}
+			writer := &dnsWriter{}
 			if err := got.WriteRecordData(writer); err != nil {
 				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
 			}
@@ -296,8 +342,8 @@ func TestRDataMX(t *testing.T) {
 				3, 'm', 'x', '1', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0,
 			},
 			want: &RDataMX{
-				preference: 10,
-				domainName: "mx1.example.com.",
+				Preference: 10,
+				Exchange:   "mx1.example.com.",
 			},
 			wantError: nil,
 		},
@@ -308,8 +354,8 @@ func TestRDataMX(t *testing.T) {
 				3, 'm', 'x', '1', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm',
 			},
 			want: &RDataMX{
-				preference: 10,
-				domainName: "mx1.example.com.",
+				Preference: 10,
+				Exchange:   "mx1.example.com.",
 			},
 			wantError: ErrInvalidRecordData,
 		},
@@ -319,8 +365,8 @@ func TestRDataMX(t *testing.T) {
 				3, 'm', 'x', '1', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0,
 			},
 			want: &RDataMX{
-				preference: 10,
-				domainName: "mx1.example.com.",
+				Preference: 10,
+				Exchange:   "mx1.example.com.",
 			},
 			wantError: ErrInvalidRecordData,
 		},
@@ -346,16 +392,16 @@ func TestRDataMX(t *testing.T) {
 			}
 
 			// Test Decode
-			if got.preference != want.preference {
-				t.Errorf("Decode() preference got = %d, want = %d, data = %v\n", got.preference, want.preference, tt.data)
+			if got.Preference != want.Preference {
+				t.Errorf("Decode() preference got = %d, want = %d, data = %v\n", got.Preference, want.Preference, tt.data)
 			}
-			if got.domainName != want.domainName {
-				t.Errorf("Decode() domainName got = %s, want = %s, data = %v\n", got.domainName, want.domainName, tt.data)
+			if got.Exchange != want.Exchange {
+				t.Errorf("Decode() exchange got = %s, want = %s, data = %v\n", got.Exchange, want.Exchange, tt.data)
 			}
 
 			// Test String
 			gotString := got.String()
-			wantString := strconv.Itoa(int(want.preference)) + " " + want.domainName
+			wantString := strconv.Itoa(int(want.Preference)) + " " + want.Exchange
 			if gotString != wantString {
 				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, wantString, tt.data)
 			}
@@ -376,105 +422,1310 @@ func TestRDataMX(t *testing.T) {
 	}
 }
 
-func TestRDataSOA(t *testing.T) {
+func TestRDataSRV(t *testing.T) {
 	tests := []struct {
-		name      string
-		data      []byte
-		want      RData
-		wantError error
+		name       string
+		data       []byte
+		want       RData
+		wantString string
+		wantError  error
 	}{
 		{
-			name: "SOA record",
+			name: "SRV record",
 			data: []byte{
-				3, 'n', 's', '1', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // MName: ns1.example.com
-				5, 'a', 'd', 'm', 'i', 'n', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // RName: admin.example.com
-				0, 0, 0, 202, // Serial: 202
-				0, 0, 1, 44, // Refresh: 300
-				0, 0, 0, 100, // Retry: 100
-				0, 0, 10, 0, // Expire: 2560
-				0, 0, 1, 0, // Minimum: 256
+				0, 10, // Priority: 10
+				0, 60, // Weight: 60
+				0x13, 0xc4, // Port: 5060
+				3, 's', 'i', 'p', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0,
 			},
-			want: &RDataSOA{
-				mName:   "ns1.example.com.",
-				rName:   "admin.example.com.",
-				serial:  202,
-				refresh: 300,
-				retry:   100,
-				expire:  2560,
-				minimum: 256,
+			want: &RDataSRV{
+				Priority: 10,
+				Weight:   60,
+				Port:     5060,
+				Target:   "sip.example.com.",
 			},
-			wantError: nil,
+			wantString: "10 60 5060 sip.example.com.",
+			wantError:  nil,
 		},
 		{
-			name: "Invalid SOA record: bad domain name",
+			name: "SRV record: service not available",
+			data: []byte{0, 0, 0, 0, 0, 0, 0},
+			want: &RDataSRV{
+				Target: ".",
+			},
+			wantString: "0 0 0 .",
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid SRV record: missing target",
+			data:      []byte{0, 10, 0, 60, 0x13, 0xc4},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name: "Invalid SRV record: bad domain name",
 			data: []byte{
-				3, 'n', 's', '1', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // MName: ns1.example.com
-				5, 'a', 'd', 'm', 'i', 'n', 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // RName: admin.example.com
-				0, 0, 0, 202, // Serial: 202
-				0, 0, 1, 44, // Refresh: 300
-				0, 0, 0, 100, // Retry: 100
-				0, 0, 10, 0, // Expire: 2560
-				0, 0, 1, 0, // Minimum: 256
+				0, 10, 0, 60, 0x13, 0xc4,
+				3, 's', 'i', 'p', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e',
 			},
-			want:      &RDataSOA{},
 			wantError: ErrInvalidRecordData,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var got RDataSOA
+			got := &RDataSRV{}
 			reader := &dnsReader{data: tt.data}
 
 			err := got.ReadRecordData(reader, uint16(len(tt.data)))
 
 			if tt.wantError != nil {
 				if err == nil || !errors.Is(err, tt.wantError) {
-					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err.Error(), tt.wantError.Error(), tt.data)
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
 				}
 				return
 			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error = %v, data = %v\n", err, tt.data)
+			}
 
-			want, ok := tt.want.(*RDataSOA)
-			if !ok {
-				t.Fatalf("want is not of type *RDataSOA, got %T", tt.want)
+			// Test Decode
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %+v, want = %+v, data = %v\n", got, tt.want, tt.data)
+			}
+
+			// Test String
+			gotString := got.String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{}
+			if err := got.WriteRecordData(writer); err != nil {
+				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
+			}
+
+			if !bytes.Equal(writer.data, tt.data) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, tt.data)
+			}
+		})
+	}
+}
+
+func TestRDataHINFO(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		want       RData
+		wantString string
+		wantError  error
+	}{
+		{
+			name: "HINFO record",
+			data: []byte{
+				7, 'A', 'M', 'D', '6', '4', ' ', '8', // CPU: "AMD64 8"
+				5, 'L', 'i', 'n', 'u', 'x', // OS: "Linux"
+			},
+			want:       &RDataHINFO{CPU: "AMD64 8", OS: "Linux"},
+			wantString: "\"AMD64 8\" \"Linux\"",
+			wantError:  nil,
+		},
+		{
+			name: "HINFO record answering an ANY query",
+			data: []byte{
+				7, 'R', 'F', 'C', '8', '4', '8', '2', // CPU: "RFC8482"
+				0, // OS: ""
+			},
+			want:       &RDataHINFO{CPU: "RFC8482", OS: ""},
+			wantString: "\"RFC8482\" \"\"",
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid HINFO record: missing OS",
+			data:      []byte{3, 'x', '8', '6'},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Invalid HINFO record: character-string past record length",
+			data:      []byte{3, 'x', '8', '6', 5, 'L', 'i'},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Invalid HINFO record: trailing data",
+			data:      []byte{0, 0, 0},
+			wantError: ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &RDataHINFO{}
+			reader := &dnsReader{data: tt.data}
+
+			err := got.ReadRecordData(reader, uint16(len(tt.data)))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test Decode
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %+v, want = %+v, data = %v\n", got, tt.want, tt.data)
+			}
+
+			// Test String
+			gotString := got.String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{}
+			if err := got.WriteRecordData(writer); err != nil {
+				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
+			}
+
+			if !bytes.Equal(writer.data, tt.data) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, tt.data)
+			}
+		})
+	}
+}
+
+func TestRDataNAPTR(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		want       RData
+		wantString string
+		wantError  error
+	}{
+		{
+			name: "NAPTR record with replacement",
+			data: []byte{
+				0, 100, // Order: 100
+				0, 10, // Preference: 10
+				1, 'S', // Flags: "S"
+				7, 'S', 'I', 'P', '+', 'D', '2', 'U', // Services: "SIP+D2U"
+				0, // Regexp: ""
+				4, '_', 's', 'i', 'p', 4, '_', 'u', 'd', 'p', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0,
+			},
+			want: &RDataNAPTR{
+				Order:       100,
+				Preference:  10,
+				Flags:       "S",
+				Services:    "SIP+D2U",
+				Regexp:      "",
+				Replacement: "_sip._udp.example.com.",
+			},
+			wantString: "100 10 \"S\" \"SIP+D2U\" \"\" _sip._udp.example.com.",
+			wantError:  nil,
+		},
+		{
+			name: "NAPTR record with regexp",
+			data: append(append([]byte{
+				0, 100, // Order: 100
+				0, 10, // Preference: 10
+				1, 'u', // Flags: "u"
+				7, 'E', '2', 'U', '+', 's', 'i', 'p', // Services: "E2U+sip"
+				28, // Regexp length
+			}, `!^.*$!sip:info\@example.com!`...),
+				0, // Replacement: .
+			),
+			want: &RDataNAPTR{
+				Order:       100,
+				Preference:  10,
+				Flags:       "u",
+				Services:    "E2U+sip",
+				Regexp:      `!^.*$!sip:info\@example.com!`,
+				Replacement: ".",
+			},
+			wantString: `100 10 "u" "E2U+sip" "!^.*$!sip:info\\@example.com!" .`,
+			wantError:  nil,
+		},
+		{
+			name: "Invalid NAPTR record: character-string exceeds record length",
+			data: []byte{
+				0, 100, 0, 10,
+				1, 'S',
+				20, 'S', 'I', 'P',
+			},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name: "Invalid NAPTR record: missing replacement",
+			data: []byte{
+				0, 100, 0, 10,
+				1, 'S',
+				0,
+				0,
+			},
+			wantError: ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &RDataNAPTR{}
+			reader := &dnsReader{data: tt.data}
+
+			err := got.ReadRecordData(reader, uint16(len(tt.data)))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error = %v, data = %v\n", err, tt.data)
 			}
 
 			// Test Decode
-			if got.mName != want.mName {
-				t.Errorf("Decode() mName got = %s, want = %s, data = %v\n", got.mName, want.mName, tt.data)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %+v, want = %+v, data = %v\n", got, tt.want, tt.data)
 			}
-			if got.rName != want.rName {
-				t.Errorf("Decode() rName got = %s, want = %s, data = %v\n", got.rName, want.rName, tt.data)
+
+			// Test String
+			gotString := got.String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
 			}
-			if got.serial != want.serial {
-				t.Errorf("Decode() serial got = %d, want = %d, data = %v\n", got.serial, want.serial, tt.data)
+
+			// Test Encode
+			writer := &dnsWriter{}
+			if err := got.WriteRecordData(writer); err != nil {
+				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
 			}
-			if got.refresh != want.refresh {
-				t.Errorf("Decode() refresh got = %d, want = %d, data = %v\n", got.refresh, want.refresh, tt.data)
+
+			if !bytes.Equal(writer.data, tt.data) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, tt.data)
 			}
-			if got.retry != want.retry {
-				t.Errorf("Decode() retry got = %d, want = %d, data = %v\n", got.retry, want.retry, tt.data)
+		})
+	}
+}
+
+func TestWriteCharacterStringTooLong(t *testing.T) {
+	rdata := &RDataNAPTR{Regexp: strings.Repeat("a", 256), Replacement: "."}
+	if err := rdata.WriteRecordData(&dnsWriter{}); !errors.Is(err, ErrInvalidRecordData) {
+		t.Errorf("Encode() error = %v, want = %v\n", err, ErrInvalidRecordData)
+	}
+}
+
+func TestRDataTLSA(t *testing.T) {
+	digest := []byte{
+		0x92, 0x00, 0x3b, 0xa3, 0x49, 0x42, 0xdc, 0x74, 0x15, 0x2e, 0x2f, 0x2c, 0x40, 0x8d, 0x29, 0xec,
+		0xa5, 0xa5, 0x20, 0xe7, 0xf2, 0xe0, 0x6b, 0xb9, 0x44, 0xf4, 0xdc, 0xa3, 0x46, 0xba, 0xf6, 0x3c,
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		want       RData
+		wantString string
+		wantError  error
+	}{
+		{
+			name: "TLSA record",
+			data: append([]byte{
+				3, // Usage: 3 (DANE-EE)
+				1, // Selector: 1 (SPKI)
+				1, // Matching type: 1 (SHA-256)
+			}, digest...),
+			want: &RDataTLSA{
+				Usage:        TLSAUsageDANEEE,
+				Selector:     TLSASelectorSPKI,
+				MatchingType: TLSAMatchingSHA256,
+				Data:         digest,
+			},
+			wantString: "3 1 1 92003BA34942DC74152E2F2C408D29ECA5A520E7F2E06BB944F4DCA346BAF63C",
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid TLSA record: too short",
+			data:      []byte{3, 1},
+			wantError: ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &RDataTLSA{}
+			reader := &dnsReader{data: tt.data}
+
+			err := got.ReadRecordData(reader, uint16(len(tt.data)))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
 			}
-			if got.expire != want.expire {
-				t.Errorf("Decode() expire got = %d, want = %d, data = %v\n", got.expire, want.expire, tt.data)
+			if err != nil {
+				t.Fatalf("Decode() unexpected error = %v, data = %v\n", err, tt.data)
 			}
-			if got.minimum != want.minimum {
-				t.Errorf("Decode() minimum got = %d, want = %d, data = %v\n", got.minimum, want.minimum, tt.data)
+
+			// Test Decode
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %+v, want = %+v, data = %v\n", got, tt.want, tt.data)
 			}
 
 			// Test String
 			gotString := got.String()
-			wantString := want.mName + " " + want.rName + " " + strconv.Itoa(int(want.serial)) + " " + strconv.Itoa(int(want.refresh)) + " " + strconv.Itoa(int(want.retry)) + " " + strconv.Itoa(int(want.expire)) + " " + strconv.Itoa(int(want.minimum))
-			if gotString != wantString {
-				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, wantString, tt.data)
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
 			}
 
 			// Test Encode
-			writer := &dnsWriter{
-				data:   make([]byte, 1),
-				offset: 0,
+			writer := &dnsWriter{}
+			if err := got.WriteRecordData(writer); err != nil {
+				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
+			}
+
+			if !bytes.Equal(writer.data, tt.data) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, tt.data)
+			}
+		})
+	}
+}
+
+func TestRDataOPENPGPKEY(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		want       RData
+		wantString string
+		wantError  error
+	}{
+		{
+			name:       "OPENPGPKEY record",
+			data:       []byte{0x99, 0x01, 0x0d, 0x04, 0x5a},
+			want:       &RDataOPENPGPKEY{PublicKey: []byte{0x99, 0x01, 0x0d, 0x04, 0x5a}},
+			wantString: "mQENBFo=",
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid OPENPGPKEY record: empty",
+			data:      []byte{},
+			wantError: ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &RDataOPENPGPKEY{}
+			reader := &dnsReader{data: tt.data}
+
+			err := got.ReadRecordData(reader, uint16(len(tt.data)))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test Decode
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %+v, want = %+v, data = %v\n", got, tt.want, tt.data)
 			}
+
+			// Test String
+			gotString := got.String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{}
+			if err := got.WriteRecordData(writer); err != nil {
+				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
+			}
+
+			if !bytes.Equal(writer.data, tt.data) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, tt.data)
+			}
+		})
+	}
+}
+
+func TestRDataCSYNC(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		want       RData
+		wantString string
+		wantError  error
+	}{
+		{
+			name: "CSYNC record",
+			data: []byte{
+				0, 0, 0, 66, // SOA serial: 66
+				0, 3, // Flags: immediate, soaminimum
+				0, 4, 0x60, 0x00, 0x00, 0x08, // Window 0: A, NS, AAAA
+			},
+			want: &RDataCSYNC{
+				Serial: 66,
+				Flags:  CSYNCFlagImmediate | CSYNCFlagSOAMinimum,
+				Types:  []uint16{A, NS, AAAA},
+			},
+			wantString: "66 3 A NS AAAA",
+			wantError:  nil,
+		},
+		{
+			name: "CSYNC record without types",
+			data: []byte{
+				0xff, 0xff, 0xff, 0xff, // SOA serial: 4294967295
+				0, 0, // Flags: none
+			},
+			want: &RDataCSYNC{
+				Serial: 4294967295,
+				Flags:  0,
+				Types:  nil,
+			},
+			wantString: "4294967295 0",
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid CSYNC record: too short",
+			data:      []byte{0, 0, 0, 66, 0},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Invalid CSYNC record: invalid type bit map",
+			data:      []byte{0, 0, 0, 66, 0, 3, 0, 0},
+			wantError: ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &RDataCSYNC{}
+			reader := &dnsReader{data: tt.data}
+
+			err := got.ReadRecordData(reader, uint16(len(tt.data)))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test Decode
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %+v, want = %+v, data = %v\n", got, tt.want, tt.data)
+			}
+
+			// Test String
+			gotString := got.String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{}
+			if err := got.WriteRecordData(writer); err != nil {
+				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
+			}
+
+			if !bytes.Equal(writer.data, tt.data) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, tt.data)
+			}
+		})
+	}
+}
+
+func TestRDataCERT(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		want       RData
+		wantString string
+		wantError  error
+	}{
+		{
+			name: "CERT record",
+			data: []byte{
+				0, 3, // Type: 3 (PGP)
+				0x30, 0x39, // Key tag: 12345
+				8,                // Algorithm: 8 (RSASHA256)
+				0x99, 0x01, 0x0d, // Certificate
+			},
+			want: &RDataCERT{
+				Type:        CERTTypePGP,
+				KeyTag:      12345,
+				Algorithm:   8,
+				Certificate: []byte{0x99, 0x01, 0x0d},
+			},
+			wantString: "PGP 12345 8 mQEN",
+			wantError:  nil,
+		},
+		{
+			name: "CERT record with unknown type",
+			data: []byte{
+				0, 100, // Type: 100
+				0, 0, // Key tag: 0
+				0,    // Algorithm: 0
+				0xff, // Certificate
+			},
+			want: &RDataCERT{
+				Type:        100,
+				KeyTag:      0,
+				Algorithm:   0,
+				Certificate: []byte{0xff},
+			},
+			wantString: "100 0 0 /w==",
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid CERT record: too short",
+			data:      []byte{0, 1, 0, 0},
+			wantError: ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &RDataCERT{}
+			reader := &dnsReader{data: tt.data}
+
+			err := got.ReadRecordData(reader, uint16(len(tt.data)))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test Decode
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %+v, want = %+v, data = %v\n", got, tt.want, tt.data)
+			}
+
+			// Test String
+			gotString := got.String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{}
+			if err := got.WriteRecordData(writer); err != nil {
+				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
+			}
+
+			if !bytes.Equal(writer.data, tt.data) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, tt.data)
+			}
+		})
+	}
+}
+
+func TestRDataLOC(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		want       RData
+		wantString string
+		wantError  error
+	}{
+		{
+			name: "LOC record",
+			data: []byte{
+				0,                      // Version: 0
+				0x33,                   // Size: 3 * 10^3cm (30m)
+				0x16,                   // Horizontal precision: 1 * 10^6cm (10,000m)
+				0x13,                   // Vertical precision: 1 * 10^3cm (10m)
+				0x89, 0x17, 0x2d, 0xd0, // Latitude: 2^31 + 152,514,000 (42 21 54 N)
+				0x70, 0xbe, 0x15, 0xf0, // Longitude: 2^31 - 255,978,000 (71 06 18 W)
+				0x00, 0x98, 0x8d, 0x20, // Altitude: 10,000,000 - 2,400 (-24m)
+			},
+			want: &RDataLOC{
+				Version:             0,
+				Size:                0x33,
+				HorizontalPrecision: 0x16,
+				VerticalPrecision:   0x13,
+				Latitude:            2299997648,
+				Longitude:           1891505648,
+				Altitude:            9997600,
+			},
+			wantString: "42 21 54.000 N 71 06 18.000 W -24.00m 30m 10000m 10m",
+			wantError:  nil,
+		},
+		{
+			name: "LOC record south east with sub-meter values",
+			data: []byte{
+				0,                      // Version: 0
+				0x12,                   // Size: 1 * 10^2cm (1m)
+				0x51,                   // Horizontal precision: 5 * 10^1cm (0.5m)
+				0x20,                   // Vertical precision: 2 * 10^0cm (0.02m)
+				0x78, 0xa4, 0x34, 0xd4, // Latitude: 2^31 - 123,456,300 (34 17 36.300 S)
+				0x80, 0x00, 0x00, 0x01, // Longitude: 2^31 + 1 (0 00 00.001 E)
+				0x00, 0x98, 0x96, 0xf5, // Altitude: 10,000,000 + 117 (1.17m)
+			},
+			want: &RDataLOC{
+				Version:             0,
+				Size:                0x12,
+				HorizontalPrecision: 0x51,
+				VerticalPrecision:   0x20,
+				Latitude:            2024027348,
+				Longitude:           2147483649,
+				Altitude:            10000117,
+			},
+			wantString: "34 17 36.300 S 0 00 00.001 E 1.17m 1m 0.50m 0.02m",
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid LOC record: unsupported version",
+			data:      []byte{1, 0x12, 0x16, 0x13, 0x80, 0, 0, 0, 0x80, 0, 0, 0, 0, 0x98, 0x96, 0x80},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Invalid LOC record: precision digit above 9",
+			data:      []byte{0, 0x1a, 0x16, 0x13, 0x80, 0, 0, 0, 0x80, 0, 0, 0, 0, 0x98, 0x96, 0x80},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Invalid LOC record: too short",
+			data:      []byte{0, 0x12, 0x16, 0x13, 0x80, 0, 0, 0},
+			wantError: ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &RDataLOC{}
+			reader := &dnsReader{data: tt.data}
+
+			err := got.ReadRecordData(reader, uint16(len(tt.data)))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test Decode
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %+v, want = %+v, data = %v\n", got, tt.want, tt.data)
+			}
+
+			// Test String
+			gotString := got.String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{}
+			if err := got.WriteRecordData(writer); err != nil {
+				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
+			}
+
+			if !bytes.Equal(writer.data, tt.data) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, tt.data)
+			}
+		})
+	}
+}
+
+func TestNewRDataLOC(t *testing.T) {
+	latitude := 42 + 21.0/60 + 54.0/3600
+	longitude := -(71 + 6.0/60 + 18.0/3600)
+
+	got, err := NewRDataLOC(latitude, longitude, -24, 30, 10000, 10)
+	if err != nil {
+		t.Fatalf("NewRDataLOC() unexpected error = %v\n", err)
+	}
+	want := &RDataLOC{Size: 0x33, HorizontalPrecision: 0x16, VerticalPrecision: 0x13, Latitude: 2299997648, Longitude: 1891505648, Altitude: 9997600}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewRDataLOC() got = %+v, want = %+v\n", got, want)
+	}
+
+	if math.Abs(got.LatitudeDegrees()-latitude) > 1e-9 || math.Abs(got.LongitudeDegrees()-longitude) > 1e-9 || got.AltitudeMeters() != -24 {
+		t.Errorf("NewRDataLOC() got coordinates = %f %f %fm\n", got.LatitudeDegrees(), got.LongitudeDegrees(), got.AltitudeMeters())
+	}
+
+	// Sizes are rounded down to a single significant digit
+	got, err = NewRDataLOC(0, 0, 0, 1234, 0.57, 0)
+	if err != nil {
+		t.Fatalf("NewRDataLOC() unexpected error = %v\n", err)
+	}
+	size, horizontalPrecision, verticalPrecision := got.SizeMeters()
+	if size != 1000 || horizontalPrecision != 0.5 || verticalPrecision != 0 {
+		t.Errorf("NewRDataLOC() got sizes = %fm %fm %fm, want = 1000m 0.5m 0m\n", size, horizontalPrecision, verticalPrecision)
+	}
+
+	if _, err := NewRDataLOC(91, 0, 0, 1, 1, 1); !errors.Is(err, ErrInvalidRecordData) {
+		t.Errorf("NewRDataLOC() error = %v, want error = %v\n", err, ErrInvalidRecordData)
+	}
+	if _, err := NewRDataLOC(0, 0, -100001, 1, 1, 1); !errors.Is(err, ErrInvalidRecordData) {
+		t.Errorf("NewRDataLOC() error = %v, want error = %v\n", err, ErrInvalidRecordData)
+	}
+}
+
+func TestRDataURI(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		want       RData
+		wantString string
+		wantError  error
+	}{
+		{
+			name: "URI record",
+			data: append([]byte{
+				0, 10, // Priority: 10
+				0, 1, // Weight: 1
+			}, "ftp://ftp1.example.com/public"...),
+			want: &RDataURI{
+				Priority: 10,
+				Weight:   1,
+				Target:   "ftp://ftp1.example.com/public",
+			},
+			wantString: "10 1 \"ftp://ftp1.example.com/public\"",
+			wantError:  nil,
+		},
+		{
+			name: "URI record with characters to escape",
+			data: append([]byte{
+				0, 1, // Priority: 1
+				0, 0, // Weight: 0
+			}, "https://example.com/?q=\"a b\""...),
+			want: &RDataURI{
+				Priority: 1,
+				Weight:   0,
+				Target:   "https://example.com/?q=\"a b\"",
+			},
+			wantString: `1 0 "https://example.com/?q=\"a b\""`,
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid URI record: empty target",
+			data:      []byte{0, 10, 0, 1},
+			wantError: ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &RDataURI{}
+			reader := &dnsReader{data: tt.data}
+
+			err := got.ReadRecordData(reader, uint16(len(tt.data)))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test Decode
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %+v, want = %+v, data = %v\n", got, tt.want, tt.data)
+			}
+
+			// Test String
+			gotString := got.String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{}
+			if err := got.WriteRecordData(writer); err != nil {
+				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
+			}
+
+			if !bytes.Equal(writer.data, tt.data) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, tt.data)
+			}
+		})
+	}
+}
+
+func TestRDataSOA(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		want      RData
+		wantError error
+	}{
+		{
+			name: "SOA record",
+			data: []byte{
+				3, 'n', 's', '1', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // MName: ns1.example.com
+				5, 'a', 'd', 'm', 'i', 'n', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // RName: admin.example.com
+				0, 0, 0, 202, // Serial: 202
+				0, 0, 1, 44, // Refresh: 300
+				0, 0, 0, 100, // Retry: 100
+				0, 0, 10, 0, // Expire: 2560
+				0, 0, 1, 0, // Minimum: 256
+			},
+			want: &RDataSOA{
+				MName:   "ns1.example.com.",
+				RName:   "admin.example.com.",
+				Serial:  202,
+				Refresh: 300,
+				Retry:   100,
+				Expire:  2560,
+				Minimum: 256,
+			},
+			wantError: nil,
+		},
+		{
+			name: "Invalid SOA record: bad domain name",
+			data: []byte{
+				3, 'n', 's', '1', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // MName: ns1.example.com
+				5, 'a', 'd', 'm', 'i', 'n', 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // RName: admin.example.com
+				0, 0, 0, 202, // Serial: 202
+				0, 0, 1, 44, // Refresh: 300
+				0, 0, 0, 100, // Retry: 100
+				0, 0, 10, 0, // Expire: 2560
+				0, 0, 1, 0, // Minimum: 256
+			},
+			want:      &RDataSOA{},
+			wantError: ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got RDataSOA
+			reader := &dnsReader{data: tt.data}
+
+			err := got.ReadRecordData(reader, uint16(len(tt.data)))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err.Error(), tt.wantError.Error(), tt.data)
+				}
+				return
+			}
+
+			want, ok := tt.want.(*RDataSOA)
+			if !ok {
+				t.Fatalf("want is not of type *RDataSOA, got %T", tt.want)
+			}
+
+			// Test Decode
+			if got.MName != want.MName {
+				t.Errorf("Decode() MName got = %s, want = %s, data = %v\n", got.MName, want.MName, tt.data)
+			}
+			if got.RName != want.RName {
+				t.Errorf("Decode() RName got = %s, want = %s, data = %v\n", got.RName, want.RName, tt.data)
+			}
+			if got.Serial != want.Serial {
+				t.Errorf("Decode() serial got = %d, want = %d, data = %v\n", got.Serial, want.Serial, tt.data)
+			}
+			if got.Refresh != want.Refresh {
+				t.Errorf("Decode() refresh got = %d, want = %d, data = %v\n", got.Refresh, want.Refresh, tt.data)
+			}
+			if got.Retry != want.Retry {
+				t.Errorf("Decode() retry got = %d, want = %d, data = %v\n", got.Retry, want.Retry, tt.data)
+			}
+			if got.Expire != want.Expire {
+				t.Errorf("Decode() expire got = %d, want = %d, data = %v\n", got.Expire, want.Expire, tt.data)
+			}
+			if got.Minimum != want.Minimum {
+				t.Errorf("Decode() minimum got = %d, want = %d, data = %v\n", got.Minimum, want.Minimum, tt.data)
+			}
+
+			// Test String
+			gotString := got.String()
+			wantString := want.MName + " " + want.RName + " " + strconv.Itoa(int(want.Serial)) + " " + strconv.Itoa(int(want.Refresh)) + " " + strconv.Itoa(int(want.Retry)) + " " + strconv.Itoa(int(want.Expire)) + " " + strconv.Itoa(int(want.Minimum))
+			if gotString != wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, wantString, tt.data)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{
+				data:   make([]byte, 1),
+				offset: 0,
+			}
+			if err := got.WriteRecordData(writer); err != nil {
+				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
+			}
+
+			if !bytes.Equal(writer.data, tt.data) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, tt.data)
+			}
+		})
+	}
+}
+
+func TestRDataRRSIG(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		want       RData
+		wantString string
+		wantError  error
+	}{
+		{
+			name: "RRSIG record",
+			data: []byte{
+				0, 1, // Type covered: 1 (A)
+				13,          // Algorithm: 13 (ECDSAP256SHA256)
+				2,           // Labels: 2
+				0, 0, 1, 44, // Original TTL: 300
+				0x65, 0x92, 0x00, 0x80, // Expiration: 1704067200 (2024-01-01 00:00:00 UTC)
+				0x65, 0x69, 0x22, 0x00, // Inception: 1701388800 (2023-12-01 00:00:00 UTC)
+				0x30, 0x39, // Key tag: 12345
+				7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // Signer's name: example.com.
+				0xde, 0xad, 0xbe, 0xef, // Signature
+			},
+			want: &RDataRRSIG{
+				TypeCovered: A,
+				Algorithm:   13,
+				Labels:      2,
+				OriginalTTL: 300,
+				Expiration:  1704067200,
+				Inception:   1701388800,
+				KeyTag:      12345,
+				SignerName:  "example.com.",
+				Signature:   []byte{0xde, 0xad, 0xbe, 0xef},
+			},
+			wantString: "A 13 2 300 20240101000000 20231201000000 12345 example.com. 3q2+7w==",
+			wantError:  nil,
+		},
+		{
+			name: "Invalid RRSIG record: too short",
+			data: []byte{
+				0, 1, // Type covered: 1 (A)
+				13, 2, // Algorithm, labels
+				0, 0, 1, 44, // Original TTL: 300
+			},
+			want:      &RDataRRSIG{},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name: "Invalid RRSIG record: bad signer's name",
+			data: []byte{
+				0, 1, 13, 2,
+				0, 0, 1, 44,
+				0x65, 0x92, 0x00, 0x80,
+				0x65, 0x69, 0x22, 0x00,
+				0x30, 0x39,
+				7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', // Missing terminating 0
+			},
+			want:      &RDataRRSIG{},
+			wantError: ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got RDataRRSIG
+			reader := &dnsReader{data: tt.data}
+
+			err := got.ReadRecordData(reader, uint16(len(tt.data)))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test Decode
+			if !reflect.DeepEqual(&got, tt.want) {
+				t.Errorf("Decode() got = %+v, want = %+v, data = %v\n", got, tt.want, tt.data)
+			}
+
+			// Test String
+			gotString := got.String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{
+				data:   make([]byte, 1),
+				offset: 0,
+			}
+			if err := got.WriteRecordData(writer); err != nil {
+				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
+			}
+
+			if !bytes.Equal(writer.data, tt.data) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, tt.data)
+			}
+		})
+	}
+}
+
+func TestRDataDNSKEY(t *testing.T) {
+	publicKey := []byte{0xde, 0xad, 0xbe, 0xef}
+	for i := byte(1); i <= 28; i++ {
+		publicKey = append(publicKey, i)
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		want       RData
+		wantString string
+		wantKeyTag uint16
+		wantError  error
+	}{
+		{
+			name: "DNSKEY record",
+			data: append([]byte{
+				1, 1, // Flags: 257 (Zone, Secure Entry Point)
+				3,  // Protocol: 3
+				15, // Algorithm: 15 (ED25519)
+			}, publicKey...),
+			want: &RDataDNSKEY{
+				Flags:     DNSKEYFlagZone | DNSKEYFlagSecureEntryPoint,
+				Protocol:  DNSKEYProtocol,
+				Algorithm: ED25519,
+				PublicKey: publicKey,
+			},
+			wantString: "257 3 15 3q2+7wECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxw=",
+			wantKeyTag: 26240,
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid DNSKEY record: too short",
+			data:      []byte{1, 1, 3},
+			want:      &RDataDNSKEY{},
+			wantError: ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got RDataDNSKEY
+			reader := &dnsReader{data: tt.data}
+
+			err := got.ReadRecordData(reader, uint16(len(tt.data)))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test Decode
+			if !reflect.DeepEqual(&got, tt.want) {
+				t.Errorf("Decode() got = %+v, want = %+v, data = %v\n", got, tt.want, tt.data)
+			}
+
+			// Test String
+			gotString := got.String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test KeyTag
+			if gotKeyTag := got.KeyTag(); gotKeyTag != tt.wantKeyTag {
+				t.Errorf("KeyTag() got = %d, want = %d\n", gotKeyTag, tt.wantKeyTag)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{
+				data:   make([]byte, 1),
+				offset: 0,
+			}
+			if err := got.WriteRecordData(writer); err != nil {
+				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
+			}
+
+			if !bytes.Equal(writer.data, tt.data) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, tt.data)
+			}
+		})
+	}
+}
+
+func TestRDataDNSSEC(t *testing.T) {
+	nsec3Hash := []byte{0x17, 0x4e, 0xb2, 0x40, 0x9f, 0xe2, 0x8b, 0xcb, 0x48, 0x87, 0xa1, 0x83, 0x6f, 0x95, 0x7f, 0x0a, 0x84, 0x25, 0xe2, 0x7b}
+	dsDigest := []byte{0x2b, 0xb1, 0x83, 0xaf, 0x5f, 0x22, 0x58, 0x81, 0x79, 0xa5, 0x3b, 0x0a, 0x98, 0x63, 0x1f, 0xad, 0x1a, 0x29, 0x21, 0x18}
+
+	tests := []struct {
+		name       string
+		rdata      RData
+		data       []byte
+		want       RData
+		wantString string
+		wantError  error
+	}{
+		{
+			name:  "DS record",
+			rdata: &RDataDS{},
+			data: append([]byte{
+				0xec, 0x45, // Key tag: 60485
+				5, // Algorithm: 5 (RSASHA1)
+				1, // Digest type: 1 (SHA-1)
+			}, dsDigest...),
+			want: &RDataDS{
+				KeyTag:     60485,
+				Algorithm:  RSASHA1,
+				DigestType: DSDigestSHA1,
+				Digest:     dsDigest,
+			},
+			wantString: "60485 5 1 2BB183AF5F22588179A53B0A98631FAD1A292118",
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid DS record: too short",
+			rdata:     &RDataDS{},
+			data:      []byte{0xec, 0x45, 5},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:  "NSEC record",
+			rdata: &RDataNSEC{},
+			data: []byte{
+				4, 'h', 'o', 's', 't', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // Next domain name: host.example.com.
+				0, 6, 0x40, 0x01, 0x00, 0x00, 0x00, 0x03, // Window 0: A, MX, RRSIG, NSEC
+				4, 27, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x20, // Window 4: TYPE1234
+			},
+			want: &RDataNSEC{
+				NextDomainName: "host.example.com.",
+				Types:          []uint16{A, MX, RRSIG, NSEC, 1234},
+			},
+			wantString: "host.example.com. A MX RRSIG NSEC TYPE1234",
+			wantError:  nil,
+		},
+		{
+			name:  "Invalid NSEC record: windows out of order",
+			rdata: &RDataNSEC{},
+			data: []byte{
+				0,
+				4, 1, 0x20,
+				0, 1, 0x40,
+			},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:  "Invalid NSEC record: bitmap longer than 32 bytes",
+			rdata: &RDataNSEC{},
+			data: append([]byte{
+				0,
+				0, 33,
+			}, make([]byte, 33)...),
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:  "NSEC3 record",
+			rdata: &RDataNSEC3{},
+			data: append(append([]byte{
+				1,     // Hash algorithm: 1 (SHA-1)
+				1,     // Flags: Opt-Out
+				0, 12, // Iterations: 12
+				4, 0xaa, 0xbb, 0xcc, 0xdd, // Salt
+				20, // Hash length
+			}, nsec3Hash...),
+				0, 7, 0x22, 0x01, 0x00, 0x00, 0x00, 0x02, 0x90, // Window 0: NS, SOA, MX, RRSIG, DNSKEY, NSEC3PARAM
+			),
+			want: &RDataNSEC3{
+				HashAlgorithm:       NSEC3HashSHA1,
+				Flags:               NSEC3FlagOptOut,
+				Iterations:          12,
+				Salt:                []byte{0xaa, 0xbb, 0xcc, 0xdd},
+				NextHashedOwnerName: nsec3Hash,
+				Types:               []uint16{NS, SOA, MX, RRSIG, DNSKEY, NSEC3PARAM},
+			},
+			wantString: "1 1 12 AABBCCDD 2T7B4G4VSA5SMI47K61MV5BV1A22BOJR NS SOA MX RRSIG DNSKEY NSEC3PARAM",
+			wantError:  nil,
+		},
+		{
+			name:  "Invalid NSEC3 record: salt exceeds record length",
+			rdata: &RDataNSEC3{},
+			data: []byte{
+				1, 0, 0, 0,
+				8, 0xaa, 0xbb,
+			},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:  "NSEC3PARAM record without salt",
+			rdata: &RDataNSEC3PARAM{},
+			data: []byte{
+				1,    // Hash algorithm: 1 (SHA-1)
+				0,    // Flags
+				0, 0, // Iterations: 0
+				0, // Salt length: 0
+			},
+			want: &RDataNSEC3PARAM{
+				HashAlgorithm: NSEC3HashSHA1,
+				Salt:          []byte{},
+			},
+			wantString: "1 0 0 -",
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid NSEC3PARAM record: trailing data",
+			rdata:     &RDataNSEC3PARAM{},
+			data:      []byte{1, 0, 0, 0, 0, 0xff},
+			wantError: ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rdata
+			reader := &dnsReader{data: tt.data}
+
+			err := got.ReadRecordData(reader, uint16(len(tt.data)))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test Decode
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %+v, want = %+v, data = %v\n", got, tt.want, tt.data)
+			}
+
+			// Test String
+			gotString := got.String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{}
 			if err := got.WriteRecordData(writer); err != nil {
 				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
 			}
@@ -1,8 +1,10 @@
 package dns
 
 import (
+	"bytes"
 	"errors"
 	"net/netip"
+	"reflect"
 	"testing"
 )
 
@@ -74,7 +76,7 @@ func TestDecodeResourceRecord(t *testing.T) {
 				TTL:      300,
 				RDLength: 13,
 				RData: &RDataCNAME{
-					domainName: "example.com.",
+					DomainName: "example.com.",
 				},
 			},
 			wantError: nil,
@@ -96,7 +98,7 @@ func TestDecodeResourceRecord(t *testing.T) {
 				TTL:      300,
 				RDLength: 13,
 				RData: &RDataPTR{
-					domainName: "example.com.",
+					DomainName: "example.com.",
 				},
 			},
 			wantError: nil,
@@ -118,7 +120,7 @@ func TestDecodeResourceRecord(t *testing.T) {
 				TTL:      300,
 				RDLength: 16,
 				RData: &RDataNS{
-					domainName: "ns.example.com.",
+					DomainName: "ns.example.com.",
 				},
 			},
 			wantError: nil,
@@ -130,17 +132,43 @@ func TestDecodeResourceRecord(t *testing.T) {
 				0, 16, // RType: 16 (TXT)
 				0, 1, // RClass: 1
 				0, 0, 1, 44, // TTL: 300
-				0, 10, // RDLength: 10
-				'h', 'e', 'l', 'l', 'o', 'w', 'o', 'r', 'l', 'd', // RData: helloworld
+				0, 11, // RDLength: 11
+				10, 'h', 'e', 'l', 'l', 'o', 'w', 'o', 'r', 'l', 'd', // RData: "helloworld"
 			},
 			want: ResourceRecord{
 				Name:     "www.example.com.",
 				RType:    TXT,
 				RClass:   IN,
 				TTL:      300,
-				RDLength: 10,
+				RDLength: 11,
 				RData: &RDataTXT{
-					text: "helloworld",
+					Text: []string{"helloworld"},
+				},
+			},
+			wantError: nil,
+		},
+		{
+			name: "SMIMEA record",
+			data: []byte{
+				4, 'h', 'u', 'g', 'h', 10, '_', 's', 'm', 'i', 'm', 'e', 'c', 'e', 'r', 't', 0, // Name: hugh._smimecert.
+				0, 53, // RType: 53 (SMIMEA)
+				0, 1, // RClass: 1
+				0, 0, 1, 44, // TTL: 300
+				0, 5, // RDLength: 5
+				3, 0, 0, // Usage: 3 (DANE-EE), selector: 0 (certificate), matching type: 0 (full)
+				0x30, 0x82, // RData: certificate
+			},
+			want: ResourceRecord{
+				Name:     "hugh._smimecert.",
+				RType:    SMIMEA,
+				RClass:   IN,
+				TTL:      300,
+				RDLength: 5,
+				RData: &RDataTLSA{
+					Usage:        TLSAUsageDANEEE,
+					Selector:     TLSASelectorCert,
+					MatchingType: TLSAMatchingFull,
+					Data:         []byte{0x30, 0x82},
 				},
 			},
 			wantError: nil,
@@ -163,8 +191,8 @@ func TestDecodeResourceRecord(t *testing.T) {
 				TTL:      300,
 				RDLength: 20,
 				RData: &RDataMX{
-					preference: 10,
-					domainName: "mail.example.com.",
+					Preference: 10,
+					Exchange:   "mail.example.com.",
 				},
 			},
 			wantError: nil,
@@ -176,7 +204,7 @@ func TestDecodeResourceRecord(t *testing.T) {
 				0, 6, // RType: 6 (SOA)
 				0, 1, // RClass: 1 (IN)
 				0, 0, 1, 44, // TTL: 300
-				0, 39, // RDLength: 39
+				0, 56, // RDLength: 56
 				3, 'n', 's', '1', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // MName: ns1.example.com
 				5, 'a', 'd', 'm', 'i', 'n', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // RName: admin.example.com
 				0, 0, 0, 202, // Serial: 202
@@ -190,15 +218,15 @@ func TestDecodeResourceRecord(t *testing.T) {
 				RType:    SOA,
 				RClass:   IN,
 				TTL:      300,
-				RDLength: 39,
+				RDLength: 56,
 				RData: &RDataSOA{
-					mName:   "ns1.example.com.",
-					rName:   "admin.example.com.",
-					serial:  202,
-					refresh: 300,
-					retry:   100,
-					expire:  2560,
-					minimum: 256,
+					MName:   "ns1.example.com.",
+					RName:   "admin.example.com.",
+					Serial:  202,
+					Refresh: 300,
+					Retry:   100,
+					Expire:  2560,
+					Minimum: 256,
 				},
 			},
 			wantError: nil,
@@ -246,7 +274,7 @@ func TestDecodeResourceRecord(t *testing.T) {
 				TTL:      300,
 				RDLength: 5,
 				RData: &RDataUnknown{
-					raw: []byte{'h', 'e', 'l', 'l', 'o'},
+					Data: []byte{'h', 'e', 'l', 'l', 'o'},
 				},
 			},
 		},
@@ -290,3 +318,108 @@ func assertRessourceRecord(t *testing.T, got ResourceRecord, want ResourceRecord
 		t.Errorf("decodeDNSResourceRecord() RData got = %s, want = %s, data = %v\n", got.RData.String(), want.RData.String(), data)
 	}
 }
+
+func TestParseGenericRData(t *testing.T) {
+	tests := []struct {
+		name         string
+		rtype        uint16
+		presentation string
+		want         RData
+		wantError    error
+	}{
+		{
+			name:         "Unknown type",
+			rtype:        731,
+			presentation: `\# 6 0A0000 01 02ff`,
+			want:         &RDataUnknown{Data: []byte{0x0a, 0x00, 0x00, 0x01, 0x02, 0xff}},
+		},
+		{
+			name:         "Unknown type without RData",
+			rtype:        62347,
+			presentation: `\# 0`,
+			want:         &RDataUnknown{Data: []byte{}},
+		},
+		{
+			name:         "Known type in generic format",
+			rtype:        A,
+			presentation: `\# 4 0A000001`,
+			want:         &RDataA{IP: netip.MustParseAddr("10.0.0.1")},
+		},
+		{
+			name:         "Not in generic format",
+			rtype:        731,
+			presentation: "0A000001",
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "Length does not match data",
+			rtype:        731,
+			presentation: `\# 5 0A000001`,
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "Invalid hexadecimal",
+			rtype:        731,
+			presentation: `\# 2 0G00`,
+			wantError:    ErrInvalidRecordData,
+		},
+		{
+			name:         "Invalid data for known type",
+			rtype:        A,
+			presentation: `\# 3 0A0000`,
+			wantError:    ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGenericRData(tt.rtype, tt.presentation)
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("ParseGenericRData() error = %v, want error = %v\n", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseGenericRData() unexpected error = %v\n", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseGenericRData() got = %+v, want = %+v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownRecordRoundTrip(t *testing.T) {
+	data := []byte{
+		0,      // Name: .
+		2, 219, // RType: 731
+		0, 1, // RClass: 1
+		0, 0, 1, 44, // TTL: 300
+		0, 3, // RDLength: 3
+		0xde, 0xad, 0xff, // RData
+	}
+
+	reader := &dnsReader{data: data}
+	record, err := reader.readResourceRecord()
+	if err != nil {
+		t.Fatalf("readResourceRecord() unexpected error = %v\n", err)
+	}
+	if got := record.RData.String(); got != `\# 3 DEADFF` {
+		t.Errorf("String() got = %s, want = %s\n", got, `\# 3 DEADFF`)
+	}
+
+	rdata, err := ParseGenericRData(record.RType, record.RData.String())
+	if err != nil {
+		t.Fatalf("ParseGenericRData() unexpected error = %v\n", err)
+	}
+	record.RData = rdata
+
+	writer := &dnsWriter{}
+	writer.writeResourceRecord(record)
+	if !bytes.Equal(writer.data, data) {
+		t.Errorf("Encode() got = %v, want = %v\n", writer.data, data)
+	}
+}
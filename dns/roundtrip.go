@@ -0,0 +1,94 @@
+package dns
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrRoundTripMismatch is returned by CheckRoundTrip when a message changes when encoded back.
+var ErrRoundTripMismatch = fmt.Errorf("message changed on round trip")
+
+// CheckRoundTrip checks that a message is preserved when it is decoded and encoded back:
+// the message decoded from the encoded message must be the same as the one decoded from the data,
+// and the header flags of both encodings must be the same.
+//
+// The records' RDLength is not compared, since it depends on the compression of the names in their RData.
+// The compression of the message is not compared either: the encoder's is not the same as other implementations'.
+//
+// Parameters:
+//   - data: The DNS message to check, ex. a response received from a server.
+//
+// Returns:
+//   - error: If the message cannot be decoded or encoded, or ErrRoundTripMismatch with the differences
+//     if the fields of the message change on the round trip.
+func CheckRoundTrip(data []byte) error {
+	message, err := DecodeMessage(data)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := EncodeMessage(message)
+	if err != nil {
+		return err
+	}
+
+	decoded, err := DecodeMessage(encoded)
+	if err != nil {
+		return fmt.Errorf("decode encoded message: %w", err)
+	}
+
+	var differences []string
+	if !bytes.Equal(data[2:4], encoded[2:4]) {
+		differences = append(differences, fmt.Sprintf("header flags: %016b, encoded %016b", getUint16(data[2:4]), getUint16(encoded[2:4])))
+	}
+	differences = append(differences, getMessageDifferences(message, decoded)...)
+
+	if len(differences) > 0 {
+		return fmt.Errorf("%w: %s", ErrRoundTripMismatch, strings.Join(differences, "; "))
+	}
+	return nil
+}
+
+// getMessageDifferences lists the fields that differ between two messages, ignoring the records' RDLength.
+func getMessageDifferences(a Message, b Message) (differences []string) {
+	if a.Header != b.Header {
+		differences = append(differences, fmt.Sprintf("header: %+v, encoded %+v", a.Header, b.Header))
+	}
+	if !reflect.DeepEqual(a.Questions, b.Questions) {
+		differences = append(differences, fmt.Sprintf("questions: %+v, encoded %+v", a.Questions, b.Questions))
+	}
+
+	for _, section := range []struct {
+		name string
+		a    []ResourceRecord
+		b    []ResourceRecord
+	}{
+		{"answer", a.Answers, b.Answers},
+		{"authority", a.NameServers, b.NameServers},
+		{"additional", a.Additionals, b.Additionals},
+	} {
+		if len(section.a) != len(section.b) {
+			differences = append(differences, fmt.Sprintf("%s section: %d records, encoded %d", section.name, len(section.a), len(section.b)))
+			continue
+		}
+		for i := range section.a {
+			recordA, recordB := section.a[i], section.b[i]
+			recordA.RDLength, recordB.RDLength = 0, 0
+			if !reflect.DeepEqual(recordA, recordB) {
+				differences = append(differences, fmt.Sprintf("%s section: record %d: %s %s %s, encoded %s %s %s",
+					section.name, i+1, recordA.Name, DNSType(recordA.RType), recordA.RData, recordB.Name, DNSType(recordB.RType), recordB.RData))
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(a.EDNS, b.EDNS) {
+		differences = append(differences, fmt.Sprintf("EDNS: %+v, encoded %+v", a.EDNS, b.EDNS))
+	}
+	return differences
+}
+
+func getUint16(data []byte) uint16 {
+	return uint16(data[0])<<8 | uint16(data[1])
+}
@@ -0,0 +1,50 @@
+package dns
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckRoundTrip(t *testing.T) {
+	for i, seed := range getFuzzSeeds(t) {
+		if err := CheckRoundTrip(seed); err != nil {
+			t.Errorf("CheckRoundTrip() seed %d unexpected error = %v\n", i, err)
+		}
+	}
+
+	// Names compressed by the sender are expanded, then compressed again by the encoder
+	compressed := []byte{
+		0x00, 0x01, 0x81, 0x80, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, // Header
+		7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 0, 0x00, 0x0f, 0x00, 0x01, // Question: example. MX IN
+		0xc0, 12, 0x00, 0x0f, 0x00, 0x01, 0x00, 0x00, 0x01, 0x2c, // Answer: example. MX IN 300
+		0x00, 0x09, 0x00, 0x0a, 4, 'm', 'a', 'i', 'l', 0xc0, 12, // 10 mail.example.
+	}
+	if err := CheckRoundTrip(compressed); err != nil {
+		t.Errorf("CheckRoundTrip() compressed message unexpected error = %v\n", err)
+	}
+
+	// An invalid message is reported as such
+	if err := CheckRoundTrip([]byte{0x00, 0x01, 0x81}); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("CheckRoundTrip() error = %v, want error = %v\n", err, ErrInvalidMessage)
+	}
+}
+
+func TestGetMessageDifferences(t *testing.T) {
+	message := Message{
+		Header:  Header{Id: 1, AnswerRRCount: 1},
+		Answers: []ResourceRecord{{Name: "example.com.", RType: TXT, RClass: IN, RDLength: 5, RData: &RDataTXT{Text: []string{"test"}}}},
+	}
+
+	same := message
+	same.Answers = []ResourceRecord{{Name: "example.com.", RType: TXT, RClass: IN, RDLength: 0, RData: &RDataTXT{Text: []string{"test"}}}}
+	if differences := getMessageDifferences(message, same); len(differences) != 0 {
+		t.Errorf("getMessageDifferences() got = %v, want no differences\n", differences)
+	}
+
+	changed := same
+	changed.Answers = []ResourceRecord{{Name: "example.com.", RType: TXT, RClass: IN, RData: &RDataTXT{Text: []string{"changed"}}}}
+	changed.EDNS = &EDNS{UDPSize: 1232}
+	if differences := getMessageDifferences(message, changed); len(differences) != 2 {
+		t.Errorf("getMessageDifferences() got = %v, want 2 differences\n", differences)
+	}
+}
@@ -0,0 +1,401 @@
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+SIG(0) transaction signatures [RFC2931] sign a whole message with a public key,
+as an alternative to TSIG's shared secrets. The signature is carried by a SIG
+record appended to the additional section, with owner name root, class ANY,
+TTL 0, labels 0 and type covered 0. It covers:
+
+	data = SIG RDATA (without the signature) | request | message
+
+where message is the message before the SIG record was added (ARCOUNT not
+counting it), and request is the full request message as sent, included only
+when signing or verifying a response.
+
+Keys are read from the files written by BIND's dnssec-keygen (or ldns-keygen):
+"Kexample.com.+013+12345.key" holds the KEY or DNSKEY record, and
+"Kexample.com.+013+12345.private" holds the private key.
+*/
+
+// SIG0Validity is how long before and after signing a SIG(0) signature is valid,
+// to account for clock skew between the signer and the verifier.
+const SIG0Validity = 5 * time.Minute
+
+// SIG0PublicKey is the public part of a SIG(0) key, used to verify signatures.
+type SIG0PublicKey struct {
+	Name string      // Owner name of the key record, the signer's name in signatures
+	Key  RDataDNSKEY // The KEY or DNSKEY record data
+}
+
+// SIG0Key is a SIG(0) key pair, used to sign messages.
+type SIG0Key struct {
+	SIG0PublicKey
+	Signer crypto.Signer
+}
+
+// Sign appends a SIG(0) signature to an encoded message.
+//
+// Parameters:
+//   - message: The encoded message to sign.
+//   - request: The encoded request being answered when signing a response, nil when signing a request.
+//
+// Returns:
+//   - []byte: The signed message, with the SIG record appended and ARCOUNT incremented.
+//   - error: If the message is too short or cannot be signed with this key.
+func (key *SIG0Key) Sign(message []byte, request []byte) ([]byte, error) {
+	if len(message) < DNSHeaderLength {
+		return nil, invalidMessageError("too short to sign")
+	}
+
+	now := time.Now()
+	sig := &RDataRRSIG{
+		Algorithm:  key.Key.Algorithm,
+		Expiration: uint32(now.Add(SIG0Validity).Unix()),
+		Inception:  uint32(now.Add(-SIG0Validity).Unix()),
+		KeyTag:     key.Key.KeyTag(),
+		SignerName: key.Name,
+	}
+
+	signature, err := signData(key.Key.Algorithm, key.Signer, getSIG0SignedData(sig, request, message))
+	if err != nil {
+		return nil, err
+	}
+	sig.Signature = signature
+
+	record := ResourceRecord{
+		Name:   ".",
+		RType:  SIG,
+		RClass: ANY,
+		TTL:    0,
+		RData:  sig,
+	}
+
+	writer := &dnsWriter{}
+	if err = writer.writeResourceRecord(record); err != nil {
+		return nil, err
+	}
+
+	signed := append(append([]byte{}, message...), writer.data...)
+	setAdditionalRRCount(signed, getAdditionalRRCount(message)+1)
+	return signed, nil
+}
+
+// Verify checks the SIG(0) signature of an encoded message. The SIG record must be the last
+// record of the message, be signed by this key and be within its validity period.
+//
+// Parameters:
+//   - message: The encoded signed message.
+//   - request: The encoded request as it was sent when verifying a response, nil when verifying a request.
+//
+// Returns:
+//   - error: If the message is not signed, or the signature is not valid for this key.
+func (key *SIG0PublicKey) Verify(message []byte, request []byte) error {
+	reader := &dnsReader{data: message}
+
+	header, err := reader.readHeader()
+	if err != nil {
+		return invalidMessageError(err.Error())
+	}
+	if header.AdditionalRRCount == 0 {
+		return invalidSignatureError("message has no SIG(0) record")
+	}
+
+	if _, err = reader.readQuestions(header.QuestionCount); err != nil {
+		return invalidMessageError(err.Error())
+	}
+	recordCount := header.AnswerRRCount + header.NameserverRRCount + header.AdditionalRRCount - 1
+	if _, err = reader.readResourceRecords(recordCount); err != nil {
+		return invalidMessageError(err.Error())
+	}
+
+	sigOffset := reader.offset
+	record, err := reader.readResourceRecord()
+	if err != nil {
+		return invalidMessageError(err.Error())
+	}
+	sig, ok := record.RData.(*RDataRRSIG)
+	if record.RType != SIG || !ok || sig.TypeCovered != 0 {
+		return invalidSignatureError("last record is not a SIG(0) record")
+	}
+	if reader.offset != len(message) {
+		return invalidSignatureError("SIG(0) record is not the last record")
+	}
+
+	if err = key.checkSIG0(sig, time.Now()); err != nil {
+		return err
+	}
+
+	unsigned := append([]byte{}, message[:sigOffset]...)
+	setAdditionalRRCount(unsigned, header.AdditionalRRCount-1)
+
+	signature := sig.Signature
+	unsignedSig := *sig
+	unsignedSig.Signature = nil
+
+	return verifyData(&key.Key, getSIG0SignedData(&unsignedSig, request, unsigned), signature)
+}
+
+func (key *SIG0PublicKey) checkSIG0(sig *RDataRRSIG, now time.Time) error {
+	if !strings.EqualFold(Fqdn(sig.SignerName), Fqdn(key.Name)) {
+		return invalidSignatureError(fmt.Sprintf("signer %s does not match key %s", sig.SignerName, key.Name))
+	}
+	if sig.Algorithm != key.Key.Algorithm || sig.KeyTag != key.Key.KeyTag() {
+		return invalidSignatureError(fmt.Sprintf("signature algorithm %s and key tag %d do not match key", DNSSECAlgorithm(sig.Algorithm), sig.KeyTag))
+	}
+
+	timestamp := uint32(now.Unix())
+	if timestamp < sig.Inception || timestamp > sig.Expiration {
+		return invalidSignatureError("signature is outside its validity period")
+	}
+	return nil
+}
+
+func getSIG0SignedData(sig *RDataRRSIG, request []byte, message []byte) []byte {
+	writer := &dnsWriter{}
+	sig.WriteRecordData(writer)
+
+	data := append([]byte{}, writer.data...)
+	data = append(data, request...)
+	return append(data, message...)
+}
+
+func getAdditionalRRCount(message []byte) uint16 {
+	return uint16(message[10])<<8 | uint16(message[11])
+}
+
+func setAdditionalRRCount(message []byte, count uint16) {
+	message[10] = byte(count >> 8)
+	message[11] = byte(count & 0xFF)
+}
+
+// LoadSIG0Key reads a SIG(0) key pair from the ".key" and ".private" files written by dnssec-keygen.
+//
+// Parameters:
+//   - path: The path of the key files, with or without extension, ex. "Kexample.com.+013+12345".
+//
+// Returns:
+//   - *SIG0Key: The key pair.
+//   - error: If a file cannot be read or is not valid.
+func LoadSIG0Key(path string) (*SIG0Key, error) {
+	path = strings.TrimSuffix(strings.TrimSuffix(path, ".key"), ".private")
+
+	publicFile, err := os.Open(path + ".key")
+	if err != nil {
+		return nil, err
+	}
+	defer publicFile.Close()
+
+	privateFile, err := os.Open(path + ".private")
+	if err != nil {
+		return nil, err
+	}
+	defer privateFile.Close()
+
+	return ParseSIG0Key(publicFile, privateFile)
+}
+
+// ParseSIG0PublicKey reads a KEY or DNSKEY record in presentation format, as in a dnssec-keygen ".key" file.
+//
+// Parameters:
+//   - public: The content of the ".key" file.
+//
+// Returns:
+//   - SIG0PublicKey: The public key.
+//   - error: If no valid KEY or DNSKEY record is found.
+func ParseSIG0PublicKey(public io.Reader) (SIG0PublicKey, error) {
+	content, err := io.ReadAll(public)
+	if err != nil {
+		return SIG0PublicKey{}, err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line, _, _ = strings.Cut(line, ";") // Strip comments
+		fields := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(line))
+
+		typeIndex := -1
+		for i, field := range fields {
+			if strings.EqualFold(field, "KEY") || strings.EqualFold(field, "DNSKEY") {
+				typeIndex = i
+				break
+			}
+		}
+		if typeIndex < 1 || len(fields) < typeIndex+5 {
+			continue
+		}
+
+		flags, flagsErr := strconv.ParseUint(fields[typeIndex+1], 10, 16)
+		protocol, protocolErr := strconv.ParseUint(fields[typeIndex+2], 10, 8)
+		algorithm, algorithmErr := strconv.ParseUint(fields[typeIndex+3], 10, 8)
+		publicKey, publicKeyErr := base64.StdEncoding.DecodeString(strings.Join(fields[typeIndex+4:], ""))
+		if err = errors.Join(flagsErr, protocolErr, algorithmErr, publicKeyErr); err != nil {
+			return SIG0PublicKey{}, invalidKeyError(err.Error())
+		}
+
+		return SIG0PublicKey{
+			Name: Fqdn(fields[0]),
+			Key: RDataDNSKEY{
+				Flags:     uint16(flags),
+				Protocol:  uint8(protocol),
+				Algorithm: uint8(algorithm),
+				PublicKey: publicKey,
+			},
+		}, nil
+	}
+	return SIG0PublicKey{}, invalidKeyError("no KEY or DNSKEY record found")
+}
+
+// ParseSIG0Key reads a SIG(0) key pair from the content of the ".key" and ".private" files written by dnssec-keygen.
+// RSA, ECDSA and Ed25519 keys are supported.
+//
+// Parameters:
+//   - public: The content of the ".key" file.
+//   - private: The content of the ".private" file.
+//
+// Returns:
+//   - *SIG0Key: The key pair.
+//   - error: If a file is not valid, or the private key does not match the public key.
+func ParseSIG0Key(public io.Reader, private io.Reader) (*SIG0Key, error) {
+	publicKey, err := ParseSIG0PublicKey(public)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := parsePrivateKeyFields(private)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm, err := strconv.ParseUint(strings.Fields(fields["Algorithm"] + " ")[0], 10, 8)
+	if err != nil || uint8(algorithm) != publicKey.Key.Algorithm {
+		return nil, invalidKeyError(fmt.Sprintf("private key algorithm %q does not match public key algorithm %s", fields["Algorithm"], DNSSECAlgorithm(publicKey.Key.Algorithm)))
+	}
+
+	signer, err := getPrivateKey(&publicKey.Key, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SIG0Key{SIG0PublicKey: publicKey, Signer: signer}, nil
+}
+
+// parsePrivateKeyFields reads the "Field: value" lines of a private key file.
+func parsePrivateKeyFields(private io.Reader) (map[string]string, error) {
+	fields := map[string]string{}
+
+	scanner := bufio.NewScanner(private)
+	for scanner.Scan() {
+		name, value, found := strings.Cut(scanner.Text(), ":")
+		if found {
+			fields[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(fields["Private-key-format"], "v1.") {
+		return nil, invalidKeyError(fmt.Sprintf("unsupported private key format: %q", fields["Private-key-format"]))
+	}
+	return fields, nil
+}
+
+func getPrivateKey(key *RDataDNSKEY, fields map[string]string) (crypto.Signer, error) {
+	publicKey, err := getPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	integers := map[string]*big.Int{}
+	for _, name := range []string{"PrivateKey", "Modulus", "PublicExponent", "PrivateExponent", "Prime1", "Prime2"} {
+		if value, ok := fields[name]; ok {
+			data, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, invalidKeyError(fmt.Sprintf("%s: %s", name, err.Error()))
+			}
+			integers[name] = new(big.Int).SetBytes(data)
+		}
+	}
+
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		for _, name := range []string{"Modulus", "PrivateExponent", "Prime1", "Prime2"} {
+			if integers[name] == nil {
+				return nil, invalidKeyError(fmt.Sprintf("missing RSA %s", name))
+			}
+		}
+		if integers["Modulus"].Cmp(publicKey.N) != 0 {
+			return nil, invalidKeyError("private key does not match public key")
+		}
+
+		privateKey := &rsa.PrivateKey{
+			PublicKey: *publicKey,
+			D:         integers["PrivateExponent"],
+			Primes:    []*big.Int{integers["Prime1"], integers["Prime2"]},
+		}
+		if err := privateKey.Validate(); err != nil {
+			return nil, invalidKeyError(err.Error())
+		}
+		privateKey.Precompute()
+		return privateKey, nil
+
+	case ed25519.PublicKey:
+		seed, err := base64.StdEncoding.DecodeString(fields["PrivateKey"])
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, invalidKeyError("invalid Ed25519 private key")
+		}
+		privateKey := ed25519.NewKeyFromSeed(seed)
+		if !publicKey.Equal(privateKey.Public()) {
+			return nil, invalidKeyError("private key does not match public key")
+		}
+		return privateKey, nil
+
+	case *ecdsa.PublicKey:
+		return getECDSAPrivateKey(key, publicKey, fields["PrivateKey"])
+	}
+	return nil, unsupportedAlgorithmError(key.Algorithm)
+}
+
+func getECDSAPrivateKey(key *RDataDNSKEY, publicKey *ecdsa.PublicKey, encodedPrivateKey string) (crypto.Signer, error) {
+	curve := ecdh.P256()
+	if key.Algorithm == ECDSAP384SHA384 {
+		curve = ecdh.P384()
+	}
+
+	privateKeyBytes, err := base64.StdEncoding.DecodeString(encodedPrivateKey)
+	if err != nil {
+		return nil, invalidKeyError(fmt.Sprintf("invalid ECDSA private key: %s", err.Error()))
+	}
+
+	// Going through crypto/ecdh checks the scalar and derives the public point to compare with the record
+	ecdhKey, err := curve.NewPrivateKey(privateKeyBytes)
+	if err != nil {
+		return nil, invalidKeyError(fmt.Sprintf("invalid ECDSA private key: %s", err.Error()))
+	}
+	if !bytes.Equal(ecdhKey.PublicKey().Bytes()[1:], key.PublicKey) {
+		return nil, invalidKeyError("private key does not match public key")
+	}
+
+	return &ecdsa.PrivateKey{
+		PublicKey: *publicKey,
+		D:         new(big.Int).SetBytes(privateKeyBytes),
+	}, nil
+}
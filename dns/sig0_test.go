@@ -0,0 +1,239 @@
+package dns
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// generateTestSIG0KeyFiles generates a key pair and returns it as the content
+// of the ".key" and ".private" files written by dnssec-keygen.
+func generateTestSIG0KeyFiles(t *testing.T, name string, algorithm uint8) (public string, private string) {
+	t.Helper()
+
+	encode := func(data []byte) string {
+		return base64.StdEncoding.EncodeToString(data)
+	}
+
+	var publicKey []byte
+	fields := []string{}
+	switch algorithm {
+	case RSASHA256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("generate RSA key: %v", err)
+		}
+		exponent := big.NewInt(int64(key.E)).Bytes()
+		publicKey = append(append([]byte{byte(len(exponent))}, exponent...), key.N.Bytes()...)
+		fields = append(fields,
+			"Modulus: "+encode(key.N.Bytes()),
+			"PublicExponent: "+encode(exponent),
+			"PrivateExponent: "+encode(key.D.Bytes()),
+			"Prime1: "+encode(key.Primes[0].Bytes()),
+			"Prime2: "+encode(key.Primes[1].Bytes()),
+			"Exponent1: "+encode(key.Precomputed.Dp.Bytes()),
+			"Exponent2: "+encode(key.Precomputed.Dq.Bytes()),
+			"Coefficient: "+encode(key.Precomputed.Qinv.Bytes()),
+		)
+	case ECDSAP256SHA256:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("generate ECDSA key: %v", err)
+		}
+		publicKey = append(key.X.FillBytes(make([]byte, 32)), key.Y.FillBytes(make([]byte, 32))...)
+		fields = append(fields, "PrivateKey: "+encode(key.D.FillBytes(make([]byte, 32))))
+	case ED25519:
+		publicKeyEd25519, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("generate Ed25519 key: %v", err)
+		}
+		publicKey = publicKeyEd25519
+		fields = append(fields, "PrivateKey: "+encode(key.Seed()))
+	}
+
+	public = fmt.Sprintf("; This is a key-signing key, keyid 12345, for %s\n%s IN KEY 512 3 %d %s\n", name, name, algorithm, encode(publicKey))
+	private = fmt.Sprintf("Private-key-format: v1.3\nAlgorithm: %d (%s)\n%s\nCreated: 20240101000000\n", algorithm, DNSSECAlgorithm(algorithm), strings.Join(fields, "\n"))
+	return public, private
+}
+
+func newTestSIG0Key(t *testing.T, name string, algorithm uint8) *SIG0Key {
+	t.Helper()
+
+	public, private := generateTestSIG0KeyFiles(t, name, algorithm)
+	key, err := ParseSIG0Key(strings.NewReader(public), strings.NewReader(private))
+	if err != nil {
+		t.Fatalf("ParseSIG0Key() unexpected error = %v\n", err)
+	}
+	return key
+}
+
+func newTestSIG0Message(t *testing.T, response bool) []byte {
+	t.Helper()
+
+	message := Message{
+		Header: Header{
+			Id:            1234,
+			Flags:         Flags{Response: response, Opcode: UPDATE},
+			QuestionCount: 1,
+		},
+		Questions: []Question{{Name: "example.com.", QType: SOA, QClass: IN}},
+	}
+	data, err := EncodeMessage(message)
+	if err != nil {
+		t.Fatalf("EncodeMessage() unexpected error = %v\n", err)
+	}
+	return data
+}
+
+func TestParseSIG0Key(t *testing.T) {
+	public, private := generateTestSIG0KeyFiles(t, "client.example.com.", ECDSAP256SHA256)
+	_, otherPrivate := generateTestSIG0KeyFiles(t, "client.example.com.", ECDSAP256SHA256)
+	_, ed25519Private := generateTestSIG0KeyFiles(t, "client.example.com.", ED25519)
+
+	tests := []struct {
+		name      string
+		public    string
+		private   string
+		wantError error
+	}{
+		{
+			name:      "Matching key files",
+			public:    public,
+			private:   private,
+			wantError: nil,
+		},
+		{
+			name:      "Private key does not match public key",
+			public:    public,
+			private:   otherPrivate,
+			wantError: ErrInvalidKey,
+		},
+		{
+			name:      "Private key algorithm does not match public key",
+			public:    public,
+			private:   ed25519Private,
+			wantError: ErrInvalidKey,
+		},
+		{
+			name:      "No key record",
+			public:    "; nothing here\n",
+			private:   private,
+			wantError: ErrInvalidKey,
+		},
+		{
+			name:      "Unsupported private key format",
+			public:    public,
+			private:   strings.Replace(private, "v1.3", "v2.0", 1),
+			wantError: ErrInvalidKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSIG0Key(strings.NewReader(tt.public), strings.NewReader(tt.private))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("ParseSIG0Key() error = %v, want error = %v\n", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSIG0Key() unexpected error = %v\n", err)
+			}
+			if got.Name != "client.example.com." || got.Key.Flags != 512 || got.Key.Protocol != 3 || got.Key.Algorithm != ECDSAP256SHA256 {
+				t.Errorf("ParseSIG0Key() got = %+v\n", got.SIG0PublicKey)
+			}
+		})
+	}
+}
+
+func TestLoadSIG0Key(t *testing.T) {
+	public, private := generateTestSIG0KeyFiles(t, "client.example.com.", ED25519)
+
+	path := filepath.Join(t.TempDir(), "Kclient.example.com.+015+12345")
+	if err := os.WriteFile(path+".key", []byte(public), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".private", []byte(private), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{path, path + ".key", path + ".private"} {
+		key, err := LoadSIG0Key(file)
+		if err != nil {
+			t.Fatalf("LoadSIG0Key(%s) unexpected error = %v\n", file, err)
+		}
+		if key.Name != "client.example.com." || key.Key.Algorithm != ED25519 {
+			t.Errorf("LoadSIG0Key(%s) got = %+v\n", file, key.SIG0PublicKey)
+		}
+	}
+}
+
+func TestSIG0SignVerify(t *testing.T) {
+	for _, algorithm := range []uint8{RSASHA256, ECDSAP256SHA256, ED25519} {
+		t.Run(DNSSECAlgorithm(algorithm).String(), func(t *testing.T) {
+			clientKey := newTestSIG0Key(t, "client.example.com.", algorithm)
+			serverKey := newTestSIG0Key(t, "server.example.com.", algorithm)
+
+			// Signed request
+			request, err := clientKey.Sign(newTestSIG0Message(t, false), nil)
+			if err != nil {
+				t.Fatalf("Sign() unexpected error = %v\n", err)
+			}
+
+			decoded, err := DecodeMessage(request)
+			if err != nil {
+				t.Fatalf("DecodeMessage() unexpected error = %v\n", err)
+			}
+			if decoded.Header.AdditionalRRCount != 1 || decoded.Additionals[0].RType != SIG || decoded.Additionals[0].RClass != ANY {
+				t.Fatalf("Sign() additional section got = %+v\n", decoded.Additionals)
+			}
+
+			if err = clientKey.Verify(request, nil); err != nil {
+				t.Errorf("Verify() unexpected error = %v\n", err)
+			}
+			if err = serverKey.Verify(request, nil); err == nil || !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify() with another key error = %v, want error = %v\n", err, ErrInvalidSignature)
+			}
+
+			tampered := append([]byte{}, request...)
+			tampered[1] ^= 0xFF // Change the ID
+			if err = clientKey.Verify(tampered, nil); err == nil || !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify() tampered message error = %v, want error = %v\n", err, ErrInvalidSignature)
+			}
+
+			// Signed response, covering the request
+			response, err := serverKey.Sign(newTestSIG0Message(t, true), request)
+			if err != nil {
+				t.Fatalf("Sign() unexpected error = %v\n", err)
+			}
+			if err = serverKey.Verify(response, request); err != nil {
+				t.Errorf("Verify() response unexpected error = %v\n", err)
+			}
+			if err = serverKey.Verify(response, nil); err == nil || !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify() response without request error = %v, want error = %v\n", err, ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestSIG0VerifyUnsigned(t *testing.T) {
+	key := newTestSIG0Key(t, "client.example.com.", ED25519)
+
+	err := key.Verify(newTestSIG0Message(t, false), nil)
+
+	if err == nil || !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() error = %v, want error = %v\n", err, ErrInvalidSignature)
+	}
+}
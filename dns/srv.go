@@ -0,0 +1,88 @@
+package dns
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+// GetSRVName returns the owner name of the SRV records of a service [RFC2782],
+// ex. "_sip._tcp.example.com." for the "sip" service over "tcp" at "example.com.".
+//
+// Parameters:
+//   - service: The symbolic name of the service, with or without its leading underscore.
+//   - proto: The protocol of the service, usually "tcp" or "udp", with or without its leading underscore.
+//   - name: The domain the service is provided for.
+func GetSRVName(service string, proto string, name string) string {
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return "_" + strings.TrimPrefix(service, "_") + "._" + strings.TrimPrefix(proto, "_") + "." + name
+}
+
+// IsServiceUnavailable reports whether the SRV records say the service is decidedly
+// not available at the domain: a single record with the root target [RFC2782].
+func IsServiceUnavailable(records []RDataSRV) bool {
+	return len(records) == 1 && records[0].Target == "."
+}
+
+// SortSRV orders SRV records in the order a client should try their targets [RFC2782]:
+// by increasing priority, and within each priority in a random order weighted by the records' weights.
+//
+// Parameters:
+//   - records: The records of an SRV RRset. The slice is not modified.
+//
+// Returns:
+//   - []RDataSRV: The records in selection order.
+func SortSRV(records []RDataSRV) []RDataSRV {
+	return sortSRV(records, func(n int) int { return rand.IntN(n) })
+}
+
+// sortSRV orders the records, drawing random numbers in [0, n) with randomN.
+func sortSRV(records []RDataSRV, randomN func(n int) int) []RDataSRV {
+	remaining := slices.Clone(records)
+	slices.SortStableFunc(remaining, func(a, b RDataSRV) int {
+		if a.Priority != b.Priority {
+			return int(a.Priority) - int(b.Priority)
+		}
+		// Records with weight 0 are placed first, so that they have a small chance of being selected
+		return min(int(a.Weight), 1) - min(int(b.Weight), 1)
+	})
+
+	sorted := make([]RDataSRV, 0, len(records))
+	for len(remaining) > 0 {
+		end := 1
+		for end < len(remaining) && remaining[end].Priority == remaining[0].Priority {
+			end++
+		}
+
+		group := remaining[:end]
+		for len(group) > 0 {
+			i := selectSRV(group, randomN)
+			sorted = append(sorted, group[i])
+			group = slices.Delete(group, i, i+1)
+		}
+		remaining = remaining[end:]
+	}
+	return sorted
+}
+
+// selectSRV selects a record of a priority group [RFC2782]: compute the running sum of the weights
+// for each record, pick a random number between 0 and the total weight, and select the first record
+// whose running sum is greater than or equal to that number.
+func selectSRV(group []RDataSRV, randomN func(n int) int) int {
+	total := 0
+	for _, record := range group {
+		total += int(record.Weight)
+	}
+
+	threshold := randomN(total + 1)
+	sum := 0
+	for i, record := range group {
+		sum += int(record.Weight)
+		if sum >= threshold {
+			return i
+		}
+	}
+	return len(group) - 1
+}
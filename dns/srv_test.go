@@ -0,0 +1,110 @@
+package dns
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetSRVName(t *testing.T) {
+	tests := []struct {
+		service string
+		proto   string
+		name    string
+		want    string
+	}{
+		{service: "sip", proto: "tcp", name: "example.com.", want: "_sip._tcp.example.com."},
+		{service: "_xmpp-server", proto: "_tcp", name: "example.com", want: "_xmpp-server._tcp.example.com."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := GetSRVName(tt.service, tt.proto, tt.name); got != tt.want {
+				t.Errorf("GetSRVName() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortSRV(t *testing.T) {
+	a := RDataSRV{Priority: 10, Weight: 60, Port: 5060, Target: "a.example.com."}
+	b := RDataSRV{Priority: 10, Weight: 20, Port: 5060, Target: "b.example.com."}
+	c := RDataSRV{Priority: 10, Weight: 0, Port: 5060, Target: "c.example.com."}
+	d := RDataSRV{Priority: 20, Weight: 0, Port: 5060, Target: "d.example.com."}
+	e := RDataSRV{Priority: 5, Weight: 10, Port: 5060, Target: "e.example.com."}
+
+	tests := []struct {
+		name    string
+		records []RDataSRV
+		random  []int // The random numbers drawn, in order
+		want    []RDataSRV
+	}{
+		{
+			name:    "priorities in increasing order",
+			records: []RDataSRV{d, a, e},
+			random:  []int{0, 0, 0},
+			want:    []RDataSRV{e, a, d},
+		},
+		{
+			// The running sums of c, a, b are 0, 60, 80
+			name:    "zero weight selected first on zero",
+			records: []RDataSRV{a, b, c},
+			random:  []int{0, 0, 0},
+			want:    []RDataSRV{c, a, b},
+		},
+		{
+			name:    "selection by running sum",
+			records: []RDataSRV{a, b, c},
+			random:  []int{61, 60, 0},
+			want:    []RDataSRV{b, a, c},
+		},
+		{
+			name:    "highest number selects last record",
+			records: []RDataSRV{a, b, c},
+			random:  []int{80, 1, 0},
+			want:    []RDataSRV{b, a, c},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			draws := 0
+			randomN := func(n int) int {
+				value := tt.random[draws]
+				draws++
+				if value >= n {
+					t.Fatalf("sortSRV() random number %d out of range [0, %d)\n", value, n)
+				}
+				return value
+			}
+
+			got := sortSRV(tt.records, randomN)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortSRV() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortSRVKeepsRecords(t *testing.T) {
+	records := []RDataSRV{
+		{Priority: 20, Weight: 0, Target: "c.example.com."},
+		{Priority: 10, Weight: 1, Target: "a.example.com."},
+		{Priority: 10, Weight: 1, Target: "b.example.com."},
+	}
+	original := append([]RDataSRV{}, records...)
+
+	got := SortSRV(records)
+	if len(got) != len(records) || got[2].Target != "c.example.com." {
+		t.Errorf("SortSRV() got = %v\n", got)
+	}
+	if !reflect.DeepEqual(records, original) {
+		t.Errorf("SortSRV() modified its argument: %v\n", records)
+	}
+}
+
+func TestIsServiceUnavailable(t *testing.T) {
+	if !IsServiceUnavailable([]RDataSRV{{Target: "."}}) {
+		t.Errorf("IsServiceUnavailable() got = false, want = true\n")
+	}
+	if IsServiceUnavailable([]RDataSRV{{Target: "."}, {Target: "a.example.com."}}) {
+		t.Errorf("IsServiceUnavailable() got = true, want = false\n")
+	}
+}
@@ -0,0 +1,131 @@
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Messages sent over TCP (and TLS) connections are prefixed with a two byte
+// length field which gives the message length, excluding the two byte length field [RFC1035].
+
+// ReadRawMessage reads the next length-prefixed message from a stream, ex. a TCP connection.
+//
+// Parameters:
+//   - r: The stream to read from.
+//
+// Returns:
+//   - []byte: The message, without its length prefix.
+//   - error: io.EOF if the stream ends before the next message, io.ErrUnexpectedEOF if it ends in the middle of one.
+func ReadRawMessage(r io.Reader) ([]byte, error) {
+	lengthPrefix := [2]byte{}
+	if _, err := io.ReadFull(r, lengthPrefix[:]); err != nil {
+		return nil, err
+	}
+
+	message := make([]byte, int(lengthPrefix[0])<<8|int(lengthPrefix[1]))
+	if _, err := io.ReadFull(r, message); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return message, nil
+}
+
+// ReadMessage reads and decodes the next length-prefixed message from a stream, ex. a TCP connection.
+//
+// Parameters:
+//   - r: The stream to read from.
+//
+// Returns:
+//   - Message: The decoded message.
+//   - error: io.EOF if the stream ends before the next message, or if the message cannot be read or decoded.
+func ReadMessage(r io.Reader) (Message, error) {
+	data, err := ReadRawMessage(r)
+	if err != nil {
+		return Message{}, err
+	}
+	return DecodeMessage(data)
+}
+
+// WriteRawMessage writes a message to a stream, prefixed with its length.
+//
+// Parameters:
+//   - w: The stream to write to.
+//   - data: The encoded message.
+//
+// Returns:
+//   - error: If the message is too long to be prefixed with its length, or cannot be written.
+func WriteRawMessage(w io.Writer, data []byte) error {
+	if len(data) > 0xFFFF {
+		return invalidMessageError(fmt.Sprintf("too long for a stream: %d bytes", len(data)))
+	}
+
+	length := uint16(len(data))    // ex. 00000001	00101100
+	highByte := byte(length >> 8)  // ex.			00000001
+	lowByte := byte(length & 0xFF) // ex. 			00101100
+
+	_, err := w.Write(append([]byte{highByte, lowByte}, data...))
+	return err
+}
+
+// MessageScanner reads consecutive length-prefixed messages from a stream, ex. the responses
+// of a zone transfer on one TCP connection. Like bufio.Scanner, Scan advances to the next message
+// until the stream ends or an error occurs:
+//
+//	scanner := dns.NewMessageScanner(conn)
+//	for scanner.Scan() {
+//		message := scanner.Message()
+//		...
+//	}
+//	if err := scanner.Err(); err != nil {
+//		...
+//	}
+type MessageScanner struct {
+	reader  io.Reader
+	options DecodeOptions
+	raw     []byte
+	message Message
+	err     error
+}
+
+// NewMessageScanner returns a MessageScanner reading from a stream, decoding messages with the given options.
+func NewMessageScanner(r io.Reader, options DecodeOptions) *MessageScanner {
+	return &MessageScanner{reader: r, options: options}
+}
+
+// Scan reads and decodes the next message, and reports whether it succeeded.
+// It returns false at the end of the stream or at the first error, which Err then returns.
+func (scanner *MessageScanner) Scan() bool {
+	if scanner.err != nil {
+		return false
+	}
+
+	scanner.raw, scanner.err = ReadRawMessage(scanner.reader)
+	if scanner.err != nil {
+		scanner.raw, scanner.message = nil, Message{}
+		return false
+	}
+
+	scanner.message, scanner.err = DecodeMessageWithOptions(scanner.raw, scanner.options)
+	return scanner.err == nil
+}
+
+// Message returns the message read by the last call to Scan.
+func (scanner *MessageScanner) Message() Message {
+	return scanner.message
+}
+
+// Raw returns the encoded message read by the last call to Scan, ex. to verify its signature.
+func (scanner *MessageScanner) Raw() []byte {
+	return scanner.raw
+}
+
+// Err returns the error that stopped Scan, nil if the stream ended between two messages.
+func (scanner *MessageScanner) Err() error {
+	if errors.Is(scanner.err, io.EOF) {
+		return nil
+	}
+	return scanner.err
+}
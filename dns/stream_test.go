@@ -0,0 +1,117 @@
+package dns
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestReadRawMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		want      []byte
+		wantError error
+	}{
+		{
+			name: "Message",
+			data: []byte{0, 3, 1, 2, 3, 4},
+			want: []byte{1, 2, 3},
+		},
+		{
+			name:      "End of stream",
+			data:      []byte{},
+			wantError: io.EOF,
+		},
+		{
+			name:      "Truncated length prefix",
+			data:      []byte{0},
+			wantError: io.ErrUnexpectedEOF,
+		},
+		{
+			name:      "No message after length prefix",
+			data:      []byte{0, 3},
+			wantError: io.ErrUnexpectedEOF,
+		},
+		{
+			name:      "Truncated message",
+			data:      []byte{0, 3, 1, 2},
+			wantError: io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadRawMessage(bytes.NewReader(tt.data))
+
+			if tt.wantError != nil {
+				if !errors.Is(err, tt.wantError) {
+					t.Fatalf("ReadRawMessage() error = %v, want error = %v\n", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadRawMessage() unexpected error = %v\n", err)
+			}
+
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("ReadRawMessage() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageScanner(t *testing.T) {
+	messages := []Message{
+		{Header: Header{Id: 1, Flags: Flags{Response: true}}, Questions: []Question{{Name: "example.com.", QType: AXFR, QClass: IN}}},
+		{Header: Header{Id: 1, Flags: Flags{Response: true}}, Questions: []Question{}},
+	}
+
+	var stream bytes.Buffer
+	for _, message := range messages {
+		data, err := EncodeMessage(message)
+		if err != nil {
+			t.Fatalf("EncodeMessage() unexpected error = %v\n", err)
+		}
+		if err = WriteRawMessage(&stream, data); err != nil {
+			t.Fatalf("WriteRawMessage() unexpected error = %v\n", err)
+		}
+	}
+
+	scanner := NewMessageScanner(bytes.NewReader(stream.Bytes()), DecodeOptions{})
+	var got []Message
+	for scanner.Scan() {
+		got = append(got, scanner.Message())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Err() unexpected error = %v\n", err)
+	}
+
+	if len(got) != len(messages) {
+		t.Fatalf("Scan() got %d messages, want %d\n", len(got), len(messages))
+	}
+	for i, message := range got {
+		if message.Header.Id != 1 || !reflect.DeepEqual(message.Questions, messages[i].Questions) {
+			t.Errorf("Message() got = %+v, want = %+v\n", message, messages[i])
+		}
+	}
+
+	// A stream ending in the middle of a message is an error
+	scanner = NewMessageScanner(bytes.NewReader(stream.Bytes()[:stream.Len()-1]), DecodeOptions{})
+	for scanner.Scan() {
+	}
+	if err := scanner.Err(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Err() error = %v, want error = %v\n", err, io.ErrUnexpectedEOF)
+	}
+
+	// So is a message that cannot be decoded
+	scanner = NewMessageScanner(bytes.NewReader([]byte{0, 3, 1, 2, 3}), DecodeOptions{})
+	if scanner.Scan() {
+		t.Errorf("Scan() got = true, want = false\n")
+	}
+	if err := scanner.Err(); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("Err() error = %v, want error = %v\n", err, ErrInvalidMessage)
+	}
+}
@@ -0,0 +1,653 @@
+package dns
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// -------------- SVCB / HTTPS
+// SVCB and HTTPS RDATA format [RFC9460]
+// SvcPriority:	The priority of this record. 0 means AliasMode, where the target is an alias of the owner name.
+// TargetName:	The <domain-name> of the service endpoint, uncompressed. "." means the owner name in ServiceMode.
+// SvcParams:	Zero or more {key, value} pairs describing the endpoint, in strictly increasing key order:
+
+//                +0 (MSB)                            +1 (LSB)
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//  0: |                         SvcParamKey                           |
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//  2: |                        length of value                        |
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+//  4: |                                                               |
+//     /                      SvcParamValue                            /
+//     /                                                               /
+//     +---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+---+
+
+// HTTPS records have the same format as SVCB records, for the HTTP scheme.
+
+type RDataSVCB struct {
+	Priority uint16
+	Target   string
+	Params   []SVCBParam
+}
+
+func (rdata *RDataSVCB) String() string {
+	svcb := []string{strconv.Itoa(int(rdata.Priority)), rdata.Target}
+	for _, param := range rdata.Params {
+		svcb = append(svcb, param.String())
+	}
+	return strings.Join(svcb, " ")
+}
+
+func (rdata *RDataSVCB) WriteRecordData(writer *dnsWriter) error {
+	params := slices.Clone(rdata.Params)
+	slices.SortStableFunc(params, func(a, b SVCBParam) int {
+		return int(a.Key()) - int(b.Key())
+	})
+
+	writer.writeUint16(rdata.Priority)
+	if err := writer.writeDomainName(rdata.Target); err != nil {
+		return err
+	}
+
+	for i, param := range params {
+		if i > 0 && param.Key() == params[i-1].Key() {
+			return invalidRecordDataError(fmt.Sprintf("SVCB RData: duplicate key %s", SVCBParamKey(param.Key())))
+		}
+
+		writer.writeUint16(param.Key())
+
+		// Write a placeholder for the value length, and fill it in once the value is written
+		lengthOffset := writer.offset
+		writer.writeUint16(0)
+		valueOffset := writer.offset
+
+		if err := param.WriteParamData(writer); err != nil {
+			return invalidRecordDataError(fmt.Sprintf("SVCB RData: %s", err.Error()))
+		}
+
+		length := writer.offset - valueOffset
+		if length > 0xFFFF {
+			return invalidRecordDataError(fmt.Sprintf("SVCB RData: %s value too long", SVCBParamKey(param.Key())))
+		}
+		writer.data[lengthOffset] = byte(length >> 8)
+		writer.data[lengthOffset+1] = byte(length & 0xFF)
+	}
+	return nil
+}
+
+func (rdata *RDataSVCB) ReadRecordData(reader *dnsReader, length uint16) (err error) {
+	end := reader.offset + int(length)
+	if length < 3 || end > len(reader.data) {
+		return invalidRecordDataError(fmt.Sprintf("SVCB RData: invalid length: %d", length))
+	}
+
+	rdata.Priority = reader.readUint16()
+	rdata.Target, err = reader.readDomainName()
+	if err != nil {
+		return fmt.Errorf("%w: SVCB RData: %w", ErrInvalidRecordData, err)
+	}
+	if reader.offset > end {
+		return invalidRecordDataError("SVCB RData: target name exceeds record length")
+	}
+
+	rdata.Params = nil
+	for reader.offset < end {
+		if reader.offset+4 > end {
+			return invalidRecordDataError("SVCB RData: parameter header too short")
+		}
+
+		key := reader.readUint16()
+		valueLength := reader.readUint16()
+
+		if len(rdata.Params) > 0 && key <= rdata.Params[len(rdata.Params)-1].Key() {
+			return invalidRecordDataError(fmt.Sprintf("SVCB RData: key %s out of order", SVCBParamKey(key)))
+		}
+		if reader.offset+int(valueLength) > end {
+			return invalidRecordDataError(fmt.Sprintf("SVCB RData: %s value too long: %d", SVCBParamKey(key), valueLength))
+		}
+
+		param := getSVCBParamStruct(key)
+		valueEnd := reader.offset + int(valueLength)
+
+		if err = param.ReadParamData(reader, valueLength); err != nil {
+			return invalidRecordDataError(fmt.Sprintf("SVCB RData: %s", err.Error()))
+		}
+		reader.offset = valueEnd
+
+		rdata.Params = append(rdata.Params, param)
+	}
+	return nil
+}
+
+// parseSVCBFields reads the presentation format of SVCB and HTTPS records [RFC9460]: the priority,
+// the target and the SvcParams as key=value fields, ex. "1 . alpn=h2,h3 port=8443". Values may be
+// quoted, and the commas of alpn values escaped as "\,".
+func parseSVCBFields(fields []string) (RData, error) {
+	if err := checkFieldCount(fields, 2, -1); err != nil {
+		return nil, err
+	}
+	priority, err := parseUint(fields[0], 16)
+	if err != nil {
+		return nil, err
+	}
+
+	rdata := &RDataSVCB{Priority: uint16(priority), Target: Fqdn(fields[1])}
+	for _, field := range fields[2:] {
+		param, err := parseSVCBParam(field)
+		if err != nil {
+			return nil, err
+		}
+		rdata.Params = append(rdata.Params, param)
+	}
+	return rdata, nil
+}
+
+// parseSVCBParam reads a SvcParam in presentation format, "key" or "key=value", as printed by its String method.
+func parseSVCBParam(field string) (SVCBParam, error) {
+	name, rawValue, hasValue := strings.Cut(field, "=")
+	key, err := parseSVCBParamKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, known := svcbParamKeyNames[key]; known && !hasValue && key != SVCBNoDefaultALPN {
+		return nil, fmt.Errorf("%s: no value", SVCBParamKey(key))
+	}
+
+	switch key {
+	case SVCBMandatory, SVCBALPN, SVCBIPv4Hint, SVCBIPv6Hint:
+		values, err := splitSVCBValueList(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", SVCBParamKey(key), err)
+		}
+		return parseSVCBValueList(key, values)
+
+	case SVCBNoDefaultALPN:
+		if hasValue {
+			return nil, fmt.Errorf("no-default-alpn: unexpected value")
+		}
+		return &SVCBParamNoDefaultALPN{}, nil
+	}
+
+	value, err := parseEscapedValue(rawValue)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", SVCBParamKey(key), err)
+	}
+	switch key {
+	case SVCBPort:
+		port, err := parseUint(value, 16)
+		if err != nil {
+			return nil, fmt.Errorf("port: %w", err)
+		}
+		return &SVCBParamPort{Port: uint16(port)}, nil
+	case SVCBECH:
+		config, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("ech: %w", err)
+		}
+		return &SVCBParamECH{Config: config}, nil
+	case SVCBDoHPath:
+		return &SVCBParamDoHPath{Template: value}, nil
+	}
+	return &SVCBParamUnknown{ParamKey: key, Value: []byte(value)}, nil
+}
+
+// parseSVCBValueList reads the comma-separated values of the mandatory, alpn and hint SvcParams.
+func parseSVCBValueList(key uint16, values []string) (SVCBParam, error) {
+	switch key {
+	case SVCBMandatory:
+		param := &SVCBParamMandatory{}
+		for _, value := range values {
+			mandatoryKey, err := parseSVCBParamKey(value)
+			if err != nil {
+				return nil, fmt.Errorf("mandatory: %w", err)
+			}
+			param.Keys = append(param.Keys, mandatoryKey)
+		}
+		return param, nil
+
+	case SVCBALPN:
+		return &SVCBParamALPN{Protocols: values}, nil
+	}
+
+	hints := make([]netip.Addr, 0, len(values))
+	for _, value := range values {
+		hint, err := netip.ParseAddr(value)
+		if err != nil || (key == SVCBIPv4Hint && !hint.Is4()) || (key == SVCBIPv6Hint && !hint.Is6()) {
+			return nil, fmt.Errorf("%s: %w: %s", SVCBParamKey(key), ErrInvalidIP, value)
+		}
+		hints = append(hints, hint)
+	}
+	if key == SVCBIPv4Hint {
+		return &SVCBParamIPv4Hint{Hints: hints}, nil
+	}
+	return &SVCBParamIPv6Hint{Hints: hints}, nil
+}
+
+// splitSVCBValueList splits a value list at its commas which are not escaped, and replaces the escapes of its values.
+func splitSVCBValueList(rawValue string) ([]string, error) {
+	var values []string
+	start := 0
+	for i := 0; i <= len(rawValue); i++ {
+		if i < len(rawValue) && rawValue[i] == '\\' {
+			i++
+			continue
+		}
+		if i < len(rawValue) && rawValue[i] != ',' {
+			continue
+		}
+
+		value, err := parseEscapedValue(rawValue[start:i])
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			return nil, fmt.Errorf("empty value in list: %s", rawValue)
+		}
+		values = append(values, value)
+		start = i + 1
+	}
+	return values, nil
+}
+
+// parseSVCBParamKey reads the name of a SvcParamKey, ex. "alpn", or the keyN format of unknown keys [RFC9460].
+func parseSVCBParamKey(name string) (uint16, error) {
+	for key, keyName := range svcbParamKeyNames {
+		if name == keyName {
+			return key, nil
+		}
+	}
+	if number, ok := strings.CutPrefix(name, "key"); ok {
+		if key, err := strconv.ParseUint(number, 10, 16); err == nil {
+			return uint16(key), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid SvcParamKey: %s", name)
+}
+
+// ------------------- SVCB PARAMS
+
+// SVCBParam is a single {key, value} pair in the SvcParams of an SVCB or HTTPS record.
+type SVCBParam interface {
+	Key() uint16
+	String() string
+	WriteParamData(writer *dnsWriter) error
+	ReadParamData(reader *dnsReader, length uint16) error
+}
+
+type SVCBParamKey uint16
+
+const (
+	SVCBMandatory     uint16 = 0 // Mandatory keys in this RR [RFC9460]
+	SVCBALPN          uint16 = 1 // Additional supported protocols [RFC9460]
+	SVCBNoDefaultALPN uint16 = 2 // No support for default protocol [RFC9460]
+	SVCBPort          uint16 = 3 // Port for alternative endpoint [RFC9460]
+	SVCBIPv4Hint      uint16 = 4 // IPv4 address hints [RFC9460]
+	SVCBECH           uint16 = 5 // TLS Encrypted ClientHello config [RFC9460]
+	SVCBIPv6Hint      uint16 = 6 // IPv6 address hints [RFC9460]
+	SVCBDoHPath       uint16 = 7 // DNS over HTTPS path template [RFC9461]
+)
+
+var svcbParamKeyNames = map[uint16]string{
+	SVCBMandatory:     "mandatory",
+	SVCBALPN:          "alpn",
+	SVCBNoDefaultALPN: "no-default-alpn",
+	SVCBPort:          "port",
+	SVCBIPv4Hint:      "ipv4hint",
+	SVCBECH:           "ech",
+	SVCBIPv6Hint:      "ipv6hint",
+	SVCBDoHPath:       "dohpath",
+}
+
+func (key SVCBParamKey) String() string {
+	if n, ok := svcbParamKeyNames[uint16(key)]; ok {
+		return n
+	}
+	return fmt.Sprintf("key%d", uint16(key))
+}
+
+func getSVCBParamStruct(key uint16) SVCBParam {
+	var param SVCBParam
+	switch key {
+	case SVCBMandatory:
+		param = &SVCBParamMandatory{}
+	case SVCBALPN:
+		param = &SVCBParamALPN{}
+	case SVCBNoDefaultALPN:
+		param = &SVCBParamNoDefaultALPN{}
+	case SVCBPort:
+		param = &SVCBParamPort{}
+	case SVCBIPv4Hint:
+		param = &SVCBParamIPv4Hint{}
+	case SVCBECH:
+		param = &SVCBParamECH{}
+	case SVCBIPv6Hint:
+		param = &SVCBParamIPv6Hint{}
+	case SVCBDoHPath:
+		param = &SVCBParamDoHPath{}
+	default:
+		param = &SVCBParamUnknown{ParamKey: key}
+	}
+	return param
+}
+
+// -------------- MANDATORY
+// A list of keys that a client must understand to use the record, in increasing order.
+
+type SVCBParamMandatory struct {
+	Keys []uint16
+}
+
+func (param *SVCBParamMandatory) Key() uint16 {
+	return SVCBMandatory
+}
+
+func (param *SVCBParamMandatory) String() string {
+	keys := make([]string, 0, len(param.Keys))
+	for _, key := range param.Keys {
+		keys = append(keys, SVCBParamKey(key).String())
+	}
+	return SVCBParamKey(SVCBMandatory).String() + "=" + strings.Join(keys, ",")
+}
+
+func (param *SVCBParamMandatory) WriteParamData(writer *dnsWriter) error {
+	for _, key := range param.Keys {
+		writer.writeUint16(key)
+	}
+	return nil
+}
+
+func (param *SVCBParamMandatory) ReadParamData(reader *dnsReader, length uint16) (err error) {
+	if length == 0 || length%2 != 0 {
+		return fmt.Errorf("mandatory: invalid length: %d", length)
+	}
+
+	param.Keys = make([]uint16, 0, length/2)
+	for i := 0; i < int(length)/2; i++ {
+		param.Keys = append(param.Keys, reader.readUint16())
+	}
+	return nil
+}
+
+// -------------- ALPN
+// A list of the Application-Layer Protocol Negotiation identifiers supported by the endpoint,
+// each prefixed by its length, ex. "h2" or "h3".
+
+type SVCBParamALPN struct {
+	Protocols []string
+}
+
+func (param *SVCBParamALPN) Key() uint16 {
+	return SVCBALPN
+}
+
+func (param *SVCBParamALPN) String() string {
+	protocols := make([]string, 0, len(param.Protocols))
+	for _, protocol := range param.Protocols {
+		// Commas separate the protocols in the presentation format, so they are escaped in protocol identifiers
+		protocols = append(protocols, strings.ReplaceAll(strings.ReplaceAll(protocol, `\`, `\\`), ",", `\,`))
+	}
+	return SVCBParamKey(SVCBALPN).String() + "=" + strings.Join(protocols, ",")
+}
+
+func (param *SVCBParamALPN) WriteParamData(writer *dnsWriter) error {
+	for _, protocol := range param.Protocols {
+		if len(protocol) == 0 || len(protocol) > 255 {
+			return fmt.Errorf("alpn: invalid protocol length: %d", len(protocol))
+		}
+		writer.writeData(append([]byte{byte(len(protocol))}, protocol...))
+	}
+	return nil
+}
+
+func (param *SVCBParamALPN) ReadParamData(reader *dnsReader, length uint16) (err error) {
+	if length == 0 {
+		return fmt.Errorf("alpn: empty value")
+	}
+
+	end := reader.offset + int(length)
+	param.Protocols = nil
+	for reader.offset < end {
+		protocol, err := reader.readLengthPrefixedData(end)
+		if err != nil {
+			return fmt.Errorf("alpn: %w", err)
+		}
+		if len(protocol) == 0 {
+			return fmt.Errorf("alpn: empty protocol")
+		}
+		param.Protocols = append(param.Protocols, string(protocol))
+	}
+	return nil
+}
+
+// -------------- NO-DEFAULT-ALPN
+// No value: the endpoint does not support the default protocol of the scheme, ex. HTTP/1.1 for HTTPS records.
+
+type SVCBParamNoDefaultALPN struct{}
+
+func (param *SVCBParamNoDefaultALPN) Key() uint16 {
+	return SVCBNoDefaultALPN
+}
+
+func (param *SVCBParamNoDefaultALPN) String() string {
+	return SVCBParamKey(SVCBNoDefaultALPN).String()
+}
+
+func (param *SVCBParamNoDefaultALPN) WriteParamData(writer *dnsWriter) error {
+	return nil
+}
+
+func (param *SVCBParamNoDefaultALPN) ReadParamData(reader *dnsReader, length uint16) (err error) {
+	if length != 0 {
+		return fmt.Errorf("no-default-alpn: unexpected value")
+	}
+	return nil
+}
+
+// -------------- PORT
+// The 16 bit TCP or UDP port of the endpoint.
+
+type SVCBParamPort struct {
+	Port uint16
+}
+
+func (param *SVCBParamPort) Key() uint16 {
+	return SVCBPort
+}
+
+func (param *SVCBParamPort) String() string {
+	return SVCBParamKey(SVCBPort).String() + "=" + strconv.Itoa(int(param.Port))
+}
+
+func (param *SVCBParamPort) WriteParamData(writer *dnsWriter) error {
+	writer.writeUint16(param.Port)
+	return nil
+}
+
+func (param *SVCBParamPort) ReadParamData(reader *dnsReader, length uint16) (err error) {
+	if length != 2 {
+		return fmt.Errorf("port: invalid length: %d", length)
+	}
+	param.Port = reader.readUint16()
+	return nil
+}
+
+// -------------- IPV4HINT / IPV6HINT
+// A list of IPv4 or IPv6 addresses the client may use to reach the endpoint.
+
+type SVCBParamIPv4Hint struct {
+	Hints []netip.Addr
+}
+
+func (param *SVCBParamIPv4Hint) Key() uint16 {
+	return SVCBIPv4Hint
+}
+
+func (param *SVCBParamIPv4Hint) String() string {
+	return SVCBParamKey(SVCBIPv4Hint).String() + "=" + getHintsString(param.Hints)
+}
+
+func (param *SVCBParamIPv4Hint) WriteParamData(writer *dnsWriter) error {
+	return writeHints(writer, param.Hints, 4)
+}
+
+func (param *SVCBParamIPv4Hint) ReadParamData(reader *dnsReader, length uint16) (err error) {
+	param.Hints, err = reader.readHints(length, 4)
+	if err != nil {
+		return fmt.Errorf("ipv4hint: %w", err)
+	}
+	return nil
+}
+
+type SVCBParamIPv6Hint struct {
+	Hints []netip.Addr
+}
+
+func (param *SVCBParamIPv6Hint) Key() uint16 {
+	return SVCBIPv6Hint
+}
+
+func (param *SVCBParamIPv6Hint) String() string {
+	return SVCBParamKey(SVCBIPv6Hint).String() + "=" + getHintsString(param.Hints)
+}
+
+func (param *SVCBParamIPv6Hint) WriteParamData(writer *dnsWriter) error {
+	return writeHints(writer, param.Hints, 16)
+}
+
+func (param *SVCBParamIPv6Hint) ReadParamData(reader *dnsReader, length uint16) (err error) {
+	param.Hints, err = reader.readHints(length, 16)
+	if err != nil {
+		return fmt.Errorf("ipv6hint: %w", err)
+	}
+	return nil
+}
+
+func getHintsString(hints []netip.Addr) string {
+	addresses := make([]string, 0, len(hints))
+	for _, hint := range hints {
+		addresses = append(addresses, hint.String())
+	}
+	return strings.Join(addresses, ",")
+}
+
+func writeHints(writer *dnsWriter, hints []netip.Addr, addressLength int) error {
+	for _, hint := range hints {
+		if hint.BitLen() != addressLength*8 {
+			return invalidIPError(hint.String())
+		}
+		writer.writeData(hint.AsSlice())
+	}
+	return nil
+}
+
+func (reader *dnsReader) readHints(length uint16, addressLength int) ([]netip.Addr, error) {
+	if length == 0 || int(length)%addressLength != 0 {
+		return nil, fmt.Errorf("invalid length: %d", length)
+	}
+
+	hints := make([]netip.Addr, 0, int(length)/addressLength)
+	for i := 0; i < int(length)/addressLength; i++ {
+		data, err := reader.readUntil(addressLength)
+		if err != nil {
+			return nil, err
+		}
+		hint, _ := netip.AddrFromSlice(data)
+		hints = append(hints, hint)
+	}
+	return hints, nil
+}
+
+// -------------- ECH
+// An ECHConfigList, the TLS Encrypted ClientHello configuration of the endpoint, shown in base64.
+
+type SVCBParamECH struct {
+	Config []byte
+}
+
+func (param *SVCBParamECH) Key() uint16 {
+	return SVCBECH
+}
+
+func (param *SVCBParamECH) String() string {
+	return SVCBParamKey(SVCBECH).String() + "=" + base64.StdEncoding.EncodeToString(param.Config)
+}
+
+func (param *SVCBParamECH) WriteParamData(writer *dnsWriter) error {
+	writer.writeData(param.Config)
+	return nil
+}
+
+func (param *SVCBParamECH) ReadParamData(reader *dnsReader, length uint16) (err error) {
+	data, err := reader.readUntil(int(length))
+	if err != nil {
+		return fmt.Errorf("ech: %w", err)
+	}
+	param.Config = append([]byte{}, data...)
+	return nil
+}
+
+// -------------- DOHPATH
+// The relative URI template of a DNS over HTTPS endpoint, ex. "/dns-query{?dns}".
+
+type SVCBParamDoHPath struct {
+	Template string
+}
+
+func (param *SVCBParamDoHPath) Key() uint16 {
+	return SVCBDoHPath
+}
+
+func (param *SVCBParamDoHPath) String() string {
+	return SVCBParamKey(SVCBDoHPath).String() + "=" + getQuotedValue([]byte(param.Template))
+}
+
+func (param *SVCBParamDoHPath) WriteParamData(writer *dnsWriter) error {
+	writer.writeData([]byte(param.Template))
+	return nil
+}
+
+func (param *SVCBParamDoHPath) ReadParamData(reader *dnsReader, length uint16) (err error) {
+	data, err := reader.readUntil(int(length))
+	if err != nil {
+		return fmt.Errorf("dohpath: %w", err)
+	}
+	param.Template = string(data)
+	return nil
+}
+
+// -------------- UNKNOWN
+
+type SVCBParamUnknown struct {
+	ParamKey uint16
+	Value    []byte
+}
+
+func (param *SVCBParamUnknown) Key() uint16 {
+	return param.ParamKey
+}
+
+func (param *SVCBParamUnknown) String() string {
+	key := SVCBParamKey(param.ParamKey).String()
+	if len(param.Value) == 0 {
+		return key
+	}
+	return key + "=" + getQuotedValue(param.Value)
+}
+
+func (param *SVCBParamUnknown) WriteParamData(writer *dnsWriter) error {
+	writer.writeData(param.Value)
+	return nil
+}
+
+func (param *SVCBParamUnknown) ReadParamData(reader *dnsReader, length uint16) (err error) {
+	data, err := reader.readUntil(int(length))
+	if err != nil {
+		return err
+	}
+	param.Value = append([]byte{}, data...)
+	return nil
+}
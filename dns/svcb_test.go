@@ -0,0 +1,301 @@
+package dns
+
+import (
+	"bytes"
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestRDataSVCB(t *testing.T) {
+	fooExampleCom := []byte{3, 'f', 'o', 'o', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0}
+
+	// Test vectors of RFC9460 Appendix D
+	tests := []struct {
+		name       string
+		data       []byte
+		want       RData
+		wantString string
+		wantError  error
+	}{
+		{
+			name: "AliasMode",
+			data: append([]byte{
+				0, 0, // Priority: 0
+			}, fooExampleCom...),
+			want: &RDataSVCB{
+				Priority: 0,
+				Target:   "foo.example.com.",
+			},
+			wantString: "0 foo.example.com.",
+			wantError:  nil,
+		},
+		{
+			name: "ServiceMode with root target",
+			data: []byte{
+				0, 1, // Priority: 1
+				0, // Target: .
+			},
+			want: &RDataSVCB{
+				Priority: 1,
+				Target:   ".",
+			},
+			wantString: "1 .",
+			wantError:  nil,
+		},
+		{
+			name: "Port",
+			data: append(append([]byte{0, 16}, fooExampleCom...),
+				0, 3, 0, 2, 0, 53, // port=53
+			),
+			want: &RDataSVCB{
+				Priority: 16,
+				Target:   "foo.example.com.",
+				Params:   []SVCBParam{&SVCBParamPort{Port: 53}},
+			},
+			wantString: "16 foo.example.com. port=53",
+			wantError:  nil,
+		},
+		{
+			name: "Unknown key",
+			data: append(append([]byte{0, 1}, fooExampleCom...),
+				0x02, 0x9b, 0, 5, 'h', 'e', 'l', 'l', 'o', // key667=hello
+			),
+			want: &RDataSVCB{
+				Priority: 1,
+				Target:   "foo.example.com.",
+				Params:   []SVCBParam{&SVCBParamUnknown{ParamKey: 667, Value: []byte("hello")}},
+			},
+			wantString: "1 foo.example.com. key667=\"hello\"",
+			wantError:  nil,
+		},
+		{
+			name: "Unknown key with escaped value",
+			data: append(append([]byte{0, 1}, fooExampleCom...),
+				0x02, 0x9b, 0, 9, 'h', 'e', 'l', 'l', 'o', 0xd2, 'q', 'u', 'x', // key667="hello\210qux"
+			),
+			want: &RDataSVCB{
+				Priority: 1,
+				Target:   "foo.example.com.",
+				Params:   []SVCBParam{&SVCBParamUnknown{ParamKey: 667, Value: []byte("hello\xd2qux")}},
+			},
+			wantString: "1 foo.example.com. key667=\"hello\\210qux\"",
+			wantError:  nil,
+		},
+		{
+			name: "IPv6 hints",
+			data: append(append([]byte{0, 1}, fooExampleCom...),
+				0, 6, 0, 32,
+				0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01,
+				0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x53, 0, 0x01,
+			),
+			want: &RDataSVCB{
+				Priority: 1,
+				Target:   "foo.example.com.",
+				Params: []SVCBParam{&SVCBParamIPv6Hint{Hints: []netip.Addr{
+					netip.MustParseAddr("2001:db8::1"),
+					netip.MustParseAddr("2001:db8::53:1"),
+				}}},
+			},
+			wantString: "1 foo.example.com. ipv6hint=2001:db8::1,2001:db8::53:1",
+			wantError:  nil,
+		},
+		{
+			name: "Mandatory, ALPN and IPv4 hint",
+			data: []byte{
+				0, 16, // Priority: 16
+				3, 'f', 'o', 'o', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'o', 'r', 'g', 0, // Target: foo.example.org.
+				0, 0, 0, 4, 0, 1, 0, 4, // mandatory=alpn,ipv4hint
+				0, 1, 0, 9, 2, 'h', '2', 5, 'h', '3', '-', '1', '9', // alpn=h2,h3-19
+				0, 4, 0, 4, 192, 0, 2, 1, // ipv4hint=192.0.2.1
+			},
+			want: &RDataSVCB{
+				Priority: 16,
+				Target:   "foo.example.org.",
+				Params: []SVCBParam{
+					&SVCBParamMandatory{Keys: []uint16{SVCBALPN, SVCBIPv4Hint}},
+					&SVCBParamALPN{Protocols: []string{"h2", "h3-19"}},
+					&SVCBParamIPv4Hint{Hints: []netip.Addr{netip.MustParseAddr("192.0.2.1")}},
+				},
+			},
+			wantString: "16 foo.example.org. mandatory=alpn,ipv4hint alpn=h2,h3-19 ipv4hint=192.0.2.1",
+			wantError:  nil,
+		},
+		{
+			name: "ALPN with escaped comma",
+			data: []byte{
+				0, 16, 0,
+				0, 1, 0, 12, 8, 'f', '\\', 'o', 'o', ',', 'b', 'a', 'r', 2, 'h', '2', // alpn=f\\oo\,bar,h2
+			},
+			want: &RDataSVCB{
+				Priority: 16,
+				Target:   ".",
+				Params:   []SVCBParam{&SVCBParamALPN{Protocols: []string{"f\\oo,bar", "h2"}}},
+			},
+			wantString: "16 . alpn=f\\\\oo\\,bar,h2",
+			wantError:  nil,
+		},
+		{
+			name: "No default ALPN, ECH and DoH path",
+			data: []byte{
+				0, 1, 0,
+				0, 1, 0, 3, 2, 'h', '2', // alpn=h2
+				0, 2, 0, 0, // no-default-alpn
+				0, 5, 0, 3, 0x01, 0x02, 0x03, // ech=AQID
+				0, 7, 0, 16, '/', 'd', 'n', 's', '-', 'q', 'u', 'e', 'r', 'y', '{', '?', 'd', 'n', 's', '}', // dohpath=/dns-query{?dns}
+			},
+			want: &RDataSVCB{
+				Priority: 1,
+				Target:   ".",
+				Params: []SVCBParam{
+					&SVCBParamALPN{Protocols: []string{"h2"}},
+					&SVCBParamNoDefaultALPN{},
+					&SVCBParamECH{Config: []byte{0x01, 0x02, 0x03}},
+					&SVCBParamDoHPath{Template: "/dns-query{?dns}"},
+				},
+			},
+			wantString: "1 . alpn=h2 no-default-alpn ech=AQID dohpath=\"/dns-query{?dns}\"",
+			wantError:  nil,
+		},
+		{
+			name:      "Invalid SVCB record: keys out of order",
+			data:      []byte{0, 1, 0, 0, 4, 0, 4, 192, 0, 2, 1, 0, 3, 0, 2, 0, 53},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Invalid SVCB record: duplicate keys",
+			data:      []byte{0, 1, 0, 0, 3, 0, 2, 0, 53, 0, 3, 0, 2, 0, 80},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Invalid SVCB record: value exceeds record length",
+			data:      []byte{0, 1, 0, 0, 3, 0, 4, 0, 53},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Invalid SVCB record: port too long",
+			data:      []byte{0, 1, 0, 0, 3, 0, 3, 0, 53, 0},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Invalid SVCB record: IPv4 hint length",
+			data:      []byte{0, 1, 0, 0, 4, 0, 3, 192, 0, 2},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Invalid SVCB record: empty ALPN",
+			data:      []byte{0, 1, 0, 0, 1, 0, 0},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Invalid SVCB record: no-default-alpn with value",
+			data:      []byte{0, 1, 0, 0, 2, 0, 1, 0},
+			wantError: ErrInvalidRecordData,
+		},
+		{
+			name:      "Invalid SVCB record: too short",
+			data:      []byte{0, 1},
+			wantError: ErrInvalidRecordData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &RDataSVCB{}
+			reader := &dnsReader{data: tt.data}
+
+			err := got.ReadRecordData(reader, uint16(len(tt.data)))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("Decode() error = %v, want error = %v, data = %v\n", err, tt.wantError, tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error = %v, data = %v\n", err, tt.data)
+			}
+
+			// Test Decode
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %+v, want = %+v, data = %v\n", got, tt.want, tt.data)
+			}
+
+			// Test String
+			gotString := got.String()
+			if gotString != tt.wantString {
+				t.Errorf("String() got = \"%s\", want = \"%s\", data = %v\n", gotString, tt.wantString, tt.data)
+			}
+
+			// Test Parse
+			parsed, err := ParseRData(SVCB, gotString)
+			if err != nil || !reflect.DeepEqual(parsed, tt.want) {
+				t.Errorf("ParseRData() got = %+v, error = %v, want = %+v\n", parsed, err, tt.want)
+			}
+
+			// Test Encode
+			writer := &dnsWriter{}
+			if err := got.WriteRecordData(writer); err != nil {
+				t.Fatalf("Encode() error = %v, data = %v\n", err, tt.data)
+			}
+
+			if !bytes.Equal(writer.data, tt.data) {
+				t.Errorf("Encode() got = %v, want = %v\n", writer.data, tt.data)
+			}
+		})
+	}
+}
+
+func TestRDataSVCBEncodeSortsParams(t *testing.T) {
+	rdata := &RDataSVCB{
+		Priority: 1,
+		Target:   ".",
+		Params: []SVCBParam{
+			&SVCBParamPort{Port: 8443},
+			&SVCBParamALPN{Protocols: []string{"h3"}},
+		},
+	}
+	want := []byte{0, 1, 0, 0, 1, 0, 3, 2, 'h', '3', 0, 3, 0, 2, 0x20, 0xfb}
+
+	writer := &dnsWriter{}
+	if err := rdata.WriteRecordData(writer); err != nil {
+		t.Fatalf("Encode() unexpected error = %v\n", err)
+	}
+	if !bytes.Equal(writer.data, want) {
+		t.Errorf("Encode() got = %v, want = %v\n", writer.data, want)
+	}
+
+	// The record's own parameters keep their order
+	if rdata.Params[0].Key() != SVCBPort {
+		t.Errorf("Encode() reordered the record's parameters: %v\n", rdata.String())
+	}
+
+	rdata.Params = append(rdata.Params, &SVCBParamPort{Port: 443})
+	if err := rdata.WriteRecordData(&dnsWriter{}); !errors.Is(err, ErrInvalidRecordData) {
+		t.Errorf("Encode() error = %v, want = %v\n", err, ErrInvalidRecordData)
+	}
+}
+
+func TestDecodeHTTPSRecord(t *testing.T) {
+	data := []byte{
+		7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, // Name: example.com.
+		0, 65, // Type: HTTPS
+		0, 1, // Class: IN
+		0, 0, 0x0e, 0x10, // TTL: 3600
+		0, 10, // RDLength
+		0, 1, 0, // Priority 1, target .
+		0, 1, 0, 3, 2, 'h', '2', // alpn=h2
+	}
+
+	reader := &dnsReader{data: data}
+	record, err := reader.readResourceRecord()
+	if err != nil {
+		t.Fatalf("readResourceRecord() unexpected error = %v\n", err)
+	}
+
+	want := "1 . alpn=h2"
+	if _, ok := record.RData.(*RDataSVCB); !ok || record.RData.String() != want {
+		t.Errorf("readResourceRecord() got = %v, want = %v\n", record.RData, want)
+	}
+}
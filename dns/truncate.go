@@ -0,0 +1,115 @@
+package dns
+
+// Truncation of responses which do not fit in the UDP payload size of the client [RFC1035] [RFC2181]:
+// records are dropped from the end, and an RRset is either sent whole or not at all.
+// The TC flag is only set when records of the answer or authority sections are dropped:
+// the additional section is optional, so the client need not retry over TCP without it.
+
+// Len returns the size of the encoded message in bytes, with name compression.
+//
+// Returns:
+//   - int: The size of the encoded message.
+//   - error: If the message cannot be encoded, see EncodeMessage.
+func (message Message) Len() (int, error) {
+	data, err := EncodeMessage(message)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// Truncate drops the records of the message which do not fit in the size, keeping the header,
+// questions and EDNS parameters, and sets the TC flag if records of the answer or authority
+// sections are dropped [RFC2181]. A final SIG(0) or TSIG record is dropped with the others, so
+// a truncated message must be signed again. The header's section counts are updated.
+// The message is left unchanged if it fits.
+//
+// Parameters:
+//   - maxSize: The largest size of the encoded message, ex. the UDP payload size of the client.
+//
+// Returns:
+//   - error: If the message cannot be encoded, see EncodeMessage.
+func (message *Message) Truncate(maxSize int) error {
+	size, err := message.Len()
+	if err != nil || size <= maxSize {
+		return err
+	}
+
+	truncated := *message
+	truncated.Answers, truncated.NameServers, truncated.Additionals = []ResourceRecord{}, []ResourceRecord{}, []ResourceRecord{}
+
+	// The records are written after the header and questions as they are kept, so the size of the
+	// message is that of the message without records, which includes the OPT record, plus the
+	// size of the records written. The OPT record only comes before a final SIG(0) or TSIG record,
+	// which does not change the size of the records before it.
+	baseSize, err := truncated.Len()
+	if err != nil {
+		return err
+	}
+	writer := &dnsWriter{
+		data:        make([]byte, DNSHeaderLength),
+		compression: map[string]int{},
+	}
+	writer.writeHeader(truncated)
+	if err := writer.writeQuestions(truncated.Questions); err != nil {
+		return err
+	}
+	maxLength := maxSize - baseSize + len(writer.data)
+
+	for _, section := range []struct {
+		records  []ResourceRecord
+		kept     *[]ResourceRecord
+		required bool
+	}{
+		{message.Answers, &truncated.Answers, true},
+		{message.NameServers, &truncated.NameServers, true},
+		{message.Additionals, &truncated.Additionals, false},
+	} {
+		fits, err := writer.keepRecords(section.records, section.kept, maxLength)
+		if err != nil {
+			return err
+		}
+		if !fits {
+			truncated.Header.Flags.Truncated = truncated.Header.Flags.Truncated || section.required
+			break
+		}
+	}
+
+	truncated.UpdateHeaderCounts()
+	*message = truncated
+	return nil
+}
+
+// keepRecords writes the records and appends them to the kept section while the written data
+// fits in the length. If a record does not fit, the records of its RRset already kept are dropped
+// too, and the writer must not be used to keep more records.
+//
+// Returns:
+//   - bool: Whether all the records fit.
+//   - error: If a record cannot be encoded.
+func (writer *dnsWriter) keepRecords(records []ResourceRecord, kept *[]ResourceRecord, maxLength int) (bool, error) {
+	for _, record := range records {
+		if err := writer.writeResourceRecord(record); err != nil {
+			return false, err
+		}
+		if len(writer.data) <= maxLength {
+			*kept = append(*kept, record)
+			continue
+		}
+
+		remaining := (*kept)[:0]
+		for _, keptRecord := range *kept {
+			if !isSameRRset(keptRecord, record) {
+				remaining = append(remaining, keptRecord)
+			}
+		}
+		*kept = remaining
+		return false, nil
+	}
+	return true, nil
+}
+
+// isSameRRset reports whether two records have the same owner name, class and type.
+func isSameRRset(a ResourceRecord, b ResourceRecord) bool {
+	return EqualNames(a.Name, b.Name) && a.RClass == b.RClass && a.RType == b.RType
+}
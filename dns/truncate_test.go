@@ -0,0 +1,117 @@
+package dns
+
+import (
+	"fmt"
+	"net/netip"
+	"testing"
+)
+
+func TestMessageLen(t *testing.T) {
+	message := NewQuery("example.com.", A).WithEDNS(1232, false)
+	data, err := message.Pack()
+	if err != nil {
+		t.Fatalf("Pack() unexpected error = %v\n", err)
+	}
+
+	got, err := message.Len()
+	if err != nil {
+		t.Fatalf("Len() unexpected error = %v\n", err)
+	}
+	if got != len(data) {
+		t.Errorf("Len() got = %d, want = %d\n", got, len(data))
+	}
+}
+
+func TestMessageTruncate(t *testing.T) {
+	getRecords := func(name string, rtype uint16, n int) []ResourceRecord {
+		records := []ResourceRecord{}
+		for i := 0; i < n; i++ {
+			record := ResourceRecord{Name: name, RType: rtype, RClass: IN, TTL: 300, RData: &RDataA{IP: netip.AddrFrom4([4]byte{192, 0, 2, byte(i)})}}
+			if rtype == TXT {
+				record.RData = &RDataTXT{Text: []string{fmt.Sprintf("record %d", i)}}
+			}
+			records = append(records, record)
+		}
+		return records
+	}
+
+	// Each A record takes 16 bytes with a compressed owner name, and the TXT record 23 bytes
+	query := NewQuery("example.com.", A).WithEDNS(1232, false)
+	base, err := NewResponse(query).WithEDNS(1232, false).Len()
+	if err != nil {
+		t.Fatalf("Len() unexpected error = %v\n", err)
+	}
+
+	tests := []struct {
+		name           string
+		answers        []ResourceRecord
+		additionals    []ResourceRecord
+		maxSize        int
+		wantAnswers    int
+		wantAdditional int
+		wantTruncated  bool
+	}{
+		{
+			name:        "Message fits",
+			answers:     getRecords("example.com.", A, 2),
+			maxSize:     base + 32,
+			wantAnswers: 2,
+		},
+		{
+			name:          "RRset dropped whole",
+			answers:       append(getRecords("a.example.com.", TXT, 1), getRecords("example.com.", A, 3)...),
+			maxSize:       base + 23 + 40,
+			wantAnswers:   1,
+			wantTruncated: true,
+		},
+		{
+			name:           "Additional records dropped without TC",
+			answers:        getRecords("example.com.", A, 2),
+			additionals:    getRecords("ns.example.com.", A, 4),
+			maxSize:        base + 32 + 30,
+			wantAnswers:    2,
+			wantAdditional: 0,
+		},
+		{
+			// Each record takes 20 bytes with its first label written and the rest of its owner name compressed
+			name:           "Records fill the size exactly",
+			answers:        getRecords("example.com.", A, 2),
+			additionals:    append(append(getRecords("ns1.example.com.", A, 1), getRecords("ns2.example.com.", A, 1)...), getRecords("ns3.example.com.", A, 1)...),
+			maxSize:        base + 32 + 40,
+			wantAnswers:    2,
+			wantAdditional: 2,
+		},
+		{
+			name:          "Nothing fits",
+			answers:       getRecords("example.com.", A, 2),
+			maxSize:       base,
+			wantAnswers:   0,
+			wantTruncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := NewResponse(query).WithEDNS(1232, false).Answer(tt.answers...).Additional(tt.additionals...)
+			if err := response.Truncate(tt.maxSize); err != nil {
+				t.Fatalf("Truncate() unexpected error = %v\n", err)
+			}
+
+			if len(response.Answers) != tt.wantAnswers || len(response.Additionals) != tt.wantAdditional {
+				t.Errorf("Truncate() got %d answers and %d additionals, want = %d and %d\n", len(response.Answers), len(response.Additionals), tt.wantAnswers, tt.wantAdditional)
+			}
+			if response.Header.Flags.Truncated != tt.wantTruncated {
+				t.Errorf("Truncate() TC flag got = %v, want = %v\n", response.Header.Flags.Truncated, tt.wantTruncated)
+			}
+			if response.Header.AnswerRRCount != uint16(tt.wantAnswers) || response.Header.AdditionalRRCount != uint16(tt.wantAdditional+1) {
+				t.Errorf("Truncate() header counts got = %+v\n", response.Header)
+			}
+			if response.EDNS == nil || len(response.Questions) != 1 {
+				t.Errorf("Truncate() dropped the question or EDNS parameters: %+v\n", response)
+			}
+			if size, _ := response.Len(); size > tt.maxSize && tt.wantAnswers > 0 {
+				t.Errorf("Truncate() size got = %d, want at most %d\n", size, tt.maxSize)
+			}
+		})
+	}
+}
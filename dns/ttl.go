@@ -0,0 +1,39 @@
+package dns
+
+import "time"
+
+// TTL is the time to live of a record in seconds [RFC1035].
+// Values are at most MaxTTL: a TTL with its most significant bit set is treated as zero [RFC2181].
+type TTL uint32
+
+// MaxTTL is the largest TTL, 2^31 - 1 seconds [RFC2181].
+const MaxTTL TTL = 1<<31 - 1
+
+// TTLFromDuration converts a duration to a TTL, rounded down to the second.
+// Negative durations are 0 and durations over MaxTTL seconds are capped at MaxTTL.
+func TTLFromDuration(duration time.Duration) TTL {
+	seconds := int64(duration / time.Second)
+	if seconds <= 0 {
+		return 0
+	}
+	return TTL(min(seconds, int64(MaxTTL)))
+}
+
+// ToDuration converts the TTL to a duration, treating TTLs over MaxTTL as zero [RFC2181].
+func (ttl TTL) ToDuration() time.Duration {
+	return time.Duration(ttl.Normalized()) * time.Second
+}
+
+// Normalized returns the TTL, or 0 if it is over MaxTTL: a TTL received with its most
+// significant bit set is treated as zero [RFC2181].
+func (ttl TTL) Normalized() TTL {
+	if ttl > MaxTTL {
+		return 0
+	}
+	return ttl
+}
+
+// Remaining returns what is left of the TTL once the duration has elapsed, ex. for a cached record, or 0 if it expired.
+func (ttl TTL) Remaining(elapsed time.Duration) TTL {
+	return TTLFromDuration(ttl.ToDuration() - elapsed)
+}
@@ -0,0 +1,57 @@
+package dns
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTLFromDuration(t *testing.T) {
+	tests := []struct {
+		name string
+		data time.Duration
+		want TTL
+	}{
+		{name: "Seconds", data: 5 * time.Minute, want: 300},
+		{name: "Rounded down", data: 1999 * time.Millisecond, want: 1},
+		{name: "Negative", data: -time.Hour, want: 0},
+		{name: "Capped", data: 100 * 365 * 24 * time.Hour, want: MaxTTL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TTLFromDuration(tt.data); got != tt.want {
+				t.Errorf("TTLFromDuration() got = %d, want = %d\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTTLToDuration(t *testing.T) {
+	tests := []struct {
+		name string
+		data TTL
+		want time.Duration
+	}{
+		{name: "Seconds", data: 300, want: 5 * time.Minute},
+		{name: "Max TTL", data: MaxTTL, want: time.Duration(MaxTTL) * time.Second},
+		{name: "Most significant bit set", data: MaxTTL + 1, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.data.ToDuration(); got != tt.want {
+				t.Errorf("ToDuration() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTTLRemaining(t *testing.T) {
+	ttl := TTL(300)
+	if got := ttl.Remaining(100500 * time.Millisecond); got != 199 {
+		t.Errorf("Remaining() got = %d, want = 199\n", got)
+	}
+	if got := ttl.Remaining(time.Hour); got != 0 {
+		t.Errorf("Remaining() after expiry got = %d, want = 0\n", got)
+	}
+}
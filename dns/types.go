@@ -1,5 +1,7 @@
 package dns
 
+import "fmt"
+
 type DNSType uint16
 
 const (
@@ -298,9 +300,10 @@ var dnsTypeNames = map[uint16]string{
 	DLV:        "DLV",
 }
 
+// String returns the mnemonic of the type, or TYPEn for unknown types [RFC3597].
 func (t DNSType) String() string {
 	if n, ok := dnsTypeNames[uint16(t)]; ok {
 		return n
 	}
-	return "UNKNOWN"
+	return fmt.Sprintf("TYPE%d", t)
 }
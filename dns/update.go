@@ -0,0 +1,190 @@
+package dns
+
+import "fmt"
+
+// Dynamic update message format [RFC2136]:
+// An update message uses the same header and sections as a query,
+// but the sections are renamed and the ID, Opcode and section counts
+// are the only header fields used in a request:
+
+//     +---------------------+
+//     |        Header       |
+//     +---------------------+
+//     |         Zone        | specifies the zone to be updated
+//     +---------------------+
+//     |     Prerequisite    | RRs or RRsets which must (not) preexist
+//     +---------------------+
+//     |        Update       | RRs or RRsets to be added or deleted
+//     +---------------------+
+//     |   Additional Data   | additional data
+//     +---------------------+
+
+// The class and TTL of prerequisite and update records carry their meaning [RFC2136]:
+//
+//	CLASS    TYPE     RDATA    Prerequisite meaning
+//	------------------------------------------------
+//	ANY      ANY      empty    Name is in use
+//	ANY      rrset    empty    RRset exists (value independent)
+//	NONE     ANY      empty    Name is not in use
+//	NONE     rrset    empty    RRset does not exist
+//	zone     rrset    rr       RRset exists (value dependent)
+//
+//	CLASS    TYPE     RDATA    Update meaning
+//	------------------------------------------------
+//	ANY      ANY      empty    Delete all RRsets from a name
+//	ANY      rrset    empty    Delete an RRset
+//	NONE     rrset    rr       Delete an RR from an RRset
+//	zone     rrset    rr       Add to an RRset
+
+// Update builds a dynamic update request for a single zone.
+// Records given to its methods only need a name, type, RData and, for additions, a TTL:
+// their class, TTL and RDLength are set according to the prerequisite or update they express.
+type Update struct {
+	Zone          string // Name of the zone to update, ex. "example.com."
+	Class         uint16 // Class of the zone, IN if 0
+	Prerequisites []ResourceRecord
+	Updates       []ResourceRecord
+	Additionals   []ResourceRecord
+}
+
+// NewUpdate returns an empty update request for the given zone in the IN class.
+//
+// Parameters:
+//   - zone: The name of the zone to update, ex. "example.com.".
+func NewUpdate(zone string) *Update {
+	return &Update{
+		Zone:  zone,
+		Class: IN,
+	}
+}
+
+// NameInUse requires the name to own at least one record.
+func (update *Update) NameInUse(name string) {
+	update.Prerequisites = append(update.Prerequisites, newEmptyRecord(name, ALL, ANY))
+}
+
+// NameNotInUse requires the name to own no records.
+func (update *Update) NameNotInUse(name string) {
+	update.Prerequisites = append(update.Prerequisites, newEmptyRecord(name, ALL, NONE))
+}
+
+// RRsetExists requires the name to own records of the given type, whatever their value.
+func (update *Update) RRsetExists(name string, rtype uint16) {
+	update.Prerequisites = append(update.Prerequisites, newEmptyRecord(name, rtype, ANY))
+}
+
+// RRsetNotExists requires the name to own no records of the given type.
+func (update *Update) RRsetNotExists(name string, rtype uint16) {
+	update.Prerequisites = append(update.Prerequisites, newEmptyRecord(name, rtype, NONE))
+}
+
+// RRsetExistsValue requires the RRset of the records' names and types to be exactly these records.
+// Records of different RRsets can be given together.
+func (update *Update) RRsetExistsValue(records ...ResourceRecord) {
+	for _, record := range records {
+		record.RClass = update.getClass()
+		record.TTL = 0
+		update.Prerequisites = append(update.Prerequisites, record)
+	}
+}
+
+// Add adds the records to the zone, with their own TTL.
+func (update *Update) Add(records ...ResourceRecord) {
+	for _, record := range records {
+		record.RClass = update.getClass()
+		update.Updates = append(update.Updates, record)
+	}
+}
+
+// Delete removes the records from the zone, matching them by name, type and RData.
+func (update *Update) Delete(records ...ResourceRecord) {
+	for _, record := range records {
+		record.RClass = NONE
+		record.TTL = 0
+		update.Updates = append(update.Updates, record)
+	}
+}
+
+// DeleteRRset removes all records of the given type from the name.
+func (update *Update) DeleteRRset(name string, rtype uint16) {
+	update.Updates = append(update.Updates, newEmptyRecord(name, rtype, ANY))
+}
+
+// DeleteName removes all records from the name.
+func (update *Update) DeleteName(name string) {
+	update.Updates = append(update.Updates, newEmptyRecord(name, ALL, ANY))
+}
+
+// Message builds the update request message with a random ID.
+//
+// Returns:
+//   - Message: The update request, ready to be encoded.
+//   - error: If a record's RData cannot be encoded.
+func (update *Update) Message() (message Message, err error) {
+	message = Message{
+		Header: Header{
+			Id:    NewID(),
+			Flags: Flags{Opcode: UPDATE},
+		},
+		Questions: []Question{
+			{
+				Name:   update.Zone,
+				QType:  SOA, // The zone section's type must be SOA [RFC2136]
+				QClass: update.getClass(),
+			},
+		},
+	}
+
+	message.Answers, err = getUpdateRecords(update.Prerequisites)
+	if err != nil {
+		return Message{}, fmt.Errorf("prerequisite: %w", err)
+	}
+	message.NameServers, err = getUpdateRecords(update.Updates)
+	if err != nil {
+		return Message{}, fmt.Errorf("update: %w", err)
+	}
+	message.Additionals, err = getUpdateRecords(update.Additionals)
+	if err != nil {
+		return Message{}, fmt.Errorf("additional data: %w", err)
+	}
+
+	message.UpdateHeaderCounts()
+
+	return message, nil
+}
+
+func (update *Update) getClass() uint16 {
+	if update.Class == 0 {
+		return IN
+	}
+	return update.Class
+}
+
+// newEmptyRecord returns a record without RData, as used to match whole names or RRsets.
+func newEmptyRecord(name string, rtype uint16, rclass uint16) ResourceRecord {
+	return ResourceRecord{
+		Name:   name,
+		RType:  rtype,
+		RClass: rclass,
+		RData:  &RDataUnknown{},
+	}
+}
+
+// getUpdateRecords returns a copy of the records with their RDLength set from their RData.
+func getUpdateRecords(records []ResourceRecord) ([]ResourceRecord, error) {
+	updateRecords := make([]ResourceRecord, 0, len(records))
+	for _, record := range records {
+		if record.RData == nil {
+			record.RData = &RDataUnknown{}
+		}
+
+		rdlength, err := getRDLength(record.RData)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", record.Name, DNSType(record.RType), err)
+		}
+		record.RDLength = rdlength
+
+		updateRecords = append(updateRecords, record)
+	}
+	return updateRecords, nil
+}
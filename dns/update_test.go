@@ -0,0 +1,97 @@
+package dns
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestUpdateMessage(t *testing.T) {
+	addressRecord := ResourceRecord{
+		Name:  "www.example.com.",
+		RType: A,
+		TTL:   300,
+		RData: &RDataA{IP: netip.MustParseAddr("192.0.2.1")},
+	}
+
+	update := NewUpdate("example.com.")
+	update.NameInUse("example.com.")
+	update.NameNotInUse("new.example.com.")
+	update.RRsetExists("www.example.com.", A)
+	update.RRsetNotExists("www.example.com.", AAAA)
+	update.RRsetExistsValue(addressRecord)
+	update.DeleteName("old.example.com.")
+	update.DeleteRRset("www.example.com.", TXT)
+	update.Delete(addressRecord)
+	update.Add(addressRecord)
+
+	wantPrerequisites := []ResourceRecord{
+		{Name: "example.com.", RType: ALL, RClass: ANY, RData: &RDataUnknown{}},
+		{Name: "new.example.com.", RType: ALL, RClass: NONE, RData: &RDataUnknown{}},
+		{Name: "www.example.com.", RType: A, RClass: ANY, RData: &RDataUnknown{}},
+		{Name: "www.example.com.", RType: AAAA, RClass: NONE, RData: &RDataUnknown{}},
+		{Name: "www.example.com.", RType: A, RClass: IN, TTL: 0, RDLength: 4, RData: addressRecord.RData},
+	}
+	wantUpdates := []ResourceRecord{
+		{Name: "old.example.com.", RType: ALL, RClass: ANY, RData: &RDataUnknown{}},
+		{Name: "www.example.com.", RType: TXT, RClass: ANY, RData: &RDataUnknown{}},
+		{Name: "www.example.com.", RType: A, RClass: NONE, TTL: 0, RDLength: 4, RData: addressRecord.RData},
+		{Name: "www.example.com.", RType: A, RClass: IN, TTL: 300, RDLength: 4, RData: addressRecord.RData},
+	}
+
+	got, err := update.Message()
+	if err != nil {
+		t.Fatalf("Message() unexpected error = %v\n", err)
+	}
+
+	wantHeader := Header{
+		Id:                got.Header.Id,
+		Flags:             Flags{Opcode: UPDATE},
+		QuestionCount:     1,
+		AnswerRRCount:     uint16(len(wantPrerequisites)),
+		NameserverRRCount: uint16(len(wantUpdates)),
+		AdditionalRRCount: 0,
+	}
+	if !reflect.DeepEqual(got.Header, wantHeader) {
+		t.Errorf("Message() header got = %+v, want = %+v\n", got.Header, wantHeader)
+	}
+	wantZone := []Question{{Name: "example.com.", QType: SOA, QClass: IN}}
+	if !reflect.DeepEqual(got.Questions, wantZone) {
+		t.Errorf("Message() zone got = %+v, want = %+v\n", got.Questions, wantZone)
+	}
+	if !reflect.DeepEqual(got.Answers, wantPrerequisites) {
+		t.Errorf("Message() prerequisites got = %+v, want = %+v\n", got.Answers, wantPrerequisites)
+	}
+	if !reflect.DeepEqual(got.NameServers, wantUpdates) {
+		t.Errorf("Message() updates got = %+v, want = %+v\n", got.NameServers, wantUpdates)
+	}
+
+	// Records without RData must survive a round trip
+	data, err := EncodeMessage(got)
+	if err != nil {
+		t.Fatalf("EncodeMessage() unexpected error = %v\n", err)
+	}
+	decoded, err := DecodeMessage(data)
+	if err != nil {
+		t.Fatalf("DecodeMessage() unexpected error = %v\n", err)
+	}
+	if !reflect.DeepEqual(decoded.Answers, got.Answers) || !reflect.DeepEqual(decoded.NameServers, got.NameServers) {
+		t.Errorf("DecodeMessage() got = %+v, want = %+v\n", decoded, got)
+	}
+}
+
+func TestUpdateMessageInvalidRecord(t *testing.T) {
+	update := NewUpdate("example.com.")
+	update.Add(ResourceRecord{
+		Name:  "www.example.com.",
+		RType: A,
+		TTL:   300,
+		RData: &RDataA{IP: netip.MustParseAddr("2001:db8::1")},
+	})
+
+	_, err := update.Message()
+
+	if err == nil {
+		t.Errorf("Message() error = nil, want error\n")
+	}
+}
@@ -1,8 +1,10 @@
 package dns
 
 type dnsWriter struct {
-	data   []byte
-	offset int
+	data         []byte
+	offset       int
+	compression  map[string]int // Offsets of the names written so far, nil to write names in full
+	keepRDLength bool           // Write the records' RDLength as is, instead of the length of their encoded RData
 }
 
 func (writer *dnsWriter) writeUint16(value uint16) {
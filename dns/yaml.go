@@ -0,0 +1,191 @@
+package dns
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// YAML representation of DNS messages: the members of the JSON representation [RFC8427],
+// in the same order, written as YAML block mappings and sequences, ex.:
+//
+//	ID: 1234
+//	QR: true
+//	...
+//	answerRRs:
+//	  - NAME: example.com.
+//	    TYPE: 1
+//	    ...
+//	    rdataA: 192.0.2.1
+
+// MarshalMessageYAML converts a message into YAML, with the members of its JSON representation.
+//
+// Parameters:
+//   - message: The message to convert.
+//   - options: The options of the JSON representation.
+//
+// Returns:
+//   - []byte: The YAML document of the message.
+//   - error: If the RData of a record cannot be encoded.
+func MarshalMessageYAML(message Message, options JSONOptions) ([]byte, error) {
+	data, err := MarshalMessageJSON(message, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the JSON tokens rather than unmarshal into maps, to keep the order of the members
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	node, err := readYAMLNode(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	var builder strings.Builder
+	writeYAMLNode(&builder, node, "")
+	return []byte(builder.String()), nil
+}
+
+// yamlMapping holds the members of a JSON object in order.
+type yamlMapping struct {
+	keys   []string
+	values []any
+}
+
+// readYAMLNode reads the next JSON value as a *yamlMapping, a []any sequence or a formatted scalar string.
+func readYAMLNode(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token := token.(type) {
+	case json.Delim:
+		var node any
+		if token == '{' {
+			mapping := &yamlMapping{}
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := readYAMLNode(decoder)
+				if err != nil {
+					return nil, err
+				}
+				mapping.keys = append(mapping.keys, getYAMLString(fmt.Sprint(key)))
+				mapping.values = append(mapping.values, value)
+			}
+			node = mapping
+		} else {
+			sequence := []any{}
+			for decoder.More() {
+				value, err := readYAMLNode(decoder)
+				if err != nil {
+					return nil, err
+				}
+				sequence = append(sequence, value)
+			}
+			node = sequence
+		}
+		// Closing delimiter
+		if _, err = decoder.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return getYAMLString(token), nil
+	case json.Number:
+		return token.String(), nil
+	case bool:
+		return strconv.FormatBool(token), nil
+	}
+	return "null", nil
+}
+
+// writeYAMLNode writes a mapping or sequence as a block with the given indentation.
+// Scalars and empty mappings or sequences are written on the current line.
+func writeYAMLNode(builder *strings.Builder, node any, indent string) {
+	switch node := node.(type) {
+	case *yamlMapping:
+		if len(node.keys) == 0 {
+			builder.WriteString("{}\n")
+			return
+		}
+		for i, key := range node.keys {
+			builder.WriteString(indent + key + ":")
+			writeYAMLValue(builder, node.values[i], indent)
+		}
+	case []any:
+		if len(node) == 0 {
+			builder.WriteString("[]\n")
+			return
+		}
+		for _, item := range node {
+			// Write the first line of a mapping after the "- " of its item
+			var itemBuilder strings.Builder
+			writeYAMLNode(&itemBuilder, item, indent+"  ")
+			item := strings.TrimPrefix(itemBuilder.String(), indent+"  ")
+			builder.WriteString(indent + "- " + item)
+		}
+	default:
+		builder.WriteString(fmt.Sprint(node) + "\n")
+	}
+}
+
+// writeYAMLValue writes the value of a mapping key: on the same line if it is a scalar
+// or empty, or as an indented block on the following lines.
+func writeYAMLValue(builder *strings.Builder, value any, indent string) {
+	switch value := value.(type) {
+	case *yamlMapping:
+		if len(value.keys) > 0 {
+			builder.WriteString("\n")
+			writeYAMLNode(builder, value, indent+"  ")
+			return
+		}
+	case []any:
+		if len(value) > 0 {
+			builder.WriteString("\n")
+			writeYAMLNode(builder, value, indent+"  ")
+			return
+		}
+	}
+	builder.WriteString(" ")
+	writeYAMLNode(builder, value, indent)
+}
+
+// getYAMLString returns a string as a plain YAML scalar if it cannot be read as anything else,
+// ex. "example.com.", or double-quoted otherwise, ex. "" or "true".
+func getYAMLString(s string) string {
+	if isYAMLPlainString(s) {
+		return s
+	}
+	quoted, _ := json.Marshal(s) // JSON strings are valid YAML double-quoted scalars
+	return string(quoted)
+}
+
+func isYAMLPlainString(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s || !isYAMLPlainFirstCharacter(s[0]) {
+		return false
+	}
+	for _, c := range []byte(s) {
+		if !isYAMLPlainFirstCharacter(c) && !strings.ContainsRune(" .-_/+=", rune(c)) {
+			return false
+		}
+	}
+
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null":
+		return false
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return false
+	}
+	return true
+}
+
+func isYAMLPlainFirstCharacter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || isDigit(c)
+}
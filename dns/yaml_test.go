@@ -0,0 +1,89 @@
+package dns
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestMarshalMessageYAML(t *testing.T) {
+	message := Message{
+		Header:    Header{Id: 1234, Flags: Flags{Response: true, RecursionDesired: true}},
+		Questions: []Question{{Name: "example.com.", QType: A, QClass: IN}},
+		Answers: []ResourceRecord{
+			{Name: "example.com.", RType: A, RClass: IN, TTL: 300, RData: &RDataA{IP: netip.MustParseAddr("192.0.2.1")}},
+			{Name: "example.com.", RType: TXT, RClass: IN, TTL: 300, RData: &RDataTXT{Text: []string{"v=spf1 -all"}}},
+		},
+	}
+
+	want := `ID: 1234
+QR: true
+Opcode: 0
+AA: false
+TC: false
+RD: true
+RA: false
+AD: false
+CD: false
+RCODE: 0
+QDCOUNT: 1
+ANCOUNT: 2
+NSCOUNT: 0
+ARCOUNT: 0
+QNAME: example.com.
+QTYPE: 1
+QTYPEname: A
+QCLASS: 1
+QCLASSname: IN
+answerRRs:
+  - NAME: example.com.
+    TYPE: 1
+    TYPEname: A
+    CLASS: 1
+    CLASSname: IN
+    TTL: 300
+    RDLENGTH: 4
+    rdataA: 192.0.2.1
+  - NAME: example.com.
+    TYPE: 16
+    TYPEname: TXT
+    CLASS: 1
+    CLASSname: IN
+    TTL: 300
+    RDLENGTH: 12
+    rdataTXT: "\"v=spf1 -all\""
+`
+
+	got, err := MarshalMessageYAML(message, JSONOptions{})
+	if err != nil {
+		t.Fatalf("MarshalMessageYAML() unexpected error = %v\n", err)
+	}
+	if string(got) != want {
+		t.Errorf("MarshalMessageYAML() got = %s, want = %s\n", got, want)
+	}
+}
+
+func TestGetYAMLString(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "Domain name", data: "mail.example.com.", want: "mail.example.com."},
+		{name: "Presentation format", data: "10 mail.example.com.", want: "10 mail.example.com."},
+		{name: "Root", data: ".", want: `"."`},
+		{name: "Empty", data: "", want: `""`},
+		{name: "Boolean", data: "Yes", want: `"Yes"`},
+		{name: "Number", data: "1e3", want: `"1e3"`},
+		{name: "Colon", data: "2001:db8::1", want: `"2001:db8::1"`},
+		{name: "Trailing space", data: "a ", want: `"a "`},
+		{name: "Quotes", data: `"a"`, want: `"\"a\""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getYAMLString(tt.data); got != tt.want {
+				t.Errorf("getYAMLString() got = %s, want = %s\n", got, tt.want)
+			}
+		})
+	}
+}
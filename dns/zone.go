@@ -0,0 +1,136 @@
+package dns
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Zone master file format [RFC1035]:
+// one record per line, as its owner name, TTL, class, type and RData in presentation format.
+// A "$ORIGIN" line gives the name that relative names are completed with, and "@" stands for the origin.
+// A blank owner name is the owner name of the previous record. Parentheses continue a record
+// over several lines, and ";" starts a comment until the end of the line:
+//
+//	$ORIGIN example.com.
+//	@       3600 IN SOA ns1.example.com. hostmaster.example.com. (
+//	                    2024010101 ; serial
+//	                    ...
+//	www     300  IN A   192.0.2.1
+//	             IN AAAA 2001:db8::1
+
+// zoneBase64LineLength is the number of base64 characters per line of multi-line DNSKEY records.
+const zoneBase64LineLength = 56
+
+// WriteZone writes records as a zone master file, in the order given.
+// The owner names under the origin are written relative to it, the owner name of a record
+// is left blank when it is the same as the previous record's, and the columns are aligned.
+// SOA and DNSKEY records are written on several lines, with comments naming their fields.
+//
+// Parameters:
+//   - w: The writer to write the zone file to.
+//   - origin: The origin of the zone, ex. "example.com.", or "" to write absolute names without a "$ORIGIN" line.
+//   - records: The records of the zone.
+//
+// Returns:
+//   - error: If the zone file cannot be written.
+func WriteZone(w io.Writer, origin string, records []ResourceRecord) error {
+	if origin != "" {
+		origin = Fqdn(origin)
+	}
+
+	columns := make([][4]string, len(records))
+	widths := [4]int{}
+	for i, record := range records {
+		owner := getRelativeName(record.Name, origin)
+		if i > 0 && strings.EqualFold(record.Name, records[i-1].Name) {
+			owner = ""
+		}
+		columns[i] = [4]string{owner, strconv.FormatUint(uint64(record.TTL), 10), DNSClass(record.RClass).String(), DNSType(record.RType).String()}
+		for j, column := range columns[i] {
+			widths[j] = max(widths[j], len(column))
+		}
+	}
+
+	var builder strings.Builder
+	if origin != "" {
+		builder.WriteString("$ORIGIN " + origin + "\n")
+	}
+	for i, record := range records {
+		prefix := fmt.Sprintf("%-*s %-*s %-*s %-*s ", widths[0], columns[i][0], widths[1], columns[i][1], widths[2], columns[i][2], widths[3], columns[i][3])
+		builder.WriteString(prefix + getZoneRData(record, strings.Repeat(" ", len(prefix))) + "\n")
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// getZoneRData returns the RData of a record in presentation format, on several lines
+// starting with the indentation for SOA and DNSKEY records.
+func getZoneRData(record ResourceRecord, indent string) string {
+	switch rdata := record.RData.(type) {
+	case nil:
+		return (&RDataUnknown{}).String()
+
+	case *RDataSOA:
+		fields := []struct {
+			value   uint32
+			comment string
+		}{
+			{rdata.Serial, "serial"},
+			{rdata.Refresh, "refresh"},
+			{rdata.Retry, "retry"},
+			{rdata.Expire, "expire"},
+			{rdata.Minimum, "minimum"},
+		}
+		lines := []string{rdata.MName + " " + rdata.RName + " ("}
+		for i, field := range fields {
+			value := strconv.FormatUint(uint64(field.value), 10)
+			if i == len(fields)-1 {
+				value += " )"
+			}
+			lines = append(lines, fmt.Sprintf("%s%-12s ; %s", indent, value, field.comment))
+		}
+		return strings.Join(lines, "\n")
+
+	case *RDataDNSKEY:
+		lines := []string{fmt.Sprintf("%d %d %d (", rdata.Flags, rdata.Protocol, rdata.Algorithm)}
+		publicKey := base64.StdEncoding.EncodeToString(rdata.PublicKey)
+		for len(publicKey) > 0 {
+			n := min(len(publicKey), zoneBase64LineLength)
+			lines = append(lines, indent+publicKey[:n])
+			publicKey = publicKey[n:]
+		}
+
+		comment := fmt.Sprintf("alg = %s ; key id = %d", DNSSECAlgorithm(rdata.Algorithm), rdata.KeyTag())
+		switch {
+		case rdata.Flags&DNSKEYFlagSecureEntryPoint != 0:
+			comment = "KSK ; " + comment
+		case rdata.Flags&DNSKEYFlagZone != 0:
+			comment = "ZSK ; " + comment
+		}
+		lines = append(lines, indent+") ; "+comment)
+		return strings.Join(lines, "\n")
+	}
+
+	return record.RData.String()
+}
+
+// getRelativeName returns a name relative to the origin if it is under it, "@" if it is the origin,
+// or the absolute name otherwise.
+func getRelativeName(name string, origin string) string {
+	if origin == "" || origin == "." {
+		return name
+	}
+	if strings.EqualFold(name, origin) {
+		return "@"
+	}
+
+	relativeLength := len(name) - len(origin) - 1
+	if relativeLength > 0 && name[relativeLength] == '.' && name[relativeLength-1] != '\\' && strings.EqualFold(name[relativeLength+1:], origin) {
+		return name[:relativeLength]
+	}
+	return name
+}
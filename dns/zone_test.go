@@ -0,0 +1,91 @@
+package dns
+
+import (
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteZone(t *testing.T) {
+	soa := &RDataSOA{
+		MName:   "ns1.example.com.",
+		RName:   "hostmaster.example.com.",
+		Serial:  2024010101,
+		Refresh: 7200,
+		Retry:   3600,
+		Expire:  1209600,
+		Minimum: 300,
+	}
+	dnskey := &RDataDNSKEY{Flags: 257, Protocol: 3, Algorithm: 13, PublicKey: []byte(strings.Repeat("k", 48))}
+
+	records := []ResourceRecord{
+		{Name: "example.com.", RType: SOA, RClass: IN, TTL: 3600, RData: soa},
+		{Name: "Example.com.", RType: DNSKEY, RClass: IN, TTL: 3600, RData: dnskey},
+		{Name: "www.example.com.", RType: A, RClass: IN, TTL: 300, RData: &RDataA{IP: netip.MustParseAddr("192.0.2.1")}},
+		{Name: "www.example.com.", RType: AAAA, RClass: IN, TTL: 300, RData: &RDataAAAA{IP: netip.MustParseAddr("2001:db8::1")}},
+		{Name: "www.example.com.", RType: HTTPS, RClass: IN, TTL: 300, RData: &RDataSVCB{Priority: 1, Target: ".", Params: []SVCBParam{&SVCBParamALPN{Protocols: []string{"h2"}}}}},
+		{Name: "mail.example.net.", RType: 731, RClass: IN, TTL: 60, RData: &RDataUnknown{Data: []byte{0xde, 0xad}}},
+	}
+
+	want := `$ORIGIN example.com.
+@                 3600 IN SOA     ns1.example.com. hostmaster.example.com. (
+                                  2024010101   ; serial
+                                  7200         ; refresh
+                                  3600         ; retry
+                                  1209600      ; expire
+                                  300 )        ; minimum
+                  3600 IN DNSKEY  257 3 13 (
+                                  a2tra2tra2tra2tra2tra2tra2tra2tra2tra2tra2tra2tra2tra2tr
+                                  a2tra2tr
+                                  ) ; KSK ; alg = ECDSAP256SHA256 ; key id = ` + strconv.Itoa(int(dnskey.KeyTag())) + `
+www               300  IN A       192.0.2.1
+                  300  IN AAAA    2001:db8::1
+                  300  IN HTTPS   1 . alpn=h2
+mail.example.net. 60   IN TYPE731 \# 2 DEAD
+`
+
+	var builder strings.Builder
+	if err := WriteZone(&builder, "example.com", records); err != nil {
+		t.Fatalf("WriteZone() unexpected error = %v\n", err)
+	}
+	if got := builder.String(); got != want {
+		t.Errorf("WriteZone() got = \n%s\nwant = \n%s\n", got, want)
+	}
+
+	// The multi-line and SVCB RData is read back by the presentation format parser
+	for _, record := range append(records[:2:2], records[4]) {
+		rdata, err := ParseRData(record.RType, getZoneRData(record, "\t"))
+		if err != nil {
+			t.Fatalf("ParseRData() unexpected error = %v\n", err)
+		}
+		if !reflect.DeepEqual(rdata, record.RData) {
+			t.Errorf("ParseRData() got = %+v, want = %+v\n", rdata, record.RData)
+		}
+	}
+}
+
+func TestGetRelativeName(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		origin string
+		want   string
+	}{
+		{name: "Origin", data: "example.com.", origin: "example.com.", want: "@"},
+		{name: "Under origin", data: "a.b.Example.COM.", origin: "example.com.", want: "a.b"},
+		{name: "Not under origin", data: "example.net.", origin: "example.com.", want: "example.net."},
+		{name: "Same suffix without label boundary", data: "myexample.com.", origin: "example.com.", want: "myexample.com."},
+		{name: "Escaped dot", data: `a\.example.com.`, origin: "example.com.", want: `a\.example.com.`},
+		{name: "No origin", data: "example.com.", origin: "", want: "example.com."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRelativeName(tt.data, tt.origin); got != tt.want {
+				t.Errorf("getRelativeName() got = %s, want = %s\n", got, tt.want)
+			}
+		})
+	}
+}
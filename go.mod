@@ -1,3 +1,16 @@
 module github.com/mcombeau/dns-tools
 
 go 1.22.2
+
+require (
+	github.com/miekg/dns v1.1.65
+	golang.org/x/net v0.35.0
+)
+
+require (
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+	golang.org/x/tools v0.30.0 // indirect
+)
@@ -0,0 +1,38 @@
+package recursive
+
+import (
+	"strings"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// QNAME minimization [RFC9156]: the servers of a zone are asked for the name with one more label
+// than the zone, ex. "example.com." rather than "www.example.com." for the "com." servers, with the
+// type A rather than the type resolved, which could reveal the purpose of the query and which
+// some servers answer badly for names which are not leaves. A referral leads to the subzone,
+// and an answer or NODATA says the name is in the zone: one more label is then added.
+//
+// Names with many labels are only minimized for maxMinimizedQueries queries, and the full name is
+// sent when the servers of a zone do not answer a minimized query with NOERROR: some answer NXDOMAIN
+// for empty non-terminals [RFC8020].
+
+const (
+	maxMinimizedQueries = 10    // MAX_MINIMISE_COUNT [RFC9156]
+	minimizedQueryType  = dns.A // Type of the minimized queries [RFC9156]
+)
+
+// getMinimizedName returns the name with one more label than the zone, plus the extra labels
+// added after the previous minimized queries in the zone, or the name itself if it has no more labels.
+//
+// Parameters:
+//   - name: The name resolved, ex. "a.b.www.example.com.".
+//   - zone: The zone its servers are asked, ex. "com.".
+//   - extra: The number of labels beyond the first one to add, ex. 1 for "www.example.com.".
+func getMinimizedName(name string, zone string, extra int) string {
+	labels := dns.SplitLabels(name)
+	count := dns.CountLabels(zone) + 1 + extra
+	if count >= len(labels) {
+		return name
+	}
+	return strings.Join(labels[len(labels)-count:], ".") + "."
+}
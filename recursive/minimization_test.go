@@ -0,0 +1,94 @@
+package recursive
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestGetMinimizedName(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		zone  string
+		extra int
+		want  string
+	}{
+		{name: "Root", data: "www.example.com.", zone: ".", extra: 0, want: "com."},
+		{name: "Top-level domain", data: "www.example.com.", zone: "com.", extra: 0, want: "example.com."},
+		{name: "Extra label", data: "a.b.www.example.com.", zone: "example.com.", extra: 1, want: "b.www.example.com."},
+		{name: "Full name", data: "www.Example.com.", zone: "example.com.", extra: 0, want: "www.Example.com."},
+		{name: "Past the full name", data: "www.example.com.", zone: "example.com.", extra: 2, want: "www.example.com."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getMinimizedName(tt.data, tt.zone, tt.extra); got != tt.want {
+				t.Errorf("getMinimizedName() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveQNAMEMinimization(t *testing.T) {
+	records := []dns.ResourceRecord{
+		newTestRecord("a.b.www.example.com.", dns.TXT, &dns.RDataTXT{Text: []string{"minimized"}}),
+	}
+
+	tests := []struct {
+		name             string
+		nxdomainForEmpty bool
+		want             []string
+	}{
+		{
+			name: "Minimized queries",
+			want: []string{"www.example.com. A", "b.www.example.com. A", "a.b.www.example.com. TXT"},
+		},
+		{
+			name:             "Fallback to the full name",
+			nxdomainForEmpty: true,
+			want:             []string{"www.example.com. A", "a.b.www.example.com. TXT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servers := startTestHierarchy(t, nil, records...)
+			servers["192.0.2.3"].mutex.Lock()
+			servers["192.0.2.3"].nxdomainForEmpty = tt.nxdomainForEmpty
+			servers["192.0.2.3"].mutex.Unlock()
+			resolver := newTestResolver(servers, "192.0.2.1")
+			resolver.QNAMEMinimization = true
+
+			response, err := resolver.Resolve("a.b.www.example.com.", dns.TXT)
+			if err != nil || len(response.Answers) != 1 {
+				t.Fatalf("Resolve() got = %v, error = %v\n", response.Answers, err)
+			}
+			if got := servers["192.0.2.1"].getQuestions(); !reflect.DeepEqual(got, []string{"com. A"}) {
+				t.Errorf("Resolve() root server got = %v, want = %v\n", got, []string{"com. A"})
+			}
+			if got := servers["192.0.2.2"].getQuestions(); !reflect.DeepEqual(got, []string{"example.com. A"}) {
+				t.Errorf("Resolve() com. server got = %v, want = %v\n", got, []string{"example.com. A"})
+			}
+			if got := servers["192.0.2.3"].getQuestions(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resolve() example.com. server got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveWithoutQNAMEMinimization(t *testing.T) {
+	servers := startTestHierarchy(t, nil,
+		newTestRecord("www.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("198.51.100.1")}),
+	)
+	resolver := newTestResolver(servers, "192.0.2.1")
+
+	if _, err := resolver.Resolve("www.example.com.", dns.A); err != nil {
+		t.Fatalf("Resolve() unexpected error = %v\n", err)
+	}
+	if got := servers["192.0.2.1"].getQuestions(); !reflect.DeepEqual(got, []string{"www.example.com. A"}) {
+		t.Errorf("Resolve() root server got = %v, want the full name\n", got)
+	}
+}
@@ -0,0 +1,329 @@
+// Package recursive resolves names iteratively, the way recursive resolvers do: starting from the
+// root servers, it follows the referrals of each zone to the name servers of its subzones until it
+// reaches the servers which are authoritative for the name, then follows the CNAME records of the answer.
+package recursive
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/mcombeau/dns-tools/client"
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+var (
+	ErrNoServers      = fmt.Errorf("no name server answered")
+	ErrTooManyQueries = fmt.Errorf("too many referrals or CNAME records")
+	ErrLameDelegation = fmt.Errorf("lame delegation")
+)
+
+const (
+	DefaultMaxReferrals = 16 // Maximum number of referrals followed to resolve a name
+	DefaultMaxDepth     = 4  // Maximum depth of the resolutions of the addresses of name servers without glue
+	defaultMaxCNAMEs    = 8  // Maximum number of CNAME records followed
+)
+
+// DefaultRootServers are the IPv4 addresses of the root servers, a.root-servers.net to m.root-servers.net,
+// from DefaultRootHints.
+var DefaultRootServers = []netip.Addr{
+	netip.MustParseAddr("198.41.0.4"),
+	netip.MustParseAddr("170.247.170.2"),
+	netip.MustParseAddr("192.33.4.12"),
+	netip.MustParseAddr("199.7.91.13"),
+	netip.MustParseAddr("192.203.230.10"),
+	netip.MustParseAddr("192.5.5.241"),
+	netip.MustParseAddr("192.112.36.4"),
+	netip.MustParseAddr("198.97.190.53"),
+	netip.MustParseAddr("192.36.148.17"),
+	netip.MustParseAddr("192.58.128.30"),
+	netip.MustParseAddr("193.0.14.129"),
+	netip.MustParseAddr("199.7.83.42"),
+	netip.MustParseAddr("202.12.27.33"),
+}
+
+// Resolver resolves names iteratively from the root servers.
+type Resolver struct {
+	RootServers  []netip.Addr  // Addresses of the root servers, DefaultRootServers by default
+	Port         string        // Port of the name servers, "53" by default
+	Timeout      time.Duration // Maximum time to wait for each response
+	MaxReferrals int           // Maximum number of referrals followed to resolve a name
+	MaxDepth     int           // Maximum depth of the resolutions of the addresses of name servers without glue
+
+	// QNAMEMinimization sends each zone's servers only the labels of the name they need to give
+	// a referral, rather than the full name, so that fewer servers learn the names resolved [RFC9156].
+	QNAMEMinimization bool
+
+	serverAddress func(netip.Addr) string // Address to send the queries for a name server to, replaced in tests
+}
+
+// NewResolver returns a Resolver starting from the root servers with the default limits.
+func NewResolver() *Resolver {
+	return &Resolver{
+		RootServers:  DefaultRootServers,
+		Port:         "53",
+		Timeout:      client.DefaultTimeout,
+		MaxReferrals: DefaultMaxReferrals,
+		MaxDepth:     DefaultMaxDepth,
+	}
+}
+
+// Resolve resolves a name iteratively, following the CNAME records of the answers.
+//
+// Parameters:
+//   - name: The name to resolve, ex. "www.example.com.".
+//   - qtype: The DNS record type to resolve.
+//
+// Returns:
+//   - dns.Message: The response to the question, assembled from the answers of the authoritative
+//     servers of the name and of its CNAME targets: the CNAME records followed then the records of
+//     the type, or the authority section of the last answer if there are none, ex. NXDOMAIN with its SOA record.
+//   - error: If no server answered, the servers of a zone gave no usable response, or the
+//     limits on referrals or CNAME records were reached.
+func (resolver *Resolver) Resolve(name string, qtype uint16) (dns.Message, error) {
+	return resolver.resolve(dns.Fqdn(name), qtype, 0, nil)
+}
+
+// resolve resolves a name, see Resolve, adding the queries sent to the trace if it is not nil.
+// The depth is the number of resolutions of addresses of name servers the resolution is part of.
+func (resolver *Resolver) resolve(name string, qtype uint16, depth int, trace *Trace) (dns.Message, error) {
+	result := *dns.NewResponse(dns.NewQuery(name, qtype)).WithRecursionAvailable()
+
+	current := name
+	for cnames := 0; ; cnames++ {
+		response, err := resolver.iterate(current, qtype, depth, trace)
+		if err != nil {
+			return dns.Message{}, err
+		}
+		result.Header.Flags.ResponseCode = response.Header.Flags.ResponseCode
+
+		target, answers := getAnswers(response, current, qtype)
+		result.Answers = append(result.Answers, answers...)
+		if target == "" {
+			if len(answers) == 0 || answers[len(answers)-1].RType != qtype {
+				result.NameServers = response.NameServers
+			}
+			break
+		}
+
+		if cnames == defaultMaxCNAMEs {
+			return dns.Message{}, fmt.Errorf("%w: resolve %s", ErrTooManyQueries, name)
+		}
+		current = target
+	}
+
+	result.UpdateHeaderCounts()
+	return result, nil
+}
+
+// iterate follows the referrals from the root servers to the servers of the name,
+// and returns their response: an answer, NXDOMAIN or no records of the type.
+// With QNAME minimization, the servers of each zone are asked for one more label of the name
+// than the zone has, see getMinimizedName, until the name is reached.
+func (resolver *Resolver) iterate(name string, qtype uint16, depth int, trace *Trace) (dns.Message, error) {
+	zone := "."
+	servers := resolver.RootServers
+	minimize := resolver.QNAMEMinimization
+	minimized := 0 // Number of minimized queries sent
+	extra := 0     // Number of labels added to the minimized name in the zone
+
+	for referrals := 0; referrals <= resolver.MaxReferrals; {
+		queryName, queryType := name, qtype
+		if minimize {
+			queryName = getMinimizedName(name, zone, extra)
+			if queryName != name {
+				queryType = minimizedQueryType
+			}
+		}
+
+		response, err := resolver.queryServers(servers, zone, queryName, queryType, depth, trace)
+		if queryName != name {
+			minimized++
+			if err != nil || response.Header.Flags.ResponseCode != dns.NOERROR || minimized == maxMinimizedQueries {
+				// Servers which do not answer minimized queries properly are asked for the full name
+				minimize = false
+				continue
+			}
+		} else if err != nil {
+			return dns.Message{}, fmt.Errorf("query %s servers for %s: %w", zone, name, err)
+		} else if response.Header.Flags.ResponseCode != dns.NOERROR || len(response.Answers) > 0 {
+			return response, nil
+		}
+
+		child, nameServers := getReferral(response, zone, queryName)
+		if child == "" {
+			if queryName != name {
+				// The minimized name is in the zone: ask for one more label
+				extra++
+				continue
+			}
+			// No records of the type
+			return response, nil
+		}
+
+		servers = getGlue(response, zone, nameServers)
+		if len(servers) == 0 {
+			servers = resolver.resolveNameServers(nameServers, depth, trace)
+		}
+		if len(servers) == 0 {
+			return dns.Message{}, fmt.Errorf("%w: no address for the name servers of %s", ErrLameDelegation, child)
+		}
+		zone = child
+		extra = 0
+		referrals++
+	}
+	return dns.Message{}, fmt.Errorf("%w: resolve %s", ErrTooManyQueries, name)
+}
+
+// queryServers sends a non-recursive query to the servers of a zone in turn, until one answers with
+// NOERROR or NXDOMAIN, adding each query to the trace if it is not nil.
+func (resolver *Resolver) queryServers(servers []netip.Addr, zone string, name string, qtype uint16, depth int, trace *Trace) (dns.Message, error) {
+	err := ErrNoServers
+	for _, server := range servers {
+		var response client.Response
+		dnsClient := client.NewClient(resolver.getServerAddress(server))
+		dnsClient.Timeout = resolver.Timeout
+
+		start := time.Now()
+		response, err = dnsClient.Exchange(*dns.NewQuery(name, qtype))
+		if err == nil {
+			switch responseCode := response.Message.ResponseCode(); responseCode {
+			case dns.NOERROR, dns.NXDOMAIN:
+			default:
+				err = fmt.Errorf("%s answered %s", server, dns.DNSRCode(responseCode))
+			}
+		}
+		trace.add(newTraceStep(server, zone, name, qtype, depth, response, time.Since(start), err))
+		if err == nil {
+			return response.Message, nil
+		}
+	}
+	return dns.Message{}, err
+}
+
+func (resolver *Resolver) getServerAddress(server netip.Addr) string {
+	if resolver.serverAddress != nil {
+		return resolver.serverAddress(server)
+	}
+	return net.JoinHostPort(server.String(), resolver.Port)
+}
+
+// resolveNameServers resolves the addresses of name servers given without glue records,
+// returning the addresses of the first one which has some.
+func (resolver *Resolver) resolveNameServers(nameServers []string, depth int, trace *Trace) []netip.Addr {
+	if depth >= resolver.MaxDepth {
+		return nil
+	}
+	for _, nameServer := range nameServers {
+		response, err := resolver.resolve(nameServer, dns.A, depth+1, trace)
+		if err != nil {
+			continue
+		}
+		var addresses []netip.Addr
+		for _, record := range response.Answers {
+			if rdata, ok := record.RData.(*dns.RDataA); ok && record.RType == dns.A {
+				addresses = append(addresses, rdata.IP)
+			}
+		}
+		if len(addresses) > 0 {
+			return addresses
+		}
+	}
+	return nil
+}
+
+// getAnswers returns the answers of a response for a name: its records of the type, or its
+// CNAME record along with the name it points to, which is then resolved in turn.
+func getAnswers(response dns.Message, name string, qtype uint16) (target string, answers []dns.ResourceRecord) {
+	for _, record := range response.Answers {
+		if record.RType == qtype && dns.EqualNames(record.Name, name) {
+			answers = append(answers, record)
+		}
+	}
+	if len(answers) > 0 || qtype == dns.CNAME {
+		return "", answers
+	}
+
+	// Servers may chase the CNAME records of their own zones: follow them from the name
+	for {
+		cname := getCNAME(response.Answers, name)
+		if cname == nil {
+			return target, answers
+		}
+		answers = append(answers, *cname)
+		name = cname.RData.(*dns.RDataCNAME).DomainName
+		target = name
+
+		found := false
+		for _, record := range response.Answers {
+			if record.RType == qtype && dns.EqualNames(record.Name, name) {
+				answers = append(answers, record)
+				found = true
+			}
+		}
+		if found {
+			return "", answers
+		}
+		if len(answers) > len(response.Answers) {
+			// A loop of CNAME records
+			return target, answers
+		}
+	}
+}
+
+// getCNAME returns the CNAME record of a name among records, nil if there is none.
+func getCNAME(records []dns.ResourceRecord, name string) *dns.ResourceRecord {
+	for _, record := range records {
+		if _, ok := record.RData.(*dns.RDataCNAME); ok && record.RType == dns.CNAME && dns.EqualNames(record.Name, name) {
+			return &record
+		}
+	}
+	return nil
+}
+
+// getReferral returns the subzone a response delegates the name to, and its name servers, from the
+// NS records of its authority section. Only a zone under the zone queried and above the name
+// is a referral: other NS records, ex. the zone's own NS records along a NODATA answer, are not.
+func getReferral(response dns.Message, zone string, name string) (child string, nameServers []string) {
+	for _, record := range response.NameServers {
+		rdata, ok := record.RData.(*dns.RDataNS)
+		if !ok || record.RType != dns.NS {
+			continue
+		}
+		if dns.EqualNames(record.Name, zone) || !dns.IsSubdomain(record.Name, zone) || !dns.IsSubdomain(name, record.Name) {
+			continue
+		}
+		if child == "" {
+			child = dns.CanonicalName(record.Name)
+		}
+		if dns.EqualNames(record.Name, child) {
+			nameServers = append(nameServers, rdata.DomainName)
+		}
+	}
+	return child, nameServers
+}
+
+// getGlue returns the addresses of the name servers of a subzone in the additional section of
+// a referral, IPv4 addresses first. Only the addresses of name servers under the zone queried
+// are trusted: its servers are not authoritative for the others.
+func getGlue(response dns.Message, zone string, nameServers []string) []netip.Addr {
+	var ipv4, ipv6 []netip.Addr
+	for _, nameServer := range nameServers {
+		if !dns.IsSubdomain(nameServer, zone) {
+			continue
+		}
+		for _, record := range response.Additionals {
+			if !dns.EqualNames(record.Name, nameServer) {
+				continue
+			}
+			switch rdata := record.RData.(type) {
+			case *dns.RDataA:
+				ipv4 = append(ipv4, rdata.IP)
+			case *dns.RDataAAAA:
+				ipv6 = append(ipv6, rdata.IP)
+			}
+		}
+	}
+	return append(ipv4, ipv6...)
+}
@@ -0,0 +1,308 @@
+package recursive
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// testServer is an authoritative server for a zone, answering UDP queries on a local port:
+// with a referral for the names under the subzones it has NS records for, with the records
+// of the name and type and the addresses of the name servers among them, with the CNAME record
+// of the name, or with NXDOMAIN or NODATA and the SOA record of the zone.
+type testServer struct {
+	zone    string
+	records []dns.ResourceRecord
+	address string
+
+	nxdomainForEmpty bool // Answer NXDOMAIN for empty non-terminals, like some broken servers
+
+	mutex     sync.Mutex     // Held while answering, to change the server between tests
+	questions []dns.Question // Questions received, in order
+}
+
+func newTestRecord(name string, rtype uint16, rdata dns.RData) dns.ResourceRecord {
+	return dns.ResourceRecord{Name: name, RType: rtype, RClass: dns.IN, TTL: 300, RData: rdata}
+}
+
+func startTestServer(t *testing.T, zone string, records ...dns.ResourceRecord) *testServer {
+	t.Helper()
+
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen UDP: %v", err)
+	}
+	t.Cleanup(func() { packetConn.Close() })
+	server := &testServer{zone: zone, records: records, address: packetConn.LocalAddr().String()}
+
+	go func() {
+		buffer := make([]byte, dns.MaxDNSMessageSize)
+		for {
+			n, addr, err := packetConn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			query, err := dns.DecodeMessage(buffer[:n])
+			if err != nil || len(query.Questions) != 1 {
+				t.Errorf("test server: invalid query: %v", err)
+				continue
+			}
+			if query.Header.Flags.RecursionDesired {
+				t.Errorf("test server: recursive query for %s", query.Questions[0].Name)
+			}
+
+			server.mutex.Lock()
+			server.questions = append(server.questions, query.Questions[0])
+			response := server.answer(&query)
+			server.mutex.Unlock()
+
+			data, err := response.Pack()
+			if err != nil {
+				t.Errorf("test server: encode response: %v", err)
+				continue
+			}
+			packetConn.WriteTo(data, addr)
+		}
+	}()
+	return server
+}
+
+func (server *testServer) answer(query *dns.Message) *dns.Message {
+	question := query.Questions[0]
+	response := dns.NewResponse(query)
+
+	// Referral to the closest subzone of the name
+	child := ""
+	for _, record := range server.records {
+		if record.RType == dns.NS && !dns.EqualNames(record.Name, server.zone) && dns.IsSubdomain(question.Name, record.Name) {
+			if child == "" || dns.CountLabels(record.Name) > dns.CountLabels(child) {
+				child = record.Name
+			}
+		}
+	}
+	if child != "" {
+		for _, record := range server.records {
+			if record.RType == dns.NS && dns.EqualNames(record.Name, child) {
+				response.Authority(record)
+				for _, glue := range server.records {
+					if (glue.RType == dns.A || glue.RType == dns.AAAA) && dns.EqualNames(glue.Name, record.RData.(*dns.RDataNS).DomainName) {
+						response.Additional(glue)
+					}
+				}
+			}
+		}
+		return response
+	}
+
+	response.WithAuthoritative()
+	exists := false
+	for _, record := range server.records {
+		if dns.EqualNames(record.Name, question.Name) || (dns.IsSubdomain(record.Name, question.Name) && !server.nxdomainForEmpty) {
+			exists = true
+		}
+		if !dns.EqualNames(record.Name, question.Name) {
+			continue
+		}
+		if record.RType == question.QType || (record.RType == dns.CNAME && question.QType != dns.CNAME) {
+			response.Answer(record)
+		}
+	}
+	if len(response.Answers) > 0 {
+		for _, record := range response.Answers {
+			if rdata, ok := record.RData.(*dns.RDataNS); ok {
+				for _, glue := range server.records {
+					if (glue.RType == dns.A || glue.RType == dns.AAAA) && dns.EqualNames(glue.Name, rdata.DomainName) {
+						response.Additional(glue)
+					}
+				}
+			}
+		}
+		return response
+	}
+
+	if !exists {
+		response.WithResponseCode(dns.NXDOMAIN)
+	}
+	return response.Authority(newTestRecord(server.zone, dns.SOA, &dns.RDataSOA{
+		MName: "ns." + strings.TrimPrefix(server.zone, "."), RName: "hostmaster." + strings.TrimPrefix(server.zone, "."),
+		Serial: 1, Refresh: 3600, Retry: 600, Expire: 86400, Minimum: 300,
+	}))
+}
+
+func (server *testServer) getQuestions() []string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	questions := []string{}
+	for _, question := range server.questions {
+		questions = append(questions, question.Name+" "+dns.DNSType(question.QType).String())
+	}
+	return questions
+}
+
+// newTestResolver returns a resolver sending the queries for the name servers to the test
+// servers with their addresses, starting from the root server with the first address.
+func newTestResolver(servers map[string]*testServer, root string) *Resolver {
+	resolver := NewResolver()
+	resolver.RootServers = []netip.Addr{netip.MustParseAddr(root)}
+	resolver.Timeout = time.Second
+	resolver.serverAddress = func(address netip.Addr) string {
+		if server, ok := servers[address.String()]; ok {
+			return server.address
+		}
+		// Nothing listens there
+		return "127.0.0.1:1"
+	}
+	return resolver
+}
+
+// startTestHierarchy starts the servers of a root zone, the "com." zone and the "example.com." zone,
+// whose name servers are on the addresses 192.0.2.1, 192.0.2.2 and 192.0.2.3, with more records in
+// the root zone and the "example.com." zone.
+func startTestHierarchy(t *testing.T, rootRecords []dns.ResourceRecord, records ...dns.ResourceRecord) map[string]*testServer {
+	exampleRecords := append([]dns.ResourceRecord{
+		newTestRecord("example.com.", dns.NS, &dns.RDataNS{DomainName: "ns.example.com."}),
+		newTestRecord("ns.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.3")}),
+	}, records...)
+
+	return map[string]*testServer{
+		"192.0.2.1": startTestServer(t, ".", append([]dns.ResourceRecord{
+			newTestRecord("com.", dns.NS, &dns.RDataNS{DomainName: "a.gtld-servers.net."}),
+			newTestRecord("a.gtld-servers.net.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.2")}),
+		}, rootRecords...)...),
+		"192.0.2.2": startTestServer(t, "com.",
+			newTestRecord("example.com.", dns.NS, &dns.RDataNS{DomainName: "ns.example.com."}),
+			newTestRecord("ns.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.3")}),
+		),
+		"192.0.2.3": startTestServer(t, "example.com.", exampleRecords...),
+	}
+}
+
+func TestResolve(t *testing.T) {
+	servers := startTestHierarchy(t, nil,
+		newTestRecord("www.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("198.51.100.1")}),
+		newTestRecord("alias.example.com.", dns.CNAME, &dns.RDataCNAME{DomainName: "www.example.com."}),
+		newTestRecord("dangling.example.com.", dns.CNAME, &dns.RDataCNAME{DomainName: "missing.example.com."}),
+	)
+	resolver := newTestResolver(servers, "192.0.2.1")
+
+	tests := []struct {
+		name             string
+		data             string
+		qtype            uint16
+		wantResponseCode uint16
+		wantAnswers      []uint16
+		wantAuthority    bool
+	}{
+		{
+			name:             "Answer",
+			data:             "www.example.com",
+			qtype:            dns.A,
+			wantResponseCode: dns.NOERROR,
+			wantAnswers:      []uint16{dns.A},
+		},
+		{
+			name:             "CNAME",
+			data:             "alias.example.com.",
+			qtype:            dns.A,
+			wantResponseCode: dns.NOERROR,
+			wantAnswers:      []uint16{dns.CNAME, dns.A},
+		},
+		{
+			name:             "CNAME to a missing name",
+			data:             "dangling.example.com.",
+			qtype:            dns.A,
+			wantResponseCode: dns.NXDOMAIN,
+			wantAnswers:      []uint16{dns.CNAME},
+			wantAuthority:    true,
+		},
+		{
+			name:             "No records of the type",
+			data:             "www.example.com.",
+			qtype:            dns.AAAA,
+			wantResponseCode: dns.NOERROR,
+			wantAuthority:    true,
+		},
+		{
+			name:             "Name not found",
+			data:             "missing.example.com.",
+			qtype:            dns.A,
+			wantResponseCode: dns.NXDOMAIN,
+			wantAuthority:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := resolver.Resolve(tt.data, tt.qtype)
+			if err != nil {
+				t.Fatalf("Resolve() unexpected error = %v\n", err)
+			}
+			if response.Header.Flags.ResponseCode != tt.wantResponseCode {
+				t.Errorf("Resolve() got response code = %v, want = %v\n", dns.DNSRCode(response.Header.Flags.ResponseCode), dns.DNSRCode(tt.wantResponseCode))
+			}
+			var answers []uint16
+			for _, record := range response.Answers {
+				answers = append(answers, record.RType)
+			}
+			if len(answers) != len(tt.wantAnswers) || (len(answers) > 0 && !equalTypes(answers, tt.wantAnswers)) {
+				t.Errorf("Resolve() got answers = %v, want = %v\n", response.Answers, tt.wantAnswers)
+			}
+			if got := len(response.NameServers) > 0; got != tt.wantAuthority {
+				t.Errorf("Resolve() got authority = %v, want authority = %t\n", response.NameServers, tt.wantAuthority)
+			}
+		})
+	}
+}
+
+func TestResolveWithoutGlue(t *testing.T) {
+	servers := startTestHierarchy(t,
+		[]dns.ResourceRecord{
+			newTestRecord("net.", dns.NS, &dns.RDataNS{DomainName: "b.gtld-servers.net."}),
+			newTestRecord("b.gtld-servers.net.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.5")}),
+		},
+		newTestRecord("sub.example.com.", dns.NS, &dns.RDataNS{DomainName: "ns.sub-servers.net."}),
+		newTestRecord("lame.example.com.", dns.NS, &dns.RDataNS{DomainName: "ns.missing.net."}),
+		// Not trusted: the example.com. servers are not authoritative for sub-servers.net.
+		newTestRecord("ns.sub-servers.net.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.6")}),
+	)
+	servers["192.0.2.4"] = startTestServer(t, "sub.example.com.",
+		newTestRecord("www.sub.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("198.51.100.2")}),
+	)
+	servers["192.0.2.5"] = startTestServer(t, "net.",
+		newTestRecord("ns.sub-servers.net.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.4")}),
+	)
+	resolver := newTestResolver(servers, "192.0.2.1")
+
+	response, err := resolver.Resolve("www.sub.example.com.", dns.A)
+	if err != nil || len(response.Answers) != 1 || response.Answers[0].RData.String() != "198.51.100.2" {
+		t.Errorf("Resolve() got = %v, error = %v\n", response.Answers, err)
+	}
+	if _, err = resolver.Resolve("www.lame.example.com.", dns.A); !errors.Is(err, ErrLameDelegation) {
+		t.Errorf("Resolve() error = %v, want error = %v\n", err, ErrLameDelegation)
+	}
+}
+
+func TestResolveNoServers(t *testing.T) {
+	resolver := newTestResolver(map[string]*testServer{}, "192.0.2.1")
+	resolver.Timeout = 100 * time.Millisecond
+
+	if _, err := resolver.Resolve("www.example.com.", dns.A); err == nil {
+		t.Errorf("Resolve() error = nil, want an error\n")
+	}
+}
+
+func equalTypes(a []uint16, b []uint16) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
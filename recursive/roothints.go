@@ -0,0 +1,223 @@
+package recursive
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// Root hints are the names and addresses of the root servers a resolver starts from, in the
+// format of the named.root file published by IANA: the NS records of the root zone and the A and
+// AAAA records of their names, with comments starting with ";".
+//
+// The hints only need one of the servers to be right: a priming query [RFC8109] asks it for the
+// NS records of the root zone, whose addresses in the additional section then replace the hints.
+// The addresses are checked before they are used, so that a broken or spoofed response cannot
+// send the resolver to loopback or private addresses.
+
+var (
+	ErrInvalidRootHints = fmt.Errorf("invalid root hints")
+	ErrInvalidPriming   = fmt.Errorf("invalid priming response")
+)
+
+// RootHintsURL is where IANA publishes the root hints file.
+const RootHintsURL = "https://www.internic.net/domain/named.root"
+
+// defaultRootHints is the named.root file the resolver ships with.
+const defaultRootHints = `; Root hints of the root zone, from named.root as published by IANA
+.                        3600000      NS    A.ROOT-SERVERS.NET.
+A.ROOT-SERVERS.NET.      3600000      A     198.41.0.4
+A.ROOT-SERVERS.NET.      3600000      AAAA  2001:503:ba3e::2:30
+.                        3600000      NS    B.ROOT-SERVERS.NET.
+B.ROOT-SERVERS.NET.      3600000      A     170.247.170.2
+B.ROOT-SERVERS.NET.      3600000      AAAA  2801:1b8:10::b
+.                        3600000      NS    C.ROOT-SERVERS.NET.
+C.ROOT-SERVERS.NET.      3600000      A     192.33.4.12
+C.ROOT-SERVERS.NET.      3600000      AAAA  2001:500:2::c
+.                        3600000      NS    D.ROOT-SERVERS.NET.
+D.ROOT-SERVERS.NET.      3600000      A     199.7.91.13
+D.ROOT-SERVERS.NET.      3600000      AAAA  2001:500:2d::d
+.                        3600000      NS    E.ROOT-SERVERS.NET.
+E.ROOT-SERVERS.NET.      3600000      A     192.203.230.10
+E.ROOT-SERVERS.NET.      3600000      AAAA  2001:500:a8::e
+.                        3600000      NS    F.ROOT-SERVERS.NET.
+F.ROOT-SERVERS.NET.      3600000      A     192.5.5.241
+F.ROOT-SERVERS.NET.      3600000      AAAA  2001:500:2f::f
+.                        3600000      NS    G.ROOT-SERVERS.NET.
+G.ROOT-SERVERS.NET.      3600000      A     192.112.36.4
+G.ROOT-SERVERS.NET.      3600000      AAAA  2001:500:12::d0d
+.                        3600000      NS    H.ROOT-SERVERS.NET.
+H.ROOT-SERVERS.NET.      3600000      A     198.97.190.53
+H.ROOT-SERVERS.NET.      3600000      AAAA  2001:500:1::53
+.                        3600000      NS    I.ROOT-SERVERS.NET.
+I.ROOT-SERVERS.NET.      3600000      A     192.36.148.17
+I.ROOT-SERVERS.NET.      3600000      AAAA  2001:7fe::53
+.                        3600000      NS    J.ROOT-SERVERS.NET.
+J.ROOT-SERVERS.NET.      3600000      A     192.58.128.30
+J.ROOT-SERVERS.NET.      3600000      AAAA  2001:503:c27::2:30
+.                        3600000      NS    K.ROOT-SERVERS.NET.
+K.ROOT-SERVERS.NET.      3600000      A     193.0.14.129
+K.ROOT-SERVERS.NET.      3600000      AAAA  2001:7fd::1
+.                        3600000      NS    L.ROOT-SERVERS.NET.
+L.ROOT-SERVERS.NET.      3600000      A     199.7.83.42
+L.ROOT-SERVERS.NET.      3600000      AAAA  2001:500:9f::42
+.                        3600000      NS    M.ROOT-SERVERS.NET.
+M.ROOT-SERVERS.NET.      3600000      A     202.12.27.33
+M.ROOT-SERVERS.NET.      3600000      AAAA  2001:dc3::35
+`
+
+// RootHints are the name servers of the root zone and their addresses.
+type RootHints struct {
+	NameServers []string                // Names of the root servers, in canonical form, ex. "a.root-servers.net."
+	Addresses   map[string][]netip.Addr // Addresses of the root servers, by name
+}
+
+// DefaultRootHints returns the root hints the resolver ships with, whose IPv4 addresses are DefaultRootServers.
+func DefaultRootHints() RootHints {
+	hints, err := ParseRootHints(strings.NewReader(defaultRootHints))
+	if err != nil {
+		panic(err)
+	}
+	return hints
+}
+
+// ReadRootHints reads a root hints file.
+//
+// Parameters:
+//   - path: The path of the file, ex. "/etc/bind/named.root".
+//
+// Returns:
+//   - RootHints: The root servers of the file.
+//   - error: If the file cannot be read or is invalid.
+func ReadRootHints(path string) (RootHints, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return RootHints{}, fmt.Errorf("cannot open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	hints, err := ParseRootHints(file)
+	if err != nil {
+		return RootHints{}, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return hints, nil
+}
+
+// ParseRootHints parses root hints in the named.root format. Records of other types
+// or owners are skipped.
+//
+// Parameters:
+//   - reader: The contents of the root hints file, ex. from RootHintsURL.
+//
+// Returns:
+//   - RootHints: The root servers of the file.
+//   - error: If a record is invalid, or no root server has an address.
+func ParseRootHints(reader io.Reader) (RootHints, error) {
+	var records []dns.ResourceRecord
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line, _, _ := strings.Cut(scanner.Text(), ";")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		record, err := dns.NewRR(line)
+		if err != nil {
+			return RootHints{}, fmt.Errorf("%w: line %d: %w", ErrInvalidRootHints, lineNumber, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return RootHints{}, err
+	}
+
+	hints := getRootHints(records, records, func(netip.Addr) bool { return true })
+	if len(hints.Servers()) == 0 {
+		return RootHints{}, fmt.Errorf("%w: no root server address", ErrInvalidRootHints)
+	}
+	return hints, nil
+}
+
+// Servers returns the addresses of the root servers, IPv4 addresses first.
+func (hints RootHints) Servers() []netip.Addr {
+	var ipv4, ipv6 []netip.Addr
+	for _, nameServer := range hints.NameServers {
+		for _, address := range hints.Addresses[nameServer] {
+			if address.Is4() {
+				ipv4 = append(ipv4, address)
+			} else {
+				ipv6 = append(ipv6, address)
+			}
+		}
+	}
+	return append(ipv4, ipv6...)
+}
+
+// Prime sends a priming query [RFC8109] for the NS records of the root zone to the root servers,
+// and replaces them with the root servers of the response, so that the resolver starts from the
+// current root servers whatever the age of its hints. It must not be called while names are resolved.
+//
+// Returns:
+//   - RootHints: The root servers of the response, with the addresses which passed validation.
+//   - error: If no root server answered, or the response was not authoritative or had no valid
+//     root server address, in which case the root servers are left unchanged.
+func (resolver *Resolver) Prime() (RootHints, error) {
+	response, err := resolver.queryServers(resolver.RootServers, ".", ".", dns.NS, 0, nil)
+	if err != nil {
+		return RootHints{}, fmt.Errorf("priming query: %w", err)
+	}
+	if response.Header.Flags.ResponseCode != dns.NOERROR || !response.Header.Flags.Authoritative {
+		return RootHints{}, fmt.Errorf("%w: %s answer, authoritative: %t", ErrInvalidPriming,
+			dns.DNSRCode(response.Header.Flags.ResponseCode), response.Header.Flags.Authoritative)
+	}
+
+	hints := getRootHints(response.Answers, response.Additionals, isValidRootServerAddress)
+	servers := hints.Servers()
+	if len(servers) == 0 {
+		return RootHints{}, fmt.Errorf("%w: no valid root server address", ErrInvalidPriming)
+	}
+	resolver.RootServers = servers
+	return hints, nil
+}
+
+// getRootHints returns the root servers of the NS records of the root zone, with
+// their valid addresses among the A and AAAA records of their names.
+func getRootHints(nameServerRecords []dns.ResourceRecord, addressRecords []dns.ResourceRecord, isValid func(netip.Addr) bool) RootHints {
+	hints := RootHints{Addresses: map[string][]netip.Addr{}}
+	for _, record := range nameServerRecords {
+		if rdata, ok := record.RData.(*dns.RDataNS); ok && record.RType == dns.NS && dns.EqualNames(record.Name, ".") {
+			hints.NameServers = append(hints.NameServers, dns.CanonicalName(rdata.DomainName))
+		}
+	}
+
+	for _, record := range addressRecords {
+		var address netip.Addr
+		switch rdata := record.RData.(type) {
+		case *dns.RDataA:
+			address = rdata.IP
+		case *dns.RDataAAAA:
+			address = rdata.IP
+		default:
+			continue
+		}
+		name := dns.CanonicalName(record.Name)
+		for _, nameServer := range hints.NameServers {
+			if nameServer == name && isValid(address) {
+				hints.Addresses[name] = append(hints.Addresses[name], address)
+				break
+			}
+		}
+	}
+	return hints
+}
+
+// isValidRootServerAddress reports whether an address from a priming response may be a root
+// server's: a public unicast address, not a loopback, link-local, multicast or private one.
+func isValidRootServerAddress(address netip.Addr) bool {
+	address = address.Unmap()
+	return address.IsGlobalUnicast() && !address.IsPrivate()
+}
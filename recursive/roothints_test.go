@@ -0,0 +1,166 @@
+package recursive
+
+import (
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestParseRootHints(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		want      []string
+		wantError error
+	}{
+		{
+			name: "Root hints",
+			data: `; comment
+.                        3600000      NS    A.ROOT-SERVERS.NET.
+A.ROOT-SERVERS.NET.      3600000      A     198.41.0.4 ; inline comment
+A.ROOT-SERVERS.NET.      3600000      AAAA  2001:503:ba3e::2:30
+
+.                        3600000      NS    B.ROOT-SERVERS.NET.
+B.ROOT-SERVERS.NET.      3600000      A     170.247.170.2
+`,
+			want: []string{"198.41.0.4", "170.247.170.2", "2001:503:ba3e::2:30"},
+		},
+		{
+			name: "Addresses of other names",
+			data: `.                        3600000      NS    A.ROOT-SERVERS.NET.
+A.ROOT-SERVERS.NET.      3600000      A     198.41.0.4
+B.ROOT-SERVERS.NET.      3600000      A     170.247.170.2
+`,
+			want: []string{"198.41.0.4"},
+		},
+		{
+			name:      "No address",
+			data:      ".  3600000  NS  A.ROOT-SERVERS.NET.\n",
+			wantError: ErrInvalidRootHints,
+		},
+		{
+			name:      "Invalid record",
+			data:      ".  3600000  NS  A.ROOT-SERVERS.NET.\nA.ROOT-SERVERS.NET.  3600000  A  198.41.0\n",
+			wantError: ErrInvalidRootHints,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hints, err := ParseRootHints(strings.NewReader(tt.data))
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("ParseRootHints() error = %v, want error = %v\n", err, tt.wantError)
+			}
+			if got := getAddressStrings(hints.Servers()); !slices.Equal(got, tt.want) {
+				t.Errorf("ParseRootHints() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultRootHints(t *testing.T) {
+	hints := DefaultRootHints()
+	if len(hints.NameServers) != 13 || hints.NameServers[0] != "a.root-servers.net." {
+		t.Errorf("DefaultRootHints() got name servers = %v\n", hints.NameServers)
+	}
+	servers := hints.Servers()
+	if len(servers) != 26 || !slices.Equal(servers[:13], DefaultRootServers) {
+		t.Errorf("DefaultRootHints() got = %v, want IPv4 addresses = %v\n", servers, DefaultRootServers)
+	}
+}
+
+func TestReadRootHints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "named.root")
+	if err := os.WriteFile(path, []byte(defaultRootHints), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hints, err := ReadRootHints(path)
+	if err != nil || len(hints.NameServers) != 13 {
+		t.Errorf("ReadRootHints() got = %v, error = %v\n", hints.NameServers, err)
+	}
+
+	if _, err := ReadRootHints(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadRootHints() error = %v, want error = %v\n", err, os.ErrNotExist)
+	}
+}
+
+func TestPrime(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []dns.ResourceRecord
+		want      []string
+		wantError error
+	}{
+		{
+			name: "Priming",
+			data: []dns.ResourceRecord{
+				newTestRecord(".", dns.NS, &dns.RDataNS{DomainName: "a.root-servers.test."}),
+				newTestRecord(".", dns.NS, &dns.RDataNS{DomainName: "b.root-servers.test."}),
+				newTestRecord("a.root-servers.test.", dns.AAAA, &dns.RDataAAAA{IP: netip.MustParseAddr("2001:db8::1")}),
+				newTestRecord("a.root-servers.test.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")}),
+				newTestRecord("b.root-servers.test.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.7")}),
+			},
+			want: []string{"192.0.2.1", "192.0.2.7", "2001:db8::1"},
+		},
+		{
+			name: "Invalid addresses",
+			data: []dns.ResourceRecord{
+				newTestRecord(".", dns.NS, &dns.RDataNS{DomainName: "a.root-servers.test."}),
+				newTestRecord(".", dns.NS, &dns.RDataNS{DomainName: "b.root-servers.test."}),
+				newTestRecord("a.root-servers.test.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")}),
+				newTestRecord("b.root-servers.test.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("127.0.0.1")}),
+				newTestRecord("b.root-servers.test.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("10.0.0.1")}),
+				newTestRecord("b.root-servers.test.", dns.AAAA, &dns.RDataAAAA{IP: netip.MustParseAddr("fe80::1")}),
+			},
+			want: []string{"192.0.2.1"},
+		},
+		{
+			name: "No valid address",
+			data: []dns.ResourceRecord{
+				newTestRecord(".", dns.NS, &dns.RDataNS{DomainName: "a.root-servers.test."}),
+				newTestRecord("a.root-servers.test.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("0.0.0.0")}),
+			},
+			wantError: ErrInvalidPriming,
+		},
+		{
+			name:      "No NS records",
+			wantError: ErrInvalidPriming,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servers := map[string]*testServer{"192.0.2.1": startTestServer(t, ".", tt.data...)}
+			resolver := newTestResolver(servers, "192.0.2.1")
+
+			hints, err := resolver.Prime()
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("Prime() error = %v, want error = %v\n", err, tt.wantError)
+			}
+			if got := getAddressStrings(hints.Servers()); !slices.Equal(got, tt.want) {
+				t.Errorf("Prime() got = %v, want = %v\n", got, tt.want)
+			}
+			wantRootServers := tt.want
+			if tt.wantError != nil {
+				wantRootServers = []string{"192.0.2.1"}
+			}
+			if got := getAddressStrings(resolver.RootServers); !slices.Equal(got, wantRootServers) {
+				t.Errorf("Prime() got root servers = %v, want = %v\n", got, wantRootServers)
+			}
+		})
+	}
+}
+
+func getAddressStrings(addresses []netip.Addr) []string {
+	var strings []string
+	for _, address := range addresses {
+		strings = append(strings, address.String())
+	}
+	return strings
+}
@@ -0,0 +1,148 @@
+package recursive
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/mcombeau/dns-tools/client"
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// Trace of a resolution: every query the resolver sent, in order, with the server it was sent to
+// and what it did with it: a referral to the servers of a subzone, an answer, or a failure, after
+// which the next server of the zone is tried. The queries for the addresses of name servers given
+// without glue appear where they were sent, with a greater depth.
+//
+// Trace.String renders it like "dig +trace": the records each server gave, the authority section
+// of referrals or the answers, followed by a line saying where and how fast they came from.
+
+// StepKind is what a server did with a query of a resolution.
+type StepKind int
+
+const (
+	StepReferral StepKind = iota // The server referred the query to the servers of a subzone
+	StepAnswer                   // The server answered with records, NXDOMAIN or no records of the type
+	StepFailure                  // The server did not answer, or answered with another response code than NOERROR or NXDOMAIN
+)
+
+var stepKindNames = map[StepKind]string{
+	StepReferral: "referral",
+	StepAnswer:   "answer",
+	StepFailure:  "failure",
+}
+
+func (kind StepKind) String() string {
+	if name, ok := stepKindNames[kind]; ok {
+		return name
+	}
+	return fmt.Sprintf("StepKind(%d)", int(kind))
+}
+
+// TraceStep is a query sent to a server during a resolution.
+type TraceStep struct {
+	Server   netip.Addr    // Address of the server the query was sent to
+	Zone     string        // Zone the server was queried as a server of, ex. "com."
+	Name     string        // Name queried, shorter than the name resolved with QNAME minimization
+	Type     uint16        // Type queried
+	Depth    int           // 0 for the queries for the name resolved, 1 and more for those for the addresses of name servers
+	Kind     StepKind      // What the server did with the query
+	Referral string        // Subzone the server referred the query to, for referrals
+	Response dns.Message   // Response of the server, empty for failures without response
+	Size     int           // Size of the response on the wire, in bytes
+	Duration time.Duration // Time elapsed between sending the query and receiving the response, or giving up
+	Err      error         // Why the query failed, for failures
+}
+
+// Trace is the list of queries sent during a resolution.
+type Trace struct {
+	Steps []TraceStep
+}
+
+// ResolveTrace resolves a name iteratively like Resolve, and returns the trace of the resolution.
+//
+// Parameters:
+//   - name: The name to resolve, ex. "www.example.com.".
+//   - qtype: The DNS record type to resolve.
+//
+// Returns:
+//   - dns.Message: The response to the question, see Resolve.
+//   - Trace: The queries sent, also when the resolution failed.
+//   - error: If the resolution failed, see Resolve.
+func (resolver *Resolver) ResolveTrace(name string, qtype uint16) (dns.Message, Trace, error) {
+	var trace Trace
+	response, err := resolver.resolve(dns.Fqdn(name), qtype, 0, &trace)
+	return response, trace, err
+}
+
+// add adds a step to the trace, if there is one.
+func (trace *Trace) add(step TraceStep) {
+	if trace != nil {
+		trace.Steps = append(trace.Steps, step)
+	}
+}
+
+// newTraceStep returns the step of a query sent to a server of a zone, from its response or error.
+func newTraceStep(server netip.Addr, zone string, name string, qtype uint16, depth int, response client.Response, duration time.Duration, err error) TraceStep {
+	step := TraceStep{
+		Server:   server,
+		Zone:     zone,
+		Name:     name,
+		Type:     qtype,
+		Depth:    depth,
+		Kind:     StepAnswer,
+		Response: response.Message,
+		Size:     response.Size,
+		Duration: duration,
+		Err:      err,
+	}
+	if err != nil {
+		step.Kind = StepFailure
+	} else if child, _ := getReferral(response.Message, zone, name); child != "" {
+		step.Kind = StepReferral
+		step.Referral = child
+	}
+	return step
+}
+
+// String returns the trace in the format of "dig +trace", ex.:
+//
+//	com.	172800	IN	NS	a.gtld-servers.net.
+//	;; Received 836 bytes from 198.41.0.4 (.) for www.example.com. A in 24 ms
+func (trace Trace) String() string {
+	var builder strings.Builder
+	for _, step := range trace.Steps {
+		builder.WriteString(step.String())
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// String returns the records of the step, the authority section of a referral or the answers,
+// and a line saying where and how fast they came from, or why the query failed.
+func (step TraceStep) String() string {
+	var builder strings.Builder
+	query := fmt.Sprintf("%s (%s) for %s %s", step.Server, step.Zone, step.Name, dns.DNSType(step.Type))
+	if step.Kind == StepFailure {
+		fmt.Fprintf(&builder, ";; No answer from %s in %d ms: %v\n", query, step.Duration.Milliseconds(), step.Err)
+		return builder.String()
+	}
+
+	records := step.Response.Answers
+	if step.Kind == StepReferral || len(records) == 0 {
+		records = step.Response.NameServers
+	}
+	for _, record := range records {
+		fmt.Fprintf(&builder, "%s\n", record.String())
+	}
+	if step.Kind == StepAnswer && len(step.Response.Answers) == 0 {
+		if step.Response.ResponseCode() == dns.NXDOMAIN {
+			builder.WriteString(";; NXDOMAIN: the name does not exist\n")
+		} else {
+			builder.WriteString(";; NODATA: no records of the type\n")
+		}
+	}
+	fmt.Fprintf(&builder, ";; Received %d bytes from %s in %d ms\n", step.Size, query, step.Duration.Milliseconds())
+	return builder.String()
+}
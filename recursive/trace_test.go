@@ -0,0 +1,98 @@
+package recursive
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestResolveTrace(t *testing.T) {
+	servers := startTestHierarchy(t, nil,
+		newTestRecord("www.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("198.51.100.1")}),
+	)
+	resolver := newTestResolver(servers, "192.0.2.1")
+	resolver.Timeout = 100 * time.Millisecond
+	// Nothing answers on the first root server
+	resolver.RootServers = []netip.Addr{netip.MustParseAddr("192.0.2.9"), netip.MustParseAddr("192.0.2.1")}
+
+	type step struct {
+		server   string
+		zone     string
+		kind     StepKind
+		referral string
+	}
+	want := []step{
+		{server: "192.0.2.9", zone: ".", kind: StepFailure},
+		{server: "192.0.2.1", zone: ".", kind: StepReferral, referral: "com."},
+		{server: "192.0.2.2", zone: "com.", kind: StepReferral, referral: "example.com."},
+		{server: "192.0.2.3", zone: "example.com.", kind: StepAnswer},
+	}
+
+	response, trace, err := resolver.ResolveTrace("www.example.com", dns.A)
+	if err != nil || len(response.Answers) != 1 {
+		t.Fatalf("ResolveTrace() got = %v, error = %v\n", response.Answers, err)
+	}
+	var got []step
+	for _, traceStep := range trace.Steps {
+		got = append(got, step{traceStep.Server.String(), traceStep.Zone, traceStep.Kind, traceStep.Referral})
+		if traceStep.Name != "www.example.com." || traceStep.Type != dns.A || traceStep.Depth != 0 {
+			t.Errorf("ResolveTrace() got query = %s %s at depth %d\n", traceStep.Name, dns.DNSType(traceStep.Type), traceStep.Depth)
+		}
+		if (traceStep.Kind == StepFailure) != (traceStep.Err != nil) || (traceStep.Kind != StepFailure && traceStep.Size == 0) {
+			t.Errorf("ResolveTrace() got %s step with size = %d, error = %v\n", traceStep.Kind, traceStep.Size, traceStep.Err)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolveTrace() got = %v, want = %v\n", got, want)
+	}
+
+	output := trace.String()
+	for _, line := range []string{
+		";; No answer from 192.0.2.9 (.) for www.example.com. A in ",
+		"com.\t300\tIN\tNS\ta.gtld-servers.net.\n",
+		";; Received ",
+		" bytes from 192.0.2.1 (.) for www.example.com. A in ",
+		"www.example.com.\t300\tIN\tA\t198.51.100.1\n",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Trace.String() got = %q, want it to contain %q\n", output, line)
+		}
+	}
+}
+
+func TestResolveTraceWithoutGlue(t *testing.T) {
+	servers := startTestHierarchy(t,
+		[]dns.ResourceRecord{
+			newTestRecord("net.", dns.NS, &dns.RDataNS{DomainName: "b.gtld-servers.net."}),
+			newTestRecord("b.gtld-servers.net.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.5")}),
+		},
+		newTestRecord("sub.example.com.", dns.NS, &dns.RDataNS{DomainName: "ns.sub-servers.net."}),
+	)
+	servers["192.0.2.4"] = startTestServer(t, "sub.example.com.")
+	servers["192.0.2.5"] = startTestServer(t, "net.",
+		newTestRecord("ns.sub-servers.net.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.4")}),
+	)
+	resolver := newTestResolver(servers, "192.0.2.1")
+
+	_, trace, err := resolver.ResolveTrace("missing.sub.example.com.", dns.A)
+	if err != nil {
+		t.Fatalf("ResolveTrace() unexpected error = %v\n", err)
+	}
+
+	var depths []int
+	for _, step := range trace.Steps {
+		depths = append(depths, step.Depth)
+	}
+	// The sub.example.com. servers' address is resolved from the root after the example.com. referral
+	if want := []int{0, 0, 0, 1, 1, 0}; !reflect.DeepEqual(depths, want) {
+		t.Errorf("ResolveTrace() got depths = %v, want = %v\n", depths, want)
+	}
+	last := trace.Steps[len(trace.Steps)-1]
+	if last.Kind != StepAnswer || last.Response.ResponseCode() != dns.NXDOMAIN || !strings.Contains(last.String(), ";; NXDOMAIN") {
+		t.Errorf("ResolveTrace() got last step = %s\n", last.String())
+	}
+}
@@ -0,0 +1,126 @@
+package resolver
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Bulk lookups resolve long lists of names, ex. for enumeration tools, with a bounded number of
+// lookups at once: each worker of the pool looks up one name at a time, and the results are sent
+// on a channel as they come, so that the first ones can be used before the last ones are looked up.
+// The duplicates of a name looked up at once share its queries, see flightGroup.
+//
+// So as not to flood the servers, the resolver spaces out the queries it sends to each server
+// when it sets MaxQPS: a query waits until the server's previous one is 1/MaxQPS second old.
+
+// DefaultBulkWorkers is the number of names LookupMany looks up at once when the options do not set one.
+const DefaultBulkWorkers = 16
+
+// BulkOptions are the options of a bulk lookup.
+type BulkOptions struct {
+	Workers int // Number of names looked up at once, DefaultBulkWorkers if 0
+}
+
+// BulkResult is the result of the lookup of a name of a bulk lookup.
+type BulkResult struct {
+	Index int    // Index of the name in the list looked up
+	Name  string // Name looked up, as given
+	Chain Chain  // Aliases and records of the name, see QueryChain
+	Err   error  // Error of the lookup, see QueryChain
+}
+
+// LookupMany looks up the records of a type of a list of names concurrently, see QueryChain.
+// The queries sent to each server are limited by the MaxQPS of the resolver.
+//
+// Parameters:
+//   - ctx: Stops the bulk lookup once done: the names not looked up yet are not, the queries
+//     waiting to be sent within MaxQPS are not sent, and the channel is closed once the queries
+//     in progress are over.
+//   - names: The names to look up, ex. "www.example.com.".
+//   - qtype: The DNS record type to look up.
+//   - opts: The options of the bulk lookup.
+//
+// Returns:
+//   - <-chan BulkResult: The results, one per name, in the order the lookups end. It is closed
+//     once all the names are looked up.
+func (resolver *Resolver) LookupMany(ctx context.Context, names []string, qtype uint16, opts BulkOptions) <-chan BulkResult {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultBulkWorkers
+	}
+	workers = min(workers, max(len(names), 1))
+
+	indexes := make(chan int)
+	results := make(chan BulkResult, workers)
+	go func() {
+		defer close(indexes)
+		for i := range names {
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var group sync.WaitGroup
+	for range workers {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for i := range indexes {
+				if ctx.Err() != nil {
+					return
+				}
+				chain, err := resolver.queryChainContext(ctx, names[i], qtype)
+				select {
+				case results <- BulkResult{Index: i, Name: names[i], Chain: chain, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		group.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// serverLimiters space out the queries sent to each server. The zero value is ready to use.
+type serverLimiters struct {
+	mutex sync.Mutex
+	next  map[string]time.Time // Time from which the next query may be sent, by server address
+}
+
+// wait waits until a query may be sent to a server at a rate of qps queries per second at most,
+// and reserves the time it is sent at. It does not wait if qps is 0, and stops waiting with the error
+// of the context once it is done.
+func (limiters *serverLimiters) wait(ctx context.Context, server string, qps float64) error {
+	if qps <= 0 {
+		return nil
+	}
+
+	limiters.mutex.Lock()
+	if limiters.next == nil {
+		limiters.next = map[string]time.Time{}
+	}
+	now := time.Now()
+	sendTime := limiters.next[server]
+	if sendTime.Before(now) {
+		sendTime = now
+	}
+	limiters.next[server] = sendTime.Add(time.Duration(float64(time.Second) / qps))
+	limiters.mutex.Unlock()
+
+	timer := time.NewTimer(time.Until(sendTime))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,115 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestLookupMany(t *testing.T) {
+	var zone []dns.ResourceRecord
+	var names []string
+	for i := range 50 {
+		name := fmt.Sprintf("host%d.example.com.", i)
+		names = append(names, name)
+		if i%2 == 0 {
+			zone = append(zone, dns.ResourceRecord{Name: name, RType: dns.A, RClass: dns.IN, TTL: 300, RData: &dns.RDataA{IP: netip.AddrFrom4([4]byte{192, 0, 2, byte(i)})}})
+		}
+	}
+	resolver := startTestServer(t, zone)
+
+	seen := map[int]bool{}
+	for result := range resolver.LookupMany(context.Background(), names, dns.A, BulkOptions{Workers: 4}) {
+		if seen[result.Index] || result.Name != names[result.Index] {
+			t.Errorf("LookupMany() got result = %+v twice or for the wrong name\n", result)
+		}
+		seen[result.Index] = true
+
+		if result.Index%2 == 0 {
+			want := netip.AddrFrom4([4]byte{192, 0, 2, byte(result.Index)})
+			if result.Err != nil || len(result.Chain.Answers) != 1 || result.Chain.Answers[0].RData.(*dns.RDataA).IP != want {
+				t.Errorf("LookupMany() got = %v, error = %v for %s, want = %v\n", result.Chain.Answers, result.Err, result.Name, want)
+			}
+		} else if !errors.Is(result.Err, ErrNameNotFound) {
+			t.Errorf("LookupMany() got error = %v for %s, want = %v\n", result.Err, result.Name, ErrNameNotFound)
+		}
+	}
+	if len(seen) != len(names) {
+		t.Errorf("LookupMany() got %d results, want = %d\n", len(seen), len(names))
+	}
+}
+
+func TestLookupManyCancelled(t *testing.T) {
+	resolver := startTestServer(t, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	names := make([]string, 1000)
+	for i := range names {
+		names[i] = fmt.Sprintf("host%d.example.com.", i)
+	}
+	results := 0
+	for range resolver.LookupMany(ctx, names, dns.A, BulkOptions{}) {
+		results++
+	}
+	if results >= len(names) {
+		t.Errorf("LookupMany() got %d results after the context was cancelled\n", results)
+	}
+}
+
+func TestLookupManyCancelledRateLimited(t *testing.T) {
+	resolver := startTestServer(t, nil)
+	resolver.MaxQPS = 1
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	names := make([]string, 20)
+	for i := range names {
+		names[i] = fmt.Sprintf("host%d.example.com.", i)
+	}
+	// At 1 query per second, the 4 workers reserve slots several seconds ahead: they must
+	// give them up once the context is done instead of sleeping through them.
+	start := time.Now()
+	for range resolver.LookupMany(ctx, names, dns.A, BulkOptions{Workers: 4}) {
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("LookupMany() at 1 qps got closed %v after the start, want = less than 1s after a 100ms timeout\n", elapsed)
+	}
+}
+
+func TestServerLimiters(t *testing.T) {
+	var limiters serverLimiters
+	start := time.Now()
+	for range 5 {
+		limiters.wait(context.Background(), "192.0.2.53:53", 100)
+	}
+	// The first query is sent at once, the others 10 ms apart
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("wait() at 100 qps got 5 queries in %v, want = 40ms or more\n", elapsed)
+	}
+
+	// One query per second to each server: another server or no limit do not wait
+	start = time.Now()
+	limiters.wait(context.Background(), "192.0.2.54:53", 1)
+	limiters.wait(context.Background(), "192.0.2.55:53", 1)
+	limiters.wait(context.Background(), "192.0.2.54:53", 0)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("wait() for other servers got %v, want no wait\n", elapsed)
+	}
+
+	// The next query to the server may only be sent in a second: a done context stops the wait
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start = time.Now()
+	if err := limiters.wait(ctx, "192.0.2.54:53", 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("wait() with a cancelled context got error = %v, want = %v\n", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("wait() with a cancelled context got %v, want no wait\n", elapsed)
+	}
+}
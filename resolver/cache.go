@@ -0,0 +1,245 @@
+package resolver
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// Cache of the answers of queries, by name, type and class: the records of an answer are kept
+// until the smallest of their TTLs expires, and are returned with their TTLs decreased by the time
+// they spent in the cache. Expired answers are removed when they are looked up or swept, and
+// the least recently used answers are evicted to keep the cache within its maximum size.
+//
+// Only answers with records are cached: names which do not exist or have no records of the type
+// are queried again each time.
+//
+// A Resolver prefetches the popular answers: an answer served from the cache more than PrefetchHits
+// times with less than PrefetchTTL left is queried again in the background, so that it is
+// replaced before it expires and the names looked up often are always answered from the cache.
+
+// CacheKey identifies the answer of a query in a Cache.
+type CacheKey struct {
+	Name  string // Fully qualified name in lowercase, see NewCacheKey
+	Type  uint16
+	Class uint16
+}
+
+// NewCacheKey returns the key of the answer to a query for a name, type and class,
+// the name in canonical form so that it matches whatever its case.
+func NewCacheKey(name string, qtype uint16, qclass uint16) CacheKey {
+	return CacheKey{Name: dns.CanonicalName(name), Type: qtype, Class: qclass}
+}
+
+// Cache holds the answers of queries until their TTLs expire. It is safe for concurrent use.
+// The zero value is an empty cache without a maximum size, ready to use.
+type Cache struct {
+	MaxEntries   int           // Maximum number of answers, the least recently used are evicted beyond it, no limit if 0
+	PrefetchTTL  time.Duration // Time left to an answer below which it is prefetched, no prefetching if 0
+	PrefetchHits int           // Number of times an answer must have been served before it is prefetched
+
+	mutex   sync.Mutex
+	entries map[CacheKey]*list.Element
+	lru     *list.List       // Entries, most recently used first
+	now     func() time.Time // Current time, replaced in tests
+	stats   CacheStats       // Counters, see Stats
+}
+
+type cacheEntry struct {
+	key         CacheKey
+	records     []dns.ResourceRecord
+	stored      time.Time
+	expires     time.Time
+	hits        int  // Number of times the answer was served
+	prefetching bool // Whether the answer is being prefetched
+}
+
+// NewCache returns an empty cache.
+//
+// Parameters:
+//   - maxEntries: The maximum number of answers, 0 for no limit.
+func NewCache(maxEntries int) *Cache {
+	return &Cache{
+		MaxEntries: maxEntries,
+		entries:    map[CacheKey]*list.Element{},
+		lru:        list.New(),
+		now:        time.Now,
+	}
+}
+
+// Set stores the records of an answer, replacing the previous answer to the query.
+// The answer expires after the smallest TTL of its records: an answer without records,
+// or with a record whose TTL is 0, is not stored.
+//
+// Parameters:
+//   - key: The query the records answer.
+//   - records: The records of the answer, ex. the CNAME records and the RRset they lead to.
+func (cache *Cache) Set(key CacheKey, records []dns.ResourceRecord) {
+	if len(records) == 0 {
+		return
+	}
+	ttl := dns.MaxTTL
+	for _, record := range records {
+		ttl = min(ttl, dns.TTL(record.TTL).Normalized())
+	}
+	if ttl == 0 {
+		return
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.initialize()
+
+	now := cache.now()
+	entry := &cacheEntry{
+		key:     key,
+		records: append([]dns.ResourceRecord(nil), records...),
+		stored:  now,
+		expires: now.Add(ttl.ToDuration()),
+	}
+	if element, ok := cache.entries[key]; ok {
+		// A prefetched answer stays as popular as the answer it replaces
+		entry.hits = element.Value.(*cacheEntry).hits
+		element.Value = entry
+		cache.lru.MoveToFront(element)
+		return
+	}
+
+	cache.entries[key] = cache.lru.PushFront(entry)
+	for cache.MaxEntries > 0 && cache.lru.Len() > cache.MaxEntries {
+		cache.remove(cache.lru.Back())
+		cache.stats.Evictions++
+	}
+}
+
+// Get returns the records of the answer to a query, with their TTLs decreased by the time elapsed
+// since they were stored. Their RData is shared with the cache and must not be modified.
+//
+// Returns:
+//   - []dns.ResourceRecord: The records of the answer.
+//   - bool: Whether the answer is in the cache and has not expired.
+func (cache *Cache) Get(key CacheKey) ([]dns.ResourceRecord, bool) {
+	records, ok, _ := cache.get(key, false)
+	return records, ok
+}
+
+// get returns the records of the answer to a query, see Get, and whether it should be prefetched,
+// in which case it is marked as being prefetched if prefetch is set.
+func (cache *Cache) get(key CacheKey, prefetch bool) (records []dns.ResourceRecord, ok bool, shouldPrefetch bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.initialize()
+
+	element, ok := cache.entries[key]
+	if !ok {
+		cache.stats.Misses++
+		return nil, false, false
+	}
+	entry := element.Value.(*cacheEntry)
+	now := cache.now()
+	if !now.Before(entry.expires) {
+		cache.remove(element)
+		cache.stats.Misses++
+		cache.stats.Expired++
+		return nil, false, false
+	}
+
+	cache.lru.MoveToFront(element)
+	cache.stats.Hits++
+	entry.hits++
+	if prefetch && cache.PrefetchTTL > 0 && !entry.prefetching && entry.hits > cache.PrefetchHits && entry.expires.Sub(now) < cache.PrefetchTTL {
+		entry.prefetching = true
+		shouldPrefetch = true
+	}
+
+	elapsed := now.Sub(entry.stored)
+	records = make([]dns.ResourceRecord, 0, len(entry.records))
+	for _, record := range entry.records {
+		record.TTL = uint32(dns.TTL(record.TTL).Remaining(elapsed))
+		records = append(records, record)
+	}
+	return records, true, shouldPrefetch
+}
+
+// Delete removes the answer to a query from the cache.
+func (cache *Cache) Delete(key CacheKey) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.initialize()
+
+	if element, ok := cache.entries[key]; ok {
+		cache.remove(element)
+	}
+}
+
+// Len returns the number of answers in the cache, including the expired ones not yet swept.
+func (cache *Cache) Len() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.initialize()
+
+	return cache.lru.Len()
+}
+
+// Sweep removes the expired answers from the cache.
+//
+// Returns:
+//   - int: The number of answers removed.
+func (cache *Cache) Sweep() (removed int) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.initialize()
+
+	now := cache.now()
+	for element := cache.lru.Front(); element != nil; {
+		next := element.Next()
+		if !now.Before(element.Value.(*cacheEntry).expires) {
+			cache.remove(element)
+			cache.stats.Expired++
+			removed++
+		}
+		element = next
+	}
+	return removed
+}
+
+// StartSweeper sweeps the cache in the background at the given interval, until the context is done,
+// so that the expired answers of names which are not looked up again do not fill the cache.
+//
+// Parameters:
+//   - ctx: The context which stops the sweeper when done.
+//   - interval: The time between two sweeps, ex. time.Minute.
+func (cache *Cache) StartSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				cache.Sweep()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// initialize creates the entries of a zero-value cache, whose mutex must be held.
+func (cache *Cache) initialize() {
+	if cache.entries == nil {
+		cache.entries = map[CacheKey]*list.Element{}
+		cache.lru = list.New()
+	}
+	if cache.now == nil {
+		cache.now = time.Now
+	}
+}
+
+// remove removes an entry from the cache, whose mutex must be held.
+func (cache *Cache) remove(element *list.Element) {
+	cache.lru.Remove(element)
+	delete(cache.entries, element.Value.(*cacheEntry).key)
+}
@@ -0,0 +1,127 @@
+package resolver
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// Cache file format, so that a cache survives the restarts of a long-running program:
+//
+//	"DNSCACHE" | version (1 byte) | time of the save (8 bytes, Unix seconds) | answers...
+//
+// Each answer is a length-prefixed DNS message, as on TCP connections, whose question is the
+// query and whose answer section holds its records with their TTLs at the time of the save.
+// The answers are in the order they were last used, the least recently used first.
+
+var ErrInvalidCacheFile = fmt.Errorf("invalid cache file")
+
+const (
+	cacheFileMagic   = "DNSCACHE"
+	cacheFileVersion = 1
+)
+
+// Save writes the answers of the cache which have not expired, with the time left to them.
+//
+// Parameters:
+//   - w: The writer to write the cache file to.
+//
+// Returns:
+//   - error: If an answer cannot be encoded or the cache file cannot be written.
+func (cache *Cache) Save(w io.Writer) error {
+	now, messages := cache.snapshot()
+
+	writer := bufio.NewWriter(w)
+	header := append([]byte(cacheFileMagic), cacheFileVersion)
+	header = binary.BigEndian.AppendUint64(header, uint64(now.Unix()))
+	if _, err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		data, err := dns.EncodeMessage(message)
+		if err != nil {
+			return fmt.Errorf("encode answer for %s: %w", message.Questions[0].Name, err)
+		}
+		if err = dns.WriteRawMessage(writer, data); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// Load stores the answers of a cache file, with their TTLs decreased by the time elapsed since
+// it was saved: the answers which expired in the meantime are left out. The answers of the
+// cache file replace the answers to the same queries in the cache.
+//
+// Parameters:
+//   - r: The reader to read the cache file from.
+//
+// Returns:
+//   - error: ErrInvalidCacheFile if the cache file is not one, or if it cannot be read.
+func (cache *Cache) Load(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	header := make([]byte, len(cacheFileMagic)+1+8)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCacheFile, err)
+	}
+	if string(header[:len(cacheFileMagic)]) != cacheFileMagic || header[len(cacheFileMagic)] != cacheFileVersion {
+		return fmt.Errorf("%w: unknown format", ErrInvalidCacheFile)
+	}
+	saved := time.Unix(int64(binary.BigEndian.Uint64(header[len(cacheFileMagic)+1:])), 0)
+	cache.mutex.Lock()
+	cache.initialize()
+	elapsed := max(cache.now().Sub(saved), 0)
+	cache.mutex.Unlock()
+
+	scanner := dns.NewMessageScanner(reader, dns.DecodeOptions{})
+	for scanner.Scan() {
+		message := scanner.Message()
+		if len(message.Questions) != 1 {
+			return fmt.Errorf("%w: answer with %d questions", ErrInvalidCacheFile, len(message.Questions))
+		}
+
+		question := message.Questions[0]
+		records := message.Answers
+		for i := range records {
+			records[i].TTL = uint32(dns.TTL(records[i].TTL).Remaining(elapsed))
+		}
+		cache.Set(NewCacheKey(question.Name, question.QType, question.QClass), records)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCacheFile, err)
+	}
+	return nil
+}
+
+// snapshot returns the current time and the answers of the cache which have not expired, as
+// messages with the time left to their records, the least recently used first.
+func (cache *Cache) snapshot() (now time.Time, messages []dns.Message) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.initialize()
+
+	now = cache.now()
+	for element := cache.lru.Back(); element != nil; element = element.Prev() {
+		entry := element.Value.(*cacheEntry)
+		if !now.Before(entry.expires) {
+			continue
+		}
+
+		message := dns.Message{
+			Header:    dns.Header{Flags: dns.Flags{Response: true}},
+			Questions: []dns.Question{{Name: entry.key.Name, QType: entry.key.Type, QClass: entry.key.Class}},
+		}
+		elapsed := now.Sub(entry.stored)
+		for _, record := range entry.records {
+			record.TTL = uint32(dns.TTL(record.TTL).Remaining(elapsed))
+			message.Answers = append(message.Answers, record)
+		}
+		messages = append(messages, message)
+	}
+	return now, messages
+}
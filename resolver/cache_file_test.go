@@ -0,0 +1,60 @@
+package resolver
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestCacheSaveLoad(t *testing.T) {
+	cache, now := newTestCache(0)
+	longKey := NewCacheKey("long.example.", dns.A, dns.IN)
+	shortKey := NewCacheKey("short.example.", dns.A, dns.IN)
+	cache.Set(longKey, []dns.ResourceRecord{newTestRecord("long.example.", 3600)})
+	cache.Set(shortKey, []dns.ResourceRecord{newTestRecord("short.example.", 60)})
+
+	*now = now.Add(10 * time.Second)
+	var buffer bytes.Buffer
+	if err := cache.Save(&buffer); err != nil {
+		t.Fatalf("Save() unexpected error = %v\n", err)
+	}
+
+	// Loaded 100 seconds after the save: the short answer expired in the meantime
+	loaded, loadedNow := newTestCache(0)
+	*loadedNow = now.Add(100 * time.Second)
+	if err := loaded.Load(bytes.NewReader(buffer.Bytes())); err != nil {
+		t.Fatalf("Load() unexpected error = %v\n", err)
+	}
+
+	records, ok := loaded.Get(longKey)
+	if !ok || len(records) != 1 || records[0].TTL != 3490 || !records[0].Equal(newTestRecord("long.example.", 3490)) {
+		t.Errorf("Get() after Load() got = %v, %t, want the record with TTL 3490\n", records, ok)
+	}
+	if _, ok = loaded.Get(shortKey); ok || loaded.Len() != 1 {
+		t.Errorf("Get() after Load() got the expired answer, %d answers\n", loaded.Len())
+	}
+}
+
+func TestCacheLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Empty", data: nil},
+		{name: "Wrong magic", data: []byte("NOTCACHE\x01\x00\x00\x00\x00\x00\x00\x00\x00")},
+		{name: "Wrong version", data: []byte("DNSCACHE\x02\x00\x00\x00\x00\x00\x00\x00\x00")},
+		{name: "Truncated answer", data: []byte("DNSCACHE\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x0c\x00")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewCache(0).Load(bytes.NewReader(tt.data))
+			if !errors.Is(err, ErrInvalidCacheFile) {
+				t.Errorf("Load() error = %v, want error = %v\n", err, ErrInvalidCacheFile)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package resolver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// Inspection of a cache while it is in use, so that the operators of a long-running resolver
+// can see how well it caches and what, without restarting it: counters of the lookups answered
+// from the cache or not and of the answers removed, and a dump of the answers it holds.
+//
+// A lookup of an expired answer counts as a miss, and the answer as expired.
+
+// CacheStats are the counters of a cache, since it was created.
+type CacheStats struct {
+	Hits       uint64 // Number of lookups answered from the cache
+	Misses     uint64 // Number of lookups of answers not in the cache, or expired
+	Evictions  uint64 // Number of answers evicted to keep the cache within its maximum size
+	Expired    uint64 // Number of expired answers removed, when looked up or swept
+	Entries    int    // Number of answers in the cache, including the expired ones not yet swept
+	MaxEntries int    // Maximum number of answers, no limit if 0
+}
+
+// HitRate returns the share of the lookups answered from the cache, from 0 to 1, 0 without lookups.
+func (stats CacheStats) HitRate() float64 {
+	if stats.Hits+stats.Misses == 0 {
+		return 0
+	}
+	return float64(stats.Hits) / float64(stats.Hits+stats.Misses)
+}
+
+// CacheEntry is an answer held in a cache.
+type CacheEntry struct {
+	Key     CacheKey             // Query the answer is for
+	TTL     time.Duration        // Time left before the answer expires
+	Hits    int                  // Number of times the answer was served
+	Records []dns.ResourceRecord // Records of the answer, with their TTLs decreased by the time they spent in the cache
+}
+
+// Stats returns the counters of the cache.
+func (cache *Cache) Stats() CacheStats {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.initialize()
+
+	stats := cache.stats
+	stats.Entries = cache.lru.Len()
+	stats.MaxEntries = cache.MaxEntries
+	return stats
+}
+
+// Entries returns the answers of the cache which have not expired, the most recently used first.
+// Their RData is shared with the cache and must not be modified.
+func (cache *Cache) Entries() []CacheEntry {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.initialize()
+
+	now := cache.now()
+	entries := make([]CacheEntry, 0, cache.lru.Len())
+	for element := cache.lru.Front(); element != nil; element = element.Next() {
+		entry := element.Value.(*cacheEntry)
+		if !now.Before(entry.expires) {
+			continue
+		}
+
+		elapsed := now.Sub(entry.stored)
+		records := make([]dns.ResourceRecord, 0, len(entry.records))
+		for _, record := range entry.records {
+			record.TTL = uint32(dns.TTL(record.TTL).Remaining(elapsed))
+			records = append(records, record)
+		}
+		entries = append(entries, CacheEntry{Key: entry.key, TTL: entry.expires.Sub(now), Hits: entry.hits, Records: records})
+	}
+	return entries
+}
+
+// Dump writes the answers of the cache which have not expired, the most recently used first,
+// one per line with the name, type and class of the query, the seconds left and the hits, ex.:
+//
+//	example.com.	A	IN	295	3
+//
+// Parameters:
+//   - w: The writer to write the answers to.
+//
+// Returns:
+//   - error: If the answers cannot be written.
+func (cache *Cache) Dump(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	for _, entry := range cache.Entries() {
+		if _, err := fmt.Fprintf(writer, "%s\t%s\t%s\t%d\t%d\n", entry.Key.Name, dns.DNSType(entry.Key.Type),
+			dns.DNSClass(entry.Key.Class), int(entry.TTL.Seconds()), entry.Hits); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
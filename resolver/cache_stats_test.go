@@ -0,0 +1,71 @@
+package resolver
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestCacheStats(t *testing.T) {
+	cache, now := newTestCache(2)
+	keys := []CacheKey{
+		NewCacheKey("a.example.", dns.A, dns.IN),
+		NewCacheKey("b.example.", dns.A, dns.IN),
+		NewCacheKey("c.example.", dns.A, dns.IN),
+	}
+	cache.Set(keys[0], []dns.ResourceRecord{newTestRecord(keys[0].Name, 60)})
+	cache.Set(keys[1], []dns.ResourceRecord{newTestRecord(keys[1].Name, 300)})
+	cache.Set(keys[2], []dns.ResourceRecord{newTestRecord(keys[2].Name, 30)})
+
+	cache.Get(keys[0])
+	cache.Get(keys[1])
+	cache.Get(keys[1])
+	*now = now.Add(time.Minute)
+	cache.Get(keys[2])
+
+	got := cache.Stats()
+	want := CacheStats{Hits: 2, Misses: 2, Evictions: 1, Expired: 1, Entries: 1, MaxEntries: 2}
+	if got != want {
+		t.Errorf("Stats() got = %+v, want = %+v\n", got, want)
+	}
+	if got.HitRate() != 0.5 {
+		t.Errorf("HitRate() got = %v, want = 0.5\n", got.HitRate())
+	}
+	if (CacheStats{}).HitRate() != 0 {
+		t.Errorf("HitRate() without lookups got = %v, want = 0\n", CacheStats{}.HitRate())
+	}
+}
+
+func TestCacheEntries(t *testing.T) {
+	cache, now := newTestCache(0)
+	longKey := NewCacheKey("long.example.", dns.A, dns.IN)
+	shortKey := NewCacheKey("short.example.", dns.A, dns.IN)
+	expiredKey := NewCacheKey("expired.example.", dns.A, dns.IN)
+	cache.Set(expiredKey, []dns.ResourceRecord{newTestRecord(expiredKey.Name, 10)})
+	cache.Set(longKey, []dns.ResourceRecord{newTestRecord(longKey.Name, 300)})
+	cache.Set(shortKey, []dns.ResourceRecord{newTestRecord(shortKey.Name, 60)})
+	cache.Get(longKey)
+
+	*now = now.Add(20 * time.Second)
+	entries := cache.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Entries() got = %+v, want 2 entries\n", entries)
+	}
+	if entries[0].Key != longKey || entries[0].TTL != 280*time.Second || entries[0].Hits != 1 || entries[0].Records[0].TTL != 280 {
+		t.Errorf("Entries() got = %+v, want %s first with 280s left and 1 hit\n", entries[0], longKey.Name)
+	}
+	if entries[1].Key != shortKey || entries[1].TTL != 40*time.Second || entries[1].Hits != 0 {
+		t.Errorf("Entries() got = %+v, want %s with 40s left\n", entries[1], shortKey.Name)
+	}
+
+	var buffer bytes.Buffer
+	if err := cache.Dump(&buffer); err != nil {
+		t.Fatalf("Dump() error = %v\n", err)
+	}
+	want := "long.example.\tA\tIN\t280\t1\nshort.example.\tA\tIN\t40\t0\n"
+	if buffer.String() != want {
+		t.Errorf("Dump() got = %q, want = %q\n", buffer.String(), want)
+	}
+}
@@ -0,0 +1,173 @@
+package resolver
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func newTestCache(maxEntries int) (*Cache, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewCache(maxEntries)
+	cache.now = func() time.Time { return now }
+	return cache, &now
+}
+
+func newTestRecord(name string, ttl uint32) dns.ResourceRecord {
+	return dns.ResourceRecord{Name: name, RType: dns.A, RClass: dns.IN, TTL: ttl, RData: &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")}}
+}
+
+func TestCacheTTL(t *testing.T) {
+	cache, now := newTestCache(0)
+	key := NewCacheKey("Example.com", dns.A, dns.IN)
+	cache.Set(key, []dns.ResourceRecord{
+		newTestRecord("www.example.com.", 300),
+		newTestRecord("example.com.", 60),
+	})
+
+	*now = now.Add(20 * time.Second)
+	records, ok := cache.Get(NewCacheKey("example.com.", dns.A, dns.IN))
+	if !ok || len(records) != 2 || records[0].TTL != 280 || records[1].TTL != 40 {
+		t.Fatalf("Get() got = %v, %t, want TTLs 280 and 40\n", records, ok)
+	}
+
+	// The answer expires with the smallest TTL of its records
+	*now = now.Add(40 * time.Second)
+	if records, ok = cache.Get(key); ok {
+		t.Errorf("Get() after expiry got = %v, want none\n", records)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Len() after expiry got = %d, want = 0\n", cache.Len())
+	}
+
+	cache.Set(key, []dns.ResourceRecord{newTestRecord("example.com.", 0)})
+	cache.Set(NewCacheKey("example.net.", dns.A, dns.IN), nil)
+	if cache.Len() != 0 {
+		t.Errorf("Len() after storing answers without TTL or records got = %d, want = 0\n", cache.Len())
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	cache, now := newTestCache(2)
+	keys := []CacheKey{
+		NewCacheKey("a.example.", dns.A, dns.IN),
+		NewCacheKey("b.example.", dns.A, dns.IN),
+		NewCacheKey("c.example.", dns.A, dns.IN),
+	}
+	cache.Set(keys[0], []dns.ResourceRecord{newTestRecord(keys[0].Name, 60)})
+	cache.Set(keys[1], []dns.ResourceRecord{newTestRecord(keys[1].Name, 300)})
+	cache.Get(keys[0])
+	cache.Set(keys[2], []dns.ResourceRecord{newTestRecord(keys[2].Name, 300)})
+
+	// The least recently used answer is evicted
+	if _, ok := cache.Get(keys[1]); ok {
+		t.Errorf("Get() got the least recently used answer, want it evicted\n")
+	}
+	if _, ok := cache.Get(keys[0]); !ok {
+		t.Errorf("Get() got no answer for the most recently used answer\n")
+	}
+
+	*now = now.Add(time.Minute)
+	if removed := cache.Sweep(); removed != 1 || cache.Len() != 1 {
+		t.Errorf("Sweep() got = %d removed, %d left, want = 1 removed, 1 left\n", removed, cache.Len())
+	}
+}
+
+func TestCacheZeroValue(t *testing.T) {
+	var cache Cache
+	key := NewCacheKey("example.com.", dns.A, dns.IN)
+	if _, ok := cache.Get(key); ok || cache.Len() != 0 || len(cache.Entries()) != 0 || cache.Sweep() != 0 {
+		t.Errorf("zero-value Cache got answers, want none\n")
+	}
+
+	cache.Set(key, []dns.ResourceRecord{newTestRecord(key.Name, 300)})
+	if records, ok := cache.Get(key); !ok || len(records) != 1 {
+		t.Errorf("Get() got = %v, %v, want the answer set\n", records, ok)
+	}
+	cache.Delete(key)
+	if cache.Len() != 0 {
+		t.Errorf("Len() got = %d after Delete(), want = 0\n", cache.Len())
+	}
+}
+
+func TestResolverCache(t *testing.T) {
+	resolver := startTestServer(t, []dns.ResourceRecord{newTestRecord("example.com.", 300)})
+	resolver.Cache = NewCache(0)
+
+	want := []netip.Addr{netip.MustParseAddr("192.0.2.1")}
+	addresses, err := resolver.LookupA("example.com.")
+	if err != nil || !reflect.DeepEqual(addresses, want) {
+		t.Fatalf("LookupA() got = %v, error = %v\n", addresses, err)
+	}
+
+	// Answered from the cache without the server
+	resolver.Client.Server = "127.0.0.1:1"
+	addresses, err = resolver.LookupA("example.com.")
+	if err != nil || !reflect.DeepEqual(addresses, want) {
+		t.Errorf("LookupA() from the cache got = %v, error = %v\n", addresses, err)
+	}
+}
+
+func TestCachePrefetch(t *testing.T) {
+	cache, now := newTestCache(0)
+	cache.PrefetchTTL = 30 * time.Second
+	cache.PrefetchHits = 2
+	key := NewCacheKey("example.com.", dns.A, dns.IN)
+	cache.Set(key, []dns.ResourceRecord{newTestRecord("example.com.", 60)})
+
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    bool
+	}{
+		{name: "Not near expiry", elapsed: 0, want: false},
+		{name: "Not enough hits", elapsed: 40 * time.Second, want: false},
+		{name: "Popular and near expiry", elapsed: 0, want: true},
+		{name: "Already prefetching", elapsed: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*now = now.Add(tt.elapsed)
+			_, ok, got := cache.get(key, true)
+			if !ok || got != tt.want {
+				t.Errorf("get() got = %t, %t, want = true, %t\n", ok, got, tt.want)
+			}
+		})
+	}
+
+	// The prefetched answer replaces the answer being prefetched
+	cache.Set(key, []dns.ResourceRecord{newTestRecord("example.com.", 60)})
+	if _, _, got := cache.get(key, true); got {
+		t.Errorf("get() after prefetch got = %t, want = false\n", got)
+	}
+}
+
+func TestResolverPrefetch(t *testing.T) {
+	resolver := startTestServer(t, []dns.ResourceRecord{newTestRecord("example.com.", 10)})
+	cache, now := newTestCache(0)
+	cache.PrefetchTTL = time.Minute
+	resolver.Cache = cache
+	key := NewCacheKey("example.com.", dns.A, dns.IN)
+
+	if _, err := resolver.LookupA("example.com."); err != nil {
+		t.Fatalf("LookupA() unexpected error = %v\n", err)
+	}
+	// Served from the cache with 5 seconds left, and prefetched
+	*now = now.Add(5 * time.Second)
+	if _, err := resolver.LookupA("example.com."); err != nil {
+		t.Fatalf("LookupA() unexpected error = %v\n", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if records, ok := cache.Get(key); ok && records[0].TTL == 10 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("LookupA() did not prefetch the answer\n")
+}
@@ -0,0 +1,221 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// Aliases of names: a CNAME record makes its owner an alias of another name, and a DNAME record
+// makes the names under its owner aliases of the same names under its target [RFC6672], ex.
+// "www.example.com." of "www.example.net." with a DNAME record from "example.com." to "example.net.".
+//
+// Recursive servers usually follow the aliases themselves and answer with the whole chain,
+// but some stop at the first alias, or at the ones leading out of their zones: the resolver
+// then sends a query for the name the chain stopped at, until it reaches a name which has
+// records of the type or none. A chain which comes back to a name already followed, or which
+// is longer than the resolver's MaxChainLength, is an error.
+
+var (
+	ErrAliasLoop         = fmt.Errorf("CNAME or DNAME loop")
+	ErrAliasChainTooLong = fmt.Errorf("CNAME or DNAME chain too long")
+)
+
+// DefaultMaxChainLength is the number of aliases followed to look up a name when the resolver does not set one.
+const DefaultMaxChainLength = 8
+
+// maxNameLength is the length of the longest name in presentation format, with a final dot:
+// its wire format, one byte longer, is at most 255 bytes [RFC1035].
+const maxNameLength = 254
+
+// Chain is the result of a lookup which followed the aliases of a name.
+type Chain struct {
+	Name    string               // Name looked up, with the search domain it was found in, ex. "www.example.com."
+	Aliases []dns.ResourceRecord // CNAME and DNAME records followed, in order, ex. a DNAME record and the CNAME record synthesized from it
+	Target  string               // Name the chain leads to, the name looked up if it is not an alias
+	Answers []dns.ResourceRecord // Records of the type of the target, none if it has none
+	Glue    []dns.ResourceRecord // A and AAAA records of the additional sections of the responses, ex. for the targets of MX records
+	Queries []QueryInfo          // How the queries of the lookup were answered, in order, see QueryInfo
+}
+
+// String returns the names of the chain, from the name looked up to its target,
+// ex. "www.example.com. -> www.example.net. -> web.example.org.".
+func (chain Chain) String() string {
+	names := []string{chain.Name}
+	for _, record := range chain.Aliases {
+		last := names[len(names)-1]
+		next := ""
+		switch rdata := record.RData.(type) {
+		case *dns.RDataCNAME:
+			next = rdata.DomainName
+		case *dns.RDataDNAME:
+			next, _ = substituteDNAME(last, record.Name, rdata.Target)
+		}
+		// The CNAME record synthesized from a DNAME record leads to the same name
+		if next != "" && !dns.EqualNames(next, last) {
+			names = append(names, next)
+		}
+	}
+	return strings.Join(names, " -> ")
+}
+
+// QueryChain looks up the records of a type of a name, see Query, following its aliases across
+// queries when the server did not follow them all.
+//
+// Parameters:
+//   - name: The name to look up, ex. "www".
+//   - qtype: The DNS record type to look up. The aliases are not followed for CNAME queries,
+//     and the DNAME records are not followed for DNAME queries.
+//
+// Returns:
+//   - Chain: The aliases followed, the records of the type of their target, and the metadata of the
+//     queries sent. The chain followed so far is returned along with the errors, with the queries
+//     sent until then.
+//   - error: If a query failed, ErrNameNotFound if the name or the target of one of its aliases does
+//     not exist, ErrAliasLoop if the chain loops, ErrAliasChainTooLong if it is too long, or
+//     dns.ErrInvalidDomainName if a DNAME record maps a name to one which is too long.
+//
+// With DNS64, the AAAA records of a target which has none are synthesized from its A records, see SynthesizeAAAA.
+func (resolver *Resolver) QueryChain(name string, qtype uint16) (Chain, error) {
+	return resolver.queryChainContext(context.Background(), name, qtype)
+}
+
+// queryChainContext is QueryChain, whose queries waiting to be sent within MaxQPS give up once the context is done.
+func (resolver *Resolver) queryChainContext(ctx context.Context, name string, qtype uint16) (Chain, error) {
+	chain, err := resolver.queryChain(ctx, name, qtype)
+	if err == nil && qtype == dns.AAAA && resolver.DNS64 {
+		return resolver.synthesizeAAAA(ctx, chain)
+	}
+	return chain, err
+}
+
+func (resolver *Resolver) queryChain(ctx context.Context, name string, qtype uint16) (Chain, error) {
+	answered, response, queries, err := resolver.query(ctx, name, qtype)
+	if err != nil {
+		return Chain{Queries: queries}, err
+	}
+
+	maxLength := resolver.MaxChainLength
+	if maxLength <= 0 {
+		maxLength = DefaultMaxChainLength
+	}
+
+	chain := Chain{Name: answered, Target: answered, Queries: queries}
+	followed := map[string]bool{dns.CanonicalName(answered): true}
+	for length := 0; ; length++ {
+		chain.Glue = append(chain.Glue, getGlue(response.Additionals)...)
+		chain.Answers = getOwnedRecords(response.Answers, chain.Target, qtype)
+		if len(chain.Answers) > 0 {
+			return chain, nil
+		}
+
+		aliases, target, err := getAlias(response.Answers, chain.Target, qtype)
+		if err != nil {
+			return chain, err
+		}
+		if target == "" {
+			// The target has no records of the type
+			return chain, nil
+		}
+		chain.Aliases = append(chain.Aliases, aliases...)
+
+		if length == maxLength {
+			return chain, fmt.Errorf("%w: %s", ErrAliasChainTooLong, chain)
+		}
+		if followed[dns.CanonicalName(target)] {
+			return chain, fmt.Errorf("%w: %s -> %s", ErrAliasLoop, chain, target)
+		}
+		followed[dns.CanonicalName(target)] = true
+		chain.Target = target
+
+		if !hasOwner(response.Answers, target) {
+			// The server stopped at the alias
+			var info QueryInfo
+			response, info, err = resolver.exchange(ctx, target, qtype)
+			chain.Queries = append(chain.Queries, info)
+			if err != nil {
+				return chain, err
+			}
+		}
+	}
+}
+
+// getOwnedRecords returns the records of a type of a name.
+func getOwnedRecords(records []dns.ResourceRecord, name string, qtype uint16) (owned []dns.ResourceRecord) {
+	for _, record := range records {
+		if record.RType == qtype && dns.EqualNames(record.Name, name) {
+			owned = append(owned, record)
+		}
+	}
+	return owned
+}
+
+// getGlue returns the A and AAAA records among records.
+func getGlue(records []dns.ResourceRecord) (glue []dns.ResourceRecord) {
+	for _, record := range records {
+		if record.RType == dns.A || record.RType == dns.AAAA {
+			glue = append(glue, record)
+		}
+	}
+	return glue
+}
+
+// getAlias returns the alias record of a name among records, and the name it points to: its CNAME
+// record, or the DNAME record of one of its parents along with the CNAME record synthesized from it
+// if there is one. The target is empty if the name is not an alias.
+func getAlias(records []dns.ResourceRecord, name string, qtype uint16) (aliases []dns.ResourceRecord, target string, err error) {
+	if qtype != dns.DNAME {
+		for _, record := range records {
+			rdata, ok := record.RData.(*dns.RDataDNAME)
+			if !ok || record.RType != dns.DNAME || dns.EqualNames(record.Name, name) || !dns.IsSubdomain(name, record.Name) {
+				continue
+			}
+			target, err = substituteDNAME(name, record.Name, rdata.Target)
+			if err != nil {
+				return nil, "", err
+			}
+			aliases = append(aliases, record)
+			break
+		}
+	}
+	if qtype == dns.CNAME {
+		return aliases, target, nil
+	}
+
+	for _, record := range records {
+		if rdata, ok := record.RData.(*dns.RDataCNAME); ok && record.RType == dns.CNAME && dns.EqualNames(record.Name, name) {
+			return append(aliases, record), rdata.DomainName, nil
+		}
+	}
+	return aliases, target, nil
+}
+
+// substituteDNAME returns the name a DNAME record maps a name under its owner to,
+// by replacing the owner with the target [RFC6672].
+//
+// Returns:
+//   - string: The name under the target, ex. "www.example.net." for "www.example.com."
+//     with a DNAME record from "example.com." to "example.net.".
+//   - error: dns.ErrInvalidDomainName if the name is too long, which servers answer with YXDOMAIN.
+func substituteDNAME(name string, owner string, target string) (string, error) {
+	prefix := dns.SplitLabels(name)
+	prefix = prefix[:len(prefix)-dns.CountLabels(owner)]
+
+	substituted := dns.Fqdn(strings.Join(append(prefix, strings.TrimSuffix(target, ".")), "."))
+	if len(substituted) > maxNameLength {
+		return "", fmt.Errorf("%w: %s: DNAME substitution to %s is too long", dns.ErrInvalidDomainName, name, target)
+	}
+	return substituted, nil
+}
+
+// hasOwner reports whether a name owns records among records, or is under the owner of a DNAME record.
+func hasOwner(records []dns.ResourceRecord, name string) bool {
+	for _, record := range records {
+		if dns.EqualNames(record.Name, name) || (record.RType == dns.DNAME && dns.IsSubdomain(name, record.Name)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package resolver
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestQueryChain(t *testing.T) {
+	newRecord := func(name string, rtype uint16, rdata dns.RData) dns.ResourceRecord {
+		return dns.ResourceRecord{Name: name, RType: rtype, RClass: dns.IN, TTL: 300, RData: rdata}
+	}
+	resolver := startTestServer(t, []dns.ResourceRecord{
+		newRecord("example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")}),
+		newRecord("www.example.com.", dns.CNAME, &dns.RDataCNAME{DomainName: "example.com."}),
+		newRecord("alias.example.com.", dns.CNAME, &dns.RDataCNAME{DomainName: "www.example.com."}),
+		newRecord("old.example.", dns.DNAME, &dns.RDataDNAME{Target: "example.com."}),
+		newRecord("loop1.example.com.", dns.CNAME, &dns.RDataCNAME{DomainName: "loop2.example.com."}),
+		newRecord("loop2.example.com.", dns.CNAME, &dns.RDataCNAME{DomainName: "LOOP1.example.com."}),
+		newRecord("dangling.example.com.", dns.CNAME, &dns.RDataCNAME{DomainName: "missing.example.com."}),
+		newRecord("long.example.", dns.DNAME, &dns.RDataDNAME{Target: strings.Repeat("a", 63) + "." + strings.Repeat("b", 63) + "." + strings.Repeat("c", 63) + "."}),
+	})
+
+	tests := []struct {
+		name           string
+		data           string
+		qtype          uint16
+		maxChainLength int
+		want           string
+		wantTarget     string
+		wantAnswers    int
+		wantError      error
+	}{
+		{
+			name:        "No alias",
+			data:        "example.com.",
+			qtype:       dns.A,
+			want:        "example.com.",
+			wantTarget:  "example.com.",
+			wantAnswers: 1,
+		},
+		{
+			name:        "CNAME chain",
+			data:        "alias.example.com.",
+			qtype:       dns.A,
+			want:        "alias.example.com. -> www.example.com. -> example.com.",
+			wantTarget:  "example.com.",
+			wantAnswers: 1,
+		},
+		{
+			name:        "DNAME",
+			data:        "www.old.example.",
+			qtype:       dns.A,
+			want:        "www.old.example. -> www.example.com. -> example.com.",
+			wantTarget:  "example.com.",
+			wantAnswers: 1,
+		},
+		{
+			name:        "CNAME query",
+			data:        "alias.example.com.",
+			qtype:       dns.CNAME,
+			want:        "alias.example.com.",
+			wantTarget:  "alias.example.com.",
+			wantAnswers: 1,
+		},
+		{
+			name:       "No records of the type",
+			data:       "www.example.com.",
+			qtype:      dns.MX,
+			want:       "www.example.com. -> example.com.",
+			wantTarget: "example.com.",
+		},
+		{
+			name:       "Loop",
+			data:       "loop1.example.com.",
+			qtype:      dns.A,
+			want:       "loop1.example.com. -> loop2.example.com. -> LOOP1.example.com.",
+			wantTarget: "loop2.example.com.",
+			wantError:  ErrAliasLoop,
+		},
+		{
+			name:           "Too long",
+			data:           "alias.example.com.",
+			qtype:          dns.A,
+			maxChainLength: 1,
+			want:           "alias.example.com. -> www.example.com. -> example.com.",
+			wantTarget:     "www.example.com.",
+			wantError:      ErrAliasChainTooLong,
+		},
+		{
+			name:       "Dangling CNAME",
+			data:       "dangling.example.com.",
+			qtype:      dns.A,
+			want:       "dangling.example.com. -> missing.example.com.",
+			wantTarget: "missing.example.com.",
+			wantError:  ErrNameNotFound,
+		},
+		{
+			name:       "DNAME substitution too long",
+			data:       strings.Repeat("d", 63) + ".long.example.",
+			qtype:      dns.A,
+			want:       strings.Repeat("d", 63) + ".long.example.",
+			wantTarget: strings.Repeat("d", 63) + ".long.example.",
+			wantError:  dns.ErrInvalidDomainName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver.MaxChainLength = tt.maxChainLength
+			chain, err := resolver.QueryChain(tt.data, tt.qtype)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("QueryChain() error = %v, want error = %v\n", err, tt.wantError)
+			}
+			if got := chain.String(); got != tt.want {
+				t.Errorf("QueryChain() got = %s, want = %s\n", got, tt.want)
+			}
+			if chain.Target != tt.wantTarget || len(chain.Answers) != tt.wantAnswers {
+				t.Errorf("QueryChain() got target = %s, answers = %v, want target = %s, %d answers\n", chain.Target, chain.Answers, tt.wantTarget, tt.wantAnswers)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// DNS64 [RFC6147] lets IPv6-only hosts reach IPv4-only servers through a NAT64 gateway: the names
+// which have A records but no AAAA records are given AAAA records synthesized from their A records,
+// the IPv4 address embedded in the NAT64 prefix of the gateway [RFC6052]. The names which have
+// AAAA records keep them, apart from IPv4-mapped addresses, which are not reachable over IPv6 and
+// are ignored. The addresses of the well-known prefix are not synthesized for private IPv4
+// addresses, which it must not be used for.
+//
+// The synthesized records have the TTL of the A records they are made from.
+
+var ErrInvalidNAT64Prefix = fmt.Errorf("invalid NAT64 prefix")
+
+// DefaultNAT64Prefix is the well-known prefix of IPv4-embedded IPv6 addresses [RFC6052].
+var DefaultNAT64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+
+// ipv4MappedPrefix is the prefix of the IPv4-mapped IPv6 addresses, excluded from the AAAA answers [RFC6147].
+var ipv4MappedPrefix = netip.MustParsePrefix("::ffff:0:0/96")
+
+// SynthesizeAAAA returns the IPv4-embedded IPv6 address of an IPv4 address in a NAT64 prefix [RFC6052],
+// ex. "64:ff9b::c000:221" for "192.0.2.33" in the well-known prefix.
+//
+// Parameters:
+//   - prefix: The NAT64 prefix, of length 32, 40, 48, 56, 64 or 96, with bits 64 to 71 set to zero.
+//   - ipv4: The IPv4 address.
+//
+// Returns:
+//   - netip.Addr: The IPv6 address: the IPv4 address after the prefix, skipping bits 64 to 71.
+//   - error: ErrInvalidNAT64Prefix if the prefix is not valid, or dns.ErrInvalidIP if the address is not IPv4.
+func SynthesizeAAAA(prefix netip.Prefix, ipv4 netip.Addr) (netip.Addr, error) {
+	prefix = prefix.Masked()
+	address := prefix.Addr().As16()
+	switch prefix.Bits() {
+	case 32, 40, 48, 56, 64, 96:
+	default:
+		return netip.Addr{}, fmt.Errorf("%w: %s: length must be 32, 40, 48, 56, 64 or 96", ErrInvalidNAT64Prefix, prefix)
+	}
+	if !prefix.Addr().Is6() || prefix.Addr().Is4In6() || address[8] != 0 {
+		return netip.Addr{}, fmt.Errorf("%w: %s", ErrInvalidNAT64Prefix, prefix)
+	}
+	if !ipv4.Unmap().Is4() {
+		return netip.Addr{}, fmt.Errorf("%w: %s is not an IPv4 address", dns.ErrInvalidIP, ipv4)
+	}
+
+	i := prefix.Bits() / 8
+	for _, b := range ipv4.Unmap().As4() {
+		if i == 8 {
+			// Bits 64 to 71 are reserved
+			i++
+		}
+		address[i] = b
+		i++
+	}
+	return netip.AddrFrom16(address), nil
+}
+
+// synthesizeAAAA gives a chain without AAAA answers, apart from IPv4-mapped ones which are left out,
+// the AAAA records synthesized from the A records of its target. The chain is returned without
+// answers if the A query gives no records, and with the error of the A query if it fails.
+func (resolver *Resolver) synthesizeAAAA(ctx context.Context, chain Chain) (Chain, error) {
+	var answers []dns.ResourceRecord
+	for _, record := range chain.Answers {
+		if rdata, ok := record.RData.(*dns.RDataAAAA); !ok || !ipv4MappedPrefix.Contains(rdata.IP) {
+			answers = append(answers, record)
+		}
+	}
+	chain.Answers = answers
+	if len(answers) > 0 {
+		return chain, nil
+	}
+
+	prefix := resolver.NAT64Prefix
+	if !prefix.IsValid() {
+		prefix = DefaultNAT64Prefix
+	}
+	ipv4Chain, err := resolver.queryChain(ctx, chain.Target, dns.A)
+	chain.Queries = append(chain.Queries, ipv4Chain.Queries...)
+	if err != nil {
+		return chain, err
+	}
+
+	for _, record := range ipv4Chain.Answers {
+		rdata, ok := record.RData.(*dns.RDataA)
+		if !ok {
+			continue
+		}
+		if prefix == DefaultNAT64Prefix && (!rdata.IP.IsGlobalUnicast() || rdata.IP.IsPrivate()) {
+			continue
+		}
+		ipv6, err := SynthesizeAAAA(prefix, rdata.IP)
+		if err != nil {
+			return Chain{}, err
+		}
+		answers = append(answers, dns.ResourceRecord{
+			Name:     chain.Target,
+			RType:    dns.AAAA,
+			RClass:   record.RClass,
+			TTL:      record.TTL,
+			RDLength: 16,
+			RData:    &dns.RDataAAAA{IP: ipv6},
+		})
+	}
+	chain.Answers = answers
+	return chain, nil
+}
@@ -0,0 +1,105 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestSynthesizeAAAA(t *testing.T) {
+	// Examples of RFC 6052 section 2.4
+	tests := []struct {
+		name      string
+		prefix    string
+		want      string
+		wantError error
+	}{
+		{name: "Well-known prefix", prefix: "64:ff9b::/96", want: "64:ff9b::c000:221"},
+		{name: "/32", prefix: "2001:db8::/32", want: "2001:db8:c000:221::"},
+		{name: "/40", prefix: "2001:db8:100::/40", want: "2001:db8:1c0:2:21::"},
+		{name: "/48", prefix: "2001:db8:122::/48", want: "2001:db8:122:c000:2:2100::"},
+		{name: "/56", prefix: "2001:db8:122:300::/56", want: "2001:db8:122:3c0:0:221::"},
+		{name: "/64", prefix: "2001:db8:122:344::/64", want: "2001:db8:122:344:c0:2:2100:0"},
+		{name: "/96", prefix: "2001:db8:122:344::/96", want: "2001:db8:122:344::c000:221"},
+		{name: "Invalid length", prefix: "2001:db8::/33", wantError: ErrInvalidNAT64Prefix},
+		{name: "Reserved bits set", prefix: "2001:db8:0:0:ff00::/96", wantError: ErrInvalidNAT64Prefix},
+		{name: "IPv4 prefix", prefix: "192.0.2.0/32", wantError: ErrInvalidNAT64Prefix},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SynthesizeAAAA(netip.MustParsePrefix(tt.prefix), netip.MustParseAddr("192.0.2.33"))
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("SynthesizeAAAA() error = %v, want error = %v\n", err, tt.wantError)
+			}
+			if tt.wantError == nil && got != netip.MustParseAddr(tt.want) {
+				t.Errorf("SynthesizeAAAA() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := SynthesizeAAAA(DefaultNAT64Prefix, netip.MustParseAddr("2001:db8::1")); !errors.Is(err, dns.ErrInvalidIP) {
+		t.Errorf("SynthesizeAAAA() error = %v, want error = %v\n", err, dns.ErrInvalidIP)
+	}
+}
+
+func TestResolverDNS64(t *testing.T) {
+	newRecord := func(name string, rtype uint16, rdata dns.RData) dns.ResourceRecord {
+		return dns.ResourceRecord{Name: name, RType: rtype, RClass: dns.IN, TTL: 300, RData: rdata}
+	}
+	resolver := startTestServer(t, []dns.ResourceRecord{
+		newRecord("ipv4.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.33")}),
+		newRecord("www.example.com.", dns.CNAME, &dns.RDataCNAME{DomainName: "ipv4.example.com."}),
+		newRecord("dual.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")}),
+		newRecord("dual.example.com.", dns.AAAA, &dns.RDataAAAA{IP: netip.MustParseAddr("2001:db8::1")}),
+		newRecord("mapped.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.2")}),
+		newRecord("mapped.example.com.", dns.AAAA, &dns.RDataAAAA{IP: netip.MustParseAddr("::ffff:192.0.2.2")}),
+		newRecord("private.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("10.0.0.1")}),
+		newRecord("none.example.com.", dns.TXT, &dns.RDataTXT{Text: []string{"no addresses"}}),
+	})
+	resolver.DNS64 = true
+
+	tests := []struct {
+		name   string
+		data   string
+		prefix netip.Prefix
+		want   []netip.Addr
+	}{
+		{name: "Synthesized", data: "ipv4.example.com.", want: []netip.Addr{netip.MustParseAddr("64:ff9b::192.0.2.33")}},
+		{name: "Through a CNAME record", data: "www.example.com.", want: []netip.Addr{netip.MustParseAddr("64:ff9b::192.0.2.33")}},
+		{name: "AAAA records", data: "dual.example.com.", want: []netip.Addr{netip.MustParseAddr("2001:db8::1")}},
+		{name: "IPv4-mapped AAAA records", data: "mapped.example.com.", want: []netip.Addr{netip.MustParseAddr("64:ff9b::192.0.2.2")}},
+		{name: "Private address with the well-known prefix", data: "private.example.com."},
+		{
+			name:   "Private address with a network-specific prefix",
+			data:   "private.example.com.",
+			prefix: netip.MustParsePrefix("2001:db8:64::/96"),
+			want:   []netip.Addr{netip.MustParseAddr("2001:db8:64::10.0.0.1")},
+		},
+		{name: "No A records", data: "none.example.com."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver.NAT64Prefix = tt.prefix
+			got, err := resolver.LookupAAAA(tt.data)
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LookupAAAA() got = %v, error = %v, want = %v\n", got, err, tt.want)
+			}
+		})
+	}
+
+	chain, err := resolver.QueryChain("www.example.com.", dns.AAAA)
+	if err != nil || len(chain.Answers) != 1 || chain.Answers[0].Name != "ipv4.example.com." || chain.Answers[0].TTL != 300 {
+		t.Errorf("QueryChain() got = %v, error = %v\n", chain.Answers, err)
+	}
+
+	// The A query fails: its error is returned rather than an empty answer
+	if _, err = resolver.synthesizeAAAA(context.Background(), Chain{Name: "missing.example.com.", Target: "missing.example.com."}); !errors.Is(err, ErrNameNotFound) {
+		t.Errorf("synthesizeAAAA() error = %v, want error = %v\n", err, ErrNameNotFound)
+	}
+}
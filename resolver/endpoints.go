@@ -0,0 +1,149 @@
+package resolver
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mcombeau/dns-tools/client"
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// Endpoints of mail exchanges and services: the targets of MX and SRV records, in the order they
+// should be tried, with their addresses and ports, so that they can be connected to at once.
+//
+//   - The mail exchanges are sorted by preference [RFC5321]. A domain without MX records is its own
+//     mail exchange, and a domain with a single MX record whose exchange is "." accepts no mail [RFC7505].
+//   - The targets of SRV records are sorted by priority, then in a random order weighted by their
+//     weights [RFC2782]. A single record whose target is "." says the service is not available.
+//
+// The addresses of a target come from the A and AAAA records of the additional sections of the
+// responses when the server gave some, and are looked up otherwise. They are in connection
+// attempt order, see LookupDialAddrs.
+
+var ErrNoMailService = fmt.Errorf("domain does not accept mail")
+
+// SMTPPort is the port of the endpoints of mail exchanges [RFC5321].
+const SMTPPort = 25
+
+// Endpoint is the target of an MX or SRV record with its addresses.
+type Endpoint struct {
+	Target    string       // Host name of the target, ex. "mail.example.com."
+	Port      uint16       // Port of the service, SMTPPort for mail exchanges
+	Priority  uint16       // Preference of an MX record or priority of an SRV record, the lowest first
+	Weight    uint16       // Weight of an SRV record, 0 for mail exchanges
+	Addresses []netip.Addr // Addresses of the target, in connection attempt order, none if it has none
+}
+
+// DialAddrs returns the addresses of the endpoint with its port, ex. "[2001:db8::25]:25", for net.Dial.
+func (endpoint Endpoint) DialAddrs() []string {
+	addresses := make([]string, 0, len(endpoint.Addresses))
+	for _, address := range endpoint.Addresses {
+		addresses = append(addresses, net.JoinHostPort(address.String(), strconv.Itoa(int(endpoint.Port))))
+	}
+	return addresses
+}
+
+// LookupMXEndpoints returns the mail exchanges of a domain with their addresses, most preferred first.
+//
+// Parameters:
+//   - name: The mail domain, ex. "example.com.".
+//
+// Returns:
+//   - []Endpoint: The mail exchanges, the domain itself if it has no MX records.
+//   - error: If the MX query failed, ErrNameNotFound if the domain does not exist,
+//     or ErrNoMailService if it has a null MX record.
+func (resolver *Resolver) LookupMXEndpoints(name string) ([]Endpoint, error) {
+	chain, err := resolver.QueryChain(name, dns.MX)
+	if err != nil {
+		return nil, err
+	}
+
+	var exchanges []dns.RDataMX
+	for _, record := range chain.Answers {
+		if rdata, ok := record.RData.(*dns.RDataMX); ok {
+			exchanges = append(exchanges, *rdata)
+		}
+	}
+	if len(exchanges) == 0 {
+		// Implicit MX
+		exchanges = []dns.RDataMX{{Exchange: chain.Target}}
+	}
+	if len(exchanges) == 1 && exchanges[0].Exchange == "." {
+		return nil, fmt.Errorf("%w: %s", ErrNoMailService, strings.TrimSuffix(chain.Name, "."))
+	}
+	slices.SortStableFunc(exchanges, func(a dns.RDataMX, b dns.RDataMX) int {
+		return int(a.Preference) - int(b.Preference)
+	})
+
+	endpoints := make([]Endpoint, 0, len(exchanges))
+	for _, exchange := range exchanges {
+		endpoints = append(endpoints, resolver.getEndpoint(exchange.Exchange, SMTPPort, exchange.Preference, 0, chain.Glue))
+	}
+	return endpoints, nil
+}
+
+// LookupSRVEndpoints returns the targets of the SRV records of a service with their addresses,
+// in the order they should be tried.
+//
+// Parameters:
+//   - service: The symbolic name of the service, ex. "sip".
+//   - proto: The protocol of the service, ex. "tcp".
+//   - name: The domain the service is provided for, ex. "example.com.".
+//
+// Returns:
+//   - []Endpoint: The targets of the service, none if it has no SRV records.
+//   - error: If the SRV query failed, ErrNameNotFound if the service does not exist,
+//     or client.ErrServiceUnavailable if the domain says the service is not available.
+func (resolver *Resolver) LookupSRVEndpoints(service string, proto string, name string) ([]Endpoint, error) {
+	srvName := dns.GetSRVName(service, proto, name)
+	chain, err := resolver.QueryChain(srvName, dns.SRV)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []dns.RDataSRV
+	for _, record := range chain.Answers {
+		if rdata, ok := record.RData.(*dns.RDataSRV); ok {
+			records = append(records, *rdata)
+		}
+	}
+	if dns.IsServiceUnavailable(records) {
+		return nil, fmt.Errorf("%w: %s", client.ErrServiceUnavailable, strings.TrimSuffix(srvName, "."))
+	}
+
+	endpoints := make([]Endpoint, 0, len(records))
+	for _, record := range dns.SortSRV(records) {
+		endpoints = append(endpoints, resolver.getEndpoint(record.Target, record.Port, record.Priority, record.Weight, chain.Glue))
+	}
+	return endpoints, nil
+}
+
+// getEndpoint returns the endpoint of a target, with its addresses among the glue records,
+// or else looked up. The lookup errors leave the endpoint without the addresses of their type.
+func (resolver *Resolver) getEndpoint(target string, port uint16, priority uint16, weight uint16, glue []dns.ResourceRecord) Endpoint {
+	endpoint := Endpoint{Target: target, Port: port, Priority: priority, Weight: weight}
+
+	var addresses []netip.Addr
+	for _, record := range glue {
+		if !dns.EqualNames(record.Name, target) {
+			continue
+		}
+		switch rdata := record.RData.(type) {
+		case *dns.RDataA:
+			addresses = append(addresses, rdata.IP)
+		case *dns.RDataAAAA:
+			addresses = append(addresses, rdata.IP)
+		}
+	}
+	if len(addresses) == 0 {
+		ipv6, _ := resolver.LookupAAAA(target)
+		ipv4, _ := resolver.LookupA(target)
+		addresses = append(ipv6, ipv4...)
+	}
+	endpoint.Addresses = sortDialAddrs(addresses)
+	return endpoint
+}
@@ -0,0 +1,84 @@
+package resolver
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/mcombeau/dns-tools/client"
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestLookupEndpoints(t *testing.T) {
+	newRecord := func(name string, rtype uint16, rdata dns.RData) dns.ResourceRecord {
+		return dns.ResourceRecord{Name: name, RType: rtype, RClass: dns.IN, TTL: 300, RData: rdata}
+	}
+	resolver := startTestServer(t, []dns.ResourceRecord{
+		newRecord("example.com.", dns.MX, &dns.RDataMX{Preference: 20, Exchange: "mx2.example.com."}),
+		newRecord("example.com.", dns.MX, &dns.RDataMX{Preference: 10, Exchange: "mx1.example.com."}),
+		newRecord("mx1.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.25")}),
+		newRecord("mx1.example.com.", dns.AAAA, &dns.RDataAAAA{IP: netip.MustParseAddr("2001:db8::25")}),
+		newRecord("mx2.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.26")}),
+		newRecord("implicit.example.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.27")}),
+		newRecord("null.example.", dns.MX, &dns.RDataMX{Preference: 0, Exchange: "."}),
+		newRecord("_sip._tcp.example.com.", dns.SRV, &dns.RDataSRV{Priority: 20, Weight: 0, Port: 5061, Target: "sip2.example.com."}),
+		newRecord("_sip._tcp.example.com.", dns.SRV, &dns.RDataSRV{Priority: 10, Weight: 0, Port: 5060, Target: "sip1.example.com."}),
+		newRecord("sip1.example.com.", dns.AAAA, &dns.RDataAAAA{IP: netip.MustParseAddr("2001:db8::5060")}),
+		newRecord("_xmpp._tcp.example.com.", dns.SRV, &dns.RDataSRV{Target: "."}),
+	})
+
+	mx, err := resolver.LookupMXEndpoints("example.com.")
+	want := []Endpoint{
+		{Target: "mx1.example.com.", Port: SMTPPort, Priority: 10, Addresses: []netip.Addr{netip.MustParseAddr("2001:db8::25"), netip.MustParseAddr("192.0.2.25")}},
+		{Target: "mx2.example.com.", Port: SMTPPort, Priority: 20, Addresses: []netip.Addr{netip.MustParseAddr("192.0.2.26")}},
+	}
+	if err != nil || !reflect.DeepEqual(mx, want) {
+		t.Errorf("LookupMXEndpoints() got = %+v, error = %v, want = %+v\n", mx, err, want)
+	}
+	if got, want := mx[0].DialAddrs(), []string{"[2001:db8::25]:25", "192.0.2.25:25"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DialAddrs() got = %v, want = %v\n", got, want)
+	}
+
+	// A domain without MX records is its own mail exchange
+	mx, err = resolver.LookupMXEndpoints("implicit.example.")
+	want = []Endpoint{{Target: "implicit.example.", Port: SMTPPort, Addresses: []netip.Addr{netip.MustParseAddr("192.0.2.27")}}}
+	if err != nil || !reflect.DeepEqual(mx, want) {
+		t.Errorf("LookupMXEndpoints() implicit MX got = %+v, error = %v, want = %+v\n", mx, err, want)
+	}
+
+	if _, err = resolver.LookupMXEndpoints("null.example."); !errors.Is(err, ErrNoMailService) {
+		t.Errorf("LookupMXEndpoints() null MX error = %v, want error = %v\n", err, ErrNoMailService)
+	}
+	if _, err = resolver.LookupMXEndpoints("missing.example."); !errors.Is(err, ErrNameNotFound) {
+		t.Errorf("LookupMXEndpoints() missing domain error = %v, want error = %v\n", err, ErrNameNotFound)
+	}
+
+	srv, err := resolver.LookupSRVEndpoints("sip", "tcp", "example.com.")
+	wantSRV := []Endpoint{
+		{Target: "sip1.example.com.", Port: 5060, Priority: 10, Addresses: []netip.Addr{netip.MustParseAddr("2001:db8::5060")}},
+		{Target: "sip2.example.com.", Port: 5061, Priority: 20, Addresses: []netip.Addr{}},
+	}
+	if err != nil || !reflect.DeepEqual(srv, wantSRV) {
+		t.Errorf("LookupSRVEndpoints() got = %+v, error = %v, want = %+v\n", srv, err, wantSRV)
+	}
+	if _, err = resolver.LookupSRVEndpoints("xmpp", "tcp", "example.com."); !errors.Is(err, client.ErrServiceUnavailable) {
+		t.Errorf("LookupSRVEndpoints() error = %v, want error = %v\n", err, client.ErrServiceUnavailable)
+	}
+}
+
+func TestGetEndpointGlue(t *testing.T) {
+	// Nothing answers: the addresses can only come from the glue
+	resolver := NewResolver("127.0.0.1:1")
+	glue := []dns.ResourceRecord{
+		{Name: "MX1.example.com.", RType: dns.A, RClass: dns.IN, TTL: 300, RData: &dns.RDataA{IP: netip.MustParseAddr("192.0.2.25")}},
+		{Name: "mx1.example.com.", RType: dns.AAAA, RClass: dns.IN, TTL: 300, RData: &dns.RDataAAAA{IP: netip.MustParseAddr("2001:db8::25")}},
+		{Name: "mx2.example.com.", RType: dns.A, RClass: dns.IN, TTL: 300, RData: &dns.RDataA{IP: netip.MustParseAddr("192.0.2.26")}},
+	}
+
+	got := resolver.getEndpoint("mx1.example.com.", SMTPPort, 10, 0, glue)
+	want := []netip.Addr{netip.MustParseAddr("2001:db8::25"), netip.MustParseAddr("192.0.2.25")}
+	if !reflect.DeepEqual(got.Addresses, want) {
+		t.Errorf("getEndpoint() got addresses = %v, want = %v\n", got.Addresses, want)
+	}
+}
@@ -0,0 +1,192 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"slices"
+	"time"
+)
+
+// Happy Eyeballs [RFC8305]: dialers connecting to a host with both IPv4 and IPv6 addresses
+// try them in turn, alternating between the families, so that a broken IPv6 path does not
+// delay the connection for long. LookupDialAddrs gives them the addresses in that order:
+//
+//   - The A and AAAA queries are sent at once. Once one of them answers with addresses, the other
+//     is waited for during the resolution delay at most, so that a slow answer does not hold up the
+//     connection: its addresses are then left out.
+//   - The addresses are sorted by the rules of RFC 6724 destination address selection which do not
+//     depend on the source address: the higher precedence of the default policy table first, then
+//     the smaller scope. The others are left to the routing of the system.
+//   - The families are then interleaved, starting with the family of the first address: IPv6
+//     with the default policy table, unless the only IPv6 addresses are of low precedence ones
+//     like 6to4 or Teredo addresses.
+
+// DefaultResolutionDelay is the time LookupDialAddrs waits for the answer to the second query,
+// once the first one has answered, when the resolver does not set one [RFC8305].
+const DefaultResolutionDelay = 50 * time.Millisecond
+
+// Scopes of addresses [RFC6724]
+const (
+	scopeLinkLocal = 0x2
+	scopeSiteLocal = 0x5
+	scopeGlobal    = 0xe
+)
+
+// siteLocalPrefix is the prefix of the deprecated IPv6 site-local addresses [RFC3879].
+var siteLocalPrefix = netip.MustParsePrefix("fec0::/10")
+
+// addressPolicy is an entry of the policy table of destination address selection.
+type addressPolicy struct {
+	prefix     netip.Prefix
+	precedence int
+}
+
+// defaultPolicyTable is the default policy table [RFC6724], longest prefixes first so that the
+// first prefix which contains an address is its longest match. IPv4 addresses are matched as IPv4-mapped.
+var defaultPolicyTable = []addressPolicy{
+	{netip.MustParsePrefix("::1/128"), 50},
+	{netip.MustParsePrefix("::ffff:0:0/96"), 35},
+	{netip.MustParsePrefix("::/96"), 1},
+	{netip.MustParsePrefix("2001::/32"), 5},
+	{netip.MustParsePrefix("2002::/16"), 30},
+	{netip.MustParsePrefix("3ffe::/16"), 1},
+	{netip.MustParsePrefix("fec0::/10"), 1},
+	{netip.MustParsePrefix("fc00::/7"), 3},
+	{netip.MustParsePrefix("::/0"), 40},
+}
+
+// LookupDialAddrs is the variant of LookupIPAddr for dialers: it returns the addresses of a host
+// in the order they should be tried to connect to it with Happy Eyeballs [RFC8305], racing the
+// A and AAAA queries. An IP address is returned as is.
+//
+// Returns:
+//   - []net.IPAddr: The addresses of the host, in connection attempt order.
+//   - error: If neither query gave addresses, a not found error if the host has none.
+func (resolver *NetResolver) LookupDialAddrs(ctx context.Context, host string) ([]net.IPAddr, error) {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return []net.IPAddr{{IP: ip.AsSlice(), Zone: ip.Zone()}}, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, resolver.getDNSError(host, err)
+	}
+
+	type result struct {
+		addresses []netip.Addr
+		err       error
+	}
+	results := make(chan result, 2)
+	for _, lookup := range []func(string) ([]netip.Addr, error){resolver.Resolver.LookupAAAA, resolver.Resolver.LookupA} {
+		go func() {
+			addresses, err := lookup(host)
+			results <- result{addresses, err}
+		}()
+	}
+
+	delay := resolver.ResolutionDelay
+	if delay <= 0 {
+		delay = DefaultResolutionDelay
+	}
+
+	var addresses []netip.Addr
+	var err error
+	var resolutionDelay <-chan time.Time
+wait:
+	for pending := 2; pending > 0; pending-- {
+		select {
+		case result := <-results:
+			addresses = append(addresses, result.addresses...)
+			if err == nil {
+				err = result.err
+			}
+			if len(addresses) > 0 && resolutionDelay == nil {
+				timer := time.NewTimer(delay)
+				defer timer.Stop()
+				resolutionDelay = timer.C
+			}
+		case <-resolutionDelay:
+			break wait
+		case <-ctx.Done():
+			if len(addresses) > 0 {
+				break wait
+			}
+			return nil, resolver.getDNSError(host, ctx.Err())
+		}
+	}
+
+	if len(addresses) == 0 {
+		if err == nil {
+			err = ErrNameNotFound
+		}
+		return nil, resolver.getDNSError(host, err)
+	}
+
+	dialAddrs := make([]net.IPAddr, 0, len(addresses))
+	for _, address := range sortDialAddrs(addresses) {
+		dialAddrs = append(dialAddrs, net.IPAddr{IP: address.AsSlice(), Zone: address.Zone()})
+	}
+	return dialAddrs, nil
+}
+
+// sortDialAddrs returns addresses in connection attempt order: sorted by precedence and scope,
+// then alternating between the families, starting with the family of the first address.
+func sortDialAddrs(addresses []netip.Addr) []netip.Addr {
+	sorted := make([]netip.Addr, 0, len(addresses))
+	for _, address := range addresses {
+		sorted = append(sorted, address.Unmap())
+	}
+	slices.SortStableFunc(sorted, func(a netip.Addr, b netip.Addr) int {
+		// Rule 6: prefer higher precedence
+		if precedence := getPrecedence(b) - getPrecedence(a); precedence != 0 {
+			return precedence
+		}
+		// Rule 8: prefer smaller scope
+		return getScope(a) - getScope(b)
+	})
+	if len(sorted) == 0 {
+		return sorted
+	}
+
+	var first, second []netip.Addr
+	for _, address := range sorted {
+		if address.Is4() == sorted[0].Is4() {
+			first = append(first, address)
+		} else {
+			second = append(second, address)
+		}
+	}
+	interleaved := make([]netip.Addr, 0, len(sorted))
+	for i := 0; i < max(len(first), len(second)); i++ {
+		if i < len(first) {
+			interleaved = append(interleaved, first[i])
+		}
+		if i < len(second) {
+			interleaved = append(interleaved, second[i])
+		}
+	}
+	return interleaved
+}
+
+// getPrecedence returns the precedence of an address in the default policy table.
+func getPrecedence(address netip.Addr) int {
+	mapped := netip.AddrFrom16(address.As16())
+	for _, policy := range defaultPolicyTable {
+		if policy.prefix.Contains(mapped) {
+			return policy.precedence
+		}
+	}
+	return 0
+}
+
+// getScope returns the scope of a unicast address [RFC6724]: IPv4 loopback and link-local
+// addresses have the link-local scope, like their IPv6 counterparts.
+func getScope(address netip.Addr) int {
+	switch {
+	case address.IsLoopback() || address.IsLinkLocalUnicast():
+		return scopeLinkLocal
+	case siteLocalPrefix.Contains(address.WithZone("")):
+		return scopeSiteLocal
+	default:
+		return scopeGlobal
+	}
+}
@@ -0,0 +1,118 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestSortDialAddrs(t *testing.T) {
+	tests := []struct {
+		name string
+		data []string
+		want []string
+	}{
+		{
+			name: "IPv6 first, interleaved",
+			data: []string{"192.0.2.1", "192.0.2.2", "192.0.2.3", "2001:db8::1", "2001:db8::2"},
+			want: []string{"2001:db8::1", "192.0.2.1", "2001:db8::2", "192.0.2.2", "192.0.2.3"},
+		},
+		{
+			name: "IPv4 before low precedence IPv6",
+			data: []string{"2002:c000:201::1", "2001:0:4136:e378::1", "192.0.2.1"},
+			want: []string{"192.0.2.1", "2002:c000:201::1", "2001:0:4136:e378::1"},
+		},
+		{
+			name: "Smaller scope first",
+			data: []string{"2001:db8::1", "fe80::1%eth0", "::1"},
+			want: []string{"::1", "fe80::1%eth0", "2001:db8::1"},
+		},
+		{
+			name: "IPv4-mapped",
+			data: []string{"::ffff:192.0.2.1", "2001:db8::1"},
+			want: []string{"2001:db8::1", "192.0.2.1"},
+		},
+		{
+			name: "One family",
+			data: []string{"192.0.2.2", "192.0.2.1"},
+			want: []string{"192.0.2.2", "192.0.2.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var addresses []netip.Addr
+			for _, address := range tt.data {
+				addresses = append(addresses, netip.MustParseAddr(address))
+			}
+			var got []string
+			for _, address := range sortDialAddrs(addresses) {
+				got = append(got, address.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortDialAddrs() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupDialAddrs(t *testing.T) {
+	newRecord := func(name string, rtype uint16, rdata dns.RData) dns.ResourceRecord {
+		return dns.ResourceRecord{Name: name, RType: rtype, RClass: dns.IN, TTL: 300, RData: rdata}
+	}
+	resolver := startTestServer(t, []dns.ResourceRecord{
+		newRecord("example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")}),
+		newRecord("example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.2")}),
+		newRecord("example.com.", dns.AAAA, &dns.RDataAAAA{IP: netip.MustParseAddr("2001:db8::1")}),
+		newRecord("ipv4.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.3")}),
+	})
+	netResolver := resolver.Net()
+	// Both answers are expected: a slow test machine should not leave one out
+	netResolver.ResolutionDelay = 5 * time.Second
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		data         string
+		want         []string
+		wantNotFound bool
+	}{
+		{name: "Both families", data: "example.com", want: []string{"2001:db8::1", "192.0.2.1", "192.0.2.2"}},
+		{name: "IPv4 only", data: "ipv4.example.com", want: []string{"192.0.2.3"}},
+		{name: "IP address", data: "2001:db8::2", want: []string{"2001:db8::2"}},
+		{name: "Not found", data: "missing.example.com", wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addresses, err := netResolver.LookupDialAddrs(ctx, tt.data)
+			var dnsErr *net.DNSError
+			if tt.wantNotFound {
+				if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+					t.Errorf("LookupDialAddrs() error = %v, want a not found error\n", err)
+				}
+				return
+			}
+			var got []string
+			for _, address := range addresses {
+				got = append(got, address.String())
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LookupDialAddrs() got = %v, error = %v, want = %v\n", got, err, tt.want)
+			}
+		})
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	var dnsErr *net.DNSError
+	if _, err := netResolver.LookupDialAddrs(cancelled, "example.com"); !errors.As(err, &dnsErr) || dnsErr.Err != context.Canceled.Error() {
+		t.Errorf("LookupDialAddrs() with a cancelled context error = %v, want = %v\n", err, context.Canceled)
+	}
+}
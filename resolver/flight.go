@@ -0,0 +1,56 @@
+package resolver
+
+import (
+	"sync"
+
+	"github.com/mcombeau/dns-tools/client"
+)
+
+// flightGroup collapses the identical queries in flight into one: the goroutines which send a query
+// while the same query is waiting for its response wait for that response too, instead of sending
+// their own. Many lookups of the same name at once, ex. a bulk lookup of a list with duplicates
+// or the cache entry of a popular name expiring, then send a single query upstream.
+type flightGroup struct {
+	mutex   sync.Mutex
+	flights map[CacheKey]*flight
+}
+
+// flight is a query in flight, whose response is shared by all the goroutines waiting for it.
+type flight struct {
+	done     chan struct{} // Closed once the response is received
+	response client.Response
+	err      error
+}
+
+// do sends a query with the exchange function, unless the same query is already in flight,
+// in which case it waits for its response. The response is shared and must not be modified.
+//
+// Parameters:
+//   - key: The query, ex. its name, type and class.
+//   - exchange: The function which sends the query and returns its response.
+//
+// Returns:
+//   - client.Response: The response to the query.
+//   - error: The error of the exchange function.
+func (group *flightGroup) do(key CacheKey, exchange func() (client.Response, error)) (client.Response, error) {
+	group.mutex.Lock()
+	if current, ok := group.flights[key]; ok {
+		group.mutex.Unlock()
+		<-current.done
+		return current.response, current.err
+	}
+	if group.flights == nil {
+		group.flights = map[CacheKey]*flight{}
+	}
+	current := &flight{done: make(chan struct{})}
+	group.flights[key] = current
+	group.mutex.Unlock()
+
+	current.response, current.err = exchange()
+
+	group.mutex.Lock()
+	delete(group.flights, key)
+	group.mutex.Unlock()
+	close(current.done)
+	return current.response, current.err
+}
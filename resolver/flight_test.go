@@ -0,0 +1,81 @@
+package resolver
+
+import (
+	"net"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestFlightGroup(t *testing.T) {
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen UDP: %v", err)
+	}
+	t.Cleanup(func() { packetConn.Close() })
+
+	// The server answers each query after a while, so that the lookups started at once are in flight together
+	var queries atomic.Int32
+	go func() {
+		buffer := make([]byte, dns.MaxDNSMessageSize)
+		for {
+			n, addr, err := packetConn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			queries.Add(1)
+			query, err := dns.DecodeMessage(buffer[:n])
+			if err != nil {
+				t.Errorf("test server: invalid query: %v", err)
+				continue
+			}
+			go func() {
+				time.Sleep(200 * time.Millisecond)
+				response := dns.NewResponse(&query).WithRecursionAvailable().Answer(dns.ResourceRecord{
+					Name: "example.com.", RType: dns.A, RClass: dns.IN, TTL: 300, RData: &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")},
+				})
+				data, err := response.Pack()
+				if err != nil {
+					t.Errorf("test server: encode response: %v", err)
+					return
+				}
+				packetConn.WriteTo(data, addr)
+			}()
+		}
+	}()
+	resolver := NewResolver(packetConn.LocalAddr().String())
+
+	const lookups = 10
+	var done sync.WaitGroup
+	done.Add(lookups)
+	addresses := make([][]netip.Addr, lookups)
+	errs := make([]error, lookups)
+	for i := 0; i < lookups; i++ {
+		go func() {
+			defer done.Done()
+			addresses[i], errs[i] = resolver.LookupA("example.com.")
+		}()
+	}
+	done.Wait()
+
+	if got := queries.Load(); got != 1 {
+		t.Fatalf("LookupA() at once got = %d queries, want = 1\n", got)
+	}
+	for i := range addresses {
+		if errs[i] != nil || len(addresses[i]) != 1 || addresses[i][0] != netip.MustParseAddr("192.0.2.1") {
+			t.Errorf("LookupA() lookup %d got = %v, error = %v, want the shared response\n", i, addresses[i], errs[i])
+		}
+	}
+
+	// The flight is over: the next query is sent again
+	if _, err := resolver.LookupA("example.com."); err != nil || queries.Load() != 2 {
+		t.Errorf("LookupA() after the flight error = %v, got = %d queries, want = 2\n", err, queries.Load())
+	}
+	if len(resolver.flights.flights) != 0 {
+		t.Errorf("LookupA() got %d flights left, want = 0\n", len(resolver.flights.flights))
+	}
+}
@@ -0,0 +1,127 @@
+package resolver
+
+import (
+	"bufio"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// Hosts file, see hosts(5): one IP address per line followed by its canonical name and aliases,
+// with "#" starting a comment until the end of the line:
+//
+//	127.0.0.1   localhost
+//	192.0.2.10  server.example.com server
+//
+// A Resolver looks up the addresses of a name and the names of an address in its hosts file
+// before sending queries, like the "files" source before "dns" in nsswitch.conf(5):
+// a name in the hosts file is only looked up there.
+
+// DefaultHostsPath is the path of the hosts file on Unix systems.
+const DefaultHostsPath = "/etc/hosts"
+
+// Hosts holds the entries of a hosts file, read again when the file is modified.
+type Hosts struct {
+	Path string // The path of the hosts file, ex. DefaultHostsPath
+
+	mutex   sync.Mutex
+	modTime time.Time
+	size    int64
+	byName  map[string][]netip.Addr // Addresses of the names, by fully qualified name in lowercase
+	byAddr  map[netip.Addr][]string // Names of the addresses, canonical name first
+}
+
+// NewHosts returns the entries of a hosts file, read on the first lookup.
+//
+// Parameters:
+//   - path: The path of the hosts file, ex. DefaultHostsPath.
+func NewHosts(path string) *Hosts {
+	return &Hosts{Path: path}
+}
+
+// LookupHost returns the addresses of a name in the hosts file, in the order of the file.
+//
+// Returns:
+//   - []netip.Addr: The addresses of the name.
+//   - bool: Whether the name is in the hosts file.
+func (hosts *Hosts) LookupHost(name string) ([]netip.Addr, bool) {
+	hosts.mutex.Lock()
+	defer hosts.mutex.Unlock()
+	hosts.reload()
+
+	addresses, ok := hosts.byName[dns.CanonicalName(name)]
+	return append([]netip.Addr(nil), addresses...), ok
+}
+
+// LookupAddr returns the names of an address in the hosts file, in the order of the file,
+// as fully qualified names.
+func (hosts *Hosts) LookupAddr(ip netip.Addr) []string {
+	hosts.mutex.Lock()
+	defer hosts.mutex.Unlock()
+	hosts.reload()
+
+	return append([]string(nil), hosts.byAddr[ip.Unmap()]...)
+}
+
+// reload reads the hosts file again if its modification time or size changed since it was read.
+// A hosts file which cannot be read has no entries.
+func (hosts *Hosts) reload() {
+	info, err := os.Stat(hosts.Path)
+	if err != nil {
+		hosts.modTime, hosts.size = time.Time{}, 0
+		hosts.byName, hosts.byAddr = nil, nil
+		return
+	}
+	if hosts.byName != nil && info.ModTime().Equal(hosts.modTime) && info.Size() == hosts.size {
+		return
+	}
+
+	hosts.byName = map[string][]netip.Addr{}
+	hosts.byAddr = map[netip.Addr][]string{}
+	hosts.modTime, hosts.size = info.ModTime(), info.Size()
+
+	file, err := os.Open(hosts.Path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		ip, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			continue
+		}
+		ip = ip.Unmap()
+
+		for _, name := range fields[1:] {
+			name = dns.CanonicalName(name)
+			hosts.byName[name] = append(hosts.byName[name], ip)
+			hosts.byAddr[ip] = append(hosts.byAddr[ip], name)
+		}
+	}
+}
+
+// lookupHosts returns the addresses of a name in the hosts file of the resolver which pass the filter,
+// ex. netip.Addr.Is4, and whether the name is in the hosts file.
+func (resolver *Resolver) lookupHosts(name string, filter func(netip.Addr) bool) (addresses []netip.Addr, ok bool) {
+	if resolver.Hosts == nil {
+		return nil, false
+	}
+	ips, ok := resolver.Hosts.LookupHost(name)
+	for _, ip := range ips {
+		if filter(ip) {
+			addresses = append(addresses, ip)
+		}
+	}
+	return addresses, ok
+}
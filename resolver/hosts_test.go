@@ -0,0 +1,113 @@
+package resolver
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestHosts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	data := "# Static table lookup for hostnames\n" +
+		"127.0.0.1    localhost\n" +
+		"::1          localhost ip6-localhost\n" +
+		"192.0.2.10   Server.example.com server # file server\n" +
+		"invalid      invalid.example.com\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write hosts file: %v", err)
+	}
+	hosts := NewHosts(path)
+
+	tests := []struct {
+		name   string
+		data   string
+		want   []netip.Addr
+		wantOk bool
+	}{
+		{
+			name:   "IPv4 and IPv6 addresses",
+			data:   "localhost",
+			want:   []netip.Addr{netip.MustParseAddr("127.0.0.1"), netip.MustParseAddr("::1")},
+			wantOk: true,
+		},
+		{
+			name:   "Case insensitive fully qualified name",
+			data:   "server.EXAMPLE.com.",
+			want:   []netip.Addr{netip.MustParseAddr("192.0.2.10")},
+			wantOk: true,
+		},
+		{
+			name:   "Alias",
+			data:   "server",
+			want:   []netip.Addr{netip.MustParseAddr("192.0.2.10")},
+			wantOk: true,
+		},
+		{
+			name:   "Invalid address",
+			data:   "invalid.example.com",
+			want:   nil,
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := hosts.LookupHost(tt.data)
+			if !reflect.DeepEqual(got, tt.want) || ok != tt.wantOk {
+				t.Errorf("LookupHost() got = %v, %t, want = %v, %t\n", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+
+	names := hosts.LookupAddr(netip.MustParseAddr("192.0.2.10"))
+	if want := []string{"server.example.com.", "server."}; !reflect.DeepEqual(names, want) {
+		t.Errorf("LookupAddr() got = %v, want = %v\n", names, want)
+	}
+
+	// The file is read again once modified
+	if err := os.WriteFile(path, []byte("192.0.2.20 server\n"), 0o644); err != nil {
+		t.Fatalf("write hosts file: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("change hosts file time: %v", err)
+	}
+	addresses, _ := hosts.LookupHost("server")
+	if want := []netip.Addr{netip.MustParseAddr("192.0.2.20")}; !reflect.DeepEqual(addresses, want) {
+		t.Errorf("LookupHost() after modification got = %v, want = %v\n", addresses, want)
+	}
+	if _, ok := hosts.LookupHost("localhost"); ok {
+		t.Errorf("LookupHost() after modification found a removed name\n")
+	}
+}
+
+func TestResolverHosts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte("192.0.2.10 example.com\n"), 0o644); err != nil {
+		t.Fatalf("write hosts file: %v", err)
+	}
+	resolver := startTestServer(t, []dns.ResourceRecord{
+		{Name: "example.com.", RType: dns.A, RClass: dns.IN, TTL: 300, RData: &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")}},
+		{Name: "example.com.", RType: dns.AAAA, RClass: dns.IN, TTL: 300, RData: &dns.RDataAAAA{IP: netip.MustParseAddr("2001:db8::1")}},
+	})
+	resolver.Hosts = NewHosts(path)
+
+	addresses, err := resolver.LookupA("example.com.")
+	if err != nil || !reflect.DeepEqual(addresses, []netip.Addr{netip.MustParseAddr("192.0.2.10")}) {
+		t.Errorf("LookupA() got = %v, error = %v\n", addresses, err)
+	}
+	// A name in the hosts file is only looked up there
+	addresses, err = resolver.LookupAAAA("example.com.")
+	if err != nil || addresses != nil {
+		t.Errorf("LookupAAAA() got = %v, error = %v, want none\n", addresses, err)
+	}
+	names, err := resolver.LookupPTR(netip.MustParseAddr("192.0.2.10"))
+	if err != nil || !reflect.DeepEqual(names, []string{"example.com."}) {
+		t.Errorf("LookupPTR() got = %v, error = %v\n", names, err)
+	}
+}
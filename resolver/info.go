@@ -0,0 +1,66 @@
+package resolver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mcombeau/dns-tools/client"
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// Metadata of the queries of a lookup, for the tools which monitor the resolution of names: a lookup
+// may send several queries, one per search domain tried and per alias the server did not follow,
+// each answered from the cache or by one of the servers of the resolver. QueryChain returns them
+// along with the answers, in the Queries of the chain, in the order they were sent.
+
+// QueryInfo describes how a query of a lookup was answered.
+type QueryInfo struct {
+	Name      string        // Name queried, ex. "www.example.com."
+	Type      uint16        // Type queried
+	CacheHit  bool          // Whether the answer came from the cache, in which case no query was sent
+	Server    string        // Address of the server which answered, ex. "192.0.2.53:53", empty for cache hits and failures
+	Transport string        // "udp" or "tcp", the transport of the response
+	Retries   int           // Number of times the query was sent again to the server over UDP
+	Truncated bool          // Whether the UDP response was truncated: then retried over TCP unless the client ignores truncation
+	Size      int           // Size of the response on the wire, in bytes
+	Duration  time.Duration // Time elapsed until the answer, including the servers which failed and waiting for the same query in flight
+}
+
+// newQueryInfo returns the metadata of a query answered by a server, after the time elapsed since start.
+func newQueryInfo(name string, qtype uint16, response client.Response, start time.Time) QueryInfo {
+	info := QueryInfo{Name: name, Type: qtype, Duration: time.Since(start)}
+	if response.Server == "" {
+		// No server answered
+		return info
+	}
+
+	info.Server = response.Server
+	info.Transport = "udp"
+	if response.TCP {
+		info.Transport = "tcp"
+	}
+	info.Retries = response.Retries
+	info.Truncated = response.TCP || response.Message.Header.Flags.Truncated
+	info.Size = response.Size
+	return info
+}
+
+// String returns the metadata in one line, ex. "www.example.com. A: 56 bytes from 192.0.2.53:53 over udp in 12 ms".
+func (info QueryInfo) String() string {
+	query := fmt.Sprintf("%s %s", info.Name, dns.DNSType(info.Type))
+	switch {
+	case info.CacheHit:
+		return fmt.Sprintf("%s: cache hit", query)
+	case info.Server == "":
+		return fmt.Sprintf("%s: no answer in %d ms", query, info.Duration.Milliseconds())
+	}
+
+	line := fmt.Sprintf("%s: %d bytes from %s over %s in %d ms", query, info.Size, info.Server, info.Transport, info.Duration.Milliseconds())
+	if info.Truncated {
+		line += ", truncated"
+	}
+	if info.Retries > 0 {
+		line += fmt.Sprintf(", %d retries", info.Retries)
+	}
+	return line
+}
@@ -0,0 +1,127 @@
+package resolver
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/client"
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestQueryChainQueries(t *testing.T) {
+	newRecord := func(name string, rtype uint16, rdata dns.RData) dns.ResourceRecord {
+		return dns.ResourceRecord{Name: name, RType: rtype, RClass: dns.IN, TTL: 300, RData: rdata}
+	}
+	resolver := startTestServer(t, []dns.ResourceRecord{
+		newRecord("example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")}),
+		newRecord("host.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.2")}),
+		newRecord("www.example.com.", dns.CNAME, &dns.RDataCNAME{DomainName: "example.com."}),
+	})
+	resolver.Cache = NewCache(0)
+	resolver.Search = []string{"example.org.", "example.com."}
+	resolver.Ndots = 1
+
+	tests := []struct {
+		name         string
+		data         string
+		want         []string
+		wantCacheHit []bool
+		wantError    error
+	}{
+		{
+			name:         "Search domains",
+			data:         "host",
+			want:         []string{"host.example.org.", "host.example.com."},
+			wantCacheHit: []bool{false, false},
+		},
+		{
+			name:         "CNAME not followed by the server",
+			data:         "www.example.com.",
+			want:         []string{"www.example.com.", "example.com."},
+			wantCacheHit: []bool{false, false},
+		},
+		{
+			name:         "Cache hit",
+			data:         "example.com.",
+			want:         []string{"example.com."},
+			wantCacheHit: []bool{true},
+		},
+		{
+			name:         "Name not found",
+			data:         "missing.example.com.",
+			want:         []string{"missing.example.com."},
+			wantCacheHit: []bool{false},
+			wantError:    ErrNameNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := resolver.QueryChain(tt.data, dns.A)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("QueryChain() error = %v, want error = %v\n", err, tt.wantError)
+			}
+			if len(chain.Queries) != len(tt.want) {
+				t.Fatalf("QueryChain() got queries = %v, want = %v\n", chain.Queries, tt.want)
+			}
+			for i, info := range chain.Queries {
+				if info.Name != tt.want[i] || info.Type != dns.A || info.CacheHit != tt.wantCacheHit[i] {
+					t.Errorf("QueryChain() got query = %+v, want = %s, cache hit = %t\n", info, tt.want[i], tt.wantCacheHit[i])
+				}
+				if info.CacheHit {
+					continue
+				}
+				if info.Server != resolver.Client.Server || info.Transport != "udp" || info.Size == 0 || info.Duration <= 0 {
+					t.Errorf("QueryChain() got query = %+v, want an answer from %s over udp\n", info, resolver.Client.Server)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryInfoString(t *testing.T) {
+	tests := []struct {
+		name string
+		data QueryInfo
+		want string
+	}{
+		{
+			name: "Cache hit",
+			data: QueryInfo{Name: "example.com.", Type: dns.A, CacheHit: true},
+			want: "example.com. A: cache hit",
+		},
+		{
+			name: "No answer",
+			data: QueryInfo{Name: "example.com.", Type: dns.AAAA, Duration: 5 * time.Second},
+			want: "example.com. AAAA: no answer in 5000 ms",
+		},
+		{
+			name: "TCP fallback with retries",
+			data: QueryInfo{Name: "example.com.", Type: dns.TXT, Server: "192.0.2.53:53", Transport: "tcp", Retries: 1, Truncated: true, Size: 1400, Duration: 12 * time.Millisecond},
+			want: "example.com. TXT: 1400 bytes from 192.0.2.53:53 over tcp in 12 ms, truncated, 1 retries",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.data.String(); got != tt.want {
+				t.Errorf("String() got = %q, want = %q\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewQueryInfo(t *testing.T) {
+	response := client.Response{Server: "192.0.2.53:53", Size: 1400, TCP: true, Retries: 2}
+	got := newQueryInfo("example.com.", dns.A, response, time.Now())
+	if got.Server != "192.0.2.53:53" || got.Transport != "tcp" || !got.Truncated || got.Retries != 2 || got.Size != 1400 {
+		t.Errorf("newQueryInfo() got = %+v\n", got)
+	}
+
+	got = newQueryInfo("example.com.", dns.A, client.Response{}, time.Now())
+	if got.Server != "" || got.Transport != "" {
+		t.Errorf("newQueryInfo() without response got = %+v\n", got)
+	}
+}
@@ -0,0 +1,264 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/mcombeau/dns-tools/client"
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// Integration with the net package, in two ways:
+//
+//   - NetResolver has the lookup methods of net.Resolver, so code written against
+//     net.Resolver can use a Resolver by changing the type of a variable or field.
+//   - Resolver.Dial makes the Go resolver of the net package send its queries to the
+//     resolver's server, for code which cannot be changed:
+//
+//	net.DefaultResolver = &net.Resolver{PreferGo: true, Dial: resolver.Dial}
+//
+// The errors of NetResolver are *net.DNSError, like those of net.Resolver.
+
+// NetResolver has the lookup methods of net.Resolver, sending the queries through a Resolver.
+// The lookups are abandoned when their context is done, but the query in flight is only
+// stopped by the timeout of the resolver's client.
+type NetResolver struct {
+	Resolver        *Resolver
+	ResolutionDelay time.Duration // Time LookupDialAddrs waits for its second answer, DefaultResolutionDelay if 0
+}
+
+// Net returns a NetResolver sending its queries through the resolver.
+func (resolver *Resolver) Net() *NetResolver {
+	return &NetResolver{Resolver: resolver}
+}
+
+// Dial connects to the resolver's server over the network asked for, "udp" or "tcp",
+// whatever the address, so that it can be set as the Dial function of a net.Resolver
+// with PreferGo, which then sends its queries to the resolver's server.
+//
+// Parameters:
+//   - ctx: The context of the connection, which may cancel it.
+//   - network: The network asked for by the net package, ex. "udp".
+//   - address: The address of the name server from the system configuration. It is ignored.
+func (resolver *Resolver) Dial(ctx context.Context, network string, address string) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: resolver.Client.Timeout}
+	return dialer.DialContext(ctx, network, resolver.Client.Server)
+}
+
+// LookupHost returns the addresses of a host, IPv4 addresses first.
+// An IP address is returned as is.
+func (resolver *NetResolver) LookupHost(ctx context.Context, host string) (addresses []string, err error) {
+	ips, err := resolver.LookupNetIP(ctx, "ip", host)
+	for _, ip := range ips {
+		addresses = append(addresses, ip.String())
+	}
+	return addresses, err
+}
+
+// LookupIPAddr returns the addresses of a host, IPv4 addresses first.
+func (resolver *NetResolver) LookupIPAddr(ctx context.Context, host string) (addresses []net.IPAddr, err error) {
+	ips, err := resolver.LookupNetIP(ctx, "ip", host)
+	for _, ip := range ips {
+		addresses = append(addresses, net.IPAddr{IP: ip.AsSlice(), Zone: ip.Zone()})
+	}
+	return addresses, err
+}
+
+// LookupIP returns the addresses of a host for a network: "ip" for all of them,
+// IPv4 addresses first, "ip4" for IPv4 addresses or "ip6" for IPv6 addresses.
+func (resolver *NetResolver) LookupIP(ctx context.Context, network string, host string) (addresses []net.IP, err error) {
+	ips, err := resolver.LookupNetIP(ctx, network, host)
+	for _, ip := range ips {
+		addresses = append(addresses, ip.AsSlice())
+	}
+	return addresses, err
+}
+
+// LookupNetIP returns the addresses of a host for a network, see LookupIP.
+// A host without addresses for the network gives a not found error, like with net.Resolver.
+func (resolver *NetResolver) LookupNetIP(ctx context.Context, network string, host string) ([]netip.Addr, error) {
+	var qtypes []uint16
+	switch network {
+	case "ip":
+		qtypes = []uint16{dns.A, dns.AAAA}
+	case "ip4":
+		qtypes = []uint16{dns.A}
+	case "ip6":
+		qtypes = []uint16{dns.AAAA}
+	default:
+		return nil, &net.DNSError{Err: "unsupported network " + network, Name: host}
+	}
+
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{ip}, nil
+	}
+
+	addresses, err := withContext(ctx, func() (addresses []netip.Addr, err error) {
+		for _, qtype := range qtypes {
+			lookup := resolver.Resolver.LookupA
+			if qtype == dns.AAAA {
+				lookup = resolver.Resolver.LookupAAAA
+			}
+			ips, lookupErr := lookup(host)
+			addresses = append(addresses, ips...)
+			if err == nil {
+				err = lookupErr
+			}
+		}
+		if len(addresses) > 0 {
+			// Like net.Resolver, the addresses of one type are enough
+			return addresses, nil
+		}
+		if err == nil {
+			err = ErrNameNotFound
+		}
+		return nil, err
+	})
+	return addresses, resolver.getDNSError(host, err)
+}
+
+// LookupCNAME returns the canonical name of a host, following the CNAME and DNAME records
+// of its A query, see Resolver.QueryChain. A host without them is its own canonical name,
+// with the search domain it was found in.
+func (resolver *NetResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	cname, err := withContext(ctx, func() (string, error) {
+		chain, err := resolver.Resolver.QueryChain(host, dns.A)
+		if err != nil {
+			return "", err
+		}
+		return chain.Target, nil
+	})
+	return cname, resolver.getDNSError(host, err)
+}
+
+// LookupAddr returns the names of an IP address, from the PTR records of its reverse name.
+func (resolver *NetResolver) LookupAddr(ctx context.Context, address string) ([]string, error) {
+	ip, err := netip.ParseAddr(address)
+	if err != nil {
+		return nil, &net.DNSError{Err: "unrecognized address", Name: address}
+	}
+	names, err := withContext(ctx, func() ([]string, error) {
+		return resolver.Resolver.LookupPTR(ip)
+	})
+	return names, resolver.getDNSError(address, err)
+}
+
+// LookupMX returns the mail exchanges of a name, most preferred first.
+func (resolver *NetResolver) LookupMX(ctx context.Context, name string) (exchanges []*net.MX, err error) {
+	records, err := withContext(ctx, func() ([]dns.RDataMX, error) {
+		return resolver.Resolver.LookupMX(name)
+	})
+	for _, record := range records {
+		exchanges = append(exchanges, &net.MX{Host: record.Exchange, Pref: record.Preference})
+	}
+	return exchanges, resolver.getDNSError(name, err)
+}
+
+// LookupNS returns the name servers of a zone.
+func (resolver *NetResolver) LookupNS(ctx context.Context, name string) (servers []*net.NS, err error) {
+	hosts, err := withContext(ctx, func() ([]string, error) {
+		return resolver.Resolver.LookupNS(name)
+	})
+	for _, host := range hosts {
+		servers = append(servers, &net.NS{Host: host})
+	}
+	return servers, resolver.getDNSError(name, err)
+}
+
+// LookupTXT returns the text of the TXT records of a name, see Resolver.LookupTXT.
+func (resolver *NetResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	texts, err := withContext(ctx, func() ([]string, error) {
+		return resolver.Resolver.LookupTXT(name)
+	})
+	return texts, resolver.getDNSError(name, err)
+}
+
+// LookupSRV returns the SRV records of a service in the order their targets should be tried.
+// With empty service and proto, the name is looked up as is, like with net.Resolver.
+//
+// Returns:
+//   - string: The name looked up, ex. "_sip._tcp.example.com.".
+//   - []*net.SRV: The SRV records of the service, in selection order.
+//   - error: If the lookup failed.
+func (resolver *NetResolver) LookupSRV(ctx context.Context, service string, proto string, name string) (string, []*net.SRV, error) {
+	srvName := dns.Fqdn(name)
+	if service != "" || proto != "" {
+		srvName = dns.GetSRVName(service, proto, name)
+	}
+
+	records, err := withContext(ctx, func() ([]dns.RDataSRV, error) {
+		if service != "" || proto != "" {
+			return resolver.Resolver.LookupSRV(service, proto, name)
+		}
+		records, err := resolver.Resolver.lookup(srvName, dns.SRV)
+		srvs := make([]dns.RDataSRV, 0, len(records))
+		for _, record := range records {
+			if rdata, ok := record.RData.(*dns.RDataSRV); ok {
+				srvs = append(srvs, *rdata)
+			}
+		}
+		return dns.SortSRV(srvs), err
+	})
+	addresses := make([]*net.SRV, 0, len(records))
+	for _, record := range records {
+		addresses = append(addresses, &net.SRV{Target: record.Target, Port: record.Port, Priority: record.Priority, Weight: record.Weight})
+	}
+	return srvName, addresses, resolver.getDNSError(srvName, err)
+}
+
+// LookupPort returns the port of a service for a network, from the services database
+// of the system: it sends no query, so it is the one of net.DefaultResolver.
+func (resolver *NetResolver) LookupPort(ctx context.Context, network string, service string) (int, error) {
+	return net.DefaultResolver.LookupPort(ctx, network, service)
+}
+
+// getDNSError returns a lookup error as a *net.DNSError, reporting whether the name was not
+// found or the server did not answer in time, or nil if there is no error.
+func (resolver *NetResolver) getDNSError(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	dnsErr := &net.DNSError{
+		Err:       err.Error(),
+		Name:      strings.TrimSuffix(name, "."),
+		Server:    resolver.Resolver.Client.Server,
+		IsTimeout: client.GetErrorClass(err) == client.ErrorClassTimeout || errors.Is(err, context.DeadlineExceeded),
+	}
+	if errors.Is(err, ErrNameNotFound) {
+		dnsErr.Err = "no such host"
+		dnsErr.IsNotFound = true
+	}
+	return dnsErr
+}
+
+// withContext runs a lookup, returning the error of the context instead if it is done first.
+// The lookup then goes on in the background until the client's timeout.
+func withContext[T any](ctx context.Context, lookup func() (T, error)) (T, error) {
+	if err := ctx.Err(); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := lookup()
+		done <- result{value, err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.value, result.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
@@ -0,0 +1,97 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestNetResolver(t *testing.T) {
+	newRecord := func(name string, rtype uint16, rdata dns.RData) dns.ResourceRecord {
+		return dns.ResourceRecord{Name: name, RType: rtype, RClass: dns.IN, TTL: 300, RData: rdata}
+	}
+	resolver := startTestServer(t, []dns.ResourceRecord{
+		newRecord("example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")}),
+		newRecord("example.com.", dns.AAAA, &dns.RDataAAAA{IP: netip.MustParseAddr("2001:db8::1")}),
+		newRecord("example.com.", dns.MX, &dns.RDataMX{Preference: 10, Exchange: "mail.example.com."}),
+		newRecord("www.example.com.", dns.CNAME, &dns.RDataCNAME{DomainName: "example.com."}),
+		newRecord("_sip._tcp.example.com.", dns.SRV, &dns.RDataSRV{Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com."}),
+		newRecord("ipv4.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.2")}),
+	})
+	netResolver := resolver.Net()
+	ctx := context.Background()
+
+	hosts, err := netResolver.LookupHost(ctx, "example.com")
+	if err != nil || !reflect.DeepEqual(hosts, []string{"192.0.2.1", "2001:db8::1"}) {
+		t.Errorf("LookupHost() got = %v, error = %v\n", hosts, err)
+	}
+	hosts, err = netResolver.LookupHost(ctx, "ipv4.example.com")
+	if err != nil || !reflect.DeepEqual(hosts, []string{"192.0.2.2"}) {
+		t.Errorf("LookupHost() without IPv6 addresses got = %v, error = %v\n", hosts, err)
+	}
+	hosts, err = netResolver.LookupHost(ctx, "2001:db8::2")
+	if err != nil || !reflect.DeepEqual(hosts, []string{"2001:db8::2"}) {
+		t.Errorf("LookupHost() of an IP address got = %v, error = %v\n", hosts, err)
+	}
+	ips, err := netResolver.LookupIP(ctx, "ip6", "example.com")
+	if err != nil || len(ips) != 1 || !ips[0].Equal(net.ParseIP("2001:db8::1")) {
+		t.Errorf("LookupIP() got = %v, error = %v\n", ips, err)
+	}
+
+	cname, err := netResolver.LookupCNAME(ctx, "www.example.com")
+	if err != nil || cname != "example.com." {
+		t.Errorf("LookupCNAME() got = %v, error = %v, want = %v\n", cname, err, "example.com.")
+	}
+	cname, err = netResolver.LookupCNAME(ctx, "example.com")
+	if err != nil || cname != "example.com." {
+		t.Errorf("LookupCNAME() without CNAME got = %v, error = %v, want = %v\n", cname, err, "example.com.")
+	}
+
+	exchanges, err := netResolver.LookupMX(ctx, "example.com")
+	if err != nil || !reflect.DeepEqual(exchanges, []*net.MX{{Host: "mail.example.com.", Pref: 10}}) {
+		t.Errorf("LookupMX() got = %v, error = %v\n", exchanges, err)
+	}
+
+	srvName, srvs, err := netResolver.LookupSRV(ctx, "sip", "tcp", "example.com")
+	wantSRVs := []*net.SRV{{Target: "sip.example.com.", Port: 5060, Priority: 10, Weight: 5}}
+	if err != nil || srvName != "_sip._tcp.example.com." || !reflect.DeepEqual(srvs, wantSRVs) {
+		t.Errorf("LookupSRV() got = %v %v, error = %v\n", srvName, srvs, err)
+	}
+	srvName, srvs, err = netResolver.LookupSRV(ctx, "", "", "_sip._tcp.example.com")
+	if err != nil || srvName != "_sip._tcp.example.com." || !reflect.DeepEqual(srvs, wantSRVs) {
+		t.Errorf("LookupSRV() of a name got = %v %v, error = %v\n", srvName, srvs, err)
+	}
+
+	var dnsErr *net.DNSError
+	_, err = netResolver.LookupHost(ctx, "missing.example.com")
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound || dnsErr.Name != "missing.example.com" {
+		t.Errorf("LookupHost() error = %#v, want a not found *net.DNSError\n", err)
+	}
+	_, err = netResolver.LookupNS(ctx, "www.example.com")
+	if err != nil {
+		t.Errorf("LookupNS() without records error = %v, want = nil\n", err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err = netResolver.LookupHost(canceled, "example.com"); !errors.As(err, &dnsErr) || dnsErr.Err != context.Canceled.Error() {
+		t.Errorf("LookupHost() with canceled context error = %v\n", err)
+	}
+}
+
+func TestResolverDial(t *testing.T) {
+	resolver := startTestServer(t, []dns.ResourceRecord{
+		{Name: "example.com.", RType: dns.A, RClass: dns.IN, TTL: 300, RData: &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")}},
+	})
+	netResolver := &net.Resolver{PreferGo: true, Dial: resolver.Dial}
+
+	addresses, err := netResolver.LookupNetIP(context.Background(), "ip4", "example.com.")
+	if err != nil || !reflect.DeepEqual(addresses, []netip.Addr{netip.MustParseAddr("192.0.2.1")}) {
+		t.Errorf("net.Resolver.LookupNetIP() got = %v, error = %v\n", addresses, err)
+	}
+}
@@ -0,0 +1,321 @@
+// Package resolver looks up the records of domain names through a DNS server and returns
+// their data as Go values, ex. the addresses of a name, instead of the response messages.
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mcombeau/dns-tools/client"
+	"github.com/mcombeau/dns-tools/dns"
+	"github.com/mcombeau/dns-tools/sysconfig"
+)
+
+var ErrNameNotFound = fmt.Errorf("name not found")
+
+// Resolver sends recursive queries through a client, ex. to the system resolver.
+// A name which exists without records of the type looked up gives no values and no error.
+// Names without a final dot are relative: they are tried with the search domains, see Query.
+//
+// A query which fails, or which the server answers with SERVFAIL, REFUSED or NOTIMP,
+// is sent to the servers of the fallback clients in turn, in the order of the selection policy.
+type Resolver struct {
+	Client    *client.Client
+	Fallbacks []*client.Client // Clients of the servers tried after the client's, in order
+	Rotate    bool             // Start each query with the next server, spreading the load across the servers
+	Policy    SelectionPolicy  // Order in which the servers are tried, see SelectionPolicy
+	MaxQPS    float64          // Maximum number of queries sent to each server per second, no limit if 0
+	Search    []string         // Domains to search for relative names, see SearchNames
+	Ndots     int              // Number of dots from which a name is tried as is first, see SearchNames
+	Hosts     *Hosts           // Hosts file looked up before sending queries for addresses and names of addresses, if not nil
+	Cache     *Cache           // Cache of the answers, looked up before sending queries, if not nil
+
+	MaxChainLength int          // Maximum number of CNAME and DNAME records followed, DefaultMaxChainLength if 0
+	DNS64          bool         // Synthesize AAAA records from the A records of the names which have none, see QueryChain
+	NAT64Prefix    netip.Prefix // Prefix of the synthesized IPv6 addresses, DefaultNAT64Prefix if not set
+
+	next     atomic.Uint32  // Index of the server the next query starts with when rotating
+	flights  flightGroup    // Queries in flight, shared by the lookups which send the same query at once
+	stats    serverStats    // Round trip times and failure rates of the servers
+	limiters serverLimiters // Times the next queries may be sent to the servers, with MaxQPS
+}
+
+// NewResolver returns a Resolver querying the given server with the client defaults.
+//
+// Parameters:
+//   - server: The address of the recursive DNS server, ex. "8.8.8.8:53".
+func NewResolver(server string) *Resolver {
+	return &Resolver{Client: client.NewClient(server)}
+}
+
+// NewResolverFromConfig returns a Resolver querying the name servers of a resolver configuration,
+// ex. read with sysconfig.ReadResolvConf, on port 53 with its timeout, rotation and search list,
+// and looking up the addresses of names in the hosts file at DefaultHostsPath first.
+// Each server is queried up to the configuration's number of attempts before the next one is tried.
+//
+// Parameters:
+//   - conf: The resolver configuration.
+//
+// Returns:
+//   - *Resolver: The resolver.
+//   - error: sysconfig.ErrNoResolvers if the configuration has no name server.
+func NewResolverFromConfig(conf sysconfig.ResolvConf) (*Resolver, error) {
+	if len(conf.Nameservers) == 0 {
+		return nil, sysconfig.ErrNoResolvers
+	}
+
+	clients := make([]*client.Client, 0, len(conf.Nameservers))
+	for _, nameserver := range conf.Nameservers {
+		dnsClient := client.NewClient(net.JoinHostPort(nameserver, "53"))
+		if conf.Timeout > 0 {
+			dnsClient.Timeout = conf.Timeout
+		}
+		dnsClient.Retries = max(conf.Attempts-1, 0)
+		clients = append(clients, dnsClient)
+	}
+	return &Resolver{
+		Client:    clients[0],
+		Fallbacks: clients[1:],
+		Rotate:    conf.Rotate,
+		Search:    conf.Search,
+		Ndots:     conf.Ndots,
+		Hosts:     NewHosts(DefaultHostsPath),
+	}, nil
+}
+
+// LookupA returns the IPv4 addresses of a name, from the hosts file of the resolver if the name is in it.
+func (resolver *Resolver) LookupA(name string) (addresses []netip.Addr, err error) {
+	if addresses, ok := resolver.lookupHosts(name, netip.Addr.Is4); ok {
+		return addresses, nil
+	}
+
+	records, err := resolver.lookup(name, dns.A)
+	for _, record := range records {
+		if rdata, ok := record.RData.(*dns.RDataA); ok {
+			addresses = append(addresses, rdata.IP)
+		}
+	}
+	return addresses, err
+}
+
+// LookupAAAA returns the IPv6 addresses of a name, from the hosts file of the resolver if the name is in it.
+func (resolver *Resolver) LookupAAAA(name string) (addresses []netip.Addr, err error) {
+	if addresses, ok := resolver.lookupHosts(name, netip.Addr.Is6); ok {
+		return addresses, nil
+	}
+
+	records, err := resolver.lookup(name, dns.AAAA)
+	for _, record := range records {
+		if rdata, ok := record.RData.(*dns.RDataAAAA); ok {
+			addresses = append(addresses, rdata.IP)
+		}
+	}
+	return addresses, err
+}
+
+// LookupMX returns the mail exchanges of a name, by preference: most preferred first [RFC5321].
+func (resolver *Resolver) LookupMX(name string) (exchanges []dns.RDataMX, err error) {
+	records, err := resolver.lookup(name, dns.MX)
+	for _, record := range records {
+		if rdata, ok := record.RData.(*dns.RDataMX); ok {
+			exchanges = append(exchanges, *rdata)
+		}
+	}
+	slices.SortStableFunc(exchanges, func(a dns.RDataMX, b dns.RDataMX) int {
+		return int(a.Preference) - int(b.Preference)
+	})
+	return exchanges, err
+}
+
+// LookupTXT returns the text of the TXT records of a name, one string per record:
+// the character-strings of a record are concatenated, as SPF and DKIM records expect [RFC7208].
+func (resolver *Resolver) LookupTXT(name string) (texts []string, err error) {
+	records, err := resolver.lookup(name, dns.TXT)
+	for _, record := range records {
+		if rdata, ok := record.RData.(*dns.RDataTXT); ok {
+			texts = append(texts, strings.Join(rdata.Text, ""))
+		}
+	}
+	return texts, err
+}
+
+// LookupNS returns the names of the name servers of a zone.
+func (resolver *Resolver) LookupNS(name string) (servers []string, err error) {
+	records, err := resolver.lookup(name, dns.NS)
+	for _, record := range records {
+		if rdata, ok := record.RData.(*dns.RDataNS); ok {
+			servers = append(servers, rdata.DomainName)
+		}
+	}
+	return servers, err
+}
+
+// LookupSRV returns the SRV records of a service in the order their targets should be tried,
+// see client.Client.LookupSRV.
+//
+// Parameters:
+//   - service: The symbolic name of the service, ex. "sip".
+//   - proto: The protocol of the service, ex. "tcp".
+//   - name: The domain the service is provided for, ex. "example.com.".
+//
+// Returns:
+//   - []dns.RDataSRV: The SRV records of the service, in selection order.
+//   - error: If the SRV query failed, ErrNameNotFound if the service does not exist,
+//     or client.ErrServiceUnavailable if the domain says the service is not available.
+func (resolver *Resolver) LookupSRV(service string, proto string, name string) ([]dns.RDataSRV, error) {
+	srvName := dns.GetSRVName(service, proto, name)
+	answers, err := resolver.lookup(srvName, dns.SRV)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []dns.RDataSRV
+	for _, record := range answers {
+		if rdata, ok := record.RData.(*dns.RDataSRV); ok {
+			records = append(records, *rdata)
+		}
+	}
+	if dns.IsServiceUnavailable(records) {
+		return nil, fmt.Errorf("%w: %s", client.ErrServiceUnavailable, strings.TrimSuffix(srvName, "."))
+	}
+	return dns.SortSRV(records), nil
+}
+
+// LookupPTR returns the names of an IP address, from the PTR records of its reverse name,
+// or from the hosts file of the resolver if the address is in it.
+func (resolver *Resolver) LookupPTR(ip netip.Addr) (names []string, err error) {
+	if resolver.Hosts != nil {
+		if names := resolver.Hosts.LookupAddr(ip); len(names) > 0 {
+			return names, nil
+		}
+	}
+
+	reverseName, err := dns.ReverseAddr(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := resolver.lookup(reverseName, dns.PTR)
+	for _, record := range records {
+		if rdata, ok := record.RData.(*dns.RDataPTR); ok {
+			names = append(names, rdata.DomainName)
+		}
+	}
+	return names, err
+}
+
+// lookup sends a recursive query and returns the answers of the type looked up,
+// following the CNAME and DNAME records which lead to them, see QueryChain.
+//
+// Returns:
+//   - []dns.ResourceRecord: The records of the type, none if the name has no such records.
+//   - error: If the query failed, see QueryChain.
+func (resolver *Resolver) lookup(name string, qtype uint16) ([]dns.ResourceRecord, error) {
+	chain, err := resolver.QueryChain(name, qtype)
+	if err != nil {
+		return nil, err
+	}
+	return chain.Answers, nil
+}
+
+// exchange sends a recursive query and returns the response, or a response with the
+// answer from the cache of the resolver if it is there. A query which is already in flight
+// is not sent again: the response to the query in flight is returned, even if it was given up
+// because the context of the lookup which sent it is done.
+//
+// Returns:
+//   - dns.Message: The response, with the NOERROR response code.
+//   - QueryInfo: How the query was answered, also when it failed.
+//   - error: If the query failed, ErrNameNotFound if the name does not exist,
+//     or if the server did not answer with NOERROR.
+func (resolver *Resolver) exchange(ctx context.Context, name string, qtype uint16) (dns.Message, QueryInfo, error) {
+	start := time.Now()
+	query, err := dns.CreateQueryMessage(name, qtype, false)
+	if err != nil {
+		return dns.Message{}, QueryInfo{Name: name, Type: qtype}, err
+	}
+
+	cacheKey := NewCacheKey(name, qtype, dns.IN)
+	if resolver.Cache != nil {
+		if records, ok, prefetch := resolver.Cache.get(cacheKey, true); ok {
+			if prefetch {
+				go resolver.prefetch(query, cacheKey)
+			}
+			info := QueryInfo{Name: name, Type: qtype, CacheHit: true, Duration: time.Since(start)}
+			return *dns.NewResponse(&query).WithRecursionAvailable().Answer(records...), info, nil
+		}
+	}
+
+	response, err := resolver.flights.do(cacheKey, func() (client.Response, error) {
+		return resolver.exchangeQuery(ctx, query)
+	})
+	info := newQueryInfo(name, qtype, response, start)
+	if err != nil {
+		return dns.Message{}, info, err
+	}
+	switch responseCode := response.Message.ResponseCode(); responseCode {
+	case dns.NOERROR:
+	case dns.NXDOMAIN:
+		return dns.Message{}, info, errorNameNotFound(name)
+	default:
+		return dns.Message{}, info, fmt.Errorf("lookup %s: %s", name, dns.DNSRCode(responseCode))
+	}
+
+	if resolver.Cache != nil && hasAnswers(response.Message, qtype) {
+		resolver.Cache.Set(cacheKey, response.Message.Answers)
+	}
+	return response.Message, info, nil
+}
+
+// prefetch sends a query again to replace its answer in the cache before it expires.
+// The answer is left to expire if the query fails.
+func (resolver *Resolver) prefetch(query dns.Message, cacheKey CacheKey) {
+	response, err := resolver.flights.do(cacheKey, func() (client.Response, error) {
+		return resolver.exchangeQuery(context.Background(), query)
+	})
+	if err == nil && response.Message.ResponseCode() == dns.NOERROR && hasAnswers(response.Message, cacheKey.Type) {
+		resolver.Cache.Set(cacheKey, response.Message.Answers)
+	}
+}
+
+// exchangeQuery sends a query to the servers of the resolver in turn, until one answers
+// with another response code than SERVFAIL, REFUSED or NOTIMP, and returns the last response.
+// The round trip time and outcome of each query are recorded in the statistics of its server.
+// The query is given up with the error of the context if it is done while waiting to be sent within MaxQPS.
+func (resolver *Resolver) exchangeQuery(ctx context.Context, query dns.Message) (response client.Response, err error) {
+	clients := resolver.getClients()
+	for i, dnsClient := range clients {
+		if err = resolver.limiters.wait(ctx, dnsClient.Server, resolver.MaxQPS); err != nil {
+			return client.Response{}, err
+		}
+		start := time.Now()
+		response, err = dnsClient.Exchange(query)
+		resolver.stats.record(dnsClient.Server, time.Since(start), err != nil || isServerFailure(response.Message.ResponseCode()))
+		if i == len(clients)-1 {
+			break
+		}
+		if err != nil && !client.GetErrorClass(err).TryNextServer() {
+			break
+		}
+		if err == nil && !isServerFailure(response.Message.ResponseCode()) {
+			break
+		}
+	}
+	return response, err
+}
+
+// isServerFailure reports whether a response code says the server could not answer,
+// rather than giving the answer: another server may answer the query.
+func isServerFailure(responseCode uint16) bool {
+	return responseCode == dns.SERVFAIL || responseCode == dns.REFUSED || responseCode == dns.NOTIMP
+}
+
+func errorNameNotFound(name string) error {
+	return fmt.Errorf("%w: %s", ErrNameNotFound, strings.TrimSuffix(name, "."))
+}
@@ -0,0 +1,185 @@
+package resolver
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/client"
+	"github.com/mcombeau/dns-tools/dns"
+	"github.com/mcombeau/dns-tools/sysconfig"
+)
+
+// startTestServer answers UDP queries on a local port with the records of the zone
+// with the queried name and type, its CNAME record and the DNAME records of its parents,
+// and NXDOMAIN for the names which have none. It does not follow them.
+func startTestServer(t *testing.T, zone []dns.ResourceRecord) *Resolver {
+	t.Helper()
+
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen UDP: %v", err)
+	}
+	t.Cleanup(func() { packetConn.Close() })
+
+	go func() {
+		buffer := make([]byte, dns.MaxDNSMessageSize)
+		for {
+			n, addr, err := packetConn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			query, err := dns.DecodeMessage(buffer[:n])
+			if err != nil || len(query.Questions) != 1 {
+				t.Errorf("test server: invalid query: %v", err)
+				continue
+			}
+
+			response := dns.NewResponse(&query).WithRecursionAvailable().WithResponseCode(dns.NXDOMAIN)
+			for _, record := range zone {
+				if record.RType == dns.DNAME && !dns.EqualNames(record.Name, query.Questions[0].Name) && dns.IsSubdomain(query.Questions[0].Name, record.Name) {
+					response.WithResponseCode(dns.NOERROR).Answer(record)
+					continue
+				}
+				if !dns.EqualNames(record.Name, query.Questions[0].Name) {
+					continue
+				}
+				response.WithResponseCode(dns.NOERROR)
+				if record.RType == query.Questions[0].QType || record.RType == dns.CNAME {
+					response.Answer(record)
+				}
+			}
+
+			data, err := response.Pack()
+			if err != nil {
+				t.Errorf("test server: encode response: %v", err)
+				continue
+			}
+			packetConn.WriteTo(data, addr)
+		}
+	}()
+
+	return NewResolver(packetConn.LocalAddr().String())
+}
+
+func TestResolver(t *testing.T) {
+	newRecord := func(name string, rtype uint16, rdata dns.RData) dns.ResourceRecord {
+		return dns.ResourceRecord{Name: name, RType: rtype, RClass: dns.IN, TTL: 300, RData: rdata}
+	}
+	resolver := startTestServer(t, []dns.ResourceRecord{
+		newRecord("example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")}),
+		newRecord("example.com.", dns.AAAA, &dns.RDataAAAA{IP: netip.MustParseAddr("2001:db8::1")}),
+		newRecord("example.com.", dns.MX, &dns.RDataMX{Preference: 20, Exchange: "backup.example.com."}),
+		newRecord("example.com.", dns.MX, &dns.RDataMX{Preference: 10, Exchange: "mail.example.com."}),
+		newRecord("example.com.", dns.TXT, &dns.RDataTXT{Text: []string{"v=spf1 ", "-all"}}),
+		newRecord("example.com.", dns.NS, &dns.RDataNS{DomainName: "ns.example.com."}),
+		newRecord("www.example.com.", dns.CNAME, &dns.RDataCNAME{DomainName: "example.com."}),
+		newRecord("1.2.0.192.in-addr.arpa.", dns.PTR, &dns.RDataPTR{DomainName: "example.com."}),
+		newRecord("_sip._tcp.example.com.", dns.SRV, &dns.RDataSRV{Priority: 20, Port: 5061, Target: "sip2.example.com."}),
+		newRecord("_sip._tcp.example.com.", dns.SRV, &dns.RDataSRV{Priority: 10, Port: 5060, Target: "sip1.example.com."}),
+		newRecord("_sips._tcp.example.com.", dns.CNAME, &dns.RDataCNAME{DomainName: "_sip._tcp.example.com."}),
+		newRecord("_xmpp._tcp.example.com.", dns.SRV, &dns.RDataSRV{Target: "."}),
+	})
+
+	addresses, err := resolver.LookupA("example.com.")
+	if err != nil || !reflect.DeepEqual(addresses, []netip.Addr{netip.MustParseAddr("192.0.2.1")}) {
+		t.Errorf("LookupA() got = %v, error = %v\n", addresses, err)
+	}
+	addresses, err = resolver.LookupAAAA("example.com.")
+	if err != nil || !reflect.DeepEqual(addresses, []netip.Addr{netip.MustParseAddr("2001:db8::1")}) {
+		t.Errorf("LookupAAAA() got = %v, error = %v\n", addresses, err)
+	}
+
+	exchanges, err := resolver.LookupMX("example.com.")
+	wantExchanges := []dns.RDataMX{{Preference: 10, Exchange: "mail.example.com."}, {Preference: 20, Exchange: "backup.example.com."}}
+	if err != nil || !reflect.DeepEqual(exchanges, wantExchanges) {
+		t.Errorf("LookupMX() got = %v, error = %v, want = %v\n", exchanges, err, wantExchanges)
+	}
+
+	texts, err := resolver.LookupTXT("example.com.")
+	if err != nil || !reflect.DeepEqual(texts, []string{"v=spf1 -all"}) {
+		t.Errorf("LookupTXT() got = %q, error = %v\n", texts, err)
+	}
+	servers, err := resolver.LookupNS("example.com.")
+	if err != nil || !reflect.DeepEqual(servers, []string{"ns.example.com."}) {
+		t.Errorf("LookupNS() got = %v, error = %v\n", servers, err)
+	}
+	names, err := resolver.LookupPTR(netip.MustParseAddr("192.0.2.1"))
+	if err != nil || !reflect.DeepEqual(names, []string{"example.com."}) {
+		t.Errorf("LookupPTR() got = %v, error = %v\n", names, err)
+	}
+
+	// The server does not follow the CNAME record: the resolver does
+	addresses, err = resolver.LookupA("www.example.com.")
+	if err != nil || !reflect.DeepEqual(addresses, []netip.Addr{netip.MustParseAddr("192.0.2.1")}) {
+		t.Errorf("LookupA() through CNAME got = %v, error = %v\n", addresses, err)
+	}
+	// The SRV lookups follow CNAME records too
+	wantSRV := []dns.RDataSRV{{Priority: 10, Port: 5060, Target: "sip1.example.com."}, {Priority: 20, Port: 5061, Target: "sip2.example.com."}}
+	srv, err := resolver.LookupSRV("sips", "tcp", "example.com.")
+	if err != nil || !reflect.DeepEqual(srv, wantSRV) {
+		t.Errorf("LookupSRV() through CNAME got = %v, error = %v, want = %v\n", srv, err, wantSRV)
+	}
+	if _, err = resolver.LookupSRV("xmpp", "tcp", "example.com."); !errors.Is(err, client.ErrServiceUnavailable) {
+		t.Errorf("LookupSRV() error = %v, want error = %v\n", err, client.ErrServiceUnavailable)
+	}
+	// No records of the type
+	servers, err = resolver.LookupNS("1.2.0.192.in-addr.arpa.")
+	if err != nil || servers != nil {
+		t.Errorf("LookupNS() without records got = %v, error = %v\n", servers, err)
+	}
+	if _, err = resolver.LookupA("missing.example.com."); !errors.Is(err, ErrNameNotFound) {
+		t.Errorf("LookupA() error = %v, want error = %v\n", err, ErrNameNotFound)
+	}
+}
+
+func TestNewResolverFromConfig(t *testing.T) {
+	conf := sysconfig.DefaultResolvConf()
+	if _, err := NewResolverFromConfig(conf); !errors.Is(err, sysconfig.ErrNoResolvers) {
+		t.Errorf("NewResolverFromConfig() error = %v, want error = %v\n", err, sysconfig.ErrNoResolvers)
+	}
+
+	conf.Nameservers = []string{"192.0.2.53", "2001:db8::53"}
+	conf.Timeout = 3 * time.Second
+	conf.Attempts = 3
+	conf.Rotate = true
+	resolver, err := NewResolverFromConfig(conf)
+	if err != nil {
+		t.Fatalf("NewResolverFromConfig() unexpected error = %v\n", err)
+	}
+	if resolver.Client.Server != "192.0.2.53:53" || len(resolver.Fallbacks) != 1 || resolver.Fallbacks[0].Server != "[2001:db8::53]:53" {
+		t.Errorf("NewResolverFromConfig() got servers = %v and %v\n", resolver.Client.Server, resolver.Fallbacks)
+	}
+	if resolver.Client.Timeout != 3*time.Second || resolver.Client.Retries != 2 || !resolver.Rotate {
+		t.Errorf("NewResolverFromConfig() got timeout = %v, retries = %d, rotate = %t\n", resolver.Client.Timeout, resolver.Client.Retries, resolver.Rotate)
+	}
+
+	// Rotation starts with the next server on each query
+	first := resolver.getClients()[0]
+	if second := resolver.getClients()[0]; first == second {
+		t.Errorf("getClients() got the same first server twice: %v\n", first.Server)
+	}
+}
+
+func TestResolverFallbacks(t *testing.T) {
+	working := startTestServer(t, []dns.ResourceRecord{
+		{Name: "example.com.", RType: dns.A, RClass: dns.IN, TTL: 300, RData: &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")}},
+	})
+
+	// Nothing listens on the port of a closed socket: the query is refused
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen UDP: %v", err)
+	}
+	packetConn.Close()
+	resolver := NewResolver(packetConn.LocalAddr().String())
+	resolver.Fallbacks = []*client.Client{working.Client}
+
+	addresses, err := resolver.LookupA("example.com.")
+	if err != nil || !reflect.DeepEqual(addresses, []netip.Addr{netip.MustParseAddr("192.0.2.1")}) {
+		t.Errorf("LookupA() got = %v, error = %v\n", addresses, err)
+	}
+}
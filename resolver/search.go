@@ -0,0 +1,101 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+// Search list of stub resolvers, see resolv.conf(5): a name with fewer dots than ndots is
+// likely relative, ex. "www" in the "example.com" domain, so it is tried with each search
+// domain appended before it is tried as is. A name with at least ndots dots is tried as is
+// first. A fully qualified name, ending with a dot, is only tried as is.
+
+// SearchNames returns the fully qualified names to try for a name, in order.
+//
+// Parameters:
+//   - name: The name to look up, ex. "www".
+//   - search: The search domains, ex. ["example.com", "corp.example.com"].
+//   - ndots: The number of dots from which the name is tried as is first, ex. 1.
+//
+// Returns:
+//   - []string: The names to try, ex. ["www.example.com.", "www.corp.example.com.", "www."].
+func SearchNames(name string, search []string, ndots int) []string {
+	if strings.HasSuffix(name, ".") {
+		return []string{name}
+	}
+
+	names := make([]string, 0, len(search)+1)
+	absolute := strings.Count(name, ".") >= ndots
+	if absolute {
+		names = append(names, name+".")
+	}
+	for _, domain := range search {
+		domain = strings.Trim(domain, ".")
+		if domain != "" {
+			names = append(names, name+"."+domain+".")
+		}
+	}
+	if !absolute {
+		names = append(names, name+".")
+	}
+	return names
+}
+
+// Query looks up the records of a type of a name, trying the names of its search list in turn,
+// see SearchNames, until one of them has records of the type.
+//
+// Parameters:
+//   - name: The name to look up, ex. "www".
+//   - qtype: The DNS record type to look up.
+//
+// Returns:
+//   - string: The name which answered, ex. "www.example.com.". If none of the names has records
+//     of the type but one exists, the first name which exists.
+//   - dns.Message: The response to the query for that name. It may be shared with the lookups
+//     of the same query at the same time, so it must not be modified.
+//   - error: If a query failed, or ErrNameNotFound if none of the names exists.
+func (resolver *Resolver) Query(name string, qtype uint16) (answered string, response dns.Message, err error) {
+	answered, response, _, err = resolver.query(context.Background(), name, qtype)
+	return answered, response, err
+}
+
+// query is Query, also returning the metadata of the queries sent for each name tried, in order.
+func (resolver *Resolver) query(ctx context.Context, name string, qtype uint16) (answered string, response dns.Message, queries []QueryInfo, err error) {
+	found := false
+	for _, candidate := range SearchNames(name, resolver.Search, resolver.Ndots) {
+		candidateResponse, info, err := resolver.exchange(ctx, candidate, qtype)
+		queries = append(queries, info)
+		if errors.Is(err, ErrNameNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", dns.Message{}, queries, err
+		}
+
+		if hasAnswers(candidateResponse, qtype) {
+			return candidate, candidateResponse, queries, nil
+		}
+		if !found {
+			// The name exists without records of the type: only kept if no other name has some
+			answered, response, found = candidate, candidateResponse, true
+		}
+	}
+
+	if !found {
+		return "", dns.Message{}, queries, errorNameNotFound(name)
+	}
+	return answered, response, queries, nil
+}
+
+// hasAnswers reports whether a response has answers of a type.
+func hasAnswers(response dns.Message, qtype uint16) bool {
+	for _, record := range response.Answers {
+		if record.RType == qtype {
+			return true
+		}
+	}
+	return false
+}
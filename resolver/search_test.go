@@ -0,0 +1,117 @@
+package resolver
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestSearchNames(t *testing.T) {
+	search := []string{"example.com", "corp.example.com."}
+	tests := []struct {
+		name   string
+		data   string
+		search []string
+		ndots  int
+		want   []string
+	}{
+		{
+			name:   "Fewer dots than ndots",
+			data:   "www",
+			search: search,
+			ndots:  1,
+			want:   []string{"www.example.com.", "www.corp.example.com.", "www."},
+		},
+		{
+			name:   "As many dots as ndots",
+			data:   "www.example",
+			search: search,
+			ndots:  1,
+			want:   []string{"www.example.", "www.example.example.com.", "www.example.corp.example.com."},
+		},
+		{
+			name:   "Fully qualified",
+			data:   "www.",
+			search: search,
+			ndots:  1,
+			want:   []string{"www."},
+		},
+		{
+			name:   "No search domains",
+			data:   "www",
+			search: nil,
+			ndots:  1,
+			want:   []string{"www."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SearchNames(tt.data, tt.search, tt.ndots)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchNames() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolverQuery(t *testing.T) {
+	newRecord := func(name string, rtype uint16, rdata dns.RData) dns.ResourceRecord {
+		return dns.ResourceRecord{Name: name, RType: rtype, RClass: dns.IN, TTL: 300, RData: rdata}
+	}
+	resolver := startTestServer(t, []dns.ResourceRecord{
+		newRecord("www.corp.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")}),
+		newRecord("mail.example.com.", dns.MX, &dns.RDataMX{Preference: 10, Exchange: "mail.example.com."}),
+		newRecord("mail.corp.example.com.", dns.A, &dns.RDataA{IP: netip.MustParseAddr("192.0.2.2")}),
+	})
+	resolver.Search = []string{"example.com", "corp.example.com"}
+	resolver.Ndots = 1
+
+	tests := []struct {
+		name      string
+		data      string
+		qtype     uint16
+		want      string
+		wantError error
+	}{
+		{
+			name:  "Found in the second search domain",
+			data:  "www",
+			qtype: dns.A,
+			want:  "www.corp.example.com.",
+		},
+		{
+			name:  "Records in a later search domain",
+			data:  "mail",
+			qtype: dns.A,
+			want:  "mail.corp.example.com.",
+		},
+		{
+			name:  "No records in any search domain",
+			data:  "mail",
+			qtype: dns.AAAA,
+			want:  "mail.example.com.",
+		},
+		{
+			name:      "Not found",
+			data:      "missing",
+			qtype:     dns.A,
+			wantError: ErrNameNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := resolver.Query(tt.data, tt.qtype)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("Query() error = %v, want error = %v\n", err, tt.wantError)
+			}
+			if got != tt.want {
+				t.Errorf("Query() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package resolver
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mcombeau/dns-tools/client"
+)
+
+// Selection of the servers a query is sent to, among the client's server and the fallbacks.
+// Like recursive resolvers, the resolver keeps the smoothed round trip time (SRTT) of each server,
+// updated with each response as in TCP [RFC6298]: SRTT = 7/8 SRTT + 1/8 RTT. A query which fails,
+// or gets SERVFAIL, REFUSED or NOTIMP, counts as a failure, with the time waited as its RTT, so that
+// servers which time out soon look slow. The failure rate is smoothed the same way.
+//
+// The policy of the resolver then orders the servers for each query:
+//
+//   - PolicySequential tries them in order, starting with the next one each time if the resolver rotates.
+//   - PolicyFastest tries them by SRTT, servers not queried yet first so that they are measured,
+//     those which fail more often than not last, like BIND.
+//   - PolicyWeightedRandom picks each one at random, weighted by its speed and success rate,
+//     so that slower servers still get some of the queries and their SRTT stays current, like Unbound.
+
+// SelectionPolicy is the order in which a resolver tries its servers.
+type SelectionPolicy int
+
+const (
+	PolicySequential     SelectionPolicy = iota // In order, rotating if the resolver rotates
+	PolicyFastest                               // By SRTT, fastest first
+	PolicyWeightedRandom                        // At random, weighted by speed and success rate
+)
+
+var selectionPolicyNames = map[SelectionPolicy]string{
+	PolicySequential:     "sequential",
+	PolicyFastest:        "fastest",
+	PolicyWeightedRandom: "weighted-random",
+}
+
+func (policy SelectionPolicy) String() string {
+	if name, ok := selectionPolicyNames[policy]; ok {
+		return name
+	}
+	return fmt.Sprintf("SelectionPolicy(%d)", int(policy))
+}
+
+// ParseSelectionPolicy returns the policy of a name, ex. "fastest".
+func ParseSelectionPolicy(name string) (SelectionPolicy, error) {
+	for policy, policyName := range selectionPolicyNames {
+		if name == policyName {
+			return policy, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown selection policy: %s", name)
+}
+
+// ServerStats are the statistics of the queries sent to a server.
+type ServerStats struct {
+	Server      string        // Address of the server, ex. "192.0.2.53:53"
+	Queries     int           // Number of queries sent
+	Failures    int           // Number of queries which failed
+	SRTT        time.Duration // Smoothed round trip time
+	FailureRate float64       // Smoothed failure rate, from 0 to 1
+}
+
+// serverStats are the statistics of the servers of a resolver. The zero value is ready to use.
+type serverStats struct {
+	mutex   sync.Mutex
+	servers map[string]*ServerStats
+}
+
+// record updates the statistics of a server with the outcome of a query.
+func (stats *serverStats) record(server string, rtt time.Duration, failed bool) {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	if stats.servers == nil {
+		stats.servers = map[string]*ServerStats{}
+	}
+	serverStats, ok := stats.servers[server]
+	if !ok {
+		serverStats = &ServerStats{Server: server, SRTT: rtt}
+		stats.servers[server] = serverStats
+	}
+
+	failure := 0.0
+	if failed {
+		failure = 1
+		serverStats.Failures++
+	}
+	if ok {
+		serverStats.SRTT += (rtt - serverStats.SRTT) / 8
+		serverStats.FailureRate += (failure - serverStats.FailureRate) / 8
+	} else {
+		serverStats.FailureRate = failure
+	}
+	serverStats.Queries++
+}
+
+// get returns the statistics of a server, and whether it was queried.
+func (stats *serverStats) get(server string) (ServerStats, bool) {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	if serverStats, ok := stats.servers[server]; ok {
+		return *serverStats, true
+	}
+	return ServerStats{Server: server}, false
+}
+
+// ServerStats returns the statistics of the servers of the resolver, in the order they are configured.
+// The servers not queried yet have none.
+func (resolver *Resolver) ServerStats() []ServerStats {
+	clients := append([]*client.Client{resolver.Client}, resolver.Fallbacks...)
+	stats := make([]ServerStats, 0, len(clients))
+	for _, dnsClient := range clients {
+		serverStats, _ := resolver.stats.get(dnsClient.Server)
+		stats = append(stats, serverStats)
+	}
+	return stats
+}
+
+// getClients returns the clients of the resolver in the order they are tried, see SelectionPolicy.
+func (resolver *Resolver) getClients() []*client.Client {
+	clients := append([]*client.Client{resolver.Client}, resolver.Fallbacks...)
+	switch resolver.Policy {
+	case PolicyFastest:
+		return resolver.sortFastest(clients)
+	case PolicyWeightedRandom:
+		return resolver.shuffleWeighted(clients)
+	}
+
+	if !resolver.Rotate {
+		return clients
+	}
+	start := int((resolver.next.Add(1) - 1) % uint32(len(clients)))
+	return slices.Concat(clients[start:], clients[:start])
+}
+
+// sortFastest sorts clients by the SRTT of their servers: those not queried yet first,
+// and those which fail more often than not last.
+func (resolver *Resolver) sortFastest(clients []*client.Client) []*client.Client {
+	type rank struct {
+		queried bool
+		failing bool
+		srtt    time.Duration
+	}
+	ranks := map[*client.Client]rank{}
+	for _, dnsClient := range clients {
+		stats, queried := resolver.stats.get(dnsClient.Server)
+		ranks[dnsClient] = rank{queried, stats.FailureRate > 0.5, stats.SRTT}
+	}
+
+	slices.SortStableFunc(clients, func(a *client.Client, b *client.Client) int {
+		rankA, rankB := ranks[a], ranks[b]
+		switch {
+		case rankA.queried != rankB.queried:
+			if !rankA.queried {
+				return -1
+			}
+			return 1
+		case rankA.failing != rankB.failing:
+			if rankB.failing {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(rankA.srtt, rankB.srtt)
+	})
+	return clients
+}
+
+// shuffleWeighted orders clients at random, picking each one with a probability proportional to
+// the speed and success rate of its server: 1/SRTT times the success rate, the servers not queried
+// yet weighing as much as the fastest.
+func (resolver *Resolver) shuffleWeighted(clients []*client.Client) []*client.Client {
+	weights := make([]float64, len(clients))
+	maxWeight := 0.0
+	for i, dnsClient := range clients {
+		stats, queried := resolver.stats.get(dnsClient.Server)
+		if !queried {
+			weights[i] = -1
+			continue
+		}
+		// At least a millisecond, and a small chance for failing servers to be measured again
+		weights[i] = (1 - stats.FailureRate + 0.01) / max(stats.SRTT.Seconds(), 0.001)
+		maxWeight = max(maxWeight, weights[i])
+	}
+	for i := range weights {
+		if weights[i] < 0 {
+			weights[i] = max(maxWeight, 1)
+		}
+	}
+
+	ordered := make([]*client.Client, 0, len(clients))
+	for len(clients) > 0 {
+		total := 0.0
+		for _, weight := range weights {
+			total += weight
+		}
+		pick := rand.Float64() * total
+		i := 0
+		for ; i < len(weights)-1 && pick >= weights[i]; i++ {
+			pick -= weights[i]
+		}
+		ordered = append(ordered, clients[i])
+		clients = slices.Delete(clients, i, i+1)
+		weights = slices.Delete(weights, i, i+1)
+	}
+	return ordered
+}
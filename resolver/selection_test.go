@@ -0,0 +1,134 @@
+package resolver
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/client"
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+func TestServerStatsRecord(t *testing.T) {
+	var stats serverStats
+	stats.record("192.0.2.53:53", 100*time.Millisecond, false)
+	stats.record("192.0.2.53:53", 200*time.Millisecond, true)
+
+	got, ok := stats.get("192.0.2.53:53")
+	want := ServerStats{Server: "192.0.2.53:53", Queries: 2, Failures: 1, SRTT: 112500 * time.Microsecond, FailureRate: 0.125}
+	if !ok || got != want {
+		t.Errorf("record() got = %+v, want = %+v\n", got, want)
+	}
+	if _, ok := stats.get("192.0.2.54:53"); ok {
+		t.Errorf("get() got stats for a server never queried\n")
+	}
+}
+
+func TestParseSelectionPolicy(t *testing.T) {
+	for _, policy := range []SelectionPolicy{PolicySequential, PolicyFastest, PolicyWeightedRandom} {
+		got, err := ParseSelectionPolicy(policy.String())
+		if err != nil || got != policy {
+			t.Errorf("ParseSelectionPolicy(%q) got = %v, error = %v\n", policy.String(), got, err)
+		}
+	}
+	if _, err := ParseSelectionPolicy("random"); err == nil {
+		t.Errorf("ParseSelectionPolicy(\"random\") got no error\n")
+	}
+}
+
+func newPolicyResolver(policy SelectionPolicy) *Resolver {
+	resolver := NewResolver("192.0.2.1:53")
+	resolver.Fallbacks = []*client.Client{client.NewClient("192.0.2.2:53"), client.NewClient("192.0.2.3:53"), client.NewClient("192.0.2.4:53")}
+	resolver.Policy = policy
+	resolver.stats.record("192.0.2.1:53", 80*time.Millisecond, false)
+	resolver.stats.record("192.0.2.2:53", 10*time.Millisecond, true)
+	resolver.stats.record("192.0.2.3:53", 20*time.Millisecond, false)
+	return resolver
+}
+
+func getServers(clients []*client.Client) []string {
+	servers := make([]string, 0, len(clients))
+	for _, dnsClient := range clients {
+		servers = append(servers, dnsClient.Server)
+	}
+	return servers
+}
+
+func TestSelectFastest(t *testing.T) {
+	resolver := newPolicyResolver(PolicyFastest)
+
+	// Not queried yet first, failing last
+	got := getServers(resolver.getClients())
+	want := []string{"192.0.2.4:53", "192.0.2.3:53", "192.0.2.1:53", "192.0.2.2:53"}
+	if len(got) != len(want) {
+		t.Fatalf("getClients() got = %v, want = %v\n", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("getClients() got = %v, want = %v\n", got, want)
+			break
+		}
+	}
+}
+
+func TestSelectWeightedRandom(t *testing.T) {
+	resolver := newPolicyResolver(PolicyWeightedRandom)
+
+	firsts := map[string]int{}
+	for range 1000 {
+		servers := getServers(resolver.getClients())
+		if len(servers) != 4 {
+			t.Fatalf("getClients() got = %v, want 4 servers\n", servers)
+		}
+		seen := map[string]bool{}
+		for _, server := range servers {
+			seen[server] = true
+		}
+		if len(seen) != 4 {
+			t.Fatalf("getClients() got = %v, want each server once\n", servers)
+		}
+		firsts[servers[0]]++
+	}
+
+	// Weights: 1/0.08, ~0.13/0.01, 1/0.02 and 50 for the server not queried yet
+	if firsts["192.0.2.3:53"] <= firsts["192.0.2.1:53"] || firsts["192.0.2.4:53"] <= firsts["192.0.2.1:53"] {
+		t.Errorf("getClients() got first servers = %v, want the fastest most often\n", firsts)
+	}
+	if firsts["192.0.2.1:53"] == 0 {
+		t.Errorf("getClients() never started with the slowest server: %v\n", firsts)
+	}
+}
+
+func TestResolverRecordsServerStats(t *testing.T) {
+	working := startTestServer(t, []dns.ResourceRecord{
+		{Name: "example.com.", RType: dns.A, RClass: dns.IN, TTL: 300, RData: &dns.RDataA{IP: netip.MustParseAddr("192.0.2.1")}},
+	})
+
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen UDP: %v", err)
+	}
+	packetConn.Close()
+	resolver := NewResolver(packetConn.LocalAddr().String())
+	resolver.Fallbacks = []*client.Client{working.Client}
+	resolver.Policy = PolicyFastest
+
+	for range 2 {
+		if _, err := resolver.LookupA("example.com."); err != nil {
+			t.Fatalf("LookupA() got error = %v\n", err)
+		}
+	}
+
+	// The closed port fails the first query, and is tried last once the working server is measured
+	stats := resolver.ServerStats()
+	if len(stats) != 2 {
+		t.Fatalf("ServerStats() got = %+v, want 2 servers\n", stats)
+	}
+	if stats[0].Queries != 1 || stats[0].Failures != 1 || stats[0].FailureRate != 1 {
+		t.Errorf("ServerStats() got = %+v for the closed port, want 1 failed query\n", stats[0])
+	}
+	if stats[1].Queries != 2 || stats[1].Failures != 0 || stats[1].SRTT <= 0 {
+		t.Errorf("ServerStats() got = %+v for the working server, want 2 queries\n", stats[1])
+	}
+}
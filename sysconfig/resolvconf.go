@@ -0,0 +1,141 @@
+package sysconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Resolver configuration file of Unix systems, see resolv.conf(5):
+//
+//	# comment
+//	nameserver 192.0.2.53
+//	nameserver 2001:db8::53
+//	search example.com corp.example.com
+//	options ndots:2 timeout:3 attempts:4 rotate
+//
+// Like the C library, at most maxResolvConfNameservers nameservers are used, the last
+// "search" or "domain" line wins, and the options are capped to the limits of the C library.
+// The environment variables LOCALDOMAIN and RES_OPTIONS and the domain of the host name,
+// used as a search domain when there is none, are not taken into account.
+
+// ResolvConfPath is the path of the resolver configuration file on Unix systems.
+const ResolvConfPath = "/etc/resolv.conf"
+
+const (
+	maxResolvConfNameservers = 3  // MAXNS
+	maxResolvConfNdots       = 15 // RES_MAXNDOTS
+	maxResolvConfTimeout     = 30 // RES_MAXRETRANS, in seconds
+	maxResolvConfAttempts    = 5  // RES_MAXRETRY
+)
+
+// ResolvConf is the resolver configuration read from a resolv.conf file.
+type ResolvConf struct {
+	Nameservers []string      // IP addresses of the name servers, in order of preference
+	Search      []string      // Domains to search for names with fewer than Ndots dots
+	Ndots       int           // Number of dots from which a name is first tried as is
+	Timeout     time.Duration // Time to wait for a response from a name server
+	Attempts    int           // Number of times each name server is queried before giving up
+	Rotate      bool          // Spread the queries across the name servers instead of starting with the first
+}
+
+// DefaultResolvConf returns the configuration of a resolv.conf file without any line:
+// no name server or search domain, an ndots of 1, a five second timeout and two attempts.
+func DefaultResolvConf() ResolvConf {
+	return ResolvConf{
+		Ndots:    1,
+		Timeout:  5 * time.Second,
+		Attempts: 2,
+	}
+}
+
+// ReadResolvConf reads a resolver configuration file.
+//
+// Parameters:
+//   - path: The path of the file, ex. ResolvConfPath.
+//
+// Returns:
+//   - ResolvConf: The configuration, with the defaults for what the file does not set.
+//   - error: If the file cannot be read.
+func ReadResolvConf(path string) (ResolvConf, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ResolvConf{}, fmt.Errorf("cannot open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	conf, err := ParseResolvConf(file)
+	if err != nil {
+		return ResolvConf{}, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return conf, nil
+}
+
+// ParseResolvConf parses a resolver configuration in the resolv.conf format.
+// Invalid lines and options, ex. a nameserver which is not an IP address, are skipped.
+//
+// Parameters:
+//   - reader: The contents of the configuration file.
+//
+// Returns:
+//   - ResolvConf: The configuration, with the defaults for what the file does not set.
+//   - error: If the configuration cannot be read.
+func ParseResolvConf(reader io.Reader) (ResolvConf, error) {
+	conf := DefaultResolvConf()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+
+		switch fields[0] {
+		case "nameserver":
+			if len(fields) > 1 && isIPAddress(fields[1]) && len(conf.Nameservers) < maxResolvConfNameservers {
+				conf.Nameservers = append(conf.Nameservers, fields[1])
+			}
+		case "domain":
+			if len(fields) > 1 {
+				conf.Search = []string{fields[1]}
+			}
+		case "search":
+			conf.Search = append([]string(nil), fields[1:]...)
+		case "options":
+			for _, option := range fields[1:] {
+				parseResolvConfOption(&conf, option)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return ResolvConf{}, err
+	}
+	return conf, nil
+}
+
+// parseResolvConfOption sets an option of the configuration, ex. "ndots:2".
+func parseResolvConfOption(conf *ResolvConf, option string) {
+	name, value, _ := strings.Cut(option, ":")
+	if name == "rotate" {
+		conf.Rotate = true
+		return
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return
+	}
+	switch name {
+	case "ndots":
+		conf.Ndots = min(n, maxResolvConfNdots)
+	case "timeout":
+		conf.Timeout = time.Duration(max(1, min(n, maxResolvConfTimeout))) * time.Second
+	case "attempts":
+		conf.Attempts = max(1, min(n, maxResolvConfAttempts))
+	}
+}
@@ -0,0 +1,72 @@
+package sysconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseResolvConf(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want ResolvConf
+	}{
+		{
+			name: "Empty",
+			data: "",
+			want: DefaultResolvConf(),
+		},
+		{
+			name: "All directives",
+			data: "# Generated by NetworkManager\n" +
+				"nameserver 192.0.2.53\n" +
+				"nameserver 2001:db8::53\n" +
+				"search example.com corp.example.com\n" +
+				"options ndots:2 timeout:3 attempts:4 rotate edns0\n",
+			want: ResolvConf{
+				Nameservers: []string{"192.0.2.53", "2001:db8::53"},
+				Search:      []string{"example.com", "corp.example.com"},
+				Ndots:       2,
+				Timeout:     3 * time.Second,
+				Attempts:    4,
+				Rotate:      true,
+			},
+		},
+		{
+			name: "Last search line wins",
+			data: "search example.com\n; comment\ndomain corp.example.com\n",
+			want: ResolvConf{
+				Search:   []string{"corp.example.com"},
+				Ndots:    1,
+				Timeout:  5 * time.Second,
+				Attempts: 2,
+			},
+		},
+		{
+			name: "Limits",
+			data: "nameserver 192.0.2.1\nnameserver 192.0.2.2\nnameserver 192.0.2.3\nnameserver 192.0.2.4\n" +
+				"options ndots:20 timeout:0 attempts:10 ndots:invalid\n",
+			want: ResolvConf{
+				Nameservers: []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"},
+				Ndots:       15,
+				Timeout:     1 * time.Second,
+				Attempts:    5,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseResolvConf(strings.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("ParseResolvConf() unexpected error = %v\n", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseResolvConf() got = %+v, want = %+v\n", got, tt.want)
+			}
+		})
+	}
+}
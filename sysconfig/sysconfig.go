@@ -0,0 +1,178 @@
+// Package sysconfig discovers the DNS resolvers configured on the host system.
+//
+// Resolvers are read from /etc/resolv.conf on Unix systems, from the system
+// configuration (scutil) on macOS and from the registry on Windows, see Load.
+package sysconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoResolvers = fmt.Errorf("no system DNS resolver found")
+)
+
+// Load returns the resolver configuration of this system: the name servers in order of
+// preference, the search domains and the options which the system configuration sets,
+// with the defaults of DefaultResolvConf for the others. It reads /etc/resolv.conf on Unix
+// systems, the output of "scutil --dns" on macOS, falling back to /etc/resolv.conf, and
+// the TCP/IP parameters of the registry on Windows.
+//
+// Returns:
+//   - ResolvConf: The configuration.
+//   - error: If the system configuration cannot be read, ErrNoResolvers if it holds no resolver.
+func Load() (ResolvConf, error) {
+	conf, err := loadSystemConfig()
+	if err != nil {
+		return ResolvConf{}, err
+	}
+	if len(conf.Nameservers) == 0 {
+		return ResolvConf{}, ErrNoResolvers
+	}
+	return conf, nil
+}
+
+// GetSystemResolvers returns the IP addresses of the DNS resolvers configured
+// on this system, in order of preference, see Load.
+//
+// Returns:
+//   - []string: The resolver IP addresses, ex. ["192.168.1.1", "2001:db8::1"].
+//   - error: If the system configuration cannot be read or holds no resolver.
+func GetSystemResolvers() (resolvers []string, err error) {
+	conf, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return conf.Nameservers, nil
+}
+
+// parseScutilConfig reads the configuration of the default resolvers from the output of "scutil --dns" on macOS:
+//
+//	DNS configuration
+//
+//	resolver #1
+//	  search domain[0] : example.com
+//	  nameserver[0] : 192.168.1.1
+//	  nameserver[1] : 2001:db8::1
+//	  timeout  : 5
+//	  ...
+//
+//	resolver #2
+//	  domain   : local
+//	  ...
+//
+// Only resolvers without a "domain" entry apply to all queries: the others are
+// used for specific domains only (ex. mDNS for "local"). The name servers of the
+// default resolvers are merged, and the search domains and timeout are those of the first one setting them.
+func parseScutilConfig(reader io.Reader) (conf ResolvConf, err error) {
+	conf = DefaultResolvConf()
+	scanner := bufio.NewScanner(reader)
+
+	var current, currentSearch []string
+	var currentTimeout time.Duration
+	scoped := false
+	searchSet, timeoutSet := false, false
+
+	flush := func() {
+		if !scoped {
+			conf.Nameservers = appendUnique(conf.Nameservers, current...)
+			if !searchSet && len(currentSearch) > 0 {
+				conf.Search, searchSet = currentSearch, true
+			}
+			if !timeoutSet && currentTimeout > 0 {
+				conf.Timeout, timeoutSet = currentTimeout, true
+			}
+		}
+		current, currentSearch, currentTimeout = nil, nil, 0
+		scoped = false
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "DNS configuration (for scoped queries)") {
+			// Scoped resolvers duplicate the ones above for a specific interface
+			break
+		}
+
+		if strings.HasPrefix(line, "resolver #") {
+			flush()
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch {
+		case key == "domain":
+			scoped = true
+		case strings.HasPrefix(key, "nameserver["):
+			if isIPAddress(value) {
+				current = append(current, value)
+			}
+		case strings.HasPrefix(key, "search domain["):
+			currentSearch = append(currentSearch, value)
+		case key == "timeout":
+			if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+				currentTimeout = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	flush()
+
+	if err = scanner.Err(); err != nil {
+		return ResolvConf{}, err
+	}
+
+	return conf, nil
+}
+
+// parseNameServerList splits a list of nameservers as stored in the
+// Windows registry, which can be separated by commas or spaces.
+func parseNameServerList(list string) (resolvers []string) {
+	for _, field := range splitRegistryList(list) {
+		if isIPAddress(field) {
+			resolvers = append(resolvers, field)
+		}
+	}
+	return resolvers
+}
+
+// splitRegistryList splits a list of values of the Windows registry, ex. of search domains,
+// which can be separated by commas or spaces.
+func splitRegistryList(list string) []string {
+	return strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\x00'
+	})
+}
+
+func isIPAddress(address string) bool {
+	_, err := netip.ParseAddr(address)
+	return err == nil
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
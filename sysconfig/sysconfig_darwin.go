@@ -0,0 +1,23 @@
+//go:build darwin
+
+package sysconfig
+
+import (
+	"bytes"
+	"os/exec"
+)
+
+// On macOS, /etc/resolv.conf is generated for compatibility but does not
+// reflect per-interface or VPN resolvers: the system configuration is authoritative.
+func loadSystemConfig() (ResolvConf, error) {
+	output, err := exec.Command("scutil", "--dns").Output()
+	if err == nil {
+		conf, err := parseScutilConfig(bytes.NewReader(output))
+		if err == nil && len(conf.Nameservers) > 0 {
+			return conf, nil
+		}
+	}
+
+	// Fall back to resolv.conf
+	return ReadResolvConf(ResolvConfPath)
+}
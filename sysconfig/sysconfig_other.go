@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package sysconfig
+
+import "fmt"
+
+func loadSystemConfig() (ResolvConf, error) {
+	return ResolvConf{}, fmt.Errorf("system resolver discovery is not supported on this platform: %w", ErrNoResolvers)
+}
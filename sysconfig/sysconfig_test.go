@@ -0,0 +1,142 @@
+package sysconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseResolvConfNameservers(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "Single nameserver",
+			data: "nameserver 192.168.1.1\n",
+			want: []string{"192.168.1.1"},
+		},
+		{
+			name: "Multiple nameservers with comments and options",
+			data: "# Generated by NetworkManager\n" +
+				"search example.com\n" +
+				"nameserver 1.1.1.1\n" +
+				"nameserver 2001:db8::1\n" +
+				"options ndots:2\n",
+			want: []string{"1.1.1.1", "2001:db8::1"},
+		},
+		{
+			name: "Invalid nameserver is skipped",
+			data: "nameserver not-an-ip\nnameserver 8.8.8.8\n",
+			want: []string{"8.8.8.8"},
+		},
+		{
+			name: "No nameserver",
+			data: "search example.com\n",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf, err := ParseResolvConf(strings.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("ParseResolvConf() unexpected error = %v\n", err)
+			}
+
+			if !reflect.DeepEqual(conf.Nameservers, tt.want) {
+				t.Errorf("ParseResolvConf() got nameservers = %v, want = %v\n", conf.Nameservers, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScutilConfig(t *testing.T) {
+	data := `DNS configuration
+
+resolver #1
+  search domain[0] : example.com
+  search domain[1] : corp.example.com
+  nameserver[0] : 192.168.1.1
+  nameserver[1] : 2001:db8::1
+  if_index : 6 (en0)
+  flags    : Request A records, Request AAAA records
+  reach    : 0x00020002 (Reachable,Directly Reachable Address)
+
+resolver #2
+  domain   : local
+  options  : mdns
+  timeout  : 5
+  flags    : Request A records, Request AAAA records
+  reach    : 0x00000000 (Not Reachable)
+  order    : 300000
+
+resolver #3
+  domain   : corp.example.com
+  nameserver[0] : 10.0.0.53
+
+resolver #4
+  search domain[0] : example.net
+  nameserver[0] : 192.168.1.1
+  nameserver[1] : 9.9.9.9
+  timeout  : 3
+
+DNS configuration (for scoped queries)
+
+resolver #1
+  nameserver[0] : 172.16.0.1
+`
+	want := []string{"192.168.1.1", "2001:db8::1", "9.9.9.9"}
+
+	got, err := parseScutilConfig(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("parseScutilConfig() unexpected error = %v\n", err)
+	}
+
+	if !reflect.DeepEqual(got.Nameservers, want) {
+		t.Errorf("parseScutilConfig() got nameservers = %v, want = %v\n", got.Nameservers, want)
+	}
+	// The search domains and timeout of the first default resolver setting them
+	if wantSearch := []string{"example.com", "corp.example.com"}; !reflect.DeepEqual(got.Search, wantSearch) {
+		t.Errorf("parseScutilConfig() got search = %v, want = %v\n", got.Search, wantSearch)
+	}
+	if got.Timeout != 3*time.Second || got.Ndots != 1 || got.Attempts != 2 {
+		t.Errorf("parseScutilConfig() got timeout = %v, ndots = %d, attempts = %d, want = 3s, 1 and 2\n", got.Timeout, got.Ndots, got.Attempts)
+	}
+}
+
+func TestParseNameServerList(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "Comma separated",
+			data: "1.1.1.1,8.8.8.8",
+			want: []string{"1.1.1.1", "8.8.8.8"},
+		},
+		{
+			name: "Space separated",
+			data: "192.168.1.1 fe80::1",
+			want: []string{"192.168.1.1", "fe80::1"},
+		},
+		{
+			name: "Empty",
+			data: "",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseNameServerList(tt.data)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseNameServerList() got = %v, want = %v\n", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+//go:build unix && !darwin
+
+package sysconfig
+
+func loadSystemConfig() (ResolvConf, error) {
+	return ReadResolvConf(ResolvConfPath)
+}
@@ -0,0 +1,133 @@
+//go:build windows
+
+package sysconfig
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// Windows stores the resolvers of each network interface in the registry,
+// either configured statically (NameServer) or obtained through DHCP (DhcpNameServer).
+var registryInterfacesKeys = []string{
+	`SYSTEM\CurrentControlSet\Services\Tcpip\Parameters\Interfaces`,
+	`SYSTEM\CurrentControlSet\Services\Tcpip6\Parameters\Interfaces`,
+}
+
+const registryParametersKey = `SYSTEM\CurrentControlSet\Services\Tcpip\Parameters`
+
+const errorNoMoreItems syscall.Errno = 259 // ERROR_NO_MORE_ITEMS
+
+// The search list is the SearchList of the global parameters if set, or else the primary
+// domain of the host and of its interfaces, also either static (Domain) or from DHCP (DhcpDomain).
+// Windows has no ndots: names with a dot are tried as is first, as is the default of resolv.conf.
+func loadSystemConfig() (ResolvConf, error) {
+	conf := DefaultResolvConf()
+
+	// Global resolvers take precedence over per-interface ones
+	conf.Nameservers = appendUnique(conf.Nameservers, readRegistryNameServers(registryParametersKey)...)
+	if searchList, err := readRegistryString(registryParametersKey, "SearchList"); err == nil {
+		conf.Search = appendUnique(conf.Search, splitRegistryList(searchList)...)
+	}
+	searchDomains := len(conf.Search) == 0
+	if searchDomains {
+		conf.Search = appendUnique(conf.Search, readRegistryDomains(registryParametersKey)...)
+	}
+
+	for _, interfacesKey := range registryInterfacesKeys {
+		interfaces, err := enumRegistrySubKeys(interfacesKey)
+		if err != nil {
+			continue
+		}
+		for _, iface := range interfaces {
+			conf.Nameservers = appendUnique(conf.Nameservers, readRegistryNameServers(interfacesKey+`\`+iface)...)
+			if searchDomains {
+				conf.Search = appendUnique(conf.Search, readRegistryDomains(interfacesKey+`\`+iface)...)
+			}
+		}
+	}
+
+	if len(conf.Nameservers) == 0 {
+		return ResolvConf{}, fmt.Errorf("no nameserver found in registry: %w", ErrNoResolvers)
+	}
+	return conf, nil
+}
+
+func readRegistryNameServers(path string) (resolvers []string) {
+	for _, valueName := range []string{"NameServer", "DhcpNameServer"} {
+		value, err := readRegistryString(path, valueName)
+		if err != nil {
+			continue
+		}
+		resolvers = appendUnique(resolvers, parseNameServerList(value)...)
+	}
+	return resolvers
+}
+
+func readRegistryDomains(path string) (domains []string) {
+	for _, valueName := range []string{"Domain", "DhcpDomain"} {
+		if value, err := readRegistryString(path, valueName); err == nil && value != "" {
+			domains = appendUnique(domains, value)
+		}
+	}
+	return domains
+}
+
+func openRegistryKey(path string) (key syscall.Handle, err error) {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	err = syscall.RegOpenKeyEx(syscall.HKEY_LOCAL_MACHINE, pathPtr, 0, syscall.KEY_READ, &key)
+	return key, err
+}
+
+func enumRegistrySubKeys(path string) (subKeys []string, err error) {
+	key, err := openRegistryKey(path)
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.RegCloseKey(key)
+
+	for index := uint32(0); ; index++ {
+		name := make([]uint16, 256)
+		nameLength := uint32(len(name))
+		err = syscall.RegEnumKeyEx(key, index, &name[0], &nameLength, nil, nil, nil, nil)
+		if err == errorNoMoreItems {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		subKeys = append(subKeys, syscall.UTF16ToString(name[:nameLength]))
+	}
+	return subKeys, nil
+}
+
+func readRegistryString(path string, valueName string) (value string, err error) {
+	key, err := openRegistryKey(path)
+	if err != nil {
+		return "", err
+	}
+	defer syscall.RegCloseKey(key)
+
+	namePtr, err := syscall.UTF16PtrFromString(valueName)
+	if err != nil {
+		return "", err
+	}
+
+	var valueType, size uint32
+	if err = syscall.RegQueryValueEx(key, namePtr, nil, &valueType, nil, &size); err != nil {
+		return "", err
+	}
+	if valueType != syscall.REG_SZ || size < 2 {
+		return "", nil
+	}
+
+	buffer := make([]uint16, size/2)
+	if err = syscall.RegQueryValueEx(key, namePtr, nil, &valueType, (*byte)(unsafe.Pointer(&buffer[0])), &size); err != nil {
+		return "", err
+	}
+	return syscall.UTF16ToString(buffer), nil
+}
@@ -0,0 +1,357 @@
+// Package trustanchor keeps the DNSSEC trust anchors of a zone up to date across key rollovers.
+//
+// A Store is bootstrapped from the IANA root anchors file, tracks the zone's key signing keys
+// with the automated updates state machine of RFC 5011, and is saved to disk between runs.
+package trustanchor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+var (
+	ErrInvalidAnchors = fmt.Errorf("invalid trust anchors")
+)
+
+// RootAnchorsURL is where IANA publishes the root zone trust anchors.
+// The file should be checked against its detached signature before use.
+const RootAnchorsURL = "https://data.iana.org/root-anchors/root-anchors.xml"
+
+// Hold-down times [RFC5011]: a new key must be seen for AddHoldDown before it is trusted,
+// and a revoked key is remembered for RemoveHoldDown before it is forgotten.
+const (
+	AddHoldDown    = 30 * 24 * time.Hour
+	RemoveHoldDown = 30 * 24 * time.Hour
+)
+
+// ------------------- KEY STATES
+type KeyState int
+
+const (
+	StateAddPend KeyState = iota + 1 // New key, waiting for the add hold-down time
+	StateValid                       // Trusted key
+	StateMissing                     // Trusted key, absent from the latest DNSKEY RRset
+	StateRevoked                     // Key revoked by its owner, no longer trusted
+)
+
+var keyStateNames = map[KeyState]string{
+	StateAddPend: "AddPend",
+	StateValid:   "Valid",
+	StateMissing: "Missing",
+	StateRevoked: "Revoked",
+}
+
+func (state KeyState) String() string {
+	if n, ok := keyStateNames[state]; ok {
+		return n
+	}
+	return "UNKNOWN"
+}
+
+func (state KeyState) MarshalText() ([]byte, error) {
+	if _, ok := keyStateNames[state]; !ok {
+		return nil, fmt.Errorf("invalid key state: %d", int(state))
+	}
+	return []byte(state.String()), nil
+}
+
+func (state *KeyState) UnmarshalText(text []byte) error {
+	for s, name := range keyStateNames {
+		if name == string(text) {
+			*state = s
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid key state: %s", text)
+}
+
+// ------------------- STORE
+
+// Anchor is a digest of a trusted key, as published in the root anchors file.
+type Anchor struct {
+	DS         dns.RDataDS
+	ValidFrom  time.Time
+	ValidUntil time.Time // Zero if the anchor has no end date
+}
+
+// Key is a key signing key of the zone tracked by the store.
+type Key struct {
+	Key   dns.RDataDNSKEY
+	State KeyState
+	Since time.Time // When the key entered its current state
+}
+
+// Store holds the trust anchors of a zone.
+type Store struct {
+	Zone    string
+	Anchors []Anchor // Initial trust anchors, which keys are checked against on bootstrap
+	Keys    []Key
+}
+
+// XML format of the root anchors file [RFC7958]
+type trustAnchorXML struct {
+	Zone       string         `xml:"Zone"`
+	KeyDigests []keyDigestXML `xml:"KeyDigest"`
+}
+
+type keyDigestXML struct {
+	ValidFrom  string `xml:"validFrom,attr"`
+	ValidUntil string `xml:"validUntil,attr"`
+	KeyTag     uint16 `xml:"KeyTag"`
+	Algorithm  uint8  `xml:"Algorithm"`
+	DigestType uint8  `xml:"DigestType"`
+	Digest     string `xml:"Digest"`
+}
+
+// ParseRootAnchors creates a store from a trust anchors file in the IANA XML format [RFC7958].
+//
+// Parameters:
+//   - reader: The content of the trust anchors file, ex. from RootAnchorsURL.
+//
+// Returns:
+//   - *Store: A store with the file's anchors and no tracked keys yet.
+//   - error: If the file cannot be parsed.
+func ParseRootAnchors(reader io.Reader) (*Store, error) {
+	var trustAnchor trustAnchorXML
+	if err := xml.NewDecoder(reader).Decode(&trustAnchor); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAnchors, err.Error())
+	}
+
+	store := &Store{Zone: trustAnchor.Zone}
+	for _, keyDigest := range trustAnchor.KeyDigests {
+		anchor, err := getAnchor(keyDigest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key tag %d: %s", ErrInvalidAnchors, keyDigest.KeyTag, err.Error())
+		}
+		store.Anchors = append(store.Anchors, anchor)
+	}
+
+	if store.Zone == "" || len(store.Anchors) == 0 {
+		return nil, fmt.Errorf("%w: no zone or key digest", ErrInvalidAnchors)
+	}
+	return store, nil
+}
+
+func getAnchor(keyDigest keyDigestXML) (anchor Anchor, err error) {
+	digest, err := hex.DecodeString(strings.TrimSpace(keyDigest.Digest))
+	if err != nil {
+		return Anchor{}, fmt.Errorf("invalid digest: %w", err)
+	}
+	anchor.DS = dns.RDataDS{
+		KeyTag:     keyDigest.KeyTag,
+		Algorithm:  keyDigest.Algorithm,
+		DigestType: keyDigest.DigestType,
+		Digest:     digest,
+	}
+
+	anchor.ValidFrom, err = time.Parse(time.RFC3339, keyDigest.ValidFrom)
+	if err != nil {
+		return Anchor{}, fmt.Errorf("invalid validFrom: %w", err)
+	}
+	if keyDigest.ValidUntil != "" {
+		anchor.ValidUntil, err = time.Parse(time.RFC3339, keyDigest.ValidUntil)
+		if err != nil {
+			return Anchor{}, fmt.Errorf("invalid validUntil: %w", err)
+		}
+	}
+
+	anchor.ValidFrom = anchor.ValidFrom.UTC()
+	anchor.ValidUntil = anchor.ValidUntil.UTC()
+	return anchor, nil
+}
+
+// Load reads a store saved with Save.
+//
+// Parameters:
+//   - path: The path of the state file.
+//
+// Returns:
+//   - *Store: The store as it was saved.
+//   - error: If the file cannot be read or parsed.
+func Load(path string) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read trust anchors state: %w", err)
+	}
+
+	store := &Store{}
+	if err = json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrInvalidAnchors, path, err.Error())
+	}
+	return store, nil
+}
+
+// Save writes the store to a state file. The file is replaced atomically,
+// so that an interrupted save does not lose the state of the keys.
+//
+// Parameters:
+//   - path: The path of the state file.
+//
+// Returns:
+//   - error: If the file cannot be written.
+func (store *Store) Save(path string) error {
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("cannot write trust anchors state: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(append(data, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write trust anchors state: %w", err)
+	}
+
+	if err = os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("cannot write trust anchors state: %w", err)
+	}
+	return nil
+}
+
+// TrustedKeys returns the keys that can validate the zone's DNSKEY RRset.
+//
+// Returns:
+//   - []dns.RDataDNSKEY: The Valid and Missing keys.
+func (store *Store) TrustedKeys() (keys []dns.RDataDNSKEY) {
+	for _, key := range store.Keys {
+		if key.State == StateValid || key.State == StateMissing {
+			keys = append(keys, key.Key)
+		}
+	}
+	return keys
+}
+
+// IsTrusted reports whether a key can validate the zone's DNSKEY RRset: either it is
+// a trusted key, or it is not tracked yet and matches one of the store's anchors.
+//
+// Parameters:
+//   - key: A key of the zone's DNSKEY RRset.
+//   - now: The current time, to check the anchors' validity period.
+func (store *Store) IsTrusted(key dns.RDataDNSKEY, now time.Time) bool {
+	if i := store.findKey(key); i >= 0 {
+		state := store.Keys[i].State
+		return (state == StateValid || state == StateMissing) && !isRevoked(key)
+	}
+	return !isRevoked(key) && store.matchesAnchor(key, now)
+}
+
+// Update applies the zone's current DNSKEY RRset to the tracked keys [RFC5011]:
+//   - A new key signing key is pending until it has been seen for AddHoldDown,
+//     unless it matches one of the store's anchors, in which case it is trusted right away.
+//   - A trusted key missing from the RRset is kept, and trusted again when it comes back.
+//   - A revoked key is no longer trusted, and is forgotten after RemoveHoldDown.
+//
+// The RRset must have been validated with a key for which IsTrusted is true before calling Update,
+// and a revoked key must have signed the RRset itself: this store does not check signatures.
+//
+// Parameters:
+//   - keys: The records of the validated DNSKEY RRset.
+//   - now: The time the RRset was received.
+func (store *Store) Update(keys []dns.RDataDNSKEY, now time.Time) {
+	seen := make([]bool, len(store.Keys))
+
+	for _, key := range keys {
+		if key.Flags&dns.DNSKEYFlagZone == 0 || key.Flags&dns.DNSKEYFlagSecureEntryPoint == 0 {
+			// Only key signing keys are trust anchors
+			continue
+		}
+
+		i := store.findKey(key)
+		if i < 0 {
+			if isRevoked(key) {
+				// A key that was never trusted cannot be revoked
+				continue
+			}
+			state := StateAddPend
+			if store.matchesAnchor(key, now) {
+				state = StateValid
+			}
+			store.Keys = append(store.Keys, Key{Key: key, State: state, Since: now})
+			seen = append(seen, true)
+			continue
+		}
+
+		seen[i] = true
+		tracked := &store.Keys[i]
+		switch {
+		case isRevoked(key) && tracked.State != StateRevoked:
+			tracked.Key = key
+			tracked.State = StateRevoked
+			tracked.Since = now
+		case tracked.State == StateAddPend && now.Sub(tracked.Since) >= AddHoldDown:
+			tracked.State = StateValid
+			tracked.Since = now
+		case tracked.State == StateMissing:
+			tracked.State = StateValid
+			tracked.Since = now
+		}
+	}
+
+	keep := store.Keys[:0]
+	for i, tracked := range store.Keys {
+		switch {
+		case !seen[i] && tracked.State == StateAddPend:
+			// The add hold-down starts again if the key comes back
+			continue
+		case !seen[i] && tracked.State == StateValid:
+			tracked.State = StateMissing
+			tracked.Since = now
+		case tracked.State == StateRevoked && now.Sub(tracked.Since) >= RemoveHoldDown:
+			continue
+		}
+		keep = append(keep, tracked)
+	}
+	store.Keys = keep
+}
+
+// findKey returns the index of the tracked key with the same key material, or -1.
+// Keys are compared without their revoked flag, which changes when a key is revoked.
+func (store *Store) findKey(key dns.RDataDNSKEY) int {
+	for i, tracked := range store.Keys {
+		if tracked.Key.Flags&^dns.DNSKEYFlagRevoke == key.Flags&^dns.DNSKEYFlagRevoke &&
+			tracked.Key.Protocol == key.Protocol &&
+			tracked.Key.Algorithm == key.Algorithm &&
+			bytes.Equal(tracked.Key.PublicKey, key.PublicKey) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (store *Store) matchesAnchor(key dns.RDataDNSKEY, now time.Time) bool {
+	for _, anchor := range store.Anchors {
+		if now.Before(anchor.ValidFrom) || (!anchor.ValidUntil.IsZero() && !now.Before(anchor.ValidUntil)) {
+			continue
+		}
+
+		ds, err := key.DS(store.Zone, anchor.DS.DigestType)
+		if err != nil {
+			continue
+		}
+		if ds.KeyTag == anchor.DS.KeyTag && ds.Algorithm == anchor.DS.Algorithm && bytes.Equal(ds.Digest, anchor.DS.Digest) {
+			return true
+		}
+	}
+	return false
+}
+
+func isRevoked(key dns.RDataDNSKEY) bool {
+	return key.Flags&dns.DNSKEYFlagRevoke != 0
+}
@@ -0,0 +1,272 @@
+package trustanchor
+
+import (
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcombeau/dns-tools/dns"
+)
+
+const testRootAnchors = `<?xml version="1.0" encoding="UTF-8"?>
+<TrustAnchor id="380DC50D-484E-40D0-A3AE-68F2B18F61C7" source="http://data.iana.org/root-anchors/root-anchors.xml">
+<Zone>.</Zone>
+<KeyDigest id="Kjqmt7v" validFrom="2010-07-15T00:00:00+00:00" validUntil="2019-01-11T00:00:00+00:00">
+<KeyTag>19036</KeyTag>
+<Algorithm>8</Algorithm>
+<DigestType>2</DigestType>
+<Digest>49AAC11D7B6F6446702E54A1607371607A1A41855200FD2CE1CDDE32F24E8FB5</Digest>
+</KeyDigest>
+<KeyDigest id="Klajeyz" validFrom="2017-02-02T00:00:00+00:00">
+<KeyTag>20326</KeyTag>
+<Algorithm>8</Algorithm>
+<DigestType>2</DigestType>
+<Digest>E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D</Digest>
+</KeyDigest>
+</TrustAnchor>
+`
+
+// The root zone KSK-2017, key tag 20326
+const testRootKSKPublicKey = "AwEAAaz/tAm8yTn4Mfeh5eyI96WSVexTBAvkMgJzkKTOiW1vkIbzxeF3+/4RgWOq7HrxRixHlFlExOLAJr5emLvN7SWXgnLh4+B5xQlNVz8Og8kvArMtNROxVQuCaSnIDdD5LKyWbRd2n9WGe2R8PzgCmr3EgVLrjyBxWezF0jLHwVN8efS3rCj/EWgvIWgb9tarpVUDK/b58Da+sqqls3eNbuv7pr+eoZG+SrDK6nWeL3c6H5Apxz7LjVc1uTIdsIXxuOLYA4/ilBmSVIzuDWfdRUfhHdY6+cn8HFRm+2hM8AnXGXws9555KrUB5qihylGa8subX2Nn6UwNR1AkUTV74bU="
+
+func newTestKey(t *testing.T, flags uint16, publicKey string) dns.RDataDNSKEY {
+	t.Helper()
+
+	decoded, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		t.Fatalf("decode public key: %v", err)
+	}
+	return dns.RDataDNSKEY{
+		Flags:     flags,
+		Protocol:  dns.DNSKEYProtocol,
+		Algorithm: dns.RSASHA256,
+		PublicKey: decoded,
+	}
+}
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := ParseRootAnchors(strings.NewReader(testRootAnchors))
+	if err != nil {
+		t.Fatalf("ParseRootAnchors() unexpected error = %v\n", err)
+	}
+	return store
+}
+
+func getTestKeyStates(store *Store) (states []KeyState) {
+	for _, key := range store.Keys {
+		states = append(states, key.State)
+	}
+	return states
+}
+
+func TestParseRootAnchors(t *testing.T) {
+	tests := []struct {
+		name        string
+		xml         string
+		wantAnchors int
+		wantError   error
+	}{
+		{
+			name:        "IANA root anchors",
+			xml:         testRootAnchors,
+			wantAnchors: 2,
+			wantError:   nil,
+		},
+		{
+			name:      "Invalid digest",
+			xml:       strings.Replace(testRootAnchors, "E06D44B8", "NOTHEX", 1),
+			wantError: ErrInvalidAnchors,
+		},
+		{
+			name:      "No key digest",
+			xml:       "<TrustAnchor><Zone>.</Zone></TrustAnchor>",
+			wantError: ErrInvalidAnchors,
+		},
+		{
+			name:      "Not XML",
+			xml:       ". IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D",
+			wantError: ErrInvalidAnchors,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRootAnchors(strings.NewReader(tt.xml))
+
+			if tt.wantError != nil {
+				if err == nil || !errors.Is(err, tt.wantError) {
+					t.Fatalf("ParseRootAnchors() error = %v, want error = %v\n", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRootAnchors() unexpected error = %v\n", err)
+			}
+			if got.Zone != "." || len(got.Anchors) != tt.wantAnchors {
+				t.Fatalf("ParseRootAnchors() got = %+v\n", got)
+			}
+			if got.Anchors[1].DS.String() != "20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D" || !got.Anchors[1].ValidUntil.IsZero() {
+				t.Errorf("ParseRootAnchors() anchor got = %+v\n", got.Anchors[1])
+			}
+		})
+	}
+}
+
+func TestUpdateBootstrap(t *testing.T) {
+	store := newTestStore(t)
+	ksk := newTestKey(t, dns.DNSKEYFlagZone|dns.DNSKEYFlagSecureEntryPoint, testRootKSKPublicKey)
+	zsk := newTestKey(t, dns.DNSKEYFlagZone, "AwEAAQ==")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if !store.IsTrusted(ksk, now) {
+		t.Errorf("IsTrusted() got = false, want = true for the key matching the anchor\n")
+	}
+	if store.IsTrusted(ksk, time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("IsTrusted() got = true, want = false before the anchor is valid\n")
+	}
+
+	store.Update([]dns.RDataDNSKEY{ksk, zsk}, now)
+
+	if got := getTestKeyStates(store); !reflect.DeepEqual(got, []KeyState{StateValid}) {
+		t.Fatalf("Update() states got = %v, want = [Valid]\n", got)
+	}
+	if got := store.TrustedKeys(); len(got) != 1 || got[0].KeyTag() != 20326 {
+		t.Errorf("TrustedKeys() got = %+v\n", got)
+	}
+}
+
+func TestUpdateRollover(t *testing.T) {
+	store := newTestStore(t)
+	oldKey := newTestKey(t, dns.DNSKEYFlagZone|dns.DNSKEYFlagSecureEntryPoint, testRootKSKPublicKey)
+	newKey := newTestKey(t, dns.DNSKEYFlagZone|dns.DNSKEYFlagSecureEntryPoint, "AwEAAbEEF/new/key/material/3nXnV0A==")
+	revokedKey := oldKey
+	revokedKey.Flags |= dns.DNSKEYFlagRevoke
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	steps := []struct {
+		name        string
+		keys        []dns.RDataDNSKEY
+		at          time.Duration
+		wantStates  []KeyState
+		wantTrusted []bool // IsTrusted of oldKey and newKey
+	}{
+		{
+			name:        "Bootstrap",
+			keys:        []dns.RDataDNSKEY{oldKey},
+			at:          0,
+			wantStates:  []KeyState{StateValid},
+			wantTrusted: []bool{true, false},
+		},
+		{
+			name:        "New key is published",
+			keys:        []dns.RDataDNSKEY{oldKey, newKey},
+			at:          1 * day,
+			wantStates:  []KeyState{StateValid, StateAddPend},
+			wantTrusted: []bool{true, false},
+		},
+		{
+			name:        "New key during the add hold-down",
+			keys:        []dns.RDataDNSKEY{oldKey, newKey},
+			at:          30 * day,
+			wantStates:  []KeyState{StateValid, StateAddPend},
+			wantTrusted: []bool{true, false},
+		},
+		{
+			name:        "New key after the add hold-down",
+			keys:        []dns.RDataDNSKEY{oldKey, newKey},
+			at:          31 * day,
+			wantStates:  []KeyState{StateValid, StateValid},
+			wantTrusted: []bool{true, true},
+		},
+		{
+			name:        "Old key is revoked",
+			keys:        []dns.RDataDNSKEY{revokedKey, newKey},
+			at:          40 * day,
+			wantStates:  []KeyState{StateRevoked, StateValid},
+			wantTrusted: []bool{false, true},
+		},
+		{
+			name:        "Revoked key is removed from the zone",
+			keys:        []dns.RDataDNSKEY{newKey},
+			at:          50 * day,
+			wantStates:  []KeyState{StateRevoked, StateValid},
+			wantTrusted: []bool{false, true},
+		},
+		{
+			name:        "Revoked key is forgotten after the remove hold-down",
+			keys:        []dns.RDataDNSKEY{newKey},
+			at:          70 * day,
+			wantStates:  []KeyState{StateValid},
+			wantTrusted: []bool{true, true}, // The old key matches the anchor again once forgotten
+		},
+	}
+
+	for _, step := range steps {
+		store.Update(step.keys, start.Add(step.at))
+
+		if got := getTestKeyStates(store); !reflect.DeepEqual(got, step.wantStates) {
+			t.Fatalf("%s: Update() states got = %v, want = %v\n", step.name, got, step.wantStates)
+		}
+		for i, key := range []dns.RDataDNSKEY{oldKey, newKey} {
+			if got := store.IsTrusted(key, start.Add(step.at)); got != step.wantTrusted[i] {
+				t.Errorf("%s: IsTrusted() key %d got = %v, want = %v\n", step.name, i, got, step.wantTrusted[i])
+			}
+		}
+	}
+}
+
+func TestUpdateMissingKeys(t *testing.T) {
+	store := newTestStore(t)
+	trustedKey := newTestKey(t, dns.DNSKEYFlagZone|dns.DNSKEYFlagSecureEntryPoint, testRootKSKPublicKey)
+	pendingKey := newTestKey(t, dns.DNSKEYFlagZone|dns.DNSKEYFlagSecureEntryPoint, "AwEAAbEEF/new/key/material/3nXnV0A==")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store.Update([]dns.RDataDNSKEY{trustedKey, pendingKey}, now)
+	store.Update([]dns.RDataDNSKEY{}, now.Add(time.Hour))
+
+	if got := getTestKeyStates(store); !reflect.DeepEqual(got, []KeyState{StateMissing}) {
+		t.Fatalf("Update() states got = %v, want = [Missing]\n", got)
+	}
+	if !store.IsTrusted(trustedKey, now) {
+		t.Errorf("IsTrusted() got = false, want = true for a missing key\n")
+	}
+
+	store.Update([]dns.RDataDNSKEY{trustedKey}, now.Add(2*time.Hour))
+
+	if got := getTestKeyStates(store); !reflect.DeepEqual(got, []KeyState{StateValid}) {
+		t.Errorf("Update() states got = %v, want = [Valid]\n", got)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	store := newTestStore(t)
+	store.Update([]dns.RDataDNSKEY{
+		newTestKey(t, dns.DNSKEYFlagZone|dns.DNSKEYFlagSecureEntryPoint, testRootKSKPublicKey),
+		newTestKey(t, dns.DNSKEYFlagZone|dns.DNSKEYFlagSecureEntryPoint, "AwEAAbEEF/new/key/material/3nXnV0A=="),
+	}, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	path := filepath.Join(t.TempDir(), "root-anchors.json")
+	if err := store.Save(path); err != nil {
+		t.Fatalf("Save() unexpected error = %v\n", err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error = %v\n", err)
+	}
+	if !reflect.DeepEqual(got, store) {
+		t.Errorf("Load() got = %+v, want = %+v\n", got, store)
+	}
+
+	if _, err = Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("Load() missing file error = nil, want error\n")
+	}
+}